@@ -0,0 +1,157 @@
+package influxdb
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// ops for audit log errors.
+const (
+	OpRecordAuditEvent  = "RecordAuditEvent"
+	OpFindAuditEvents   = "FindAuditEvents"
+	OpDeleteAuditEvents = "DeleteAuditEvents"
+)
+
+// AuditAction is the kind of call recorded by an audit event.
+type AuditAction string
+
+// audit actions.
+const (
+	AuditCreate     AuditAction = "create"
+	AuditUpdate     AuditAction = "update"
+	AuditDelete     AuditAction = "delete"
+	AuditLogin      AuditAction = "login"
+	AuditLogout     AuditAction = "logout"
+	AuditDeleteData AuditAction = "delete-data"
+)
+
+// Valid returns an error if the action is unknown.
+func (a AuditAction) Valid() error {
+	switch a {
+	case AuditCreate, AuditUpdate, AuditDelete, AuditLogin, AuditLogout, AuditDeleteData:
+		return nil
+	}
+	return &Error{
+		Code: EInvalid,
+		Msg:  "unknown audit action " + string(a),
+	}
+}
+
+// The resource types of the audit events of resources that have no
+// resource type of their own in permissions.
+const (
+	RolesAuditResourceType      = ResourceType("roles")
+	UserGroupsAuditResourceType = ResourceType("usergroups")
+)
+
+// AuditEvent is a record of a call that changed a resource, or of a login
+// or logout, in the audit log.
+type AuditEvent struct {
+	ID     ID          `json:"id,omitempty"`
+	Time   time.Time   `json:"time"`
+	Action AuditAction `json:"action"`
+
+	// UserID is the user that made the call, and TokenID or SessionID the
+	// authorization or session it was authorized with. The user of a login
+	// is the user logging in.
+	UserID    ID     `json:"userID,omitempty"`
+	TokenID   ID     `json:"tokenID,omitempty"`
+	SessionID ID     `json:"sessionID,omitempty"`
+	SourceIP  string `json:"sourceIP,omitempty"`
+
+	OrgID        ID           `json:"orgID,omitempty"`
+	ResourceType ResourceType `json:"resourceType"`
+	ResourceID   ID           `json:"resourceID,omitempty"`
+
+	// Before and After are the resource before and after the call. Changes
+	// are the top level fields of the resource that were updated.
+	Before  json.RawMessage `json:"before,omitempty"`
+	After   json.RawMessage `json:"after,omitempty"`
+	Changes []string        `json:"changes,omitempty"`
+
+	// Error is the error of a failed call, such as a failed login.
+	Error string `json:"error,omitempty"`
+
+	// Repeated is the number of failed logins of the user from the same
+	// source that were not recorded since the previous one was, as they
+	// are recorded at most once a minute.
+	Repeated int `json:"repeated,omitempty"`
+}
+
+// AuditFilter represents a set of filters that restrict the returned audit
+// events. Events are returned between Start, inclusive, and Stop, exclusive,
+// when they are set. After continues a page of events after the event with
+// that ID, in the order of the page.
+type AuditFilter struct {
+	OrgID        *ID
+	UserID       *ID
+	ResourceType *ResourceType
+	ResourceID   *ID
+	Action       *AuditAction
+	Start        time.Time
+	Stop         time.Time
+	After        *ID
+}
+
+// Matches returns true if the event matches the filter.
+func (f AuditFilter) Matches(e *AuditEvent) bool {
+	switch {
+	case f.OrgID != nil && e.OrgID != *f.OrgID,
+		f.UserID != nil && e.UserID != *f.UserID,
+		f.ResourceType != nil && e.ResourceType != *f.ResourceType,
+		f.ResourceID != nil && e.ResourceID != *f.ResourceID,
+		f.Action != nil && e.Action != *f.Action,
+		!f.Start.IsZero() && e.Time.Before(f.Start),
+		!f.Stop.IsZero() && !e.Time.Before(f.Stop):
+		return false
+	}
+	return true
+}
+
+// QueryParams converts the filter to url query params.
+func (f AuditFilter) QueryParams() map[string][]string {
+	qp := map[string][]string{}
+	if f.OrgID != nil {
+		qp["orgID"] = []string{f.OrgID.String()}
+	}
+	if f.UserID != nil {
+		qp["userID"] = []string{f.UserID.String()}
+	}
+	if f.ResourceType != nil {
+		qp["resourceType"] = []string{string(*f.ResourceType)}
+	}
+	if f.ResourceID != nil {
+		qp["resourceID"] = []string{f.ResourceID.String()}
+	}
+	if f.Action != nil {
+		qp["action"] = []string{string(*f.Action)}
+	}
+	if !f.Start.IsZero() {
+		qp["start"] = []string{f.Start.Format(time.RFC3339Nano)}
+	}
+	if !f.Stop.IsZero() {
+		qp["stop"] = []string{f.Stop.Format(time.RFC3339Nano)}
+	}
+	if f.After != nil {
+		qp["after"] = []string{f.After.String()}
+	}
+	return qp
+}
+
+// AuditService represents a service for recording and retrieving the audit log.
+type AuditService interface {
+	// RecordAuditEvent records an event in the audit log and sets its ID,
+	// and its time if it is not set.
+	RecordAuditEvent(ctx context.Context, e *AuditEvent) error
+
+	// FindAuditEvents returns the events that match filter, oldest first
+	// unless the options are descending, and the count of returned events.
+	FindAuditEvents(ctx context.Context, filter AuditFilter, opt ...FindOptions) ([]*AuditEvent, int, error)
+}
+
+// DefaultAuditFindOptions are the default options for the audit log.
+var DefaultAuditFindOptions = FindOptions{
+	Descending: true,
+	Limit:      100,
+}
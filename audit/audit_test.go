@@ -0,0 +1,273 @@
+package audit_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/audit"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func newTestService(t *testing.T) (*kv.Service, *audit.Recorder) {
+	t.Helper()
+	svc := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	if err := svc.Initialize(context.Background()); err != nil {
+		t.Fatalf("error initializing kv service: %v", err)
+	}
+	return svc, audit.NewRecorder(zaptest.NewLogger(t), svc)
+}
+
+// actorContext returns the context of a call made by the user with a token
+// from a client IP.
+func actorContext(userID, tokenID influxdb.ID) context.Context {
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:     tokenID,
+		UserID: userID,
+		Status: influxdb.Active,
+	})
+	return icontext.SetSourceIP(ctx, "10.0.0.1")
+}
+
+func findEvents(t *testing.T, svc influxdb.AuditService) []*influxdb.AuditEvent {
+	t.Helper()
+	es, _, err := svc.FindAuditEvents(context.Background(), influxdb.AuditFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return es
+}
+
+func TestBucketService(t *testing.T) {
+	svc, r := newTestService(t)
+	ctx := actorContext(influxdb.ID(10), influxdb.ID(20))
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	s := audit.NewBucketService(r, svc)
+	b := &influxdb.Bucket{OrgID: org.ID, Name: "telegraf"}
+	if err := s.CreateBucket(ctx, b); err != nil {
+		t.Fatal(err)
+	}
+	name := "metrics"
+	if _, err := s.UpdateBucket(ctx, b.ID, influxdb.BucketUpdate{Name: &name}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteBucket(ctx, b.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	es := findEvents(t, svc)
+	if len(es) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(es))
+	}
+	for i, action := range []influxdb.AuditAction{influxdb.AuditCreate, influxdb.AuditUpdate, influxdb.AuditDelete} {
+		e := es[i]
+		if e.Action != action || e.ResourceType != influxdb.BucketsResourceType || e.ResourceID != b.ID || e.OrgID != org.ID {
+			t.Errorf("unexpected %s event %+v", action, e)
+		}
+		if e.UserID != influxdb.ID(10) || e.TokenID != influxdb.ID(20) || e.SourceIP != "10.0.0.1" {
+			t.Errorf("unexpected actor of %s event %+v", action, e)
+		}
+	}
+	if es[0].Before != nil || es[0].After == nil {
+		t.Errorf("expected only the bucket after its creation, got %s %s", es[0].Before, es[0].After)
+	}
+	if diff := cmp.Diff([]string{"name"}, es[1].Changes); diff != "" {
+		t.Errorf("unexpected changes -want/+got:\n%s", diff)
+	}
+	var before influxdb.Bucket
+	if err := json.Unmarshal(es[2].Before, &before); err != nil {
+		t.Fatal(err)
+	}
+	if before.Name != name || es[2].After != nil {
+		t.Errorf("expected the deleted bucket, got %s %s", es[2].Before, es[2].After)
+	}
+}
+
+func TestBucketService_Failed(t *testing.T) {
+	svc, r := newTestService(t)
+
+	// A bucket without an organization cannot be created, so nothing is recorded.
+	s := audit.NewBucketService(r, svc)
+	if err := s.CreateBucket(context.Background(), &influxdb.Bucket{Name: "orphan"}); err == nil {
+		t.Fatal("expected an error creating a bucket without an organization")
+	}
+	if es := findEvents(t, svc); len(es) != 0 {
+		t.Fatalf("expected no events, got %d", len(es))
+	}
+}
+
+func TestAuthorizationService(t *testing.T) {
+	svc, r := newTestService(t)
+	ctx := context.Background()
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	user := &influxdb.User{Name: "user"}
+	if err := svc.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+
+	s := audit.NewAuthorizationService(r, svc)
+	a := &influxdb.Authorization{OrgID: org.ID, UserID: user.ID, Permissions: influxdb.OperPermissions()}
+	if err := s.CreateAuthorization(ctx, a); err != nil {
+		t.Fatal(err)
+	}
+	if a.Token == "" {
+		t.Fatal("expected the created authorization to have a token")
+	}
+
+	es := findEvents(t, svc)
+	if len(es) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(es))
+	}
+	if strings.Contains(string(es[0].After), a.Token) {
+		t.Fatal("expected the token not to be recorded")
+	}
+}
+
+func TestSecretService(t *testing.T) {
+	svc, r := newTestService(t)
+	ctx := actorContext(influxdb.ID(10), influxdb.ID(20))
+
+	s := audit.NewSecretService(r, svc)
+	if err := s.PutSecrets(ctx, influxdb.ID(1), map[string]string{"api": "hunter2", "db": "password1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	es := findEvents(t, svc)
+	if len(es) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(es))
+	}
+	if diff := cmp.Diff([]string{"api", "db"}, es[0].Changes); diff != "" {
+		t.Errorf("unexpected changes -want/+got:\n%s", diff)
+	}
+	raw, err := json.Marshal(es[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), "hunter2") || strings.Contains(string(raw), "password1") {
+		t.Fatalf("expected the secret values not to be recorded, got %s", raw)
+	}
+}
+
+func TestPasswordsService(t *testing.T) {
+	svc, r := newTestService(t)
+	ctx := icontext.SetSourceIP(context.Background(), "10.0.0.1")
+
+	user := &influxdb.User{Name: "user"}
+	if err := svc.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+
+	s := audit.NewPasswordsService(r, svc)
+	if err := s.SetPassword(ctx, user.ID, "correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ComparePassword(ctx, user.ID, "correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ComparePassword(ctx, user.ID, "battery staple"); err == nil {
+		t.Fatal("expected a wrong password to fail")
+	}
+
+	es := findEvents(t, svc)
+	if len(es) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(es))
+	}
+	if es[0].Action != influxdb.AuditUpdate || !cmp.Equal(es[0].Changes, []string{"password"}) {
+		t.Errorf("unexpected password update %+v", es[0])
+	}
+	if e := es[1]; e.Action != influxdb.AuditLogin || e.UserID != user.ID || e.Error == "" || e.SourceIP != "10.0.0.1" {
+		t.Errorf("unexpected failed login %+v", e)
+	}
+}
+
+func TestRoleService(t *testing.T) {
+	svc, r := newTestService(t)
+	ctx := actorContext(influxdb.ID(10), influxdb.ID(20))
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	roles := audit.NewRoleService(r, svc)
+	role := &influxdb.Role{OrgID: org.ID, Name: "readers"}
+	if err := roles.CreateRole(ctx, role); err != nil {
+		t.Fatal(err)
+	}
+	groups := audit.NewUserGroupService(r, svc)
+	g := &influxdb.UserGroup{OrgID: org.ID, Name: "team"}
+	if err := groups.CreateUserGroup(ctx, g); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := groups.UpdateUserGroup(ctx, g.ID, influxdb.UserGroupUpdate{Roles: &[]influxdb.ID{role.ID}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := roles.DeleteRole(ctx, role.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	es := findEvents(t, svc)
+	if len(es) != 4 {
+		t.Fatalf("expected 4 events, got %d", len(es))
+	}
+	for i, want := range []struct {
+		action       influxdb.AuditAction
+		resourceType influxdb.ResourceType
+		resourceID   influxdb.ID
+	}{
+		{influxdb.AuditCreate, influxdb.RolesAuditResourceType, role.ID},
+		{influxdb.AuditCreate, influxdb.UserGroupsAuditResourceType, g.ID},
+		{influxdb.AuditUpdate, influxdb.UserGroupsAuditResourceType, g.ID},
+		{influxdb.AuditDelete, influxdb.RolesAuditResourceType, role.ID},
+	} {
+		e := es[i]
+		if e.Action != want.action || e.ResourceType != want.resourceType || e.ResourceID != want.resourceID || e.OrgID != org.ID {
+			t.Errorf("unexpected %s event %+v", want.action, e)
+		}
+	}
+	if diff := cmp.Diff([]string{"roles"}, es[2].Changes); diff != "" {
+		t.Errorf("unexpected changes -want/+got:\n%s", diff)
+	}
+}
+
+func TestOrgLimitsService(t *testing.T) {
+	svc, r := newTestService(t)
+	ctx := actorContext(influxdb.ID(10), influxdb.ID(20))
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	s := audit.NewOrgLimitsService(r, svc)
+	quota := int64(1000)
+	if _, err := s.UpdateOrgLimits(ctx, org.ID, influxdb.OrgLimitsUpdate{MonthlyWriteBytesQuota: &quota}); err != nil {
+		t.Fatal(err)
+	}
+
+	es := findEvents(t, svc)
+	if len(es) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(es))
+	}
+	if e := es[0]; e.Action != influxdb.AuditUpdate || e.ResourceType != influxdb.OrgsResourceType || e.ResourceID != org.ID || e.OrgID != org.ID {
+		t.Errorf("unexpected event %+v", e)
+	}
+	if diff := cmp.Diff([]string{"monthlyWriteBytesQuota"}, es[0].Changes); diff != "" {
+		t.Errorf("unexpected changes -want/+got:\n%s", diff)
+	}
+}
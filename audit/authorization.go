@@ -0,0 +1,82 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.AuthorizationService = (*AuthorizationService)(nil)
+
+// AuthorizationService records the changes to authorizations in the audit
+// log. The tokens of the authorizations are never recorded.
+type AuthorizationService struct {
+	influxdb.AuthorizationService
+	r *Recorder
+}
+
+// NewAuthorizationService returns an AuthorizationService that records the changes made through s.
+func NewAuthorizationService(r *Recorder, s influxdb.AuthorizationService) *AuthorizationService {
+	return &AuthorizationService{
+		AuthorizationService: s,
+		r:                    r,
+	}
+}
+
+// CreateAuthorization creates the authorization and records its creation.
+func (s *AuthorizationService) CreateAuthorization(ctx context.Context, a *influxdb.Authorization) error {
+	if err := s.AuthorizationService.CreateAuthorization(ctx, a); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditCreate,
+		OrgID:        a.OrgID,
+		ResourceType: influxdb.AuthorizationsResourceType,
+		ResourceID:   a.ID,
+	}, nil, withoutToken(a))
+	return nil
+}
+
+// UpdateAuthorization updates the authorization and records the authorization before and after the update.
+func (s *AuthorizationService) UpdateAuthorization(ctx context.Context, id influxdb.ID, upd *influxdb.AuthorizationUpdate) (*influxdb.Authorization, error) {
+	before, err := s.AuthorizationService.FindAuthorizationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	a, err := s.AuthorizationService.UpdateAuthorization(ctx, id, upd)
+	if err != nil {
+		return nil, err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditUpdate,
+		OrgID:        a.OrgID,
+		ResourceType: influxdb.AuthorizationsResourceType,
+		ResourceID:   id,
+	}, withoutToken(before), withoutToken(a))
+	return a, nil
+}
+
+// DeleteAuthorization deletes the authorization and records the deleted authorization.
+func (s *AuthorizationService) DeleteAuthorization(ctx context.Context, id influxdb.ID) error {
+	before, err := s.AuthorizationService.FindAuthorizationByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.AuthorizationService.DeleteAuthorization(ctx, id); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditDelete,
+		OrgID:        before.OrgID,
+		ResourceType: influxdb.AuthorizationsResourceType,
+		ResourceID:   id,
+	}, withoutToken(before), nil)
+	return nil
+}
+
+// withoutToken returns a copy of the authorization without its token.
+func withoutToken(a *influxdb.Authorization) *influxdb.Authorization {
+	c := *a
+	c.Token = ""
+	return &c
+}
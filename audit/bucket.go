@@ -0,0 +1,74 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.BucketService = (*BucketService)(nil)
+
+// BucketService records the changes to buckets in the audit log.
+type BucketService struct {
+	influxdb.BucketService
+	r *Recorder
+}
+
+// NewBucketService returns a BucketService that records the changes made through s.
+func NewBucketService(r *Recorder, s influxdb.BucketService) *BucketService {
+	return &BucketService{
+		BucketService: s,
+		r:             r,
+	}
+}
+
+// CreateBucket creates the bucket and records its creation.
+func (s *BucketService) CreateBucket(ctx context.Context, b *influxdb.Bucket) error {
+	if err := s.BucketService.CreateBucket(ctx, b); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditCreate,
+		OrgID:        b.OrgID,
+		ResourceType: influxdb.BucketsResourceType,
+		ResourceID:   b.ID,
+	}, nil, b)
+	return nil
+}
+
+// UpdateBucket updates the bucket and records the bucket before and after the update.
+func (s *BucketService) UpdateBucket(ctx context.Context, id influxdb.ID, upd influxdb.BucketUpdate) (*influxdb.Bucket, error) {
+	before, err := s.BucketService.FindBucketByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.BucketService.UpdateBucket(ctx, id, upd)
+	if err != nil {
+		return nil, err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditUpdate,
+		OrgID:        b.OrgID,
+		ResourceType: influxdb.BucketsResourceType,
+		ResourceID:   id,
+	}, before, b)
+	return b, nil
+}
+
+// DeleteBucket deletes the bucket and records the deleted bucket.
+func (s *BucketService) DeleteBucket(ctx context.Context, id influxdb.ID) error {
+	before, err := s.BucketService.FindBucketByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.BucketService.DeleteBucket(ctx, id); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditDelete,
+		OrgID:        before.OrgID,
+		ResourceType: influxdb.BucketsResourceType,
+		ResourceID:   id,
+	}, before, nil)
+	return nil
+}
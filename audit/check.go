@@ -0,0 +1,87 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.CheckService = (*CheckService)(nil)
+
+// CheckService records the changes to checks in the audit log.
+type CheckService struct {
+	influxdb.CheckService
+	r *Recorder
+}
+
+// NewCheckService returns a CheckService that records the changes made through s.
+func NewCheckService(r *Recorder, s influxdb.CheckService) *CheckService {
+	return &CheckService{
+		CheckService: s,
+		r:            r,
+	}
+}
+
+// CreateCheck creates the check and records its creation.
+func (s *CheckService) CreateCheck(ctx context.Context, c influxdb.CheckCreate, userID influxdb.ID) error {
+	if err := s.CheckService.CreateCheck(ctx, c, userID); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditCreate,
+		OrgID:        c.GetOrgID(),
+		ResourceType: influxdb.ChecksResourceType,
+		ResourceID:   c.GetID(),
+	}, nil, c.Check)
+	return nil
+}
+
+// UpdateCheck updates the check and records the check before and after the update.
+func (s *CheckService) UpdateCheck(ctx context.Context, id influxdb.ID, cc influxdb.CheckCreate) (influxdb.Check, error) {
+	return s.update(ctx, id, func() (influxdb.Check, error) {
+		return s.CheckService.UpdateCheck(ctx, id, cc)
+	})
+}
+
+// PatchCheck updates the check and records the check before and after the update.
+func (s *CheckService) PatchCheck(ctx context.Context, id influxdb.ID, upd influxdb.CheckUpdate) (influxdb.Check, error) {
+	return s.update(ctx, id, func() (influxdb.Check, error) {
+		return s.CheckService.PatchCheck(ctx, id, upd)
+	})
+}
+
+// DeleteCheck deletes the check and records the deleted check.
+func (s *CheckService) DeleteCheck(ctx context.Context, id influxdb.ID) error {
+	before, err := s.CheckService.FindCheckByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.CheckService.DeleteCheck(ctx, id); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditDelete,
+		OrgID:        before.GetOrgID(),
+		ResourceType: influxdb.ChecksResourceType,
+		ResourceID:   id,
+	}, before, nil)
+	return nil
+}
+
+func (s *CheckService) update(ctx context.Context, id influxdb.ID, fn func() (influxdb.Check, error)) (influxdb.Check, error) {
+	before, err := s.CheckService.FindCheckByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	c, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditUpdate,
+		OrgID:        c.GetOrgID(),
+		ResourceType: influxdb.ChecksResourceType,
+		ResourceID:   id,
+	}, before, c)
+	return c, nil
+}
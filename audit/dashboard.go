@@ -0,0 +1,146 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.DashboardService = (*DashboardService)(nil)
+
+// DashboardService records the changes to dashboards in the audit log. The
+// changes to the cells of a dashboard are recorded as updates of the
+// dashboard.
+type DashboardService struct {
+	influxdb.DashboardService
+	r *Recorder
+}
+
+// NewDashboardService returns a DashboardService that records the changes made through s.
+func NewDashboardService(r *Recorder, s influxdb.DashboardService) *DashboardService {
+	return &DashboardService{
+		DashboardService: s,
+		r:                r,
+	}
+}
+
+// CreateDashboard creates the dashboard and records its creation.
+func (s *DashboardService) CreateDashboard(ctx context.Context, d *influxdb.Dashboard) error {
+	if err := s.DashboardService.CreateDashboard(ctx, d); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditCreate,
+		OrgID:        d.OrganizationID,
+		ResourceType: influxdb.DashboardsResourceType,
+		ResourceID:   d.ID,
+	}, nil, d)
+	return nil
+}
+
+// UpdateDashboard updates the dashboard and records the dashboard before and after the update.
+func (s *DashboardService) UpdateDashboard(ctx context.Context, id influxdb.ID, upd influxdb.DashboardUpdate) (*influxdb.Dashboard, error) {
+	var d *influxdb.Dashboard
+	err := s.update(ctx, id, func() (err error) {
+		d, err = s.DashboardService.UpdateDashboard(ctx, id, upd)
+		return err
+	})
+	return d, err
+}
+
+// AddDashboardCell adds the cell and records the dashboard before and after the update.
+func (s *DashboardService) AddDashboardCell(ctx context.Context, id influxdb.ID, c *influxdb.Cell, opts influxdb.AddDashboardCellOptions) error {
+	return s.update(ctx, id, func() error {
+		return s.DashboardService.AddDashboardCell(ctx, id, c, opts)
+	})
+}
+
+// RemoveDashboardCell removes the cell and records the dashboard before and after the update.
+func (s *DashboardService) RemoveDashboardCell(ctx context.Context, dashboardID, cellID influxdb.ID) error {
+	return s.update(ctx, dashboardID, func() error {
+		return s.DashboardService.RemoveDashboardCell(ctx, dashboardID, cellID)
+	})
+}
+
+// UpdateDashboardCell updates the cell and records the dashboard before and after the update.
+func (s *DashboardService) UpdateDashboardCell(ctx context.Context, dashboardID, cellID influxdb.ID, upd influxdb.CellUpdate) (*influxdb.Cell, error) {
+	var c *influxdb.Cell
+	err := s.update(ctx, dashboardID, func() (err error) {
+		c, err = s.DashboardService.UpdateDashboardCell(ctx, dashboardID, cellID, upd)
+		return err
+	})
+	return c, err
+}
+
+// ReplaceDashboardCells replaces the cells and records the dashboard before and after the update.
+func (s *DashboardService) ReplaceDashboardCells(ctx context.Context, id influxdb.ID, cs []*influxdb.Cell) error {
+	return s.update(ctx, id, func() error {
+		return s.DashboardService.ReplaceDashboardCells(ctx, id, cs)
+	})
+}
+
+// UpdateDashboardCellView updates the view of the cell and records the view before and after the update.
+func (s *DashboardService) UpdateDashboardCellView(ctx context.Context, dashboardID, cellID influxdb.ID, upd influxdb.ViewUpdate) (*influxdb.View, error) {
+	d, err := s.DashboardService.FindDashboardByID(ctx, dashboardID)
+	if err != nil {
+		return nil, err
+	}
+	before, err := s.DashboardService.GetDashboardCellView(ctx, dashboardID, cellID)
+	if err != nil {
+		return nil, err
+	}
+	v, err := s.DashboardService.UpdateDashboardCellView(ctx, dashboardID, cellID, upd)
+	if err != nil {
+		return nil, err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditUpdate,
+		OrgID:        d.OrganizationID,
+		ResourceType: influxdb.ViewsResourceType,
+		ResourceID:   cellID,
+	}, before, v)
+	return v, nil
+}
+
+// DeleteDashboard deletes the dashboard and records the deleted dashboard.
+func (s *DashboardService) DeleteDashboard(ctx context.Context, id influxdb.ID) error {
+	before, err := s.DashboardService.FindDashboardByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.DashboardService.DeleteDashboard(ctx, id); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditDelete,
+		OrgID:        before.OrganizationID,
+		ResourceType: influxdb.DashboardsResourceType,
+		ResourceID:   id,
+	}, before, nil)
+	return nil
+}
+
+// update calls fn and records the dashboard before and after it.
+func (s *DashboardService) update(ctx context.Context, id influxdb.ID, fn func() error) error {
+	before, err := s.DashboardService.FindDashboardByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+
+	// The update has been made, so the dashboard after it is only recorded
+	// if it can be found.
+	var after interface{}
+	if d, err := s.DashboardService.FindDashboardByID(ctx, id); err == nil {
+		after = d
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditUpdate,
+		OrgID:        before.OrganizationID,
+		ResourceType: influxdb.DashboardsResourceType,
+		ResourceID:   id,
+	}, before, after)
+	return nil
+}
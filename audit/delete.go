@@ -0,0 +1,68 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/storage/reads"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+)
+
+var _ influxdb.DeleteService = (*DeleteService)(nil)
+
+// DeleteService records the deletes of data in the audit log.
+type DeleteService struct {
+	influxdb.DeleteService
+	r *Recorder
+}
+
+// NewDeleteService returns a DeleteService that records the deletes made through s.
+func NewDeleteService(r *Recorder, s influxdb.DeleteService) *DeleteService {
+	return &DeleteService{
+		DeleteService: s,
+		r:             r,
+	}
+}
+
+// deletedData is the data of a bucket deleted by a delete, as recorded in
+// the audit log.
+type deletedData struct {
+	Start     time.Time `json:"start"`
+	Stop      time.Time `json:"stop"`
+	Predicate string    `json:"predicate,omitempty"`
+}
+
+// DeleteBucketRangePredicate deletes the data and records the range and predicate of the delete.
+func (s *DeleteService) DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) error {
+	if err := s.DeleteService.DeleteBucketRangePredicate(ctx, orgID, bucketID, min, max, pred); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditDeleteData,
+		OrgID:        orgID,
+		ResourceType: influxdb.BucketsResourceType,
+		ResourceID:   bucketID,
+	}, deletedData{
+		Start:     time.Unix(0, min).UTC(),
+		Stop:      time.Unix(0, max).UTC(),
+		Predicate: predicateString(pred),
+	}, nil)
+	return nil
+}
+
+// predicateString returns the expression of the predicate of a delete.
+func predicateString(pred influxdb.Predicate) string {
+	if pred == nil {
+		return ""
+	}
+	b, err := pred.Marshal()
+	if err != nil {
+		return ""
+	}
+	var p datatypes.Predicate
+	if err := p.Unmarshal(b); err != nil {
+		return ""
+	}
+	return reads.PredicateToExprString(&p)
+}
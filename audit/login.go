@@ -0,0 +1,93 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.LockoutService = (*LockoutService)(nil)
+
+// LockoutService records the unlocks of users in the audit log. The failed
+// sign-ins themselves are recorded as failed logins.
+type LockoutService struct {
+	influxdb.LockoutService
+	r *Recorder
+}
+
+// NewLockoutService returns a LockoutService that records the unlocks made through s.
+func NewLockoutService(r *Recorder, s influxdb.LockoutService) *LockoutService {
+	return &LockoutService{
+		LockoutService: s,
+		r:              r,
+	}
+}
+
+// ResetLoginAttempts unlocks the user and records the update of the user.
+func (s *LockoutService) ResetLoginAttempts(ctx context.Context, userID influxdb.ID) error {
+	if err := s.LockoutService.ResetLoginAttempts(ctx, userID); err != nil {
+		return err
+	}
+	recordUserUpdate(ctx, s.r, userID, "lockout")
+	return nil
+}
+
+var _ influxdb.TOTPService = (*TOTPService)(nil)
+
+// TOTPService records the TOTP changes and failed TOTP logins in the audit
+// log. The secrets and codes are never recorded.
+type TOTPService struct {
+	influxdb.TOTPService
+	r *Recorder
+}
+
+// NewTOTPService returns a TOTPService that records the TOTP changes and logins of s.
+func NewTOTPService(r *Recorder, s influxdb.TOTPService) *TOTPService {
+	return &TOTPService{
+		TOTPService: s,
+		r:           r,
+	}
+}
+
+// ConfirmTOTP enables TOTP for the user and records the update of the user.
+func (s *TOTPService) ConfirmTOTP(ctx context.Context, userID influxdb.ID, code string) error {
+	if err := s.TOTPService.ConfirmTOTP(ctx, userID, code); err != nil {
+		return err
+	}
+	recordUserUpdate(ctx, s.r, userID, "totp")
+	return nil
+}
+
+// VerifyTOTP verifies the code of the user and records a failed login if it
+// is not valid, at most once a minute for a user and source.
+func (s *TOTPService) VerifyTOTP(ctx context.Context, userID influxdb.ID, code string) error {
+	err := s.TOTPService.VerifyTOTP(ctx, userID, code)
+	if err != nil {
+		s.r.recordFailedLogin(ctx, influxdb.AuditEvent{
+			Action:       influxdb.AuditLogin,
+			UserID:       userID,
+			ResourceType: influxdb.UsersResourceType,
+			ResourceID:   userID,
+			Error:        err.Error(),
+		})
+	}
+	return err
+}
+
+// ResetTOTP disables TOTP for the user and records the update of the user.
+func (s *TOTPService) ResetTOTP(ctx context.Context, userID influxdb.ID) error {
+	if err := s.TOTPService.ResetTOTP(ctx, userID); err != nil {
+		return err
+	}
+	recordUserUpdate(ctx, s.r, userID, "totp")
+	return nil
+}
+
+func recordUserUpdate(ctx context.Context, r *Recorder, userID influxdb.ID, change string) {
+	r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditUpdate,
+		ResourceType: influxdb.UsersResourceType,
+		ResourceID:   userID,
+		Changes:      []string{change},
+	}, nil, nil)
+}
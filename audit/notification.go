@@ -0,0 +1,170 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	_ influxdb.NotificationEndpointService = (*NotificationEndpointService)(nil)
+	_ influxdb.NotificationRuleStore       = (*NotificationRuleStore)(nil)
+)
+
+// NotificationEndpointService records the changes to notification endpoints
+// in the audit log. The secrets of the endpoints are recorded by key only.
+type NotificationEndpointService struct {
+	influxdb.NotificationEndpointService
+	r *Recorder
+}
+
+// NewNotificationEndpointService returns a NotificationEndpointService that records the changes made through s.
+func NewNotificationEndpointService(r *Recorder, s influxdb.NotificationEndpointService) *NotificationEndpointService {
+	return &NotificationEndpointService{
+		NotificationEndpointService: s,
+		r:                           r,
+	}
+}
+
+// CreateNotificationEndpoint creates the notification endpoint and records its creation.
+func (s *NotificationEndpointService) CreateNotificationEndpoint(ctx context.Context, ne influxdb.NotificationEndpoint, userID influxdb.ID) error {
+	if err := s.NotificationEndpointService.CreateNotificationEndpoint(ctx, ne, userID); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditCreate,
+		OrgID:        ne.GetOrgID(),
+		ResourceType: influxdb.NotificationEndpointResourceType,
+		ResourceID:   ne.GetID(),
+	}, nil, ne)
+	return nil
+}
+
+// UpdateNotificationEndpoint updates the notification endpoint and records the endpoint before and after the update.
+func (s *NotificationEndpointService) UpdateNotificationEndpoint(ctx context.Context, id influxdb.ID, ne influxdb.NotificationEndpoint, userID influxdb.ID) (influxdb.NotificationEndpoint, error) {
+	return s.update(ctx, id, func() (influxdb.NotificationEndpoint, error) {
+		return s.NotificationEndpointService.UpdateNotificationEndpoint(ctx, id, ne, userID)
+	})
+}
+
+// PatchNotificationEndpoint updates the notification endpoint and records the endpoint before and after the update.
+func (s *NotificationEndpointService) PatchNotificationEndpoint(ctx context.Context, id influxdb.ID, upd influxdb.NotificationEndpointUpdate) (influxdb.NotificationEndpoint, error) {
+	return s.update(ctx, id, func() (influxdb.NotificationEndpoint, error) {
+		return s.NotificationEndpointService.PatchNotificationEndpoint(ctx, id, upd)
+	})
+}
+
+// DeleteNotificationEndpoint deletes the notification endpoint and records the deleted endpoint.
+func (s *NotificationEndpointService) DeleteNotificationEndpoint(ctx context.Context, id influxdb.ID) ([]influxdb.SecretField, influxdb.ID, error) {
+	before, err := s.NotificationEndpointService.FindNotificationEndpointByID(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	flds, orgID, err := s.NotificationEndpointService.DeleteNotificationEndpoint(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditDelete,
+		OrgID:        orgID,
+		ResourceType: influxdb.NotificationEndpointResourceType,
+		ResourceID:   id,
+	}, before, nil)
+	return flds, orgID, nil
+}
+
+func (s *NotificationEndpointService) update(ctx context.Context, id influxdb.ID, fn func() (influxdb.NotificationEndpoint, error)) (influxdb.NotificationEndpoint, error) {
+	before, err := s.NotificationEndpointService.FindNotificationEndpointByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ne, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditUpdate,
+		OrgID:        ne.GetOrgID(),
+		ResourceType: influxdb.NotificationEndpointResourceType,
+		ResourceID:   id,
+	}, before, ne)
+	return ne, nil
+}
+
+// NotificationRuleStore records the changes to notification rules in the audit log.
+type NotificationRuleStore struct {
+	influxdb.NotificationRuleStore
+	r *Recorder
+}
+
+// NewNotificationRuleStore returns a NotificationRuleStore that records the changes made through s.
+func NewNotificationRuleStore(r *Recorder, s influxdb.NotificationRuleStore) *NotificationRuleStore {
+	return &NotificationRuleStore{
+		NotificationRuleStore: s,
+		r:                     r,
+	}
+}
+
+// CreateNotificationRule creates the notification rule and records its creation.
+func (s *NotificationRuleStore) CreateNotificationRule(ctx context.Context, nr influxdb.NotificationRuleCreate, userID influxdb.ID) error {
+	if err := s.NotificationRuleStore.CreateNotificationRule(ctx, nr, userID); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditCreate,
+		OrgID:        nr.GetOrgID(),
+		ResourceType: influxdb.NotificationRuleResourceType,
+		ResourceID:   nr.GetID(),
+	}, nil, nr.NotificationRule)
+	return nil
+}
+
+// UpdateNotificationRule updates the notification rule and records the rule before and after the update.
+func (s *NotificationRuleStore) UpdateNotificationRule(ctx context.Context, id influxdb.ID, nr influxdb.NotificationRuleCreate, userID influxdb.ID) (influxdb.NotificationRule, error) {
+	return s.update(ctx, id, func() (influxdb.NotificationRule, error) {
+		return s.NotificationRuleStore.UpdateNotificationRule(ctx, id, nr, userID)
+	})
+}
+
+// PatchNotificationRule updates the notification rule and records the rule before and after the update.
+func (s *NotificationRuleStore) PatchNotificationRule(ctx context.Context, id influxdb.ID, upd influxdb.NotificationRuleUpdate) (influxdb.NotificationRule, error) {
+	return s.update(ctx, id, func() (influxdb.NotificationRule, error) {
+		return s.NotificationRuleStore.PatchNotificationRule(ctx, id, upd)
+	})
+}
+
+// DeleteNotificationRule deletes the notification rule and records the deleted rule.
+func (s *NotificationRuleStore) DeleteNotificationRule(ctx context.Context, id influxdb.ID) error {
+	before, err := s.NotificationRuleStore.FindNotificationRuleByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.NotificationRuleStore.DeleteNotificationRule(ctx, id); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditDelete,
+		OrgID:        before.GetOrgID(),
+		ResourceType: influxdb.NotificationRuleResourceType,
+		ResourceID:   id,
+	}, before, nil)
+	return nil
+}
+
+func (s *NotificationRuleStore) update(ctx context.Context, id influxdb.ID, fn func() (influxdb.NotificationRule, error)) (influxdb.NotificationRule, error) {
+	before, err := s.NotificationRuleStore.FindNotificationRuleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	nr, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditUpdate,
+		OrgID:        nr.GetOrgID(),
+		ResourceType: influxdb.NotificationRuleResourceType,
+		ResourceID:   id,
+	}, before, nr)
+	return nr, nil
+}
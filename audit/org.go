@@ -0,0 +1,74 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.OrganizationService = (*OrgService)(nil)
+
+// OrgService records the changes to organizations in the audit log.
+type OrgService struct {
+	influxdb.OrganizationService
+	r *Recorder
+}
+
+// NewOrgService returns an OrgService that records the changes made through s.
+func NewOrgService(r *Recorder, s influxdb.OrganizationService) *OrgService {
+	return &OrgService{
+		OrganizationService: s,
+		r:                   r,
+	}
+}
+
+// CreateOrganization creates the organization and records its creation.
+func (s *OrgService) CreateOrganization(ctx context.Context, o *influxdb.Organization) error {
+	if err := s.OrganizationService.CreateOrganization(ctx, o); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditCreate,
+		OrgID:        o.ID,
+		ResourceType: influxdb.OrgsResourceType,
+		ResourceID:   o.ID,
+	}, nil, o)
+	return nil
+}
+
+// UpdateOrganization updates the organization and records the organization before and after the update.
+func (s *OrgService) UpdateOrganization(ctx context.Context, id influxdb.ID, upd influxdb.OrganizationUpdate) (*influxdb.Organization, error) {
+	before, err := s.OrganizationService.FindOrganizationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	o, err := s.OrganizationService.UpdateOrganization(ctx, id, upd)
+	if err != nil {
+		return nil, err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditUpdate,
+		OrgID:        id,
+		ResourceType: influxdb.OrgsResourceType,
+		ResourceID:   id,
+	}, before, o)
+	return o, nil
+}
+
+// DeleteOrganization deletes the organization and records the deleted organization.
+func (s *OrgService) DeleteOrganization(ctx context.Context, id influxdb.ID) error {
+	before, err := s.OrganizationService.FindOrganizationByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.OrganizationService.DeleteOrganization(ctx, id); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditDelete,
+		OrgID:        id,
+		ResourceType: influxdb.OrgsResourceType,
+		ResourceID:   id,
+	}, before, nil)
+	return nil
+}
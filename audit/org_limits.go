@@ -0,0 +1,44 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.OrgLimitsService = (*OrgLimitsService)(nil)
+
+// OrgLimitsService records the changes to the limits and quotas of
+// organizations in the audit log. The changes are recorded as updates of
+// the organization.
+type OrgLimitsService struct {
+	influxdb.OrgLimitsService
+	r *Recorder
+}
+
+// NewOrgLimitsService returns an OrgLimitsService that records the changes made through s.
+func NewOrgLimitsService(r *Recorder, s influxdb.OrgLimitsService) *OrgLimitsService {
+	return &OrgLimitsService{
+		OrgLimitsService: s,
+		r:                r,
+	}
+}
+
+// UpdateOrgLimits updates the limits and records the limits before and after the update.
+func (s *OrgLimitsService) UpdateOrgLimits(ctx context.Context, orgID influxdb.ID, upd influxdb.OrgLimitsUpdate) (*influxdb.OrgLimits, error) {
+	before, err := s.OrgLimitsService.FindOrgLimits(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	l, err := s.OrgLimitsService.UpdateOrgLimits(ctx, orgID, upd)
+	if err != nil {
+		return nil, err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditUpdate,
+		OrgID:        orgID,
+		ResourceType: influxdb.OrgsResourceType,
+		ResourceID:   orgID,
+	}, before, l)
+	return l, nil
+}
@@ -0,0 +1,191 @@
+// Package audit records the changes made through the services of the API,
+// and logins and logouts, in an audit log.
+//
+// The services of the package decorate the services of the API. Each call
+// that creates, updates or deletes a resource is recorded along with the
+// user, token or session and client IP of the call, and the resource before
+// and after it.
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"go.uber.org/zap"
+)
+
+// Failed logins of a user from a source IP are recorded at most once every
+// failedLoginInterval, along with the number of failures that were not, so
+// that guessing a password does not flood the audit log. The failures of at
+// most maxFailedLoginSources users and sources are tracked at a time.
+const (
+	failedLoginInterval   = time.Minute
+	maxFailedLoginSources = 10000
+)
+
+type failedLoginSource struct {
+	userID   influxdb.ID
+	sourceIP string
+}
+
+type failedLogins struct {
+	recorded time.Time
+	repeated int
+}
+
+// Recorder records the events of the decorated services in an audit log.
+type Recorder struct {
+	log *zap.Logger
+	s   influxdb.AuditService
+
+	mu           sync.Mutex
+	failedLogins map[failedLoginSource]*failedLogins
+	now          func() time.Time
+}
+
+// NewRecorder returns a Recorder that records events in the audit service.
+func NewRecorder(log *zap.Logger, s influxdb.AuditService) *Recorder {
+	return &Recorder{
+		log:          log,
+		s:            s,
+		failedLogins: make(map[failedLoginSource]*failedLogins),
+		now:          time.Now,
+	}
+}
+
+// recordFailedLogin records the failed login e of the user of the event,
+// unless a failed login of the user from the same source IP was recorded
+// less than failedLoginInterval ago, in which case it is counted in the
+// next one that is recorded.
+func (r *Recorder) recordFailedLogin(ctx context.Context, e influxdb.AuditEvent) {
+	src := failedLoginSource{userID: e.UserID, sourceIP: icontext.GetSourceIP(ctx)}
+	now := r.now()
+
+	r.mu.Lock()
+	f, ok := r.failedLogins[src]
+	if ok && now.Sub(f.recorded) < failedLoginInterval {
+		f.repeated++
+		r.mu.Unlock()
+		return
+	}
+	if ok {
+		e.Repeated = f.repeated
+		f.recorded, f.repeated = now, 0
+	} else {
+		if len(r.failedLogins) >= maxFailedLoginSources {
+			r.expireFailedLogins(now)
+		}
+		if len(r.failedLogins) < maxFailedLoginSources {
+			r.failedLogins[src] = &failedLogins{recorded: now}
+		}
+	}
+	r.mu.Unlock()
+
+	r.record(ctx, e, nil, nil)
+}
+
+// expireFailedLogins stops tracking the sources whose last failed login was
+// recorded more than failedLoginInterval ago. Failures that were not
+// recorded are dropped with them.
+func (r *Recorder) expireFailedLogins(now time.Time) {
+	for src, f := range r.failedLogins {
+		if now.Sub(f.recorded) >= failedLoginInterval {
+			delete(r.failedLogins, src)
+		}
+	}
+}
+
+// record records e with the actor and source of the call of ctx, and the
+// resource before and after the call. Either may be nil. The call has
+// already been made, so an error recording it is logged rather than
+// returned.
+func (r *Recorder) record(ctx context.Context, e influxdb.AuditEvent, before, after interface{}) {
+	if a, err := icontext.GetAuthorizer(ctx); err == nil {
+		if !e.UserID.Valid() {
+			e.UserID = a.GetUserID()
+		}
+		switch a.Kind() {
+		case influxdb.AuthorizationKind:
+			e.TokenID = a.Identifier()
+		case influxdb.SessionAuthorizionKind:
+			e.SessionID = a.Identifier()
+		}
+	}
+	e.SourceIP = icontext.GetSourceIP(ctx)
+
+	var err error
+	if e.Before, err = marshal(before); err != nil {
+		r.log.Error("Failed to encode resource for audit log", zap.Error(err))
+	}
+	if e.After, err = marshal(after); err != nil {
+		r.log.Error("Failed to encode resource for audit log", zap.Error(err))
+	}
+	if e.Before != nil && e.After != nil {
+		e.Changes = changes(e.Before, e.After)
+	}
+
+	if err := r.s.RecordAuditEvent(ctx, &e); err != nil {
+		r.log.Error("Failed to record audit event",
+			zap.String("action", string(e.Action)),
+			zap.String("resource_type", string(e.ResourceType)),
+			zap.Stringer("resource_id", e.ResourceID),
+			zap.Error(err))
+	}
+}
+
+func marshal(v interface{}) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+// ignoredChanges are the fields that change on every update.
+var ignoredChanges = map[string]bool{
+	"updatedAt": true,
+}
+
+// changes returns the paths of the fields that differ between the resource
+// before and after an update. Objects are compared by field, so that a
+// change to a nested field is reported as, for example, "meta.name".
+func changes(before, after json.RawMessage) []string {
+	var cs []string
+	diff("", before, after, &cs)
+	sort.Strings(cs)
+	return cs
+}
+
+func diff(path string, before, after json.RawMessage, cs *[]string) {
+	var b, a map[string]json.RawMessage
+	if json.Unmarshal(before, &b) != nil || json.Unmarshal(after, &a) != nil || b == nil || a == nil {
+		if !bytes.Equal(before, after) {
+			*cs = append(*cs, path)
+		}
+		return
+	}
+
+	for k, bv := range b {
+		if ignoredChanges[k] {
+			continue
+		}
+		diff(join(path, k), bv, a[k], cs)
+	}
+	for k, av := range a {
+		if _, ok := b[k]; !ok && !ignoredChanges[k] {
+			diff(join(path, k), nil, av, cs)
+		}
+	}
+}
+
+func join(path, k string) string {
+	if path == "" {
+		return k
+	}
+	return path + "." + k
+}
@@ -0,0 +1,45 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestRecorder_recordFailedLogin(t *testing.T) {
+	var events []*influxdb.AuditEvent
+	svc := mock.NewAuditService()
+	svc.RecordAuditEventFn = func(ctx context.Context, e *influxdb.AuditEvent) error {
+		events = append(events, e)
+		return nil
+	}
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := NewRecorder(zaptest.NewLogger(t), svc)
+	r.now = func() time.Time { return now }
+
+	login := influxdb.AuditEvent{Action: influxdb.AuditLogin, UserID: 1, ResourceType: influxdb.UsersResourceType, ResourceID: 1}
+	ctx := icontext.SetSourceIP(context.Background(), "10.0.0.1")
+	for i := 0; i < 3; i++ {
+		r.recordFailedLogin(ctx, login)
+	}
+	if len(events) != 1 || events[0].Repeated != 0 {
+		t.Fatalf("expected the first failed login to be recorded alone, got %+v", events)
+	}
+
+	r.recordFailedLogin(icontext.SetSourceIP(context.Background(), "10.0.0.2"), login)
+	if len(events) != 2 || events[1].SourceIP != "10.0.0.2" {
+		t.Fatalf("expected the failed login from another source to be recorded, got %+v", events)
+	}
+
+	now = now.Add(failedLoginInterval)
+	r.recordFailedLogin(ctx, login)
+	if len(events) != 3 || events[2].Repeated != 2 {
+		t.Fatalf("expected the failed login to count the 2 that were not recorded, got %+v", events)
+	}
+}
@@ -0,0 +1,39 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultRetentionCheckInterval is how often the events older than the
+// retention of the audit log are deleted.
+const DefaultRetentionCheckInterval = time.Hour
+
+// LogTrimmer deletes the events of an audit log recorded before a time.
+type LogTrimmer interface {
+	DeleteAuditEventsBefore(ctx context.Context, before time.Time) (int, error)
+}
+
+// EnforceRetention deletes the events older than retention from the audit
+// log every interval, starting now, until ctx is done.
+func EnforceRetention(ctx context.Context, log *zap.Logger, s LogTrimmer, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := s.DeleteAuditEventsBefore(ctx, time.Now().Add(-retention))
+		if err != nil && ctx.Err() == nil {
+			log.Error("Failed to delete expired audit events", zap.Error(err))
+		} else if n > 0 {
+			log.Info("Deleted expired audit events", zap.Int("count", n))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.RoleService = (*RoleService)(nil)
+
+// RoleService records the changes to roles in the audit log.
+type RoleService struct {
+	influxdb.RoleService
+	r *Recorder
+}
+
+// NewRoleService returns a RoleService that records the changes made through s.
+func NewRoleService(r *Recorder, s influxdb.RoleService) *RoleService {
+	return &RoleService{
+		RoleService: s,
+		r:           r,
+	}
+}
+
+// CreateRole creates the role and records its creation.
+func (s *RoleService) CreateRole(ctx context.Context, role *influxdb.Role) error {
+	if err := s.RoleService.CreateRole(ctx, role); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditCreate,
+		OrgID:        role.OrgID,
+		ResourceType: influxdb.RolesAuditResourceType,
+		ResourceID:   role.ID,
+	}, nil, role)
+	return nil
+}
+
+// UpdateRole updates the role and records the role before and after the update.
+func (s *RoleService) UpdateRole(ctx context.Context, id influxdb.ID, upd influxdb.RoleUpdate) (*influxdb.Role, error) {
+	before, err := s.RoleService.FindRoleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	role, err := s.RoleService.UpdateRole(ctx, id, upd)
+	if err != nil {
+		return nil, err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditUpdate,
+		OrgID:        role.OrgID,
+		ResourceType: influxdb.RolesAuditResourceType,
+		ResourceID:   id,
+	}, before, role)
+	return role, nil
+}
+
+// DeleteRole deletes the role and records the deleted role.
+func (s *RoleService) DeleteRole(ctx context.Context, id influxdb.ID) error {
+	before, err := s.RoleService.FindRoleByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.RoleService.DeleteRole(ctx, id); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditDelete,
+		OrgID:        before.OrgID,
+		ResourceType: influxdb.RolesAuditResourceType,
+		ResourceID:   id,
+	}, before, nil)
+	return nil
+}
+
+var _ influxdb.UserGroupService = (*UserGroupService)(nil)
+
+// UserGroupService records the changes to user groups, including their
+// members and roles, in the audit log.
+type UserGroupService struct {
+	influxdb.UserGroupService
+	r *Recorder
+}
+
+// NewUserGroupService returns a UserGroupService that records the changes made through s.
+func NewUserGroupService(r *Recorder, s influxdb.UserGroupService) *UserGroupService {
+	return &UserGroupService{
+		UserGroupService: s,
+		r:                r,
+	}
+}
+
+// CreateUserGroup creates the user group and records its creation.
+func (s *UserGroupService) CreateUserGroup(ctx context.Context, g *influxdb.UserGroup) error {
+	if err := s.UserGroupService.CreateUserGroup(ctx, g); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditCreate,
+		OrgID:        g.OrgID,
+		ResourceType: influxdb.UserGroupsAuditResourceType,
+		ResourceID:   g.ID,
+	}, nil, g)
+	return nil
+}
+
+// UpdateUserGroup updates the user group and records the group before and after the update.
+func (s *UserGroupService) UpdateUserGroup(ctx context.Context, id influxdb.ID, upd influxdb.UserGroupUpdate) (*influxdb.UserGroup, error) {
+	before, err := s.UserGroupService.FindUserGroupByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	g, err := s.UserGroupService.UpdateUserGroup(ctx, id, upd)
+	if err != nil {
+		return nil, err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditUpdate,
+		OrgID:        g.OrgID,
+		ResourceType: influxdb.UserGroupsAuditResourceType,
+		ResourceID:   id,
+	}, before, g)
+	return g, nil
+}
+
+// DeleteUserGroup deletes the user group and records the deleted group.
+func (s *UserGroupService) DeleteUserGroup(ctx context.Context, id influxdb.ID) error {
+	before, err := s.UserGroupService.FindUserGroupByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.UserGroupService.DeleteUserGroup(ctx, id); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditDelete,
+		OrgID:        before.OrgID,
+		ResourceType: influxdb.UserGroupsAuditResourceType,
+		ResourceID:   id,
+	}, before, nil)
+	return nil
+}
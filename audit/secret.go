@@ -0,0 +1,79 @@
+package audit
+
+import (
+	"context"
+	"sort"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.SecretService = (*SecretService)(nil)
+
+// SecretService records the changes to secrets in the audit log. Only the
+// keys of the changed secrets are recorded, never their values.
+type SecretService struct {
+	influxdb.SecretService
+	r *Recorder
+}
+
+// NewSecretService returns a SecretService that records the changes made through s.
+func NewSecretService(r *Recorder, s influxdb.SecretService) *SecretService {
+	return &SecretService{
+		SecretService: s,
+		r:             r,
+	}
+}
+
+// PutSecret puts the secret and records the update of its key.
+func (s *SecretService) PutSecret(ctx context.Context, orgID influxdb.ID, k string, v string) error {
+	if err := s.SecretService.PutSecret(ctx, orgID, k, v); err != nil {
+		return err
+	}
+	s.recordKeys(ctx, influxdb.AuditUpdate, orgID, []string{k})
+	return nil
+}
+
+// PutSecrets replaces the secrets of the organization and records the update of their keys.
+func (s *SecretService) PutSecrets(ctx context.Context, orgID influxdb.ID, m map[string]string) error {
+	if err := s.SecretService.PutSecrets(ctx, orgID, m); err != nil {
+		return err
+	}
+	s.recordKeys(ctx, influxdb.AuditUpdate, orgID, keys(m))
+	return nil
+}
+
+// PatchSecrets updates the secrets and records the update of their keys.
+func (s *SecretService) PatchSecrets(ctx context.Context, orgID influxdb.ID, m map[string]string) error {
+	if err := s.SecretService.PatchSecrets(ctx, orgID, m); err != nil {
+		return err
+	}
+	s.recordKeys(ctx, influxdb.AuditUpdate, orgID, keys(m))
+	return nil
+}
+
+// DeleteSecret deletes the secrets and records the deletion of their keys.
+func (s *SecretService) DeleteSecret(ctx context.Context, orgID influxdb.ID, ks ...string) error {
+	if err := s.SecretService.DeleteSecret(ctx, orgID, ks...); err != nil {
+		return err
+	}
+	s.recordKeys(ctx, influxdb.AuditDelete, orgID, ks)
+	return nil
+}
+
+func (s *SecretService) recordKeys(ctx context.Context, action influxdb.AuditAction, orgID influxdb.ID, ks []string) {
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       action,
+		OrgID:        orgID,
+		ResourceType: influxdb.SecretsResourceType,
+		Changes:      ks,
+	}, nil, nil)
+}
+
+func keys(m map[string]string) []string {
+	ks := make([]string, 0, len(m))
+	for k := range m {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+	return ks
+}
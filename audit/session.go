@@ -0,0 +1,59 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.SessionService = (*SessionService)(nil)
+
+// SessionService records logins and logouts in the audit log.
+type SessionService struct {
+	influxdb.SessionService
+	r *Recorder
+}
+
+// NewSessionService returns a SessionService that records the sessions created and expired through s.
+func NewSessionService(r *Recorder, s influxdb.SessionService) *SessionService {
+	return &SessionService{
+		SessionService: s,
+		r:              r,
+	}
+}
+
+// CreateSession creates a session for the user and records the login of the user.
+func (s *SessionService) CreateSession(ctx context.Context, user string) (*influxdb.Session, error) {
+	sess, err := s.SessionService.CreateSession(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditLogin,
+		UserID:       sess.UserID,
+		SessionID:    sess.ID,
+		ResourceType: influxdb.UsersResourceType,
+		ResourceID:   sess.UserID,
+	}, nil, nil)
+	return sess, nil
+}
+
+// ExpireSession expires the session and records the logout of its user.
+func (s *SessionService) ExpireSession(ctx context.Context, key string) error {
+	// FindSession returns an expired session along with an error.
+	sess, _ := s.SessionService.FindSession(ctx, key)
+	if err := s.SessionService.ExpireSession(ctx, key); err != nil {
+		return err
+	}
+	if sess == nil {
+		return nil
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditLogout,
+		UserID:       sess.UserID,
+		SessionID:    sess.ID,
+		ResourceType: influxdb.UsersResourceType,
+		ResourceID:   sess.UserID,
+	}, nil, nil)
+	return nil
+}
@@ -0,0 +1,75 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.TaskService = (*TaskService)(nil)
+
+// TaskService records the changes to tasks in the audit log.
+type TaskService struct {
+	influxdb.TaskService
+	r *Recorder
+}
+
+// NewTaskService returns a TaskService that records the changes made through s.
+func NewTaskService(r *Recorder, s influxdb.TaskService) *TaskService {
+	return &TaskService{
+		TaskService: s,
+		r:           r,
+	}
+}
+
+// CreateTask creates the task and records its creation.
+func (s *TaskService) CreateTask(ctx context.Context, tc influxdb.TaskCreate) (*influxdb.Task, error) {
+	t, err := s.TaskService.CreateTask(ctx, tc)
+	if err != nil {
+		return nil, err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditCreate,
+		OrgID:        t.OrganizationID,
+		ResourceType: influxdb.TasksResourceType,
+		ResourceID:   t.ID,
+	}, nil, t)
+	return t, nil
+}
+
+// UpdateTask updates the task and records the task before and after the update.
+func (s *TaskService) UpdateTask(ctx context.Context, id influxdb.ID, upd influxdb.TaskUpdate) (*influxdb.Task, error) {
+	before, err := s.TaskService.FindTaskByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	t, err := s.TaskService.UpdateTask(ctx, id, upd)
+	if err != nil {
+		return nil, err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditUpdate,
+		OrgID:        t.OrganizationID,
+		ResourceType: influxdb.TasksResourceType,
+		ResourceID:   id,
+	}, before, t)
+	return t, nil
+}
+
+// DeleteTask deletes the task and records the deleted task.
+func (s *TaskService) DeleteTask(ctx context.Context, id influxdb.ID) error {
+	before, err := s.TaskService.FindTaskByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.TaskService.DeleteTask(ctx, id); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditDelete,
+		OrgID:        before.OrganizationID,
+		ResourceType: influxdb.TasksResourceType,
+		ResourceID:   id,
+	}, before, nil)
+	return nil
+}
@@ -0,0 +1,71 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.UserResourceMappingService = (*UserResourceMappingService)(nil)
+
+// UserResourceMappingService records the members and owners added to and
+// removed from resources in the audit log. The changes are recorded as
+// updates of the resource.
+type UserResourceMappingService struct {
+	influxdb.UserResourceMappingService
+	r *Recorder
+}
+
+// NewUserResourceMappingService returns a UserResourceMappingService that records the changes made through s.
+func NewUserResourceMappingService(r *Recorder, s influxdb.UserResourceMappingService) *UserResourceMappingService {
+	return &UserResourceMappingService{
+		UserResourceMappingService: s,
+		r:                          r,
+	}
+}
+
+// CreateUserResourceMapping creates the mapping and records it.
+func (s *UserResourceMappingService) CreateUserResourceMapping(ctx context.Context, m *influxdb.UserResourceMapping) error {
+	if err := s.UserResourceMappingService.CreateUserResourceMapping(ctx, m); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditUpdate,
+		OrgID:        mappingOrgID(m),
+		ResourceType: m.ResourceType,
+		ResourceID:   m.ResourceID,
+	}, nil, m)
+	return nil
+}
+
+// DeleteUserResourceMapping deletes the mapping and records the deleted mapping.
+func (s *UserResourceMappingService) DeleteUserResourceMapping(ctx context.Context, resourceID, userID influxdb.ID) error {
+	ms, _, err := s.UserResourceMappingService.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+		ResourceID: resourceID,
+		UserID:     userID,
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.UserResourceMappingService.DeleteUserResourceMapping(ctx, resourceID, userID); err != nil {
+		return err
+	}
+	for _, m := range ms {
+		s.r.record(ctx, influxdb.AuditEvent{
+			Action:       influxdb.AuditUpdate,
+			OrgID:        mappingOrgID(m),
+			ResourceType: m.ResourceType,
+			ResourceID:   m.ResourceID,
+		}, m, nil)
+	}
+	return nil
+}
+
+// mappingOrgID returns the organization of the mapping if it maps a user to
+// an organization.
+func mappingOrgID(m *influxdb.UserResourceMapping) influxdb.ID {
+	if m.ResourceType == influxdb.OrgsResourceType {
+		return m.ResourceID
+	}
+	return 0
+}
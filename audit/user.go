@@ -0,0 +1,128 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	_ influxdb.UserService      = (*UserService)(nil)
+	_ influxdb.PasswordsService = (*PasswordsService)(nil)
+)
+
+// UserService records the changes to users in the audit log.
+type UserService struct {
+	influxdb.UserService
+	r *Recorder
+}
+
+// NewUserService returns a UserService that records the changes made through s.
+func NewUserService(r *Recorder, s influxdb.UserService) *UserService {
+	return &UserService{
+		UserService: s,
+		r:           r,
+	}
+}
+
+// CreateUser creates the user and records its creation.
+func (s *UserService) CreateUser(ctx context.Context, u *influxdb.User) error {
+	if err := s.UserService.CreateUser(ctx, u); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditCreate,
+		ResourceType: influxdb.UsersResourceType,
+		ResourceID:   u.ID,
+	}, nil, u)
+	return nil
+}
+
+// UpdateUser updates the user and records the user before and after the update.
+func (s *UserService) UpdateUser(ctx context.Context, id influxdb.ID, upd influxdb.UserUpdate) (*influxdb.User, error) {
+	before, err := s.UserService.FindUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.UserService.UpdateUser(ctx, id, upd)
+	if err != nil {
+		return nil, err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditUpdate,
+		ResourceType: influxdb.UsersResourceType,
+		ResourceID:   id,
+	}, before, u)
+	return u, nil
+}
+
+// DeleteUser deletes the user and records the deleted user.
+func (s *UserService) DeleteUser(ctx context.Context, id influxdb.ID) error {
+	before, err := s.UserService.FindUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.UserService.DeleteUser(ctx, id); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditEvent{
+		Action:       influxdb.AuditDelete,
+		ResourceType: influxdb.UsersResourceType,
+		ResourceID:   id,
+	}, before, nil)
+	return nil
+}
+
+// PasswordsService records password changes and failed password logins in
+// the audit log. The passwords themselves are never recorded.
+type PasswordsService struct {
+	influxdb.PasswordsService
+	r *Recorder
+}
+
+// NewPasswordsService returns a PasswordsService that records the password changes and logins of s.
+func NewPasswordsService(r *Recorder, s influxdb.PasswordsService) *PasswordsService {
+	return &PasswordsService{
+		PasswordsService: s,
+		r:                r,
+	}
+}
+
+// SetPassword sets the password of the user and records the update of the user.
+func (s *PasswordsService) SetPassword(ctx context.Context, userID influxdb.ID, password string) error {
+	if err := s.PasswordsService.SetPassword(ctx, userID, password); err != nil {
+		return err
+	}
+	s.recordPasswordUpdate(ctx, userID)
+	return nil
+}
+
+// CompareAndSetPassword sets the password of the user and records the update of the user.
+func (s *PasswordsService) CompareAndSetPassword(ctx context.Context, userID influxdb.ID, old, new string) error {
+	if err := s.PasswordsService.CompareAndSetPassword(ctx, userID, old, new); err != nil {
+		return err
+	}
+	s.recordPasswordUpdate(ctx, userID)
+	return nil
+}
+
+// ComparePassword compares the password of the user and records a failed
+// login if it does not match, at most once a minute for a user and source.
+// Successful logins are recorded when their session is created.
+func (s *PasswordsService) ComparePassword(ctx context.Context, userID influxdb.ID, password string) error {
+	err := s.PasswordsService.ComparePassword(ctx, userID, password)
+	if err != nil {
+		s.r.recordFailedLogin(ctx, influxdb.AuditEvent{
+			Action:       influxdb.AuditLogin,
+			UserID:       userID,
+			ResourceType: influxdb.UsersResourceType,
+			ResourceID:   userID,
+			Error:        err.Error(),
+		})
+	}
+	return err
+}
+
+func (s *PasswordsService) recordPasswordUpdate(ctx context.Context, userID influxdb.ID) {
+	recordUserUpdate(ctx, s.r, userID, "password")
+}
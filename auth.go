@@ -3,6 +3,7 @@ package influxdb
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 // AuthorizationKind is returned by (*Authorization).Kind().
@@ -14,6 +15,13 @@ var ErrUnableToCreateToken = &Error{
 	Code: EInvalid,
 }
 
+// ErrAuthorizationExpired is returned when the token of an expired
+// authorization is used.
+var ErrAuthorizationExpired = &Error{
+	Msg:  "authorization has expired",
+	Code: EUnauthorized,
+}
+
 // Authorization is an authorization. 🎉
 type Authorization struct {
 	ID          ID           `json:"id"`
@@ -23,17 +31,62 @@ type Authorization struct {
 	OrgID       ID           `json:"orgID"`
 	UserID      ID           `json:"userID,omitempty"`
 	Permissions []Permission `json:"permissions"`
+	// ExpiresAt is when the token expires. Tokens with no expiry never expire.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	// LastUsedAt and LastUsedIP are when and from where the token was last
+	// used to authenticate a request.
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	LastUsedIP string     `json:"lastUsedIP,omitempty"`
+	// RateLimits are the rates at which the token may write and query.
+	RateLimits *RateLimits `json:"rateLimits,omitempty"`
 	CRUDLog
 }
 
 // AuthorizationUpdate is the authorization update request.
 type AuthorizationUpdate struct {
-	Status      *Status `json:"status,omitempty"`
-	Description *string `json:"description,omitempty"`
+	Status      *Status    `json:"status,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	// NeverExpires removes the expiry of the authorization. ExpiresAt must
+	// not be set with it.
+	NeverExpires bool        `json:"neverExpires,omitempty"`
+	RateLimits   *RateLimits `json:"rateLimits,omitempty"`
+}
+
+// Valid returns an error if the update both sets and removes the expiry.
+func (u *AuthorizationUpdate) Valid() error {
+	if u.NeverExpires && u.ExpiresAt != nil {
+		return &Error{
+			Msg:  "authorization update cannot both set and remove the expiry",
+			Code: EInvalid,
+		}
+	}
+	return nil
+}
+
+// AuthorizationRotation is the rotation of the token of an authorization.
+type AuthorizationRotation struct {
+	// GracePeriod is how long the rotated token remains valid.
+	GracePeriod time.Duration
+	// ExpiresAt is when the new token expires. It never expires if nil.
+	ExpiresAt *time.Time
 }
 
 // Valid ensures that the authorization is valid.
 func (a *Authorization) Valid() error {
+	if a.ExpiresAt != nil && !a.ExpiresAt.After(time.Now()) {
+		return &Error{
+			Msg:  "authorization expiry must be in the future",
+			Code: EInvalid,
+		}
+	}
+
+	if a.RateLimits != nil {
+		if err := a.RateLimits.Valid(); err != nil {
+			return err
+		}
+	}
+
 	for _, p := range a.Permissions {
 		if p.Resource.OrgID != nil && *p.Resource.OrgID != a.OrgID {
 			return &Error{
@@ -56,14 +109,27 @@ func (a *Authorization) Allowed(p Permission) bool {
 	return PermissionAllowed(p, a.Permissions)
 }
 
+// PermissionSet returns the permissions of the authorization if it is active.
+func (a *Authorization) PermissionSet() []Permission {
+	if !a.IsActive() {
+		return nil
+	}
+	return a.Permissions
+}
+
 // IsActive is a stub for idpe.
 func IsActive(a *Authorization) bool {
 	return a.IsActive()
 }
 
-// IsActive returns true if the authorization active.
+// IsActive returns true if the authorization active and not expired.
 func (a *Authorization) IsActive() bool {
-	return a.Status == Active
+	return a.Status == Active && !a.IsExpired(time.Now())
+}
+
+// IsExpired returns true if the authorization has expired at now.
+func (a *Authorization) IsExpired(now time.Time) bool {
+	return a.ExpiresAt != nil && !now.Before(*a.ExpiresAt)
 }
 
 // GetUserID returns the user id.
@@ -120,3 +186,52 @@ type AuthorizationFilter struct {
 	OrgID *ID
 	Org   *string
 }
+
+// RotateAuthorization creates an authorization with the permissions of the
+// authorization of the id and a new token. The token of the rotated
+// authorization expires once the grace period of the rotation has passed.
+// The new authorization is deleted if the rotated one cannot be updated.
+func RotateAuthorization(ctx context.Context, s AuthorizationService, id ID, r AuthorizationRotation) (*Authorization, error) {
+	if r.GracePeriod < 0 {
+		return nil, &Error{
+			Msg:  "rotation grace period must not be negative",
+			Code: EInvalid,
+		}
+	}
+
+	old, err := s.FindAuthorizationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if old.IsExpired(now) {
+		return nil, &Error{
+			Msg:  "cannot rotate an expired authorization",
+			Code: EInvalid,
+		}
+	}
+
+	a := &Authorization{
+		Status:      old.Status,
+		Description: old.Description,
+		OrgID:       old.OrgID,
+		UserID:      old.UserID,
+		Permissions: old.Permissions,
+		ExpiresAt:   r.ExpiresAt,
+		RateLimits:  old.RateLimits,
+	}
+	if err := s.CreateAuthorization(ctx, a); err != nil {
+		return nil, err
+	}
+
+	expiresAt := now.Add(r.GracePeriod)
+	if old.ExpiresAt == nil || expiresAt.Before(*old.ExpiresAt) {
+		if _, err := s.UpdateAuthorization(ctx, id, &AuthorizationUpdate{ExpiresAt: &expiresAt}); err != nil {
+			// Do not leave a second token around when the old one was not
+			// rotated.
+			_ = s.DeleteAuthorization(ctx, a.ID)
+			return nil, err
+		}
+	}
+	return a, nil
+}
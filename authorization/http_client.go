@@ -3,6 +3,7 @@ package authorization
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/pkg/httpc"
@@ -98,6 +99,26 @@ func (s *AuthorizationClientService) UpdateAuthorization(ctx context.Context, id
 	return res.toInfluxdb(), nil
 }
 
+// RotateAuthorization creates an authorization with a new token, and the token of
+// the authorization of the id expires once the grace period has passed.
+func (s *AuthorizationClientService) RotateAuthorization(ctx context.Context, id influxdb.ID, rot influxdb.AuthorizationRotation) (*influxdb.Authorization, error) {
+	req := rotateAuthorizationRequest{
+		GracePeriodSeconds: int64(rot.GracePeriod / time.Second),
+		ExpiresAt:          rot.ExpiresAt,
+	}
+
+	var res authResponse
+	err := s.Client.
+		PostJSON(req, prefixAuthorization, id.String(), "rotate").
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.toInfluxdb(), nil
+}
+
 // DeleteAuthorization removes a authorization by id.
 func (s *AuthorizationClientService) DeleteAuthorization(ctx context.Context, id influxdb.ID) error {
 	return s.Client.
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 
@@ -57,6 +58,7 @@ func NewHTTPAuthHandler(log *zap.Logger, authService influxdb.AuthorizationServi
 			r.Get("/", h.handleGetAuthorization)
 			r.Patch("/", h.handleUpdateAuthorization)
 			r.Delete("/", h.handleDeleteAuthorization)
+			r.Post("/rotate", h.handleRotateAuthorization)
 		})
 	})
 
@@ -131,11 +133,13 @@ type postAuthorizationRequest struct {
 	UserID      *influxdb.ID          `json:"userID,omitempty"`
 	Description string                `json:"description"`
 	Permissions []influxdb.Permission `json:"permissions"`
+	ExpiresAt   *time.Time            `json:"expiresAt,omitempty"`
+	RateLimits  *influxdb.RateLimits  `json:"rateLimits,omitempty"`
 }
 
 type authResponse struct {
 	ID          influxdb.ID          `json:"id"`
-	Token       string               `json:"token"`
+	Token       string               `json:"token,omitempty"`
 	Status      influxdb.Status      `json:"status"`
 	Description string               `json:"description"`
 	OrgID       influxdb.ID          `json:"orgID"`
@@ -144,6 +148,10 @@ type authResponse struct {
 	User        string               `json:"user"`
 	Permissions []permissionResponse `json:"permissions"`
 	Links       map[string]string    `json:"links"`
+	ExpiresAt   *time.Time           `json:"expiresAt,omitempty"`
+	LastUsedAt  *time.Time           `json:"lastUsedAt,omitempty"`
+	LastUsedIP  string               `json:"lastUsedIP,omitempty"`
+	RateLimits  *influxdb.RateLimits `json:"rateLimits,omitempty"`
 	CreatedAt   time.Time            `json:"createdAt"`
 	UpdatedAt   time.Time            `json:"updatedAt"`
 }
@@ -176,8 +184,12 @@ func (h *AuthHandler) newAuthResponse(ctx context.Context, a *influxdb.Authoriza
 			"self": fmt.Sprintf("/api/v2/authorizations/%s", a.ID),
 			"user": fmt.Sprintf("/api/v2/users/%s", a.UserID),
 		},
-		CreatedAt: a.CreatedAt,
-		UpdatedAt: a.UpdatedAt,
+		ExpiresAt:  a.ExpiresAt,
+		LastUsedAt: a.LastUsedAt,
+		LastUsedIP: a.LastUsedIP,
+		RateLimits: a.RateLimits,
+		CreatedAt:  a.CreatedAt,
+		UpdatedAt:  a.UpdatedAt,
 	}
 	return res, nil
 }
@@ -189,6 +201,8 @@ func (p *postAuthorizationRequest) toInfluxdb(userID influxdb.ID) *influxdb.Auth
 		Description: p.Description,
 		Permissions: p.Permissions,
 		UserID:      userID,
+		ExpiresAt:   p.ExpiresAt,
+		RateLimits:  p.RateLimits,
 	}
 }
 
@@ -200,13 +214,17 @@ func (a *authResponse) toInfluxdb() *influxdb.Authorization {
 		Description: a.Description,
 		OrgID:       a.OrgID,
 		UserID:      a.UserID,
+		ExpiresAt:   a.ExpiresAt,
+		LastUsedAt:  a.LastUsedAt,
+		LastUsedIP:  a.LastUsedIP,
+		RateLimits:  a.RateLimits,
 		CRUDLog: influxdb.CRUDLog{
 			CreatedAt: a.CreatedAt,
 			UpdatedAt: a.UpdatedAt,
 		},
 	}
 	for _, p := range a.Permissions {
-		res.Permissions = append(res.Permissions, influxdb.Permission{Action: p.Action, Resource: p.Resource.Resource})
+		res.Permissions = append(res.Permissions, influxdb.Permission{Action: p.Action, Resource: p.Resource.Resource, Predicate: p.Predicate})
 	}
 	return res
 }
@@ -232,6 +250,8 @@ func newPostAuthorizationRequest(a *influxdb.Authorization) (*postAuthorizationR
 		Description: a.Description,
 		Permissions: a.Permissions,
 		Status:      a.Status,
+		ExpiresAt:   a.ExpiresAt,
+		RateLimits:  a.RateLimits,
 	}
 
 	if a.UserID.Valid() {
@@ -286,8 +306,9 @@ func (p *postAuthorizationRequest) Validate() error {
 }
 
 type permissionResponse struct {
-	Action   influxdb.Action  `json:"action"`
-	Resource resourceResponse `json:"resource"`
+	Action    influxdb.Action  `json:"action"`
+	Resource  resourceResponse `json:"resource"`
+	Predicate string           `json:"predicate,omitempty"`
 }
 
 type resourceResponse struct {
@@ -304,6 +325,7 @@ func newPermissionsResponse(ctx context.Context, ps []influxdb.Permission, svc i
 			Resource: resourceResponse{
 				Resource: p.Resource,
 			},
+			Predicate: p.Predicate,
 		}
 
 		if p.Resource.ID != nil {
@@ -548,6 +570,71 @@ func decodeUpdateAuthorizationRequest(ctx context.Context, r *http.Request) (*up
 	}, nil
 }
 
+// handleRotateAuthorization is the HTTP handler for the POST /api/v2/authorizations/:id/rotate route.
+// It creates an authorization with a new token, and the token of the authorization
+// of the id expires once the grace period has passed.
+func (h *AuthHandler) handleRotateAuthorization(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req, err := decodeRotateAuthorizationRequest(ctx, r)
+	if err != nil {
+		h.log.Info("Failed to decode request", zap.String("handler", "rotateAuthorization"), zap.Error(err))
+		h.api.Err(w, err)
+		return
+	}
+
+	a, err := influxdb.RotateAuthorization(ctx, h.authSvc, req.ID, req.toInfluxdb())
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	ps, err := newPermissionsResponse(ctx, a.Permissions, h.lookupService)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	h.log.Debug("Auth rotated", zap.String("authID", req.ID.String()), zap.String("auth", fmt.Sprint(a)))
+
+	resp, err := h.newAuthResponse(ctx, a, ps)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	h.api.Respond(w, http.StatusCreated, resp)
+}
+
+type rotateAuthorizationRequest struct {
+	ID                 influxdb.ID `json:"-"`
+	GracePeriodSeconds int64       `json:"gracePeriodSeconds"`
+	ExpiresAt          *time.Time  `json:"expiresAt,omitempty"`
+}
+
+func (r *rotateAuthorizationRequest) toInfluxdb() influxdb.AuthorizationRotation {
+	return influxdb.AuthorizationRotation{
+		GracePeriod: time.Duration(r.GracePeriodSeconds) * time.Second,
+		ExpiresAt:   r.ExpiresAt,
+	}
+}
+
+func decodeRotateAuthorizationRequest(ctx context.Context, r *http.Request) (*rotateAuthorizationRequest, error) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		return nil, err
+	}
+
+	req := &rotateAuthorizationRequest{ID: *id}
+	// The body is optional, the token expires immediately without one.
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil && err != io.EOF {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid json structure",
+			Err:  err,
+		}
+	}
+	return req, nil
+}
+
 // handleDeleteAuthorization is the HTTP handler for the DELETE /api/v2/authorizations/:id route.
 func (h *AuthHandler) handleDeleteAuthorization(w http.ResponseWriter, r *http.Request) {
 	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
@@ -0,0 +1,112 @@
+package authorization
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+	"go.uber.org/zap"
+)
+
+// DefaultLastUsedFlushInterval is the interval at which the last use of
+// the authorizations is written to the store.
+const DefaultLastUsedFlushInterval = time.Minute
+
+type lastUse struct {
+	at time.Time
+	ip string
+}
+
+// LastUsedTracker tracks when and from where authorizations were last used.
+// The uses are kept in memory and written to the store in batches, rather
+// than written on every request.
+type LastUsedTracker struct {
+	log   *zap.Logger
+	store *Store
+
+	mu      sync.Mutex
+	pending map[influxdb.ID]lastUse
+}
+
+// NewLastUsedTracker returns a LastUsedTracker of the authorizations of the store.
+func NewLastUsedTracker(log *zap.Logger, st *Store) *LastUsedTracker {
+	return &LastUsedTracker{
+		log:     log,
+		store:   st,
+		pending: make(map[influxdb.ID]lastUse),
+	}
+}
+
+// Track records the use of the authorization at the time from the ip.
+func (t *LastUsedTracker) Track(id influxdb.ID, at time.Time, ip string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if u, ok := t.pending[id]; !ok || u.at.Before(at) {
+		t.pending[id] = lastUse{at: at, ip: ip}
+	}
+}
+
+// Run flushes the uses every interval until ctx is done.
+func (t *LastUsedTracker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := t.Flush(ctx); err != nil && ctx.Err() == nil {
+				t.log.Error("Failed to flush last use of authorizations", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Flush writes the uses tracked since the last flush to the store in a
+// single transaction. Uses of deleted authorizations are dropped.
+func (t *LastUsedTracker) Flush(ctx context.Context) error {
+	t.mu.Lock()
+	pending := t.pending
+	t.pending = make(map[influxdb.ID]lastUse)
+	t.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	err := t.store.Update(ctx, func(tx kv.Tx) error {
+		for id, u := range pending {
+			a, err := t.store.GetAuthorizationByID(ctx, tx, id)
+			if err == ErrAuthNotFound {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if a.LastUsedAt != nil && !a.LastUsedAt.Before(u.at) {
+				continue
+			}
+
+			at := u.at
+			a.LastUsedAt, a.LastUsedIP = &at, u.ip
+			if _, err := t.store.UpdateAuthorization(ctx, tx, id, a); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		// Keep the uses for the next flush, unless newer uses were tracked.
+		t.mu.Lock()
+		for id, u := range pending {
+			if p, ok := t.pending[id]; !ok || p.at.Before(u.at) {
+				t.pending[id] = u
+			}
+		}
+		t.mu.Unlock()
+	}
+	return err
+}
@@ -0,0 +1,201 @@
+package authorization_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorization"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/tenant"
+	"go.uber.org/zap/zaptest"
+)
+
+func newTestAuthorization(t *testing.T) (*authorization.Store, influxdb.AuthorizationService, *influxdb.Authorization) {
+	t.Helper()
+	ctx := context.Background()
+	s := inmem.NewKVStore()
+
+	st, err := tenant.NewStore(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := tenant.NewService(st)
+	store, err := authorization.NewStore(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := authorization.NewService(store, ts)
+
+	u := &influxdb.User{Name: "marty"}
+	if err := ts.CreateUser(ctx, u); err != nil {
+		t.Fatal(err)
+	}
+	o := &influxdb.Organization{Name: "hill-valley"}
+	if err := ts.CreateOrganization(ctx, o); err != nil {
+		t.Fatal(err)
+	}
+	a := &influxdb.Authorization{
+		UserID: u.ID,
+		OrgID:  o.ID,
+		Permissions: []influxdb.Permission{{
+			Action:   influxdb.ReadAction,
+			Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: &o.ID},
+		}},
+	}
+	if err := svc.CreateAuthorization(ctx, a); err != nil {
+		t.Fatal(err)
+	}
+	return store, svc, a
+}
+
+func TestLastUsedTracker_Flush(t *testing.T) {
+	ctx := context.Background()
+	store, svc, a := newTestAuthorization(t)
+	tracker := authorization.NewLastUsedTracker(zaptest.NewLogger(t), store)
+
+	t0 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker.Track(a.ID, t0.Add(time.Minute), "10.0.0.2")
+	tracker.Track(a.ID, t0, "10.0.0.1")
+	tracker.Track(influxdb.ID(1), t0, "10.0.0.3")
+	if err := tracker.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := svc.FindAuthorizationByID(ctx, a.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.LastUsedAt == nil || !got.LastUsedAt.Equal(t0.Add(time.Minute)) || got.LastUsedIP != "10.0.0.2" {
+		t.Errorf("unexpected last use %v from %q", got.LastUsedAt, got.LastUsedIP)
+	}
+
+	// Uses older than the stored use are not written.
+	tracker.Track(a.ID, t0, "10.0.0.1")
+	if err := tracker.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	got, err = svc.FindAuthorizationByID(ctx, a.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.LastUsedIP != "10.0.0.2" {
+		t.Errorf("expected last use from 10.0.0.2 to be kept, got %q", got.LastUsedIP)
+	}
+}
+
+func TestService_ExpiredAuthorization(t *testing.T) {
+	ctx := context.Background()
+	_, svc, a := newTestAuthorization(t)
+
+	past := time.Now().Add(-time.Hour)
+	if err := svc.CreateAuthorization(ctx, &influxdb.Authorization{
+		UserID:      a.UserID,
+		OrgID:       a.OrgID,
+		Permissions: a.Permissions,
+		ExpiresAt:   &past,
+	}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected invalid creating expired authorization, got %v", err)
+	}
+
+	if _, err := svc.UpdateAuthorization(ctx, a.ID, &influxdb.AuthorizationUpdate{ExpiresAt: &past}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindAuthorizationByToken(ctx, a.Token); err != influxdb.ErrAuthorizationExpired {
+		t.Errorf("expected expired error finding token, got %v", err)
+	}
+	got, err := svc.FindAuthorizationByID(ctx, a.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.IsActive() {
+		t.Error("expected expired authorization to be inactive")
+	}
+}
+
+func TestRotateAuthorization(t *testing.T) {
+	ctx := context.Background()
+	_, svc, a := newTestAuthorization(t)
+
+	rotated, err := influxdb.RotateAuthorization(ctx, svc, a.ID, influxdb.AuthorizationRotation{GracePeriod: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotated.ID == a.ID || rotated.Token == a.Token {
+		t.Fatal("expected rotation to create an authorization with a new token")
+	}
+	if len(rotated.Permissions) != len(a.Permissions) || rotated.ExpiresAt != nil {
+		t.Errorf("unexpected rotated authorization %+v", rotated)
+	}
+
+	// The old token is valid for the grace period.
+	old, err := svc.FindAuthorizationByToken(ctx, a.Token)
+	if err != nil {
+		t.Fatalf("expected old token to be valid during grace period: %v", err)
+	}
+	if old.ExpiresAt == nil || old.ExpiresAt.After(time.Now().Add(time.Hour)) {
+		t.Errorf("expected old token to expire within the grace period, got %v", old.ExpiresAt)
+	}
+
+	// Without a grace period the old token expires at once.
+	if _, err := influxdb.RotateAuthorization(ctx, svc, rotated.ID, influxdb.AuthorizationRotation{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindAuthorizationByToken(ctx, rotated.Token); err != influxdb.ErrAuthorizationExpired {
+		t.Errorf("expected rotated token to be expired, got %v", err)
+	}
+	if _, err := influxdb.RotateAuthorization(ctx, svc, rotated.ID, influxdb.AuthorizationRotation{}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected invalid rotating expired authorization, got %v", err)
+	}
+}
+
+func TestRotateAuthorization_rollback(t *testing.T) {
+	ctx := context.Background()
+	svc := mock.NewAuthorizationService()
+	svc.FindAuthorizationByIDFn = func(_ context.Context, id influxdb.ID) (*influxdb.Authorization, error) {
+		return &influxdb.Authorization{ID: id, OrgID: 1, UserID: 1}, nil
+	}
+	svc.CreateAuthorizationFn = func(_ context.Context, a *influxdb.Authorization) error {
+		a.ID = 2
+		return nil
+	}
+	svc.UpdateAuthorizationFn = func(context.Context, influxdb.ID, *influxdb.AuthorizationUpdate) (*influxdb.Authorization, error) {
+		return nil, &influxdb.Error{Code: influxdb.EInternal, Msg: "disk full"}
+	}
+	var deleted []influxdb.ID
+	svc.DeleteAuthorizationFn = func(_ context.Context, id influxdb.ID) error {
+		deleted = append(deleted, id)
+		return nil
+	}
+
+	if _, err := influxdb.RotateAuthorization(ctx, svc, 1, influxdb.AuthorizationRotation{}); influxdb.ErrorCode(err) != influxdb.EInternal {
+		t.Fatalf("expected the update error, got %v", err)
+	}
+	if len(deleted) != 1 || deleted[0] != 2 {
+		t.Errorf("expected the new authorization to be deleted, got %v", deleted)
+	}
+}
+
+func TestService_NeverExpires(t *testing.T) {
+	ctx := context.Background()
+	_, svc, a := newTestAuthorization(t)
+
+	future := time.Now().Add(time.Hour)
+	if _, err := svc.UpdateAuthorization(ctx, a.ID, &influxdb.AuthorizationUpdate{ExpiresAt: &future}); err != nil {
+		t.Fatal(err)
+	}
+	_, err := svc.UpdateAuthorization(ctx, a.ID, &influxdb.AuthorizationUpdate{ExpiresAt: &future, NeverExpires: true})
+	if influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected invalid setting and removing the expiry, got %v", err)
+	}
+
+	got, err := svc.UpdateAuthorization(ctx, a.ID, &influxdb.AuthorizationUpdate{NeverExpires: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ExpiresAt != nil {
+		t.Errorf("expected the expiry to be removed, got %v", got.ExpiresAt)
+	}
+}
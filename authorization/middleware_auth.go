@@ -32,6 +32,11 @@ func (s *AuthedAuthorizationService) CreateAuthorization(ctx context.Context, a
 	if err := authorizer.VerifyPermissions(ctx, a.Permissions); err != nil {
 		return err
 	}
+	if a.RateLimits != nil {
+		if _, _, err := authorizer.AuthorizeWriteOrg(ctx, a.OrgID); err != nil {
+			return err
+		}
+	}
 
 	return s.s.CreateAuthorization(ctx, a)
 }
@@ -85,6 +90,12 @@ func (s *AuthedAuthorizationService) UpdateAuthorization(ctx context.Context, id
 	if _, _, err := authorizer.AuthorizeWriteResource(ctx, influxdb.UsersResourceType, a.UserID); err != nil {
 		return nil, err
 	}
+	// Only the owners of the org, and operators, may change the rates of its tokens.
+	if upd.RateLimits != nil {
+		if _, _, err := authorizer.AuthorizeWriteOrg(ctx, a.OrgID); err != nil {
+			return nil, err
+		}
+	}
 	return s.s.UpdateAuthorization(ctx, id, upd)
 }
 
@@ -106,6 +106,10 @@ func (s *Service) FindAuthorizationByToken(ctx context.Context, n string) (*infl
 		return nil, err
 	}
 
+	if a.IsExpired(time.Now()) {
+		return nil, influxdb.ErrAuthorizationExpired
+	}
+
 	return a, nil
 }
 
@@ -172,6 +176,10 @@ func (s *Service) FindAuthorizations(ctx context.Context, filter influxdb.Author
 
 // UpdateAuthorization updates the status and description if available.
 func (s *Service) UpdateAuthorization(ctx context.Context, id influxdb.ID, upd *influxdb.AuthorizationUpdate) (*influxdb.Authorization, error) {
+	if err := upd.Valid(); err != nil {
+		return nil, err
+	}
+
 	var auth *influxdb.Authorization
 	err := s.store.View(ctx, func(tx kv.Tx) error {
 		a, e := s.store.GetAuthorizationByID(ctx, tx, id)
@@ -195,6 +203,18 @@ func (s *Service) UpdateAuthorization(ctx context.Context, id influxdb.ID, upd *
 	if upd.Description != nil {
 		auth.Description = *upd.Description
 	}
+	if upd.ExpiresAt != nil {
+		auth.ExpiresAt = upd.ExpiresAt
+	}
+	if upd.NeverExpires {
+		auth.ExpiresAt = nil
+	}
+	if upd.RateLimits != nil {
+		if err := upd.RateLimits.Valid(); err != nil {
+			return nil, err
+		}
+		auth.RateLimits = upd.RateLimits
+	}
 
 	auth.SetUpdatedAt(time.Now())
 
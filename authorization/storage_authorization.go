@@ -2,18 +2,12 @@ package authorization
 
 import (
 	"context"
-	"encoding/json"
 
-	"github.com/buger/jsonparser"
 	influxdb "github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/kv"
 	jsonp "github.com/influxdata/influxdb/v2/pkg/jsonparser"
 )
 
-func authIndexKey(n string) []byte {
-	return []byte(n)
-}
-
 func authIndexBucket(tx kv.Tx) (kv.Bucket, error) {
 	b, err := tx.Bucket([]byte(authIndex))
 	if err != nil {
@@ -23,31 +17,6 @@ func authIndexBucket(tx kv.Tx) (kv.Bucket, error) {
 	return b, nil
 }
 
-func encodeAuthorization(a *influxdb.Authorization) ([]byte, error) {
-	switch a.Status {
-	case influxdb.Active, influxdb.Inactive:
-	case "":
-		a.Status = influxdb.Active
-	default:
-		return nil, &influxdb.Error{
-			Code: influxdb.EInvalid,
-			Msg:  "unknown authorization status",
-		}
-	}
-
-	return json.Marshal(a)
-}
-
-func decodeAuthorization(b []byte, a *influxdb.Authorization) error {
-	if err := json.Unmarshal(b, a); err != nil {
-		return err
-	}
-	if a.Status == "" {
-		a.Status = influxdb.Active
-	}
-	return nil
-}
-
 // CreateAuthorization takes an Authorization object and saves it in storage using its token
 // using its token property as an index
 func (s *Store) CreateAuthorization(ctx context.Context, tx kv.Tx, a *influxdb.Authorization) error {
@@ -70,43 +39,11 @@ func (s *Store) CreateAuthorization(ctx context.Context, tx kv.Tx, a *influxdb.A
 		return ErrTokenAlreadyExistsError
 	}
 
-	v, err := encodeAuthorization(a)
-	if err != nil {
-		return &influxdb.Error{
-			Code: influxdb.EInvalid,
-			Err:  err,
-		}
-	}
-
-	encodedID, err := a.ID.Encode()
-	if err != nil {
+	if _, err := a.ID.Encode(); err != nil {
 		return ErrInvalidAuthIDError(err)
 	}
 
-	idx, err := authIndexBucket(tx)
-	if err != nil {
-		return err
-	}
-
-	if err := idx.Put(authIndexKey(a.Token), encodedID); err != nil {
-		return &influxdb.Error{
-			Code: influxdb.EInternal,
-			Err:  err,
-		}
-	}
-
-	b, err := tx.Bucket(authBucket)
-	if err != nil {
-		return err
-	}
-
-	if err := b.Put(encodedID, v); err != nil {
-		return &influxdb.Error{
-			Err: err,
-		}
-	}
-
-	return nil
+	return kv.PutAuthorizationRecord(ctx, tx, a)
 }
 
 // GetAuthorization gets an authorization by its ID from the auth bucket in kv
@@ -131,7 +68,7 @@ func (s *Store) GetAuthorizationByID(ctx context.Context, tx kv.Tx, id influxdb.
 	}
 
 	a := &influxdb.Authorization{}
-	if err := decodeAuthorization(v, a); err != nil {
+	if err := kv.DecodeAuthorization(v, a); err != nil {
 		return nil, &influxdb.Error{
 			Code: influxdb.EInvalid,
 			Err:  err,
@@ -141,38 +78,46 @@ func (s *Store) GetAuthorizationByID(ctx context.Context, tx kv.Tx, id influxdb.
 	return a, nil
 }
 
+// GetAuthorizationByToken gets an authorization by its token. Only the hash of
+// the token is stored, so the token is compared to the hashed tokens of the
+// authorizations indexed by its prefix.
 func (s *Store) GetAuthorizationByToken(ctx context.Context, tx kv.Tx, token string) (*influxdb.Authorization, error) {
-	idx, err := authIndexBucket(tx)
+	id, err := kv.FindAuthorizationIDByToken(ctx, tx, token)
 	if err != nil {
 		return nil, err
 	}
 
-	// use the token to look up the authorization's ID
-	idKey, err := idx.Get(authIndexKey(token))
-	if kv.IsNotFound(err) {
-		return nil, &influxdb.Error{
-			Code: influxdb.ENotFound,
-			Msg:  "authorization not found",
-		}
-	}
-
-	var id influxdb.ID
-	if err := id.Decode(idKey); err != nil {
-		return nil, &influxdb.Error{
-			Code: influxdb.EInvalid,
-			Err:  err,
-		}
+	a, err := s.GetAuthorizationByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
 	}
-
-	return s.GetAuthorizationByID(ctx, tx, id)
+	a.Token = token
+	return a, nil
 }
 
 // ListAuthorizations returns all the authorizations matching a set of FindOptions. This function is used for
 // FindAuthorizationByID, FindAuthorizationByToken, and FindAuthorizations in the AuthorizationService implementation
 func (s *Store) ListAuthorizations(ctx context.Context, tx kv.Tx, f influxdb.AuthorizationFilter) ([]*influxdb.Authorization, error) {
 	var as []*influxdb.Authorization
-	pred := authorizationsPredicateFn(f)
 	filterFn := filterAuthorizationsFn(f)
+
+	// only the hash of the token is stored, so the authorization is found
+	// with the token index.
+	if f.Token != nil {
+		a, err := s.GetAuthorizationByToken(ctx, tx, *f.Token)
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return as, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if filterFn(a) {
+			as = append(as, a)
+		}
+		return as, nil
+	}
+
+	pred := authorizationsPredicateFn(f)
 	err := s.forEachAuthorization(ctx, tx, pred, func(a *influxdb.Authorization) bool {
 		if filterFn(a) {
 			as = append(as, a)
@@ -209,7 +154,7 @@ func (s *Store) forEachAuthorization(ctx context.Context, tx kv.Tx, pred kv.Curs
 			Permissions: make([]influxdb.Permission, 64),
 		}
 
-		if err := decodeAuthorization(v, a); err != nil {
+		if err := kv.DecodeAuthorization(v, a); err != nil {
 			return err
 		}
 		if !fn(a) {
@@ -222,112 +167,30 @@ func (s *Store) forEachAuthorization(ctx context.Context, tx kv.Tx, pred kv.Curs
 
 // UpdateAuthorization updates the status and description only of an authorization
 func (s *Store) UpdateAuthorization(ctx context.Context, tx kv.Tx, id influxdb.ID, a *influxdb.Authorization) (*influxdb.Authorization, error) {
-	v, err := encodeAuthorization(a)
-	if err != nil {
-		return nil, &influxdb.Error{
-			Code: influxdb.EInvalid,
-			Err:  err,
-		}
-	}
-
-	encodedID, err := a.ID.Encode()
-	if err != nil {
-		return nil, &influxdb.Error{
-			Code: influxdb.ENotFound,
-			Err:  err,
-		}
-	}
-
-	idx, err := authIndexBucket(tx)
-	if err != nil {
+	if err := kv.PutAuthorizationRecord(ctx, tx, a); err != nil {
 		return nil, err
 	}
 
-	if err := idx.Put(authIndexKey(a.Token), encodedID); err != nil {
-		return nil, &influxdb.Error{
-			Code: influxdb.EInternal,
-			Err:  err,
-		}
-	}
-
-	b, err := tx.Bucket(authBucket)
-	if err != nil {
-		return nil, err
-	}
-
-	if err := b.Put(encodedID, v); err != nil {
-		return nil, &influxdb.Error{
-			Err: err,
-		}
-	}
-
 	return a, nil
-
 }
 
 // DeleteAuthorization removes an authorization from storage
 func (s *Store) DeleteAuthorization(ctx context.Context, tx kv.Tx, id influxdb.ID) error {
-	a, err := s.GetAuthorizationByID(ctx, tx, id)
-	if err != nil {
-		return err
-	}
-
-	encodedID, err := id.Encode()
-	if err != nil {
-		return ErrInvalidAuthID
-	}
-
-	idx, err := authIndexBucket(tx)
-	if err != nil {
-		return err
-	}
-
-	b, err := tx.Bucket(authBucket)
-	if err != nil {
-		return err
-	}
-
-	if err := idx.Delete([]byte(a.Token)); err != nil {
-		return ErrInternalServiceError(err)
-	}
-
-	if err := b.Delete(encodedID); err != nil {
-		return ErrInternalServiceError(err)
-	}
-
-	return nil
+	return kv.DeleteAuthorizationRecord(ctx, tx, id)
 }
 
 func (s *Store) uniqueAuthToken(ctx context.Context, tx kv.Tx, a *influxdb.Authorization) error {
-	err := unique(ctx, tx, authIndex, authIndexKey(a.Token))
-	if err == kv.NotUniqueError {
+	_, err := s.GetAuthorizationByToken(ctx, tx, a.Token)
+	if err == nil {
 		// by returning a generic error we are trying to hide when
 		// a token is non-unique.
 		return influxdb.ErrUnableToCreateToken
 	}
-	// otherwise, this is some sort of internal server error and we
-	// should provide some debugging information.
-	return err
-}
-
-func unique(ctx context.Context, tx kv.Tx, indexBucket, indexKey []byte) error {
-	bucket, err := tx.Bucket(indexBucket)
-	if err != nil {
-		return kv.UnexpectedIndexError(err)
-	}
-
-	_, err = bucket.Get(indexKey)
-	// if not found then this token is unique.
-	if kv.IsNotFound(err) {
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
 		return nil
 	}
-
-	// no error means this is not unique
-	if err == nil {
-		return kv.NotUniqueError
-	}
-
-	// any other error is some sort of internal server error
+	// otherwise, this is some sort of internal server error and we
+	// should provide some debugging information.
 	return kv.UnexpectedIndexError(err)
 }
 
@@ -372,18 +235,6 @@ func authorizationsPredicateFn(f influxdb.AuthorizationFilter) kv.CursorPredicat
 		}
 	}
 
-	if f.Token != nil {
-		exp := *f.Token
-		return func(_, value []byte) bool {
-			// it is assumed that token never has escaped string data
-			got, _, _, err := jsonparser.Get(value, "token")
-			if err != nil {
-				return true
-			}
-			return string(got) == exp
-		}
-	}
-
 	var pred kv.CursorPredicateFunc
 	if f.OrgID != nil {
 		exp := *f.OrgID
@@ -417,12 +268,6 @@ func filterAuthorizationsFn(filter influxdb.AuthorizationFilter) func(a *influxd
 		}
 	}
 
-	if filter.Token != nil {
-		return func(a *influxdb.Authorization) bool {
-			return a.Token == *filter.Token
-		}
-	}
-
 	// Filter by org and user
 	if filter.OrgID != nil && filter.UserID != nil {
 		return func(a *influxdb.Authorization) bool {
@@ -51,11 +51,11 @@ func TestAuth(t *testing.T) {
 					t.Fatalf("expected 10 authorizations, got: %d", len(auths))
 				}
 
+				// tokens are only stored hashed
 				expected := []*influxdb.Authorization{}
 				for i := 1; i <= 10; i++ {
 					expected = append(expected, &influxdb.Authorization{
 						ID:     influxdb.ID(i),
-						Token:  fmt.Sprintf("randomtoken%d", i),
 						OrgID:  influxdb.ID(i),
 						UserID: influxdb.ID(i),
 						Status: "active",
@@ -65,6 +65,36 @@ func TestAuth(t *testing.T) {
 					t.Fatalf("expected identical authorizations: \n%+v\n%+v", auths, expected)
 				}
 
+				// authorizations are found by their tokens
+				a, err := store.GetAuthorizationByToken(context.Background(), tx, "randomtoken3")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if a.ID != influxdb.ID(3) || a.Token != "randomtoken3" {
+					t.Fatalf("unexpected authorization found by token: %+v", a)
+				}
+				if _, err := store.GetAuthorizationByToken(context.Background(), tx, "randomtoken11"); influxdb.ErrorCode(err) != influxdb.ENotFound {
+					t.Fatalf("expected not found for unknown token, got %v", err)
+				}
+
+				// and listed by them with the token index
+				token := "randomtoken3"
+				auths, err = store.ListAuthorizations(context.Background(), tx, influxdb.AuthorizationFilter{Token: &token})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(auths) != 1 || auths[0].ID != influxdb.ID(3) {
+					t.Fatalf("unexpected authorizations listed by token: %+v", auths)
+				}
+				token = "randomtoken11"
+				auths, err = store.ListAuthorizations(context.Background(), tx, influxdb.AuthorizationFilter{Token: &token})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(auths) != 0 {
+					t.Fatalf("unexpected authorizations listed by unknown token: %+v", auths)
+				}
+
 				// should not be able to create two authorizations with identical tokens
 				err = store.CreateAuthorization(context.Background(), tx, &influxdb.Authorization{
 					ID:     influxdb.ID(1),
@@ -0,0 +1,94 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/query"
+)
+
+var _ query.ActiveQueryService = (*ActiveQueryService)(nil)
+
+// ActiveQueryService wraps a query.ActiveQueryService and authorizes actions
+// against it appropriately.
+type ActiveQueryService struct {
+	s query.ActiveQueryService
+}
+
+// NewActiveQueryService constructs an instance of an authorizing active query service.
+func NewActiveQueryService(s query.ActiveQueryService) *ActiveQueryService {
+	return &ActiveQueryService{
+		s: s,
+	}
+}
+
+// FindActiveQueries returns the active queries of the orgs the authorizer on context may read.
+func (s *ActiveQueryService) FindActiveQueries(ctx context.Context, filter query.ActiveQueryFilter) ([]*query.ActiveQuery, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if filter.OrganizationID != nil {
+		if _, _, err := AuthorizeReadOrg(ctx, *filter.OrganizationID); err != nil {
+			return nil, err
+		}
+	}
+	qs, err := s.s.FindActiveQueries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return AuthorizeFindActiveQueries(ctx, qs)
+}
+
+// FindActiveQueryByID checks to see if the authorizer on context has read access to the org of the query.
+func (s *ActiveQueryService) FindActiveQueryByID(ctx context.Context, id uint64) (*query.ActiveQuery, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	q, err := s.s.FindActiveQueryByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeReadOrg(ctx, q.OrganizationID); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// CancelActiveQuery checks to see if the authorizer on context started the query
+// or has write access to its org.
+func (s *ActiveQueryService) CancelActiveQuery(ctx context.Context, id uint64) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	q, err := s.FindActiveQueryByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	a, _, err := AuthorizeWriteOrg(ctx, q.OrganizationID)
+	if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+		return err
+	}
+	if err != nil && (!q.UserID.Valid() || a.GetUserID() != q.UserID) {
+		return err
+	}
+	return s.s.CancelActiveQuery(ctx, id)
+}
+
+// AuthorizeFindActiveQueries takes the given items and returns only the ones that the user is authorized to read.
+func AuthorizeFindActiveQueries(ctx context.Context, rs []*query.ActiveQuery) ([]*query.ActiveQuery, error) {
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	rrs := rs[:0]
+	for _, r := range rs {
+		_, _, err := AuthorizeReadOrg(ctx, r.OrganizationID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+		rrs = append(rrs, r)
+	}
+	return rrs, nil
+}
@@ -0,0 +1,167 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/query"
+	querymock "github.com/influxdata/influxdb/v2/query/mock"
+	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
+)
+
+func newActiveQueryService(canceled *[]uint64) *querymock.ActiveQueryService {
+	queries := []*query.ActiveQuery{
+		{ID: 1, OrganizationID: 10, UserID: 2},
+		{ID: 2, OrganizationID: 10, UserID: 3},
+		{ID: 3, OrganizationID: 11, UserID: 2},
+	}
+	return &querymock.ActiveQueryService{
+		FindActiveQueriesFn: func(ctx context.Context, filter query.ActiveQueryFilter) ([]*query.ActiveQuery, error) {
+			qs := make([]*query.ActiveQuery, 0, len(queries))
+			for _, q := range queries {
+				if filter.OrganizationID == nil || *filter.OrganizationID == q.OrganizationID {
+					qs = append(qs, q)
+				}
+			}
+			return qs, nil
+		},
+		FindActiveQueryByIDFn: func(ctx context.Context, id uint64) (*query.ActiveQuery, error) {
+			for _, q := range queries {
+				if q.ID == id {
+					return q, nil
+				}
+			}
+			return nil, query.ErrActiveQueryNotFound
+		},
+		CancelActiveQueryFn: func(ctx context.Context, id uint64) error {
+			*canceled = append(*canceled, id)
+			return nil
+		},
+	}
+}
+
+func orgPermission(action influxdb.Action, orgID influxdb.ID) influxdb.Permission {
+	return influxdb.Permission{
+		Action: action,
+		Resource: influxdb.Resource{
+			Type: influxdb.OrgsResourceType,
+			ID:   influxdbtesting.IDPtr(orgID),
+		},
+	}
+}
+
+func TestActiveQueryService_FindActiveQueries(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		filter      query.ActiveQueryFilter
+		wantIDs     []uint64
+		wantErr     bool
+	}{
+		{
+			name:        "members see the queries of their org",
+			permissions: []influxdb.Permission{orgPermission(influxdb.ReadAction, 10)},
+			wantIDs:     []uint64{1, 2},
+		},
+		{
+			name: "members of several orgs see the queries of every org",
+			permissions: []influxdb.Permission{
+				orgPermission(influxdb.ReadAction, 10),
+				orgPermission(influxdb.ReadAction, 11),
+			},
+			wantIDs: []uint64{1, 2, 3},
+		},
+		{
+			name:        "filtering by an org the user may not read",
+			permissions: []influxdb.Permission{orgPermission(influxdb.ReadAction, 10)},
+			filter:      query.ActiveQueryFilter{OrganizationID: influxdbtesting.IDPtr(11)},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var canceled []uint64
+			s := authorizer.NewActiveQueryService(newActiveQueryService(&canceled))
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), mock.NewMockAuthorizer(false, tt.permissions))
+
+			qs, err := s.FindActiveQueries(ctx, tt.filter)
+			if tt.wantErr {
+				if influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+					t.Fatalf("expected unauthorized error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids := make([]uint64, 0, len(qs))
+			for _, q := range qs {
+				ids = append(ids, q.ID)
+			}
+			if !cmp.Equal(tt.wantIDs, ids) {
+				t.Fatalf("unexpected queries -want/+got:\n%s", cmp.Diff(tt.wantIDs, ids))
+			}
+		})
+	}
+}
+
+func TestActiveQueryService_CancelActiveQuery(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		id          uint64
+		wantErr     bool
+	}{
+		{
+			name:        "members may cancel their own queries",
+			permissions: []influxdb.Permission{orgPermission(influxdb.ReadAction, 10)},
+			id:          1,
+		},
+		{
+			name:        "members may not cancel the queries of other users",
+			permissions: []influxdb.Permission{orgPermission(influxdb.ReadAction, 10)},
+			id:          2,
+			wantErr:     true,
+		},
+		{
+			name:        "owners may cancel the queries of other users",
+			permissions: []influxdb.Permission{orgPermission(influxdb.WriteAction, 10), orgPermission(influxdb.ReadAction, 10)},
+			id:          2,
+		},
+		{
+			name:        "users may not cancel their queries of orgs they left",
+			permissions: []influxdb.Permission{orgPermission(influxdb.ReadAction, 10)},
+			id:          3,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var canceled []uint64
+			s := authorizer.NewActiveQueryService(newActiveQueryService(&canceled))
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), mock.NewMockAuthorizer(false, tt.permissions))
+
+			err := s.CancelActiveQuery(ctx, tt.id)
+			if tt.wantErr {
+				if influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+					t.Fatalf("expected unauthorized error, got %v", err)
+				}
+				if len(canceled) > 0 {
+					t.Fatalf("query was canceled: %v", canceled)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []uint64{tt.id}; !cmp.Equal(want, canceled) {
+				t.Fatalf("unexpected canceled queries -want/+got:\n%s", cmp.Diff(want, canceled))
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.AuditService = (*AuditService)(nil)
+
+// AuditService wraps a influxdb.AuditService and authorizes actions
+// against it appropriately.
+type AuditService struct {
+	s influxdb.AuditService
+}
+
+// NewAuditService constructs an instance of an authorizing audit service.
+func NewAuditService(s influxdb.AuditService) *AuditService {
+	return &AuditService{
+		s: s,
+	}
+}
+
+// RecordAuditEvent checks to see if the authorizer on context has write access to all organizations.
+// The events of the API are recorded by the services that make the changes, not through this method.
+func (s *AuditService) RecordAuditEvent(ctx context.Context, e *influxdb.AuditEvent) error {
+	if _, _, err := AuthorizeWriteGlobal(ctx, influxdb.OrgsResourceType); err != nil {
+		return err
+	}
+	return s.s.RecordAuditEvent(ctx, e)
+}
+
+// FindAuditEvents checks to see if the authorizer on context has write access to the organization
+// of the filter. The events of all organizations, and those of no organization, such as logins,
+// require write access to all organizations.
+func (s *AuditService) FindAuditEvents(ctx context.Context, filter influxdb.AuditFilter, opt ...influxdb.FindOptions) ([]*influxdb.AuditEvent, int, error) {
+	if filter.OrgID != nil {
+		if _, _, err := AuthorizeWriteOrg(ctx, *filter.OrgID); err != nil {
+			return nil, 0, err
+		}
+	} else if _, _, err := AuthorizeWriteGlobal(ctx, influxdb.OrgsResourceType); err != nil {
+		return nil, 0, err
+	}
+	return s.s.FindAuditEvents(ctx, filter, opt...)
+}
@@ -0,0 +1,99 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/mock"
+	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
+)
+
+func TestAuditService_FindAuditEvents(t *testing.T) {
+	writeOrg := influxdb.Permission{
+		Action: influxdb.WriteAction,
+		Resource: influxdb.Resource{
+			Type: influxdb.OrgsResourceType,
+			ID:   influxdbtesting.IDPtr(1),
+		},
+	}
+	readOrg := influxdb.Permission{
+		Action: influxdb.ReadAction,
+		Resource: influxdb.Resource{
+			Type: influxdb.OrgsResourceType,
+			ID:   influxdbtesting.IDPtr(1),
+		},
+	}
+	writeOrgs := influxdb.Permission{
+		Action: influxdb.WriteAction,
+		Resource: influxdb.Resource{
+			Type: influxdb.OrgsResourceType,
+		},
+	}
+
+	type args struct {
+		permissions []influxdb.Permission
+		filter      influxdb.AuditFilter
+	}
+	type wants struct {
+		err error
+	}
+
+	tests := []struct {
+		name  string
+		args  args
+		wants wants
+	}{
+		{
+			name: "authorized to find events of the org",
+			args: args{
+				permissions: []influxdb.Permission{writeOrg},
+				filter:      influxdb.AuditFilter{OrgID: influxdbtesting.IDPtr(1)},
+			},
+		},
+		{
+			name: "unauthorized to find events of the org it can only read",
+			args: args{
+				permissions: []influxdb.Permission{readOrg},
+				filter:      influxdb.AuditFilter{OrgID: influxdbtesting.IDPtr(1)},
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Msg:  "write:orgs/0000000000000001 is unauthorized",
+					Code: influxdb.EUnauthorized,
+				},
+			},
+		},
+		{
+			name: "unauthorized to find events of all orgs",
+			args: args{
+				permissions: []influxdb.Permission{writeOrg},
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Msg:  "write:orgs is unauthorized",
+					Code: influxdb.EUnauthorized,
+				},
+			},
+		},
+		{
+			name: "authorized to find events of all orgs",
+			args: args{
+				permissions: []influxdb.Permission{writeOrgs},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewAuditService(mock.NewAuditService())
+
+			ctx := context.Background()
+			ctx = influxdbcontext.SetAuthorizer(ctx, mock.NewMockAuthorizer(false, tt.args.permissions))
+			_, _, err := s.FindAuditEvents(ctx, tt.args.filter)
+			influxdbtesting.ErrorsEqual(t, err, tt.wants.err)
+		})
+	}
+}
@@ -74,6 +74,11 @@ func (s *AuthorizationService) CreateAuthorization(ctx context.Context, a *influ
 	if err := VerifyPermissions(ctx, a.Permissions); err != nil {
 		return err
 	}
+	if a.RateLimits != nil {
+		if _, _, err := AuthorizeWriteOrg(ctx, a.OrgID); err != nil {
+			return err
+		}
+	}
 	return s.s.CreateAuthorization(ctx, a)
 }
 
@@ -89,6 +94,12 @@ func (s *AuthorizationService) UpdateAuthorization(ctx context.Context, id influ
 	if _, _, err := AuthorizeWriteResource(ctx, influxdb.UsersResourceType, a.UserID); err != nil {
 		return nil, err
 	}
+	// Only the owners of the org, and operators, may change the rates of its tokens.
+	if upd.RateLimits != nil {
+		if _, _, err := AuthorizeWriteOrg(ctx, a.OrgID); err != nil {
+			return nil, err
+		}
+	}
 	return s.s.UpdateAuthorization(ctx, id, upd)
 }
 
@@ -109,6 +120,9 @@ func (s *AuthorizationService) DeleteAuthorization(ctx context.Context, id influ
 
 // VerifyPermissions ensures that an authorization is allowed all of the appropriate permissions.
 func VerifyPermissions(ctx context.Context, ps []influxdb.Permission) error {
+	if err := validPredicates(ps); err != nil {
+		return err
+	}
 	for _, p := range ps {
 		if err := IsAllowed(ctx, p); err != nil {
 			return &influxdb.Error{
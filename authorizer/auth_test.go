@@ -314,6 +314,83 @@ func TestAuthorizationService_WriteAuthorization(t *testing.T) {
 	}
 }
 
+func TestAuthorizationService_UpdateAuthorization_rateLimits(t *testing.T) {
+	tokenPermissions := []influxdb.Permission{
+		{
+			Action: influxdb.WriteAction,
+			Resource: influxdb.Resource{
+				Type:  influxdb.AuthorizationsResourceType,
+				OrgID: influxdbtesting.IDPtr(1),
+			},
+		},
+		{
+			Action: influxdb.WriteAction,
+			Resource: influxdb.Resource{
+				Type: influxdb.UsersResourceType,
+				ID:   influxdbtesting.IDPtr(1),
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		err         error
+	}{
+		{
+			name:        "org owner may set rate limits",
+			permissions: append(tokenPermissions, influxdb.OwnerPermissions(1)...),
+		},
+		{
+			name:        "operator may set rate limits",
+			permissions: influxdb.OperPermissions(),
+		},
+		{
+			name:        "token owner may not set rate limits",
+			permissions: tokenPermissions,
+			err: &influxdb.Error{
+				Msg:  "write:orgs/0000000000000001 is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mock.AuthorizationService{}
+			m.FindAuthorizationByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Authorization, error) {
+				return &influxdb.Authorization{
+					ID:     id,
+					UserID: 1,
+					OrgID:  1,
+				}, nil
+			}
+			m.CreateAuthorizationFn = func(ctx context.Context, a *influxdb.Authorization) error {
+				return nil
+			}
+			m.UpdateAuthorizationFn = func(ctx context.Context, id influxdb.ID, upd *influxdb.AuthorizationUpdate) (*influxdb.Authorization, error) {
+				return nil, nil
+			}
+			s := authorizer.NewAuthorizationService(m)
+
+			ctx := context.Background()
+			ctx = influxdbcontext.SetAuthorizer(ctx, mock.NewMockAuthorizer(false, tt.permissions))
+
+			limits := &influxdb.RateLimits{QueriesPerSecond: 100}
+
+			t.Run("update authorization", func(t *testing.T) {
+				_, err := s.UpdateAuthorization(ctx, 10, &influxdb.AuthorizationUpdate{RateLimits: limits})
+				influxdbtesting.ErrorsEqual(t, err, tt.err)
+			})
+
+			t.Run("create authorization", func(t *testing.T) {
+				err := s.CreateAuthorization(ctx, &influxdb.Authorization{OrgID: 1, UserID: 1, RateLimits: limits})
+				influxdbtesting.ErrorsEqual(t, err, tt.err)
+			})
+		})
+	}
+}
+
 func TestAuthorizationService_CreateAuthorization(t *testing.T) {
 	type args struct {
 		permissions []influxdb.Permission
@@ -436,3 +513,48 @@ func TestAuthorizationService_CreateAuthorization(t *testing.T) {
 		})
 	}
 }
+
+func TestVerifyPermissions_Predicate(t *testing.T) {
+	bucket := influxdb.Resource{
+		Type:  influxdb.BucketsResourceType,
+		OrgID: influxdbtesting.IDPtr(1),
+		ID:    influxdbtesting.IDPtr(2),
+	}
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), mock.NewMockAuthorizer(false, []influxdb.Permission{
+		{Action: influxdb.ReadAction, Resource: bucket, Predicate: `host="a"`},
+	}))
+
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		code       string
+	}{
+		{
+			name:       "same predicate",
+			permission: influxdb.Permission{Action: influxdb.ReadAction, Resource: bucket, Predicate: `host="a"`},
+		},
+		{
+			name:       "whole bucket",
+			permission: influxdb.Permission{Action: influxdb.ReadAction, Resource: bucket},
+			code:       influxdb.EForbidden,
+		},
+		{
+			name:       "other predicate",
+			permission: influxdb.Permission{Action: influxdb.ReadAction, Resource: bucket, Predicate: `host="b"`},
+			code:       influxdb.EForbidden,
+		},
+		{
+			name:       "invalid predicate",
+			permission: influxdb.Permission{Action: influxdb.ReadAction, Resource: bucket, Predicate: `host =~ /a/`},
+			code:       influxdb.EInvalid,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := authorizer.VerifyPermissions(ctx, []influxdb.Permission{tt.permission})
+			if code := influxdb.ErrorCode(err); code != tt.code {
+				t.Errorf("expected error code %q, got %v", tt.code, err)
+			}
+		})
+	}
+}
@@ -99,7 +99,13 @@ func AuthorizeReadBucket(ctx context.Context, bt influxdb.BucketType, bid, oid i
 	case influxdb.BucketTypeSystem:
 		return authorizeReadSystemBucket(ctx, bid, oid)
 	default:
-		return AuthorizeRead(ctx, influxdb.BucketsResourceType, bid, oid)
+		a, p, err := AuthorizeRead(ctx, influxdb.BucketsResourceType, bid, oid)
+		// A permission with a predicate allows finding the bucket, its
+		// data is restricted by the Store.
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized && len(bucketPredicates(a, p)) > 0 {
+			return a, p, nil
+		}
+		return a, p, err
 	}
 }
 
@@ -626,3 +626,36 @@ func TestBucketService_CreateBucket(t *testing.T) {
 		})
 	}
 }
+
+func TestBucketService_Predicate(t *testing.T) {
+	svc := mock.NewBucketService()
+	svc.FindBucketByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+		return &influxdb.Bucket{ID: id, OrgID: 10}, nil
+	}
+	svc.UpdateBucketFn = func(ctx context.Context, id influxdb.ID, upd influxdb.BucketUpdate) (*influxdb.Bucket, error) {
+		return &influxdb.Bucket{ID: id, OrgID: 10}, nil
+	}
+	s := authorizer.NewBucketService(svc, nil)
+
+	bucket := influxdb.Resource{
+		Type:  influxdb.BucketsResourceType,
+		OrgID: influxdbtesting.IDPtr(10),
+		ID:    influxdbtesting.IDPtr(1),
+	}
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), mock.NewMockAuthorizer(false, []influxdb.Permission{
+		{Action: influxdb.ReadAction, Resource: bucket, Predicate: `host="a"`},
+		{Action: influxdb.WriteAction, Resource: bucket, Predicate: `host="a"`},
+	}))
+
+	// The bucket may be found to read the series of the predicate...
+	if _, err := s.FindBucketByID(ctx, 1); err != nil {
+		t.Fatalf("expected bucket to be found, got %v", err)
+	}
+	if _, err := s.FindBucketByID(ctx, 2); influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+		t.Fatalf("expected other bucket to be unauthorized, got %v", err)
+	}
+	// ...but not changed.
+	if _, err := s.UpdateBucket(ctx, 1, influxdb.BucketUpdate{}); influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+		t.Fatalf("expected bucket update to be unauthorized, got %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+)
+
+var _ influxdb.LockoutService = (*LockoutService)(nil)
+
+// LockoutService wraps a influxdb.LockoutService and authorizes actions
+// against it appropriately.
+type LockoutService struct {
+	s influxdb.LockoutService
+}
+
+// NewLockoutService constructs an instance of an authorizing lockout service.
+func NewLockoutService(s influxdb.LockoutService) *LockoutService {
+	return &LockoutService{
+		s: s,
+	}
+}
+
+// FindLoginAttempts checks to see if the authorizer on context has read access to the user.
+func (s *LockoutService) FindLoginAttempts(ctx context.Context, userID influxdb.ID) (*influxdb.LoginAttempts, error) {
+	if _, _, err := AuthorizeReadResource(ctx, influxdb.UsersResourceType, userID); err != nil {
+		return nil, err
+	}
+	return s.s.FindLoginAttempts(ctx, userID)
+}
+
+// RecordLoginFailure checks to see if the authorizer on context has write access to the user.
+func (s *LockoutService) RecordLoginFailure(ctx context.Context, userID influxdb.ID) (*influxdb.LoginAttempts, error) {
+	if _, _, err := AuthorizeWriteResource(ctx, influxdb.UsersResourceType, userID); err != nil {
+		return nil, err
+	}
+	return s.s.RecordLoginFailure(ctx, userID)
+}
+
+// ResetLoginAttempts checks to see if the authorizer on context has write access to the user.
+func (s *LockoutService) ResetLoginAttempts(ctx context.Context, userID influxdb.ID) error {
+	if _, _, err := AuthorizeWriteResource(ctx, influxdb.UsersResourceType, userID); err != nil {
+		return err
+	}
+	return s.s.ResetLoginAttempts(ctx, userID)
+}
+
+var _ influxdb.TOTPService = (*TOTPService)(nil)
+
+// TOTPService wraps a influxdb.TOTPService and authorizes actions
+// against it appropriately.
+type TOTPService struct {
+	s influxdb.TOTPService
+}
+
+// NewTOTPService constructs an instance of an authorizing TOTP service.
+func NewTOTPService(s influxdb.TOTPService) *TOTPService {
+	return &TOTPService{
+		s: s,
+	}
+}
+
+// EnrollTOTP checks to see if the authorizer on context is the user.
+func (s *TOTPService) EnrollTOTP(ctx context.Context, userID influxdb.ID) (*influxdb.TOTPEnrollment, error) {
+	if err := authorizeSelf(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.s.EnrollTOTP(ctx, userID)
+}
+
+// ConfirmTOTP checks to see if the authorizer on context is the user.
+func (s *TOTPService) ConfirmTOTP(ctx context.Context, userID influxdb.ID, code string) error {
+	if err := authorizeSelf(ctx, userID); err != nil {
+		return err
+	}
+	return s.s.ConfirmTOTP(ctx, userID, code)
+}
+
+// TOTPEnabled checks to see if the authorizer on context has read access to the user.
+func (s *TOTPService) TOTPEnabled(ctx context.Context, userID influxdb.ID) (bool, error) {
+	if _, _, err := AuthorizeReadResource(ctx, influxdb.UsersResourceType, userID); err != nil {
+		return false, err
+	}
+	return s.s.TOTPEnabled(ctx, userID)
+}
+
+// VerifyTOTP checks to see if the authorizer on context has write access to the user.
+func (s *TOTPService) VerifyTOTP(ctx context.Context, userID influxdb.ID, code string) error {
+	if _, _, err := AuthorizeWriteResource(ctx, influxdb.UsersResourceType, userID); err != nil {
+		return err
+	}
+	return s.s.VerifyTOTP(ctx, userID, code)
+}
+
+// ResetTOTP checks to see if the authorizer on context has write access to the user.
+func (s *TOTPService) ResetTOTP(ctx context.Context, userID influxdb.ID) error {
+	if _, _, err := AuthorizeWriteResource(ctx, influxdb.UsersResourceType, userID); err != nil {
+		return err
+	}
+	return s.s.ResetTOTP(ctx, userID)
+}
+
+// authorizeSelf checks to see if the authorizer on context is the user, as
+// only users can enroll their own devices.
+func authorizeSelf(ctx context.Context, userID influxdb.ID) error {
+	a, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return err
+	}
+	if a.GetUserID() != userID {
+		return &influxdb.Error{
+			Code: influxdb.EUnauthorized,
+			Msg:  "users can only enroll themselves for TOTP",
+		}
+	}
+	return nil
+}
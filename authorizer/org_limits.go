@@ -0,0 +1,39 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.OrgLimitsService = (*OrgLimitsService)(nil)
+
+// OrgLimitsService wraps a influxdb.OrgLimitsService and authorizes actions
+// against it appropriately.
+type OrgLimitsService struct {
+	s influxdb.OrgLimitsService
+}
+
+// NewOrgLimitsService constructs an instance of an authorizing org limits service.
+func NewOrgLimitsService(s influxdb.OrgLimitsService) *OrgLimitsService {
+	return &OrgLimitsService{
+		s: s,
+	}
+}
+
+// FindOrgLimits checks to see if the authorizer on context has read access to the org provided.
+func (s *OrgLimitsService) FindOrgLimits(ctx context.Context, orgID influxdb.ID) (*influxdb.OrgLimits, error) {
+	if _, _, err := AuthorizeReadOrg(ctx, orgID); err != nil {
+		return nil, err
+	}
+	return s.s.FindOrgLimits(ctx, orgID)
+}
+
+// UpdateOrgLimits checks to see if the authorizer on context has write access to every org.
+// The owners of an org may not raise its limits.
+func (s *OrgLimitsService) UpdateOrgLimits(ctx context.Context, orgID influxdb.ID, upd influxdb.OrgLimitsUpdate) (*influxdb.OrgLimits, error) {
+	if _, _, err := AuthorizeWriteGlobal(ctx, influxdb.OrgsResourceType); err != nil {
+		return nil, err
+	}
+	return s.s.UpdateOrgLimits(ctx, orgID, upd)
+}
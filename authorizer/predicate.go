@@ -0,0 +1,136 @@
+package authorizer
+
+import (
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/predicate"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
+)
+
+// permissionSet is implemented by authorizers that can list their permissions.
+type permissionSet interface {
+	PermissionSet() []influxdb.Permission
+}
+
+// bucketPredicates returns the predicates of the permissions of the authorizer
+// that allow p on the series of the bucket that match them.
+func bucketPredicates(a influxdb.Authorizer, p influxdb.Permission) []string {
+	ps, ok := a.(permissionSet)
+	if !ok {
+		return nil
+	}
+
+	var preds []string
+	for _, perm := range ps.PermissionSet() {
+		if perm.Predicate == "" {
+			continue
+		}
+		whole := perm
+		whole.Predicate = ""
+		if whole.Matches(p) {
+			preds = append(preds, perm.Predicate)
+		}
+	}
+	return preds
+}
+
+// BucketPredicate returns the predicate that restricts the action of the
+// authorizer on a bucket to the series allowed by its permissions with a
+// predicate. It returns nil if the authorizer is allowed the whole bucket or
+// holds no permission with a predicate for it.
+func BucketPredicate(a influxdb.Authorizer, action influxdb.Action, orgID, bucketID influxdb.ID) (*datatypes.Predicate, error) {
+	p, err := influxdb.NewPermissionAtID(bucketID, action, influxdb.BucketsResourceType, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if a.Allowed(*p) {
+		return nil, nil
+	}
+
+	var root *datatypes.Node
+	for _, s := range bucketPredicates(a, *p) {
+		n, err := predicateNode(s)
+		if err != nil {
+			return nil, err
+		}
+		if root == nil {
+			root = n
+			continue
+		}
+		root = &datatypes.Node{
+			NodeType: datatypes.NodeTypeLogicalExpression,
+			Value:    &datatypes.Node_Logical_{Logical: datatypes.LogicalOr},
+			Children: []*datatypes.Node{root, n},
+		}
+	}
+	if root == nil {
+		return nil, nil
+	}
+	return &datatypes.Predicate{Root: root}, nil
+}
+
+// AuthorizeWritePoints checks that the points, exploded by field as they are
+// written to storage, are in series that match the predicate of a bucket as
+// returned by BucketPredicate.
+func AuthorizeWritePoints(pred *datatypes.Predicate, points []models.Point) error {
+	if pred == nil {
+		return nil
+	}
+
+	m, err := tsm1.NewProtobufPredicate(pred)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Msg:  "unable to build the predicate of the permissions",
+			Err:  err,
+		}
+	}
+
+	for _, pt := range points {
+		if m.Matches(pt.Key()) {
+			continue
+		}
+		tags := pt.Tags()
+		return &influxdb.Error{
+			Code: influxdb.EForbidden,
+			Msg: fmt.Sprintf("insufficient permissions to write field %q of measurement %q",
+				tags.Get(models.FieldKeyTagKeyBytes), tags.Get(models.MeasurementTagKeyBytes)),
+		}
+	}
+	return nil
+}
+
+// predicateNode parses the predicate of a permission.
+func predicateNode(s string) (*datatypes.Node, error) {
+	n, err := predicate.Parse(s)
+	if err == nil && n == nil {
+		err = fmt.Errorf("empty predicate")
+	}
+	if err == nil {
+		var dt *datatypes.Node
+		if dt, err = n.ToDataType(); err == nil {
+			return dt, nil
+		}
+	}
+	return nil, &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  fmt.Sprintf("invalid predicate %q for permission", s),
+		Err:  err,
+	}
+}
+
+// validPredicates checks that the predicates of the permissions are valid.
+func validPredicates(ps []influxdb.Permission) error {
+	for _, p := range ps {
+		if p.Predicate == "" {
+			continue
+		}
+		if _, err := predicateNode(p.Predicate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	_ influxdb.RoleService      = (*RoleService)(nil)
+	_ influxdb.UserGroupService = (*UserGroupService)(nil)
+)
+
+// RoleService wraps a influxdb.RoleService and authorizes actions
+// against it appropriately.
+type RoleService struct {
+	s influxdb.RoleService
+}
+
+// NewRoleService constructs an instance of an authorizing role service.
+func NewRoleService(s influxdb.RoleService) *RoleService {
+	return &RoleService{
+		s: s,
+	}
+}
+
+// FindRoleByID checks to see if the authorizer on context has read access to the org of the role.
+func (s *RoleService) FindRoleByID(ctx context.Context, id influxdb.ID) (*influxdb.Role, error) {
+	r, err := s.s.FindRoleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeReadOrg(ctx, r.OrgID); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// FindRoles retrieves all roles that match the provided filter and then filters the list down to the roles of the orgs that are authorized.
+func (s *RoleService) FindRoles(ctx context.Context, filter influxdb.RoleFilter, opt ...influxdb.FindOptions) ([]*influxdb.Role, int, error) {
+	rs, _, err := s.s.FindRoles(ctx, filter, opt...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rrs := rs[:0]
+	for _, r := range rs {
+		_, _, err := AuthorizeReadOrg(ctx, r.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, 0, err
+		}
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+		rrs = append(rrs, r)
+	}
+	return rrs, len(rrs), nil
+}
+
+// CreateRole checks to see if the authorizer on context has write access to the org of the role,
+// and holds the permissions of the role.
+func (s *RoleService) CreateRole(ctx context.Context, r *influxdb.Role) error {
+	if _, _, err := AuthorizeWriteOrg(ctx, r.OrgID); err != nil {
+		return err
+	}
+	if err := validPredicates(r.Permissions); err != nil {
+		return err
+	}
+	if err := IsAllowedAll(ctx, r.Permissions); err != nil {
+		return err
+	}
+	return s.s.CreateRole(ctx, r)
+}
+
+// UpdateRole checks to see if the authorizer on context has write access to the org of the role,
+// and holds the updated permissions of the role.
+func (s *RoleService) UpdateRole(ctx context.Context, id influxdb.ID, upd influxdb.RoleUpdate) (*influxdb.Role, error) {
+	r, err := s.s.FindRoleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeWriteOrg(ctx, r.OrgID); err != nil {
+		return nil, err
+	}
+	if upd.Permissions != nil {
+		if err := validPredicates(*upd.Permissions); err != nil {
+			return nil, err
+		}
+		if err := IsAllowedAll(ctx, *upd.Permissions); err != nil {
+			return nil, err
+		}
+	}
+	return s.s.UpdateRole(ctx, id, upd)
+}
+
+// DeleteRole checks to see if the authorizer on context has write access to the org of the role.
+func (s *RoleService) DeleteRole(ctx context.Context, id influxdb.ID) error {
+	r, err := s.s.FindRoleByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, _, err := AuthorizeWriteOrg(ctx, r.OrgID); err != nil {
+		return err
+	}
+	return s.s.DeleteRole(ctx, id)
+}
+
+// UserGroupService wraps a influxdb.UserGroupService and authorizes actions
+// against it appropriately.
+type UserGroupService struct {
+	s influxdb.UserGroupService
+}
+
+// NewUserGroupService constructs an instance of an authorizing user group service.
+func NewUserGroupService(s influxdb.UserGroupService) *UserGroupService {
+	return &UserGroupService{
+		s: s,
+	}
+}
+
+// FindUserGroupByID checks to see if the authorizer on context has read access to the org of the group.
+func (s *UserGroupService) FindUserGroupByID(ctx context.Context, id influxdb.ID) (*influxdb.UserGroup, error) {
+	g, err := s.s.FindUserGroupByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeReadOrg(ctx, g.OrgID); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// FindUserGroups retrieves all user groups that match the provided filter and then filters the list down to the groups of the orgs that are authorized.
+func (s *UserGroupService) FindUserGroups(ctx context.Context, filter influxdb.UserGroupFilter, opt ...influxdb.FindOptions) ([]*influxdb.UserGroup, int, error) {
+	gs, _, err := s.s.FindUserGroups(ctx, filter, opt...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ggs := gs[:0]
+	for _, g := range gs {
+		_, _, err := AuthorizeReadOrg(ctx, g.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, 0, err
+		}
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+		ggs = append(ggs, g)
+	}
+	return ggs, len(ggs), nil
+}
+
+// CreateUserGroup checks to see if the authorizer on context has write access to the org of the group.
+func (s *UserGroupService) CreateUserGroup(ctx context.Context, g *influxdb.UserGroup) error {
+	if _, _, err := AuthorizeWriteOrg(ctx, g.OrgID); err != nil {
+		return err
+	}
+	return s.s.CreateUserGroup(ctx, g)
+}
+
+// UpdateUserGroup checks to see if the authorizer on context has write access to the org of the group.
+func (s *UserGroupService) UpdateUserGroup(ctx context.Context, id influxdb.ID, upd influxdb.UserGroupUpdate) (*influxdb.UserGroup, error) {
+	g, err := s.s.FindUserGroupByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeWriteOrg(ctx, g.OrgID); err != nil {
+		return nil, err
+	}
+	return s.s.UpdateUserGroup(ctx, id, upd)
+}
+
+// DeleteUserGroup checks to see if the authorizer on context has write access to the org of the group.
+func (s *UserGroupService) DeleteUserGroup(ctx context.Context, id influxdb.ID) error {
+	g, err := s.s.FindUserGroupByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, _, err := AuthorizeWriteOrg(ctx, g.OrgID); err != nil {
+		return err
+	}
+	return s.s.DeleteUserGroup(ctx, id)
+}
+
+// RolePermissions returns the permissions of the roles of the user, whether
+// assigned to the user directly or to the user groups the user is a member
+// of. The services must not authorize, as it is used to find the
+// permissions of an authorizer before it is on the context.
+func RolePermissions(ctx context.Context, roles influxdb.RoleService, groups influxdb.UserGroupService, userID influxdb.ID) ([]influxdb.Permission, error) {
+	rs, _, err := roles.FindRoles(ctx, influxdb.RoleFilter{UserID: &userID})
+	if err != nil {
+		return nil, err
+	}
+
+	gs, _, err := groups.FindUserGroups(ctx, influxdb.UserGroupFilter{UserID: &userID})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[influxdb.ID]bool, len(rs))
+	var ps []influxdb.Permission
+	for _, r := range rs {
+		seen[r.ID] = true
+		ps = append(ps, r.Permissions...)
+	}
+	for _, g := range gs {
+		for _, id := range g.Roles {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			r, err := roles.FindRoleByID(ctx, id)
+			if influxdb.ErrorCode(err) == influxdb.ENotFound {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			ps = append(ps, r.Permissions...)
+		}
+	}
+	return ps, nil
+}
+
+// SessionWithRoles returns a copy of the session that is also allowed the
+// permissions of the roles of its user. Roles are evaluated when a session
+// is used, so changes to roles and groups apply to existing sessions.
+func SessionWithRoles(ctx context.Context, roles influxdb.RoleService, groups influxdb.UserGroupService, s *influxdb.Session) (*influxdb.Session, error) {
+	ps, err := RolePermissions(ctx, roles, groups, s.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(ps) == 0 {
+		return s, nil
+	}
+
+	sess := *s
+	sess.Permissions = append(append(make([]influxdb.Permission, 0, len(s.Permissions)+len(ps)), s.Permissions...), ps...)
+	return &sess, nil
+}
+
+// AuthorizationWithRoles returns a copy of the authorization that is also
+// allowed the permissions of the roles of its user. As for sessions, roles
+// are evaluated when a token is used.
+func AuthorizationWithRoles(ctx context.Context, roles influxdb.RoleService, groups influxdb.UserGroupService, a *influxdb.Authorization) (*influxdb.Authorization, error) {
+	ps, err := RolePermissions(ctx, roles, groups, a.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(ps) == 0 {
+		return a, nil
+	}
+
+	auth := *a
+	auth.Permissions = append(append(make([]influxdb.Permission, 0, len(a.Permissions)+len(ps)), a.Permissions...), ps...)
+	return &auth, nil
+}
@@ -0,0 +1,221 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/mock"
+	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
+)
+
+func TestRoleService_CreateRole(t *testing.T) {
+	writeOrg := influxdb.Permission{
+		Action: influxdb.WriteAction,
+		Resource: influxdb.Resource{
+			Type: influxdb.OrgsResourceType,
+			ID:   influxdbtesting.IDPtr(1),
+		},
+	}
+	readBuckets := influxdb.Permission{
+		Action: influxdb.ReadAction,
+		Resource: influxdb.Resource{
+			Type:  influxdb.BucketsResourceType,
+			OrgID: influxdbtesting.IDPtr(1),
+		},
+	}
+
+	type args struct {
+		permissions []influxdb.Permission
+		role        *influxdb.Role
+	}
+	type wants struct {
+		err error
+	}
+
+	tests := []struct {
+		name  string
+		args  args
+		wants wants
+	}{
+		{
+			name: "authorized to create role",
+			args: args{
+				permissions: []influxdb.Permission{writeOrg, readBuckets},
+				role: &influxdb.Role{
+					OrgID:       1,
+					Name:        "readers",
+					Permissions: []influxdb.Permission{readBuckets},
+				},
+			},
+			wants: wants{
+				err: nil,
+			},
+		},
+		{
+			name: "unauthorized to write org",
+			args: args{
+				permissions: []influxdb.Permission{readBuckets},
+				role: &influxdb.Role{
+					OrgID: 1,
+					Name:  "readers",
+				},
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Msg:  "write:orgs/0000000000000001 is unauthorized",
+					Code: influxdb.EUnauthorized,
+				},
+			},
+		},
+		{
+			name: "unauthorized to grant permissions it does not hold",
+			args: args{
+				permissions: []influxdb.Permission{writeOrg},
+				role: &influxdb.Role{
+					OrgID:       1,
+					Name:        "readers",
+					Permissions: []influxdb.Permission{readBuckets},
+				},
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Msg:  "read:orgs/0000000000000001/buckets is unauthorized",
+					Code: influxdb.EUnauthorized,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewRoleService(mock.NewRoleService())
+
+			ctx := context.Background()
+			ctx = influxdbcontext.SetAuthorizer(ctx, mock.NewMockAuthorizer(false, tt.args.permissions))
+			err := s.CreateRole(ctx, tt.args.role)
+			influxdbtesting.ErrorsEqual(t, err, tt.wants.err)
+		})
+	}
+}
+
+func TestRoleService_FindRoles(t *testing.T) {
+	roles := mock.NewRoleService()
+	roles.FindRolesFn = func(ctx context.Context, filter influxdb.RoleFilter, opt ...influxdb.FindOptions) ([]*influxdb.Role, int, error) {
+		return []*influxdb.Role{
+			{ID: 10, OrgID: 1, Name: "a"},
+			{ID: 20, OrgID: 2, Name: "b"},
+		}, 2, nil
+	}
+	s := authorizer.NewRoleService(roles)
+
+	ctx := context.Background()
+	ctx = influxdbcontext.SetAuthorizer(ctx, mock.NewMockAuthorizer(false, []influxdb.Permission{{
+		Action:   influxdb.ReadAction,
+		Resource: influxdb.Resource{Type: influxdb.OrgsResourceType, ID: influxdbtesting.IDPtr(2)},
+	}}))
+
+	rs, n, err := s.FindRoles(ctx, influxdb.RoleFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 || rs[0].ID != 20 {
+		t.Fatalf("expected only the role of the readable org, got %v", rs)
+	}
+}
+
+func TestSessionWithRoles(t *testing.T) {
+	readBuckets := influxdb.Permission{
+		Action:   influxdb.ReadAction,
+		Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: influxdbtesting.IDPtr(1)},
+	}
+	writeTasks := influxdb.Permission{
+		Action:   influxdb.WriteAction,
+		Resource: influxdb.Resource{Type: influxdb.TasksResourceType, OrgID: influxdbtesting.IDPtr(1)},
+	}
+
+	roles := mock.NewRoleService()
+	roles.FindRolesFn = func(ctx context.Context, filter influxdb.RoleFilter, opt ...influxdb.FindOptions) ([]*influxdb.Role, int, error) {
+		if filter.UserID == nil || *filter.UserID != 5 {
+			return nil, 0, nil
+		}
+		return []*influxdb.Role{{ID: 10, OrgID: 1, Permissions: []influxdb.Permission{readBuckets}}}, 1, nil
+	}
+	roles.FindRoleByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Role, error) {
+		switch id {
+		case 10:
+			return &influxdb.Role{ID: 10, OrgID: 1, Permissions: []influxdb.Permission{readBuckets}}, nil
+		case 30:
+			return &influxdb.Role{ID: 30, OrgID: 1, Permissions: []influxdb.Permission{writeTasks}}, nil
+		}
+		return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: influxdb.ErrRoleNotFound}
+	}
+	groups := mock.NewUserGroupService()
+	groups.FindUserGroupsFn = func(ctx context.Context, filter influxdb.UserGroupFilter, opt ...influxdb.FindOptions) ([]*influxdb.UserGroup, int, error) {
+		// The group role 10 is also assigned directly and role 40 no longer exists.
+		return []*influxdb.UserGroup{{ID: 50, OrgID: 1, Members: []influxdb.ID{5}, Roles: []influxdb.ID{10, 30, 40}}}, 1, nil
+	}
+
+	orig := &influxdb.Session{ID: 1, UserID: 5, ExpiresAt: time.Now().Add(time.Hour)}
+	sess, err := authorizer.SessionWithRoles(context.Background(), roles, groups, orig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orig.Permissions) != 0 {
+		t.Fatalf("original session was modified: %v", orig.Permissions)
+	}
+	if len(sess.Permissions) != 2 {
+		t.Fatalf("expected the deduplicated permissions of the roles, got %v", sess.Permissions)
+	}
+	if !sess.Allowed(readBuckets) || !sess.Allowed(writeTasks) {
+		t.Fatalf("expected session to be allowed the role permissions, got %v", sess.Permissions)
+	}
+}
+
+func TestAuthorizationWithRoles(t *testing.T) {
+	readBuckets := influxdb.Permission{
+		Action:   influxdb.ReadAction,
+		Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: influxdbtesting.IDPtr(1)},
+	}
+	writeTasks := influxdb.Permission{
+		Action:   influxdb.WriteAction,
+		Resource: influxdb.Resource{Type: influxdb.TasksResourceType, OrgID: influxdbtesting.IDPtr(1)},
+	}
+
+	roles := mock.NewRoleService()
+	roles.FindRolesFn = func(ctx context.Context, filter influxdb.RoleFilter, opt ...influxdb.FindOptions) ([]*influxdb.Role, int, error) {
+		if filter.UserID == nil || *filter.UserID != 5 {
+			return nil, 0, nil
+		}
+		return []*influxdb.Role{{ID: 10, OrgID: 1, Permissions: []influxdb.Permission{writeTasks}}}, 1, nil
+	}
+	groups := mock.NewUserGroupService()
+	groups.FindUserGroupsFn = func(ctx context.Context, filter influxdb.UserGroupFilter, opt ...influxdb.FindOptions) ([]*influxdb.UserGroup, int, error) {
+		return nil, 0, nil
+	}
+
+	orig := &influxdb.Authorization{ID: 1, UserID: 5, OrgID: 1, Status: influxdb.Active, Permissions: []influxdb.Permission{readBuckets}}
+	auth, err := authorizer.AuthorizationWithRoles(context.Background(), roles, groups, orig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orig.Permissions) != 1 {
+		t.Fatalf("original authorization was modified: %v", orig.Permissions)
+	}
+	if !auth.Allowed(readBuckets) || !auth.Allowed(writeTasks) {
+		t.Fatalf("expected authorization to be allowed its own and the role permissions, got %v", auth.Permissions)
+	}
+
+	// The authorizations of users without roles are unchanged.
+	other := &influxdb.Authorization{ID: 2, UserID: 6, OrgID: 1, Status: influxdb.Active, Permissions: []influxdb.Permission{readBuckets}}
+	auth, err = authorizer.AuthorizationWithRoles(context.Background(), roles, groups, other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth.Allowed(writeTasks) {
+		t.Fatalf("expected authorization without roles not to be allowed the role permissions, got %v", auth.Permissions)
+	}
+}
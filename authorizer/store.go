@@ -0,0 +1,177 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/storage/reads"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+)
+
+var (
+	_ reads.Store                = (*Store)(nil)
+	_ reads.WindowAggregateStore = (*WindowAggregateStore)(nil)
+)
+
+// Store wraps a reads.Store and restricts the series that are read to the
+// predicates of the permissions of the authorizer on context. Access to the
+// bucket itself is authorized when it is looked up.
+type Store struct {
+	s reads.Store
+}
+
+// WindowAggregateStore is a Store whose wrapped store implements the
+// reads.WindowAggregateStore capability.
+type WindowAggregateStore struct {
+	*Store
+	wa reads.WindowAggregateStore
+}
+
+// NewStore constructs an instance of an authorizing store. The store
+// implements reads.WindowAggregateStore if s does.
+func NewStore(s reads.Store) reads.Store {
+	store := &Store{s: s}
+	if wa, ok := s.(reads.WindowAggregateStore); ok {
+		return &WindowAggregateStore{Store: store, wa: wa}
+	}
+	return store
+}
+
+// ReadFilter restricts the series of the request to those the authorizer on context may read.
+func (s *Store) ReadFilter(ctx context.Context, req *datatypes.ReadFilterRequest) (reads.ResultSet, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	pred, err := restrictPredicate(ctx, req.ReadSource, req.Predicate)
+	if err != nil {
+		return nil, err
+	}
+	r := *req
+	r.Predicate = pred
+	return s.s.ReadFilter(ctx, &r)
+}
+
+// ReadGroup restricts the series of the request to those the authorizer on context may read.
+func (s *Store) ReadGroup(ctx context.Context, req *datatypes.ReadGroupRequest) (reads.GroupResultSet, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	pred, err := restrictPredicate(ctx, req.ReadSource, req.Predicate)
+	if err != nil {
+		return nil, err
+	}
+	r := *req
+	r.Predicate = pred
+	return s.s.ReadGroup(ctx, &r)
+}
+
+// TagKeys restricts the series of the request to those the authorizer on context may read,
+// so that the keys of other series are not disclosed.
+func (s *Store) TagKeys(ctx context.Context, req *datatypes.TagKeysRequest) (cursors.StringIterator, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	pred, err := restrictPredicate(ctx, req.TagsSource, req.Predicate)
+	if err != nil {
+		return nil, err
+	}
+	r := *req
+	r.Predicate = pred
+	return s.s.TagKeys(ctx, &r)
+}
+
+// TagValues restricts the series of the request to those the authorizer on context may read,
+// so that the values of other series, including their measurements and fields, are not disclosed.
+func (s *Store) TagValues(ctx context.Context, req *datatypes.TagValuesRequest) (cursors.StringIterator, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	pred, err := restrictPredicate(ctx, req.TagsSource, req.Predicate)
+	if err != nil {
+		return nil, err
+	}
+	r := *req
+	r.Predicate = pred
+	return s.s.TagValues(ctx, &r)
+}
+
+// GetSource returns the source of the wrapped store.
+func (s *Store) GetSource(orgID, bucketID uint64) proto.Message {
+	return s.s.GetSource(orgID, bucketID)
+}
+
+// HasWindowAggregateCapability checks if the wrapped store supports the capability.
+func (s *WindowAggregateStore) HasWindowAggregateCapability(ctx context.Context, capability ...*reads.WindowAggregateCapability) bool {
+	return s.wa.HasWindowAggregateCapability(ctx, capability...)
+}
+
+// WindowAggregate restricts the series of the request to those the authorizer on context may read.
+func (s *WindowAggregateStore) WindowAggregate(ctx context.Context, req *datatypes.ReadWindowAggregateRequest) (reads.ResultSet, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	pred, err := restrictPredicate(ctx, req.ReadSource, req.Predicate)
+	if err != nil {
+		return nil, err
+	}
+	r := *req
+	r.Predicate = pred
+	return s.wa.WindowAggregate(ctx, &r)
+}
+
+// readSource is the source of a request as encoded by the storage
+// readservice.
+type readSource struct {
+	BucketID       uint64 `protobuf:"varint,1,opt,name=bucket_id,proto3"`
+	OrganizationID uint64 `protobuf:"varint,2,opt,name=organization_id,proto3"`
+}
+
+func (r *readSource) XXX_MessageName() string { return "readSource" }
+func (r *readSource) Reset()                  { *r = readSource{} }
+func (r *readSource) String() string          { return "readSource{}" }
+func (r *readSource) ProtoMessage()           {}
+
+// restrictPredicate returns pred restricted to the series of the bucket of
+// the source that the authorizer on context may read. Requests without an
+// authorizer on context are internal and are not restricted.
+func restrictPredicate(ctx context.Context, source *types.Any, pred *datatypes.Predicate) (*datatypes.Predicate, error) {
+	a, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return pred, nil
+	}
+	if source == nil {
+		return pred, nil
+	}
+
+	var src readSource
+	if err := types.UnmarshalAny(source, &src); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid read source",
+			Err:  err,
+		}
+	}
+
+	allowed, err := BucketPredicate(a, influxdb.ReadAction, influxdb.ID(src.OrganizationID), influxdb.ID(src.BucketID))
+	if err != nil {
+		return nil, err
+	}
+	if allowed == nil {
+		return pred, nil
+	}
+	if pred.GetRoot() == nil {
+		return allowed, nil
+	}
+	return &datatypes.Predicate{
+		Root: &datatypes.Node{
+			NodeType: datatypes.NodeTypeLogicalExpression,
+			Value:    &datatypes.Node_Logical_{Logical: datatypes.LogicalAnd},
+			Children: []*datatypes.Node{pred.Root, allowed.Root},
+		},
+	}, nil
+}
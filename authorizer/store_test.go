@@ -0,0 +1,287 @@
+package authorizer_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/storage/reads"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/v2/storage/readservice"
+	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+)
+
+const (
+	storeOrgID    = influxdb.ID(1)
+	storeBucketID = influxdb.ID(2)
+)
+
+// newTestStore opens a storage engine with the data of a bucket shared by
+// two customers and returns an authorizing store that reads it.
+func newTestStore(t *testing.T) (reads.Store, func()) {
+	t.Helper()
+
+	path, err := ioutil.TempDir("", "authorizer_store_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := storage.NewEngine(path, storage.NewConfig())
+	if err := engine.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	name := tsdb.EncodeName(storeOrgID, storeBucketID)
+	points, err := models.ParsePointsWithOptions([]byte(`cpu,customer=acme,host=a usage=1 10
+cpu,customer=globex,host=b usage=2 10
+mem,customer=acme,host=a used=3 10
+secrets,customer=globex,vault=b key=4 10
+`), models.EscapeMeasurement(name[:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.WritePoints(context.Background(), points); err != nil {
+		t.Fatal(err)
+	}
+
+	return authorizer.NewStore(readservice.NewStore(engine)), func() {
+		engine.Close()
+		os.RemoveAll(path)
+	}
+}
+
+func readBucketPermission(predicate string) influxdb.Permission {
+	return influxdb.Permission{
+		Action: influxdb.ReadAction,
+		Resource: influxdb.Resource{
+			Type:  influxdb.BucketsResourceType,
+			OrgID: influxdbtesting.IDPtr(storeOrgID),
+			ID:    influxdbtesting.IDPtr(storeBucketID),
+		},
+		Predicate: predicate,
+	}
+}
+
+func storeSource(t *testing.T, s reads.Store) *types.Any {
+	t.Helper()
+	src, err := types.MarshalAny(s.GetSource(uint64(storeOrgID), uint64(storeBucketID)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return src
+}
+
+func readStrings(t *testing.T, it cursors.StringIterator, err error) []string {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ss []string
+	for it.Next() {
+		ss = append(ss, it.Value())
+	}
+	sort.Strings(ss)
+	return ss
+}
+
+func TestStore(t *testing.T) {
+	s, closeStore := newTestStore(t)
+	defer closeStore()
+
+	tests := []struct {
+		name         string
+		permissions  []influxdb.Permission
+		measurements []string
+		fields       []string
+		tagKeys      []string
+		hosts        []string
+		series       int
+	}{
+		{
+			name:         "whole bucket",
+			permissions:  []influxdb.Permission{readBucketPermission("")},
+			measurements: []string{"cpu", "mem", "secrets"},
+			fields:       []string{"key", "usage", "used"},
+			tagKeys:      []string{models.MeasurementTagKey, "customer", "host", "vault", models.FieldKeyTagKey},
+			hosts:        []string{"a", "b"},
+			series:       4,
+		},
+		{
+			name:         "whole bucket and predicate",
+			permissions:  []influxdb.Permission{readBucketPermission(`customer="acme"`), readBucketPermission("")},
+			measurements: []string{"cpu", "mem", "secrets"},
+			fields:       []string{"key", "usage", "used"},
+			tagKeys:      []string{models.MeasurementTagKey, "customer", "host", "vault", models.FieldKeyTagKey},
+			hosts:        []string{"a", "b"},
+			series:       4,
+		},
+		{
+			name:         "tag predicate",
+			permissions:  []influxdb.Permission{readBucketPermission(`customer="acme"`)},
+			measurements: []string{"cpu", "mem"},
+			fields:       []string{"usage", "used"},
+			tagKeys:      []string{models.MeasurementTagKey, "customer", "host", models.FieldKeyTagKey},
+			hosts:        []string{"a"},
+			series:       2,
+		},
+		{
+			name:         "measurement and tag predicate",
+			permissions:  []influxdb.Permission{readBucketPermission(`_measurement="cpu" AND customer="globex"`)},
+			measurements: []string{"cpu"},
+			fields:       []string{"usage"},
+			tagKeys:      []string{models.MeasurementTagKey, "customer", "host", models.FieldKeyTagKey},
+			hosts:        []string{"b"},
+			series:       1,
+		},
+		{
+			name: "union of predicates",
+			permissions: []influxdb.Permission{
+				readBucketPermission(`_measurement="mem"`),
+				readBucketPermission(`_measurement="cpu" AND host="b"`),
+			},
+			measurements: []string{"cpu", "mem"},
+			fields:       []string{"usage", "used"},
+			tagKeys:      []string{models.MeasurementTagKey, "customer", "host", models.FieldKeyTagKey},
+			hosts:        []string{"a", "b"},
+			series:       2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), mock.NewMockAuthorizer(false, tt.permissions))
+			src := storeSource(t, s)
+
+			tagValues := func(key string) []string {
+				it, err := s.TagValues(ctx, &datatypes.TagValuesRequest{TagsSource: src, TagKey: key})
+				return readStrings(t, it, err)
+			}
+			if diff := cmp.Diff(tt.measurements, tagValues(models.MeasurementTagKey)); diff != "" {
+				t.Errorf("unexpected measurements -want/+got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.fields, tagValues(models.FieldKeyTagKey)); diff != "" {
+				t.Errorf("unexpected fields -want/+got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.hosts, tagValues("host")); diff != "" {
+				t.Errorf("unexpected hosts -want/+got:\n%s", diff)
+			}
+
+			it, err := s.TagKeys(ctx, &datatypes.TagKeysRequest{TagsSource: src})
+			keys := readStrings(t, it, err)
+			sort.Strings(tt.tagKeys)
+			if diff := cmp.Diff(tt.tagKeys, keys); diff != "" {
+				t.Errorf("unexpected tag keys -want/+got:\n%s", diff)
+			}
+
+			rs, err := s.ReadFilter(ctx, &datatypes.ReadFilterRequest{
+				ReadSource: src,
+				Range:      datatypes.TimestampRange{Start: models.MinNanoTime, End: models.MaxNanoTime},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var series int
+			for rs != nil && rs.Next() {
+				series++
+			}
+			if rs != nil {
+				rs.Close()
+			}
+			if series != tt.series {
+				t.Errorf("expected %d series, got %d", tt.series, series)
+			}
+		})
+	}
+}
+
+func TestStore_RequestPredicate(t *testing.T) {
+	s, closeStore := newTestStore(t)
+	defer closeStore()
+
+	// The predicate of the request can only narrow the series of the permissions.
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), mock.NewMockAuthorizer(false, []influxdb.Permission{
+		readBucketPermission(`customer="acme"`),
+	}))
+	it, err := s.TagValues(ctx, &datatypes.TagValuesRequest{
+		TagsSource: storeSource(t, s),
+		TagKey:     models.MeasurementTagKey,
+		Predicate: &datatypes.Predicate{
+			Root: &datatypes.Node{
+				NodeType: datatypes.NodeTypeComparisonExpression,
+				Value:    &datatypes.Node_Comparison_{Comparison: datatypes.ComparisonEqual},
+				Children: []*datatypes.Node{
+					{NodeType: datatypes.NodeTypeTagRef, Value: &datatypes.Node_TagRefValue{TagRefValue: "customer"}},
+					{NodeType: datatypes.NodeTypeLiteral, Value: &datatypes.Node_StringValue{StringValue: "globex"}},
+				},
+			},
+		},
+	})
+	if got := readStrings(t, it, err); len(got) != 0 {
+		t.Fatalf("expected no measurements, got %v", got)
+	}
+}
+
+func TestBucketPredicate(t *testing.T) {
+	a := mock.NewMockAuthorizer(false, []influxdb.Permission{readBucketPermission(`host =~ /a/`)})
+	_, err := authorizer.BucketPredicate(a, influxdb.ReadAction, storeOrgID, storeBucketID)
+	if influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected an invalid predicate to be rejected, got %v", err)
+	}
+
+	a = mock.NewMockAuthorizer(false, []influxdb.Permission{readBucketPermission(`host="a"`)})
+	pred, err := authorizer.BucketPredicate(a, influxdb.WriteAction, storeOrgID, storeBucketID)
+	if err != nil || pred != nil {
+		t.Fatalf("expected a read predicate not to allow writes, got %v %v", pred, err)
+	}
+}
+
+func TestAuthorizeWritePoints(t *testing.T) {
+	write := readBucketPermission(`_measurement="cpu" AND customer="acme"`)
+	write.Action = influxdb.WriteAction
+	a := mock.NewMockAuthorizer(false, []influxdb.Permission{write})
+
+	if a.Allowed(influxdb.Permission{Action: influxdb.WriteAction, Resource: write.Resource}) {
+		t.Fatal("expected a permission with a predicate not to allow writing the whole bucket")
+	}
+	pred, err := authorizer.BucketPredicate(a, influxdb.WriteAction, storeOrgID, storeBucketID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pred == nil {
+		t.Fatal("expected a predicate for the bucket")
+	}
+
+	name := tsdb.EncodeName(storeOrgID, storeBucketID)
+	parse := func(data string) []models.Point {
+		points, err := models.ParsePointsWithOptions([]byte(data), models.EscapeMeasurement(name[:]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return points
+	}
+
+	if err := authorizer.AuthorizeWritePoints(pred, parse("cpu,customer=acme,host=a usage=1,idle=2 10\n")); err != nil {
+		t.Fatalf("expected points to be allowed, got %v", err)
+	}
+	for _, data := range []string{
+		"cpu,customer=globex,host=a usage=1 10\n",
+		"cpu,host=a usage=1 10\n",
+		"cpu,customer=acme usage=1 10\nmem,customer=acme used=1 10\n",
+	} {
+		err := authorizer.AuthorizeWritePoints(pred, parse(data))
+		if influxdb.ErrorCode(err) != influxdb.EForbidden {
+			t.Errorf("expected points %q to be forbidden, got %v", data, err)
+		}
+	}
+}
@@ -205,14 +205,23 @@ func (t ResourceType) Valid() (err error) {
 type Permission struct {
 	Action   Action   `json:"action"`
 	Resource Resource `json:"resource"`
+	// Predicate restricts a permission on buckets to the series that match
+	// it, in the syntax of the delete predicate.
+	Predicate string `json:"predicate,omitempty"`
 }
 
 // Matches returns whether or not one permission matches the other.
+// A permission with a predicate only matches permissions with the same
+// predicate, as it does not allow the whole of the resource.
 func (p Permission) Matches(perm Permission) bool {
 	if p.Action != perm.Action {
 		return false
 	}
 
+	if p.Predicate != "" && p.Predicate != perm.Predicate {
+		return false
+	}
+
 	if p.Resource.Type != perm.Resource.Type {
 		return false
 	}
@@ -245,6 +254,9 @@ func (p Permission) Matches(perm Permission) bool {
 }
 
 func (p Permission) String() string {
+	if p.Predicate != "" {
+		return fmt.Sprintf("%s:%s[%s]", p.Action, p.Resource, p.Predicate)
+	}
 	return fmt.Sprintf("%s:%s", p.Action, p.Resource)
 }
 
@@ -282,6 +294,13 @@ func (p *Permission) Valid() error {
 		}
 	}
 
+	if p.Predicate != "" && p.Resource.Type != BucketsResourceType {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "only permissions for buckets may have a predicate",
+		}
+	}
+
 	return nil
 }
 
@@ -229,6 +229,100 @@ func TestAuthorizer_PermissionAllowed(t *testing.T) {
 			},
 			allowed: false,
 		},
+		{
+			name: "predicate does not allow whole resource",
+			permission: platform.Permission{
+				Action: platform.ReadAction,
+				Resource: platform.Resource{
+					Type:  platform.BucketsResourceType,
+					OrgID: influxdbtesting.IDPtr(1),
+					ID:    influxdbtesting.IDPtr(1),
+				},
+			},
+			permissions: []platform.Permission{
+				{
+					Action: platform.ReadAction,
+					Resource: platform.Resource{
+						Type:  platform.BucketsResourceType,
+						OrgID: influxdbtesting.IDPtr(1),
+						ID:    influxdbtesting.IDPtr(1),
+					},
+					Predicate: `host="a"`,
+				},
+			},
+			allowed: false,
+		},
+		{
+			name: "predicate allows same predicate",
+			permission: platform.Permission{
+				Action: platform.WriteAction,
+				Resource: platform.Resource{
+					Type:  platform.BucketsResourceType,
+					OrgID: influxdbtesting.IDPtr(1),
+					ID:    influxdbtesting.IDPtr(1),
+				},
+				Predicate: `host="a"`,
+			},
+			permissions: []platform.Permission{
+				{
+					Action: platform.WriteAction,
+					Resource: platform.Resource{
+						Type:  platform.BucketsResourceType,
+						OrgID: influxdbtesting.IDPtr(1),
+						ID:    influxdbtesting.IDPtr(1),
+					},
+					Predicate: `host="a"`,
+				},
+			},
+			allowed: true,
+		},
+		{
+			name: "predicate does not allow other predicate",
+			permission: platform.Permission{
+				Action: platform.ReadAction,
+				Resource: platform.Resource{
+					Type:  platform.BucketsResourceType,
+					OrgID: influxdbtesting.IDPtr(1),
+					ID:    influxdbtesting.IDPtr(1),
+				},
+				Predicate: `host="b"`,
+			},
+			permissions: []platform.Permission{
+				{
+					Action: platform.ReadAction,
+					Resource: platform.Resource{
+						Type:  platform.BucketsResourceType,
+						OrgID: influxdbtesting.IDPtr(1),
+						ID:    influxdbtesting.IDPtr(1),
+					},
+					Predicate: `host="a"`,
+				},
+			},
+			allowed: false,
+		},
+		{
+			name: "whole resource allows predicate",
+			permission: platform.Permission{
+				Action: platform.ReadAction,
+				Resource: platform.Resource{
+					Type:  platform.BucketsResourceType,
+					OrgID: influxdbtesting.IDPtr(1),
+					ID:    influxdbtesting.IDPtr(1),
+				},
+				Predicate: `host="a"`,
+			},
+			permissions: []platform.Permission{
+				{
+					Action: platform.ReadAction,
+					Resource: platform.Resource{
+						Type:  platform.BucketsResourceType,
+						OrgID: influxdbtesting.IDPtr(1),
+						ID:    influxdbtesting.IDPtr(1),
+					},
+				},
+			},
+			allowed: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -243,8 +337,9 @@ func TestAuthorizer_PermissionAllowed(t *testing.T) {
 
 func TestPermission_Valid(t *testing.T) {
 	type fields struct {
-		Action   platform.Action
-		Resource platform.Resource
+		Action    platform.Action
+		Resource  platform.Resource
+		Predicate string
 	}
 	tests := []struct {
 		name    string
@@ -302,12 +397,36 @@ func TestPermission_Valid(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid bucket permission with a predicate",
+			fields: fields{
+				Action: platform.ReadAction,
+				Resource: platform.Resource{
+					Type:  platform.BucketsResourceType,
+					OrgID: influxdbtesting.IDPtr(1),
+				},
+				Predicate: `_measurement="cpu"`,
+			},
+		},
+		{
+			name: "invalid task permission with a predicate",
+			fields: fields{
+				Action: platform.ReadAction,
+				Resource: platform.Resource{
+					Type:  platform.TasksResourceType,
+					OrgID: influxdbtesting.IDPtr(1),
+				},
+				Predicate: `_measurement="cpu"`,
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			p := &platform.Permission{
-				Action:   tt.fields.Action,
-				Resource: tt.fields.Resource,
+				Action:    tt.fields.Action,
+				Resource:  tt.fields.Resource,
+				Predicate: tt.fields.Predicate,
 			}
 			if err := p.Valid(); (err != nil) != tt.wantErr {
 				t.Errorf("Permission.Valid() error = %v, wantErr %v", err, tt.wantErr)
@@ -27,6 +27,7 @@ const (
 const (
 	TasksSystemBucketName      = "_tasks"
 	MonitoringSystemBucketName = "_monitoring"
+	UsageSystemBucketName      = "_usage"
 )
 
 // InfiniteRetention is default infinite retention period.
@@ -41,9 +42,42 @@ type Bucket struct {
 	Description         string        `json:"description"`
 	RetentionPolicyName string        `json:"rp,omitempty"` // This to support v1 sources
 	RetentionPeriod     time.Duration `json:"retentionPeriod"`
+	// Durability is when the writes to the bucket are acknowledged by the
+	// WAL, rather than with the durability the WAL is configured with.
+	Durability BucketDurability `json:"durability,omitempty"`
 	CRUDLog
 }
 
+// BucketDurability is when the writes to a bucket are acknowledged by the WAL.
+type BucketDurability string
+
+// bucket durabilities.
+const (
+	// BucketDurabilityDefault uses the durability the WAL is configured with.
+	BucketDurabilityDefault BucketDurability = ""
+	// BucketDurabilitySync fsyncs the WAL before each write is acknowledged.
+	BucketDurabilitySync BucketDurability = "sync"
+	// BucketDurabilityGroup fsyncs concurrent writes together.
+	BucketDurabilityGroup BucketDurability = "group"
+	// BucketDurabilityAsync acknowledges writes before they are fsynced.
+	BucketDurabilityAsync BucketDurability = "async"
+	// BucketDurabilityNone skips the WAL, so that writes are lost if the
+	// server stops before the cache is snapshotted.
+	BucketDurabilityNone BucketDurability = "none"
+)
+
+// Valid returns an error if the durability is unknown.
+func (d BucketDurability) Valid() error {
+	switch d {
+	case BucketDurabilityDefault, BucketDurabilitySync, BucketDurabilityGroup, BucketDurabilityAsync, BucketDurabilityNone:
+		return nil
+	}
+	return &Error{
+		Code: EInvalid,
+		Msg:  "invalid durability " + string(d) + "; valid durabilities are sync, group, async and none",
+	}
+}
+
 // BucketType differentiates system buckets from user buckets.
 type BucketType int
 
@@ -101,9 +135,10 @@ type BucketService interface {
 // BucketUpdate represents updates to a bucket.
 // Only fields which are set are updated.
 type BucketUpdate struct {
-	Name            *string        `json:"name,omitempty"`
-	Description     *string        `json:"description,omitempty"`
-	RetentionPeriod *time.Duration `json:"retentionPeriod,omitempty"`
+	Name            *string           `json:"name,omitempty"`
+	Description     *string           `json:"description,omitempty"`
+	RetentionPeriod *time.Duration    `json:"retentionPeriod,omitempty"`
+	Durability      *BucketDurability `json:"durability,omitempty"`
 }
 
 // BucketFilter represents a set of filter that restrict the returned results.
@@ -0,0 +1,201 @@
+package oauth2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	gojwt "github.com/dgrijalva/jwt-go"
+	"golang.org/x/oauth2"
+)
+
+var _ ExtendedProvider = &OIDC{}
+
+const (
+	// DefaultOIDCPrincipalClaim is the claim of the principal of OIDC users.
+	DefaultOIDCPrincipalClaim = "email"
+	// DefaultOIDCGroupsClaim is the claim of the groups of OIDC users.
+	DefaultOIDCGroupsClaim = "groups"
+)
+
+// OIDC is an OpenID Connect provider. The principal and groups of the user
+// are read from the claims of the id_token, or else from the userinfo
+// endpoint of the provider. Group returns the groups of the groups claim,
+// which may be a list or a single string, as a comma delimited list.
+type OIDC struct {
+	PageName       string // Name displayed on the login page
+	ClientID       string
+	ClientSecret   string
+	RequiredScopes []string
+	RedirectURL    string
+	AuthURL        string
+	TokenURL       string
+	UserinfoURL    string
+	PrincipalClaim string // PrincipalClaim defaults to DefaultOIDCPrincipalClaim
+	GroupsClaim    string // GroupsClaim defaults to DefaultOIDCGroupsClaim
+}
+
+// OIDCConfiguration is the OpenID Provider Configuration Information
+// published by providers at /.well-known/openid-configuration.
+type OIDCConfiguration struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// DiscoverOIDC fetches the configuration of the provider of the issuer.
+func DiscoverOIDC(ctx context.Context, client *http.Client, issuer string) (*OIDCConfiguration, error) {
+	req, err := http.NewRequest("GET", strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to discover OpenID configuration of %s: %s", issuer, resp.Status)
+	}
+
+	var conf OIDCConfiguration
+	if err := json.NewDecoder(resp.Body).Decode(&conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
+
+// Name is the name of the provider
+func (o *OIDC) Name() string {
+	if o.PageName == "" {
+		return "oidc"
+	}
+	return o.PageName
+}
+
+// ID returns the OIDC application client id
+func (o *OIDC) ID() string {
+	return o.ClientID
+}
+
+// Secret returns the OIDC application client secret
+func (o *OIDC) Secret() string {
+	return o.ClientSecret
+}
+
+// Scopes for OIDC provider required of the client.
+func (o *OIDC) Scopes() []string {
+	if len(o.RequiredScopes) == 0 {
+		return []string{"openid", "email", "profile"}
+	}
+	return o.RequiredScopes
+}
+
+// Config is the OIDC OAuth2 exchange information and endpoints
+func (o *OIDC) Config() *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     o.ID(),
+		ClientSecret: o.Secret(),
+		Scopes:       o.Scopes(),
+		RedirectURL:  o.RedirectURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  o.AuthURL,
+			TokenURL: o.TokenURL,
+		},
+	}
+}
+
+// PrincipalID returns the principal claim of the userinfo of the user.
+func (o *OIDC) PrincipalID(provider *http.Client) (string, error) {
+	claims, err := o.userinfo(provider)
+	if err != nil {
+		return "", err
+	}
+	return o.PrincipalIDFromClaims(claims)
+}
+
+// Group returns the groups claim of the userinfo of the user.
+func (o *OIDC) Group(provider *http.Client) (string, error) {
+	claims, err := o.userinfo(provider)
+	if err != nil {
+		return "", err
+	}
+	return o.GroupFromClaims(claims)
+}
+
+// PrincipalIDFromClaims returns the principal claim of the user. The email
+// claim is only returned if the provider verified the email address, since
+// anyone could otherwise sign in as the user of the address.
+func (o *OIDC) PrincipalIDFromClaims(claims gojwt.MapClaims) (string, error) {
+	key := o.PrincipalClaim
+	if key == "" {
+		key = DefaultOIDCPrincipalClaim
+	}
+	id, ok := claims[key].(string)
+	if !ok || id == "" {
+		return "", fmt.Errorf("no claim for %s", key)
+	}
+	if key == "email" && !emailVerified(claims) {
+		return "", fmt.Errorf("email %s is not verified", id)
+	}
+	return id, nil
+}
+
+// emailVerified returns whether the email_verified claim is true. Some
+// providers send it as a string.
+func emailVerified(claims gojwt.MapClaims) bool {
+	switch v := claims["email_verified"].(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	default:
+		return false
+	}
+}
+
+// GroupFromClaims returns the groups claim of the user as a comma delimited
+// list. Users with no groups claim have no groups.
+func (o *OIDC) GroupFromClaims(claims gojwt.MapClaims) (string, error) {
+	key := o.GroupsClaim
+	if key == "" {
+		key = DefaultOIDCGroupsClaim
+	}
+	switch groups := claims[key].(type) {
+	case string:
+		return groups, nil
+	case []interface{}:
+		names := make([]string, 0, len(groups))
+		for _, g := range groups {
+			if name, ok := g.(string); ok {
+				names = append(names, name)
+			}
+		}
+		return strings.Join(names, ","), nil
+	case nil:
+		return "", nil
+	default:
+		return "", fmt.Errorf("invalid claim for %s", key)
+	}
+}
+
+func (o *OIDC) userinfo(provider *http.Client) (gojwt.MapClaims, error) {
+	r, err := provider.Get(o.UserinfoURL)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to get OIDC userinfo: %s", r.Status)
+	}
+
+	claims := gojwt.MapClaims{}
+	if err := json.NewDecoder(r.Body).Decode(&claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
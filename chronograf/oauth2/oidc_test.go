@@ -0,0 +1,130 @@
+package oauth2_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gojwt "github.com/dgrijalva/jwt-go"
+	"github.com/influxdata/influxdb/v2/chronograf/oauth2"
+)
+
+func TestOIDC_Userinfo(t *testing.T) {
+	t.Parallel()
+
+	mockAPI := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/userinfo" {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(rw).Encode(map[string]interface{}{
+			"sub":            "1234",
+			"email":          "martymcfly@pinheads.rok",
+			"email_verified": true,
+			"groups":         []string{"hill-valley", "pinheads"},
+		})
+	}))
+	defer mockAPI.Close()
+
+	prov := oauth2.OIDC{
+		UserinfoURL: mockAPI.URL + "/userinfo",
+	}
+
+	id, err := prov.PrincipalID(http.DefaultClient)
+	if err != nil {
+		t.Fatal("Unexpected error while retrieving PrincipalID: err:", err)
+	}
+	if want := "martymcfly@pinheads.rok"; id != want {
+		t.Fatal("Retrieved email was not as expected. Want:", want, "Got:", id)
+	}
+
+	group, err := prov.Group(http.DefaultClient)
+	if err != nil {
+		t.Fatal("Unexpected error while retrieving Group: err:", err)
+	}
+	if want := "hill-valley,pinheads"; group != want {
+		t.Fatal("Retrieved group was not as expected. Want:", want, "Got:", group)
+	}
+}
+
+func TestOIDC_Claims(t *testing.T) {
+	t.Parallel()
+
+	prov := oauth2.OIDC{
+		PrincipalClaim: "preferred_username",
+		GroupsClaim:    "roles",
+	}
+	claims := gojwt.MapClaims{
+		"email":              "martymcfly@pinheads.rok",
+		"preferred_username": "marty",
+		"roles":              "pinheads",
+	}
+
+	id, err := prov.PrincipalIDFromClaims(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "marty"; id != want {
+		t.Fatal("Retrieved principal was not as expected. Want:", want, "Got:", id)
+	}
+	group, err := prov.GroupFromClaims(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "pinheads"; group != want {
+		t.Fatal("Retrieved group was not as expected. Want:", want, "Got:", group)
+	}
+
+	if _, err := prov.PrincipalIDFromClaims(gojwt.MapClaims{}); err == nil {
+		t.Fatal("Expected an error for a missing principal claim")
+	}
+
+	// Email principals must be verified by the provider.
+	prov.PrincipalClaim = ""
+	if _, err := prov.PrincipalIDFromClaims(claims); err == nil {
+		t.Fatal("Expected an error for an unverified email")
+	}
+	claims["email_verified"] = "true"
+	if id, err := prov.PrincipalIDFromClaims(claims); err != nil || id != "martymcfly@pinheads.rok" {
+		t.Fatal("Expected the verified email, got:", id, err)
+	}
+	if group, err := prov.GroupFromClaims(gojwt.MapClaims{}); err != nil || group != "" {
+		t.Fatal("Expected no groups for a missing groups claim, got:", group, err)
+	}
+}
+
+func TestDiscoverOIDC(t *testing.T) {
+	t.Parallel()
+
+	var issuer string
+	mockAPI := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.well-known/openid-configuration" {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(rw).Encode(oauth2.OIDCConfiguration{
+			Issuer:                issuer,
+			AuthorizationEndpoint: issuer + "/authorize",
+			TokenEndpoint:         issuer + "/token",
+			UserinfoEndpoint:      issuer + "/userinfo",
+			JWKSURI:               issuer + "/jwks",
+		})
+	}))
+	defer mockAPI.Close()
+	issuer = mockAPI.URL
+
+	conf, err := oauth2.DiscoverOIDC(context.Background(), http.DefaultClient, issuer+"/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf.TokenEndpoint != issuer+"/token" || conf.UserinfoEndpoint != issuer+"/userinfo" {
+		t.Fatalf("unexpected configuration: %+v", conf)
+	}
+
+	if _, err := oauth2.DiscoverOIDC(context.Background(), http.DefaultClient, issuer+"/missing"); err == nil {
+		t.Fatal("Expected an error for an issuer with no configuration")
+	}
+}
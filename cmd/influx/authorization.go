@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"io"
+	"time"
 
 	platform "github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/cmd/influx/internal"
@@ -12,11 +13,33 @@ import (
 
 type token struct {
 	ID          platform.ID `json:"id"`
-	Token       string      `json:"token"`
+	Token       string      `json:"token,omitempty"`
 	Status      string      `json:"status"`
 	UserName    string      `json:"userName"`
 	UserID      platform.ID `json:"userID"`
 	Permissions []string    `json:"permissions"`
+	ExpiresAt   *time.Time  `json:"expiresAt,omitempty"`
+	LastUsedAt  *time.Time  `json:"lastUsedAt,omitempty"`
+	LastUsedIP  string      `json:"lastUsedIP,omitempty"`
+}
+
+func newToken(a *platform.Authorization, user *platform.User) token {
+	ps := make([]string, 0, len(a.Permissions))
+	for _, p := range a.Permissions {
+		ps = append(ps, p.String())
+	}
+
+	return token{
+		ID:          a.ID,
+		Token:       a.Token,
+		Status:      string(a.Status),
+		UserName:    user.Name,
+		UserID:      user.ID,
+		Permissions: ps,
+		ExpiresAt:   a.ExpiresAt,
+		LastUsedAt:  a.LastUsedAt,
+		LastUsedIP:  a.LastUsedIP,
+	}
 }
 
 func cmdAuth(f *globalFlags, opt genericCLIOpts) *cobra.Command {
@@ -31,6 +54,7 @@ func cmdAuth(f *globalFlags, opt genericCLIOpts) *cobra.Command {
 		authDeleteCmd(),
 		authFindCmd(),
 		authInactiveCmd(),
+		authRotateCmd(),
 	)
 
 	return cmd
@@ -43,8 +67,9 @@ var authCRUDFlags struct {
 }
 
 var authCreateFlags struct {
-	user string
-	org  organization
+	user      string
+	org       organization
+	expiresIn time.Duration
 
 	writeUserPermission bool
 	readUserPermission  bool
@@ -81,11 +106,14 @@ func authCreateCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "create",
 		Short: "Create authorization",
-		RunE:  checkSetupRunEMiddleware(&flags)(authorizationCreateF),
+		Long: `Create an authorization. Tokens are stored hashed, so the token of the
+authorization is only shown when it is created.`,
+		RunE: checkSetupRunEMiddleware(&flags)(authorizationCreateF),
 	}
 	authCreateFlags.org.register(cmd, false)
 
 	cmd.Flags().StringVarP(&authCreateFlags.user, "user", "u", "", "The user name")
+	cmd.Flags().DurationVarP(&authCreateFlags.expiresIn, "expires-in", "", 0, "The duration after which the token expires, it never expires if unset")
 	registerPrintOptions(cmd, &authCRUDFlags.hideHeaders, &authCRUDFlags.json)
 
 	cmd.Flags().BoolVarP(&authCreateFlags.writeUserPermission, "write-user", "", false, "Grants the permission to perform mutative actions against organization users")
@@ -240,6 +268,10 @@ func authorizationCreateF(cmd *cobra.Command, args []string) error {
 		Permissions: permissions,
 		OrgID:       orgID,
 	}
+	if authCreateFlags.expiresIn > 0 {
+		expiresAt := time.Now().Add(authCreateFlags.expiresIn)
+		authorization.ExpiresAt = &expiresAt
+	}
 
 	if userName := authCreateFlags.user; userName != "" {
 		user, err := userSvc.FindUser(context.Background(), platform.UserFilter{
@@ -265,22 +297,10 @@ func authorizationCreateF(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	ps := make([]string, 0, len(authorization.Permissions))
-	for _, p := range authorization.Permissions {
-		ps = append(ps, p.String())
-	}
-
 	return writeTokens(cmd.OutOrStdout(), tokenPrintOpt{
 		jsonOut:     authCRUDFlags.json,
 		hideHeaders: authCRUDFlags.hideHeaders,
-		token: token{
-			ID:          authorization.ID,
-			Token:       authorization.Token,
-			Status:      string(authorization.Status),
-			UserName:    user.Name,
-			UserID:      user.ID,
-			Permissions: ps,
-		},
+		token:       newToken(authorization, user),
 	})
 }
 
@@ -355,24 +375,12 @@ func authorizationFindF(cmd *cobra.Command, args []string) error {
 
 	var tokens []token
 	for _, a := range authorizations {
-		var permissions []string
-		for _, p := range a.Permissions {
-			permissions = append(permissions, p.String())
-		}
-
 		user, err := us.FindUserByID(context.Background(), a.UserID)
 		if err != nil {
 			return err
 		}
 
-		tokens = append(tokens, token{
-			ID:          a.ID,
-			Token:       a.Token,
-			Status:      string(a.Status),
-			UserName:    user.Name,
-			UserID:      a.UserID,
-			Permissions: permissions,
-		})
+		tokens = append(tokens, newToken(a, user))
 	}
 
 	return writeTokens(cmd.OutOrStdout(), tokenPrintOpt{
@@ -427,23 +435,11 @@ func authorizationDeleteF(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	ps := make([]string, 0, len(a.Permissions))
-	for _, p := range a.Permissions {
-		ps = append(ps, p.String())
-	}
-
 	return writeTokens(cmd.OutOrStdout(), tokenPrintOpt{
 		jsonOut:     authCRUDFlags.json,
 		deleted:     true,
 		hideHeaders: authCRUDFlags.hideHeaders,
-		token: token{
-			ID:          a.ID,
-			Token:       a.Token,
-			Status:      string(a.Status),
-			UserName:    user.Name,
-			UserID:      user.ID,
-			Permissions: ps,
-		},
+		token:       newToken(a, user),
 	})
 }
 
@@ -494,22 +490,10 @@ func authorizationActiveF(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	ps := make([]string, 0, len(a.Permissions))
-	for _, p := range a.Permissions {
-		ps = append(ps, p.String())
-	}
-
 	return writeTokens(cmd.OutOrStdout(), tokenPrintOpt{
 		jsonOut:     authCRUDFlags.json,
 		hideHeaders: authCRUDFlags.hideHeaders,
-		token: token{
-			ID:          a.ID,
-			Token:       a.Token,
-			Status:      string(a.Status),
-			UserName:    user.Name,
-			UserID:      user.ID,
-			Permissions: ps,
-		},
+		token:       newToken(a, user),
 	})
 }
 
@@ -560,22 +544,86 @@ func authorizationInactiveF(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	ps := make([]string, 0, len(a.Permissions))
-	for _, p := range a.Permissions {
-		ps = append(ps, p.String())
+	return writeTokens(cmd.OutOrStdout(), tokenPrintOpt{
+		jsonOut:     authCRUDFlags.json,
+		hideHeaders: authCRUDFlags.hideHeaders,
+		token:       newToken(a, user),
+	})
+}
+
+var authRotateFlags struct {
+	gracePeriod time.Duration
+	expiresIn   time.Duration
+}
+
+func authRotateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rotate",
+		Short: "Rotate authorization token",
+		Long: `Rotate the token of an authorization. A new authorization with the same
+permissions and a new token is created, and the token of the rotated
+authorization expires once the grace period has passed. The new token is
+only shown when it is created.`,
+		RunE: checkSetupRunEMiddleware(&flags)(authorizationRotateF),
+	}
+
+	registerPrintOptions(cmd, &authCRUDFlags.hideHeaders, &authCRUDFlags.json)
+	cmd.Flags().StringVarP(&authCRUDFlags.id, "id", "i", "", "The authorization ID (required)")
+	cmd.MarkFlagRequired("id")
+	cmd.Flags().DurationVarP(&authRotateFlags.gracePeriod, "grace-period", "", 0, "The duration the rotated token remains valid for")
+	cmd.Flags().DurationVarP(&authRotateFlags.expiresIn, "expires-in", "", 0, "The duration after which the new token expires, it never expires if unset")
+
+	return cmd
+}
+
+// authorizationRotator rotates the tokens of authorizations in a single
+// request, rather than with the requests of platform.RotateAuthorization.
+type authorizationRotator interface {
+	RotateAuthorization(ctx context.Context, id platform.ID, rot platform.AuthorizationRotation) (*platform.Authorization, error)
+}
+
+func authorizationRotateF(cmd *cobra.Command, args []string) error {
+	s, err := newAuthorizationService()
+	if err != nil {
+		return err
+	}
+
+	us, err := newUserService()
+	if err != nil {
+		return err
+	}
+
+	var id platform.ID
+	if err := id.DecodeFromString(authCRUDFlags.id); err != nil {
+		return err
+	}
+
+	rot := platform.AuthorizationRotation{GracePeriod: authRotateFlags.gracePeriod}
+	if authRotateFlags.expiresIn > 0 {
+		expiresAt := time.Now().Add(authRotateFlags.expiresIn)
+		rot.ExpiresAt = &expiresAt
+	}
+
+	ctx := context.Background()
+	var a *platform.Authorization
+	if r, ok := s.(authorizationRotator); ok {
+		a, err = r.RotateAuthorization(ctx, id, rot)
+	} else {
+		a, err = platform.RotateAuthorization(ctx, s, id, rot)
+	}
+	if err != nil {
+		return err
+	}
+
+	user, err := us.FindUserByID(ctx, a.UserID)
+	if err != nil {
+		return err
 	}
 
 	return writeTokens(cmd.OutOrStdout(), tokenPrintOpt{
 		jsonOut:     authCRUDFlags.json,
 		hideHeaders: authCRUDFlags.hideHeaders,
-		token: token{
-			ID:          a.ID,
-			Token:       a.Token,
-			Status:      string(a.Status),
-			UserName:    user.Name,
-			UserID:      user.ID,
-			Permissions: ps,
-		},
+		token:       newToken(a, user),
 	})
 }
 
@@ -601,29 +649,43 @@ func writeTokens(w io.Writer, printOpts tokenPrintOpt) error {
 
 	tabW.HideHeaders(printOpts.hideHeaders)
 
-	headers := []string{
-		"ID",
-		"Token",
+	if printOpts.tokens == nil {
+		printOpts.tokens = append(printOpts.tokens, printOpts.token)
+	}
+
+	// tokens are only known when they are created, so only then are they shown.
+	var showTokens bool
+	for _, t := range printOpts.tokens {
+		showTokens = showTokens || t.Token != ""
+	}
+
+	headers := []string{"ID"}
+	if showTokens {
+		headers = append(headers, "Token")
+	}
+	headers = append(headers,
 		"User Name",
 		"User ID",
 		"Permissions",
-	}
+		"Expires At",
+		"Last Used At",
+		"Last Used IP",
+	)
 	if printOpts.deleted {
 		headers = append(headers, "Deleted")
 	}
 	tabW.WriteHeaders(headers...)
 
-	if printOpts.tokens == nil {
-		printOpts.tokens = append(printOpts.tokens, printOpts.token)
-	}
-
 	for _, t := range printOpts.tokens {
 		m := map[string]interface{}{
-			"ID":          t.ID.String(),
-			"Token":       t.Token,
-			"User Name":   t.UserName,
-			"User ID":     t.UserID.String(),
-			"Permissions": t.Permissions,
+			"ID":           t.ID.String(),
+			"Token":        t.Token,
+			"User Name":    t.UserName,
+			"User ID":      t.UserID.String(),
+			"Permissions":  t.Permissions,
+			"Expires At":   formatTokenTime(t.ExpiresAt),
+			"Last Used At": formatTokenTime(t.LastUsedAt),
+			"Last Used IP": t.LastUsedIP,
 		}
 		if printOpts.deleted {
 			m["Deleted"] = true
@@ -634,6 +696,13 @@ func writeTokens(w io.Writer, printOpts tokenPrintOpt) error {
 	return nil
 }
 
+func formatTokenTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
 func newAuthorizationService() (platform.AuthorizationService, error) {
 	if flags.local {
 		return newLocalKVService()
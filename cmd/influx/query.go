@@ -1,20 +1,27 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"strings"
 
 	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
 	_ "github.com/influxdata/flux/stdlib"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/http"
+	"github.com/influxdata/influxdb/v2/query"
 	_ "github.com/influxdata/influxdb/v2/query/stdlib"
 	"github.com/spf13/cobra"
 )
 
 var queryFlags struct {
-	org  organization
-	file string
+	org     organization
+	file    string
+	explain bool
 }
 
 func cmdQuery(f *globalFlags, opts genericCLIOpts) *cobra.Command {
@@ -25,6 +32,14 @@ func cmdQuery(f *globalFlags, opts genericCLIOpts) *cobra.Command {
 
 	queryFlags.org.register(cmd, true)
 	cmd.Flags().StringVarP(&queryFlags.file, "file", "f", "", "Path to Flux query file")
+	cmd.Flags().BoolVar(&queryFlags.explain, "explain", false, "Print the logical and physical plans of the query instead of executing it")
+
+	builder := newCmdActiveQueryBuilder(newActiveQuerySVCs, opts)
+	builder.globalFlags = f
+	cmd.AddCommand(
+		builder.cmdPS(),
+		builder.cmdKill(),
+	)
 
 	return cmd
 }
@@ -86,6 +101,10 @@ func fluxQueryF(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if queryFlags.explain {
+		return explainFluxQuery(cmd.OutOrStdout(), orgID, q)
+	}
+
 	flux.FinalizeBuiltIns()
 
 	r, err := getFluxREPL(flags.Host, flags.Token, flags.skipVerify, orgID)
@@ -99,3 +118,23 @@ func fluxQueryF(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// explainFluxQuery prints how the server plans the query.
+func explainFluxQuery(w io.Writer, orgID influxdb.ID, q string) error {
+	client, err := newHTTPClient()
+	if err != nil {
+		return err
+	}
+
+	svc := &http.QueryExplainService{Client: client}
+	e, err := svc.Explain(context.Background(), &query.Request{
+		OrganizationID: orgID,
+		Compiler:       lang.FluxCompiler{Query: q},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to explain query: %v", err)
+	}
+
+	_, err = io.WriteString(w, e.Text)
+	return err
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/http"
+	"github.com/influxdata/influxdb/v2/query"
+	"github.com/spf13/cobra"
+)
+
+type activeQuerySVCsFn func() (query.ActiveQueryService, influxdb.OrganizationService, error)
+
+type cmdActiveQueryBuilder struct {
+	genericCLIOpts
+	*globalFlags
+
+	svcFn activeQuerySVCsFn
+
+	json        bool
+	hideHeaders bool
+	org         organization
+}
+
+func newCmdActiveQueryBuilder(svcsFn activeQuerySVCsFn, opt genericCLIOpts) *cmdActiveQueryBuilder {
+	return &cmdActiveQueryBuilder{
+		genericCLIOpts: opt,
+		svcFn:          svcsFn,
+	}
+}
+
+func (b *cmdActiveQueryBuilder) cmdPS() *cobra.Command {
+	cmd := b.newCmd("ps", b.cmdPSRunEFn, true)
+	cmd.Short = "List the queries that are queued or executing"
+	cmd.Long = `List the queries that are queued or executing in every organization that
+you may read, or only in the given organization.`
+
+	b.org.register(cmd, false)
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdActiveQueryBuilder) cmdPSRunEFn(cmd *cobra.Command, args []string) error {
+	querySVC, orgSVC, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+
+	var filter query.ActiveQueryFilter
+	if b.org.id != "" || b.org.name != "" {
+		orgID, err := b.org.getID(orgSVC)
+		if err != nil {
+			return err
+		}
+		filter.OrganizationID = &orgID
+	}
+
+	queries, err := querySVC.FindActiveQueries(context.Background(), filter)
+	if err != nil {
+		return fmt.Errorf("failed to list queries: %v", err)
+	}
+
+	return b.printQueries(activeQueryPrintOpt{
+		queries: queries,
+	})
+}
+
+func (b *cmdActiveQueryBuilder) cmdKill() *cobra.Command {
+	cmd := b.newCmd("kill <query-id>", b.cmdKillRunEFn, true)
+	cmd.Short = "Cancel a query that is queued or executing"
+	cmd.Args = cobra.ExactArgs(1)
+
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdActiveQueryBuilder) cmdKillRunEFn(cmd *cobra.Command, args []string) error {
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid query ID %q: %v", args[0], err)
+	}
+
+	querySVC, _, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	q, err := querySVC.FindActiveQueryByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to find query with ID %d: %v", id, err)
+	}
+	if err := querySVC.CancelActiveQuery(ctx, id); err != nil {
+		return fmt.Errorf("failed to cancel query with ID %d: %v", id, err)
+	}
+
+	return b.printQueries(activeQueryPrintOpt{
+		canceled: true,
+		queries:  []*query.ActiveQuery{q},
+	})
+}
+
+func (b *cmdActiveQueryBuilder) registerPrintFlags(cmd *cobra.Command) {
+	registerPrintOptions(cmd, &b.hideHeaders, &b.json)
+}
+
+func (b *cmdActiveQueryBuilder) printQueries(opt activeQueryPrintOpt) error {
+	if b.json {
+		var v interface{} = opt.queries
+		if opt.canceled {
+			v = opt.queries[0]
+		}
+		return b.writeJSON(v)
+	}
+
+	w := b.newTabWriter()
+	defer w.Flush()
+
+	w.HideHeaders(b.hideHeaders)
+
+	headers := []string{
+		"ID",
+		"Organization ID",
+		"User ID",
+		"Token",
+		"State",
+		"Elapsed",
+		"Memory Bytes",
+		"Query",
+	}
+	if opt.canceled {
+		headers = append(headers, "Canceled")
+	}
+	w.WriteHeaders(headers...)
+
+	for _, q := range opt.queries {
+		var userID string
+		if q.UserID.Valid() {
+			userID = q.UserID.String()
+		}
+		m := map[string]interface{}{
+			"ID":              q.ID,
+			"Organization ID": q.OrganizationID.String(),
+			"User ID":         userID,
+			"Token":           q.TokenDescription,
+			"State":           q.State,
+			"Elapsed":         q.Elapsed.Round(time.Millisecond).String(),
+			"Memory Bytes":    q.MemoryBytes,
+			// Print the query on a single line to keep the table intact.
+			"Query": strings.Join(strings.Fields(q.Query), " "),
+		}
+		if opt.canceled {
+			m["Canceled"] = true
+		}
+		w.Write(m)
+	}
+
+	return nil
+}
+
+type activeQueryPrintOpt struct {
+	canceled bool
+	queries  []*query.ActiveQuery
+}
+
+func newActiveQuerySVCs() (query.ActiveQueryService, influxdb.OrganizationService, error) {
+	httpClient, err := newHTTPClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	orgSvc := &http.OrganizationService{Client: httpClient}
+
+	return &http.ActiveQueryService{Client: httpClient}, orgSvc, nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/query"
+	querymock "github.com/influxdata/influxdb/v2/query/mock"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCmdQueryActive(t *testing.T) {
+	orgID := influxdb.ID(9000)
+
+	fakeSVCFn := func(svc query.ActiveQueryService) activeQuerySVCsFn {
+		return func() (query.ActiveQueryService, influxdb.OrganizationService, error) {
+			return svc, &mock.OrganizationService{
+				FindOrganizationF: func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+					return &influxdb.Organization{ID: orgID, Name: "influxdata"}, nil
+				},
+			}, nil
+		}
+	}
+
+	// cmdFn nests the commands below a query command that, like the
+	// real one, has persistent organization flags.
+	cmdFn := func(svc query.ActiveQueryService) func(*globalFlags, genericCLIOpts) *cobra.Command {
+		return func(g *globalFlags, opt genericCLIOpts) *cobra.Command {
+			cmd := opt.newCmd("query", nil, false)
+			new(organization).register(cmd, true)
+			builder := newCmdActiveQueryBuilder(fakeSVCFn(svc), opt)
+			cmd.AddCommand(builder.cmdPS(), builder.cmdKill())
+			return cmd
+		}
+	}
+
+	activeQuery := &query.ActiveQuery{
+		ID:               7,
+		OrganizationID:   orgID,
+		UserID:           influxdb.ID(3),
+		TokenDescription: "dashboards",
+		Query:            "from(bucket: \"b\")\n  |> range(start: -1h)",
+		State:            "executing",
+		Elapsed:          1500 * time.Millisecond,
+		MemoryBytes:      1024,
+	}
+
+	t.Run("ps", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			flags     []string
+			wantOrgID *influxdb.ID
+		}{
+			{
+				name: "every org",
+			},
+			{
+				name:      "org id",
+				flags:     []string{"--org-id=" + orgID.String()},
+				wantOrgID: &orgID,
+			},
+			{
+				name:      "org",
+				flags:     []string{"--org=influxdata"},
+				wantOrgID: &orgID,
+			},
+		}
+
+		for _, tt := range tests {
+			fn := func(t *testing.T) {
+				defer addEnvVars(t, envVarsZeroMap)()
+
+				var gotFilter *query.ActiveQueryFilter
+				svc := &querymock.ActiveQueryService{
+					FindActiveQueriesFn: func(ctx context.Context, filter query.ActiveQueryFilter) ([]*query.ActiveQuery, error) {
+						gotFilter = &filter
+						return []*query.ActiveQuery{activeQuery}, nil
+					},
+				}
+
+				outBuf := new(bytes.Buffer)
+				builder := newInfluxCmdBuilder(
+					in(new(bytes.Buffer)),
+					out(outBuf),
+				)
+				cmd := builder.cmd(cmdFn(svc))
+				cmd.SetArgs(append([]string{"query", "ps"}, tt.flags...))
+
+				require.NoError(t, cmd.Execute())
+				require.NotNil(t, gotFilter)
+				assert.Equal(t, tt.wantOrgID, gotFilter.OrganizationID)
+				assert.Contains(t, outBuf.String(), `from(bucket: "b") |> range(start: -1h)`)
+				assert.Contains(t, outBuf.String(), "1.5s")
+			}
+
+			t.Run(tt.name, fn)
+		}
+	})
+
+	t.Run("kill", func(t *testing.T) {
+		var canceled []uint64
+		svc := &querymock.ActiveQueryService{
+			FindActiveQueryByIDFn: func(ctx context.Context, id uint64) (*query.ActiveQuery, error) {
+				if id != activeQuery.ID {
+					return nil, query.ErrActiveQueryNotFound
+				}
+				return activeQuery, nil
+			},
+			CancelActiveQueryFn: func(ctx context.Context, id uint64) error {
+				canceled = append(canceled, id)
+				return nil
+			},
+		}
+
+		builder := newInfluxCmdBuilder(
+			in(new(bytes.Buffer)),
+			out(new(bytes.Buffer)),
+		)
+		cmd := builder.cmd(cmdFn(svc))
+		cmd.SetArgs([]string{"query", "kill", "7"})
+		require.NoError(t, cmd.Execute())
+		assert.Equal(t, []uint64{7}, canceled)
+
+		cmd = builder.cmd(cmdFn(svc))
+		cmd.SetArgs([]string{"query", "kill", "8"})
+		require.Error(t, cmd.Execute())
+		assert.Equal(t, []uint64{7}, canceled)
+	})
+}
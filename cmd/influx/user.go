@@ -17,11 +17,13 @@ type userSVCsFn func() (
 )
 
 type cmdUserDeps struct {
-	userSVC   influxdb.UserService
-	orgSvc    influxdb.OrganizationService
-	passSVC   influxdb.PasswordsService
-	urmSVC    influxdb.UserResourceMappingService
-	getPassFn func(*input.UI, bool) string
+	userSVC    influxdb.UserService
+	orgSvc     influxdb.OrganizationService
+	passSVC    influxdb.PasswordsService
+	urmSVC     influxdb.UserResourceMappingService
+	lockoutSVC influxdb.LockoutService
+	totpSVC    influxdb.TOTPService
+	getPassFn  func(*input.UI, bool) string
 }
 
 func cmdUser(f *globalFlags, opt genericCLIOpts) *cobra.Command {
@@ -61,6 +63,8 @@ func (b *cmdUserBuilder) cmd() *cobra.Command {
 		b.cmdFind(),
 		b.cmdUpdate(),
 		b.cmdPassword(),
+		b.cmdUnlock(),
+		b.cmdResetTOTP(),
 	)
 
 	return cmd
@@ -111,6 +115,62 @@ func (b *cmdUserBuilder) cmdPasswordRunEFn(cmd *cobra.Command, args []string) er
 	return nil
 }
 
+func (b *cmdUserBuilder) cmdUnlock() *cobra.Command {
+	cmd := b.newCmd("unlock", b.cmdUnlockRunEFn, true)
+	cmd.Short = "Unlock a user locked out after failed sign-ins"
+
+	cmd.Flags().StringVarP(&b.id, "id", "i", "", "The user ID (required)")
+	cmd.MarkFlagRequired("id")
+
+	return cmd
+}
+
+func (b *cmdUserBuilder) cmdUnlockRunEFn(cmd *cobra.Command, args []string) error {
+	dep, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+
+	var id influxdb.ID
+	if err := id.DecodeFromString(b.id); err != nil {
+		return err
+	}
+
+	if err := dep.lockoutSVC.ResetLoginAttempts(context.Background(), id); err != nil {
+		return err
+	}
+	fmt.Fprintln(b.w, "The user has been unlocked.")
+	return nil
+}
+
+func (b *cmdUserBuilder) cmdResetTOTP() *cobra.Command {
+	cmd := b.newCmd("reset-totp", b.cmdResetTOTPRunEFn, true)
+	cmd.Short = "Disable the TOTP sign-in of a user, such as when it lost its device"
+
+	cmd.Flags().StringVarP(&b.id, "id", "i", "", "The user ID (required)")
+	cmd.MarkFlagRequired("id")
+
+	return cmd
+}
+
+func (b *cmdUserBuilder) cmdResetTOTPRunEFn(cmd *cobra.Command, args []string) error {
+	dep, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+
+	var id influxdb.ID
+	if err := id.DecodeFromString(b.id); err != nil {
+		return err
+	}
+
+	if err := dep.totpSVC.ResetTOTP(context.Background(), id); err != nil {
+		return err
+	}
+	fmt.Fprintln(b.w, "TOTP has been disabled for the user.")
+	return nil
+}
+
 func (b *cmdUserBuilder) cmdUpdate() *cobra.Command {
 	cmd := b.newCmd("update", b.cmdUpdateRunEFn, true)
 	cmd.Short = "Update user"
@@ -366,13 +426,17 @@ func newUserSVC() (cmdUserDeps, error) {
 	orgSvc := &http.OrganizationService{Client: httpClient}
 	passSvc := &http.PasswordService{Client: httpClient}
 	urmSvc := &http.UserResourceMappingService{Client: httpClient}
+	lockoutSvc := &http.LockoutService{Client: httpClient}
+	totpSvc := &http.TOTPService{Client: httpClient}
 	getPassFn := getPassword
 
 	return cmdUserDeps{
-		userSVC:   userSvc,
-		orgSvc:    orgSvc,
-		passSVC:   passSvc,
-		urmSVC:    urmSvc,
-		getPassFn: getPassFn,
+		userSVC:    userSvc,
+		orgSvc:     orgSvc,
+		passSVC:    passSvc,
+		urmSVC:     urmSvc,
+		lockoutSVC: lockoutSvc,
+		totpSVC:    totpSvc,
+		getPassFn:  getPassFn,
 	}, nil
 }
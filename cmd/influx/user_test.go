@@ -445,4 +445,44 @@ func TestCmdUser(t *testing.T) {
 			t.Run(tt.name, fn)
 		}
 	})
+
+	t.Run("unlock and reset-totp", func(t *testing.T) {
+		expectedID := influxdb.ID(3)
+		var unlocked, reset influxdb.ID
+
+		cmdFn := func(g *globalFlags, opt genericCLIOpts) *cobra.Command {
+			lockoutSVC := mock.NewLockoutService()
+			lockoutSVC.ResetLoginAttemptsFn = func(ctx context.Context, id influxdb.ID) error {
+				unlocked = id
+				return nil
+			}
+			totpSVC := mock.NewTOTPService()
+			totpSVC.ResetTOTPFn = func(ctx context.Context, id influxdb.ID) error {
+				reset = id
+				return nil
+			}
+
+			deps := newCMDUserDeps(mock.NewUserService(), nil, nil)
+			deps.lockoutSVC = lockoutSVC
+			deps.totpSVC = totpSVC
+			builder := newCmdUserBuilder(fakeSVCFn(deps), opt)
+			return builder.cmd()
+		}
+
+		for _, args := range [][]string{
+			{"user", "unlock", "--id=" + expectedID.String()},
+			{"user", "reset-totp", "-i=" + expectedID.String()},
+		} {
+			builder := newInfluxCmdBuilder(
+				in(new(bytes.Buffer)),
+				out(ioutil.Discard),
+			)
+			cmd := builder.cmd(cmdFn)
+			cmd.SetArgs(args)
+			require.NoError(t, cmd.Execute())
+		}
+
+		assert.Equal(t, expectedID, unlocked)
+		assert.Equal(t, expectedID, reset)
+	})
 }
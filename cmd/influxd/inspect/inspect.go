@@ -25,6 +25,8 @@ func NewCommand() *cobra.Command {
 		NewVerifySeriesFileCommand(),
 		NewDumpWALCommand(),
 		NewDumpTSICommand(),
+		NewRepairTSMCommand(),
+		NewRepairWALCommand(),
 	}
 
 	base.AddCommand(subCommands...)
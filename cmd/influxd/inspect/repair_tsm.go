@@ -0,0 +1,155 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/influxdata/influxdb/v2/cmd/influx_inspect/buildtsi"
+	"github.com/influxdata/influxdb/v2/logger"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/tsdb/seriesfile"
+	"github.com/influxdata/influxdb/v2/tsdb/tsi1"
+	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
+	"github.com/spf13/cobra"
+)
+
+// repairTSMFlags defines the `repair-tsm` Command.
+var repairTSMFlags = struct {
+	backup     bool
+	dryRun     bool
+	reportPath string
+
+	rebuildIndex   bool
+	dataPath       string
+	walPath        string
+	seriesFilePath string
+	indexPath      string
+}{}
+
+func NewRepairTSMCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "repair-tsm <pathspec>...",
+		Short: "Rewrites TSM files without their corrupt blocks",
+		Long: `
+This command will rewrite a set of TSM files, dropping every block that fails
+the checks performed by verify-tsm:
+
+* CRC-32 checksums match for each block
+* TSM index min and max timestamps match decoded data
+
+The data in dropped blocks is lost. A report of the key and time range of
+every dropped block is printed, or written to the file given by --report.
+
+If --rebuild-index is set and any blocks were dropped, the existing TSI index
+is moved aside and rebuilt from the repaired TSM files and the WAL, so that
+series with no remaining data are no longer indexed. The server must be
+stopped while this command runs. The command fails if any file could not be
+repaired.
+
+OPTIONS
+
+   <pathspec>...
+      A list of files or directories to search for TSM files.
+`,
+		RunE: repairTSMF,
+	}
+
+	defaultPath := filepath.Join(os.Getenv("HOME"), "/.influxdbv2/engine/")
+
+	cmd.Flags().BoolVar(&repairTSMFlags.backup, "backup", true, "keep each original file with a ."+tsm1.RepairTSMBackupExtension+" extension")
+	cmd.Flags().BoolVar(&repairTSMFlags.dryRun, "dry-run", false, "report corrupt blocks without modifying any files")
+	cmd.Flags().StringVar(&repairTSMFlags.reportPath, "report", "", "write the report of dropped blocks to this file instead of stdout")
+	cmd.Flags().BoolVar(&repairTSMFlags.rebuildIndex, "rebuild-index", false, "rebuild the TSI index if any blocks were dropped")
+	cmd.Flags().StringVar(&repairTSMFlags.dataPath, "tsm-path", filepath.Join(defaultPath, storage.DefaultEngineDirectoryName), "path to the TSM data directory, used to rebuild the index")
+	cmd.Flags().StringVar(&repairTSMFlags.walPath, "wal-path", filepath.Join(defaultPath, storage.DefaultWALDirectoryName), "path to the WAL data directory, used to rebuild the index")
+	cmd.Flags().StringVar(&repairTSMFlags.seriesFilePath, "sfile-path", filepath.Join(defaultPath, storage.DefaultSeriesFileDirectoryName), "path to the Series File directory, used to rebuild the index")
+	cmd.Flags().StringVar(&repairTSMFlags.indexPath, "tsi-path", filepath.Join(defaultPath, storage.DefaultIndexDirectoryName), "path to the TSI index directory, used to rebuild the index")
+
+	return cmd
+}
+
+func repairTSMF(cmd *cobra.Command, args []string) error {
+	repair := tsm1.RepairTSM{
+		Stdout: os.Stdout,
+		Backup: repairTSMFlags.backup,
+		DryRun: repairTSMFlags.dryRun,
+	}
+
+	// resolve all pathspecs
+	for _, arg := range args {
+		fi, err := os.Stat(arg)
+		if err != nil {
+			// the file fails to be repaired and is reported with the others
+			repair.Paths = append(repair.Paths, arg)
+			continue
+		}
+
+		if fi.IsDir() {
+			files, _ := filepath.Glob(filepath.Join(arg, "*."+tsm1.TSMFileExtension))
+			repair.Paths = append(repair.Paths, files...)
+		} else {
+			repair.Paths = append(repair.Paths, arg)
+		}
+	}
+
+	// The report and the index cover the files that were repaired, even if
+	// others failed.
+	summary, runErr := repair.Run()
+	if err := writeRepairReport(repairTSMFlags.reportPath, summary.WriteReport); err != nil {
+		return err
+	}
+
+	if summary.Repaired() && !repairTSMFlags.dryRun && repairTSMFlags.rebuildIndex {
+		if err := rebuildIndex(); err != nil {
+			return err
+		}
+	}
+	return runErr
+}
+
+// rebuildIndex moves the existing TSI index aside and builds a new one from
+// the TSM and WAL data.
+func rebuildIndex() error {
+	if _, err := os.Stat(repairTSMFlags.indexPath); err == nil {
+		backup := repairTSMFlags.indexPath + "." + tsm1.RepairTSMBackupExtension
+		if err := os.Rename(repairTSMFlags.indexPath, backup); err != nil {
+			return err
+		}
+		fmt.Printf("Moved existing index to %s\n", backup)
+	}
+
+	log := logger.New(os.Stdout)
+
+	sfile := seriesfile.NewSeriesFile(repairTSMFlags.seriesFilePath)
+	sfile.Logger = log
+	if err := sfile.Open(context.Background()); err != nil {
+		return err
+	}
+	defer sfile.Close()
+
+	return buildtsi.IndexShard(sfile, repairTSMFlags.indexPath, repairTSMFlags.dataPath, repairTSMFlags.walPath,
+		tsi1.DefaultMaxIndexLogFileSize, uint64(tsm1.DefaultCacheMaxMemorySize), defaultBatchSize,
+		log, false)
+}
+
+// writeRepairReport writes a repair report to the file at path, or to stdout
+// if path is empty.
+func writeRepairReport(path string, write func(io.Writer) error) error {
+	if path == "" {
+		return write(os.Stdout)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := write(f); err != nil {
+		return err
+	}
+	return f.Close()
+}
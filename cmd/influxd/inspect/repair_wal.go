@@ -0,0 +1,70 @@
+package inspect
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/influxdata/influxdb/v2/internal/fs"
+	"github.com/influxdata/influxdb/v2/storage/wal"
+	"github.com/spf13/cobra"
+)
+
+var repairWALFlags = struct {
+	dataDir    string
+	dryRun     bool
+	reportPath string
+}{}
+
+func NewRepairWALCommand() *cobra.Command {
+	repairWALCommand := &cobra.Command{
+		Use:   `repair-wal`,
+		Short: "Truncate corrupt WAL files",
+		Long: `
+This command will analyze the WAL (Write-Ahead Log) in a storage directory and
+truncate each corrupt file at the end of its last valid entry. Every entry
+after the first corrupt entry in a file is lost. The server must be stopped
+while this command runs.
+
+For each file, the following is output:
+	* The file name;
+	* "clean" (if the file is clean) OR
+	  The size the file was truncated to and the number of bytes lost OR
+	  The error that prevented the file from being repaired
+A report listing each truncated file, and the key and time range of every
+dropped entry that can still be decoded, is printed, or written to the file
+given by --report. The command fails if any file could not be repaired.`,
+		RunE: inspectRepairWAL,
+	}
+
+	dir, err := fs.InfluxDir()
+	if err != nil {
+		panic(err)
+	}
+	dir = filepath.Join(dir, "engine/wal")
+	repairWALCommand.Flags().StringVarP(&repairWALFlags.dataDir, "data-dir", "", dir, fmt.Sprintf("use provided data directory (defaults to %s).", dir))
+	repairWALCommand.Flags().BoolVar(&repairWALFlags.dryRun, "dry-run", false, "report corrupt files without modifying them")
+	repairWALCommand.Flags().StringVar(&repairWALFlags.reportPath, "report", "", "write the report of truncated files to this file instead of stdout")
+
+	return repairWALCommand
+}
+
+// inspectRepairWAL runs the repair-wal tool.
+func inspectRepairWAL(cmd *cobra.Command, args []string) error {
+	repairer := &wal.Repairer{
+		Stderr: os.Stderr,
+		Stdout: os.Stdout,
+		Dir:    repairWALFlags.dataDir,
+		DryRun: repairWALFlags.dryRun,
+	}
+
+	// The report covers the files that were repaired, even if others failed.
+	summary, runErr := repairer.Run()
+	if summary == nil {
+		return runErr
+	}
+	if err := writeRepairReport(repairWALFlags.reportPath, summary.WriteReport); err != nil {
+		return err
+	}
+	return runErr
+}
@@ -11,9 +11,12 @@ import (
 	"github.com/influxdata/influxdb/v2/http"
 	"github.com/influxdata/influxdb/v2/kit/prom"
 	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/replication"
 	"github.com/influxdata/influxdb/v2/storage"
 	"github.com/influxdata/influxdb/v2/storage/reads"
+	"github.com/influxdata/influxdb/v2/storage/wal"
 	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
 	"github.com/influxdata/influxql"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
@@ -30,8 +33,11 @@ type Engine interface {
 	storage.BucketDeleter
 	prom.PrometheusCollector
 	influxdb.BackupService
+	replication.WALSource
+	replication.WALApplier
 
 	SeriesCardinality() int64
+	MeasurementStats() (tsm1.MeasurementStats, error)
 
 	WithLogger(log *zap.Logger)
 	Open(context.Context) error
@@ -114,6 +120,11 @@ func (t *TemporaryEngine) SeriesCardinality() int64 {
 	return t.engine.SeriesCardinality()
 }
 
+// MeasurementStats returns the sizes of the measurements in the engine.
+func (t *TemporaryEngine) MeasurementStats() (tsm1.MeasurementStats, error) {
+	return t.engine.MeasurementStats()
+}
+
 // DeleteBucketRangePredicate will delete a bucket from the range and predicate.
 func (t *TemporaryEngine) DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) error {
 	return t.engine.DeleteBucketRangePredicate(ctx, orgID, bucketID, min, max, pred)
@@ -125,6 +136,16 @@ func (t *TemporaryEngine) DeleteBucket(ctx context.Context, orgID, bucketID infl
 	return t.engine.DeleteBucket(ctx, orgID, bucketID)
 }
 
+// TailWAL calls into the underlying engines TailWAL.
+func (t *TemporaryEngine) TailWAL(pos wal.Position, fn func(wal.WALEntry, wal.Position) error) (wal.Position, error) {
+	return t.engine.TailWAL(pos, fn)
+}
+
+// ApplyWALEntry calls into the underlying engines ApplyWALEntry.
+func (t *TemporaryEngine) ApplyWALEntry(ctx context.Context, entry wal.WALEntry) error {
+	return t.engine.ApplyWALEntry(ctx, entry)
+}
+
 // WithLogger sets the logger on the engine. It must be called before Open.
 func (t *TemporaryEngine) WithLogger(log *zap.Logger) {
 	t.log = log.With(zap.String("service", "temporary_engine"))
@@ -2,6 +2,7 @@ package launcher
 
 import (
 	"context"
+	"crypto/rand"
 	"crypto/tls"
 	"errors"
 	"fmt"
@@ -17,9 +18,11 @@ import (
 
 	"github.com/influxdata/flux"
 	platform "github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/audit"
 	"github.com/influxdata/influxdb/v2/authorization"
 	"github.com/influxdata/influxdb/v2/authorizer"
 	"github.com/influxdata/influxdb/v2/bolt"
+	"github.com/influxdata/influxdb/v2/chronograf/oauth2"
 	"github.com/influxdata/influxdb/v2/chronograf/server"
 	"github.com/influxdata/influxdb/v2/cmd/influxd/inspect"
 	"github.com/influxdata/influxdb/v2/endpoints"
@@ -35,13 +38,19 @@ import (
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
 	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/ldap"
 	influxlogger "github.com/influxdata/influxdb/v2/logger"
 	"github.com/influxdata/influxdb/v2/nats"
 	"github.com/influxdata/influxdb/v2/pkger"
 	infprom "github.com/influxdata/influxdb/v2/prometheus"
 	"github.com/influxdata/influxdb/v2/query"
+	querycache "github.com/influxdata/influxdb/v2/query/cache"
 	"github.com/influxdata/influxdb/v2/query/control"
+	"github.com/influxdata/influxdb/v2/query/explain"
+	"github.com/influxdata/influxdb/v2/query/flight"
 	"github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb"
+	"github.com/influxdata/influxdb/v2/ratelimit"
+	"github.com/influxdata/influxdb/v2/replication"
 	"github.com/influxdata/influxdb/v2/snowflake"
 	"github.com/influxdata/influxdb/v2/source"
 	"github.com/influxdata/influxdb/v2/storage"
@@ -54,8 +63,11 @@ import (
 	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
 	"github.com/influxdata/influxdb/v2/telemetry"
 	"github.com/influxdata/influxdb/v2/tenant"
+	"github.com/influxdata/influxdb/v2/toml"
+	"github.com/influxdata/influxdb/v2/totp"
 	_ "github.com/influxdata/influxdb/v2/tsdb/tsi1" // needed for tsi1
-	_ "github.com/influxdata/influxdb/v2/tsdb/tsm1" // needed for tsm1
+	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
+	"github.com/influxdata/influxdb/v2/usage"
 	"github.com/influxdata/influxdb/v2/vault"
 	pzap "github.com/influxdata/influxdb/v2/zap"
 	"github.com/opentracing/opentracing-go"
@@ -64,6 +76,8 @@ import (
 	jaegerconfig "github.com/uber/jaeger-client-go/config"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 const (
@@ -149,6 +163,11 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Default: ":9999",
 			Desc:    "bind address for the REST HTTP API",
 		},
+		{
+			DestP: &l.flightBindAddress,
+			Flag:  "flight-bind-address",
+			Desc:  "bind address for the Arrow Flight gRPC API, which is disabled if this is unset",
+		},
 		{
 			DestP:   &l.boltPath,
 			Flag:    "bolt-path",
@@ -202,6 +221,179 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Default: false,
 			Desc:    "disables automatically extending session ttl on request",
 		},
+		{
+			DestP:   &l.passwordPolicy.MinLength,
+			Flag:    "password-min-length",
+			Default: platform.DefaultPasswordPolicy.MinLength,
+			Desc:    "the minimum length of the passwords of users",
+		},
+		{
+			DestP: &l.passwordPolicy.RequireUpper,
+			Flag:  "password-require-uppercase",
+			Desc:  "require the passwords of users to contain an uppercase letter",
+		},
+		{
+			DestP: &l.passwordPolicy.RequireLower,
+			Flag:  "password-require-lowercase",
+			Desc:  "require the passwords of users to contain a lowercase letter",
+		},
+		{
+			DestP: &l.passwordPolicy.RequireDigit,
+			Flag:  "password-require-digit",
+			Desc:  "require the passwords of users to contain a digit",
+		},
+		{
+			DestP: &l.passwordPolicy.RequireSymbol,
+			Flag:  "password-require-symbol",
+			Desc:  "require the passwords of users to contain a symbol",
+		},
+		{
+			DestP:   &l.lockoutPolicy.MaxAttempts,
+			Flag:    "login-max-attempts",
+			Default: platform.DefaultLockoutPolicy.MaxAttempts,
+			Desc:    "the number of failed sign-ins in a row after which a user is locked out. Users are never locked out if this is 0",
+		},
+		{
+			DestP:   &l.lockoutPolicy.Duration,
+			Flag:    "login-lockout-duration",
+			Default: platform.DefaultLockoutPolicy.Duration,
+			Desc:    "how long a user is locked out after login-max-attempts failed sign-ins. It doubles with each further failure",
+		},
+		{
+			DestP:   &l.lockoutPolicy.MaxDuration,
+			Flag:    "login-max-lockout-duration",
+			Default: platform.DefaultLockoutPolicy.MaxDuration,
+			Desc:    "the longest a user is locked out after further failed sign-ins",
+		},
+		{
+			DestP:   &l.totpIssuer,
+			Flag:    "totp-issuer",
+			Default: totp.DefaultIssuer,
+			Desc:    "the issuer authenticator apps show the TOTP codes of the sign-in of users under",
+		},
+		{
+			DestP: &l.oidcIssuer,
+			Flag:  "oidc-issuer",
+			Desc:  "the issuer of the OpenID Connect provider users sign in with. The sign-in with the provider is disabled if this is unset",
+		},
+		{
+			DestP: &l.oidcClientID,
+			Flag:  "oidc-client-id",
+			Desc:  "the client id of InfluxDB at the OpenID Connect provider",
+		},
+		{
+			DestP: &l.oidcClientSecret,
+			Flag:  "oidc-client-secret",
+			Desc:  "the client secret of InfluxDB at the OpenID Connect provider",
+		},
+		{
+			DestP: &l.oidcRedirectURL,
+			Flag:  "oidc-redirect-url",
+			Desc:  "the URL the OpenID Connect provider redirects users to once they signed in, which is the /api/v2/oauth/oidc/callback route of the public URL of InfluxDB",
+		},
+		{
+			DestP:   &l.oidcPrincipalClaim,
+			Flag:    "oidc-principal-claim",
+			Default: "email",
+			Desc:    "the claim of the OpenID Connect users that is the name of their InfluxDB users",
+		},
+		{
+			DestP:   &l.oidcGroupsClaim,
+			Flag:    "oidc-groups-claim",
+			Default: "groups",
+			Desc:    "the claim of the groups of the OpenID Connect users",
+		},
+		{
+			DestP: &l.oidcGroupOrgs,
+			Flag:  "oidc-group-orgs",
+			Desc:  "group=org mappings that make the OpenID Connect users of the groups members of the orgs",
+		},
+		{
+			DestP: &l.oidcUseIDToken,
+			Flag:  "oidc-use-id-token",
+			Desc:  "read the claims of the users from the id_token of the OpenID Connect provider instead of its userinfo endpoint",
+		},
+		{
+			DestP: &l.ldapConfig.URL,
+			Flag:  "ldap-url",
+			Desc:  "the ldap:// or ldaps:// URL of the LDAP server users sign in with. The sign-in with LDAP is disabled if this is unset",
+		},
+		{
+			DestP: &l.ldapConfig.StartTLS,
+			Flag:  "ldap-start-tls",
+			Desc:  "upgrade the ldap:// connections to the LDAP server to TLS",
+		},
+		{
+			DestP: &l.ldapConfig.InsecureSkipVerify,
+			Flag:  "ldap-tls-insecure-skip-verify",
+			Desc:  "skip the verification of the certificate of the LDAP server",
+		},
+		{
+			DestP: &l.ldapConfig.CAFile,
+			Flag:  "ldap-tls-ca",
+			Desc:  "the PEM file of the certificate authorities that verify the certificate of the LDAP server",
+		},
+		{
+			DestP: &l.ldapConfig.BindDN,
+			Flag:  "ldap-bind-dn",
+			Desc:  "the DN the LDAP directory is searched as. The searches are anonymous if this is unset",
+		},
+		{
+			DestP: &l.ldapConfig.BindPassword,
+			Flag:  "ldap-bind-password",
+			Desc:  "the password of the ldap-bind-dn",
+		},
+		{
+			DestP: &l.ldapConfig.UserBaseDN,
+			Flag:  "ldap-user-base-dn",
+			Desc:  "the DN the LDAP users are searched under",
+		},
+		{
+			DestP:   &l.ldapConfig.UserFilter,
+			Flag:    "ldap-user-filter",
+			Default: ldap.DefaultUserFilter,
+			Desc:    "the filter that finds an LDAP user by name, with %s replaced by the name",
+		},
+		{
+			DestP:   &l.ldapConfig.UserNameAttribute,
+			Flag:    "ldap-user-name-attribute",
+			Default: ldap.DefaultUserNameAttribute,
+			Desc:    "the attribute of the LDAP users that is the name of their InfluxDB users",
+		},
+		{
+			DestP: &l.ldapConfig.GroupBaseDN,
+			Flag:  "ldap-group-base-dn",
+			Desc:  "the DN the LDAP groups are searched under",
+		},
+		{
+			DestP:   &l.ldapConfig.GroupFilter,
+			Flag:    "ldap-group-filter",
+			Default: ldap.DefaultGroupFilter,
+			Desc:    "the filter that finds an LDAP group by name, with %s replaced by the name",
+		},
+		{
+			DestP:   &l.ldapConfig.GroupMemberAttribute,
+			Flag:    "ldap-group-member-attribute",
+			Default: ldap.DefaultGroupMemberAttribute,
+			Desc:    "the attribute of the members of the LDAP groups, either the DNs or the names of the users",
+		},
+		{
+			DestP:   &l.ldapConfig.Timeout,
+			Flag:    "ldap-timeout",
+			Default: ldap.DefaultTimeout,
+			Desc:    "the timeout of connecting to and requests to the LDAP server",
+		},
+		{
+			DestP: &l.ldapGroupOrgs,
+			Flag:  "ldap-group-orgs",
+			Desc:  "group=org[:role] mappings that make the LDAP users of the groups members or owners of the orgs. The role is member unless it is owner",
+		},
+		{
+			DestP:   &l.ldapSyncInterval,
+			Flag:    "ldap-sync-interval",
+			Default: 5 * time.Minute,
+			Desc:    "the interval at which the LDAP groups are synced with the org members",
+		},
 		{
 			DestP: &vaultConfig.Address,
 			Flag:  "vault-addr",
@@ -306,11 +498,113 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Default: 10,
 			Desc:    "the number of queries that are allowed to be awaiting execution before new queries are rejected",
 		},
+		{
+			DestP:   &l.slowQueryThreshold,
+			Flag:    "query-slow-threshold",
+			Default: time.Duration(0),
+			Desc:    "log queries that take longer than this duration along with their statistics. If this is unset, slow queries are not logged",
+		},
+		{
+			DestP:   &l.queryCacheMaxBytes,
+			Flag:    "query-cache-max-bytes",
+			Default: 0,
+			Desc:    "the total size of the query results that are cached. If this is unset, query results are not cached",
+		},
+		{
+			DestP:   &l.queryCacheMaxEntryBytes,
+			Flag:    "query-cache-max-entry-bytes",
+			Default: querycache.DefaultMaxEntryBytes,
+			Desc:    "the size of the largest query result that is cached",
+		},
+		{
+			DestP:   &l.queryCacheTTL,
+			Flag:    "query-cache-ttl",
+			Default: querycache.DefaultTTL,
+			Desc:    "how long query results are cached. The time ranges of cached queries are aligned to it",
+		},
+		{
+			DestP: &l.ipWriteBytesPerSecond,
+			Flag:  "ip-write-bytes-per-second",
+			Desc:  "the number of line protocol bytes the clients of an IP may write every second. If this is unset, the writes of IPs are not limited",
+		},
+		{
+			DestP: &l.ipWritePointsPerSecond,
+			Flag:  "ip-write-points-per-second",
+			Desc:  "the number of points the clients of an IP may write every second. If this is unset, the writes of IPs are not limited",
+		},
+		{
+			DestP: &l.ipQueriesPerSecond,
+			Flag:  "ip-queries-per-second",
+			Desc:  "the number of queries the clients of an IP may make every second. If this is unset, the queries of IPs are not limited",
+		},
+		{
+			DestP:   &l.usageFlushInterval,
+			Flag:    "usage-flush-interval",
+			Default: usage.DefaultFlushInterval,
+			Desc:    "the interval at which the usage of organizations is written to their _usage system buckets",
+		},
+		{
+			DestP:   &l.auditLogRetention,
+			Flag:    "audit-log-retention",
+			Default: 90 * 24 * time.Hour,
+			Desc:    "how long events are kept in the audit log. Set to 0 to keep them forever",
+		},
+		{
+			DestP: &l.promRemoteMeasurement,
+			Flag:  "prometheus-remote-measurement",
+			Desc:  "the measurement of the metrics written by the Prometheus remote write API, with a field per metric name. If this is unset, the metric names are the measurements and the samples are in the value field",
+		},
 		{
 			DestP: &l.featureFlags,
 			Flag:  "feature-flags",
 			Desc:  "feature flag overrides",
 		},
+		{
+			DestP:   &l.StorageConfig.WAL.Durability,
+			Flag:    "storage-wal-durability",
+			Default: "group",
+			Desc:    "when writes to the WAL are acknowledged: sync, group, async or none. Buckets may set a different durability",
+		},
+		{
+			DestP:   &l.walFsyncDelay,
+			Flag:    "storage-wal-fsync-delay",
+			Default: tsm1.DefaultWALFsyncDelay,
+			Desc:    "the longest a group commit waits before fsyncing the WAL",
+		},
+		{
+			DestP:   &l.StorageConfig.WAL.GroupCommitMaxBatch,
+			Flag:    "storage-wal-group-commit-max-batch",
+			Default: 0,
+			Desc:    "the number of writes in a group commit that are fsynced without waiting for storage-wal-fsync-delay. If this is unset, the maximum number of waiting writes is used",
+		},
+		{
+			DestP:   &l.walFlushInterval,
+			Flag:    "storage-wal-flush-interval",
+			Default: tsm1.DefaultWALFlushInterval,
+			Desc:    "how often writes acknowledged asynchronously are fsynced",
+		},
+		{
+			DestP:   &l.replicationEnabled,
+			Flag:    "replication-enabled",
+			Default: false,
+			Desc:    "streams the WAL and metadata changes to replicas. Writes then never skip the WAL, whatever the durability of their bucket",
+		},
+		{
+			DestP: &l.replicaOf,
+			Flag:  "replica-of",
+			Desc:  "URL of the primary to replicate; the server is read-only until it is promoted",
+		},
+		{
+			DestP: &l.replicaToken,
+			Flag:  "replica-token",
+			Desc:  "operator token used to replicate the primary",
+		},
+		{
+			DestP:   &l.replicaSkipVerify,
+			Flag:    "replica-skip-verify",
+			Default: false,
+			Desc:    "skips TLS certificate verification of the primary",
+		},
 	}
 	cli.BindOptions(cmd, opts)
 	cmd.AddCommand(inspect.NewCommand())
@@ -328,6 +622,23 @@ type Launcher struct {
 	sessionLength        int // in minutes
 	sessionRenewDisabled bool
 
+	passwordPolicy platform.PasswordPolicy
+	lockoutPolicy  platform.LockoutPolicy
+	totpIssuer     string
+
+	oidcIssuer         string
+	oidcClientID       string
+	oidcClientSecret   string
+	oidcRedirectURL    string
+	oidcPrincipalClaim string
+	oidcGroupsClaim    string
+	oidcGroupOrgs      map[string]string
+	oidcUseIDToken     bool
+
+	ldapConfig       ldap.Config
+	ldapGroupOrgs    map[string]string
+	ldapSyncInterval time.Duration
+
 	logLevel          string
 	tracingType       string
 	reportingDisabled bool
@@ -347,6 +658,33 @@ type Launcher struct {
 	memoryBytesQuotaPerQuery        int
 	maxMemoryBytes                  int
 	queueSize                       int
+	slowQueryThreshold              time.Duration
+	queryCacheMaxBytes              int
+	queryCacheMaxEntryBytes         int
+	queryCacheTTL                   time.Duration
+
+	promRemoteMeasurement string
+
+	// Rate limits of the clients of every IP.
+	ipWriteBytesPerSecond  int
+	ipWritePointsPerSecond int
+	ipQueriesPerSecond     int
+
+	usageFlushInterval time.Duration
+	usageRecorder      *usage.Recorder
+
+	auditLogRetention time.Duration
+
+	// WAL options.
+	walFsyncDelay    time.Duration
+	walFlushInterval time.Duration
+
+	// Replication options.
+	replicationEnabled bool
+	replicaOf          string
+	replicaToken       string
+	replicaSkipVerify  bool
+	replica            *replication.Replica
 
 	boltClient    *bolt.Client
 	kvStore       kv.Store
@@ -361,6 +699,12 @@ type Launcher struct {
 	httpTLSCert string
 	httpTLSKey  string
 
+	flightBindAddress string
+	flightPort        int
+	flightServer      *grpc.Server
+
+	authLastUsed *authorization.LastUsedTracker
+
 	natsServer *nats.Server
 	natsPort   int
 
@@ -384,6 +728,38 @@ type stoppingScheduler interface {
 	Stop()
 }
 
+// replicaScheduler schedules no tasks while the server is a replica, and
+// delegates to the scheduler it is started with once the replica is
+// promoted.
+type replicaScheduler struct {
+	mu  sync.Mutex
+	sch stoppingScheduler
+}
+
+func (s *replicaScheduler) scheduler() stoppingScheduler {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sch
+}
+
+func (s *replicaScheduler) start(sch stoppingScheduler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sch = sch
+}
+
+func (s *replicaScheduler) Schedule(task scheduler.Schedulable) error {
+	return s.scheduler().Schedule(task)
+}
+
+func (s *replicaScheduler) Release(taskID scheduler.ID) error {
+	return s.scheduler().Release(taskID)
+}
+
+func (s *replicaScheduler) Stop() {
+	s.scheduler().Stop()
+}
+
 // NewLauncher returns a new instance of Launcher connected to standard in/out/err.
 func NewLauncher() *Launcher {
 	return &Launcher{
@@ -434,10 +810,37 @@ func (m *Launcher) Engine() Engine {
 func (m *Launcher) Shutdown(ctx context.Context) {
 	m.httpServer.Shutdown(ctx)
 
+	// The last use of tokens, and the usage, are only written by a primary.
+	if m.authLastUsed != nil && m.isPrimary() {
+		m.log.Info("Stopping", zap.String("service", "auth-last-used"))
+		if err := m.authLastUsed.Flush(ctx); err != nil {
+			m.log.Error("Failed to flush last use of authorizations", zap.Error(err))
+		}
+	}
+
+	if m.usageRecorder != nil && m.isPrimary() {
+		m.log.Info("Stopping", zap.String("service", "usage"))
+		if err := m.usageRecorder.Flush(ctx); err != nil {
+			m.log.Error("Failed to flush usage", zap.Error(err))
+		}
+	}
+
+	if m.flightServer != nil {
+		m.log.Info("Stopping", zap.String("service", "flight"))
+		m.flightServer.GracefulStop()
+	}
+
 	m.log.Info("Stopping", zap.String("service", "task"))
 
 	m.scheduler.Stop()
 
+	if m.replica != nil {
+		m.log.Info("Stopping", zap.String("service", "replica"))
+		if err := m.replica.Close(); err != nil {
+			m.log.Error("Failed to close replica", zap.Error(err))
+		}
+	}
+
 	m.log.Info("Stopping", zap.String("service", "nats"))
 	m.natsServer.Close()
 
@@ -467,6 +870,23 @@ func (m *Launcher) Shutdown(ctx context.Context) {
 	m.log.Sync()
 }
 
+// isPrimary returns true unless the server is a replica that was not
+// promoted.
+func (m *Launcher) isPrimary() bool {
+	return m.replica == nil || m.replica.Promoted()
+}
+
+// runOnPrimary calls start, which starts a service that writes to the
+// engine or the store, once the server is a primary: immediately, or once
+// the replica is promoted.
+func (m *Launcher) runOnPrimary(start func()) {
+	if m.replica == nil {
+		start()
+		return
+	}
+	m.replica.OnPromote(start)
+}
+
 // Cancel executes the context cancel on the program. Used for testing.
 func (m *Launcher) Cancel() { m.cancel() }
 
@@ -546,7 +966,9 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	}
 
 	serviceConfig := kv.ServiceConfig{
-		SessionLength: time.Duration(m.sessionLength) * time.Minute,
+		SessionLength:  time.Duration(m.sessionLength) * time.Minute,
+		PasswordPolicy: m.passwordPolicy,
+		LockoutPolicy:  m.lockoutPolicy,
 	}
 
 	flushers := flushers{}
@@ -555,14 +977,12 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		store := bolt.NewKVStore(m.log.With(zap.String("service", "kvstore-bolt")), m.boltPath)
 		store.WithDB(m.boltClient.DB())
 		m.kvStore = store
-		m.kvService = kv.NewService(m.log.With(zap.String("store", "kv")), store, serviceConfig)
 		if m.testing {
 			flushers = append(flushers, store)
 		}
 	case MemoryStore:
 		store := inmem.NewKVStore()
 		m.kvStore = store
-		m.kvService = kv.NewService(m.log.With(zap.String("store", "kv")), store, serviceConfig)
 		if m.testing {
 			flushers = append(flushers, store)
 		}
@@ -572,6 +992,31 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		return err
 	}
 
+	// Replicas apply the changes of their primary to the store directly, so that
+	// the positions they reached are not replicated in turn. The services of a
+	// replica cannot change the store until it is promoted.
+	replicaStore := m.kvStore
+	var readOnlyStore *replication.ReadOnlyStore
+	if m.replicaOf != "" {
+		readOnlyStore = replication.NewReadOnlyStore(m.kvStore)
+		m.kvStore = readOnlyStore
+	}
+	if m.replicationEnabled {
+		if m.replicaOf != "" {
+			err := errors.New("replication-enabled cannot be set on a replica")
+			m.log.Error("Failed configuring replication", zap.Error(err))
+			return err
+		}
+
+		store := replication.NewLoggingStore(m.kvStore)
+		if err := store.Initialize(ctx); err != nil {
+			m.log.Error("Failed to initialize replication log", zap.Error(err))
+			return err
+		}
+		m.kvStore = store
+	}
+	m.kvService = kv.NewService(m.log.With(zap.String("store", "kv")), m.kvStore, serviceConfig)
+
 	if err := m.kvService.Initialize(ctx); err != nil {
 		m.log.Error("Failed to initialize kv service", zap.Error(err))
 		return err
@@ -604,6 +1049,9 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		telegrafSvc               platform.TelegrafConfigStore             = m.kvService
 		labelSvc                  platform.LabelService                    = m.kvService
 		secretSvc                 platform.SecretService                   = m.kvService
+		orgLimitsSvc              platform.OrgLimitsService                = m.kvService
+		roleSvc                   platform.RoleService                     = m.kvService
+		userGroupSvc              platform.UserGroupService                = m.kvService
 		lookupSvc                 platform.LookupService                   = m.kvService
 		notificationEndpointStore platform.NotificationEndpointService     = m.kvService
 	)
@@ -615,7 +1063,7 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	}
 
 	if m.enableNewMetaStore {
-		ts := tenant.NewService(store)
+		ts := tenant.NewService(store, tenant.WithPasswordPolicy(m.passwordPolicy))
 		userSvc = tenant.NewUserLogger(m.log.With(zap.String("store", "new")), tenant.NewUserMetrics(m.reg, ts, tenant.WithSuffix("new")))
 		orgSvc = tenant.NewOrgLogger(m.log.With(zap.String("store", "new")), tenant.NewOrgMetrics(m.reg, ts, tenant.WithSuffix("new")))
 		userResourceSvc = tenant.NewURMLogger(m.log.With(zap.String("store", "new")), tenant.NewUrmMetrics(m.reg, ts, tenant.WithSuffix("new")))
@@ -623,6 +1071,26 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		passwdsSvc = tenant.NewPasswordLogger(m.log.With(zap.String("store", "new")), tenant.NewPasswordMetrics(m.reg, ts, tenant.WithSuffix("new")))
 	}
 
+	if m.ldapConfig.URL != "" {
+		passwdsSvc, err = m.runLDAP(ctx, userSvc, orgSvc, userResourceSvc, authSvc, m.kvService, passwdsSvc)
+		if err != nil {
+			m.log.Error("Failed to configure LDAP sign-in", zap.Error(err))
+			return err
+		}
+	}
+
+	totpSvc, err := totp.NewService(m.kvStore, userSvc, m.totpIssuer)
+	if err != nil {
+		m.log.Error("Failed creating TOTP service", zap.Error(err))
+		return err
+	}
+
+	authStore, err := authorization.NewStore(m.kvStore)
+	if err != nil {
+		m.log.Error("Failed creating new authorization store", zap.Error(err))
+		return err
+	}
+
 	switch m.secretStore {
 	case "bolt":
 		// If it is bolt, then we already set it above.
@@ -647,13 +1115,32 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		return err
 	}
 
+	m.StorageConfig.WAL.FsyncDelay = toml.Duration(m.walFsyncDelay)
+	m.StorageConfig.WAL.FlushInterval = toml.Duration(m.walFlushInterval)
+
+	engineOpts := []storage.Option{storage.WithRetentionEnforcer(bucketSvc)}
+	if m.replicationEnabled {
+		engineOpts = append(engineOpts, storage.WithReplication())
+	}
+
+	var queryCache *querycache.Cache
+	if m.queryCacheMaxBytes > 0 {
+		queryCache = querycache.New(m.log.With(zap.String("service", "query-cache")), querycache.Config{
+			MaxBytes:      int64(m.queryCacheMaxBytes),
+			MaxEntryBytes: int64(m.queryCacheMaxEntryBytes),
+			TTL:           m.queryCacheTTL,
+		})
+		m.reg.MustRegister(queryCache.PrometheusCollectors()...)
+		engineOpts = append(engineOpts, storage.WithWriteObserver(queryCache))
+	}
+
 	if m.testing {
 		// the testing engine will write/read into a temporary directory
-		engine := NewTemporaryEngine(m.StorageConfig, storage.WithRetentionEnforcer(bucketSvc))
+		engine := NewTemporaryEngine(m.StorageConfig, engineOpts...)
 		flushers = append(flushers, engine)
 		m.engine = engine
 	} else {
-		m.engine = storage.NewEngine(m.enginePath, m.StorageConfig, storage.WithRetentionEnforcer(bucketSvc))
+		m.engine = storage.NewEngine(m.enginePath, m.StorageConfig, engineOpts...)
 	}
 	m.engine.WithLogger(m.log)
 	if err := m.engine.Open(ctx); err != nil {
@@ -663,15 +1150,42 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	// The Engine's metrics must be registered after it opens.
 	m.reg.MustRegister(m.engine.PrometheusCollectors()...)
 
+	if m.replicaOf != "" {
+		client, err := http.NewHTTPClient(m.replicaOf, m.replicaToken, m.replicaSkipVerify)
+		if err != nil {
+			m.log.Error("Failed creating replication client", zap.Error(err))
+			return err
+		}
+		m.replica = replication.NewReplica(m.log.With(zap.String("service", "replica")), client, m.engine, replicaStore)
+		m.replica.WithPrimary(m.replicaOf)
+		readOnlyStore.Attach(m.replica)
+		if err := m.replica.Open(ctx); err != nil {
+			m.log.Error("Failed to open replica", zap.Error(err))
+			return err
+		}
+		sessionSvc = m.replica.SessionService(sessionSvc)
+	}
+
 	var (
 		deleteService platform.DeleteService = m.engine
-		pointsWriter  storage.PointsWriter   = m.engine
+		pointsWriter  storage.PointsWriter   = storage.NewDurabilityPointsWriter(bucketSvc, m.engine)
 		backupService platform.BackupService = m.engine
 	)
 
+	// The write quotas of orgs are checked against their usage, which is
+	// queried with the query controller once it is created.
+	usageQueryService := &query.QueryServiceBridge{}
+	usageSvc := usage.NewService(bucketSvc, usageQueryService)
+	writeQuotas := usage.NewQuotas(orgLimitsSvc, usageSvc, m.engine)
+
+	// The points of users, written through the API and by Flux, are checked
+	// against the write quotas of their orgs and kept out of the usage
+	// system buckets, which only the server writes.
+	userPointsWriter := usage.NewPointsWriter(bucketSvc, writeQuotas, pointsWriter)
+
 	deps, err := influxdb.NewDependencies(
-		storageflux.NewReader(readservice.NewStore(m.engine)),
-		m.engine,
+		storageflux.NewReader(authorizer.NewStore(readservice.NewStore(m.engine))),
+		userPointsWriter,
 		authorizer.NewBucketService(bucketSvc, userResourceSvc),
 		authorizer.NewOrgService(orgSvc),
 		authorizer.NewSecretService(secretSvc),
@@ -688,8 +1202,10 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		MemoryBytesQuotaPerQuery:        int64(m.memoryBytesQuotaPerQuery),
 		MaxMemoryBytes:                  int64(m.maxMemoryBytes),
 		QueueSize:                       m.queueSize,
+		SlowQueryThreshold:              m.slowQueryThreshold,
 		Logger:                          m.log.With(zap.String("service", "storage-reads")),
 		ExecutorDependencies:            []flux.Dependency{deps},
+		OrgLimits:                       orgLimitsSvc,
 	})
 	if err != nil {
 		m.log.Error("Failed to create query controller", zap.Error(err))
@@ -697,8 +1213,16 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	}
 
 	m.reg.MustRegister(m.queryController.PrometheusCollectors()...)
+	usageQueryService.AsyncQueryService = m.queryController
+
+	explainSvc := explain.NewService(m.log.With(zap.String("service", "query-explain")), deps)
+	explainSvc.MemoryBytesQuota = int64(m.memoryBytesQuotaPerQuery)
 
 	var storageQueryService = readservice.NewProxyQueryService(m.queryController)
+	var fluxQueryService = storageQueryService
+	if queryCache != nil {
+		fluxQueryService = querycache.NewProxyQueryService(queryCache, storageQueryService)
+	}
 	var taskSvc platform.TaskService
 	{
 		// create the task stack
@@ -715,13 +1239,8 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		m.reg.MustRegister(executorMetrics.PrometheusCollectors()...)
 		schLogger := m.log.With(zap.String("service", "task-scheduler"))
 
-		var sch stoppingScheduler = &scheduler.NoopScheduler{}
-		if !m.noTasks {
-			var (
-				sm  *scheduler.SchedulerMetrics
-				err error
-			)
-			sch, sm, err = scheduler.NewScheduler(
+		newScheduler := func() (stoppingScheduler, error) {
+			sch, sm, err := scheduler.NewScheduler(
 				executor,
 				taskbackend.NewSchedulableTaskService(m.kvService),
 				scheduler.WithOnErrorFn(func(ctx context.Context, taskID scheduler.ID, scheduledAt time.Time, err error) {
@@ -733,9 +1252,23 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 				}),
 			)
 			if err != nil {
-				m.log.Fatal("could not start task scheduler", zap.Error(err))
+				return nil, err
 			}
 			m.reg.MustRegister(sm.PrometheusCollectors()...)
+			return sch, nil
+		}
+
+		// Tasks run on the primary; their runs are replicated. A replica
+		// schedules its tasks once it is promoted.
+		var sch stoppingScheduler = &scheduler.NoopScheduler{}
+		var replicaSch *replicaScheduler
+		if !m.noTasks && m.replica == nil {
+			if sch, err = newScheduler(); err != nil {
+				m.log.Fatal("could not start task scheduler", zap.Error(err))
+			}
+		} else if !m.noTasks {
+			replicaSch = &replicaScheduler{sch: sch}
+			sch = replicaSch
 		}
 
 		m.scheduler = sch
@@ -748,17 +1281,32 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 
 		taskSvc = middleware.New(combinedTaskService, taskCoord)
 		m.taskControlService = combinedTaskService
-		if err := taskbackend.TaskNotifyCoordinatorOfExisting(
-			ctx,
-			taskSvc,
-			combinedTaskService,
-			taskCoord,
-			func(ctx context.Context, taskID platform.ID, runID platform.ID) error {
-				_, err := executor.ResumeCurrentRun(ctx, taskID, runID)
-				return err
-			},
-			coordLogger); err != nil {
-			m.log.Error("Failed to resume existing tasks", zap.Error(err))
+		notifyExisting := func() {
+			if err := taskbackend.TaskNotifyCoordinatorOfExisting(
+				ctx,
+				taskSvc,
+				combinedTaskService,
+				taskCoord,
+				func(ctx context.Context, taskID platform.ID, runID platform.ID) error {
+					_, err := executor.ResumeCurrentRun(ctx, taskID, runID)
+					return err
+				},
+				coordLogger); err != nil {
+				m.log.Error("Failed to resume existing tasks", zap.Error(err))
+			}
+		}
+		if m.replica == nil {
+			notifyExisting()
+		} else if replicaSch != nil {
+			m.replica.OnPromote(func() {
+				sch, err := newScheduler()
+				if err != nil {
+					m.log.Error("Failed to start task scheduler", zap.Error(err))
+					return
+				}
+				replicaSch.start(sch)
+				notifyExisting()
+			})
 		}
 	}
 
@@ -848,6 +1396,23 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		log.Info("Stopping")
 	}(m.log)
 
+	// Replicas keep the last use of tokens in memory, since they cannot
+	// write to the store until they are promoted.
+	m.authLastUsed = authorization.NewLastUsedTracker(m.log.With(zap.String("service", "auth-last-used")), authStore)
+	m.runOnPrimary(func() {
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			m.authLastUsed.Run(ctx, authorization.DefaultLastUsedFlushInterval)
+		}()
+	})
+
+	oauthConfig, err := m.oauthConfig(ctx)
+	if err != nil {
+		m.log.Error("Failed to configure OpenID Connect sign-in", zap.Error(err))
+		return err
+	}
+
 	m.httpServer = &nethttp.Server{
 		Addr: m.httpBindAddress,
 	}
@@ -864,6 +1429,38 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		flagger = f
 	}
 
+	// The changes made through the API, and logins, are recorded in the audit log.
+	auditRecorder := audit.NewRecorder(m.log.With(zap.String("service", "audit")), m.kvService)
+	if m.auditLogRetention > 0 {
+		m.runOnPrimary(func() {
+			m.wg.Add(1)
+			go func() {
+				defer m.wg.Done()
+				audit.EnforceRetention(ctx, m.log.With(zap.String("service", "audit-retention")), m.kvService, m.auditLogRetention, audit.DefaultRetentionCheckInterval)
+			}()
+		})
+	}
+	authSvc = audit.NewAuthorizationService(auditRecorder, authSvc)
+
+	// The usage of writes and queries is recorded in the usage system
+	// bucket of every org, which the write quotas are checked against. The
+	// usage recorded by the primary is replicated, while a replica keeps
+	// the usage of its queries in memory until it is promoted.
+	m.usageRecorder = usage.NewRecorder(m.log.With(zap.String("service", "usage")), bucketSvc, pointsWriter)
+	m.runOnPrimary(func() {
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			m.usageRecorder.Run(ctx, m.usageFlushInterval)
+		}()
+	})
+
+	rateLimiter := ratelimit.NewLimiter(orgLimitsSvc, platform.RateLimits{
+		WriteBytesPerSecond:  int64(m.ipWriteBytesPerSecond),
+		WritePointsPerSecond: int64(m.ipWritePointsPerSecond),
+		QueriesPerSecond:     int64(m.ipQueriesPerSecond),
+	})
+
 	m.apibackend = &http.APIBackend{
 		AssetsPath:           m.assetsPath,
 		HTTPErrorHandler:     kithttp.ErrorHandler(0),
@@ -871,42 +1468,57 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		SessionRenewDisabled: m.sessionRenewDisabled,
 		NewBucketService:     source.NewBucketService,
 		NewQueryService:      source.NewQueryService,
-		PointsWriter:         pointsWriter,
-		DeleteService:        deleteService,
+		PointsWriter:         userPointsWriter,
+		ReadStore:            authorizer.NewStore(readservice.NewStore(m.engine)),
+		PromRemoteSchema:     infprom.RemoteSchema{Measurement: m.promRemoteMeasurement},
+		OAuth:                oauthConfig,
+		DeleteService:        audit.NewDeleteService(auditRecorder, deleteService),
 		BackupService:        backupService,
 		KVBackupService:      m.kvService,
 		AuthorizationService: authSvc,
+		AuditService:         m.kvService,
 		AlgoWProxy:           &http.NoopProxyHandler{},
 		// Wrap the BucketService in a storage backed one that will ensure deleted buckets are removed from the storage engine.
-		BucketService:                   storage.NewBucketService(bucketSvc, m.engine),
-		SessionService:                  sessionSvc,
-		UserService:                     userSvc,
-		OrganizationService:             orgSvc,
-		UserResourceMappingService:      userResourceSvc,
+		BucketService:                   audit.NewBucketService(auditRecorder, storage.NewBucketService(bucketSvc, m.engine)),
+		SessionService:                  audit.NewSessionService(auditRecorder, sessionSvc),
+		AuthorizationLastUsedTracker:    m.authLastUsed,
+		UserService:                     audit.NewUserService(auditRecorder, userSvc),
+		OrganizationService:             audit.NewOrgService(auditRecorder, orgSvc),
+		OrgLimitsService:                audit.NewOrgLimitsService(auditRecorder, orgLimitsSvc),
+		UsageService:                    usageSvc,
+		RoleService:                     audit.NewRoleService(auditRecorder, roleSvc),
+		UserGroupService:                audit.NewUserGroupService(auditRecorder, userGroupSvc),
+		UserResourceMappingService:      audit.NewUserResourceMappingService(auditRecorder, userResourceSvc),
 		LabelService:                    labelSvc,
-		DashboardService:                dashboardSvc,
+		DashboardService:                audit.NewDashboardService(auditRecorder, dashboardSvc),
 		DashboardOperationLogService:    dashboardLogSvc,
 		BucketOperationLogService:       bucketLogSvc,
 		UserOperationLogService:         userLogSvc,
 		OrganizationOperationLogService: orgLogSvc,
 		SourceService:                   sourceSvc,
 		VariableService:                 variableSvc,
-		PasswordsService:                passwdsSvc,
+		PasswordsService:                audit.NewPasswordsService(auditRecorder, passwdsSvc),
+		LockoutService:                  audit.NewLockoutService(auditRecorder, m.kvService),
+		TOTPService:                     audit.NewTOTPService(auditRecorder, totpSvc),
 		InfluxQLService:                 storageQueryService,
-		FluxService:                     storageQueryService,
-		TaskService:                     taskSvc,
+		FluxService:                     fluxQueryService,
+		ActiveQueryService:              m.queryController,
+		ExplainService:                  explainSvc,
+		TaskService:                     audit.NewTaskService(auditRecorder, taskSvc),
 		TelegrafService:                 telegrafSvc,
-		NotificationRuleStore:           notificationRuleSvc,
-		NotificationEndpointService:     endpoints.NewService(notificationEndpointStore, secretSvc, userResourceSvc, orgSvc),
-		CheckService:                    checkSvc,
+		NotificationRuleStore:           audit.NewNotificationRuleStore(auditRecorder, notificationRuleSvc),
+		NotificationEndpointService:     audit.NewNotificationEndpointService(auditRecorder, endpoints.NewService(notificationEndpointStore, secretSvc, userResourceSvc, orgSvc)),
+		CheckService:                    audit.NewCheckService(auditRecorder, checkSvc),
 		ScraperTargetStoreService:       scraperTargetSvc,
 		ChronografService:               chronografSvc,
-		SecretService:                   secretSvc,
+		SecretService:                   audit.NewSecretService(auditRecorder, secretSvc),
 		LookupService:                   lookupSvc,
 		DocumentService:                 m.kvService,
 		OrgLookupService:                m.kvService,
 		WriteEventRecorder:              infprom.NewEventRecorder("write"),
 		QueryEventRecorder:              infprom.NewEventRecorder("query"),
+		RateLimiter:                     rateLimiter,
+		UsageRecorder:                   m.usageRecorder,
 		Flagger:                         flagger,
 		FlagsHandler:                    feature.NewFlagsHandler(kithttp.ErrorHandler(0), feature.ByKey),
 	}
@@ -931,6 +1543,7 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 			pkger.WithNotificationEndpointSVC(authorizer.NewNotificationEndpointService(b.NotificationEndpointService, authedURMSVC, authedOrgSVC)),
 			pkger.WithNotificationRuleSVC(authorizer.NewNotificationRuleStore(b.NotificationRuleStore, authedURMSVC, authedOrgSVC)),
 			pkger.WithOrganizationService(authorizer.NewOrgService(b.OrganizationService)),
+			pkger.WithRoleSVC(authorizer.NewRoleService(b.RoleService)),
 			pkger.WithSecretSVC(authorizer.NewSecretService(b.SecretService)),
 			pkger.WithTaskSVC(authorizer.NewTaskService(pkgerLogger, b.TaskService)),
 			pkger.WithTelegrafSVC(authorizer.NewTelegrafConfigService(b.TelegrafService, b.UserResourceMappingService)),
@@ -968,12 +1581,8 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		oldBackend.AuthorizationService = authorizer.NewAuthorizationService(authSvc)
 		oldHandler := http.NewAuthorizationHandler(authLogger, oldBackend)
 
-		authStore, err := authorization.NewStore(m.kvStore)
-		if err != nil {
-			m.log.Error("Failed creating new authorization store", zap.Error(err))
-			return err
-		}
 		authService := authorization.NewService(authStore, ts)
+		authService = audit.NewAuthorizationService(auditRecorder, authService)
 		authService = authorization.NewAuthedAuthorizationService(authService, ts)
 		authService = authorization.NewAuthMetrics(m.reg, authService)
 		authService = authorization.NewAuthLogger(authLogger, authService)
@@ -983,7 +1592,16 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	}
 
 	{
-		platformHandler := http.NewPlatformHandler(m.apibackend, http.WithResourceHandler(pkgHTTPServer), http.WithResourceHandler(onboardHTTPServer), http.WithResourceHandler(authHTTPServer))
+		var (
+			walSource replication.WALSource
+			kvLog     kv.Store
+		)
+		if m.replicationEnabled {
+			walSource, kvLog = m.engine, m.kvStore
+		}
+		replicationHTTPServer := replication.NewHTTPHandler(m.log.With(zap.String("handler", "replication")), walSource, kvLog, m.replica)
+
+		platformHandler := http.NewPlatformHandler(m.apibackend, http.WithResourceHandler(pkgHTTPServer), http.WithResourceHandler(onboardHTTPServer), http.WithResourceHandler(authHTTPServer), http.WithResourceHandler(replicationHTTPServer))
 
 		httpLogger := m.log.With(zap.String("service", "http"))
 		m.httpServer.Handler = http.NewHandlerFromRegistry(
@@ -993,6 +1611,10 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 			http.WithAPIHandler(platformHandler),
 		)
 
+		if m.replica != nil {
+			m.httpServer.Handler = m.replica.ReadOnly(m.httpServer.Handler)
+		}
+
 		if logconf.Level == zap.DebugLevel {
 			m.httpServer.Handler = http.LoggingMW(httpLogger)(m.httpServer.Handler)
 		}
@@ -1002,13 +1624,6 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		}
 	}
 
-	ln, err := net.Listen("tcp", m.httpBindAddress)
-	if err != nil {
-		m.log.Error("failed http listener", zap.Error(err))
-		m.log.Info("Stopping")
-		return err
-	}
-
 	var cer tls.Certificate
 	transport := "http"
 
@@ -1026,6 +1641,38 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		m.httpServer.TLSConfig = &tls.Config{}
 	}
 
+	if m.flightBindAddress != "" {
+		flightSvc := flight.NewService(
+			m.log.With(zap.String("service", "flight")),
+			query.QueryServiceBridge{AsyncQueryService: m.queryController},
+			authSvc,
+			orgSvc,
+		)
+		flightSvc.RoleService = roleSvc
+		flightSvc.UserGroupService = userGroupSvc
+		flightSvc.RateLimiter = rateLimiter
+		flightSvc.UsageRecorder = m.usageRecorder
+
+		// The Flight API is served with the certificate of the HTTP API,
+		// so that tokens are not sent in cleartext.
+		var opts []grpc.ServerOption
+		if cer.Certificate != nil {
+			tlsConfig := m.httpServer.TLSConfig.Clone()
+			tlsConfig.Certificates = []tls.Certificate{cer}
+			opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+		}
+		if err := m.runFlight(flightSvc, opts...); err != nil {
+			return err
+		}
+	}
+
+	ln, err := net.Listen("tcp", m.httpBindAddress)
+	if err != nil {
+		m.log.Error("failed http listener", zap.Error(err))
+		m.log.Info("Stopping")
+		return err
+	}
+
 	if addr, ok := ln.Addr().(*net.TCPAddr); ok {
 		m.httpPort = addr.Port
 	}
@@ -1050,6 +1697,99 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	return nil
 }
 
+// runLDAP signs in the users of the LDAP directory of the ldap flags, and
+// syncs the members of the orgs with its groups until ctx is done.
+func (m *Launcher) runLDAP(ctx context.Context, userSvc platform.UserService, orgSvc platform.OrganizationService, userResourceSvc platform.UserResourceMappingService, authSvc platform.AuthorizationService, sessionSvc ldap.UserSessionExpirer, passwdsSvc platform.PasswordsService) (platform.PasswordsService, error) {
+	log := m.log.With(zap.String("service", "ldap"))
+
+	dir, err := ldap.NewClient(m.ldapConfig)
+	if err != nil {
+		return nil, err
+	}
+	mappings, err := ldap.ParseGroupMappings(m.ldapGroupOrgs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(mappings) > 0 {
+		if m.ldapSyncInterval <= 0 {
+			return nil, fmt.Errorf("invalid ldap sync interval %s", m.ldapSyncInterval)
+		}
+		syncer := ldap.NewSyncer(log, dir, mappings, userSvc, orgSvc, userResourceSvc, authSvc, sessionSvc)
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			syncer.Run(ctx, m.ldapSyncInterval)
+			log.Info("Stopping")
+		}()
+	}
+
+	return ldap.NewPasswordsService(log, dir, userSvc, passwdsSvc), nil
+}
+
+// oauthConfig configures the sign-in of users with the OpenID Connect
+// provider of the oidc flags, whose endpoints are discovered from its issuer.
+func (m *Launcher) oauthConfig(ctx context.Context) (http.OAuthConfig, error) {
+	if m.oidcIssuer == "" {
+		return http.OAuthConfig{}, nil
+	}
+	if m.oidcClientID == "" || m.oidcRedirectURL == "" {
+		return http.OAuthConfig{}, errors.New("oidc-client-id and oidc-redirect-url are required with oidc-issuer")
+	}
+
+	conf, err := oauth2.DiscoverOIDC(ctx, nethttp.DefaultClient, m.oidcIssuer)
+	if err != nil {
+		return http.OAuthConfig{}, err
+	}
+
+	// The state of the sign-ins only has to be valid for this process.
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return http.OAuthConfig{}, err
+	}
+
+	return http.OAuthConfig{
+		Providers: []oauth2.Provider{&oauth2.OIDC{
+			ClientID:       m.oidcClientID,
+			ClientSecret:   m.oidcClientSecret,
+			RedirectURL:    m.oidcRedirectURL,
+			AuthURL:        conf.AuthorizationEndpoint,
+			TokenURL:       conf.TokenEndpoint,
+			UserinfoURL:    conf.UserinfoEndpoint,
+			PrincipalClaim: m.oidcPrincipalClaim,
+			GroupsClaim:    m.oidcGroupsClaim,
+		}},
+		Tokens:     oauth2.NewJWT(string(secret), conf.JWKSURI),
+		UseIDToken: m.oidcUseIDToken,
+		GroupOrgs:  m.oidcGroupOrgs,
+	}, nil
+}
+
+// runFlight serves the Arrow Flight API of the flight service with the
+// options of the gRPC server.
+func (m *Launcher) runFlight(svc *flight.Service, opts ...grpc.ServerOption) error {
+	ln, err := net.Listen("tcp", m.flightBindAddress)
+	if err != nil {
+		m.log.Error("failed flight listener", zap.Error(err))
+		return err
+	}
+	if addr, ok := ln.Addr().(*net.TCPAddr); ok {
+		m.flightPort = addr.Port
+	}
+
+	m.flightServer = flight.NewServer(svc, opts...)
+	m.wg.Add(1)
+	go func(log *zap.Logger) {
+		defer m.wg.Done()
+		log.Info("Listening", zap.String("transport", "grpc"), zap.String("addr", m.flightBindAddress), zap.Int("port", m.flightPort))
+		if err := m.flightServer.Serve(ln); err != nil {
+			log.Error("Failed flight service", zap.Error(err))
+		}
+		log.Info("Stopping")
+	}(m.log.With(zap.String("service", "flight")))
+	return nil
+}
+
 // isAddressPortAvailable checks whether the address:port is available to listen,
 // by using net.Listen to verify that the port opens successfully, then closes the listener.
 func isAddressPortAvailable(address string, port int) (bool, error) {
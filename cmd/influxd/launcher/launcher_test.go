@@ -4,7 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"io/ioutil"
+	"net"
 	nethttp "net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 
 	platform "github.com/influxdata/influxdb/v2"
@@ -139,3 +143,132 @@ func TestLauncher_SetupWithUsers(t *testing.T) {
 		t.Fatalf("unexpected 2 users: %#+v", exp)
 	}
 }
+
+func TestLauncher_OIDC(t *testing.T) {
+	var issuer string
+	idp := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"issuer":                 issuer,
+				"authorization_endpoint": issuer + "/authorize",
+				"token_endpoint":         issuer + "/token",
+				"userinfo_endpoint":      issuer + "/userinfo",
+			})
+		case "/token":
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"access_token": "myaccesstoken",
+				"token_type":   "Bearer",
+			})
+		case "/userinfo":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"email":          "marty@example.com",
+				"email_verified": true,
+				"groups":         []string{"engineering"},
+			})
+		default:
+			w.WriteHeader(nethttp.StatusNotFound)
+		}
+	}))
+	defer idp.Close()
+	issuer = idp.URL
+
+	l := launcher.RunTestLauncherOrFail(t, ctx,
+		"--oidc-issuer", issuer,
+		"--oidc-client-id", "influxdb",
+		"--oidc-redirect-url", "http://localhost:9999/api/v2/oauth/oidc/callback",
+		"--oidc-group-orgs", "engineering=ORG",
+	)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	client := &nethttp.Client{
+		CheckRedirect: func(*nethttp.Request, []*nethttp.Request) error {
+			return nethttp.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Get(l.URL() + "/api/v2/oauth/oidc/login")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	loc, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q := url.Values{"state": {loc.Query().Get("state")}, "code": {"mycode"}}
+	resp, err = client.Get(l.URL() + "/api/v2/oauth/oidc/callback?" + q.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != nethttp.StatusTemporaryRedirect || resp.Header.Get("Location") != "/" {
+		t.Fatalf("unexpected callback response: %d %s", resp.StatusCode, resp.Header.Get("Location"))
+	}
+
+	// The session of the sign-in is a member of the org of its group.
+	req, err := nethttp.NewRequest("GET", l.URL()+"/api/v2/orgs/"+l.Org.ID.String()+"/members", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range resp.Cookies() {
+		req.AddCookie(c)
+	}
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != nethttp.StatusOK {
+		t.Fatalf("unexpected status %d: %s", resp.StatusCode, body)
+	}
+	if !strings.Contains(string(body), `"name":"marty@example.com"`) {
+		t.Errorf("signed in user is not a member of the org: %s", body)
+	}
+}
+
+func TestLauncher_LDAP(t *testing.T) {
+	// The directory is unavailable, so that the local users of the setup
+	// sign in with their local passwords.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	l := launcher.RunTestLauncherOrFail(t, ctx,
+		"--ldap-url", "ldap://"+addr,
+		"--ldap-timeout", "1s",
+		"--ldap-group-orgs", "engineering=ORG:owner",
+	)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	signin := func(password string) int {
+		t.Helper()
+		req, err := nethttp.NewRequest("POST", l.URL()+"/api/v2/signin", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.SetBasicAuth("USER", password)
+		resp, err := nethttp.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if code := signin("PASSWORD"); code != nethttp.StatusNoContent {
+		t.Errorf("unexpected status signing in local user: %d", code)
+	}
+	if code := signin("WRONG"); code != nethttp.StatusUnauthorized {
+		t.Errorf("unexpected status signing in with wrong password: %d", code)
+	}
+}
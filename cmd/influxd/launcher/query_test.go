@@ -3,17 +3,26 @@ package launcher_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"html/template"
 	"io"
+	"io/ioutil"
 	"math/rand"
 	nethttp "net/http"
+	"net/url"
+	"sort"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/execute/executetest"
@@ -23,6 +32,8 @@ import (
 	"github.com/influxdata/influxdb/v2/cmd/influxd/launcher"
 	phttp "github.com/influxdata/influxdb/v2/http"
 	"github.com/influxdata/influxdb/v2/kit/prom"
+	"github.com/influxdata/influxdb/v2/kit/prom/promtest"
+	"github.com/influxdata/influxdb/v2/prometheus/prompb"
 	"github.com/influxdata/influxdb/v2/query"
 )
 
@@ -365,6 +376,40 @@ func TestLauncher_QueryMemoryManager_ExceedMemory(t *testing.T) {
 	checkMemoryUsed(t, l, 1, 100)
 }
 
+func TestLauncher_QueryOrgMemoryQuota(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx,
+		"--log-level", "error",
+		"--query-concurrency", "1",
+		"--query-initial-memory-bytes", "100",
+		"--query-memory-bytes", "50000",
+		"--query-max-memory-bytes", "200000",
+	)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	writeBytes(t, l, "t0", 5000)
+
+	limits := &phttp.OrgLimitsService{Client: l.HTTPClient(t)}
+	quota := int64(1000)
+	if _, err := limits.UpdateOrgLimits(ctx, l.Org.ID, influxdb.OrgLimitsUpdate{MemoryBytesQuota: &quota}); err != nil {
+		t.Fatal(err)
+	}
+	if err := queryPoints(context.Background(), t, l); err == nil || !strings.Contains(err.Error(), "org memory bytes quota is 1000") {
+		t.Errorf("expected an error naming the org memory quota, got %v", err)
+	}
+	checkMemoryUsed(t, l, 1, 100)
+
+	// Lifting the quota lets the query use the memory of the server.
+	quota = 0
+	if _, err := limits.UpdateOrgLimits(ctx, l.Org.ID, influxdb.OrgLimitsUpdate{MemoryBytesQuota: &quota}); err != nil {
+		t.Fatal(err)
+	}
+	if err := queryPoints(context.Background(), t, l); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	checkMemoryUsed(t, l, 1, 100)
+}
+
 // This test:
 //  - initializes a default launcher and sets memory limits;
 //  - writes some data;
@@ -744,3 +789,441 @@ from(bucket: "%s")
 		t.Fatal(err)
 	}
 }
+
+func TestLauncher_PushDownWindowAggregate(t *testing.T) {
+	points := `m0,k=k0 f=0i 0
+m0,k=k0 f=6i 1000000000
+m0,k=k0 f=2i 11000000000
+m0,k=k0 f=9i 15000000000
+m0,k=k0 f=4i 32000000000
+m0,k=k1 f=3.5 5000000000
+m0,k=k1 f=1.5 25000000000
+m0,k=k1 f=8.0 27000000000`
+
+	var queries []string
+	for _, fn := range []string{"count", "sum", "mean", "min", "max", "first", "last"} {
+		queries = append(queries,
+			fmt.Sprintf(`|> aggregateWindow(every: 10s, fn: %s)`, fn),
+			fmt.Sprintf(`|> window(every: 10s) |> %s()`, fn),
+		)
+	}
+	testPushDown(t, "pushDownWindowAggregate", points, queries)
+}
+
+func TestLauncher_PushDownGroupAggregate(t *testing.T) {
+	points := `m0,k=k0,s=s0 f=0i 0
+m0,k=k0,s=s0 f=6i 1000000000
+m0,k=k0,s=s1 f=2i 11000000000
+m0,k=k0,s=s1 f=9i 15000000000
+m0,k=k1,s=s2 f=3i 32000000000
+m0,k=k1,s=s3 f=4i 5000000000
+m0,k=k1,s=s3 f=1i 25000000000
+m1,k=k0,s=s0 f=3.5 2000000000
+m1,k=k0,s=s1 f=1.5 3000000000`
+
+	var queries []string
+	for _, fn := range []string{"count", "sum", "mean", "min", "max", "first", "last"} {
+		queries = append(queries, fmt.Sprintf(`|> %s()`, fn))
+	}
+	for _, fn := range []string{"count", "sum", "min", "max"} {
+		queries = append(queries,
+			fmt.Sprintf(`|> filter(fn: (r) => r._measurement == "m0") |> group(columns: ["k"]) |> %s()`, fn),
+			fmt.Sprintf(`|> group(columns: ["k", "_measurement"]) |> %s()`, fn),
+		)
+	}
+	testPushDown(t, "pushDownGroupAggregate", points, queries)
+}
+
+// testPushDown checks the results of queries with the flag enabled are those
+// of the queries without it.
+func testPushDown(t *testing.T, flag, points string, queries []string) {
+	t.Helper()
+
+	run := func(args ...string) []string {
+		l := launcher.RunTestLauncherOrFail(t, ctx, args...)
+		l.SetupOrFail(t)
+		defer l.ShutdownOrFail(t, ctx)
+
+		l.WritePointsOrFail(t, points)
+
+		results := make([]string, len(queries))
+		for i, q := range queries {
+			results[i] = l.FluxQueryOrFail(t, l.Org, l.Auth.Token, fmt.Sprintf(`
+from(bucket: "%s")
+	|> range(start: 1970-01-01T00:00:00Z, stop: 1970-01-01T00:00:45Z)
+	%s`, l.Bucket.Name, q))
+		}
+		return results
+	}
+
+	// Storage returns the tables of each series in turn, rather than the
+	// tables of each window in turn, so compare the rows regardless of table.
+	rows := func(result string) string {
+		var rows []string
+		for _, line := range strings.Split(result, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, ",result,") {
+				continue
+			}
+			cols := strings.Split(line, ",")
+			rows = append(rows, strings.Join(append(cols[:2:2], cols[3:]...), ","))
+		}
+		sort.Strings(rows)
+		return strings.Join(rows, "\n")
+	}
+
+	want := run()
+	got := run("--feature-flags", flag+"=true")
+	for i, q := range queries {
+		if rows(got[i]) != rows(want[i]) {
+			t.Errorf("unexpected result for %q:\n-- pushed down:\n%s\n-- not pushed down:\n%s", q, got[i], want[i])
+		}
+	}
+}
+
+func TestLauncher_QueryExplain(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	client, err := phttp.NewHTTPClient(l.URL(), l.Auth.Token, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := &phttp.QueryExplainService{Client: client}
+	e, err := svc.Explain(ctx, &query.Request{
+		OrganizationID: l.Org.ID,
+		Compiler: lang.FluxCompiler{
+			Query: fmt.Sprintf(`from(bucket: "%s")
+	|> range(start: 1970-01-01T00:00:00Z, stop: 1970-01-01T00:00:45Z)
+	|> filter(fn: (r) => r._measurement == "m0")`, l.Bucket.Name),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rules []string
+	for _, r := range e.Rules {
+		rules = append(rules, r.Name)
+	}
+	if want := []string{"PushDownRangeRule", "PushDownFilterRule"}; !cmp.Equal(want, rules) {
+		t.Errorf("unexpected rules fired -want/+got:\n%s", cmp.Diff(want, rules))
+	}
+
+	if len(e.StorageReads) != 1 {
+		t.Fatalf("expected one storage read, got %d", len(e.StorageReads))
+	}
+	read := e.StorageReads[0]
+	if read.Request != "ReadFilterRequest" || read.BucketID != l.Bucket.ID.String() {
+		t.Errorf("unexpected storage read: %+v", read)
+	}
+	if want := `'_measurement' = "m0"`; read.Predicate != want {
+		t.Errorf("unexpected predicate: got %s want %s", read.Predicate, want)
+	}
+	if !strings.Contains(e.Text, "Physical Plan:") {
+		t.Errorf("unexpected text:\n%s", e.Text)
+	}
+}
+
+func TestLauncher_QueryCache(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx,
+		"--query-cache-max-bytes", "1048576",
+		"--query-cache-ttl", "1h",
+	)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	requests := func(result string) float64 {
+		t.Helper()
+		mfs, err := l.Registry().Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := promtest.FindMetric(mfs, "query_cache_requests_total", map[string]string{"result": result})
+		if m == nil {
+			return 0
+		}
+		return m.GetCounter().GetValue()
+	}
+	queryCount := func(headers ...string) string {
+		t.Helper()
+		q := fmt.Sprintf(`from(bucket: "%s")
+	|> range(start: 1970-01-01T00:00:00Z, stop: 1970-01-01T00:01:00Z)
+	|> count()
+	|> keep(columns: ["_value"])`, l.Bucket.Name)
+		b, err := phttp.SimpleQuery(l.URL(), q, l.Org.Name, l.Auth.Token, headers...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	l.WritePointsOrFail(t, "m,k=v f=1 1000000000\nm,k=v f=2 2000000000")
+	want := queryCount()
+	if got := queryCount(); got != want {
+		t.Fatalf("unexpected cached result:\n%s\nwant:\n%s", got, want)
+	}
+	if hits, misses := requests("hit"), requests("miss"); hits != 1 || misses != 1 {
+		t.Fatalf("unexpected cache requests: %v hits, %v misses", hits, misses)
+	}
+
+	// Writes outside of the time range do not invalidate the result.
+	l.WritePointsOrFail(t, "m,k=v f=3 120000000000")
+	queryCount()
+	if hits := requests("hit"); hits != 2 {
+		t.Fatalf("expected result to be cached, got %v hits", hits)
+	}
+
+	// Writes to the time range invalidate the result.
+	l.WritePointsOrFail(t, "m,k=v f=3 3000000000")
+	if got := queryCount(); got == want || !strings.Contains(got, ",3\r\n") {
+		t.Fatalf("expected result to include the new point:\n%s", got)
+	}
+	if misses := requests("miss"); misses != 2 {
+		t.Fatalf("expected result to be invalidated, got %v misses", misses)
+	}
+
+	queryCount("Cache-Control", "no-cache")
+	if bypasses := requests("bypass"); bypasses != 1 {
+		t.Fatalf("expected query to bypass the cache, got %v bypasses", bypasses)
+	}
+}
+
+func TestLauncher_PromQL(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	l.WritePointsOrFail(t, `up,job=a,instance=x value=1 1060000000000
+up,job=a,instance=x value=2 1120000000000
+up,job=a,instance=y value=5 1120000000000
+up,job=b,instance=z value=3 1060000000000`)
+
+	get := func(path string, params url.Values) string {
+		t.Helper()
+		params.Set("org", l.Org.Name)
+		params.Set("bucket", l.Bucket.Name)
+		resp, err := nethttp.DefaultClient.Do(l.MustNewHTTPRequest("GET", path+"?"+params.Encode(), ""))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != nethttp.StatusOK {
+			t.Fatalf("unexpected status %d: %s", resp.StatusCode, b)
+		}
+		return strings.TrimSpace(string(b))
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		params url.Values
+		want   string
+	}{
+		{
+			name:   "instant query",
+			path:   "/api/v1/query",
+			params: url.Values{"query": {"up"}, "time": {"1150"}},
+			want:   `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"up","instance":"x","job":"a"},"value":[1150,"2"]},{"metric":{"__name__":"up","instance":"y","job":"a"},"value":[1150,"5"]},{"metric":{"__name__":"up","instance":"z","job":"b"},"value":[1150,"3"]}]}}`,
+		},
+		{
+			name:   "instant aggregation",
+			path:   "/api/v1/query",
+			params: url.Values{"query": {"sum by (job) (up)"}, "time": {"1150"}},
+			want:   `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"job":"a"},"value":[1150,"7"]},{"metric":{"job":"b"},"value":[1150,"3"]}]}}`,
+		},
+		{
+			name:   "range query",
+			path:   "/api/v1/query_range",
+			params: url.Values{"query": {`up{instance="x"}`}, "start": {"1000"}, "end": {"1180"}, "step": {"60"}},
+			want:   `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"__name__":"up","instance":"x","job":"a"},"values":[[1060,"1"],[1120,"2"],[1180,"2"]]}]}}`,
+		},
+		{
+			name:   "series",
+			path:   "/api/v1/series",
+			params: url.Values{"match[]": {`up{job="b"}`}},
+			want:   `{"status":"success","data":[{"__name__":"up","instance":"z","job":"b"}]}`,
+		},
+		{
+			name:   "labels",
+			path:   "/api/v1/labels",
+			params: url.Values{},
+			want:   `{"status":"success","data":["__name__","instance","job"]}`,
+		},
+		{
+			name:   "label values",
+			path:   "/api/v1/label/job/values",
+			params: url.Values{},
+			want:   `{"status":"success","data":["a","b"]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := get(tt.path, tt.params); got != tt.want {
+				t.Fatalf("unexpected response:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLauncher_PromRemote(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	do := func(path string, req, res proto.Message) {
+		t.Helper()
+		data, err := proto.Marshal(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := l.MustNewHTTPRequest("POST", fmt.Sprintf("%s?org=%s&bucket=%s", path, l.Org.ID, l.Bucket.ID), string(snappy.Encode(nil, data)))
+		r.Header.Set("Content-Encoding", "snappy")
+		resp, err := nethttp.DefaultClient.Do(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode/100 != 2 {
+			t.Fatalf("unexpected status %d: %s", resp.StatusCode, body)
+		}
+		if res == nil {
+			return
+		}
+		if body, err = snappy.Decode(nil, body); err != nil {
+			t.Fatal(err)
+		}
+		if err := proto.Unmarshal(body, res); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	series := []*prompb.TimeSeries{
+		{
+			Labels:  []*prompb.Label{{Name: "__name__", Value: "up"}, {Name: "job", Value: "a"}},
+			Samples: []prompb.Sample{{Timestamp: 1000, Value: 1}, {Timestamp: 2000, Value: 0}},
+		},
+		{
+			Labels:  []*prompb.Label{{Name: "__name__", Value: "up"}, {Name: "job", Value: "b"}},
+			Samples: []prompb.Sample{{Timestamp: 1000, Value: 1}},
+		},
+	}
+	do("/api/v2/prom/write", &prompb.WriteRequest{Timeseries: series}, nil)
+
+	// The points are readable with Flux too.
+	got := l.FluxQueryOrFail(t, l.Org, l.Auth.Token, fmt.Sprintf(`from(bucket: "%s")
+	|> range(start: 0)
+	|> filter(fn: (r) => r._measurement == "up" and r._field == "value")
+	|> count()
+	|> keep(columns: ["job", "_value"])`, l.Bucket.Name))
+	if !strings.Contains(got, ",a,2\r\n") && !strings.Contains(got, ",2,a\r\n") {
+		t.Fatalf("unexpected Flux result:\n%s", got)
+	}
+
+	var res prompb.ReadResponse
+	do("/api/v2/prom/read", &prompb.ReadRequest{
+		Queries: []*prompb.Query{
+			{
+				StartTimestampMs: 0,
+				EndTimestampMs:   2000,
+				Matchers: []*prompb.LabelMatcher{
+					{Type: prompb.LabelMatcher_EQ, Name: "__name__", Value: "up"},
+					{Type: prompb.LabelMatcher_RE, Name: "job", Value: "a|c"},
+				},
+			},
+			{
+				StartTimestampMs: 0,
+				EndTimestampMs:   1500,
+				Matchers: []*prompb.LabelMatcher{
+					{Type: prompb.LabelMatcher_EQ, Name: "__name__", Value: "up"},
+				},
+			},
+		},
+	}, &res)
+
+	want := &prompb.ReadResponse{
+		Results: []*prompb.QueryResult{
+			{Timeseries: series[:1]},
+			{
+				Timeseries: []*prompb.TimeSeries{
+					{Labels: series[0].Labels, Samples: series[0].Samples[:1]},
+					series[1],
+				},
+			},
+		},
+	}
+	if !proto.Equal(&res, want) {
+		t.Fatalf("unexpected read response:\n%s\nwant:\n%s", res.String(), want.String())
+	}
+}
+
+func TestLauncher_QueryArrow(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	l.WritePointsOrFail(t, `cpu,host=a value=1 1000000000
+cpu,host=a value=2 2000000000
+cpu,host=b value=3 1000000000`)
+
+	body, err := json.Marshal(map[string]string{
+		"type":  "flux",
+		"query": fmt.Sprintf(`from(bucket: "%s") |> range(start: 0) |> keep(columns: ["_time", "_value", "host"])`, l.Bucket.Name),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := l.MustNewHTTPRequest("POST", fmt.Sprintf("/api/v2/query?orgID=%s", l.Org.ID), string(body))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Accept", query.ArrowContentType)
+	resp, err := nethttp.DefaultClient.Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != nethttp.StatusOK {
+		t.Fatalf("unexpected status %d: %s", resp.StatusCode, data)
+	}
+	if got, want := resp.Header.Get("Content-Type"), query.ArrowContentType; got != want {
+		t.Errorf("unexpected content type: got %q want %q", got, want)
+	}
+
+	// Each table is an Arrow stream, whose metadata holds its group key.
+	var got []string
+	br := bytes.NewReader(data)
+	for br.Len() > 0 {
+		rdr, err := ipc.NewReader(br)
+		if err != nil {
+			t.Fatal(err)
+		}
+		md := rdr.Schema().Metadata()
+		if i := md.FindKey(query.ArrowGroupKeyKey); i < 0 || md.Values()[i] != `["host"]` {
+			t.Errorf("unexpected schema metadata: %v", md)
+		}
+		for rdr.Next() {
+			rec := rdr.Record()
+			host := rec.Column(rdr.Schema().FieldIndex("host")).(*array.String)
+			value := rec.Column(rdr.Schema().FieldIndex("_value")).(*array.Float64)
+			for i := 0; i < int(rec.NumRows()); i++ {
+				got = append(got, fmt.Sprintf("%s=%v", host.Value(i), value.Value(i)))
+			}
+		}
+		rdr.Release()
+	}
+	if want := []string{"a=1", "a=2", "b=3"}; !cmp.Equal(want, got) {
+		t.Errorf("unexpected values -want/+got:\n%s", cmp.Diff(want, got))
+	}
+}
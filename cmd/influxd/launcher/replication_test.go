@@ -0,0 +1,67 @@
+package launcher_test
+
+import (
+	nethttp "net/http"
+	"testing"
+	"time"
+
+	platform "github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/cmd/influxd/launcher"
+	"github.com/influxdata/influxdb/v2/http"
+)
+
+func TestLauncher_ReplicaPromote(t *testing.T) {
+	primary := launcher.RunTestLauncherOrFail(t, ctx, "--replication-enabled")
+	primary.SetupOrFail(t)
+	defer primary.ShutdownOrFail(t, ctx)
+
+	replica := launcher.RunTestLauncherOrFail(t, ctx,
+		"--replica-of", primary.URL(),
+		"--replica-token", primary.Auth.Token,
+		"--usage-flush-interval", "10ms",
+	)
+	defer replica.ShutdownOrFail(t, ctx)
+	replica.User, replica.Org, replica.Bucket, replica.Auth = primary.User, primary.Org, primary.Bucket, primary.Auth
+
+	// The replica is promoted once it replicated the token of the operator.
+	for deadline := time.Now().Add(10 * time.Second); ; {
+		resp, err := nethttp.DefaultClient.Do(replica.MustNewHTTPRequest("POST", "/api/v2/replication/promote", ""))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode/100 == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("unexpected status promoting the replica: got %d", resp.StatusCode)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The usage of the promoted replica is recorded, as on a primary.
+	const data = "m,k=v f=1"
+	replica.WritePointsOrFail(t, data)
+
+	usageSvc := &http.UsageService{Client: replica.HTTPClient(t)}
+	filter := platform.UsageFilter{
+		OrgID: &replica.Org.ID,
+		Range: &platform.Timespan{
+			Start: time.Now().Add(-time.Hour),
+			Stop:  time.Now().Add(time.Hour),
+		},
+	}
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		usage, err := usageSvc.GetUsage(ctx, filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u, ok := usage[platform.UsageWriteRequestBytes]; ok && u.Value == float64(len(data)) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the usage of the write to be recorded, got %v", usage)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,92 @@
+package launcher_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux/lang"
+	platform "github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/cmd/influxd/launcher"
+	"github.com/influxdata/influxdb/v2/http"
+	"github.com/influxdata/influxdb/v2/query"
+)
+
+func TestLauncher_Usage(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx, "--usage-flush-interval", "10ms")
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	const data = "m,k=v f=1\nm,k=v g=2"
+	l.WritePointsOrFail(t, data)
+
+	usageSvc := &http.UsageService{Client: l.HTTPClient(t)}
+	filter := platform.UsageFilter{
+		OrgID:    &l.Org.ID,
+		BucketID: &l.Bucket.ID,
+		Range: &platform.Timespan{
+			Start: time.Now().Add(-time.Hour),
+			Stop:  time.Now().Add(time.Hour),
+		},
+	}
+
+	var usage map[platform.UsageMetric]*platform.Usage
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		var err error
+		if usage, err = usageSvc.GetUsage(ctx, filter); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := usage[platform.UsageWriteRequestCount]; ok || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for m, want := range map[platform.UsageMetric]float64{
+		platform.UsageWriteRequestCount: 1,
+		platform.UsageWriteRequestBytes: float64(len(data)),
+		platform.UsageValues:            2,
+	} {
+		if u, ok := usage[m]; !ok || u.Value != want {
+			t.Errorf("expected %s of %v, got %+v", m, want, u)
+		}
+	}
+
+	// Flux cannot write to the usage system bucket, which now exists, any
+	// more than the write API can.
+	for _, to := range []string{
+		`to(bucket: "_usage")`,
+		`pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value") |> experimental.to(bucket: "_usage")`,
+	} {
+		req := &query.Request{
+			Authorization:  l.Auth,
+			OrganizationID: l.Org.ID,
+			Compiler: lang.FluxCompiler{
+				Query: fmt.Sprintf(`
+import "experimental"
+
+from(bucket: "%s")
+	|> range(start: -1h)
+	|> %s
+`, l.Bucket.Name, to),
+			},
+		}
+		if err := l.QueryAndNopConsume(ctx, req); err == nil || !strings.Contains(err.Error(), "only written by the server") {
+			t.Errorf("expected %s to be rejected, got %v", to, err)
+		}
+	}
+
+	// Writes are rejected once the monthly quota of the org is used up.
+	quota := int64(2 * len(data))
+	limitsSvc := &http.OrgLimitsService{Client: l.HTTPClient(t)}
+	if _, err := limitsSvc.UpdateOrgLimits(ctx, l.Org.ID, platform.OrgLimitsUpdate{MonthlyWriteBytesQuota: &quota}); err != nil {
+		t.Fatal(err)
+	}
+
+	l.WritePointsOrFail(t, data)
+	err := l.WritePoints(data)
+	if err == nil {
+		t.Fatal("expected the write over the monthly quota to be rejected")
+	}
+}
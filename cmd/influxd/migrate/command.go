@@ -38,6 +38,17 @@ into an OSS 2.x server in an offline manner.
 It is very important when running this tool that the 2.x server is not running, 
 and ideally the 1.x server is not running, or at least, it is not writing into 
 any of the shards that will be migrated.
+
+With --migrate-users, each 1.x user in meta.db is created as a 2.x user in the
+destination organization, along with a token granting the same read and write
+privileges on the migrated buckets. Admin users become organization owners.
+Passwords are not migrated. The generated tokens are printed once.
+
+With --migrate-continuous-queries, each continuous query in meta.db is
+transpiled to a Flux task that runs on the same schedule and writes to the
+bucket mapped from the INTO clause. Tasks are owned by an owner of the
+destination organization. Continuous queries that cannot be transpiled are
+reported and skipped.
 `,
 	Args: cobra.ExactArgs(0),
 	RunE: migrateE,
@@ -53,6 +64,9 @@ var flags struct {
 	basePath2x string // base path of 2.x installation (defaults to ~/.influxdbv2)
 	destOrg    string // destination 2.x organisation (base-16 format)
 
+	migrateUsers bool // migrate 1.x users and privileges
+	migrateCQs   bool // migrate 1.x continuous queries
+
 	dryRun  bool // enable dry-run mode (don't do any migration)
 	verbose bool // enable verbose logging
 }
@@ -144,6 +158,18 @@ func init() {
 			Default: false,
 			Desc:    "migrate all shards including hot ones. Can leave unsnapshotted data behind",
 		},
+		{
+			DestP:   &flags.migrateUsers,
+			Flag:    "migrate-users",
+			Default: false,
+			Desc:    "migrate 1.x users and their privileges to 2.x users and tokens",
+		},
+		{
+			DestP:   &flags.migrateCQs,
+			Flag:    "migrate-continuous-queries",
+			Default: false,
+			Desc:    "migrate 1.x continuous queries to 2.x tasks",
+		},
 		{
 			DestP:   &flags.verbose,
 			Flag:    "verbose",
@@ -207,6 +233,9 @@ func migrateE(cmd *cobra.Command, args []string) error {
 		VerboseLogging:  flags.verbose,
 		DestOrg:         *destOrg,
 		DryRun:          flags.dryRun,
+
+		MigrateUsers:             flags.migrateUsers,
+		MigrateContinuousQueries: flags.migrateCQs,
 	})
 	return migrator.Process1xShards(flags.db, flags.rp)
 }
@@ -0,0 +1,213 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorization"
+	"github.com/influxdata/influxdb/v2/http"
+	"github.com/influxdata/influxdb/v2/kit/cli"
+	"github.com/influxdata/influxdb/v2/tsdb/migrate"
+	"github.com/spf13/cobra"
+)
+
+var remoteCommand = &cobra.Command{
+	Use:   "remote",
+	Short: "Migrate from a running InfluxDB 1.x server over the network",
+	Long: `This command migrates data from a running InfluxDB 1.x server into a
+running InfluxDB 2.x server over their HTTP APIs. Neither server needs access to
+the other's filesystem.
+
+Data is read from the 1.x /query endpoint one measurement and one time window
+at a time and written to the 2.x /api/v2/write endpoint. Each 1.x database and
+retention policy is written to a bucket named "db/rp", which is the bucket
+InfluxQL queries for that database and retention policy resolve to.
+
+With --migrate-users, each 1.x user is created as a 2.x user in the destination
+organization, along with a token granting the same read and write privileges on
+the migrated buckets. Admin users become organization owners. Passwords cannot
+be read from 1.x and must be set again. The generated tokens are printed once.
+
+Progress is recorded in the file given by --checkpoint after every window. If
+the migration is interrupted, running it again with the same checkpoint file
+resumes where it stopped.
+`,
+	Args: cobra.ExactArgs(0),
+	RunE: migrateRemoteE,
+}
+
+var remoteFlags struct {
+	sourceURL      string
+	sourceUsername string
+	sourcePassword string
+	chunkSize      int
+	skipVerify     bool
+
+	host       string
+	token      string
+	destOrg    string
+	db         string
+	rp         string
+	checkpoint string
+	window     time.Duration
+	batchSize  int
+
+	migrateUsers bool
+	dryRun       bool
+	verbose      bool
+}
+
+func init() {
+	opts := []cli.Opt{
+		{
+			DestP:   &remoteFlags.sourceURL,
+			Flag:    "source-url",
+			Default: "http://localhost:8086",
+			Desc:    "URL of the 1.x InfluxDB server",
+		},
+		{
+			DestP:   &remoteFlags.sourceUsername,
+			Flag:    "source-username",
+			Default: "",
+			Desc:    "username for the 1.x InfluxDB server",
+		},
+		{
+			DestP:   &remoteFlags.sourcePassword,
+			Flag:    "source-password",
+			EnvVar:  "INFLUXDB_1X_PASSWORD",
+			Default: "",
+			Desc:    "password for the 1.x InfluxDB server",
+		},
+		{
+			DestP:   &remoteFlags.chunkSize,
+			Flag:    "chunk-size",
+			Default: 10000,
+			Desc:    "number of rows per chunk read from 1.x",
+		},
+		{
+			DestP:   &remoteFlags.host,
+			Flag:    "host",
+			Default: "http://localhost:9999",
+			Desc:    "URL of the 2.x InfluxDB server",
+		},
+		{
+			DestP:   &remoteFlags.token,
+			Flag:    "token",
+			EnvVar:  "INFLUX_TOKEN",
+			Default: "",
+			Desc:    "2.x token with permission to create buckets, users and authorizations in the destination organization",
+		},
+		{
+			DestP:   &remoteFlags.skipVerify,
+			Flag:    "skip-verify",
+			Default: false,
+			Desc:    "skip TLS certificate verification for both servers",
+		},
+		{
+			DestP:   &remoteFlags.destOrg,
+			Flag:    "org-id",
+			Default: "",
+			Desc:    "destination 2.x organization id (required)",
+		},
+		{
+			DestP:   &remoteFlags.db,
+			Flag:    "db",
+			Default: "",
+			Desc:    "only import the provided 1.x database",
+		},
+		{
+			DestP:   &remoteFlags.rp,
+			Flag:    "rp",
+			Default: "",
+			Desc:    "only import the provided 1.x retention policy. --db must be set",
+		},
+		{
+			DestP:   &remoteFlags.checkpoint,
+			Flag:    "checkpoint",
+			Default: "",
+			Desc:    "file to record progress in, allowing an interrupted migration to be resumed",
+		},
+		{
+			DestP:   &remoteFlags.window,
+			Flag:    "window",
+			Default: migrate.DefaultRemoteWindow,
+			Desc:    "time range of data copied per query",
+		},
+		{
+			DestP:   &remoteFlags.batchSize,
+			Flag:    "batch-size",
+			Default: migrate.DefaultRemoteBatchSize,
+			Desc:    "number of points per write to 2.x",
+		},
+		{
+			DestP:   &remoteFlags.migrateUsers,
+			Flag:    "migrate-users",
+			Default: false,
+			Desc:    "migrate 1.x users and their privileges to 2.x users and tokens",
+		},
+		{
+			DestP:   &remoteFlags.dryRun,
+			Flag:    "dry-run",
+			Default: false,
+			Desc:    "simulate migration without running it",
+		},
+		{
+			DestP:   &remoteFlags.verbose,
+			Flag:    "verbose",
+			Default: false,
+			Desc:    "enable verbose logging",
+		},
+	}
+	cli.BindOptions(remoteCommand, opts)
+
+	Command.AddCommand(remoteCommand)
+}
+
+func migrateRemoteE(cmd *cobra.Command, args []string) error {
+	if remoteFlags.destOrg == "" {
+		return errors.New("destination organization must be set")
+	} else if remoteFlags.rp != "" && remoteFlags.db == "" {
+		return errors.New("source database empty. Cannot filter by retention policy")
+	}
+
+	destOrg, err := influxdb.IDFromString(remoteFlags.destOrg)
+	if err != nil {
+		return err
+	}
+
+	client, err := http.NewHTTPClient(remoteFlags.host, remoteFlags.token, remoteFlags.skipVerify)
+	if err != nil {
+		return err
+	}
+
+	migrator := migrate.NewRemoteMigrator(migrate.RemoteConfig{
+		SourceURL:      remoteFlags.sourceURL,
+		SourceUsername: remoteFlags.sourceUsername,
+		SourcePassword: remoteFlags.sourcePassword,
+		ChunkSize:      remoteFlags.chunkSize,
+		HTTPClient:     http.NewClient("https", remoteFlags.skipVerify),
+		DestOrg:        *destOrg,
+		BucketService:  &http.BucketService{Client: client},
+		WriteService: &http.WriteService{
+			Addr:               remoteFlags.host,
+			Token:              remoteFlags.token,
+			InsecureSkipVerify: remoteFlags.skipVerify,
+		},
+		MigrateUsers: remoteFlags.migrateUsers,
+		UserServices: migrate.UserServices{
+			UserService:                &http.UserService{Client: client},
+			UserResourceMappingService: &http.UserResourceMappingService{Client: client},
+			AuthorizationService:       &authorization.AuthorizationClientService{Client: client},
+		},
+		CheckpointPath: remoteFlags.checkpoint,
+		Window:         remoteFlags.window,
+		BatchSize:      remoteFlags.batchSize,
+		DryRun:         remoteFlags.dryRun,
+		Stdout:         os.Stdout,
+		VerboseLogging: remoteFlags.verbose,
+	})
+	return migrator.Migrate(context.Background(), remoteFlags.db, remoteFlags.rp)
+}
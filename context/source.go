@@ -0,0 +1,17 @@
+package context
+
+import "context"
+
+const sourceIPCtxKey contextKey = "influx/sourceip/v1"
+
+// SetSourceIP sets the IP address of the client of a request on context.
+func SetSourceIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, sourceIPCtxKey, ip)
+}
+
+// GetSourceIP retrieves the IP address of the client of a request from
+// context. It returns an empty string if it is not set.
+func GetSourceIP(ctx context.Context) string {
+	ip, _ := ctx.Value(sourceIPCtxKey).(string)
+	return ip
+}
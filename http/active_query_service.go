@@ -0,0 +1,244 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"github.com/influxdata/influxdb/v2/query"
+	"go.uber.org/zap"
+)
+
+// ActiveQueryBackend is all services and associated parameters required to construct
+// the ActiveQueryHandler.
+type ActiveQueryBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	ActiveQueryService query.ActiveQueryService
+}
+
+// NewActiveQueryBackend returns a new instance of ActiveQueryBackend.
+func NewActiveQueryBackend(log *zap.Logger, b *APIBackend) *ActiveQueryBackend {
+	return &ActiveQueryBackend{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		ActiveQueryService: b.ActiveQueryService,
+	}
+}
+
+// ActiveQueryHandler represents an HTTP API handler for the queries in flight.
+type ActiveQueryHandler struct {
+	*httprouter.Router
+	*kithttp.API
+	log *zap.Logger
+
+	ActiveQueryService query.ActiveQueryService
+}
+
+const (
+	prefixQueries = "/api/v2/queries"
+	queriesIDPath = "/api/v2/queries/:id"
+)
+
+// NewActiveQueryHandler returns a new instance of ActiveQueryHandler.
+func NewActiveQueryHandler(log *zap.Logger, b *ActiveQueryBackend) *ActiveQueryHandler {
+	h := &ActiveQueryHandler{
+		Router: NewRouter(b.HTTPErrorHandler),
+		API:    kithttp.NewAPI(kithttp.WithLog(log)),
+		log:    log,
+
+		ActiveQueryService: b.ActiveQueryService,
+	}
+
+	h.HandlerFunc("GET", prefixQueries, h.handleGetQueries)
+	h.HandlerFunc("GET", queriesIDPath, h.handleGetQuery)
+	h.HandlerFunc("DELETE", queriesIDPath, h.handleDeleteQuery)
+	return h
+}
+
+type activeQueryResponse struct {
+	Links map[string]string `json:"links"`
+	query.ActiveQuery
+}
+
+func newActiveQueryResponse(q *query.ActiveQuery) *activeQueryResponse {
+	return &activeQueryResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("/api/v2/queries/%d", q.ID),
+			"org":  fmt.Sprintf("/api/v2/orgs/%s", q.OrganizationID),
+		},
+		ActiveQuery: *q,
+	}
+}
+
+type activeQueriesResponse struct {
+	Links   map[string]string      `json:"links"`
+	Queries []*activeQueryResponse `json:"queries"`
+}
+
+func newActiveQueriesResponse(qs []*query.ActiveQuery) *activeQueriesResponse {
+	res := &activeQueriesResponse{
+		Links: map[string]string{
+			"self": prefixQueries,
+		},
+		Queries: make([]*activeQueryResponse, 0, len(qs)),
+	}
+	for _, q := range qs {
+		res.Queries = append(res.Queries, newActiveQueryResponse(q))
+	}
+	return res
+}
+
+// handleGetQueries is the HTTP handler for the GET /api/v2/queries route.
+func (h *ActiveQueryHandler) handleGetQueries(w http.ResponseWriter, r *http.Request) {
+	var filter query.ActiveQueryFilter
+	orgID, err := decodeIDFromQuery(r.URL.Query(), "orgID")
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+	if orgID.Valid() {
+		filter.OrganizationID = &orgID
+	}
+
+	qs, err := h.ActiveQueryService.FindActiveQueries(r.Context(), filter)
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	h.API.Respond(w, http.StatusOK, newActiveQueriesResponse(qs))
+}
+
+// handleGetQuery is the HTTP handler for the GET /api/v2/queries/:id route.
+func (h *ActiveQueryHandler) handleGetQuery(w http.ResponseWriter, r *http.Request) {
+	id, err := decodeActiveQueryID(r.Context())
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	q, err := h.ActiveQueryService.FindActiveQueryByID(r.Context(), id)
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	h.API.Respond(w, http.StatusOK, newActiveQueryResponse(q))
+}
+
+// handleDeleteQuery is the HTTP handler for the DELETE /api/v2/queries/:id route.
+func (h *ActiveQueryHandler) handleDeleteQuery(w http.ResponseWriter, r *http.Request) {
+	id, err := decodeActiveQueryID(r.Context())
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	if err := h.ActiveQueryService.CancelActiveQuery(r.Context(), id); err != nil {
+		h.API.Err(w, err)
+		return
+	}
+	h.log.Debug("Query canceled", zap.Uint64("queryID", id))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeActiveQueryID decodes the ID of a query which, unlike the IDs of
+// stored resources, is a decimal number.
+func decodeActiveQueryID(ctx context.Context) (uint64, error) {
+	idStr := httprouter.ParamsFromContext(ctx).ByName("id")
+	if idStr == "" {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "url missing id",
+		}
+	}
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("invalid query id %q", idStr),
+			Err:  err,
+		}
+	}
+	return id, nil
+}
+
+// ActiveQueryService connects to Influx via HTTP to inspect and cancel the queries in flight.
+type ActiveQueryService struct {
+	Client *httpc.Client
+}
+
+var _ query.ActiveQueryService = (*ActiveQueryService)(nil)
+
+// FindActiveQueries returns the active queries that match the filter via HTTP.
+func (s *ActiveQueryService) FindActiveQueries(ctx context.Context, filter query.ActiveQueryFilter) ([]*query.ActiveQuery, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var params [][2]string
+	if filter.OrganizationID != nil {
+		params = append(params, [2]string{"orgID", filter.OrganizationID.String()})
+	}
+
+	var res activeQueriesResponse
+	err := s.Client.
+		Get(prefixQueries).
+		QueryParams(params...).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, tracing.LogError(span, err)
+	}
+
+	qs := make([]*query.ActiveQuery, 0, len(res.Queries))
+	for _, q := range res.Queries {
+		qs = append(qs, &q.ActiveQuery)
+	}
+	return qs, nil
+}
+
+// FindActiveQueryByID returns the active query with the given ID via HTTP.
+func (s *ActiveQueryService) FindActiveQueryByID(ctx context.Context, id uint64) (*query.ActiveQuery, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	span.LogKV("query-id", id)
+
+	var res activeQueryResponse
+	err := s.Client.
+		Get(activeQueryIDPath(id)).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, tracing.LogError(span, err)
+	}
+	return &res.ActiveQuery, nil
+}
+
+// CancelActiveQuery cancels the active query with the given ID via HTTP.
+func (s *ActiveQueryService) CancelActiveQuery(ctx context.Context, id uint64) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	span.LogKV("query-id", id)
+
+	err := s.Client.
+		Delete(activeQueryIDPath(id)).
+		Do(ctx)
+	return tracing.LogError(span, err)
+}
+
+func activeQueryIDPath(id uint64) string {
+	return strings.Replace(queriesIDPath, ":id", strconv.FormatUint(id, 10), 1)
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/query"
+	querymock "github.com/influxdata/influxdb/v2/query/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestActiveQueryService(t *testing.T) {
+	t.Parallel()
+
+	startedAt := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	queries := map[uint64]*query.ActiveQuery{
+		1: {
+			ID:               1,
+			OrganizationID:   10,
+			UserID:           20,
+			TokenDescription: "reporting",
+			Query:            `from(bucket: "telegraf") |> range(start: -1h)`,
+			CompilerType:     "flux",
+			State:            "executing",
+			StartedAt:        startedAt,
+			Elapsed:          2 * time.Second,
+			MemoryBytes:      1024,
+		},
+		2: {
+			ID:             2,
+			OrganizationID: 11,
+			Query:          "SELECT * FROM cpu",
+			CompilerType:   "influxql",
+			State:          "queueing",
+			StartedAt:      startedAt,
+		},
+	}
+	svc := &querymock.ActiveQueryService{
+		FindActiveQueriesFn: func(ctx context.Context, filter query.ActiveQueryFilter) ([]*query.ActiveQuery, error) {
+			var qs []*query.ActiveQuery
+			for _, id := range []uint64{1, 2} {
+				if q := queries[id]; q != nil && (filter.OrganizationID == nil || *filter.OrganizationID == q.OrganizationID) {
+					qs = append(qs, q)
+				}
+			}
+			return qs, nil
+		},
+		FindActiveQueryByIDFn: func(ctx context.Context, id uint64) (*query.ActiveQuery, error) {
+			if q := queries[id]; q != nil {
+				return q, nil
+			}
+			return nil, query.ErrActiveQueryNotFound
+		},
+		CancelActiveQueryFn: func(ctx context.Context, id uint64) error {
+			if queries[id] == nil {
+				return query.ErrActiveQueryNotFound
+			}
+			delete(queries, id)
+			return nil
+		},
+	}
+
+	server := httptest.NewServer(NewActiveQueryHandler(zaptest.NewLogger(t), &ActiveQueryBackend{
+		HTTPErrorHandler:   kithttp.ErrorHandler(0),
+		log:                zaptest.NewLogger(t),
+		ActiveQueryService: svc,
+	}))
+	defer server.Close()
+	client := ActiveQueryService{
+		Client: mustNewHTTPClient(t, server.URL, ""),
+	}
+
+	ctx := context.Background()
+	want := []*query.ActiveQuery{queries[1], queries[2]}
+	got, err := client.FindActiveQueries(ctx, query.ActiveQueryFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(want, got) {
+		t.Fatalf("unexpected queries -want/+got:\n%s", cmp.Diff(want, got))
+	}
+
+	orgID := influxdb.ID(11)
+	got, err = client.FindActiveQueries(ctx, query.ActiveQueryFilter{OrganizationID: &orgID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []*query.ActiveQuery{queries[2]}; !cmp.Equal(want, got) {
+		t.Fatalf("unexpected queries of org -want/+got:\n%s", cmp.Diff(want, got))
+	}
+
+	q, err := client.FindActiveQueryByID(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(queries[1], q) {
+		t.Fatalf("unexpected query -want/+got:\n%s", cmp.Diff(queries[1], q))
+	}
+
+	if err := client.CancelActiveQuery(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.FindActiveQueryByID(ctx, 1); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected canceled query to be gone, got %v", err)
+	}
+	if err := client.CancelActiveQuery(ctx, 1); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected query not found, got %v", err)
+	}
+}
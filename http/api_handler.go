@@ -11,8 +11,11 @@ import (
 	"github.com/influxdata/influxdb/v2/kit/feature"
 	"github.com/influxdata/influxdb/v2/kit/prom"
 	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	pr "github.com/influxdata/influxdb/v2/prometheus"
 	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/ratelimit"
 	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/storage/reads"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
@@ -45,23 +48,44 @@ type APIBackend struct {
 	// write request. A value of zero specifies there is no limit.
 	WriteParserMaxValues int
 
+	// PromRemoteSchema maps the samples of the Prometheus remote write and
+	// read protocols to points.
+	PromRemoteSchema pr.RemoteSchema
+
+	// OAuth configures the single sign-on of users with OAuth2 providers.
+	OAuth OAuthConfig
+
+	// AuthorizationLastUsedTracker tracks the last use of the tokens that
+	// authenticate requests.
+	AuthorizationLastUsedTracker AuthorizationLastUsedTracker
+
 	NewBucketService func(*influxdb.Source) (influxdb.BucketService, error)
 	NewQueryService  func(*influxdb.Source) (query.ProxyQueryService, error)
 
 	WriteEventRecorder metric.EventRecorder
 	QueryEventRecorder metric.EventRecorder
+	// RateLimiter limits the rates of writes and queries when it is set.
+	RateLimiter *ratelimit.Limiter
+	// UsageRecorder records the usage of writes and queries when it is set.
+	UsageRecorder influxdb.UsageRecorder
 
 	AlgoWProxy FeatureProxyHandler
 
 	PointsWriter                    storage.PointsWriter
+	ReadStore                       reads.Store
 	DeleteService                   influxdb.DeleteService
 	BackupService                   influxdb.BackupService
 	KVBackupService                 influxdb.KVBackupService
 	AuthorizationService            influxdb.AuthorizationService
+	AuditService                    influxdb.AuditService
 	BucketService                   influxdb.BucketService
 	SessionService                  influxdb.SessionService
 	UserService                     influxdb.UserService
 	OrganizationService             influxdb.OrganizationService
+	OrgLimitsService                influxdb.OrgLimitsService
+	UsageService                    influxdb.UsageService
+	RoleService                     influxdb.RoleService
+	UserGroupService                influxdb.UserGroupService
 	UserResourceMappingService      influxdb.UserResourceMappingService
 	LabelService                    influxdb.LabelService
 	DashboardService                influxdb.DashboardService
@@ -72,8 +96,12 @@ type APIBackend struct {
 	SourceService                   influxdb.SourceService
 	VariableService                 influxdb.VariableService
 	PasswordsService                influxdb.PasswordsService
+	LockoutService                  influxdb.LockoutService
+	TOTPService                     influxdb.TOTPService
 	InfluxQLService                 query.ProxyQueryService
 	FluxService                     query.ProxyQueryService
+	ActiveQueryService              query.ActiveQueryService
+	ExplainService                  query.ExplainService
 	TaskService                     influxdb.TaskService
 	CheckService                    influxdb.CheckService
 	TelegrafService                 influxdb.TelegrafConfigStore
@@ -101,6 +129,10 @@ func (b *APIBackend) PrometheusCollectors() []prometheus.Collector {
 		cs = append(cs, pc.PrometheusCollectors()...)
 	}
 
+	if b.RateLimiter != nil {
+		cs = append(cs, b.RateLimiter.PrometheusCollectors()...)
+	}
+
 	return cs
 }
 
@@ -127,6 +159,10 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 
 	h.Mount("/api/v2", serveLinksHandler(b.HTTPErrorHandler))
 
+	auditBackend := NewAuditBackend(b.Logger.With(zap.String("handler", "audit")), b)
+	auditBackend.AuditService = authorizer.NewAuditService(b.AuditService)
+	h.Mount(prefixAudit, NewAuditHandler(b.Logger, auditBackend))
+
 	bucketBackend := NewBucketBackend(b.Logger.With(zap.String("handler", "bucket")), b)
 	bucketBackend.BucketService = authorizer.NewBucketService(b.BucketService, noAuthUserResourceMappingService)
 	h.Mount(prefixBuckets, NewBucketHandler(b.Logger, bucketBackend))
@@ -152,6 +188,15 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	fluxBackend := NewFluxBackend(b.Logger.With(zap.String("handler", "query")), b)
 	h.Mount(prefixQuery, NewFluxHandler(b.Logger, fluxBackend))
 
+	activeQueryBackend := NewActiveQueryBackend(b.Logger.With(zap.String("handler", "queries")), b)
+	activeQueryBackend.ActiveQueryService = authorizer.NewActiveQueryService(b.ActiveQueryService)
+	h.Mount(prefixQueries, NewActiveQueryHandler(b.Logger, activeQueryBackend))
+
+	promQLBackend := NewPromQLBackend(b.Logger.With(zap.String("handler", "promql")), b)
+	promQLBackend.BucketService = authorizer.NewBucketService(b.BucketService, noAuthUserResourceMappingService)
+	promQLBackend.OrganizationService = authorizer.NewOrgService(b.OrganizationService)
+	h.Mount(prefixPromQL, NewPromQLHandler(b.Logger, promQLBackend))
+
 	h.Mount(prefixLabels, NewLabelHandler(b.Logger, b.LabelService, b.HTTPErrorHandler))
 
 	notificationEndpointBackend := NewNotificationEndpointBackend(b.Logger.With(zap.String("handler", "notificationEndpoint")), b)
@@ -167,8 +212,17 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	orgBackend := NewOrgBackend(b.Logger.With(zap.String("handler", "org")), b)
 	orgBackend.OrganizationService = authorizer.NewOrgService(b.OrganizationService)
 	orgBackend.SecretService = authorizer.NewSecretService(b.SecretService)
+	orgBackend.OrgLimitsService = authorizer.NewOrgLimitsService(b.OrgLimitsService)
+	orgBackend.UsageService = authorizer.NewUsageService(b.UsageService)
 	h.Mount(prefixOrganizations, NewOrgHandler(b.Logger, orgBackend))
 
+	roleBackend := NewRoleBackend(b.Logger.With(zap.String("handler", "role")), b)
+	roleBackend.RoleService = authorizer.NewRoleService(b.RoleService)
+	roleBackend.UserGroupService = authorizer.NewUserGroupService(b.UserGroupService)
+	roleHandler := NewRoleHandler(b.Logger, roleBackend)
+	h.Mount(prefixRoles, roleHandler)
+	h.Mount(prefixUserGroups, roleHandler)
+
 	scraperBackend := NewScraperBackend(b.Logger.With(zap.String("handler", "scraper")), b)
 	scraperBackend.ScraperStorageService = authorizer.NewScraperTargetStoreService(b.ScraperTargetStoreService,
 		b.UserResourceMappingService,
@@ -180,6 +234,12 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	h.Mount(prefixSignIn, sessionHandler)
 	h.Mount(prefixSignOut, sessionHandler)
 
+	if len(b.OAuth.Providers) > 0 {
+		oauthBackend := NewOAuthBackend(b.Logger.With(zap.String("handler", "oauth")), b)
+		oauthBackend.UserResourceMappingService = noAuthUserResourceMappingService
+		h.Mount(prefixOAuth, NewOAuthHandler(b.Logger, oauthBackend))
+	}
+
 	sourceBackend := NewSourceBackend(b.Logger.With(zap.String("handler", "source")), b)
 	sourceBackend.SourceService = authorizer.NewSourceService(b.SourceService)
 	sourceBackend.BucketService = authorizer.NewBucketService(b.BucketService, noAuthUserResourceMappingService)
@@ -201,6 +261,8 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	userBackend := NewUserBackend(b.Logger.With(zap.String("handler", "user")), b)
 	userBackend.UserService = authorizer.NewUserService(b.UserService)
 	userBackend.PasswordsService = authorizer.NewPasswordService(b.PasswordsService)
+	userBackend.LockoutService = authorizer.NewLockoutService(b.LockoutService)
+	userBackend.TOTPService = authorizer.NewTOTPService(b.TOTPService)
 	userHandler := NewUserHandler(b.Logger, userBackend)
 	h.Mount(prefixMe, userHandler)
 	h.Mount(prefixUsers, userHandler)
@@ -222,6 +284,9 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 		WithParserMaxValues(b.WriteParserMaxValues),
 	))
 
+	promRemoteBackend := NewPromRemoteBackend(b.Logger.With(zap.String("handler", "prom")), b)
+	h.Mount(prefixPromRemote, NewPromRemoteHandler(b.Logger, promRemoteBackend))
+
 	for _, o := range opts {
 		o(h)
 	}
@@ -231,6 +296,7 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 var apiLinks = map[string]interface{}{
 	// when adding new links, please take care to keep this list alphabetical
 	// as this makes it easier to verify values against the swagger document.
+	"audit":          "/api/v2/audit",
 	"authorizations": "/api/v2/authorizations",
 	"backup":         "/api/v2/backup",
 	"buckets":        "/api/v2/buckets",
@@ -245,6 +311,8 @@ var apiLinks = map[string]interface{}{
 	"notificationRules":     "/api/v2/notificationRules",
 	"notificationEndpoints": "/api/v2/notificationEndpoints",
 	"orgs":                  "/api/v2/orgs",
+	"queries":               "/api/v2/queries",
+	"roles":                 "/api/v2/roles",
 	"query": map[string]string{
 		"self":        "/api/v2/query",
 		"ast":         "/api/v2/query/ast",
@@ -262,13 +330,14 @@ var apiLinks = map[string]interface{}{
 		"debug":   "/debug/pprof",
 		"health":  "/health",
 	},
-	"tasks":     "/api/v2/tasks",
-	"checks":    "/api/v2/checks",
-	"telegrafs": "/api/v2/telegrafs",
-	"plugins":   "/api/v2/telegraf/plugins",
-	"users":     "/api/v2/users",
-	"write":     "/api/v2/write",
-	"delete":    "/api/v2/delete",
+	"tasks":      "/api/v2/tasks",
+	"checks":     "/api/v2/checks",
+	"telegrafs":  "/api/v2/telegrafs",
+	"plugins":    "/api/v2/telegraf/plugins",
+	"usergroups": "/api/v2/usergroups",
+	"users":      "/api/v2/users",
+	"write":      "/api/v2/write",
+	"delete":     "/api/v2/delete",
 }
 
 func serveLinksHandler(errorHandler influxdb.HTTPErrorHandler) http.Handler {
@@ -0,0 +1,201 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+// AuditBackend is all services and associated parameters required to construct
+// the AuditHandler.
+type AuditBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	AuditService influxdb.AuditService
+}
+
+// NewAuditBackend returns a new instance of AuditBackend.
+func NewAuditBackend(log *zap.Logger, b *APIBackend) *AuditBackend {
+	return &AuditBackend{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		AuditService: b.AuditService,
+	}
+}
+
+// AuditHandler represents an HTTP API handler for the audit log.
+type AuditHandler struct {
+	*httprouter.Router
+	*kithttp.API
+	log *zap.Logger
+
+	AuditService influxdb.AuditService
+}
+
+const (
+	prefixAudit     = "/api/v2/audit"
+	auditExportPath = "/api/v2/audit/export"
+
+	// auditExportPageSize is the number of events read at a time by an export.
+	auditExportPageSize = 1000
+)
+
+// NewAuditHandler returns a new instance of AuditHandler.
+func NewAuditHandler(log *zap.Logger, b *AuditBackend) *AuditHandler {
+	h := &AuditHandler{
+		Router: NewRouter(b.HTTPErrorHandler),
+		API:    kithttp.NewAPI(kithttp.WithLog(log)),
+		log:    log,
+
+		AuditService: b.AuditService,
+	}
+
+	h.HandlerFunc("GET", prefixAudit, h.handleGetAuditEvents)
+	h.HandlerFunc("GET", auditExportPath, h.handleExportAuditEvents)
+	return h
+}
+
+type auditEventsResponse struct {
+	Links  *influxdb.PagingLinks  `json:"links"`
+	Events []*influxdb.AuditEvent `json:"events"`
+}
+
+// decodeAuditFilter decodes the filter of the audit log routes.
+func decodeAuditFilter(r *http.Request) (influxdb.AuditFilter, error) {
+	var f influxdb.AuditFilter
+	q := r.URL.Query()
+
+	for key, id := range map[string]**influxdb.ID{
+		"orgID":      &f.OrgID,
+		"userID":     &f.UserID,
+		"resourceID": &f.ResourceID,
+		"after":      &f.After,
+	} {
+		v, err := decodeIDFromQuery(q, key)
+		if err != nil {
+			return f, err
+		}
+		if v.Valid() {
+			*id = &v
+		}
+	}
+	if rt := q.Get("resourceType"); rt != "" {
+		t := influxdb.ResourceType(rt)
+		f.ResourceType = &t
+	}
+	if action := q.Get("action"); action != "" {
+		a := influxdb.AuditAction(action)
+		if err := a.Valid(); err != nil {
+			return f, err
+		}
+		f.Action = &a
+	}
+	for key, t := range map[string]*time.Time{
+		"start": &f.Start,
+		"stop":  &f.Stop,
+	} {
+		v := q.Get(key)
+		if v == "" {
+			continue
+		}
+		tm, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return f, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  key + " must be an RFC3339 time",
+				Err:  err,
+			}
+		}
+		*t = tm
+	}
+	return f, nil
+}
+
+// handleGetAuditEvents is the HTTP handler for the GET /api/v2/audit route.
+func (h *AuditHandler) handleGetAuditEvents(w http.ResponseWriter, r *http.Request) {
+	filter, err := decodeAuditFilter(r)
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+	opts, err := influxdb.DecodeFindOptions(r)
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+	// The most recent events are returned first unless requested otherwise.
+	if r.URL.Query().Get("descending") == "" {
+		opts.Descending = influxdb.DefaultAuditFindOptions.Descending
+	}
+
+	es, _, err := h.AuditService.FindAuditEvents(r.Context(), filter, *opts)
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	// The next page continues after the last event rather than at an
+	// offset, so that it is neither read from the start of the log again
+	// nor shifted by the events recorded in between.
+	links := influxdb.NewPagingLinks(prefixAudit, *opts, filter, len(es))
+	if links.Next != "" && len(es) > 0 {
+		next, nextOpts := filter, *opts
+		next.After = &es[len(es)-1].ID
+		nextOpts.Offset = 0
+		links.Next = influxdb.NewPagingLinks(prefixAudit, nextOpts, next, len(es)).Self
+	}
+
+	h.API.Respond(w, http.StatusOK, &auditEventsResponse{
+		Links:  links,
+		Events: es,
+	})
+}
+
+// handleExportAuditEvents is the HTTP handler for the GET /api/v2/audit/export route.
+// It writes all the events that match the filter, oldest first, as newline delimited JSON.
+// The events are read a page at a time.
+func (h *AuditHandler) handleExportAuditEvents(w http.ResponseWriter, r *http.Request) {
+	filter, err := decodeAuditFilter(r)
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	opts := influxdb.FindOptions{Limit: auditExportPageSize}
+	es, _, err := h.AuditService.FindAuditEvents(r.Context(), filter, opts)
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="audit.ndjson"`)
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	for {
+		for _, e := range es {
+			if err := enc.Encode(e); err != nil {
+				h.log.Info("Failed to write audit export", zap.Error(err))
+				return
+			}
+		}
+		if len(es) < auditExportPageSize {
+			return
+		}
+
+		filter.After = &es[len(es)-1].ID
+		if es, _, err = h.AuditService.FindAuditEvents(r.Context(), filter, opts); err != nil {
+			// The response has started, so the export is cut short.
+			h.log.Info("Failed to read audit log for export", zap.Error(err))
+			return
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func newAuditTestHandler(t *testing.T, events []*influxdb.AuditEvent, filters *[]influxdb.AuditFilter, opts *[]influxdb.FindOptions) http.Handler {
+	t.Helper()
+	svc := mock.NewAuditService()
+	svc.FindAuditEventsFn = func(ctx context.Context, f influxdb.AuditFilter, opt ...influxdb.FindOptions) ([]*influxdb.AuditEvent, int, error) {
+		*filters = append(*filters, f)
+		*opts = append(*opts, opt...)
+		return events, len(events), nil
+	}
+	log := zaptest.NewLogger(t)
+	return NewAuditHandler(log, &AuditBackend{
+		HTTPErrorHandler: kithttp.ErrorHandler(0),
+		log:              log,
+		AuditService:     svc,
+	})
+}
+
+func TestAuditHandler_handleGetAuditEvents(t *testing.T) {
+	events := []*influxdb.AuditEvent{
+		{ID: 2, Time: time.Date(2020, 1, 1, 0, 1, 0, 0, time.UTC), Action: influxdb.AuditUpdate, OrgID: 1, ResourceType: influxdb.BucketsResourceType, ResourceID: 3},
+		{ID: 1, Time: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), Action: influxdb.AuditCreate, OrgID: 1, ResourceType: influxdb.BucketsResourceType, ResourceID: 3},
+	}
+	var filters []influxdb.AuditFilter
+	var opts []influxdb.FindOptions
+	h := newAuditTestHandler(t, events, &filters, &opts)
+
+	r := httptest.NewRequest("GET", "/api/v2/audit?orgID=0000000000000001&action=update&start=2020-01-01T00:00:00Z&limit=10", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", w.Code, w.Body.String())
+	}
+	if len(filters) != 1 || filters[0].OrgID == nil || *filters[0].OrgID != 1 ||
+		filters[0].Action == nil || *filters[0].Action != influxdb.AuditUpdate ||
+		!filters[0].Start.Equal(events[1].Time) || !filters[0].Stop.IsZero() {
+		t.Fatalf("unexpected filter %+v", filters)
+	}
+	if len(opts) != 1 || !opts[0].Descending || opts[0].Limit != 10 {
+		t.Fatalf("expected the most recent events first, got %+v", opts)
+	}
+
+	var resp auditEventsResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Events) != 2 || resp.Events[0].ID != 2 || resp.Links == nil {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+}
+
+func TestAuditHandler_handleGetAuditEvents_NextPage(t *testing.T) {
+	events := []*influxdb.AuditEvent{
+		{ID: 2, Action: influxdb.AuditUpdate, OrgID: 1, ResourceType: influxdb.BucketsResourceType, ResourceID: 3},
+		{ID: 1, Action: influxdb.AuditCreate, OrgID: 1, ResourceType: influxdb.BucketsResourceType, ResourceID: 3},
+	}
+	var filters []influxdb.AuditFilter
+	var opts []influxdb.FindOptions
+	h := newAuditTestHandler(t, events, &filters, &opts)
+
+	r := httptest.NewRequest("GET", "/api/v2/audit?orgID=0000000000000001&limit=2&after=0000000000000005", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", w.Code, w.Body.String())
+	}
+	if len(filters) != 1 || filters[0].After == nil || *filters[0].After != 5 {
+		t.Fatalf("expected the page to continue after event 5, got %+v", filters)
+	}
+
+	var resp auditEventsResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	want := "/api/v2/audit?after=0000000000000001&descending=true&limit=2&offset=0&orgID=0000000000000001"
+	if resp.Links == nil || resp.Links.Next != want {
+		t.Fatalf("expected the next page to continue after the last event, got %+v", resp.Links)
+	}
+}
+
+func TestAuditHandler_handleGetAuditEvents_Invalid(t *testing.T) {
+	var filters []influxdb.AuditFilter
+	var opts []influxdb.FindOptions
+	h := newAuditTestHandler(t, nil, &filters, &opts)
+
+	for _, q := range []string{"action=read", "start=yesterday", "orgID=bad"} {
+		r := httptest.NewRequest("GET", "/api/v2/audit?"+q, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected %q to be a bad request, got %d", q, w.Code)
+		}
+	}
+	if len(filters) != 0 {
+		t.Fatalf("expected no events to be found, got %+v", filters)
+	}
+}
+
+func TestAuditHandler_handleExportAuditEvents(t *testing.T) {
+	events := []*influxdb.AuditEvent{
+		{ID: 1, Action: influxdb.AuditLogin, UserID: 4, ResourceType: influxdb.UsersResourceType, ResourceID: 4},
+		{ID: 2, Action: influxdb.AuditLogout, UserID: 4, ResourceType: influxdb.UsersResourceType, ResourceID: 4},
+	}
+	var filters []influxdb.AuditFilter
+	var opts []influxdb.FindOptions
+	h := newAuditTestHandler(t, events, &filters, &opts)
+
+	r := httptest.NewRequest("GET", "/api/v2/audit/export?userID=0000000000000004", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Disposition"); got != `attachment; filename="audit.ndjson"` {
+		t.Errorf("unexpected content disposition %q", got)
+	}
+	if len(filters) != 1 || filters[0].UserID == nil || *filters[0].UserID != 4 ||
+		len(opts) != 1 || opts[0].Descending || opts[0].Limit != auditExportPageSize {
+		t.Fatalf("expected all the events of the user a page at a time, got %+v %+v", filters, opts)
+	}
+
+	var ids []influxdb.ID
+	sc := bufio.NewScanner(w.Body)
+	for sc.Scan() {
+		var e influxdb.AuditEvent
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, e.ID)
+	}
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Fatalf("expected the events oldest first, got %v", ids)
+	}
+}
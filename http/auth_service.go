@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 
@@ -73,12 +74,13 @@ func NewAuthorizationHandler(log *zap.Logger, b *AuthorizationBackend) *Authoriz
 	h.HandlerFunc("GET", "/api/v2/authorizations/:id", h.handleGetAuthorization)
 	h.HandlerFunc("PATCH", "/api/v2/authorizations/:id", h.handleUpdateAuthorization)
 	h.HandlerFunc("DELETE", "/api/v2/authorizations/:id", h.handleDeleteAuthorization)
+	h.HandlerFunc("POST", "/api/v2/authorizations/:id/rotate", h.handleRotateAuthorization)
 	return h
 }
 
 type authResponse struct {
 	ID          platform.ID          `json:"id"`
-	Token       string               `json:"token"`
+	Token       string               `json:"token,omitempty"`
 	Status      platform.Status      `json:"status"`
 	Description string               `json:"description"`
 	OrgID       platform.ID          `json:"orgID"`
@@ -87,6 +89,10 @@ type authResponse struct {
 	User        string               `json:"user"`
 	Permissions []permissionResponse `json:"permissions"`
 	Links       map[string]string    `json:"links"`
+	ExpiresAt   *time.Time           `json:"expiresAt,omitempty"`
+	LastUsedAt  *time.Time           `json:"lastUsedAt,omitempty"`
+	LastUsedIP  string               `json:"lastUsedIP,omitempty"`
+	RateLimits  *platform.RateLimits `json:"rateLimits,omitempty"`
 	CreatedAt   time.Time            `json:"createdAt"`
 	UpdatedAt   time.Time            `json:"updatedAt"`
 }
@@ -106,8 +112,12 @@ func newAuthResponse(a *platform.Authorization, org *platform.Organization, user
 			"self": fmt.Sprintf("/api/v2/authorizations/%s", a.ID),
 			"user": fmt.Sprintf("/api/v2/users/%s", a.UserID),
 		},
-		CreatedAt: a.CreatedAt,
-		UpdatedAt: a.UpdatedAt,
+		ExpiresAt:  a.ExpiresAt,
+		LastUsedAt: a.LastUsedAt,
+		LastUsedIP: a.LastUsedIP,
+		RateLimits: a.RateLimits,
+		CreatedAt:  a.CreatedAt,
+		UpdatedAt:  a.UpdatedAt,
 	}
 	return res
 }
@@ -120,20 +130,25 @@ func (a *authResponse) toPlatform() *platform.Authorization {
 		Description: a.Description,
 		OrgID:       a.OrgID,
 		UserID:      a.UserID,
+		ExpiresAt:   a.ExpiresAt,
+		LastUsedAt:  a.LastUsedAt,
+		LastUsedIP:  a.LastUsedIP,
+		RateLimits:  a.RateLimits,
 		CRUDLog: platform.CRUDLog{
 			CreatedAt: a.CreatedAt,
 			UpdatedAt: a.UpdatedAt,
 		},
 	}
 	for _, p := range a.Permissions {
-		res.Permissions = append(res.Permissions, platform.Permission{Action: p.Action, Resource: p.Resource.Resource})
+		res.Permissions = append(res.Permissions, platform.Permission{Action: p.Action, Resource: p.Resource.Resource, Predicate: p.Predicate})
 	}
 	return res
 }
 
 type permissionResponse struct {
-	Action   platform.Action  `json:"action"`
-	Resource resourceResponse `json:"resource"`
+	Action    platform.Action  `json:"action"`
+	Resource  resourceResponse `json:"resource"`
+	Predicate string           `json:"predicate,omitempty"`
 }
 
 type resourceResponse struct {
@@ -150,6 +165,7 @@ func newPermissionsResponse(ctx context.Context, ps []platform.Permission, svc p
 			Resource: resourceResponse{
 				Resource: p.Resource,
 			},
+			Predicate: p.Predicate,
 		}
 
 		if p.Resource.ID != nil {
@@ -246,6 +262,8 @@ type postAuthorizationRequest struct {
 	UserID      *platform.ID          `json:"userID,omitempty"`
 	Description string                `json:"description"`
 	Permissions []platform.Permission `json:"permissions"`
+	ExpiresAt   *time.Time            `json:"expiresAt,omitempty"`
+	RateLimits  *platform.RateLimits  `json:"rateLimits,omitempty"`
 }
 
 func (p *postAuthorizationRequest) toPlatform(userID platform.ID) *platform.Authorization {
@@ -255,6 +273,8 @@ func (p *postAuthorizationRequest) toPlatform(userID platform.ID) *platform.Auth
 		Description: p.Description,
 		Permissions: p.Permissions,
 		UserID:      userID,
+		ExpiresAt:   p.ExpiresAt,
+		RateLimits:  p.RateLimits,
 	}
 }
 
@@ -264,6 +284,8 @@ func newPostAuthorizationRequest(a *platform.Authorization) (*postAuthorizationR
 		Description: a.Description,
 		Permissions: a.Permissions,
 		Status:      a.Status,
+		ExpiresAt:   a.ExpiresAt,
+		RateLimits:  a.RateLimits,
 	}
 
 	if a.UserID.Valid() {
@@ -619,6 +641,80 @@ func decodeDeleteAuthorizationRequest(ctx context.Context, r *http.Request) (*de
 	}, nil
 }
 
+// handleRotateAuthorization is the HTTP handler for the POST /api/v2/authorizations/:id/rotate route.
+// It creates an authorization with a new token, and the token of the authorization
+// of the id expires once the grace period has passed.
+func (h *AuthorizationHandler) handleRotateAuthorization(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req, err := decodeRotateAuthorizationRequest(ctx, r)
+	if err != nil {
+		h.log.Info("Failed to decode request", zap.String("handler", "rotateAuthorization"), zap.Error(err))
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	a, err := platform.RotateAuthorization(ctx, h.AuthorizationService, req.ID, req.toPlatform())
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	o, err := h.OrganizationService.FindOrganizationByID(ctx, a.OrgID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	u, err := h.UserService.FindUserByID(ctx, a.UserID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	ps, err := newPermissionsResponse(ctx, a.Permissions, h.LookupService)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Auth rotated", zap.String("authID", req.ID.String()), zap.String("auth", fmt.Sprint(a)))
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newAuthResponse(a, o, u, ps)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+type rotateAuthorizationRequest struct {
+	ID                 platform.ID `json:"-"`
+	GracePeriodSeconds int64       `json:"gracePeriodSeconds"`
+	ExpiresAt          *time.Time  `json:"expiresAt,omitempty"`
+}
+
+func (r *rotateAuthorizationRequest) toPlatform() platform.AuthorizationRotation {
+	return platform.AuthorizationRotation{
+		GracePeriod: time.Duration(r.GracePeriodSeconds) * time.Second,
+		ExpiresAt:   r.ExpiresAt,
+	}
+}
+
+func decodeRotateAuthorizationRequest(ctx context.Context, r *http.Request) (*rotateAuthorizationRequest, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	var req rotateAuthorizationRequest
+	if err := req.ID.DecodeFromString(params.ByName("id")); err != nil {
+		return nil, err
+	}
+
+	// The body is optional, the token expires immediately without one.
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "invalid json structure",
+			Err:  err,
+		}
+	}
+	return &req, nil
+}
+
 func getAuthorizedUser(r *http.Request, svc platform.UserService) (*platform.User, error) {
 	ctx := r.Context()
 
@@ -720,6 +816,26 @@ func (s *AuthorizationService) UpdateAuthorization(ctx context.Context, id platf
 	return res.toPlatform(), nil
 }
 
+// RotateAuthorization creates an authorization with a new token, and the token of
+// the authorization of the id expires once the grace period has passed.
+func (s *AuthorizationService) RotateAuthorization(ctx context.Context, id platform.ID, rot platform.AuthorizationRotation) (*platform.Authorization, error) {
+	req := rotateAuthorizationRequest{
+		GracePeriodSeconds: int64(rot.GracePeriod / time.Second),
+		ExpiresAt:          rot.ExpiresAt,
+	}
+
+	var res authResponse
+	err := s.Client.
+		PostJSON(req, prefixAuthorization, id.String(), "rotate").
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.toPlatform(), nil
+}
+
 // DeleteAuthorization removes a authorization by id.
 func (s *AuthorizationService) DeleteAuthorization(ctx context.Context, id platform.ID) error {
 	return s.Client.
@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"time"
 
 	"github.com/influxdata/httprouter"
 	platform "github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
 	platcontext "github.com/influxdata/influxdb/v2/context"
 	"github.com/influxdata/influxdb/v2/jsonweb"
 	"github.com/opentracing/opentracing-go"
@@ -26,6 +28,16 @@ type AuthenticationHandler struct {
 	TokenParser          *jsonweb.TokenParser
 	SessionRenewDisabled bool
 
+	// LastUsedTracker tracks the last use of the authorizations of the
+	// requests. The last use is not tracked if it is nil.
+	LastUsedTracker AuthorizationLastUsedTracker
+
+	// RoleService and UserGroupService find the roles of the users of
+	// sessions and tokens. Sessions and tokens are allowed the permissions of
+	// the roles of their user when both are set.
+	RoleService      platform.RoleService
+	UserGroupService platform.UserGroupService
+
 	// This is only really used for it's lookup method the specific http
 	// handler used to register routes does not matter.
 	noAuthRouter *httprouter.Router
@@ -33,6 +45,12 @@ type AuthenticationHandler struct {
 	Handler http.Handler
 }
 
+// AuthorizationLastUsedTracker tracks when and from where authorizations
+// were last used.
+type AuthorizationLastUsedTracker interface {
+	Track(id platform.ID, at time.Time, ip string)
+}
+
 // NewAuthenticationHandler creates an authentication handler.
 func NewAuthenticationHandler(log *zap.Logger, h platform.HTTPErrorHandler) *AuthenticationHandler {
 	return &AuthenticationHandler{
@@ -76,14 +94,19 @@ func (h *AuthenticationHandler) unauthorized(ctx context.Context, w http.Respons
 	UnauthorizedError(ctx, h, w)
 }
 
-// ServeHTTP extracts the session or token from the http request and places the resulting authorizer on the request context.
+// ServeHTTP extracts the session or token from the http request and places the resulting authorizer,
+// and the IP address of the client, on the request context.
 func (h *AuthenticationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// The source of the request is set for all routes, so that sign in
+	// attempts can be attributed to their client.
+	ctx := platcontext.SetSourceIP(r.Context(), remoteIP(r))
+	r = r.WithContext(ctx)
+
 	if handler, _, _ := h.noAuthRouter.Lookup(r.Method, r.URL.Path); handler != nil {
 		h.Handler.ServeHTTP(w, r)
 		return
 	}
 
-	ctx := r.Context()
 	scheme, err := ProbeAuthScheme(r)
 	if err != nil {
 		h.unauthorized(ctx, w, err)
@@ -95,7 +118,7 @@ func (h *AuthenticationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 	case tokenAuthScheme:
 		auth, err = h.extractAuthorization(ctx, r)
 	case sessionAuthScheme:
-		auth, err = h.extractSession(ctx, r)
+		auth, err = h.extractSessionWithRoles(ctx, r)
 	default:
 		// TODO: this error will be nil if it gets here, this should be remedied with some
 		//  sentinel error I'm thinking
@@ -157,7 +180,31 @@ func (h *AuthenticationHandler) extractAuthorization(ctx context.Context, r *htt
 		return nil, err
 	}
 
-	return h.AuthorizationService.FindAuthorizationByToken(ctx, t)
+	a, err := h.AuthorizationService.FindAuthorizationByToken(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if a.IsExpired(now) {
+		return nil, platform.ErrAuthorizationExpired
+	}
+	if h.LastUsedTracker != nil {
+		h.LastUsedTracker.Track(a.ID, now, remoteIP(r))
+	}
+	if h.RoleService == nil || h.UserGroupService == nil {
+		return a, nil
+	}
+	return authorizer.AuthorizationWithRoles(ctx, h.RoleService, h.UserGroupService, a)
+}
+
+// remoteIP returns the IP address of the client of the request.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
 }
 
 func (h *AuthenticationHandler) extractSession(ctx context.Context, r *http.Request) (*platform.Session, error) {
@@ -181,3 +228,17 @@ func (h *AuthenticationHandler) extractSession(ctx context.Context, r *http.Requ
 
 	return s, err
 }
+
+// extractSessionWithRoles extracts the session of the request, allowed the
+// permissions of the roles of its user. The role permissions are added
+// after the session is renewed so that they are never persisted.
+func (h *AuthenticationHandler) extractSessionWithRoles(ctx context.Context, r *http.Request) (*platform.Session, error) {
+	s, err := h.extractSession(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	if h.RoleService == nil || h.UserGroupService == nil {
+		return s, nil
+	}
+	return authorizer.SessionWithRoles(ctx, h.RoleService, h.UserGroupService, s)
+}
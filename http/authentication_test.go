@@ -11,6 +11,7 @@ import (
 
 	influxdb "github.com/influxdata/influxdb/v2"
 	platform "github.com/influxdata/influxdb/v2"
+	platcontext "github.com/influxdata/influxdb/v2/context"
 	platformhttp "github.com/influxdata/influxdb/v2/http"
 	"github.com/influxdata/influxdb/v2/jsonweb"
 	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
@@ -249,6 +250,58 @@ func TestAuthenticationHandler(t *testing.T) {
 	}
 }
 
+func TestAuthenticationHandler_TokenRoles(t *testing.T) {
+	writeTasks := influxdb.Permission{
+		Action:   influxdb.WriteAction,
+		Resource: influxdb.Resource{Type: influxdb.TasksResourceType, OrgID: &one},
+	}
+
+	h := platformhttp.NewAuthenticationHandler(zaptest.NewLogger(t), kithttp.ErrorHandler(0))
+	h.AuthorizationService = &mock.AuthorizationService{
+		FindAuthorizationByTokenFn: func(ctx context.Context, token string) (*platform.Authorization, error) {
+			return &platform.Authorization{ID: 2, OrgID: one, UserID: 5, Status: platform.Active}, nil
+		},
+	}
+	h.SessionService = mock.NewSessionService()
+	h.UserService = &mock.UserService{
+		FindUserByIDFn: func(ctx context.Context, id platform.ID) (*platform.User, error) {
+			return &platform.User{ID: id}, nil
+		},
+	}
+	roles := mock.NewRoleService()
+	roles.FindRolesFn = func(ctx context.Context, filter influxdb.RoleFilter, opt ...influxdb.FindOptions) ([]*influxdb.Role, int, error) {
+		return []*influxdb.Role{{ID: 10, OrgID: one, Permissions: []influxdb.Permission{writeTasks}}}, 1, nil
+	}
+	groups := mock.NewUserGroupService()
+	groups.FindUserGroupsFn = func(ctx context.Context, filter influxdb.UserGroupFilter, opt ...influxdb.FindOptions) ([]*influxdb.UserGroup, int, error) {
+		return nil, 0, nil
+	}
+	h.RoleService = roles
+	h.UserGroupService = groups
+
+	var allowed bool
+	h.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a, err := platcontext.GetAuthorizer(r.Context())
+		if err != nil {
+			t.Fatal(err)
+		}
+		allowed = a.Allowed(writeTasks)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "http://any.url", nil)
+	platformhttp.SetToken("abc123", r)
+	h.ServeHTTP(w, r)
+
+	if got, want := w.Code, http.StatusOK; got != want {
+		t.Fatalf("expected status code to be %d got %d", want, got)
+	}
+	if !allowed {
+		t.Fatal("expected the token to be allowed the permissions of the roles of its user")
+	}
+}
+
 func TestProbeAuthScheme(t *testing.T) {
 	type args struct {
 		token   string
@@ -609,9 +609,10 @@ func TestService_handlePatchBucket(t *testing.T) {
 		BucketService platform.BucketService
 	}
 	type args struct {
-		id        string
-		name      string
-		retention time.Duration
+		id         string
+		name       string
+		retention  time.Duration
+		durability platform.BucketDurability
 	}
 	type wants struct {
 		statusCode  int
@@ -625,6 +626,67 @@ func TestService_handlePatchBucket(t *testing.T) {
 		args   args
 		wants  wants
 	}{
+		{
+			name: "update a bucket durability",
+			fields: fields{
+				&mock.BucketService{
+					UpdateBucketFn: func(ctx context.Context, id platform.ID, upd platform.BucketUpdate) (*platform.Bucket, error) {
+						d := &platform.Bucket{
+							ID:    platformtesting.MustIDBase16("020f755c3c082000"),
+							Name:  "hello",
+							OrgID: platformtesting.MustIDBase16("020f755c3c082000"),
+						}
+						if upd.Durability != nil {
+							d.Durability = *upd.Durability
+						}
+						return d, nil
+					},
+				},
+			},
+			args: args{
+				id:         "020f755c3c082000",
+				durability: platform.BucketDurabilityNone,
+			},
+			wants: wants{
+				statusCode:  http.StatusOK,
+				contentType: "application/json; charset=utf-8",
+				body: `
+{
+  "links": {
+    "org": "/api/v2/orgs/020f755c3c082000",
+    "self": "/api/v2/buckets/020f755c3c082000",
+    "logs": "/api/v2/buckets/020f755c3c082000/logs",
+    "labels": "/api/v2/buckets/020f755c3c082000/labels",
+    "members": "/api/v2/buckets/020f755c3c082000/members",
+    "owners": "/api/v2/buckets/020f755c3c082000/owners",
+    "write": "/api/v2/write?org=020f755c3c082000&bucket=020f755c3c082000"
+  },
+  "createdAt": "0001-01-01T00:00:00Z",
+  "updatedAt": "0001-01-01T00:00:00Z",
+  "id": "020f755c3c082000",
+  "orgID": "020f755c3c082000",
+  "type": "user",
+  "name": "hello",
+  "retentionRules": [],
+  "durability": "none",
+  "labels": []
+}
+`,
+			},
+		},
+		{
+			name: "update a bucket with an invalid durability is an error",
+			fields: fields{
+				&mock.BucketService{},
+			},
+			args: args{
+				id:         "020f755c3c082000",
+				durability: "fast",
+			},
+			wants: wants{
+				statusCode: http.StatusBadRequest,
+			},
+		},
 		{
 			name: "update a bucket name and retention",
 			fields: fields{
@@ -943,6 +1005,10 @@ func TestService_handlePatchBucket(t *testing.T) {
 				upd.RetentionPeriod = &tt.args.retention
 			}
 
+			if tt.args.durability != "" {
+				upd.Durability = &tt.args.durability
+			}
+
 			b, err := json.Marshal(newBucketUpdate(&upd))
 			if err != nil {
 				t.Fatalf("failed to unmarshal bucket update: %v", err)
@@ -13,6 +13,7 @@ import (
 	pcontext "github.com/influxdata/influxdb/v2/context"
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	"github.com/influxdata/influxdb/v2/predicate"
+	"github.com/influxdata/influxdb/v2/usage"
 	"go.uber.org/zap"
 )
 
@@ -94,6 +95,15 @@ func (h *DeleteHandler) handleDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if usage.IsSystemBucket(dr.Bucket) {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EForbidden,
+			Op:   "http/handleDelete",
+			Msg:  usage.ErrSystemBucketWrite,
+		}, w)
+		return
+	}
+
 	p, err := influxdb.NewPermissionAtID(dr.Bucket.ID, influxdb.WriteAction, influxdb.BucketsResourceType, dr.Org.ID)
 	if err != nil {
 		h.HandleHTTPError(ctx, &influxdb.Error{
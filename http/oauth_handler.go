@@ -0,0 +1,354 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/chronograf"
+	"github.com/influxdata/influxdb/v2/chronograf/oauth2"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixOAuth       = "/api/v2/oauth"
+	oauthLoginPath    = "/api/v2/oauth/:provider/login"
+	oauthCallbackPath = "/api/v2/oauth/:provider/callback"
+
+	// oauthIDPrefix prefixes the OAuthID of the users created when they
+	// first signed in with a provider. Only these users may sign in with
+	// the providers.
+	oauthIDPrefix = "oauth:"
+)
+
+// OAuthConfig configures the single sign-on of users with OAuth2 and OpenID
+// Connect providers.
+type OAuthConfig struct {
+	// Providers are the providers users sign in with. Single sign-on is
+	// disabled if there are none.
+	Providers []oauth2.Provider
+	// Tokens creates and validates the state of the sign-ins, and the
+	// id_tokens of the providers if UseIDToken is set.
+	Tokens oauth2.Tokenizer
+	// UseIDToken reads the principal and groups of users from the id_tokens
+	// of the providers, rather than from their APIs.
+	UseIDToken bool
+	// GroupOrgs maps the groups of the users to the names of the orgs they
+	// are made members of.
+	GroupOrgs map[string]string
+}
+
+// OAuthBackend is all services and associated parameters required to construct
+// the OAuthHandler.
+type OAuthBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	Config OAuthConfig
+
+	SessionService             influxdb.SessionService
+	UserService                influxdb.UserService
+	OrganizationService        influxdb.OrganizationService
+	UserResourceMappingService influxdb.UserResourceMappingService
+}
+
+// NewOAuthBackend returns a new instance of OAuthBackend.
+func NewOAuthBackend(log *zap.Logger, b *APIBackend) *OAuthBackend {
+	return &OAuthBackend{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		Config: b.OAuth,
+
+		SessionService:             b.SessionService,
+		UserService:                b.UserService,
+		OrganizationService:        b.OrganizationService,
+		UserResourceMappingService: b.UserResourceMappingService,
+	}
+}
+
+// OAuthHandler signs in users with the OAuth2 providers. Users are created
+// when they first sign in, are made members of the orgs of their groups and
+// removed from the orgs of the groups they left, and are given a session as
+// if they signed in with a password.
+type OAuthHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	providers []oauth2.Provider
+	muxes     map[string]*oauth2.AuthMux
+}
+
+// NewOAuthHandler returns a new instance of OAuthHandler.
+func NewOAuthHandler(log *zap.Logger, b *OAuthBackend) *OAuthHandler {
+	h := &OAuthHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		providers: b.Config.Providers,
+		muxes:     make(map[string]*oauth2.AuthMux, len(b.Config.Providers)),
+	}
+
+	auth := &SessionAuthenticator{
+		log:                        log,
+		GroupOrgs:                  b.Config.GroupOrgs,
+		SessionService:             b.SessionService,
+		UserService:                b.UserService,
+		OrganizationService:        b.OrganizationService,
+		UserResourceMappingService: b.UserResourceMappingService,
+	}
+	for _, p := range b.Config.Providers {
+		mux := oauth2.NewAuthMux(p, auth, b.Config.Tokens, "", newChronografLogger(log), b.Config.UseIDToken)
+		mux.FailureURL = "/signin"
+		h.muxes[p.Name()] = mux
+	}
+
+	h.HandlerFunc("GET", prefixOAuth, h.handleGetProviders)
+	h.HandlerFunc("GET", oauthLoginPath, h.handleLogin)
+	h.HandlerFunc("GET", oauthCallbackPath, h.handleCallback)
+	return h
+}
+
+type oauthProvider struct {
+	Name  string `json:"name"`
+	Login string `json:"login"`
+}
+
+type oauthProvidersResponse struct {
+	Providers []oauthProvider `json:"providers"`
+}
+
+// handleGetProviders is the HTTP handler for the GET /api/v2/oauth route.
+func (h *OAuthHandler) handleGetProviders(w http.ResponseWriter, r *http.Request) {
+	res := oauthProvidersResponse{Providers: make([]oauthProvider, 0, len(h.providers))}
+	for _, p := range h.providers {
+		res.Providers = append(res.Providers, oauthProvider{
+			Name:  p.Name(),
+			Login: fmt.Sprintf("%s/%s/login", prefixOAuth, p.Name()),
+		})
+	}
+	if err := encodeResponse(r.Context(), w, http.StatusOK, res); err != nil {
+		logEncodingError(h.log, r, err)
+	}
+}
+
+// handleLogin is the HTTP handler for the GET /api/v2/oauth/:provider/login
+// route. It redirects to the sign-in page of the provider.
+func (h *OAuthHandler) handleLogin(w http.ResponseWriter, r *http.Request) {
+	mux, err := h.mux(r)
+	if err != nil {
+		h.HandleHTTPError(r.Context(), err, w)
+		return
+	}
+	mux.Login().ServeHTTP(w, r)
+}
+
+// handleCallback is the HTTP handler for the GET /api/v2/oauth/:provider/callback
+// route. The provider redirects to it once the user signed in.
+func (h *OAuthHandler) handleCallback(w http.ResponseWriter, r *http.Request) {
+	mux, err := h.mux(r)
+	if err != nil {
+		h.HandleHTTPError(r.Context(), err, w)
+		return
+	}
+	mux.Callback().ServeHTTP(w, r)
+}
+
+func (h *OAuthHandler) mux(r *http.Request) (*oauth2.AuthMux, error) {
+	name := httprouter.ParamsFromContext(r.Context()).ByName("provider")
+	mux, ok := h.muxes[name]
+	if !ok {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  fmt.Sprintf("oauth provider %q not found", name),
+		}
+	}
+	return mux, nil
+}
+
+// SessionAuthenticator is the oauth2.Authenticator that gives a session
+// to the users signed in by OAuthHandler.
+type SessionAuthenticator struct {
+	log *zap.Logger
+
+	// GroupOrgs maps the groups of the users to the names of the orgs they
+	// are made members of.
+	GroupOrgs map[string]string
+
+	SessionService             influxdb.SessionService
+	UserService                influxdb.UserService
+	OrganizationService        influxdb.OrganizationService
+	UserResourceMappingService influxdb.UserResourceMappingService
+}
+
+var _ oauth2.Authenticator = (*SessionAuthenticator)(nil)
+
+// Authorize creates the user of the principal if it does not exist, syncs
+// its memberships of the orgs of the groups and sets the cookie of a new
+// session.
+func (a *SessionAuthenticator) Authorize(ctx context.Context, w http.ResponseWriter, p oauth2.Principal) error {
+	u, err := a.findOrCreateUser(ctx, p.Subject)
+	if err != nil {
+		return err
+	}
+	if u.Status == influxdb.Inactive {
+		return &influxdb.Error{Code: influxdb.EForbidden, Msg: "User is inactive"}
+	}
+
+	member := make(map[string]bool)
+	for _, group := range strings.Split(p.Group, ",") {
+		if org, ok := a.GroupOrgs[strings.TrimSpace(group)]; ok {
+			member[org] = true
+		}
+	}
+	for _, org := range a.GroupOrgs {
+		if member[org] {
+			err = a.addMember(ctx, u.ID, org)
+		} else {
+			err = a.removeMember(ctx, u.ID, org)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	s, err := a.SessionService.CreateSession(ctx, u.Name)
+	if err != nil {
+		return err
+	}
+	encodeCookieSession(w, s)
+	return nil
+}
+
+// findOrCreateUser returns the user of the principal, creating it if it does
+// not exist. Users that were not created by signing in with a provider, such
+// as local users of the same name, are never signed in.
+func (a *SessionAuthenticator) findOrCreateUser(ctx context.Context, name string) (*influxdb.User, error) {
+	u, err := a.UserService.FindUser(ctx, influxdb.UserFilter{Name: &name})
+	if err == nil {
+		if u.OAuthID != oauthIDPrefix+name {
+			a.log.Warn("Refused oauth sign-in of user not created by oauth", zap.String("user", name))
+			return nil, &influxdb.Error{
+				Code: influxdb.EForbidden,
+				Msg:  "user was not created by single sign-on",
+			}
+		}
+		return u, nil
+	}
+	if influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return nil, err
+	}
+
+	u = &influxdb.User{Name: name, OAuthID: oauthIDPrefix + name, Status: influxdb.Active}
+	if err := a.UserService.CreateUser(ctx, u); err != nil {
+		return nil, err
+	}
+	a.log.Info("Created user signed in with oauth", zap.String("user", name), zap.Stringer("userID", u.ID))
+	return u, nil
+}
+
+// addMember makes the user a member of the org, unless it already has a
+// role in it.
+func (a *SessionAuthenticator) addMember(ctx context.Context, userID influxdb.ID, orgName string) error {
+	org, urms, err := a.findOrgMappings(ctx, userID, orgName)
+	if err != nil || org == nil || len(urms) > 0 {
+		return err
+	}
+	return a.UserResourceMappingService.CreateUserResourceMapping(ctx, &influxdb.UserResourceMapping{
+		ResourceID:   org.ID,
+		ResourceType: influxdb.OrgsResourceType,
+		UserID:       userID,
+		UserType:     influxdb.Member,
+	})
+}
+
+// removeMember removes the user from the members of the org. Owners keep
+// their role, since it is not given by the groups.
+func (a *SessionAuthenticator) removeMember(ctx context.Context, userID influxdb.ID, orgName string) error {
+	org, urms, err := a.findOrgMappings(ctx, userID, orgName)
+	if err != nil || org == nil {
+		return err
+	}
+	for _, urm := range urms {
+		if urm.UserType != influxdb.Member {
+			continue
+		}
+		if err := a.UserResourceMappingService.DeleteUserResourceMapping(ctx, org.ID, userID); err != nil {
+			return err
+		}
+		a.log.Info("Removed oauth user from organization", zap.Stringer("userID", userID), zap.String("org", orgName))
+	}
+	return nil
+}
+
+// findOrgMappings returns the org of the name and the roles of the user in
+// it. No org is returned if it does not exist.
+func (a *SessionAuthenticator) findOrgMappings(ctx context.Context, userID influxdb.ID, orgName string) (*influxdb.Organization, []*influxdb.UserResourceMapping, error) {
+	org, err := a.OrganizationService.FindOrganization(ctx, influxdb.OrganizationFilter{Name: &orgName})
+	if err != nil {
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			a.log.Warn("Organization of oauth group not found", zap.String("org", orgName))
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	urms, _, err := a.UserResourceMappingService.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+		ResourceID:   org.ID,
+		ResourceType: influxdb.OrgsResourceType,
+		UserID:       userID,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return org, urms, nil
+}
+
+// Validate is not supported, as the sessions are validated by the
+// authentication of the API.
+func (a *SessionAuthenticator) Validate(context.Context, *http.Request) (oauth2.Principal, error) {
+	return oauth2.Principal{}, errors.New("validating oauth principals is not supported")
+}
+
+// Extend is not supported, as the sessions are renewed by the
+// authentication of the API.
+func (a *SessionAuthenticator) Extend(context.Context, http.ResponseWriter, oauth2.Principal) (oauth2.Principal, error) {
+	return oauth2.Principal{}, errors.New("extending oauth principals is not supported")
+}
+
+// Expire removes the session cookie.
+func (a *SessionAuthenticator) Expire(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{Name: cookieSessionName, MaxAge: -1})
+}
+
+// chronografLogger logs the messages of the chronograf packages to zap.
+type chronografLogger struct {
+	log *zap.SugaredLogger
+}
+
+func newChronografLogger(log *zap.Logger) chronograf.Logger {
+	return &chronografLogger{log: log.Sugar()}
+}
+
+func (l *chronografLogger) Debug(args ...interface{}) { l.log.Debug(args...) }
+func (l *chronografLogger) Info(args ...interface{})  { l.log.Info(args...) }
+func (l *chronografLogger) Error(args ...interface{}) { l.log.Error(args...) }
+
+func (l *chronografLogger) WithField(key string, value interface{}) chronograf.Logger {
+	return &chronografLogger{log: l.log.With(key, value)}
+}
+
+func (l *chronografLogger) Writer() *io.PipeWriter {
+	r, w := io.Pipe()
+	go func() {
+		_, _ = io.Copy(zap.NewStdLog(l.log.Desugar()).Writer(), r)
+	}()
+	return w
+}
@@ -0,0 +1,194 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/chronograf/oauth2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap/zaptest"
+)
+
+// newMockOIDCServer returns an OpenID Connect provider that signs in the
+// user of the userinfo.
+func newMockOIDCServer(t *testing.T, userinfo map[string]interface{}) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/token":
+			if err := r.ParseForm(); err != nil || r.PostForm.Get("code") != "mycode" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token": "myaccesstoken",
+				"token_type":   "Bearer",
+			})
+		case "/userinfo":
+			if r.Header.Get("Authorization") != "Bearer myaccesstoken" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(userinfo)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestOAuthHandler_SignIn(t *testing.T) {
+	ctx := context.Background()
+	userinfo := map[string]interface{}{
+		"email":          "marty@example.com",
+		"email_verified": true,
+		"groups":         []string{"engineering", "unknown"},
+	}
+	idp := newMockOIDCServer(t, userinfo)
+	defer idp.Close()
+
+	svc := newInMemKVSVC(t)
+	org := &influxdb.Organization{Name: "acme"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewOAuthHandler(zaptest.NewLogger(t), &OAuthBackend{
+		HTTPErrorHandler: kithttp.ErrorHandler(0),
+		log:              zaptest.NewLogger(t),
+		Config: OAuthConfig{
+			Providers: []oauth2.Provider{&oauth2.OIDC{
+				ClientID:    "myclient",
+				AuthURL:     idp.URL + "/authorize",
+				TokenURL:    idp.URL + "/token",
+				UserinfoURL: idp.URL + "/userinfo",
+				RedirectURL: "http://localhost:9999/api/v2/oauth/oidc/callback",
+			}},
+			Tokens:    oauth2.NewJWT("secret", ""),
+			GroupOrgs: map[string]string{"engineering": "acme", "unknown": "missing"},
+		},
+		SessionService:             svc,
+		UserService:                svc,
+		OrganizationService:        svc,
+		UserResourceMappingService: svc,
+	})
+
+	// The providers are listed for the sign-in page.
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v2/oauth", nil))
+	if got, want := w.Body.String(), `{"providers":[{"name":"oidc","login":"/api/v2/oauth/oidc/login"}]}`; got != want+"\n" {
+		t.Errorf("unexpected providers: got %s want %s", got, want)
+	}
+
+	signIn := func(code string) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v2/oauth/oidc/login", nil))
+		if w.Code != http.StatusTemporaryRedirect {
+			t.Fatalf("unexpected login status: %d", w.Code)
+		}
+		loc, err := url.Parse(w.Header().Get("Location"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := loc.Scheme+"://"+loc.Host+loc.Path, idp.URL+"/authorize"; got != want {
+			t.Fatalf("unexpected login redirect: got %s want %s", got, want)
+		}
+
+		q := url.Values{"state": {loc.Query().Get("state")}, "code": {code}}
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v2/oauth/oidc/callback?"+q.Encode(), nil))
+		return w
+	}
+
+	name := "marty@example.com"
+	for i := 0; i < 2; i++ {
+		w := signIn("mycode")
+		if got, want := w.Header().Get("Location"), "/"; w.Code != http.StatusTemporaryRedirect || got != want {
+			t.Fatalf("unexpected callback redirect: %d %s", w.Code, got)
+		}
+
+		var key string
+		for _, c := range w.Result().Cookies() {
+			if c.Name == cookieSessionName {
+				key = c.Value
+			}
+		}
+		s, err := svc.FindSession(ctx, key)
+		if err != nil {
+			t.Fatalf("session of the sign-in not found: %v", err)
+		}
+
+		u, err := svc.FindUser(ctx, influxdb.UserFilter{Name: &name})
+		if err != nil {
+			t.Fatalf("user of the sign-in not found: %v", err)
+		}
+		if s.UserID != u.ID {
+			t.Errorf("unexpected session user: got %s want %s", s.UserID, u.ID)
+		}
+
+		urms, _, err := svc.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+			ResourceType: influxdb.OrgsResourceType,
+			UserID:       u.ID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(urms) != 1 || urms[0].ResourceID != org.ID || urms[0].UserType != influxdb.Member {
+			t.Errorf("unexpected user resource mappings after sign-in %d: %+v", i, urms)
+		}
+	}
+
+	// The user is removed from the orgs of the groups it left.
+	userinfo["groups"] = []string{}
+	if w := signIn("mycode"); w.Header().Get("Location") != "/" {
+		t.Fatalf("unexpected callback redirect: %d %s", w.Code, w.Header().Get("Location"))
+	}
+	u, err := svc.FindUser(ctx, influxdb.UserFilter{Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, n, err := svc.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+		ResourceType: influxdb.OrgsResourceType,
+		UserID:       u.ID,
+	}); err != nil || n != 0 {
+		t.Errorf("expected no user resource mappings after leaving the group, got %d: %v", n, err)
+	}
+
+	// Local users are not signed in by the provider.
+	local := &influxdb.User{Name: "doc@example.com", Status: influxdb.Active}
+	if err := svc.CreateUser(ctx, local); err != nil {
+		t.Fatal(err)
+	}
+	userinfo["email"] = local.Name
+	if w := signIn("mycode"); w.Header().Get("Location") != "/signin" || len(w.Result().Cookies()) != 0 {
+		t.Errorf("unexpected sign-in as a local user: %d %s", w.Code, w.Header().Get("Location"))
+	}
+
+	// Unverified emails are not signed in.
+	userinfo["email"], userinfo["email_verified"] = "biff@example.com", false
+	if w := signIn("mycode"); w.Header().Get("Location") != "/signin" || len(w.Result().Cookies()) != 0 {
+		t.Errorf("unexpected sign-in with an unverified email: %d %s", w.Code, w.Header().Get("Location"))
+	}
+
+	// Failed sign-ins are redirected to the sign-in page.
+	if w := signIn("invalid"); w.Header().Get("Location") != "/signin" || len(w.Result().Cookies()) != 0 {
+		t.Errorf("unexpected failed callback: %d %s", w.Code, w.Header().Get("Location"))
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v2/oauth/oidc/callback?state=invalid&code=mycode", nil))
+	if w.Header().Get("Location") != "/signin" || len(w.Result().Cookies()) != 0 {
+		t.Errorf("unexpected callback with invalid state: %d %s", w.Code, w.Header().Get("Location"))
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v2/oauth/github/login", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unexpected status for unknown provider: %d", w.Code)
+	}
+}
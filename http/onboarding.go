@@ -115,6 +115,7 @@ func newOnboardingResponse(results *platform.OnboardingResults) *onboardingRespo
 			Resource: resourceResponse{
 				Resource: p.Resource,
 			},
+			Predicate: p.Predicate,
 		}
 	}
 	return &onboardingResponse{
@@ -27,6 +27,8 @@ type OrgBackend struct {
 	SecretService                   influxdb.SecretService
 	LabelService                    influxdb.LabelService
 	UserService                     influxdb.UserService
+	OrgLimitsService                influxdb.OrgLimitsService
+	UsageService                    influxdb.UsageService
 }
 
 // NewOrgBackend is a datasource used by the org handler.
@@ -41,6 +43,8 @@ func NewOrgBackend(log *zap.Logger, b *APIBackend) *OrgBackend {
 		SecretService:                   b.SecretService,
 		LabelService:                    b.LabelService,
 		UserService:                     b.UserService,
+		OrgLimitsService:                b.OrgLimitsService,
+		UsageService:                    b.UsageService,
 	}
 }
 
@@ -56,6 +60,8 @@ type OrgHandler struct {
 	SecretService                   influxdb.SecretService
 	LabelService                    influxdb.LabelService
 	UserService                     influxdb.UserService
+	OrgLimitsService                influxdb.OrgLimitsService
+	UsageService                    influxdb.UsageService
 }
 
 const (
@@ -71,6 +77,8 @@ const (
 	organizationsIDSecretsDeletePath = "/api/v2/orgs/:id/secrets/delete"
 	organizationsIDLabelsPath        = "/api/v2/orgs/:id/labels"
 	organizationsIDLabelsIDPath      = "/api/v2/orgs/:id/labels/:lid"
+	organizationsIDLimitsPath        = "/api/v2/orgs/:id/limits"
+	organizationsIDUsagePath         = "/api/v2/orgs/:id/usage"
 )
 
 func checkOrganizationExists(orgHandler *OrgHandler) kithttp.Middleware {
@@ -107,6 +115,8 @@ func NewOrgHandler(log *zap.Logger, b *OrgBackend) *OrgHandler {
 		SecretService:                   b.SecretService,
 		LabelService:                    b.LabelService,
 		UserService:                     b.UserService,
+		OrgLimitsService:                b.OrgLimitsService,
+		UsageService:                    b.UsageService,
 	}
 
 	h.HandlerFunc("POST", prefixOrganizations, h.handlePostOrg)
@@ -155,6 +165,11 @@ func NewOrgHandler(log *zap.Logger, b *OrgBackend) *OrgHandler {
 	h.HandlerFunc("POST", organizationsIDLabelsPath, newPostLabelHandler(labelBackend))
 	h.HandlerFunc("DELETE", organizationsIDLabelsIDPath, newDeleteLabelHandler(labelBackend))
 
+	h.Handler("GET", organizationsIDLimitsPath, applyMW(http.HandlerFunc(h.handleGetOrgLimits), checkOrganizationExists(h)))
+	h.HandlerFunc("PATCH", organizationsIDLimitsPath, h.handlePatchOrgLimits)
+
+	h.Handler("GET", organizationsIDUsagePath, applyMW(http.HandlerFunc(h.handleGetOrgUsage), checkOrganizationExists(h)))
+
 	return h
 }
 
@@ -198,6 +213,8 @@ func newOrgResponse(o influxdb.Organization) orgResponse {
 			"owners":     fmt.Sprintf("/api/v2/orgs/%s/owners", o.ID),
 			"secrets":    fmt.Sprintf("/api/v2/orgs/%s/secrets", o.ID),
 			"labels":     fmt.Sprintf("/api/v2/orgs/%s/labels", o.ID),
+			"limits":     fmt.Sprintf("/api/v2/orgs/%s/limits", o.ID),
+			"usage":      fmt.Sprintf("/api/v2/orgs/%s/usage", o.ID),
 			"buckets":    fmt.Sprintf("/api/v2/buckets?org=%s", o.Name),
 			"tasks":      fmt.Sprintf("/api/v2/tasks?org=%s", o.Name),
 			"dashboards": fmt.Sprintf("/api/v2/dashboards?org=%s", o.Name),
@@ -375,6 +392,91 @@ func (h *OrgHandler) handlePatchSecrets(w http.ResponseWriter, r *http.Request)
 	h.API.Respond(w, http.StatusNoContent, nil)
 }
 
+type orgLimitsResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.OrgLimits
+}
+
+func newOrgLimitsResponse(l influxdb.OrgLimits) orgLimitsResponse {
+	return orgLimitsResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("/api/v2/orgs/%s/limits", l.OrgID),
+			"org":  fmt.Sprintf("/api/v2/orgs/%s", l.OrgID),
+		},
+		OrgLimits: l,
+	}
+}
+
+// handleGetOrgLimits is the HTTP handler for the GET /api/v2/orgs/:id/limits route.
+func (h *OrgHandler) handleGetOrgLimits(w http.ResponseWriter, r *http.Request) {
+	orgID, err := decodeIDFromCtx(r.Context(), "id")
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	l, err := h.OrgLimitsService.FindOrgLimits(r.Context(), orgID)
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	h.API.Respond(w, http.StatusOK, newOrgLimitsResponse(*l))
+}
+
+// handlePatchOrgLimits is the HTTP handler for the PATCH /api/v2/orgs/:id/limits route.
+func (h *OrgHandler) handlePatchOrgLimits(w http.ResponseWriter, r *http.Request) {
+	orgID, err := decodeIDFromCtx(r.Context(), "id")
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	var upd influxdb.OrgLimitsUpdate
+	if err := h.API.DecodeJSON(r.Body, &upd); err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	l, err := h.OrgLimitsService.UpdateOrgLimits(r.Context(), orgID, upd)
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+	h.log.Debug("Org limits updated", zap.String("limits", fmt.Sprint(l)))
+
+	h.API.Respond(w, http.StatusOK, newOrgLimitsResponse(*l))
+}
+
+// handleGetOrgUsage is the HTTP handler for the GET /api/v2/orgs/:id/usage route.
+func (h *OrgHandler) handleGetOrgUsage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	req, err := decodeGetUsageRequest(ctx, r)
+	if err != nil {
+		h.API.Err(w, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid usage request",
+			Err:  err,
+		})
+		return
+	}
+	req.filter.OrgID = &orgID
+
+	usage, err := h.UsageService.GetUsage(ctx, req.filter)
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	h.API.Respond(w, http.StatusOK, usage)
+}
+
 type secretsDeleteBody struct {
 	Secrets []string `json:"secrets"`
 }
@@ -518,6 +620,55 @@ func (s *SecretService) DeleteSecret(ctx context.Context, orgID influxdb.ID, ks
 		Do(ctx)
 }
 
+// OrgLimitsService connects to Influx via HTTP using tokens to manage the limits of organizations.
+type OrgLimitsService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.OrgLimitsService = (*OrgLimitsService)(nil)
+
+// FindOrgLimits gets the limits of an organization via HTTP.
+func (s *OrgLimitsService) FindOrgLimits(ctx context.Context, orgID influxdb.ID) (*influxdb.OrgLimits, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	span.LogKV("org-id", orgID)
+
+	path := strings.Replace(organizationsIDLimitsPath, ":id", orgID.String(), 1)
+
+	var l orgLimitsResponse
+	err := s.Client.
+		Get(path).
+		DecodeJSON(&l).
+		Do(ctx)
+	if err != nil {
+		return nil, tracing.LogError(span, err)
+	}
+
+	return &l.OrgLimits, nil
+}
+
+// UpdateOrgLimits updates the limits of an organization via HTTP.
+func (s *OrgLimitsService) UpdateOrgLimits(ctx context.Context, orgID influxdb.ID, upd influxdb.OrgLimitsUpdate) (*influxdb.OrgLimits, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	span.LogKV("org-id", orgID)
+
+	path := strings.Replace(organizationsIDLimitsPath, ":id", orgID.String(), 1)
+
+	var l orgLimitsResponse
+	err := s.Client.
+		PatchJSON(upd, path).
+		DecodeJSON(&l).
+		Do(ctx)
+	if err != nil {
+		return nil, tracing.LogError(span, err)
+	}
+
+	return &l.OrgLimits, nil
+}
+
 // OrganizationService connects to Influx via HTTP using tokens to manage organizations.
 type OrganizationService struct {
 	Client *httpc.Client
@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 
 	"github.com/influxdata/influxdb/v2"
@@ -30,6 +31,7 @@ func NewMockOrgBackend(t *testing.T) *OrgBackend {
 		SecretService:                   mock.NewSecretService(),
 		LabelService:                    mock.NewLabelService(),
 		UserService:                     mock.NewUserService(),
+		OrgLimitsService:                mock.NewOrgLimitsService(),
 	}
 }
 
@@ -108,6 +110,55 @@ func TestSecretService(t *testing.T) {
 	influxdbtesting.PatchSecrets(initSecretService, t)
 }
 
+func TestOrgLimitsService(t *testing.T) {
+	t.Parallel()
+
+	svc := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	orgBackend := NewMockOrgBackend(t)
+	orgBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+	orgBackend.OrganizationService = svc
+	orgBackend.OrgLimitsService = svc
+	server := httptest.NewServer(NewOrgHandler(zaptest.NewLogger(t), orgBackend))
+	defer server.Close()
+	client := OrgLimitsService{
+		Client: mustNewHTTPClient(t, server.URL, ""),
+	}
+
+	concurrency, weight := 4, 2
+	got, err := client.UpdateOrgLimits(ctx, org.ID, influxdb.OrgLimitsUpdate{
+		ConcurrencyQuota: &concurrency,
+		Weight:           &weight,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &influxdb.OrgLimits{OrgID: org.ID, ConcurrencyQuota: 4, Weight: 2}
+	if !reflect.DeepEqual(want, got) {
+		t.Fatalf("UpdateOrgLimits() = %+v, want %+v", got, want)
+	}
+
+	got, err = client.FindOrgLimits(ctx, org.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Fatalf("FindOrgLimits() = %+v, want %+v", got, want)
+	}
+
+	if _, err := client.FindOrgLimits(ctx, influxdb.ID(1)); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected org not found, got %v", err)
+	}
+}
+
 func TestSecretService_handleGetSecrets(t *testing.T) {
 	type fields struct {
 		SecretService influxdb.SecretService
@@ -23,10 +23,16 @@ func NewPlatformHandler(b *APIBackend, opts ...APIHandlerOptFn) *PlatformHandler
 	h.SessionService = b.SessionService
 	h.SessionRenewDisabled = b.SessionRenewDisabled
 	h.UserService = b.UserService
+	h.LastUsedTracker = b.AuthorizationLastUsedTracker
+	h.RoleService = b.RoleService
+	h.UserGroupService = b.UserGroupService
 
 	h.RegisterNoAuthRoute("GET", "/api/v2")
 	h.RegisterNoAuthRoute("POST", "/api/v2/signin")
 	h.RegisterNoAuthRoute("POST", "/api/v2/signout")
+	h.RegisterNoAuthRoute("GET", prefixOAuth)
+	h.RegisterNoAuthRoute("GET", oauthLoginPath)
+	h.RegisterNoAuthRoute("GET", oauthCallbackPath)
 	h.RegisterNoAuthRoute("POST", "/api/v2/setup")
 	h.RegisterNoAuthRoute("GET", "/api/v2/setup")
 	h.RegisterNoAuthRoute("GET", "/api/v2/swagger.json")
@@ -55,6 +61,7 @@ func (h *PlatformHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// of the platform API.
 	if !strings.HasPrefix(r.URL.Path, "/v1") &&
 		!strings.HasPrefix(r.URL.Path, "/api/v2") &&
+		!strings.HasPrefix(r.URL.Path, prefixPromQL+"/") &&
 		!strings.HasPrefix(r.URL.Path, "/chronograf/") {
 		h.AssetHandler.ServeHTTP(w, r)
 		return
@@ -0,0 +1,381 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
+	"github.com/golang/snappy"
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	pcontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/http/metric"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	pr "github.com/influxdata/influxdb/v2/prometheus"
+	"github.com/influxdata/influxdb/v2/prometheus/prompb"
+	"github.com/influxdata/influxdb/v2/ratelimit"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/storage/reads"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/usage"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixPromRemote    = "/api/v2/prom"
+	promRemoteWritePath = "/api/v2/prom/write"
+	promRemoteReadPath  = "/api/v2/prom/read"
+)
+
+// PromRemoteBackend is all services and associated parameters required to construct
+// the PromRemoteHandler.
+type PromRemoteBackend struct {
+	influxdb.HTTPErrorHandler
+	log                *zap.Logger
+	WriteEventRecorder metric.EventRecorder
+	RateLimiter        *ratelimit.Limiter
+	UsageRecorder      influxdb.UsageRecorder
+
+	MaxBatchSizeBytes int64
+	Schema            pr.RemoteSchema
+
+	PointsWriter        storage.PointsWriter
+	ReadStore           reads.Store
+	BucketService       influxdb.BucketService
+	OrganizationService influxdb.OrganizationService
+}
+
+// NewPromRemoteBackend returns a new instance of PromRemoteBackend.
+func NewPromRemoteBackend(log *zap.Logger, b *APIBackend) *PromRemoteBackend {
+	return &PromRemoteBackend{
+		HTTPErrorHandler:   b.HTTPErrorHandler,
+		log:                log,
+		WriteEventRecorder: b.WriteEventRecorder,
+		RateLimiter:        b.RateLimiter,
+		UsageRecorder:      b.UsageRecorder,
+
+		MaxBatchSizeBytes: b.MaxBatchSizeBytes,
+		Schema:            b.PromRemoteSchema,
+
+		PointsWriter:        b.PointsWriter,
+		ReadStore:           b.ReadStore,
+		BucketService:       b.BucketService,
+		OrganizationService: b.OrganizationService,
+	}
+}
+
+// PromRemoteHandler implements the remote write and read protocols of
+// Prometheus, so that InfluxDB can be the long-term storage of Prometheus
+// servers. The bucket of the metrics is given by the org and bucket
+// parameters of the remote URLs.
+type PromRemoteHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	EventRecorder metric.EventRecorder
+
+	// RateLimiter limits the rates of writes and reads when it is set.
+	RateLimiter *ratelimit.Limiter
+
+	// UsageRecorder records the usage of writes and reads when it is set.
+	UsageRecorder influxdb.UsageRecorder
+
+	maxBatchSizeBytes int64
+	schema            pr.RemoteSchema
+
+	PointsWriter        storage.PointsWriter
+	ReadStore           reads.Store
+	BucketService       influxdb.BucketService
+	OrganizationService influxdb.OrganizationService
+}
+
+// NewPromRemoteHandler returns a new instance of PromRemoteHandler.
+func NewPromRemoteHandler(log *zap.Logger, b *PromRemoteBackend) *PromRemoteHandler {
+	h := &PromRemoteHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		EventRecorder: b.WriteEventRecorder,
+		RateLimiter:   b.RateLimiter,
+		UsageRecorder: b.UsageRecorder,
+
+		maxBatchSizeBytes: b.MaxBatchSizeBytes,
+		schema:            b.Schema,
+
+		PointsWriter:        b.PointsWriter,
+		ReadStore:           b.ReadStore,
+		BucketService:       b.BucketService,
+		OrganizationService: b.OrganizationService,
+	}
+
+	h.HandlerFunc("POST", promRemoteWritePath, h.handleWrite)
+	h.HandlerFunc("POST", promRemoteReadPath, h.handleRead)
+	return h
+}
+
+// handleWrite is the HTTP handler for the POST /api/v2/prom/write route.
+func (h *PromRemoteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "PromRemoteHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	var (
+		orgID        influxdb.ID
+		requestBytes int
+		sw           = kithttp.NewStatusResponseWriter(w)
+	)
+	w = sw
+	defer func() {
+		h.EventRecorder.Record(ctx, metric.Event{
+			OrgID:         orgID,
+			Endpoint:      r.URL.Path,
+			RequestBytes:  requestBytes,
+			ResponseBytes: sw.ResponseBytes(),
+			Status:        sw.Code(),
+		})
+	}()
+
+	bucket, pred, err := h.authorizedBucket(ctx, r, influxdb.WriteAction)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	orgID = bucket.OrgID
+
+	var req prompb.WriteRequest
+	requestBytes, err = h.decodeRequest(ctx, r, &req)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	pts, err := h.schema.Points(&req)
+	if err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   "http/handlePromRemoteWrite",
+			Err:  err,
+		}, w)
+		return
+	}
+	span.LogKV("values_total", len(pts))
+	if len(pts) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	pts, err = tsdb.ExplodePoints(bucket.OrgID, bucket.ID, pts)
+	if err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   "http/handlePromRemoteWrite",
+			Err:  err,
+		}, w)
+		return
+	}
+	if err := authorizer.AuthorizeWritePoints(pred, pts); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if h.RateLimiter != nil {
+		if err := h.RateLimiter.AllowWrite(ctx, bucket.OrgID, remoteIP(r), requestBytes, len(pts)); err != nil {
+			rateLimitedError(ctx, h, w, err)
+			return
+		}
+	}
+	ctx = usage.NewContextWithWriteBytes(ctx, requestBytes)
+	if err := h.PointsWriter.WritePoints(ctx, pts); err != nil {
+		if influxdb.ErrorCode(err) == influxdb.EForbidden {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		h.log.Error("Error writing points", zap.Error(err))
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Op:   "http/handlePromRemoteWrite",
+			Msg:  "unexpected error writing points to database",
+			Err:  err,
+		}, w)
+		return
+	}
+	recordWriteUsage(ctx, h.UsageRecorder, bucket.OrgID, bucket.ID, requestBytes, len(pts))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleRead is the HTTP handler for the POST /api/v2/prom/read route.
+func (h *PromRemoteHandler) handleRead(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "PromRemoteHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	bucket, _, err := h.authorizedBucket(ctx, r, influxdb.ReadAction)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var req prompb.ReadRequest
+	requestBytes, err := h.decodeRequest(ctx, r, &req)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if h.RateLimiter != nil {
+		if err := h.RateLimiter.AllowQuery(ctx, bucket.OrgID, remoteIP(r), requestBytes); err != nil {
+			rateLimitedError(ctx, h, w, err)
+			return
+		}
+	}
+	recordQueryUsage(ctx, h.UsageRecorder, bucket.OrgID, requestBytes)
+
+	source, err := types.MarshalAny(h.ReadStore.GetSource(uint64(bucket.OrgID), uint64(bucket.ID)))
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	res := &prompb.ReadResponse{
+		Results: make([]*prompb.QueryResult, len(req.Queries)),
+	}
+	for i, q := range req.Queries {
+		series, err := h.read(ctx, source, q)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		res.Results[i] = &prompb.QueryResult{Timeseries: series}
+	}
+
+	data, err := proto.Marshal(res)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Header().Set("Content-Encoding", "snappy")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(snappy.Encode(nil, data)); err != nil {
+		h.log.Info("Failed to write response", zap.Error(err))
+	}
+}
+
+// read finds the series that match the query in the bucket of the source.
+func (h *PromRemoteHandler) read(ctx context.Context, source *types.Any, q *prompb.Query) ([]*prompb.TimeSeries, error) {
+	pred, err := h.schema.Predicate(q.Matchers)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   "http/handlePromRemoteRead",
+			Err:  err,
+		}
+	}
+
+	// The end of a query is inclusive, while that of a storage read is not.
+	rs, err := h.ReadStore.ReadFilter(ctx, &datatypes.ReadFilterRequest{
+		ReadSource: source,
+		Range: datatypes.TimestampRange{
+			Start: q.StartTimestampMs * 1e6,
+			End:   q.EndTimestampMs*1e6 + 1,
+		},
+		Predicate: pred,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if rs == nil {
+		return nil, nil
+	}
+	defer rs.Close()
+	return h.schema.TimeSeries(rs)
+}
+
+// authorizedBucket finds the bucket of the request and checks that the
+// authorizer of the request is allowed the action on it. If it is only
+// allowed the action on the series that match the predicates of its
+// permissions, the predicate of the bucket is returned as well. The series
+// that are read are restricted by the ReadStore.
+func (h *PromRemoteHandler) authorizedBucket(ctx context.Context, r *http.Request, action influxdb.Action) (*influxdb.Bucket, *datatypes.Predicate, error) {
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	org, err := queryOrganization(ctx, r, h.OrganizationService)
+	if err != nil {
+		return nil, nil, err
+	}
+	bucket, err := queryBucket(ctx, org.ID, r, h.BucketService)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p, err := influxdb.NewPermissionAtID(bucket.ID, action, influxdb.BucketsResourceType, org.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if a.Allowed(*p) {
+		return bucket, nil, nil
+	}
+
+	pred, err := authorizer.BucketPredicate(a, action, org.ID, bucket.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pred == nil {
+		return nil, nil, &influxdb.Error{
+			Code: influxdb.EForbidden,
+			Msg:  "insufficient permissions for " + string(action),
+		}
+	}
+	return bucket, pred, nil
+}
+
+// decodeRequest decodes the snappy compressed protocol buffer message of
+// the request body. It returns the size of the decompressed message.
+func (h *PromRemoteHandler) decodeRequest(ctx context.Context, r *http.Request, msg proto.Message) (int, error) {
+	span, _ := tracing.StartSpanFromContextWithOperationName(ctx, "read request body")
+	defer span.Finish()
+
+	var body = r.Body
+	if h.maxBatchSizeBytes > 0 {
+		body = newLimitedReadCloser(body, h.maxBatchSizeBytes)
+	}
+	compressed, err := ioutil.ReadAll(body)
+	if cerr := body.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		code := influxdb.EInternal
+		if errors.Is(err, ErrMaxBatchSizeExceeded) {
+			code = influxdb.ETooLarge
+		}
+		return 0, &influxdb.Error{Code: code, Msg: "unable to read data", Err: err}
+	}
+
+	n, err := snappy.DecodedLen(compressed)
+	if err != nil {
+		return 0, &influxdb.Error{Code: influxdb.EInvalid, Msg: "invalid snappy data", Err: err}
+	}
+	if h.maxBatchSizeBytes > 0 && int64(n) > h.maxBatchSizeBytes {
+		return 0, &influxdb.Error{Code: influxdb.ETooLarge, Msg: "unable to read data", Err: ErrMaxBatchSizeExceeded}
+	}
+	data, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return 0, &influxdb.Error{Code: influxdb.EInvalid, Msg: "invalid snappy data", Err: err}
+	}
+	span.LogKV("request_bytes", len(data))
+
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return 0, &influxdb.Error{Code: influxdb.EInvalid, Msg: "invalid protocol buffer message", Err: err}
+	}
+	return len(data), nil
+}
@@ -0,0 +1,726 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	pcontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/query/promql"
+	"github.com/influxdata/influxdb/v2/ratelimit"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixPromQL          = "/api/v1"
+	promQLQueryPath       = "/api/v1/query"
+	promQLQueryRangePath  = "/api/v1/query_range"
+	promQLSeriesPath      = "/api/v1/series"
+	promQLLabelsPath      = "/api/v1/labels"
+	promQLLabelValuesPath = "/api/v1/label/:name/values"
+
+	// promQLMaxPoints is the most steps that a range query may evaluate,
+	// which is the same limit as Prometheus.
+	promQLMaxPoints = 11000
+)
+
+// PromQLBackend is all services and associated parameters required to construct
+// the PromQLHandler.
+type PromQLBackend struct {
+	influxdb.HTTPErrorHandler
+	log           *zap.Logger
+	RateLimiter   *ratelimit.Limiter
+	UsageRecorder influxdb.UsageRecorder
+
+	OrganizationService influxdb.OrganizationService
+	BucketService       influxdb.BucketService
+	QueryService        query.QueryService
+}
+
+// NewPromQLBackend returns a new instance of PromQLBackend.
+func NewPromQLBackend(log *zap.Logger, b *APIBackend) *PromQLBackend {
+	return &PromQLBackend{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+		RateLimiter:      b.RateLimiter,
+		UsageRecorder:    b.UsageRecorder,
+
+		OrganizationService: b.OrganizationService,
+		BucketService:       b.BucketService,
+		QueryService:        query.QueryServiceProxyBridge{ProxyQueryService: b.FluxService},
+	}
+}
+
+// PromQLHandler implements the query API of Prometheus. The PromQL queries
+// are transpiled to Flux and read the metrics of the bucket given by the
+// bucket or bucketID parameter of the requests.
+type PromQLHandler struct {
+	*httprouter.Router
+	log *zap.Logger
+
+	// RateLimiter limits the rates of queries when it is set.
+	RateLimiter *ratelimit.Limiter
+
+	// UsageRecorder records the usage of queries when it is set.
+	UsageRecorder influxdb.UsageRecorder
+
+	OrganizationService influxdb.OrganizationService
+	BucketService       influxdb.BucketService
+	QueryService        query.QueryService
+}
+
+// NewPromQLHandler returns a new instance of PromQLHandler.
+func NewPromQLHandler(log *zap.Logger, b *PromQLBackend) *PromQLHandler {
+	h := &PromQLHandler{
+		Router: NewRouter(b.HTTPErrorHandler),
+		log:    log,
+
+		RateLimiter:   b.RateLimiter,
+		UsageRecorder: b.UsageRecorder,
+
+		OrganizationService: b.OrganizationService,
+		BucketService:       b.BucketService,
+		QueryService:        b.QueryService,
+	}
+
+	for _, method := range []string{"GET", "POST"} {
+		h.HandlerFunc(method, promQLQueryPath, h.handleQuery)
+		h.HandlerFunc(method, promQLQueryRangePath, h.handleQueryRange)
+		h.HandlerFunc(method, promQLSeriesPath, h.handleSeries)
+		h.HandlerFunc(method, promQLLabelsPath, h.handleLabels)
+	}
+	h.HandlerFunc("GET", promQLLabelValuesPath, h.handleLabelValues)
+	return h
+}
+
+// The error types of the Prometheus API.
+const (
+	promErrorBadData   = "bad_data"
+	promErrorExecution = "execution"
+	promErrorInternal  = "internal"
+)
+
+type promError struct {
+	typ string
+	err error
+}
+
+func (e *promError) Error() string {
+	return e.err.Error()
+}
+
+func promBadData(format string, args ...interface{}) error {
+	return &promError{typ: promErrorBadData, err: fmt.Errorf(format, args...)}
+}
+
+type promResponse struct {
+	Status    string      `json:"status"`
+	Data      interface{} `json:"data,omitempty"`
+	ErrorType string      `json:"errorType,omitempty"`
+	Error     string      `json:"error,omitempty"`
+}
+
+type promQueryData struct {
+	ResultType string      `json:"resultType"`
+	Result     interface{} `json:"result"`
+}
+
+type promSample struct {
+	Metric map[string]string `json:"metric"`
+	Value  promPoint         `json:"value"`
+}
+
+type promSeries struct {
+	Metric map[string]string `json:"metric"`
+	Values []promPoint       `json:"values"`
+}
+
+// promPoint is encoded as an array of the time in seconds and the value
+// as a string.
+type promPoint struct {
+	T time.Time
+	V float64
+}
+
+func (p promPoint) MarshalJSON() ([]byte, error) {
+	var v string
+	switch {
+	case math.IsNaN(p.V):
+		v = "NaN"
+	case math.IsInf(p.V, 1):
+		v = "+Inf"
+	case math.IsInf(p.V, -1):
+		v = "-Inf"
+	default:
+		v = strconv.FormatFloat(p.V, 'f', -1, 64)
+	}
+	t := strconv.FormatFloat(float64(p.T.UnixNano()/int64(time.Millisecond))/1e3, 'f', -1, 64)
+	return []byte(fmt.Sprintf("[%s,%q]", t, v)), nil
+}
+
+// handleQuery is the HTTP handler for the /api/v1/query route.
+func (h *PromQLHandler) handleQuery(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "PromQLHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	data, err := func() (interface{}, error) {
+		bucket, err := h.decodeBucket(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		t := time.Now()
+		if v := r.Form.Get("time"); v != "" {
+			if t, err = parsePromTime(v); err != nil {
+				return nil, promBadData("invalid parameter \"time\": %v", err)
+			}
+		}
+		return h.eval(ctx, bucket, r.Form.Get("query"), promql.EvalParams{
+			BucketID: bucket.ID.String(),
+			Start:    t,
+			End:      t,
+		})
+	}()
+	h.respond(w, data, err)
+}
+
+// handleQueryRange is the HTTP handler for the /api/v1/query_range route.
+func (h *PromQLHandler) handleQueryRange(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "PromQLHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	data, err := func() (interface{}, error) {
+		bucket, err := h.decodeBucket(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		start, err := parsePromTime(r.Form.Get("start"))
+		if err != nil {
+			return nil, promBadData("invalid parameter \"start\": %v", err)
+		}
+		end, err := parsePromTime(r.Form.Get("end"))
+		if err != nil {
+			return nil, promBadData("invalid parameter \"end\": %v", err)
+		}
+		if end.Before(start) {
+			return nil, promBadData("invalid parameter \"end\": end timestamp must not be before start time")
+		}
+		step, err := parsePromDuration(r.Form.Get("step"))
+		if err != nil {
+			return nil, promBadData("invalid parameter \"step\": %v", err)
+		}
+		if step <= 0 {
+			return nil, promBadData("zero or negative query resolution step widths are not accepted. Try a positive integer")
+		}
+		if end.Sub(start)/step > promQLMaxPoints {
+			return nil, promBadData("exceeded maximum resolution of %d points per timeseries. Try decreasing the query resolution (?step=XX)", promQLMaxPoints)
+		}
+		return h.eval(ctx, bucket, r.Form.Get("query"), promql.EvalParams{
+			BucketID: bucket.ID.String(),
+			Start:    start,
+			End:      end,
+			Step:     step,
+		})
+	}()
+	h.respond(w, data, err)
+}
+
+// handleSeries is the HTTP handler for the /api/v1/series route.
+func (h *PromQLHandler) handleSeries(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "PromQLHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	data, err := func() (interface{}, error) {
+		bucket, err := h.decodeBucket(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		matches := r.Form["match[]"]
+		if len(matches) == 0 {
+			return nil, promBadData("no match[] parameter provided")
+		}
+		start, end, err := decodePromTimeRange(r)
+		if err != nil {
+			return nil, err
+		}
+		q, err := promql.SeriesFlux(bucket.ID.String(), matches, start, end)
+		if err != nil {
+			return nil, promBadData("%v", err)
+		}
+
+		series := make([]map[string]string, 0)
+		if err := h.query(ctx, bucket, q, func(tbl flux.Table) error {
+			series = append(series, promLabels(tbl.Key()))
+			return tbl.Do(func(flux.ColReader) error { return nil })
+		}); err != nil {
+			return nil, err
+		}
+		sort.Slice(series, func(i, j int) bool {
+			return promLabelsString(series[i]) < promLabelsString(series[j])
+		})
+		return series, nil
+	}()
+	h.respond(w, data, err)
+}
+
+// handleLabels is the HTTP handler for the /api/v1/labels route.
+func (h *PromQLHandler) handleLabels(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "PromQLHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	data, err := func() (interface{}, error) {
+		bucket, err := h.decodeBucket(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		start, end, err := decodePromTimeRange(r)
+		if err != nil {
+			return nil, err
+		}
+		q, err := promql.LabelNamesFlux(bucket.ID.String(), r.Form["match[]"], start, end)
+		if err != nil {
+			return nil, promBadData("%v", err)
+		}
+		values, err := h.queryStrings(ctx, bucket, q)
+		if err != nil {
+			return nil, err
+		}
+
+		names := make([]string, 0, len(values))
+		for _, v := range values {
+			switch v {
+			case "_measurement":
+				names = append(names, promql.MetricNameLabel)
+			case execute.DefaultStartColLabel, execute.DefaultStopColLabel, execute.DefaultTimeColLabel, execute.DefaultValueColLabel, "_field":
+			default:
+				names = append(names, v)
+			}
+		}
+		sort.Strings(names)
+		return names, nil
+	}()
+	h.respond(w, data, err)
+}
+
+// handleLabelValues is the HTTP handler for the /api/v1/label/:name/values route.
+func (h *PromQLHandler) handleLabelValues(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "PromQLHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	data, err := func() (interface{}, error) {
+		bucket, err := h.decodeBucket(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		name := httprouter.ParamsFromContext(ctx).ByName("name")
+		if name == "" || strings.HasPrefix(name, "_") && name != promql.MetricNameLabel {
+			return nil, promBadData("invalid label name: %q", name)
+		}
+		start, end, err := decodePromTimeRange(r)
+		if err != nil {
+			return nil, err
+		}
+		q, err := promql.LabelValuesFlux(bucket.ID.String(), name, r.Form["match[]"], start, end)
+		if err != nil {
+			return nil, promBadData("%v", err)
+		}
+		values, err := h.queryStrings(ctx, bucket, q)
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(values)
+		return values, nil
+	}()
+	h.respond(w, data, err)
+}
+
+// eval evaluates the PromQL expression and converts its result to the
+// result of the Prometheus API.
+func (h *PromQLHandler) eval(ctx context.Context, bucket *influxdb.Bucket, expr string, p promql.EvalParams) (interface{}, error) {
+	if expr == "" {
+		return nil, promBadData("invalid parameter \"query\": empty query")
+	}
+	q, err := promql.Flux(expr, p)
+	if err != nil {
+		return nil, promBadData("invalid parameter \"query\": %v", err)
+	}
+
+	var (
+		vector = make([]*promSample, 0)
+		matrix = make([]*promSeries, 0)
+	)
+	if err := h.query(ctx, bucket, q.Query, func(tbl flux.Table) error {
+		labels := promLabels(tbl.Key())
+		var points []promPoint
+		if err := tbl.Do(func(cr flux.ColReader) error {
+			timeIdx := execute.ColIdx(execute.DefaultTimeColLabel, cr.Cols())
+			valueIdx := execute.ColIdx(execute.DefaultValueColLabel, cr.Cols())
+			if valueIdx < 0 {
+				return nil
+			}
+			for i := 0; i < cr.Len(); i++ {
+				v, ok := promValue(execute.ValueForRow(cr, i, valueIdx))
+				if !ok {
+					continue
+				}
+				// The values of vectors are at the evaluation time, and
+				// aggregations of them do not keep the time column.
+				pt := promPoint{T: p.End, V: v}
+				if q.ResultType == promql.ValueTypeMatrix {
+					if timeIdx < 0 {
+						return nil
+					}
+					t := execute.ValueForRow(cr, i, timeIdx)
+					if t.IsNull() {
+						continue
+					}
+					pt.T = t.Time().Time().Add(q.Shift)
+				}
+				points = append(points, pt)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		if len(points) == 0 {
+			return nil
+		}
+
+		if q.ResultType == promql.ValueTypeVector {
+			vector = append(vector, &promSample{
+				Metric: labels,
+				Value:  points[len(points)-1],
+			})
+			return nil
+		}
+		matrix = append(matrix, &promSeries{
+			Metric: labels,
+			Values: points,
+		})
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if q.ResultType == promql.ValueTypeVector {
+		sort.Slice(vector, func(i, j int) bool {
+			return promLabelsString(vector[i].Metric) < promLabelsString(vector[j].Metric)
+		})
+		return &promQueryData{ResultType: q.ResultType, Result: vector}, nil
+	}
+
+	// A series is split into several tables if the types of its values differ.
+	series := make(map[string]*promSeries, len(matrix))
+	merged := make([]*promSeries, 0, len(matrix))
+	for _, s := range matrix {
+		key := promLabelsString(s.Metric)
+		if m, ok := series[key]; ok {
+			m.Values = append(m.Values, s.Values...)
+			continue
+		}
+		series[key] = s
+		merged = append(merged, s)
+	}
+	for _, s := range merged {
+		sort.SliceStable(s.Values, func(i, j int) bool { return s.Values[i].T.Before(s.Values[j].T) })
+		values := s.Values[:0]
+		for _, v := range s.Values {
+			if p.Step > 0 && (v.T.Before(p.Start) || v.T.After(p.End)) {
+				continue
+			}
+			if n := len(values); n > 0 && values[n-1].T.Equal(v.T) {
+				continue
+			}
+			values = append(values, v)
+		}
+		s.Values = values
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return promLabelsString(merged[i].Metric) < promLabelsString(merged[j].Metric)
+	})
+	return &promQueryData{ResultType: q.ResultType, Result: merged}, nil
+}
+
+// query performs the Flux query with the authorization of the request.
+func (h *PromQLHandler) query(ctx context.Context, bucket *influxdb.Bucket, q string, fn func(tbl flux.Table) error) error {
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		return err
+	}
+	auth, err := queryAuthorization(a, bucket.OrgID)
+	if err != nil {
+		return err
+	}
+	ctx = pcontext.SetAuthorizer(ctx, auth)
+
+	h.log.Debug("PromQL query transpiled to Flux", zap.String("query", q))
+	results, err := h.QueryService.Query(ctx, &query.Request{
+		Authorization:  auth,
+		OrganizationID: bucket.OrgID,
+		Compiler:       lang.FluxCompiler{Query: q},
+	})
+	if err != nil {
+		return &promError{typ: promErrorExecution, err: err}
+	}
+	defer results.Release()
+
+	for results.More() {
+		if err := results.Next().Tables().Do(fn); err != nil {
+			return &promError{typ: promErrorExecution, err: err}
+		}
+	}
+	if err := results.Err(); err != nil {
+		return &promError{typ: promErrorExecution, err: err}
+	}
+	return nil
+}
+
+// queryStrings returns the strings in the _value column of the result.
+func (h *PromQLHandler) queryStrings(ctx context.Context, bucket *influxdb.Bucket, q string) ([]string, error) {
+	values := make([]string, 0)
+	err := h.query(ctx, bucket, q, func(tbl flux.Table) error {
+		return tbl.Do(func(cr flux.ColReader) error {
+			j := execute.ColIdx(execute.DefaultValueColLabel, cr.Cols())
+			if j < 0 || cr.Cols()[j].Type != flux.TString {
+				return nil
+			}
+			vs := cr.Strings(j)
+			for i := 0; i < vs.Len(); i++ {
+				if vs.IsValid(i) {
+					values = append(values, vs.ValueString(i))
+				}
+			}
+			return nil
+		})
+	})
+	return values, err
+}
+
+// decodeBucket parses the form of the request and finds the bucket of the
+// metrics, which is given by the bucket or bucketID parameter along with
+// the org or orgID parameter. Every request is a query of the org of the
+// bucket, so it is checked against the query rate limits and its usage is
+// recorded here, as the /api/v2/query handler does.
+func (h *PromQLHandler) decodeBucket(ctx context.Context, r *http.Request) (*influxdb.Bucket, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, promBadData("%v", err)
+	}
+
+	var filter influxdb.BucketFilter
+	if org := r.Form.Get(Org); org != "" {
+		if id, err := influxdb.IDFromString(org); err == nil {
+			filter.OrganizationID = id
+		} else {
+			filter.Org = &org
+		}
+	}
+	if id := r.Form.Get(OrgID); id != "" {
+		orgID, err := influxdb.IDFromString(id)
+		if err != nil {
+			return nil, promBadData("invalid parameter \"orgID\": %v", err)
+		}
+		filter.OrganizationID = orgID
+	}
+	if filter.OrganizationID == nil && filter.Org == nil {
+		return nil, promBadData("please provide either orgID or org")
+	}
+
+	if bucket := r.Form.Get(Bucket); bucket != "" {
+		if id, err := influxdb.IDFromString(bucket); err == nil {
+			filter.ID = id
+		} else {
+			filter.Name = &bucket
+		}
+	}
+	if id := r.Form.Get(BucketID); id != "" {
+		bucketID, err := influxdb.IDFromString(id)
+		if err != nil {
+			return nil, promBadData("invalid parameter \"bucketID\": %v", err)
+		}
+		filter.ID = bucketID
+	}
+	if filter.ID == nil && filter.Name == nil {
+		return nil, promBadData("please provide either bucketID or bucket")
+	}
+
+	if filter.Org != nil {
+		o, err := h.OrganizationService.FindOrganization(ctx, influxdb.OrganizationFilter{Name: filter.Org})
+		if err != nil {
+			return nil, err
+		}
+		filter.OrganizationID, filter.Org = &o.ID, nil
+	}
+	bucket, err := h.BucketService.FindBucket(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	requestBytes := len(r.Form.Encode())
+	if h.RateLimiter != nil {
+		if err := h.RateLimiter.AllowQuery(ctx, bucket.OrgID, remoteIP(r), requestBytes); err != nil {
+			return nil, err
+		}
+	}
+	recordQueryUsage(ctx, h.UsageRecorder, bucket.OrgID, requestBytes)
+	return bucket, nil
+}
+
+func (h *PromQLHandler) respond(w http.ResponseWriter, data interface{}, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	if err == nil {
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(&promResponse{Status: "success", Data: data})
+		return
+	}
+
+	res := &promResponse{Status: "error", Error: err.Error()}
+	var code int
+	var rl *ratelimit.Error
+	if errors.As(err, &rl) {
+		setRetryAfter(w, rl)
+		code = http.StatusTooManyRequests
+		res.ErrorType = promErrorExecution
+	} else if e, ok := err.(*promError); ok {
+		res.ErrorType = e.typ
+		switch e.typ {
+		case promErrorBadData:
+			code = http.StatusBadRequest
+		case promErrorExecution:
+			code = http.StatusUnprocessableEntity
+		}
+		if influxdb.ErrorCode(e.err) == influxdb.EUnauthorized {
+			code = http.StatusUnauthorized
+		}
+	} else {
+		code = kithttp.ErrorCodeToStatusCode(influxdb.ErrorCode(err))
+		res.ErrorType = promErrorBadData
+		if code >= http.StatusInternalServerError {
+			res.ErrorType = promErrorInternal
+		}
+	}
+	if code >= http.StatusInternalServerError {
+		h.log.Error("PromQL request failed", zap.Error(err))
+	}
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+// decodePromTimeRange decodes the optional start and end parameters,
+// which default to all time.
+func decodePromTimeRange(r *http.Request) (start, end time.Time, err error) {
+	start, end = time.Unix(0, 0), time.Now()
+	if v := r.Form.Get("start"); v != "" {
+		if start, err = parsePromTime(v); err != nil {
+			return start, end, promBadData("invalid parameter \"start\": %v", err)
+		}
+	}
+	if v := r.Form.Get("end"); v != "" {
+		if end, err = parsePromTime(v); err != nil {
+			return start, end, promBadData("invalid parameter \"end\": %v", err)
+		}
+	}
+	return start, end, nil
+}
+
+// parsePromTime parses a time given in seconds since the epoch or in
+// the RFC 3339 format.
+func parsePromTime(s string) (time.Time, error) {
+	if t, err := strconv.ParseFloat(s, 64); err == nil {
+		s, ns := math.Modf(t)
+		return time.Unix(int64(s), int64(math.Round(ns*1e9))).UTC(), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q to a valid timestamp", s)
+}
+
+// parsePromDuration parses a duration given in seconds or as a duration string.
+func parsePromDuration(s string) (time.Duration, error) {
+	if d, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(d * float64(time.Second)), nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, nil
+	}
+	return 0, fmt.Errorf("cannot parse %q to a valid duration", s)
+}
+
+// promLabels returns the labels of the series of the group key.
+func promLabels(key flux.GroupKey) map[string]string {
+	labels := make(map[string]string, len(key.Cols()))
+	for j, c := range key.Cols() {
+		if c.Type != flux.TString || key.IsNull(j) {
+			continue
+		}
+		switch c.Label {
+		case "_measurement":
+			labels[promql.MetricNameLabel] = key.ValueString(j)
+		case execute.DefaultStartColLabel, execute.DefaultStopColLabel, execute.DefaultTimeColLabel, execute.DefaultValueColLabel, "_field":
+		default:
+			labels[c.Label] = key.ValueString(j)
+		}
+	}
+	return labels
+}
+
+// promLabelsString returns the labels in the Prometheus text format.
+func promLabelsString(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString("{")
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, "%s=%q", k, labels[k])
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// promValue converts a numeric value to a float.
+func promValue(v values.Value) (float64, bool) {
+	if v.IsNull() {
+		return 0, false
+	}
+	switch v.Type() {
+	case semantic.Float:
+		return v.Float(), true
+	case semantic.Int:
+		return float64(v.Int()), true
+	case semantic.UInt:
+		return float64(v.UInt()), true
+	case semantic.Bool:
+		if v.Bool() {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
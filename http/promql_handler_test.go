@@ -0,0 +1,84 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/influxdb/v2"
+	httpmock "github.com/influxdata/influxdb/v2/http/mock"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/query"
+	querymock "github.com/influxdata/influxdb/v2/query/mock"
+	"github.com/influxdata/influxdb/v2/ratelimit"
+	influxtesting "github.com/influxdata/influxdb/v2/testing"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestPromQLHandler_rateLimit(t *testing.T) {
+	const (
+		org    = "043e0780ee2b1000"
+		bucket = "04504b356e23b000"
+	)
+
+	buckets := mock.NewBucketService()
+	buckets.FindBucketFn = func(context.Context, influxdb.BucketFilter) (*influxdb.Bucket, error) {
+		return testBucket(org, bucket), nil
+	}
+	limits := mock.NewOrgLimitsService()
+	limits.FindOrgLimitsFn = func(_ context.Context, orgID influxdb.ID) (*influxdb.OrgLimits, error) {
+		return &influxdb.OrgLimits{
+			OrgID:      orgID,
+			RateLimits: influxdb.RateLimits{QueriesPerSecond: 1},
+		}, nil
+	}
+	recorder := usageRecorder{}
+
+	promQLHandler := NewPromQLHandler(zaptest.NewLogger(t), &PromQLBackend{
+		HTTPErrorHandler: DefaultErrorHandler,
+		log:              zaptest.NewLogger(t),
+		RateLimiter:      ratelimit.NewLimiter(limits, influxdb.RateLimits{}),
+		UsageRecorder:    recorder,
+		BucketService:    buckets,
+		QueryService: &querymock.QueryService{
+			QueryF: func(context.Context, *query.Request) (flux.ResultIterator, error) {
+				return flux.NewSliceResultIterator(nil), nil
+			},
+		},
+	})
+	oid := influxtesting.MustIDBase16(org)
+	handler := httpmock.NewAuthMiddlewareHandler(promQLHandler, &influxdb.Authorization{
+		OrgID:       oid,
+		Status:      influxdb.Active,
+		Permissions: influxdb.OwnerPermissions(oid),
+	})
+
+	labels := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "http://localhost:9999/api/v1/labels?orgID="+org+"&bucketID="+bucket, nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := labels(); w.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: got %d want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	w := labels()
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("unexpected status code: got %d want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if got, want := w.Header().Get("Retry-After"), "1"; got != want {
+		t.Errorf("unexpected Retry-After: got %s want %s", got, want)
+	}
+	if got, want := w.Body.String(), `{"status":"error","errorType":"execution","error":"org query rate limit of 1 queries per second exceeded"}`+"\n"; got != want {
+		t.Errorf("unexpected body: got %s want %s", got, want)
+	}
+
+	// Only the allowed query is recorded.
+	if got := recorder[influxdb.UsageQueryRequestCount]; got != 1 {
+		t.Errorf("unexpected query count: got %v want 1", got)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 	"unicode/utf8"
 
@@ -69,6 +70,8 @@ type QueryDialect struct {
 	CommentPrefix  string   `json:"commentPrefix"`
 	DateTimeFormat string   `json:"dateTimeFormat"`
 	Annotations    []string `json:"annotations"`
+	// Profile appends a table with the profile of each operator to the results.
+	Profile bool `json:"profile"`
 }
 
 // WithDefaults adds default values to the request.
@@ -112,6 +115,10 @@ func (r QueryRequest) Validate() error {
 		return fmt.Errorf("bucket parameter is required for influxql queries")
 	}
 
+	if r.Type == "influxql" && r.Dialect.Profile {
+		return fmt.Errorf("dialect profile is only supported for flux queries")
+	}
+
 	if len(r.Dialect.CommentPrefix) > 1 {
 		return fmt.Errorf("invalid dialect comment prefix: must be length 0 or 1")
 	}
@@ -331,6 +338,7 @@ func (r QueryRequest) proxyRequest(now func() time.Time) (*query.ProxyRequest, e
 		Request: query.Request{
 			OrganizationID: r.Org.ID,
 			Compiler:       compiler,
+			Profile:        r.Dialect.Profile,
 		},
 		Dialect: dialect,
 	}, nil
@@ -370,6 +378,7 @@ func QueryRequestFromProxyRequest(req *query.ProxyRequest) (*QueryRequest, error
 	default:
 		return nil, fmt.Errorf("unsupported dialect %T", d)
 	}
+	qr.Dialect.Profile = req.Request.Profile
 	return qr, nil
 }
 
@@ -438,18 +447,52 @@ func decodeProxyQueryRequest(ctx context.Context, r *http.Request, auth influxdb
 		return nil, n, err
 	}
 
-	var token *influxdb.Authorization
+	token, err := queryAuthorization(auth, req.Org.ID)
+	if err != nil {
+		return pr, n, err
+	}
+
+	pr.Request.Authorization = token
+	return pr, n, nil
+}
+
+// queryAuthorization returns the authorization with which the authorizer
+// performs queries in the organization.
+func queryAuthorization(auth influxdb.Authorizer, orgID influxdb.ID) (*influxdb.Authorization, error) {
 	switch a := auth.(type) {
 	case *influxdb.Authorization:
-		token = a
+		return a, nil
 	case *influxdb.Session:
-		token = a.EphemeralAuth(req.Org.ID)
+		return a.EphemeralAuth(orgID), nil
 	case *jsonweb.Token:
-		token = a.EphemeralAuth(req.Org.ID)
+		return a.EphemeralAuth(orgID), nil
 	default:
-		return pr, n, influxdb.ErrAuthorizerNotSupported
+		return nil, influxdb.ErrAuthorizerNotSupported
 	}
+}
 
-	pr.Request.Authorization = token
-	return pr, n, nil
+// acceptsArrow reports whether the request accepts query results in the
+// Arrow IPC stream format.
+func acceptsArrow(r *http.Request) bool {
+	for _, v := range r.Header.Values("Accept") {
+		for _, typ := range strings.Split(v, ",") {
+			if mt, _, err := mime.ParseMediaType(strings.TrimSpace(typ)); err == nil && mt == query.ArrowContentType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// noCache reports whether the request asks that the query is performed
+// even if its results are cached.
+func noCache(r *http.Request) bool {
+	for _, v := range r.Header.Values("Cache-Control") {
+		for _, directive := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(directive), "no-cache") {
+				return true
+			}
+		}
+	}
+	return false
 }
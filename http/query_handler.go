@@ -27,16 +27,19 @@ import (
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
 	"github.com/influxdata/influxdb/v2/logger"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
 	"github.com/influxdata/influxdb/v2/query"
 	"github.com/influxdata/influxdb/v2/query/influxql"
+	"github.com/influxdata/influxdb/v2/ratelimit"
 	"github.com/pkg/errors"
 	prom "github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
 
 const (
-	prefixQuery   = "/api/v2/query"
-	traceIDHeader = "Trace-Id"
+	prefixQuery        = "/api/v2/query"
+	prefixQueryExplain = "/api/v2/query/explain"
+	traceIDHeader      = "Trace-Id"
 )
 
 // FluxBackend is all services and associated parameters required to construct
@@ -45,10 +48,13 @@ type FluxBackend struct {
 	influxdb.HTTPErrorHandler
 	log                *zap.Logger
 	QueryEventRecorder metric.EventRecorder
+	RateLimiter        *ratelimit.Limiter
+	UsageRecorder      influxdb.UsageRecorder
 
 	AlgoWProxy          FeatureProxyHandler
 	OrganizationService influxdb.OrganizationService
 	ProxyQueryService   query.ProxyQueryService
+	ExplainService      query.ExplainService
 }
 
 // NewFluxBackend returns a new instance of FluxBackend.
@@ -57,12 +63,15 @@ func NewFluxBackend(log *zap.Logger, b *APIBackend) *FluxBackend {
 		HTTPErrorHandler:   b.HTTPErrorHandler,
 		log:                log,
 		QueryEventRecorder: b.QueryEventRecorder,
+		RateLimiter:        b.RateLimiter,
+		UsageRecorder:      b.UsageRecorder,
 		AlgoWProxy:         b.AlgoWProxy,
 		ProxyQueryService: routingQueryService{
 			InfluxQLService: b.InfluxQLService,
 			DefaultService:  b.FluxService,
 		},
 		OrganizationService: b.OrganizationService,
+		ExplainService:      b.ExplainService,
 	}
 }
 
@@ -80,8 +89,15 @@ type FluxHandler struct {
 	Now                 func() time.Time
 	OrganizationService influxdb.OrganizationService
 	ProxyQueryService   query.ProxyQueryService
+	ExplainService      query.ExplainService
 
 	EventRecorder metric.EventRecorder
+
+	// RateLimiter limits the rates of queries when it is set.
+	RateLimiter *ratelimit.Limiter
+
+	// UsageRecorder records the usage of queries when it is set.
+	UsageRecorder influxdb.UsageRecorder
 }
 
 // Prefix provides the route prefix.
@@ -99,7 +115,10 @@ func NewFluxHandler(log *zap.Logger, b *FluxBackend) *FluxHandler {
 
 		ProxyQueryService:   b.ProxyQueryService,
 		OrganizationService: b.OrganizationService,
+		ExplainService:      b.ExplainService,
 		EventRecorder:       b.QueryEventRecorder,
+		RateLimiter:         b.RateLimiter,
+		UsageRecorder:       b.UsageRecorder,
 	}
 
 	// query reponses can optionally be gzip encoded
@@ -107,6 +126,7 @@ func NewFluxHandler(log *zap.Logger, b *FluxBackend) *FluxHandler {
 	h.Handler("POST", prefixQuery, withFeatureProxy(b.AlgoWProxy, qh))
 	h.Handler("POST", "/api/v2/query/ast", withFeatureProxy(b.AlgoWProxy, http.HandlerFunc(h.postFluxAST)))
 	h.Handler("POST", "/api/v2/query/analyze", withFeatureProxy(b.AlgoWProxy, http.HandlerFunc(h.postQueryAnalyze)))
+	h.Handler("POST", prefixQueryExplain, withFeatureProxy(b.AlgoWProxy, http.HandlerFunc(h.postQueryExplain)))
 	h.Handler("GET", "/api/v2/query/suggestions", withFeatureProxy(b.AlgoWProxy, http.HandlerFunc(h.getFluxSuggestions)))
 	h.Handler("GET", "/api/v2/query/suggestions/:name", withFeatureProxy(b.AlgoWProxy, http.HandlerFunc(h.getFluxSuggestion)))
 	return h
@@ -163,12 +183,24 @@ func (h *FluxHandler) handleQuery(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	req.Request.Source = r.Header.Get("User-Agent")
+	req.Request.NoCache = noCache(r)
+	if _, ok := req.Dialect.(*csv.Dialect); ok && acceptsArrow(r) {
+		req.Dialect = query.NewArrowDialect()
+	}
 	orgID = req.Request.OrganizationID
 	requestBytes = n
 
 	// Transform the context into one with the request's authorization.
 	ctx = pcontext.SetAuthorizer(ctx, req.Request.Authorization)
 
+	if h.RateLimiter != nil {
+		if err := h.RateLimiter.AllowQuery(ctx, orgID, remoteIP(r), requestBytes); err != nil {
+			rateLimitedError(ctx, h, w, err)
+			return
+		}
+	}
+	recordQueryUsage(ctx, h.UsageRecorder, orgID, requestBytes)
+
 	hd, ok := req.Dialect.(HTTPDialect)
 	if !ok {
 		err := &influxdb.Error{
@@ -271,6 +303,51 @@ func (h *FluxHandler) postQueryAnalyze(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// postQueryExplain compiles and plans a query and returns its plans
+// without executing it.
+func (h *FluxHandler) postQueryExplain(w http.ResponseWriter, r *http.Request) {
+	const op = "http/postQueryExplain"
+	span, r := tracing.ExtractFromHTTPRequest(r, "FluxHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EUnauthorized,
+			Msg:  "authorization is invalid or missing in the query request",
+			Op:   op,
+			Err:  err,
+		}, w)
+		return
+	}
+
+	req, _, err := decodeProxyQueryRequest(ctx, r, a, h.OrganizationService)
+	if err != nil && err != influxdb.ErrAuthorizerNotSupported {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "failed to decode request body",
+			Op:   op,
+			Err:  err,
+		}, w)
+		return
+	}
+	req.Request.Source = r.Header.Get("User-Agent")
+
+	// Buckets are looked up with the request's authorization while planning.
+	ctx = pcontext.SetAuthorizer(ctx, req.Request.Authorization)
+
+	e, err := h.ExplainService.Explain(ctx, &req.Request)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, e); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
 // fluxParams contain flux funciton parameters as defined by the semantic graph
 type fluxParams map[string]string
 
@@ -506,6 +583,38 @@ func (s FluxQueryService) Check(ctx context.Context) check.Response {
 	return QueryHealthCheck(s.Addr, s.InsecureSkipVerify)
 }
 
+// QueryExplainService explains queries via HTTP.
+type QueryExplainService struct {
+	Client *httpc.Client
+}
+
+var _ query.ExplainService = (*QueryExplainService)(nil)
+
+// Explain compiles and plans the query of the request via HTTP without executing it.
+func (s *QueryExplainService) Explain(ctx context.Context, r *query.Request) (*query.Explanation, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	qreq, err := QueryRequestFromProxyRequest(&query.ProxyRequest{
+		Request: *r,
+		Dialect: csv.DefaultDialect(),
+	})
+	if err != nil {
+		return nil, tracing.LogError(span, err)
+	}
+
+	var e query.Explanation
+	err = s.Client.
+		PostJSON(qreq, prefixQueryExplain).
+		QueryParams([2]string{OrgID, r.OrganizationID.String()}).
+		DecodeJSON(&e).
+		Do(ctx)
+	if err != nil {
+		return nil, tracing.LogError(span, err)
+	}
+	return &e, nil
+}
+
 // GetQueryResponse runs a flux query with common parameters and returns the response from the query service.
 func GetQueryResponse(qr *QueryRequest, addr, org, token string, headers ...string) (*http.Response, error) {
 	if len(headers)%2 != 0 {
@@ -449,6 +449,80 @@ func TestFluxHandler_PostQuery_Errors(t *testing.T) {
 	})
 }
 
+func TestFluxHandler_postQueryExplain(t *testing.T) {
+	orgSVC := newInMemKVSVC(t)
+	org := influxdb.Organization{Name: "my-org"}
+	if err := orgSVC.CreateOrganization(context.Background(), &org); err != nil {
+		t.Fatal(err)
+	}
+
+	authz := &influxdb.Authorization{ID: 1, OrgID: org.ID}
+	want := &query.Explanation{
+		LogicalPlan: []*query.PlanNode{
+			{ID: "influxDBFrom0", Kind: "influxDBFrom"},
+			{ID: "range1", Kind: "range", Predecessors: []string{"influxDBFrom0"}},
+		},
+		PhysicalPlan: []*query.PlanNode{
+			{ID: "ReadRange1", Kind: "ReadRange"},
+		},
+		Rules: []*query.PlanRule{
+			{Name: "PushDownRangeRule", Phase: query.PhysicalPlanPhase, Node: "range1"},
+		},
+		StorageReads: []*query.StorageRead{
+			{Node: "ReadRange1", Request: "ReadFilterRequest", Bucket: "my-bucket", Predicate: "[none]"},
+		},
+		Text: "Physical Plan:\n  ReadRange1\n",
+	}
+	b := &FluxBackend{
+		HTTPErrorHandler:    kithttp.ErrorHandler(0),
+		log:                 zaptest.NewLogger(t),
+		QueryEventRecorder:  noopEventRecorder{},
+		OrganizationService: orgSVC,
+		ExplainService: &mock.ExplainService{
+			ExplainFn: func(ctx context.Context, req *query.Request) (*query.Explanation, error) {
+				if req.OrganizationID != org.ID {
+					return nil, fmt.Errorf("unexpected org %s", req.OrganizationID)
+				}
+				if req.Authorization == nil || req.Authorization.ID != authz.ID {
+					return nil, fmt.Errorf("unexpected authorization %v", req.Authorization)
+				}
+				c, ok := req.Compiler.(lang.FluxCompiler)
+				if !ok || c.Query != `from(bucket: "my-bucket") |> range(start: -1h)` {
+					return nil, &influxdb.Error{Code: influxdb.EInvalid, Msg: "unexpected query"}
+				}
+				return want, nil
+			},
+		},
+	}
+	h := NewFluxHandler(zaptest.NewLogger(t), b)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, r.WithContext(icontext.SetAuthorizer(r.Context(), authz)))
+	}))
+	defer server.Close()
+
+	client := QueryExplainService{
+		Client: mustNewHTTPClient(t, server.URL, ""),
+	}
+	got, err := client.Explain(context.Background(), &query.Request{
+		OrganizationID: org.ID,
+		Compiler:       lang.FluxCompiler{Query: `from(bucket: "my-bucket") |> range(start: -1h)`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(want, got) {
+		t.Fatalf("unexpected explanation -want/+got:\n%s", cmp.Diff(want, got))
+	}
+
+	_, err = client.Explain(context.Background(), &query.Request{
+		OrganizationID: org.ID,
+		Compiler:       lang.FluxCompiler{Query: "buckets()"},
+	})
+	if influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected invalid error, got %v", err)
+	}
+}
+
 func TestFluxService_Query_gzip(t *testing.T) {
 	// orgService is just to mock out orgs by returning
 	// the same org every time.
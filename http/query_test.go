@@ -256,6 +256,35 @@ func TestQueryRequest_proxyRequest(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "valid query with profile",
+			fields: fields{
+				Query: "howdy",
+				Type:  "flux",
+				Dialect: QueryDialect{
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+					Profile:        true,
+				},
+				org: &platform.Organization{},
+			},
+			now: func() time.Time { return time.Unix(1, 1) },
+			want: &query.ProxyRequest{
+				Request: query.Request{
+					Compiler: lang.FluxCompiler{
+						Now:   time.Unix(1, 1),
+						Query: `howdy`,
+					},
+					Profile: true,
+				},
+				Dialect: &csv.Dialect{
+					ResultEncoderConfig: csv.ResultEncoderConfig{
+						NoHeader:  false,
+						Delimiter: ',',
+					},
+				},
+			},
+		},
 		{
 			name: "valid AST",
 			fields: fields{
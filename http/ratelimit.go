@@ -0,0 +1,33 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/ratelimit"
+)
+
+// rateLimitedError responds to requests rejected by a rate limit with when
+// the client may retry them.
+func rateLimitedError(ctx context.Context, h influxdb.HTTPErrorHandler, w http.ResponseWriter, err error) {
+	var e *ratelimit.Error
+	if errors.As(err, &e) {
+		setRetryAfter(w, e)
+		err = &influxdb.Error{
+			Code: influxdb.ETooManyRequests,
+			Msg:  e.Error(),
+		}
+	}
+	h.HandleHTTPError(ctx, err, w)
+}
+
+// setRetryAfter sets the Retry-After header to the seconds the client has
+// to wait before retrying a request rejected by a rate limit.
+func setRetryAfter(w http.ResponseWriter, e *ratelimit.Error) {
+	retry := int(math.Ceil(e.RetryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(retry))
+}
@@ -0,0 +1,568 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// RoleBackend is all services and associated parameters required to construct
+// the RoleHandler.
+type RoleBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	RoleService      influxdb.RoleService
+	UserGroupService influxdb.UserGroupService
+}
+
+// NewRoleBackend returns a new instance of RoleBackend.
+func NewRoleBackend(log *zap.Logger, b *APIBackend) *RoleBackend {
+	return &RoleBackend{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		RoleService:      b.RoleService,
+		UserGroupService: b.UserGroupService,
+	}
+}
+
+// RoleHandler represents an HTTP API handler for roles and user groups.
+type RoleHandler struct {
+	*httprouter.Router
+	*kithttp.API
+	log *zap.Logger
+
+	RoleService      influxdb.RoleService
+	UserGroupService influxdb.UserGroupService
+}
+
+const (
+	prefixRoles      = "/api/v2/roles"
+	rolesIDPath      = "/api/v2/roles/:id"
+	prefixUserGroups = "/api/v2/usergroups"
+	userGroupsIDPath = "/api/v2/usergroups/:id"
+)
+
+// NewRoleHandler returns a new instance of RoleHandler.
+func NewRoleHandler(log *zap.Logger, b *RoleBackend) *RoleHandler {
+	h := &RoleHandler{
+		Router: NewRouter(b.HTTPErrorHandler),
+		API:    kithttp.NewAPI(kithttp.WithLog(log)),
+		log:    log,
+
+		RoleService:      b.RoleService,
+		UserGroupService: b.UserGroupService,
+	}
+
+	h.HandlerFunc("GET", prefixRoles, h.handleGetRoles)
+	h.HandlerFunc("POST", prefixRoles, h.handlePostRole)
+	h.HandlerFunc("GET", rolesIDPath, h.handleGetRole)
+	h.HandlerFunc("PATCH", rolesIDPath, h.handlePatchRole)
+	h.HandlerFunc("DELETE", rolesIDPath, h.handleDeleteRole)
+
+	h.HandlerFunc("GET", prefixUserGroups, h.handleGetUserGroups)
+	h.HandlerFunc("POST", prefixUserGroups, h.handlePostUserGroup)
+	h.HandlerFunc("GET", userGroupsIDPath, h.handleGetUserGroup)
+	h.HandlerFunc("PATCH", userGroupsIDPath, h.handlePatchUserGroup)
+	h.HandlerFunc("DELETE", userGroupsIDPath, h.handleDeleteUserGroup)
+	return h
+}
+
+type roleResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.Role
+}
+
+func newRoleResponse(r *influxdb.Role) *roleResponse {
+	return &roleResponse{
+		Links: map[string]string{
+			"self": path.Join(prefixRoles, r.ID.String()),
+			"org":  path.Join(prefixOrganizations, r.OrgID.String()),
+		},
+		Role: *r,
+	}
+}
+
+type rolesResponse struct {
+	Links map[string]string `json:"links"`
+	Roles []*roleResponse   `json:"roles"`
+}
+
+func newRolesResponse(rs []*influxdb.Role) *rolesResponse {
+	res := &rolesResponse{
+		Links: map[string]string{
+			"self": prefixRoles,
+		},
+		Roles: make([]*roleResponse, 0, len(rs)),
+	}
+	for _, r := range rs {
+		res.Roles = append(res.Roles, newRoleResponse(r))
+	}
+	return res
+}
+
+type userGroupResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.UserGroup
+}
+
+func newUserGroupResponse(g *influxdb.UserGroup) *userGroupResponse {
+	return &userGroupResponse{
+		Links: map[string]string{
+			"self": path.Join(prefixUserGroups, g.ID.String()),
+			"org":  path.Join(prefixOrganizations, g.OrgID.String()),
+		},
+		UserGroup: *g,
+	}
+}
+
+type userGroupsResponse struct {
+	Links      map[string]string    `json:"links"`
+	UserGroups []*userGroupResponse `json:"userGroups"`
+}
+
+func newUserGroupsResponse(gs []*influxdb.UserGroup) *userGroupsResponse {
+	res := &userGroupsResponse{
+		Links: map[string]string{
+			"self": prefixUserGroups,
+		},
+		UserGroups: make([]*userGroupResponse, 0, len(gs)),
+	}
+	for _, g := range gs {
+		res.UserGroups = append(res.UserGroups, newUserGroupResponse(g))
+	}
+	return res
+}
+
+// decodeOrgUserFilter decodes the orgID, name and userID query parameters
+// shared by the role and user group list routes.
+func decodeOrgUserFilter(r *http.Request) (orgID, userID *influxdb.ID, name *string, err error) {
+	q := r.URL.Query()
+	oid, err := decodeIDFromQuery(q, "orgID")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if oid.Valid() {
+		orgID = &oid
+	}
+	uid, err := decodeIDFromQuery(q, "userID")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if uid.Valid() {
+		userID = &uid
+	}
+	if n := q.Get("name"); n != "" {
+		name = &n
+	}
+	return orgID, userID, name, nil
+}
+
+// handleGetRoles is the HTTP handler for the GET /api/v2/roles route.
+func (h *RoleHandler) handleGetRoles(w http.ResponseWriter, r *http.Request) {
+	orgID, userID, name, err := decodeOrgUserFilter(r)
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	rs, _, err := h.RoleService.FindRoles(r.Context(), influxdb.RoleFilter{
+		OrgID:  orgID,
+		Name:   name,
+		UserID: userID,
+	})
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	h.API.Respond(w, http.StatusOK, newRolesResponse(rs))
+}
+
+// handlePostRole is the HTTP handler for the POST /api/v2/roles route.
+func (h *RoleHandler) handlePostRole(w http.ResponseWriter, r *http.Request) {
+	var role influxdb.Role
+	if err := h.API.DecodeJSON(r.Body, &role); err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	if err := h.RoleService.CreateRole(r.Context(), &role); err != nil {
+		h.API.Err(w, err)
+		return
+	}
+	h.log.Debug("Role created", zap.String("role", fmt.Sprint(role)))
+
+	h.API.Respond(w, http.StatusCreated, newRoleResponse(&role))
+}
+
+// handleGetRole is the HTTP handler for the GET /api/v2/roles/:id route.
+func (h *RoleHandler) handleGetRole(w http.ResponseWriter, r *http.Request) {
+	id, err := decodeIDFromCtx(r.Context(), "id")
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	role, err := h.RoleService.FindRoleByID(r.Context(), id)
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	h.API.Respond(w, http.StatusOK, newRoleResponse(role))
+}
+
+// handlePatchRole is the HTTP handler for the PATCH /api/v2/roles/:id route.
+func (h *RoleHandler) handlePatchRole(w http.ResponseWriter, r *http.Request) {
+	id, err := decodeIDFromCtx(r.Context(), "id")
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	var upd influxdb.RoleUpdate
+	if err := h.API.DecodeJSON(r.Body, &upd); err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	role, err := h.RoleService.UpdateRole(r.Context(), id, upd)
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+	h.log.Debug("Role updated", zap.String("role", fmt.Sprint(role)))
+
+	h.API.Respond(w, http.StatusOK, newRoleResponse(role))
+}
+
+// handleDeleteRole is the HTTP handler for the DELETE /api/v2/roles/:id route.
+func (h *RoleHandler) handleDeleteRole(w http.ResponseWriter, r *http.Request) {
+	id, err := decodeIDFromCtx(r.Context(), "id")
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	if err := h.RoleService.DeleteRole(r.Context(), id); err != nil {
+		h.API.Err(w, err)
+		return
+	}
+	h.log.Debug("Role deleted", zap.String("roleID", id.String()))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetUserGroups is the HTTP handler for the GET /api/v2/usergroups route.
+func (h *RoleHandler) handleGetUserGroups(w http.ResponseWriter, r *http.Request) {
+	orgID, userID, name, err := decodeOrgUserFilter(r)
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	gs, _, err := h.UserGroupService.FindUserGroups(r.Context(), influxdb.UserGroupFilter{
+		OrgID:  orgID,
+		Name:   name,
+		UserID: userID,
+	})
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	h.API.Respond(w, http.StatusOK, newUserGroupsResponse(gs))
+}
+
+// handlePostUserGroup is the HTTP handler for the POST /api/v2/usergroups route.
+func (h *RoleHandler) handlePostUserGroup(w http.ResponseWriter, r *http.Request) {
+	var g influxdb.UserGroup
+	if err := h.API.DecodeJSON(r.Body, &g); err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	if err := h.UserGroupService.CreateUserGroup(r.Context(), &g); err != nil {
+		h.API.Err(w, err)
+		return
+	}
+	h.log.Debug("User group created", zap.String("userGroup", fmt.Sprint(g)))
+
+	h.API.Respond(w, http.StatusCreated, newUserGroupResponse(&g))
+}
+
+// handleGetUserGroup is the HTTP handler for the GET /api/v2/usergroups/:id route.
+func (h *RoleHandler) handleGetUserGroup(w http.ResponseWriter, r *http.Request) {
+	id, err := decodeIDFromCtx(r.Context(), "id")
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	g, err := h.UserGroupService.FindUserGroupByID(r.Context(), id)
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	h.API.Respond(w, http.StatusOK, newUserGroupResponse(g))
+}
+
+// handlePatchUserGroup is the HTTP handler for the PATCH /api/v2/usergroups/:id route.
+func (h *RoleHandler) handlePatchUserGroup(w http.ResponseWriter, r *http.Request) {
+	id, err := decodeIDFromCtx(r.Context(), "id")
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	var upd influxdb.UserGroupUpdate
+	if err := h.API.DecodeJSON(r.Body, &upd); err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	g, err := h.UserGroupService.UpdateUserGroup(r.Context(), id, upd)
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+	h.log.Debug("User group updated", zap.String("userGroup", fmt.Sprint(g)))
+
+	h.API.Respond(w, http.StatusOK, newUserGroupResponse(g))
+}
+
+// handleDeleteUserGroup is the HTTP handler for the DELETE /api/v2/usergroups/:id route.
+func (h *RoleHandler) handleDeleteUserGroup(w http.ResponseWriter, r *http.Request) {
+	id, err := decodeIDFromCtx(r.Context(), "id")
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	if err := h.UserGroupService.DeleteUserGroup(r.Context(), id); err != nil {
+		h.API.Err(w, err)
+		return
+	}
+	h.log.Debug("User group deleted", zap.String("userGroupID", id.String()))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RoleService connects to Influx via HTTP to manage roles.
+type RoleService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.RoleService = (*RoleService)(nil)
+
+// FindRoleByID returns the role with the given ID via HTTP.
+func (s *RoleService) FindRoleByID(ctx context.Context, id influxdb.ID) (*influxdb.Role, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var res roleResponse
+	err := s.Client.
+		Get(prefixRoles, id.String()).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, tracing.LogError(span, err)
+	}
+	return &res.Role, nil
+}
+
+// FindRoles returns the roles that match the filter via HTTP.
+func (s *RoleService) FindRoles(ctx context.Context, filter influxdb.RoleFilter, opt ...influxdb.FindOptions) ([]*influxdb.Role, int, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if filter.ID != nil {
+		r, err := s.FindRoleByID(ctx, *filter.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+		return []*influxdb.Role{r}, 1, nil
+	}
+
+	var res rolesResponse
+	err := s.Client.
+		Get(prefixRoles).
+		QueryParams(orgUserFilterParams(filter.OrgID, filter.UserID, filter.Name)...).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, 0, tracing.LogError(span, err)
+	}
+
+	rs := make([]*influxdb.Role, 0, len(res.Roles))
+	for _, r := range res.Roles {
+		rs = append(rs, &r.Role)
+	}
+	return rs, len(rs), nil
+}
+
+// CreateRole creates a role via HTTP.
+func (s *RoleService) CreateRole(ctx context.Context, r *influxdb.Role) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var res roleResponse
+	err := s.Client.
+		PostJSON(r, prefixRoles).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return tracing.LogError(span, err)
+	}
+	*r = res.Role
+	return nil
+}
+
+// UpdateRole updates a role via HTTP.
+func (s *RoleService) UpdateRole(ctx context.Context, id influxdb.ID, upd influxdb.RoleUpdate) (*influxdb.Role, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var res roleResponse
+	err := s.Client.
+		PatchJSON(upd, prefixRoles, id.String()).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, tracing.LogError(span, err)
+	}
+	return &res.Role, nil
+}
+
+// DeleteRole deletes a role via HTTP.
+func (s *RoleService) DeleteRole(ctx context.Context, id influxdb.ID) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	err := s.Client.
+		Delete(prefixRoles, id.String()).
+		Do(ctx)
+	return tracing.LogError(span, err)
+}
+
+// UserGroupService connects to Influx via HTTP to manage user groups.
+type UserGroupService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.UserGroupService = (*UserGroupService)(nil)
+
+// FindUserGroupByID returns the user group with the given ID via HTTP.
+func (s *UserGroupService) FindUserGroupByID(ctx context.Context, id influxdb.ID) (*influxdb.UserGroup, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var res userGroupResponse
+	err := s.Client.
+		Get(prefixUserGroups, id.String()).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, tracing.LogError(span, err)
+	}
+	return &res.UserGroup, nil
+}
+
+// FindUserGroups returns the user groups that match the filter via HTTP.
+func (s *UserGroupService) FindUserGroups(ctx context.Context, filter influxdb.UserGroupFilter, opt ...influxdb.FindOptions) ([]*influxdb.UserGroup, int, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if filter.ID != nil {
+		g, err := s.FindUserGroupByID(ctx, *filter.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+		return []*influxdb.UserGroup{g}, 1, nil
+	}
+
+	var res userGroupsResponse
+	err := s.Client.
+		Get(prefixUserGroups).
+		QueryParams(orgUserFilterParams(filter.OrgID, filter.UserID, filter.Name)...).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, 0, tracing.LogError(span, err)
+	}
+
+	gs := make([]*influxdb.UserGroup, 0, len(res.UserGroups))
+	for _, g := range res.UserGroups {
+		gs = append(gs, &g.UserGroup)
+	}
+	return gs, len(gs), nil
+}
+
+// CreateUserGroup creates a user group via HTTP.
+func (s *UserGroupService) CreateUserGroup(ctx context.Context, g *influxdb.UserGroup) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var res userGroupResponse
+	err := s.Client.
+		PostJSON(g, prefixUserGroups).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return tracing.LogError(span, err)
+	}
+	*g = res.UserGroup
+	return nil
+}
+
+// UpdateUserGroup updates a user group via HTTP.
+func (s *UserGroupService) UpdateUserGroup(ctx context.Context, id influxdb.ID, upd influxdb.UserGroupUpdate) (*influxdb.UserGroup, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var res userGroupResponse
+	err := s.Client.
+		PatchJSON(upd, prefixUserGroups, id.String()).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, tracing.LogError(span, err)
+	}
+	return &res.UserGroup, nil
+}
+
+// DeleteUserGroup deletes a user group via HTTP.
+func (s *UserGroupService) DeleteUserGroup(ctx context.Context, id influxdb.ID) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	err := s.Client.
+		Delete(prefixUserGroups, id.String()).
+		Do(ctx)
+	return tracing.LogError(span, err)
+}
+
+func orgUserFilterParams(orgID, userID *influxdb.ID, name *string) [][2]string {
+	var params [][2]string
+	if orgID != nil {
+		params = append(params, [2]string{"orgID", orgID.String()})
+	}
+	if userID != nil {
+		params = append(params, [2]string{"userID", userID.String()})
+	}
+	if name != nil {
+		params = append(params, [2]string{"name", *name})
+	}
+	return params
+}
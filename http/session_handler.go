@@ -3,6 +3,8 @@ package http
 import (
 	"context"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/influxdata/httprouter"
 	platform "github.com/influxdata/influxdb/v2"
@@ -12,6 +14,10 @@ import (
 const (
 	prefixSignIn  = "/api/v2/signin"
 	prefixSignOut = "/api/v2/signout"
+
+	// totpHeader is the header of the TOTP code of the sign-in of users
+	// with TOTP enabled.
+	totpHeader = "X-Influxdb-Totp"
 )
 
 // SessionBackend is all services and associated parameters required to construct
@@ -23,6 +29,8 @@ type SessionBackend struct {
 	PasswordsService platform.PasswordsService
 	SessionService   platform.SessionService
 	UserService      platform.UserService
+	LockoutService   platform.LockoutService
+	TOTPService      platform.TOTPService
 }
 
 // newSessionBackend creates a new SessionBackend with associated logger.
@@ -34,6 +42,8 @@ func newSessionBackend(log *zap.Logger, b *APIBackend) *SessionBackend {
 		PasswordsService: b.PasswordsService,
 		SessionService:   b.SessionService,
 		UserService:      b.UserService,
+		LockoutService:   b.LockoutService,
+		TOTPService:      b.TOTPService,
 	}
 }
 
@@ -46,6 +56,8 @@ type SessionHandler struct {
 	PasswordsService platform.PasswordsService
 	SessionService   platform.SessionService
 	UserService      platform.UserService
+	LockoutService   platform.LockoutService
+	TOTPService      platform.TOTPService
 }
 
 // NewSessionHandler returns a new instance of SessionHandler.
@@ -58,6 +70,8 @@ func NewSessionHandler(log *zap.Logger, b *SessionBackend) *SessionHandler {
 		PasswordsService: b.PasswordsService,
 		SessionService:   b.SessionService,
 		UserService:      b.UserService,
+		LockoutService:   b.LockoutService,
+		TOTPService:      b.TOTPService,
 	}
 
 	h.HandlerFunc("POST", prefixSignIn, h.handleSignin)
@@ -83,12 +97,55 @@ func (h *SessionHandler) handleSignin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var attempts *platform.LoginAttempts
+	if h.LockoutService != nil {
+		attempts, err = h.LockoutService.FindLoginAttempts(ctx, u.ID)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		if attempts.Locked(time.Now()) {
+			lockedOutError(ctx, h, w, attempts)
+			return
+		}
+	}
+
 	if err := h.PasswordsService.ComparePassword(ctx, u.ID, req.Password); err != nil {
 		// Don't log here, it should already be handled by the service
+		h.recordLoginFailure(ctx, u.ID)
 		UnauthorizedError(ctx, h, w)
 		return
 	}
 
+	if h.TOTPService != nil {
+		enabled, err := h.TOTPService.TOTPEnabled(ctx, u.ID)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		if enabled {
+			// Clients ask for the code once the sign-in without it is
+			// rejected, so only wrong codes count as failed sign-ins.
+			code := r.Header.Get(totpHeader)
+			if code == "" {
+				h.HandleHTTPError(ctx, platform.ErrTOTPRequired, w)
+				return
+			}
+			if err := h.TOTPService.VerifyTOTP(ctx, u.ID, code); err != nil {
+				h.recordLoginFailure(ctx, u.ID)
+				h.HandleHTTPError(ctx, platform.ErrTOTPRequired, w)
+				return
+			}
+		}
+	}
+
+	// The failed sign-ins before this one no longer count towards a lockout.
+	if attempts != nil && attempts.Failures > 0 {
+		if err := h.LockoutService.ResetLoginAttempts(ctx, u.ID); err != nil {
+			h.log.Warn("Failed to reset login attempts", zap.Stringer("user_id", u.ID), zap.Error(err))
+		}
+	}
+
 	s, e := h.SessionService.CreateSession(ctx, req.Username)
 	if e != nil {
 		UnauthorizedError(ctx, h, w)
@@ -99,6 +156,24 @@ func (h *SessionHandler) handleSignin(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// recordLoginFailure counts a failed sign-in of the user towards its lockout.
+func (h *SessionHandler) recordLoginFailure(ctx context.Context, userID platform.ID) {
+	if h.LockoutService == nil {
+		return
+	}
+	if _, err := h.LockoutService.RecordLoginFailure(ctx, userID); err != nil {
+		h.log.Warn("Failed to record login failure", zap.Stringer("user_id", userID), zap.Error(err))
+	}
+}
+
+// lockedOutError responds that the user is locked out, and when it can
+// sign in again.
+func lockedOutError(ctx context.Context, h platform.HTTPErrorHandler, w http.ResponseWriter, a *platform.LoginAttempts) {
+	retry := int(time.Until(a.LockedUntil).Seconds()) + 1
+	w.Header().Set("Retry-After", strconv.Itoa(retry))
+	h.HandleHTTPError(ctx, platform.ErrLockedOut, w)
+}
+
 type signinRequest struct {
 	Username string
 	Password string
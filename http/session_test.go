@@ -2,12 +2,14 @@ package http
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	platform "github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
 	"github.com/influxdata/influxdb/v2/mock"
 	"go.uber.org/zap/zaptest"
 )
@@ -19,11 +21,14 @@ func NewMockSessionBackend(t *testing.T) *SessionBackend {
 		return &platform.User{ID: 1}, nil
 	}
 	return &SessionBackend{
-		log: zaptest.NewLogger(t),
+		log:              zaptest.NewLogger(t),
+		HTTPErrorHandler: kithttp.ErrorHandler(0),
 
 		SessionService:   mock.NewSessionService(),
 		PasswordsService: mock.NewPasswordsService(),
 		UserService:      userSVC,
+		LockoutService:   mock.NewLockoutService(),
+		TOTPService:      mock.NewTOTPService(),
 	}
 }
 
@@ -31,14 +36,54 @@ func TestSessionHandler_handleSignin(t *testing.T) {
 	type fields struct {
 		PasswordsService platform.PasswordsService
 		SessionService   platform.SessionService
+		LockoutService   *mock.LockoutService
+		TOTPService      platform.TOTPService
 	}
 	type args struct {
 		user     string
 		password string
+		totp     string
 	}
 	type wants struct {
-		cookie string
-		code   int
+		cookie     string
+		code       int
+		retryAfter bool
+		failures   int
+	}
+
+	sessionService := &mock.SessionService{
+		CreateSessionFn: func(context.Context, string) (*platform.Session, error) {
+			return &platform.Session{
+				ID:        platform.ID(0),
+				Key:       "abc123xyz",
+				CreatedAt: time.Date(2018, 9, 26, 0, 0, 0, 0, time.UTC),
+				ExpiresAt: time.Date(2030, 9, 26, 0, 0, 0, 0, time.UTC),
+				UserID:    platform.ID(1),
+			}, nil
+		},
+	}
+	passwordsService := func(password string) platform.PasswordsService {
+		return &mock.PasswordsService{
+			ComparePasswordFn: func(_ context.Context, _ platform.ID, p string) error {
+				if p != password {
+					return fmt.Errorf("wrong password")
+				}
+				return nil
+			},
+		}
+	}
+	lockedOut := mock.NewLockoutService()
+	lockedOut.FindLoginAttemptsFn = func(_ context.Context, id platform.ID) (*platform.LoginAttempts, error) {
+		return &platform.LoginAttempts{UserID: id, Failures: 5, LockedUntil: time.Now().Add(time.Minute)}, nil
+	}
+	totpEnabled := &mock.TOTPService{
+		TOTPEnabledFn: func(context.Context, platform.ID) (bool, error) { return true, nil },
+		VerifyTOTPFn: func(_ context.Context, _ platform.ID, code string) error {
+			if code != "123456" {
+				return platform.ErrTOTPRequired
+			}
+			return nil
+		},
 	}
 
 	tests := []struct {
@@ -76,6 +121,86 @@ func TestSessionHandler_handleSignin(t *testing.T) {
 				code:   http.StatusNoContent,
 			},
 		},
+		{
+			name: "wrong password counts as a failed sign-in",
+			fields: fields{
+				SessionService:   sessionService,
+				PasswordsService: passwordsService("supersecret"),
+			},
+			args: args{
+				user:     "user1",
+				password: "guess",
+			},
+			wants: wants{
+				code:     http.StatusUnauthorized,
+				failures: 1,
+			},
+		},
+		{
+			name: "locked out user cannot sign in",
+			fields: fields{
+				SessionService:   sessionService,
+				PasswordsService: passwordsService("supersecret"),
+				LockoutService:   lockedOut,
+			},
+			args: args{
+				user:     "user1",
+				password: "supersecret",
+			},
+			wants: wants{
+				code:       http.StatusTooManyRequests,
+				retryAfter: true,
+			},
+		},
+		{
+			name: "missing totp code",
+			fields: fields{
+				SessionService:   sessionService,
+				PasswordsService: passwordsService("supersecret"),
+				TOTPService:      totpEnabled,
+			},
+			args: args{
+				user:     "user1",
+				password: "supersecret",
+			},
+			wants: wants{
+				code: http.StatusUnauthorized,
+			},
+		},
+		{
+			name: "wrong totp code counts as a failed sign-in",
+			fields: fields{
+				SessionService:   sessionService,
+				PasswordsService: passwordsService("supersecret"),
+				TOTPService:      totpEnabled,
+			},
+			args: args{
+				user:     "user1",
+				password: "supersecret",
+				totp:     "000000",
+			},
+			wants: wants{
+				code:     http.StatusUnauthorized,
+				failures: 1,
+			},
+		},
+		{
+			name: "valid totp code",
+			fields: fields{
+				SessionService:   sessionService,
+				PasswordsService: passwordsService("supersecret"),
+				TOTPService:      totpEnabled,
+			},
+			args: args{
+				user:     "user1",
+				password: "supersecret",
+				totp:     "123456",
+			},
+			wants: wants{
+				cookie: "session=abc123xyz",
+				code:   http.StatusNoContent,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -83,11 +208,27 @@ func TestSessionHandler_handleSignin(t *testing.T) {
 			b := NewMockSessionBackend(t)
 			b.PasswordsService = tt.fields.PasswordsService
 			b.SessionService = tt.fields.SessionService
+			lockout := tt.fields.LockoutService
+			if lockout == nil {
+				lockout = mock.NewLockoutService()
+			}
+			var failures int
+			lockout.RecordLoginFailureFn = func(_ context.Context, id platform.ID) (*platform.LoginAttempts, error) {
+				failures++
+				return &platform.LoginAttempts{UserID: id, Failures: failures}, nil
+			}
+			b.LockoutService = lockout
+			if tt.fields.TOTPService != nil {
+				b.TOTPService = tt.fields.TOTPService
+			}
 			h := NewSessionHandler(zaptest.NewLogger(t), b)
 
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest("POST", "http://localhost:9999/api/v2/signin", nil)
 			r.SetBasicAuth(tt.args.user, tt.args.password)
+			if tt.args.totp != "" {
+				r.Header.Set(totpHeader, tt.args.totp)
+			}
 			h.ServeHTTP(w, r)
 
 			if got, want := w.Code, tt.wants.code; got != want {
@@ -99,6 +240,12 @@ func TestSessionHandler_handleSignin(t *testing.T) {
 			if got, want := cookie, tt.wants.cookie; got != want {
 				t.Errorf("expected session cookie to be set: got %q want %q", got, want)
 			}
+			if got, want := headers.Get("Retry-After") != "", tt.wants.retryAfter; got != want {
+				t.Errorf("unexpected Retry-After header %q", headers.Get("Retry-After"))
+			}
+			if got, want := failures, tt.wants.failures; got != want {
+				t.Errorf("bad failed sign-ins: got %d want %d", got, want)
+			}
 		})
 	}
 }
@@ -4,10 +4,13 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/influxdata/httprouter"
 	platform "github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
 	"go.uber.org/zap"
 )
 
@@ -120,10 +123,81 @@ func decodeGetUsageRequest(ctx context.Context, r *http.Request) (*getUsageReque
 }
 
 func roundToMonth(t time.Time) time.Time {
-	h, m, s := t.Clock()
-	d := t.Day()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+// recordWriteUsage records a write of values in bytes to the bucket with r,
+// unless r is nil.
+func recordWriteUsage(ctx context.Context, r platform.UsageRecorder, orgID, bucketID platform.ID, bytes, values int) {
+	if r == nil {
+		return
+	}
+	for m, v := range map[platform.UsageMetric]int{
+		platform.UsageWriteRequestCount: 1,
+		platform.UsageWriteRequestBytes: bytes,
+		platform.UsageValues:            values,
+	} {
+		r.RecordUsage(ctx, platform.Usage{OrganizationID: &orgID, BucketID: &bucketID, Type: m, Value: float64(v)})
+	}
+}
+
+// recordQueryUsage records a query of bytes to the organization with r,
+// unless r is nil.
+func recordQueryUsage(ctx context.Context, r platform.UsageRecorder, orgID platform.ID, bytes int) {
+	if r == nil {
+		return
+	}
+	for m, v := range map[platform.UsageMetric]int{
+		platform.UsageQueryRequestCount: 1,
+		platform.UsageQueryRequestBytes: bytes,
+	} {
+		r.RecordUsage(ctx, platform.Usage{OrganizationID: &orgID, Type: m, Value: float64(v)})
+	}
+}
+
+// UsageService connects to Influx via HTTP using tokens to get the usage of organizations.
+type UsageService struct {
+	Client *httpc.Client
+}
 
-	delta := (time.Duration(d) * 24 * time.Hour) + time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(s)*time.Second
+var _ platform.UsageService = (*UsageService)(nil)
+
+// GetUsage gets the usage of the organization of the filter via HTTP.
+func (s *UsageService) GetUsage(ctx context.Context, filter platform.UsageFilter) (map[platform.UsageMetric]*platform.Usage, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if filter.OrgID == nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "usage requires an organization",
+		}
+	}
+
+	span.LogKV("org-id", *filter.OrgID)
+
+	var params [][2]string
+	if filter.BucketID != nil {
+		params = append(params, [2]string{"bucketID", filter.BucketID.String()})
+	}
+	if filter.Range != nil {
+		params = append(params,
+			[2]string{"start", filter.Range.Start.Format(time.RFC3339Nano)},
+			[2]string{"stop", filter.Range.Stop.Format(time.RFC3339Nano)},
+		)
+	}
+
+	path := strings.Replace(organizationsIDUsagePath, ":id", filter.OrgID.String(), 1)
+
+	var usage map[platform.UsageMetric]*platform.Usage
+	err := s.Client.
+		Get(path).
+		QueryParams(params...).
+		DecodeJSON(&usage).
+		Do(ctx)
+	if err != nil {
+		return nil, tracing.LogError(span, err)
+	}
 
-	return t.Add(-1 * delta).Round(time.Minute)
+	return usage, nil
 }
@@ -0,0 +1,233 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+)
+
+// handleGetUserLockout is the HTTP handler for the GET /api/v2/users/:id/lockout route.
+func (h *UserHandler) handleGetUserLockout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req, err := decodeGetUserRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	a, err := h.LockoutService.FindLoginAttempts(ctx, req.UserID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, a); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleDeleteUserLockout is the HTTP handler for the DELETE /api/v2/users/:id/lockout route.
+// It clears the failed sign-ins of the user, which unlocks it.
+func (h *UserHandler) handleDeleteUserLockout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req, err := decodeGetUserRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.LockoutService.ResetLoginAttempts(ctx, req.UserID); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type totpResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+// handleGetUserTOTP is the HTTP handler for the GET /api/v2/users/:id/totp route.
+func (h *UserHandler) handleGetUserTOTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req, err := decodeGetUserRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	enabled, err := h.TOTPService.TOTPEnabled(ctx, req.UserID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, totpResponse{Enabled: enabled}); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleDeleteUserTOTP is the HTTP handler for the DELETE /api/v2/users/:id/totp route.
+// It disables TOTP for the user, such as when it lost its device.
+func (h *UserHandler) handleDeleteUserTOTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req, err := decodeGetUserRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.TOTPService.ResetTOTP(ctx, req.UserID); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePostMeTOTP is the HTTP handler for the POST /api/v2/me/totp route.
+// It enrolls the user of the request for TOTP, which is enabled once the
+// enrollment is verified.
+func (h *UserHandler) handlePostMeTOTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	a, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	e, err := h.TOTPService.EnrollTOTP(ctx, a.GetUserID())
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, e); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+type totpVerifyRequest struct {
+	Code string `json:"code"`
+}
+
+// handlePostMeTOTPVerify is the HTTP handler for the POST /api/v2/me/totp/verify route.
+func (h *UserHandler) handlePostMeTOTPVerify(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	a, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var req totpVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}, w)
+		return
+	}
+
+	if err := h.TOTPService.ConfirmTOTP(ctx, a.GetUserID(), req.Code); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// LockoutService connects to Influx via HTTP using tokens to manage the
+// lockout of users.
+type LockoutService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.LockoutService = (*LockoutService)(nil)
+
+// FindLoginAttempts returns the failed sign-ins of the user.
+func (s *LockoutService) FindLoginAttempts(ctx context.Context, userID influxdb.ID) (*influxdb.LoginAttempts, error) {
+	var a influxdb.LoginAttempts
+	err := s.Client.
+		Get(prefixUsers, userID.String(), "lockout").
+		DecodeJSON(&a).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// RecordLoginFailure is not implemented, the failed sign-ins are recorded by the sign-in.
+func (s *LockoutService) RecordLoginFailure(ctx context.Context, userID influxdb.ID) (*influxdb.LoginAttempts, error) {
+	panic("not implemented")
+}
+
+// ResetLoginAttempts unlocks the user.
+func (s *LockoutService) ResetLoginAttempts(ctx context.Context, userID influxdb.ID) error {
+	return s.Client.
+		Delete(prefixUsers, userID.String(), "lockout").
+		Do(ctx)
+}
+
+// TOTPService connects to Influx via HTTP using tokens to manage the TOTP
+// of users.
+type TOTPService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.TOTPService = (*TOTPService)(nil)
+
+// EnrollTOTP enrolls the user of the token of the client. userID is ignored.
+func (s *TOTPService) EnrollTOTP(ctx context.Context, userID influxdb.ID) (*influxdb.TOTPEnrollment, error) {
+	var e influxdb.TOTPEnrollment
+	err := s.Client.
+		Post(nil, meTOTPPath).
+		DecodeJSON(&e).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// ConfirmTOTP verifies the enrollment of the user of the token of the
+// client. userID is ignored.
+func (s *TOTPService) ConfirmTOTP(ctx context.Context, userID influxdb.ID, code string) error {
+	return s.Client.
+		PostJSON(totpVerifyRequest{Code: code}, meTOTPVerifyPath).
+		Do(ctx)
+}
+
+// TOTPEnabled returns true if the user signs in with TOTP.
+func (s *TOTPService) TOTPEnabled(ctx context.Context, userID influxdb.ID) (bool, error) {
+	var resp totpResponse
+	err := s.Client.
+		Get(prefixUsers, userID.String(), "totp").
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return false, err
+	}
+	return resp.Enabled, nil
+}
+
+// VerifyTOTP is not implemented, the codes are verified by the sign-in.
+func (s *TOTPService) VerifyTOTP(ctx context.Context, userID influxdb.ID, code string) error {
+	panic("not implemented")
+}
+
+// ResetTOTP disables TOTP for the user.
+func (s *TOTPService) ResetTOTP(ctx context.Context, userID influxdb.ID) error {
+	return s.Client.
+		Delete(prefixUsers, userID.String(), "totp").
+		Do(ctx)
+}
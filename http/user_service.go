@@ -21,6 +21,8 @@ type UserBackend struct {
 	UserService             influxdb.UserService
 	UserOperationLogService influxdb.UserOperationLogService
 	PasswordsService        influxdb.PasswordsService
+	LockoutService          influxdb.LockoutService
+	TOTPService             influxdb.TOTPService
 }
 
 // NewUserBackend creates a UserBackend using information in the APIBackend.
@@ -31,6 +33,8 @@ func NewUserBackend(log *zap.Logger, b *APIBackend) *UserBackend {
 		UserService:             b.UserService,
 		UserOperationLogService: b.UserOperationLogService,
 		PasswordsService:        b.PasswordsService,
+		LockoutService:          b.LockoutService,
+		TOTPService:             b.TOTPService,
 	}
 }
 
@@ -42,6 +46,8 @@ type UserHandler struct {
 	UserService             influxdb.UserService
 	UserOperationLogService influxdb.UserOperationLogService
 	PasswordsService        influxdb.PasswordsService
+	LockoutService          influxdb.LockoutService
+	TOTPService             influxdb.TOTPService
 }
 
 const (
@@ -51,6 +57,10 @@ const (
 	usersIDPath       = "/api/v2/users/:id"
 	usersPasswordPath = "/api/v2/users/:id/password"
 	usersLogPath      = "/api/v2/users/:id/logs"
+	usersLockoutPath  = "/api/v2/users/:id/lockout"
+	usersTOTPPath     = "/api/v2/users/:id/totp"
+	meTOTPPath        = "/api/v2/me/totp"
+	meTOTPVerifyPath  = "/api/v2/me/totp/verify"
 )
 
 // NewUserHandler returns a new instance of UserHandler.
@@ -63,6 +73,8 @@ func NewUserHandler(log *zap.Logger, b *UserBackend) *UserHandler {
 		UserService:             b.UserService,
 		UserOperationLogService: b.UserOperationLogService,
 		PasswordsService:        b.PasswordsService,
+		LockoutService:          b.LockoutService,
+		TOTPService:             b.TOTPService,
 	}
 
 	h.HandlerFunc("POST", prefixUsers, h.handlePostUser)
@@ -77,8 +89,15 @@ func NewUserHandler(log *zap.Logger, b *UserBackend) *UserHandler {
 	h.HandlerFunc("POST", usersPasswordPath, h.handlePostUserPassword)
 	h.HandlerFunc("PUT", usersPasswordPath, h.handlePutUserPassword)
 
+	h.HandlerFunc("GET", usersLockoutPath, h.handleGetUserLockout)
+	h.HandlerFunc("DELETE", usersLockoutPath, h.handleDeleteUserLockout)
+	h.HandlerFunc("GET", usersTOTPPath, h.handleGetUserTOTP)
+	h.HandlerFunc("DELETE", usersTOTPPath, h.handleDeleteUserTOTP)
+
 	h.HandlerFunc("GET", prefixMe, h.handleGetMe)
 	h.HandlerFunc("PUT", mePasswordPath, h.handlePutUserPassword)
+	h.HandlerFunc("POST", meTOTPPath, h.handlePostMeTOTP)
+	h.HandlerFunc("POST", meTOTPVerifyPath, h.handlePostMeTOTPVerify)
 
 	return h
 }
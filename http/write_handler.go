@@ -11,13 +11,17 @@ import (
 
 	"github.com/influxdata/httprouter"
 	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
 	pcontext "github.com/influxdata/influxdb/v2/context"
 	"github.com/influxdata/influxdb/v2/http/metric"
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
 	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/ratelimit"
 	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
 	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/usage"
 	"go.uber.org/zap"
 )
 
@@ -34,6 +38,8 @@ type WriteBackend struct {
 	influxdb.HTTPErrorHandler
 	log                *zap.Logger
 	WriteEventRecorder metric.EventRecorder
+	RateLimiter        *ratelimit.Limiter
+	UsageRecorder      influxdb.UsageRecorder
 
 	PointsWriter        storage.PointsWriter
 	BucketService       influxdb.BucketService
@@ -46,6 +52,8 @@ func NewWriteBackend(log *zap.Logger, b *APIBackend) *WriteBackend {
 		HTTPErrorHandler:   b.HTTPErrorHandler,
 		log:                log,
 		WriteEventRecorder: b.WriteEventRecorder,
+		RateLimiter:        b.RateLimiter,
+		UsageRecorder:      b.UsageRecorder,
 
 		PointsWriter:        b.PointsWriter,
 		BucketService:       b.BucketService,
@@ -66,6 +74,12 @@ type WriteHandler struct {
 
 	EventRecorder metric.EventRecorder
 
+	// RateLimiter limits the rates of writes when it is set.
+	RateLimiter *ratelimit.Limiter
+
+	// UsageRecorder records the usage of writes when it is set.
+	UsageRecorder influxdb.UsageRecorder
+
 	maxBatchSizeBytes int64
 	parserOptions     []models.ParserOption
 	parserMaxBytes    int
@@ -130,6 +144,8 @@ func NewWriteHandler(log *zap.Logger, b *WriteBackend, opts ...WriteHandlerOptio
 		BucketService:       b.BucketService,
 		OrganizationService: b.OrganizationService,
 		EventRecorder:       b.WriteEventRecorder,
+		RateLimiter:         b.RateLimiter,
+		UsageRecorder:       b.UsageRecorder,
 	}
 
 	for _, opt := range opts {
@@ -244,9 +260,19 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A permission with a predicate allows writing the points of the
+	// series that match it, which are checked once parsed.
+	var pred *datatypes.Predicate
 	if !a.Allowed(*p) {
-		handleError(err, influxdb.EForbidden, "insufficient permissions for write")
-		return
+		pred, err = authorizer.BucketPredicate(a, influxdb.WriteAction, org.ID, bucket.ID)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		if pred == nil {
+			handleError(err, influxdb.EForbidden, "insufficient permissions for write")
+			return
+		}
 	}
 
 	data, err := readWriteRequest(ctx, r.Body, r.Header.Get("Content-Encoding"), h.maxBatchSizeBytes)
@@ -301,11 +327,30 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := authorizer.AuthorizeWritePoints(pred, points); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if h.RateLimiter != nil {
+		if err := h.RateLimiter.AllowWrite(ctx, org.ID, remoteIP(r), requestBytes, len(points)); err != nil {
+			rateLimitedError(ctx, h, w, err)
+			return
+		}
+	}
+
+	// The write quotas are checked against the size of the request.
+	ctx = usage.NewContextWithWriteBytes(ctx, requestBytes)
 	if err := h.PointsWriter.WritePoints(ctx, points); err != nil {
+		if influxdb.ErrorCode(err) == influxdb.EForbidden {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
 		log.Error("Error writing points", zap.Error(err))
 		handleError(err, influxdb.EInternal, "unexpected error writing points to database")
 		return
 	}
+	recordWriteUsage(ctx, h.UsageRecorder, org.ID, bucket.ID, requestBytes, len(points))
 
 	w.WriteHeader(http.StatusNoContent)
 }
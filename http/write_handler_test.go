@@ -3,11 +3,13 @@ package http
 import (
 	"compress/gzip"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -16,7 +18,9 @@ import (
 	httpmock "github.com/influxdata/influxdb/v2/http/mock"
 	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
 	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/ratelimit"
 	influxtesting "github.com/influxdata/influxdb/v2/testing"
+	"github.com/influxdata/influxdb/v2/usage"
 	"go.uber.org/zap/zaptest"
 )
 
@@ -241,6 +245,39 @@ func TestWriteHandler_handleWrite(t *testing.T) {
 				body: `{"code":"forbidden","message":"insufficient permissions for write"}`,
 			},
 		},
+		{
+			name: "points matching the predicate of the permission are accepted",
+			request: request{
+				org:    "043e0780ee2b1000",
+				bucket: "04504b356e23b000",
+				body:   "m1,t1=v1 f1=1,f2=2\nm1,t1=v1,t2=v2 f1=1",
+				auth:   bucketWritePredicatePermission("043e0780ee2b1000", "04504b356e23b000", `_measurement="m1" AND t1="v1"`),
+			},
+			state: state{
+				org:    testOrg("043e0780ee2b1000"),
+				bucket: testBucket("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			wants: wants{
+				code: 204,
+			},
+		},
+		{
+			name: "forbidden to write points outside of the predicate of the permission",
+			request: request{
+				org:    "043e0780ee2b1000",
+				bucket: "04504b356e23b000",
+				body:   "m1,t1=v1 f1=1\nm1,t1=v2 f1=1",
+				auth:   bucketWritePredicatePermission("043e0780ee2b1000", "04504b356e23b000", `_measurement="m1" AND t1="v1"`),
+			},
+			state: state{
+				org:    testOrg("043e0780ee2b1000"),
+				bucket: testBucket("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			wants: wants{
+				code: 403,
+				body: `{"code":"forbidden","message":"insufficient permissions to write field \"f1\" of measurement \"m1\""}`,
+			},
+		},
 		{
 			// authorization extraction happens in a different middleware.
 			name: "no authorizer is an internal error",
@@ -376,6 +413,191 @@ func TestWriteHandler_handleWrite(t *testing.T) {
 	}
 }
 
+func TestWriteHandler_rateLimit(t *testing.T) {
+	const (
+		org    = "043e0780ee2b1000"
+		bucket = "04504b356e23b000"
+	)
+
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+		return testOrg(org), nil
+	}
+	buckets := mock.NewBucketService()
+	buckets.FindBucketFn = func(context.Context, influxdb.BucketFilter) (*influxdb.Bucket, error) {
+		return testBucket(org, bucket), nil
+	}
+	limits := mock.NewOrgLimitsService()
+	limits.FindOrgLimitsFn = func(_ context.Context, orgID influxdb.ID) (*influxdb.OrgLimits, error) {
+		return &influxdb.OrgLimits{
+			OrgID:      orgID,
+			RateLimits: influxdb.RateLimits{WritePointsPerSecond: 1},
+		}, nil
+	}
+
+	b := &APIBackend{
+		HTTPErrorHandler:    DefaultErrorHandler,
+		Logger:              zaptest.NewLogger(t),
+		OrganizationService: orgs,
+		BucketService:       buckets,
+		PointsWriter:        &mock.PointsWriter{},
+		WriteEventRecorder:  &metric.NopEventRecorder{},
+		RateLimiter:         ratelimit.NewLimiter(limits, influxdb.RateLimits{}),
+	}
+	writeHandler := NewWriteHandler(zaptest.NewLogger(t), NewWriteBackend(zaptest.NewLogger(t), b))
+	handler := httpmock.NewAuthMiddlewareHandler(writeHandler, bucketWritePermission(org, bucket))
+
+	write := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "http://localhost:9999/api/v2/write?org="+org+"&bucket="+bucket, strings.NewReader("m1,t1=v1 f1=1\nm1,t1=v2 f1=1"))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := write(); w.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status code: got %d want %d", w.Code, http.StatusNoContent)
+	}
+
+	w := write()
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("unexpected status code: got %d want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if got, want := w.Header().Get("Retry-After"), "2"; got != want {
+		t.Errorf("unexpected Retry-After: got %s want %s", got, want)
+	}
+	if got, want := w.Body.String(), `{"code":"too many requests","message":"org write rate limit of 1 points per second exceeded"}`; got != want {
+		t.Errorf("unexpected body: got %s want %s", got, want)
+	}
+}
+
+// usageRecorder sums the recorded usage by metric.
+type usageRecorder map[influxdb.UsageMetric]float64
+
+func (r usageRecorder) RecordUsage(_ context.Context, u influxdb.Usage) {
+	r[u.Type] += u.Value
+}
+
+func TestWriteHandler_quota(t *testing.T) {
+	const (
+		org    = "043e0780ee2b1000"
+		bucket = "04504b356e23b000"
+	)
+
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+		return testOrg(org), nil
+	}
+	buckets := mock.NewBucketService()
+	buckets.FindBucketFn = func(context.Context, influxdb.BucketFilter) (*influxdb.Bucket, error) {
+		return testBucket(org, bucket), nil
+	}
+	buckets.FindBucketByIDFn = func(context.Context, influxdb.ID) (*influxdb.Bucket, error) {
+		return testBucket(org, bucket), nil
+	}
+	limits := mock.NewOrgLimitsService()
+	limits.FindOrgLimitsFn = func(_ context.Context, orgID influxdb.ID) (*influxdb.OrgLimits, error) {
+		return &influxdb.OrgLimits{OrgID: orgID, MonthlyWriteBytesQuota: 30}, nil
+	}
+	recorder := usageRecorder{}
+	pw := &mock.PointsWriter{}
+
+	b := &APIBackend{
+		HTTPErrorHandler:    DefaultErrorHandler,
+		Logger:              zaptest.NewLogger(t),
+		OrganizationService: orgs,
+		BucketService:       buckets,
+		PointsWriter:        usage.NewPointsWriter(buckets, usage.NewQuotas(limits, mock.NewUsageService(), nil), pw),
+		WriteEventRecorder:  &metric.NopEventRecorder{},
+		UsageRecorder:       recorder,
+	}
+	writeHandler := NewWriteHandler(zaptest.NewLogger(t), NewWriteBackend(zaptest.NewLogger(t), b))
+	handler := httpmock.NewAuthMiddlewareHandler(writeHandler, bucketWritePermission(org, bucket))
+
+	write := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "http://localhost:9999/api/v2/write?org="+org+"&bucket="+bucket, strings.NewReader("m1,t1=v1 f1=1\nm1,t1=v2 f1=1"))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	// A failed write does not count towards the quota.
+	pw.ForceError(errors.New("disk full"))
+	if w := write(); w.Code != http.StatusInternalServerError {
+		t.Fatalf("unexpected status code: got %d want %d", w.Code, http.StatusInternalServerError)
+	}
+	pw.ForceError(nil)
+
+	if w := write(); w.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status code: got %d want %d", w.Code, http.StatusNoContent)
+	}
+
+	w := write()
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("unexpected status code: got %d want %d", w.Code, http.StatusForbidden)
+	}
+	if got, want := w.Body.String(), `{"code":"forbidden","message":"monthly write quota of 30 bytes of the organization exceeded"}`; got != want {
+		t.Errorf("unexpected body: got %s want %s", got, want)
+	}
+
+	// Only the allowed write is recorded.
+	want := usageRecorder{
+		influxdb.UsageWriteRequestCount: 1,
+		influxdb.UsageWriteRequestBytes: 27,
+		influxdb.UsageValues:            2,
+	}
+	if !reflect.DeepEqual(recorder, want) {
+		t.Errorf("unexpected usage: got %v want %v", recorder, want)
+	}
+}
+
+func TestWriteHandler_usageBucket(t *testing.T) {
+	const (
+		org    = "043e0780ee2b1000"
+		bucket = "04504b356e23b000"
+	)
+
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+		return testOrg(org), nil
+	}
+	usageBucket := testBucket(org, bucket)
+	usageBucket.Name, usageBucket.Type = influxdb.UsageSystemBucketName, influxdb.BucketTypeSystem
+	buckets := mock.NewBucketService()
+	buckets.FindBucketFn = func(context.Context, influxdb.BucketFilter) (*influxdb.Bucket, error) {
+		return usageBucket, nil
+	}
+	buckets.FindBucketByIDFn = func(context.Context, influxdb.ID) (*influxdb.Bucket, error) {
+		return usageBucket, nil
+	}
+	pw := &mock.PointsWriter{}
+
+	b := &APIBackend{
+		HTTPErrorHandler:    DefaultErrorHandler,
+		Logger:              zaptest.NewLogger(t),
+		OrganizationService: orgs,
+		BucketService:       buckets,
+		PointsWriter:        usage.NewPointsWriter(buckets, usage.NewQuotas(mock.NewOrgLimitsService(), mock.NewUsageService(), nil), pw),
+		WriteEventRecorder:  &metric.NopEventRecorder{},
+	}
+	writeHandler := NewWriteHandler(zaptest.NewLogger(t), NewWriteBackend(zaptest.NewLogger(t), b))
+	handler := httpmock.NewAuthMiddlewareHandler(writeHandler, bucketWritePermission(org, bucket))
+
+	r := httptest.NewRequest("POST", "http://localhost:9999/api/v2/write?org="+org+"&bucket="+bucket, strings.NewReader(
+		"usage,org_id=043e0780ee2b1000 write_request_bytes=-1000000"))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("unexpected status code: got %d want %d", w.Code, http.StatusForbidden)
+	}
+	if got, want := w.Body.String(), `{"code":"forbidden","message":"the _usage system bucket is only written by the server"}`; got != want {
+		t.Errorf("unexpected body: got %s want %s", got, want)
+	}
+	if len(pw.Points) != 0 {
+		t.Fatalf("expected no points to be written, got %v", pw.Points)
+	}
+}
+
 var DefaultErrorHandler = kithttp.ErrorHandler(0)
 
 func bucketWritePermission(org, bucket string) *influxdb.Authorization {
@@ -397,6 +619,12 @@ func bucketWritePermission(org, bucket string) *influxdb.Authorization {
 	}
 }
 
+func bucketWritePredicatePermission(org, bucket, predicate string) *influxdb.Authorization {
+	a := bucketWritePermission(org, bucket)
+	a.Permissions[0].Predicate = predicate
+	return a
+}
+
 func testOrg(org string) *influxdb.Organization {
 	oid := influxtesting.MustIDBase16(org)
 	return &influxdb.Organization{
@@ -109,6 +109,11 @@ func (t *Token) Allowed(p influxdb.Permission) bool {
 	return false
 }
 
+// PermissionSet returns the set of permissions within the Token
+func (t *Token) PermissionSet() []influxdb.Permission {
+	return t.Permissions
+}
+
 // Identifier returns the identifier for this Token
 // as found in the standard claims
 func (t *Token) Identifier() influxdb.ID {
@@ -44,14 +44,46 @@ func NewAuthPackage() BoolFlag {
 	return newAuth
 }
 
+var pushDownWindowAggregate = MakeBoolFlag(
+	"Push Down Window Aggregate",
+	"pushDownWindowAggregate",
+	"Query Team",
+	false,
+	Temporary,
+	false,
+)
+
+// PushDownWindowAggregate - Enables pushing down window aggregates to storage
+func PushDownWindowAggregate() BoolFlag {
+	return pushDownWindowAggregate
+}
+
+var pushDownGroupAggregate = MakeBoolFlag(
+	"Push Down Group Aggregate",
+	"pushDownGroupAggregate",
+	"Query Team",
+	false,
+	Temporary,
+	false,
+)
+
+// PushDownGroupAggregate - Enables pushing down bare and grouped aggregates to storage
+func PushDownGroupAggregate() BoolFlag {
+	return pushDownGroupAggregate
+}
+
 var all = []Flag{
 	backendExample,
 	frontendExample,
 	newAuth,
+	pushDownWindowAggregate,
+	pushDownGroupAggregate,
 }
 
 var byKey = map[string]Flag{
-	"backendExample":  backendExample,
-	"frontendExample": frontendExample,
-	"newAuth":         newAuth,
+	"backendExample":          backendExample,
+	"frontendExample":         frontendExample,
+	"newAuth":                 newAuth,
+	"pushDownWindowAggregate": pushDownWindowAggregate,
+	"pushDownGroupAggregate":  pushDownGroupAggregate,
 }
@@ -26,6 +26,14 @@ func (w *StatusResponseWriter) WriteHeader(statusCode int) {
 	w.ResponseWriter.WriteHeader(statusCode)
 }
 
+// Flush sends any buffered data to the client, so that streamed responses
+// are not held back by the wrapped writer.
+func (w *StatusResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 func (w *StatusResponseWriter) Code() int {
 	code := w.statusCode
 	if code == 0 {
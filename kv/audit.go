@@ -0,0 +1,391 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	auditLogBucket = []byte("auditlogv1")
+	// auditLogIDIndex maps the ID of an event to its key, and
+	// auditLogOrgIndex the org of an event followed by its key to the key,
+	// so that the events of an org are found in time order without
+	// scanning the log.
+	auditLogIDIndex  = []byte("auditlogidv1")
+	auditLogOrgIndex = []byte("auditlogbyorgv1")
+)
+
+// auditLogTrimBatch is the number of events deleted in a transaction when
+// the audit log is trimmed.
+const auditLogTrimBatch = 1000
+
+var _ influxdb.AuditService = (*Service)(nil)
+
+func (s *Service) initializeAuditLog(ctx context.Context, store Store) error {
+	return store.Update(ctx, func(tx Tx) error {
+		_, err := tx.Bucket(auditLogBucket)
+		return err
+	})
+}
+
+// indexAuditLog creates the indexes of the audit log and indexes the events
+// recorded before they existed.
+func (s *Service) indexAuditLog(ctx context.Context, store Store) error {
+	return store.Update(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(auditLogBucket)
+		if err != nil {
+			return err
+		}
+		cur, err := b.ForwardCursor(nil)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+			e := &influxdb.AuditEvent{}
+			if err := json.Unmarshal(v, e); err != nil {
+				return err
+			}
+			if err := putAuditEventIndexes(tx, e, k); err != nil {
+				return err
+			}
+		}
+		return cur.Err()
+	})
+}
+
+// encodeAuditEventKey returns the key of an event, which orders the events
+// by time.
+func encodeAuditEventKey(e *influxdb.AuditEvent) ([]byte, error) {
+	id, err := e.ID.Encode()
+	if err != nil {
+		return nil, err
+	}
+	return append(encodeAuditTime(e.Time), id...), nil
+}
+
+func encodeAuditTime(t time.Time) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, uint64(t.UnixNano()))
+	return k
+}
+
+// auditOrgPrefix returns the prefix of the keys of the events of the org in
+// the org index.
+func auditOrgPrefix(orgID influxdb.ID) ([]byte, error) {
+	return orgID.Encode()
+}
+
+func putAuditEventIndexes(tx Tx, e *influxdb.AuditEvent, k []byte) error {
+	id, err := e.ID.Encode()
+	if err != nil {
+		return err
+	}
+	idx, err := tx.Bucket(auditLogIDIndex)
+	if err != nil {
+		return err
+	}
+	if err := idx.Put(id, k); err != nil {
+		return err
+	}
+
+	if !e.OrgID.Valid() {
+		return nil
+	}
+	prefix, err := auditOrgPrefix(e.OrgID)
+	if err != nil {
+		return err
+	}
+	idx, err = tx.Bucket(auditLogOrgIndex)
+	if err != nil {
+		return err
+	}
+	return idx.Put(append(prefix, k...), k)
+}
+
+func deleteAuditEventIndexes(tx Tx, e *influxdb.AuditEvent, k []byte) error {
+	id, err := e.ID.Encode()
+	if err != nil {
+		return err
+	}
+	idx, err := tx.Bucket(auditLogIDIndex)
+	if err != nil {
+		return err
+	}
+	if err := idx.Delete(id); err != nil {
+		return err
+	}
+
+	if !e.OrgID.Valid() {
+		return nil
+	}
+	prefix, err := auditOrgPrefix(e.OrgID)
+	if err != nil {
+		return err
+	}
+	idx, err = tx.Bucket(auditLogOrgIndex)
+	if err != nil {
+		return err
+	}
+	return idx.Delete(append(prefix, k...))
+}
+
+// RecordAuditEvent records an event in the audit log.
+func (s *Service) RecordAuditEvent(ctx context.Context, e *influxdb.AuditEvent) error {
+	if err := e.Action.Valid(); err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpRecordAuditEvent,
+			Err: err,
+		}
+	}
+
+	e.ID = s.IDGenerator.ID()
+	if e.Time.IsZero() {
+		e.Time = s.TimeGenerator.Now()
+	}
+	e.Time = e.Time.UTC()
+
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		k, err := encodeAuditEventKey(e)
+		if err != nil {
+			return err
+		}
+		v, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		b, err := tx.Bucket(auditLogBucket)
+		if err != nil {
+			return err
+		}
+		if err := b.Put(k, v); err != nil {
+			return err
+		}
+		return putAuditEventIndexes(tx, e, k)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpRecordAuditEvent,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// FindAuditEvents returns the events of the audit log that match the filter.
+// The events of an org are read from the org index, and the log is read in
+// the order of the options from the time bounds of the filter, or from the
+// event of filter.After, until the page is full.
+func (s *Service) FindAuditEvents(ctx context.Context, filter influxdb.AuditFilter, opt ...influxdb.FindOptions) ([]*influxdb.AuditEvent, int, error) {
+	var opts influxdb.FindOptions
+	if len(opt) > 0 {
+		opts = opt[0]
+	}
+
+	es := []*influxdb.AuditEvent{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		log, err := tx.Bucket(auditLogBucket)
+		if err != nil {
+			return err
+		}
+
+		// The events are either read from the log itself, or through the
+		// org index whose keys are the prefix followed by the key of the
+		// event in the log.
+		b, prefix := log, []byte(nil)
+		if filter.OrgID != nil {
+			if prefix, err = auditOrgPrefix(*filter.OrgID); err != nil {
+				return err
+			}
+			if b, err = tx.Bucket(auditLogOrgIndex); err != nil {
+				return err
+			}
+		}
+		key := func(k []byte) []byte {
+			return append(append([]byte{}, prefix...), k...)
+		}
+
+		var after []byte
+		if filter.After != nil {
+			id, err := filter.After.Encode()
+			if err != nil {
+				return err
+			}
+			idx, err := tx.Bucket(auditLogIDIndex)
+			if err != nil {
+				return err
+			}
+			k, err := idx.Get(id)
+			if IsNotFound(err) {
+				return &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  "audit event " + filter.After.String() + " to continue after not found",
+				}
+			}
+			if err != nil {
+				return err
+			}
+			after = key(k)
+		}
+
+		// The keys of the events within the time bounds are from the
+		// encoded start, inclusive, to the encoded stop, exclusive, as
+		// the keys of the events at a time follow the encoded time.
+		lower, upper := key(nil), []byte(nil)
+		if !filter.Start.IsZero() {
+			lower = key(encodeAuditTime(filter.Start))
+		}
+		if !filter.Stop.IsZero() {
+			upper = key(encodeAuditTime(filter.Stop))
+		}
+
+		var seek []byte
+		var cursorOpts []CursorOption
+		if opts.Descending {
+			if after != nil && (upper == nil || bytes.Compare(after, upper) < 0) {
+				upper = after
+			}
+			if upper == nil && prefix != nil {
+				// past the keys of the events of the org
+				upper = key(bytes.Repeat([]byte{0xff}, 8))
+			}
+			// A descending cursor starts at the last key before the seek
+			// in some stores and the first key after it in others, so
+			// the seek is never past the last key and the keys from the
+			// upper bound on are skipped.
+			c, err := b.Cursor()
+			if err != nil {
+				return err
+			}
+			last, _ := c.Last()
+			if last == nil {
+				return nil
+			}
+			seek = last
+			if upper != nil && bytes.Compare(upper, last) < 0 {
+				seek = upper
+			}
+			cursorOpts = append(cursorOpts, WithCursorDirection(CursorDescending))
+		} else {
+			seek = lower
+			if after != nil && bytes.Compare(after, lower) > 0 {
+				seek = after
+			}
+		}
+
+		cur, err := b.ForwardCursor(seek, cursorOpts...)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		skip := opts.Offset
+		for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+			if after != nil && bytes.Equal(k, after) ||
+				opts.Descending && upper != nil && bytes.Compare(k, upper) >= 0 {
+				continue
+			}
+			if !bytes.HasPrefix(k, prefix) ||
+				opts.Descending && bytes.Compare(k, lower) < 0 ||
+				!opts.Descending && upper != nil && bytes.Compare(k, upper) >= 0 {
+				break
+			}
+			if prefix != nil {
+				if v, err = log.Get(v); err != nil {
+					return err
+				}
+			}
+
+			e := &influxdb.AuditEvent{}
+			if err := json.Unmarshal(v, e); err != nil {
+				return err
+			}
+			if !filter.Matches(e) {
+				continue
+			}
+			if skip > 0 {
+				skip--
+				continue
+			}
+			es = append(es, e)
+			if opts.Limit > 0 && len(es) >= opts.Limit {
+				break
+			}
+		}
+		return cur.Err()
+	})
+	if err != nil {
+		return nil, 0, &influxdb.Error{
+			Op:  influxdb.OpFindAuditEvents,
+			Err: err,
+		}
+	}
+	return es, len(es), nil
+}
+
+// DeleteAuditEventsBefore deletes the events recorded before a time from the
+// audit log, in batches, and returns the number of deleted events.
+func (s *Service) DeleteAuditEventsBefore(ctx context.Context, before time.Time) (int, error) {
+	var deleted int
+	for {
+		var n int
+		err := s.kv.Update(ctx, func(tx Tx) error {
+			b, err := tx.Bucket(auditLogBucket)
+			if err != nil {
+				return err
+			}
+			cur, err := b.ForwardCursor(nil)
+			if err != nil {
+				return err
+			}
+
+			stop := encodeAuditTime(before)
+			var ks [][]byte
+			var es []*influxdb.AuditEvent
+			for k, v := cur.Next(); k != nil && len(ks) < auditLogTrimBatch; k, v = cur.Next() {
+				if bytes.Compare(k, stop) >= 0 {
+					break
+				}
+				e := &influxdb.AuditEvent{}
+				if err := json.Unmarshal(v, e); err != nil {
+					cur.Close()
+					return err
+				}
+				ks = append(ks, append([]byte{}, k...))
+				es = append(es, e)
+			}
+			if err := cur.Err(); err != nil {
+				cur.Close()
+				return err
+			}
+			cur.Close()
+
+			for i, k := range ks {
+				if err := b.Delete(k); err != nil {
+					return err
+				}
+				if err := deleteAuditEventIndexes(tx, es[i], k); err != nil {
+					return err
+				}
+			}
+			n = len(ks)
+			return nil
+		})
+		if err != nil {
+			return deleted, &influxdb.Error{
+				Op:  influxdb.OpDeleteAuditEvents,
+				Err: err,
+			}
+		}
+		deleted += n
+		if n < auditLogTrimBatch {
+			return deleted, nil
+		}
+	}
+}
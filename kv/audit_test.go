@@ -0,0 +1,186 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestBoltAuditService(t *testing.T) {
+	s, closeBolt, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeBolt()
+	testAuditService(t, s)
+}
+
+func TestInmemAuditService(t *testing.T) {
+	testAuditService(t, inmem.NewKVStore())
+}
+
+func testAuditService(t *testing.T, s kv.Store) {
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing audit service: %v", err)
+	}
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	orgID, userID := influxdb.ID(1), influxdb.ID(2)
+	events := []*influxdb.AuditEvent{
+		{Time: start, Action: influxdb.AuditCreate, OrgID: orgID, UserID: userID, ResourceType: influxdb.BucketsResourceType, ResourceID: 10},
+		{Time: start.Add(time.Minute), Action: influxdb.AuditUpdate, OrgID: orgID, UserID: userID, ResourceType: influxdb.BucketsResourceType, ResourceID: 10},
+		{Time: start.Add(2 * time.Minute), Action: influxdb.AuditLogin, UserID: 3, ResourceType: influxdb.UsersResourceType, ResourceID: 3},
+		{Time: start.Add(3 * time.Minute), Action: influxdb.AuditDelete, OrgID: orgID, UserID: userID, ResourceType: influxdb.BucketsResourceType, ResourceID: 10},
+	}
+	for _, e := range events {
+		if err := svc.RecordAuditEvent(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+		if !e.ID.Valid() {
+			t.Fatal("expected the event to be given an ID")
+		}
+	}
+
+	err := svc.RecordAuditEvent(ctx, &influxdb.AuditEvent{Action: "read"})
+	if code := influxdb.ErrorCode(err); code != influxdb.EInvalid {
+		t.Fatalf("expected an unknown action to be invalid, got %v", err)
+	}
+
+	ids := func(es []*influxdb.AuditEvent) []influxdb.ID {
+		var ids []influxdb.ID
+		for _, e := range es {
+			ids = append(ids, e.ID)
+		}
+		return ids
+	}
+	action := influxdb.AuditUpdate
+	login := influxdb.AuditLogin
+	first, second, last := events[0].ID, events[1].ID, events[3].ID
+	tests := []struct {
+		name   string
+		filter influxdb.AuditFilter
+		opts   []influxdb.FindOptions
+		want   []*influxdb.AuditEvent
+	}{
+		{
+			name: "all",
+			want: events,
+		},
+		{
+			name:   "org",
+			filter: influxdb.AuditFilter{OrgID: &orgID},
+			want:   []*influxdb.AuditEvent{events[0], events[1], events[3]},
+		},
+		{
+			name:   "action",
+			filter: influxdb.AuditFilter{Action: &action},
+			want:   []*influxdb.AuditEvent{events[1]},
+		},
+		{
+			name:   "time range",
+			filter: influxdb.AuditFilter{Start: start.Add(time.Minute), Stop: start.Add(3 * time.Minute)},
+			want:   []*influxdb.AuditEvent{events[1], events[2]},
+		},
+		{
+			name:   "action outside of time range",
+			filter: influxdb.AuditFilter{Action: &login, Stop: start.Add(time.Minute)},
+		},
+		{
+			name: "descending page",
+			opts: []influxdb.FindOptions{{Descending: true, Offset: 1, Limit: 2}},
+			want: []*influxdb.AuditEvent{events[2], events[1]},
+		},
+		{
+			name:   "descending org",
+			filter: influxdb.AuditFilter{OrgID: &orgID},
+			opts:   []influxdb.FindOptions{{Descending: true}},
+			want:   []*influxdb.AuditEvent{events[3], events[1], events[0]},
+		},
+		{
+			name:   "descending org time range",
+			filter: influxdb.AuditFilter{OrgID: &orgID, Start: start.Add(time.Minute), Stop: start.Add(3 * time.Minute)},
+			opts:   []influxdb.FindOptions{{Descending: true}},
+			want:   []*influxdb.AuditEvent{events[1]},
+		},
+		{
+			name:   "descending before stop",
+			filter: influxdb.AuditFilter{Stop: start.Add(2 * time.Minute)},
+			opts:   []influxdb.FindOptions{{Descending: true, Limit: 1}},
+			want:   []*influxdb.AuditEvent{events[1]},
+		},
+		{
+			name:   "after",
+			filter: influxdb.AuditFilter{After: &first},
+			opts:   []influxdb.FindOptions{{Limit: 2}},
+			want:   []*influxdb.AuditEvent{events[1], events[2]},
+		},
+		{
+			name:   "descending after",
+			filter: influxdb.AuditFilter{After: &last},
+			opts:   []influxdb.FindOptions{{Descending: true, Limit: 2}},
+			want:   []*influxdb.AuditEvent{events[2], events[1]},
+		},
+		{
+			name:   "org after",
+			filter: influxdb.AuditFilter{OrgID: &orgID, After: &second},
+			want:   []*influxdb.AuditEvent{events[3]},
+		},
+		{
+			name:   "descending org after",
+			filter: influxdb.AuditFilter{OrgID: &orgID, After: &last},
+			opts:   []influxdb.FindOptions{{Descending: true}},
+			want:   []*influxdb.AuditEvent{events[1], events[0]},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es, n, err := svc.FindAuditEvents(ctx, tt.filter, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != len(tt.want) {
+				t.Fatalf("expected %d events, got %d", len(tt.want), n)
+			}
+			got, want := ids(es), ids(tt.want)
+			for i := range want {
+				if got[i] != want[i] {
+					t.Fatalf("expected events %v, got %v", want, got)
+				}
+			}
+		})
+	}
+
+	unknown := influxdb.ID(1000)
+	_, _, err = svc.FindAuditEvents(ctx, influxdb.AuditFilter{After: &unknown})
+	if code := influxdb.ErrorCode(err); code != influxdb.EInvalid {
+		t.Fatalf("expected continuing after an unknown event to be invalid, got %v", err)
+	}
+
+	n, err := svc.DeleteAuditEventsBefore(ctx, start.Add(2*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 events to be deleted, got %d", n)
+	}
+	for _, f := range []influxdb.AuditFilter{{}, {OrgID: &orgID}} {
+		es, _, err := svc.FindAuditEvents(ctx, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(es) == 0 || es[0].ID == first || es[0].ID == second {
+			t.Fatalf("expected the events before the time to be deleted, got %v", ids(es))
+		}
+	}
+	_, _, err = svc.FindAuditEvents(ctx, influxdb.AuditFilter{After: &first})
+	if code := influxdb.ErrorCode(err); code != influxdb.EInvalid {
+		t.Fatalf("expected the deleted event to be removed from the index, got %v", err)
+	}
+}
@@ -2,10 +2,9 @@ package kv
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"time"
 
-	"github.com/buger/jsonparser"
 	influxdb "github.com/influxdata/influxdb/v2"
 	jsonp "github.com/influxdata/influxdb/v2/pkg/jsonparser"
 )
@@ -74,7 +73,7 @@ func (s *Service) findAuthorizationByID(ctx context.Context, tx Tx, id influxdb.
 	}
 
 	a := &influxdb.Authorization{}
-	if err := decodeAuthorization(v, a); err != nil {
+	if err := DecodeAuthorization(v, a); err != nil {
 		return nil, &influxdb.Error{
 			Code: influxdb.EInvalid,
 			Err:  err,
@@ -102,31 +101,26 @@ func (s *Service) FindAuthorizationByToken(ctx context.Context, n string) (*infl
 		return nil, err
 	}
 
+	if a.IsExpired(time.Now()) {
+		return nil, influxdb.ErrAuthorizationExpired
+	}
+
 	return a, nil
 }
 
 func (s *Service) findAuthorizationByToken(ctx context.Context, tx Tx, n string) (*influxdb.Authorization, error) {
-	idx, err := authIndexBucket(tx)
+	id, err := FindAuthorizationIDByToken(ctx, tx, n)
 	if err != nil {
 		return nil, err
 	}
 
-	a, err := idx.Get(authIndexKey(n))
-	if IsNotFound(err) {
-		return nil, &influxdb.Error{
-			Code: influxdb.ENotFound,
-			Msg:  "authorization not found",
-		}
-	}
-
-	var id influxdb.ID
-	if err := id.Decode(a); err != nil {
-		return nil, &influxdb.Error{
-			Code: influxdb.EInvalid,
-			Err:  err,
-		}
+	a, err := s.findAuthorizationByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
 	}
-	return s.findAuthorizationByID(ctx, tx, id)
+	// only the hash of the token is stored, so set the token it was found by.
+	a.Token = n
+	return a, nil
 }
 
 func authorizationsPredicateFn(f influxdb.AuthorizationFilter) CursorPredicateFunc {
@@ -144,18 +138,6 @@ func authorizationsPredicateFn(f influxdb.AuthorizationFilter) CursorPredicateFu
 		}
 	}
 
-	if f.Token != nil {
-		exp := *f.Token
-		return func(_, value []byte) bool {
-			// it is assumed that token never has escaped string data
-			got, _, _, err := jsonparser.Get(value, "token")
-			if err != nil {
-				return true
-			}
-			return string(got) == exp
-		}
-	}
-
 	var pred CursorPredicateFunc
 	if f.OrgID != nil {
 		exp := *f.OrgID
@@ -189,12 +171,6 @@ func filterAuthorizationsFn(filter influxdb.AuthorizationFilter) func(a *influxd
 		}
 	}
 
-	if filter.Token != nil {
-		return func(a *influxdb.Authorization) bool {
-			return a.Token == *filter.Token
-		}
-	}
-
 	// Filter by org and user
 	if filter.OrgID != nil && filter.UserID != nil {
 		return func(a *influxdb.Authorization) bool {
@@ -281,8 +257,25 @@ func (s *Service) findAuthorizations(ctx context.Context, tx Tx, f influxdb.Auth
 	}
 
 	var as []*influxdb.Authorization
-	pred := authorizationsPredicateFn(f)
 	filterFn := filterAuthorizationsFn(f)
+
+	// only the hash of the token is stored, so the authorization is found
+	// with the token index.
+	if f.Token != nil {
+		a, err := s.findAuthorizationByToken(ctx, tx, *f.Token)
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return as, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if filterFn(a) {
+			as = append(as, a)
+		}
+		return as, nil
+	}
+
+	pred := authorizationsPredicateFn(f)
 	err := s.forEachAuthorization(ctx, tx, pred, func(a *influxdb.Authorization) bool {
 		if filterFn(a) {
 			as = append(as, a)
@@ -343,7 +336,7 @@ func (s *Service) createAuthorization(ctx context.Context, tx Tx, a *influxdb.Au
 	a.SetCreatedAt(now)
 	a.SetUpdatedAt(now)
 
-	if err := s.putAuthorization(ctx, tx, a); err != nil {
+	if err := PutAuthorizationRecord(ctx, tx, a); err != nil {
 		return err
 	}
 
@@ -353,82 +346,10 @@ func (s *Service) createAuthorization(ctx context.Context, tx Tx, a *influxdb.Au
 // PutAuthorization will put a authorization without setting an ID.
 func (s *Service) PutAuthorization(ctx context.Context, a *influxdb.Authorization) error {
 	return s.kv.Update(ctx, func(tx Tx) error {
-		return s.putAuthorization(ctx, tx, a)
+		return PutAuthorizationRecord(ctx, tx, a)
 	})
 }
 
-func encodeAuthorization(a *influxdb.Authorization) ([]byte, error) {
-	switch a.Status {
-	case influxdb.Active, influxdb.Inactive:
-	case "":
-		a.Status = influxdb.Active
-	default:
-		return nil, &influxdb.Error{
-			Code: influxdb.EInvalid,
-			Msg:  "unknown authorization status",
-		}
-	}
-
-	return json.Marshal(a)
-}
-
-func (s *Service) putAuthorization(ctx context.Context, tx Tx, a *influxdb.Authorization) error {
-	v, err := encodeAuthorization(a)
-	if err != nil {
-		return &influxdb.Error{
-			Code: influxdb.EInvalid,
-			Err:  err,
-		}
-	}
-
-	encodedID, err := a.ID.Encode()
-	if err != nil {
-		return &influxdb.Error{
-			Code: influxdb.ENotFound,
-			Err:  err,
-		}
-	}
-
-	idx, err := authIndexBucket(tx)
-	if err != nil {
-		return err
-	}
-
-	if err := idx.Put(authIndexKey(a.Token), encodedID); err != nil {
-		return &influxdb.Error{
-			Code: influxdb.EInternal,
-			Err:  err,
-		}
-	}
-
-	b, err := tx.Bucket(authBucket)
-	if err != nil {
-		return err
-	}
-
-	if err := b.Put(encodedID, v); err != nil {
-		return &influxdb.Error{
-			Err: err,
-		}
-	}
-
-	return nil
-}
-
-func authIndexKey(n string) []byte {
-	return []byte(n)
-}
-
-func decodeAuthorization(b []byte, a *influxdb.Authorization) error {
-	if err := json.Unmarshal(b, a); err != nil {
-		return err
-	}
-	if a.Status == "" {
-		a.Status = influxdb.Active
-	}
-	return nil
-}
-
 // forEachAuthorization will iterate through all authorizations while fn returns true.
 func (s *Service) forEachAuthorization(ctx context.Context, tx Tx, pred CursorPredicateFunc, fn func(*influxdb.Authorization) bool) error {
 	b, err := tx.Bucket(authBucket)
@@ -452,7 +373,7 @@ func (s *Service) forEachAuthorization(ctx context.Context, tx Tx, pred CursorPr
 			Permissions: make([]influxdb.Permission, 64),
 		}
 
-		if err := decodeAuthorization(v, a); err != nil {
+		if err := DecodeAuthorization(v, a); err != nil {
 			return err
 		}
 		if !fn(a) {
@@ -466,46 +387,10 @@ func (s *Service) forEachAuthorization(ctx context.Context, tx Tx, pred CursorPr
 // DeleteAuthorization deletes a authorization and prunes it from the index.
 func (s *Service) DeleteAuthorization(ctx context.Context, id influxdb.ID) error {
 	return s.kv.Update(ctx, func(tx Tx) (err error) {
-		return s.deleteAuthorization(ctx, tx, id)
+		return DeleteAuthorizationRecord(ctx, tx, id)
 	})
 }
 
-func (s *Service) deleteAuthorization(ctx context.Context, tx Tx, id influxdb.ID) error {
-	a, err := s.findAuthorizationByID(ctx, tx, id)
-	if err != nil {
-		return err
-	}
-
-	idx, err := authIndexBucket(tx)
-	if err != nil {
-		return err
-	}
-
-	if err := idx.Delete(authIndexKey(a.Token)); err != nil {
-		return &influxdb.Error{
-			Err: err,
-		}
-	}
-	encodedID, err := id.Encode()
-	if err != nil {
-		return &influxdb.Error{
-			Err: err,
-		}
-	}
-
-	b, err := tx.Bucket(authBucket)
-	if err != nil {
-		return err
-	}
-
-	if err := b.Delete(encodedID); err != nil {
-		return &influxdb.Error{
-			Err: err,
-		}
-	}
-	return nil
-}
-
 // UpdateAuthorization updates the status and description if available.
 func (s *Service) UpdateAuthorization(ctx context.Context, id influxdb.ID, upd *influxdb.AuthorizationUpdate) (*influxdb.Authorization, error) {
 	var a *influxdb.Authorization
@@ -518,6 +403,10 @@ func (s *Service) UpdateAuthorization(ctx context.Context, id influxdb.ID, upd *
 }
 
 func (s *Service) updateAuthorization(ctx context.Context, tx Tx, id influxdb.ID, upd *influxdb.AuthorizationUpdate) (*influxdb.Authorization, error) {
+	if err := upd.Valid(); err != nil {
+		return nil, err
+	}
+
 	a, err := s.findAuthorizationByID(ctx, tx, id)
 	if err != nil {
 		return nil, err
@@ -529,11 +418,23 @@ func (s *Service) updateAuthorization(ctx context.Context, tx Tx, id influxdb.ID
 	if upd.Description != nil {
 		a.Description = *upd.Description
 	}
+	if upd.ExpiresAt != nil {
+		a.ExpiresAt = upd.ExpiresAt
+	}
+	if upd.NeverExpires {
+		a.ExpiresAt = nil
+	}
+	if upd.RateLimits != nil {
+		if err := upd.RateLimits.Valid(); err != nil {
+			return nil, err
+		}
+		a.RateLimits = upd.RateLimits
+	}
 
 	now := s.TimeGenerator.Now()
 	a.SetUpdatedAt(now)
 
-	if err := s.putAuthorization(ctx, tx, a); err != nil {
+	if err := PutAuthorizationRecord(ctx, tx, a); err != nil {
 		return nil, err
 	}
 
@@ -559,13 +460,20 @@ func UnexpectedAuthIndexError(err error) *influxdb.Error {
 }
 
 func (s *Service) uniqueAuthToken(ctx context.Context, tx Tx, a *influxdb.Authorization) error {
-	err := s.unique(ctx, tx, authIndex, authIndexKey(a.Token))
-	if err == NotUniqueError {
+	if a.Token == "" {
+		return nil
+	}
+
+	_, err := FindAuthorizationIDByToken(ctx, tx, a.Token)
+	if err == nil {
 		// by returning a generic error we are trying to hide when
 		// a token is non-unique.
 		return influxdb.ErrUnableToCreateToken
 	}
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		return nil
+	}
 	// otherwise, this is some sort of internal server error and we
 	// should provide some debugging information.
-	return err
+	return UnexpectedAuthIndexError(err)
 }
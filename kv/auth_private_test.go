@@ -38,26 +38,6 @@ func Test_authorizationsPredicateFn(t *testing.T) {
 		})
 	})
 
-	t.Run("token", func(t *testing.T) {
-		val := "token_token"
-		f := influxdb.AuthorizationFilter{Token: &val}
-		fn := authorizationsPredicateFn(f)
-
-		t.Run("does match", func(t *testing.T) {
-			a := &influxdb.Authorization{ID: 10, OrgID: 2, Token: val}
-			if got, exp := fn(nil, mustMarshal(t, a)), true; got != exp {
-				t.Errorf("unexpected result -got/+exp\n%s", cmp.Diff(got, exp))
-			}
-		})
-
-		t.Run("does not match", func(t *testing.T) {
-			a := &influxdb.Authorization{ID: 10, OrgID: 2, Token: "no_no_no"}
-			if got, exp := fn(nil, mustMarshal(t, a)), false; got != exp {
-				t.Errorf("unexpected result -got/+exp\n%s", cmp.Diff(got, exp))
-			}
-		})
-	})
-
 	t.Run("orgID", func(t *testing.T) {
 		val := influxdb.ID(1)
 		f := influxdb.AuthorizationFilter{OrgID: &val}
@@ -0,0 +1,362 @@
+package kv
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	influxdb "github.com/influxdata/influxdb/v2"
+)
+
+const (
+	// tokenIndexPrefixLen is the number of bytes of the SHA-256 digest of a
+	// token that the authorization index is keyed by.
+	tokenIndexPrefixLen = 8
+	// tokenHashSaltLen is the number of bytes of salt of a hashed token.
+	tokenHashSaltLen = 16
+
+	tokenHashScheme = "sha256"
+)
+
+// HashToken returns a salted hash of the token, which is stored in place
+// of the token. Tokens are long random strings, so a fast hash is enough
+// to keep them from being recovered from the store.
+func HashToken(token string) (string, error) {
+	salt := make([]byte, tokenHashSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	return tokenHashScheme + "$" + hex.EncodeToString(salt) + "$" + hex.EncodeToString(saltedTokenSum(salt, token)), nil
+}
+
+// CompareTokenHash reports whether hash is a hash of the token.
+func CompareTokenHash(hash, token string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 3 || parts[0] != tokenHashScheme {
+		return false
+	}
+	salt, err := hex.DecodeString(parts[1])
+	if err != nil {
+		return false
+	}
+	sum, err := hex.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(sum, saltedTokenSum(salt, token)) == 1
+}
+
+// TokenIndexPrefix returns the prefix of the index keys of the
+// authorizations of the token. The prefix is too short to recover the
+// token from, so the authorizations with the prefix are candidates that
+// are compared to the hashed token.
+func TokenIndexPrefix(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return []byte(hex.EncodeToString(sum[:tokenIndexPrefixLen]))
+}
+
+func saltedTokenSum(salt []byte, token string) []byte {
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(token))
+	return h.Sum(nil)
+}
+
+// authorizationRecord is the stored form of an authorization. The token of
+// the authorization is never stored, only its hash and index prefix.
+type authorizationRecord struct {
+	*influxdb.Authorization
+	// Token shadows the token of the authorization. It is only set on
+	// records stored before tokens were hashed.
+	Token       string `json:"token,omitempty"`
+	TokenPrefix string `json:"tokenPrefix,omitempty"`
+	HashedToken string `json:"hashedToken,omitempty"`
+}
+
+func authTokenIndexKey(prefix string, encodedID []byte) []byte {
+	return append([]byte(prefix), encodedID...)
+}
+
+// FindAuthorizationIDByToken returns the ID of the authorization of the
+// token, comparing the token to the hashed tokens of the authorizations
+// with its index prefix.
+func FindAuthorizationIDByToken(ctx context.Context, tx Tx, token string) (influxdb.ID, error) {
+	idx, err := authIndexBucket(tx)
+	if err != nil {
+		return 0, err
+	}
+	b, err := tx.Bucket(authBucket)
+	if err != nil {
+		return 0, err
+	}
+
+	prefix := TokenIndexPrefix(token)
+	cur, err := idx.ForwardCursor(prefix, WithCursorPrefix(prefix))
+	if err != nil {
+		return 0, err
+	}
+	defer cur.Close()
+
+	for _, v := cur.Next(); v != nil; _, v = cur.Next() {
+		rec, err := getAuthorizationRecord(b, v)
+		if err != nil {
+			return 0, err
+		}
+		if rec != nil && CompareTokenHash(rec.HashedToken, token) {
+			var id influxdb.ID
+			if err := id.Decode(v); err != nil {
+				return 0, err
+			}
+			return id, nil
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return 0, err
+	}
+
+	return 0, &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  "authorization not found",
+	}
+}
+
+// PutAuthorizationRecord stores the authorization with its token hashed,
+// and indexes it by the prefix of its token. The hashed token of the stored
+// authorization is kept if a has no token.
+func PutAuthorizationRecord(ctx context.Context, tx Tx, a *influxdb.Authorization) error {
+	encodedID, err := a.ID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(authBucket)
+	if err != nil {
+		return err
+	}
+
+	prev, err := getAuthorizationRecord(b, encodedID)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	rec := &authorizationRecord{Authorization: a}
+	if a.Token != "" {
+		hash, err := HashToken(a.Token)
+		if err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInternal,
+				Err:  err,
+			}
+		}
+		rec.TokenPrefix, rec.HashedToken = string(TokenIndexPrefix(a.Token)), hash
+	} else if prev != nil {
+		rec.TokenPrefix, rec.HashedToken = prev.TokenPrefix, prev.HashedToken
+	}
+
+	v, err := encodeAuthorization(rec)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	idx, err := authIndexBucket(tx)
+	if err != nil {
+		return err
+	}
+
+	if prev != nil && prev.TokenPrefix != "" && prev.TokenPrefix != rec.TokenPrefix {
+		if err := idx.Delete(authTokenIndexKey(prev.TokenPrefix, encodedID)); err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInternal,
+				Err:  err,
+			}
+		}
+	}
+
+	if rec.TokenPrefix != "" {
+		if err := idx.Put(authTokenIndexKey(rec.TokenPrefix, encodedID), encodedID); err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInternal,
+				Err:  err,
+			}
+		}
+	}
+
+	if err := b.Put(encodedID, v); err != nil {
+		return &influxdb.Error{
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+// DeleteAuthorizationRecord deletes the stored authorization of the ID and
+// its token index entry.
+func DeleteAuthorizationRecord(ctx context.Context, tx Tx, id influxdb.ID) error {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(authBucket)
+	if err != nil {
+		return err
+	}
+
+	rec, err := getAuthorizationRecord(b, encodedID)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	if rec == nil {
+		return &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  "authorization not found",
+		}
+	}
+
+	idx, err := authIndexBucket(tx)
+	if err != nil {
+		return err
+	}
+
+	if rec.TokenPrefix != "" {
+		if err := idx.Delete(authTokenIndexKey(rec.TokenPrefix, encodedID)); err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInternal,
+				Err:  err,
+			}
+		}
+	}
+
+	if err := b.Delete(encodedID); err != nil {
+		return &influxdb.Error{
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// DecodeAuthorization decodes the stored authorization b into a. The token
+// of a is not set, as only its hash is stored.
+func DecodeAuthorization(b []byte, a *influxdb.Authorization) error {
+	if err := json.Unmarshal(b, &authorizationRecord{Authorization: a}); err != nil {
+		return err
+	}
+	if a.Status == "" {
+		a.Status = influxdb.Active
+	}
+	return nil
+}
+
+func encodeAuthorization(a *authorizationRecord) ([]byte, error) {
+	switch a.Status {
+	case influxdb.Active, influxdb.Inactive:
+	case "":
+		a.Status = influxdb.Active
+	default:
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unknown authorization status",
+		}
+	}
+
+	return json.Marshal(a)
+}
+
+// getAuthorizationRecord returns the stored authorization of the ID, or nil
+// if there is none.
+func getAuthorizationRecord(b Bucket, encodedID []byte) (*authorizationRecord, error) {
+	v, err := b.Get(encodedID)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rec := &authorizationRecord{Authorization: &influxdb.Authorization{}}
+	if err := json.Unmarshal(v, rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// hashAuthorizationTokens replaces the tokens of the authorizations stored
+// before tokens were hashed with their hashes, and reindexes them.
+func (s *Service) hashAuthorizationTokens(ctx context.Context, store Store) error {
+	return store.Update(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(authBucket)
+		if err != nil {
+			return err
+		}
+		idx, err := authIndexBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		cur, err := b.Cursor()
+		if err != nil {
+			return err
+		}
+
+		// collect the records first, as the bucket is written to below.
+		var recs []*authorizationRecord
+		for k, v := cur.First(); k != nil; k, v = cur.Next() {
+			rec := &authorizationRecord{Authorization: &influxdb.Authorization{}}
+			if err := json.Unmarshal(v, rec); err != nil {
+				return err
+			}
+			if rec.Token != "" && rec.HashedToken == "" {
+				recs = append(recs, rec)
+			}
+		}
+
+		for _, rec := range recs {
+			encodedID, err := rec.ID.Encode()
+			if err != nil {
+				return err
+			}
+
+			hash, err := HashToken(rec.Token)
+			if err != nil {
+				return err
+			}
+			if err := idx.Delete([]byte(rec.Token)); err != nil {
+				return err
+			}
+			rec.TokenPrefix, rec.HashedToken = string(TokenIndexPrefix(rec.Token)), hash
+			rec.Token = ""
+
+			if err := idx.Put(authTokenIndexKey(rec.TokenPrefix, encodedID), encodedID); err != nil {
+				return err
+			}
+			v, err := json.Marshal(rec)
+			if err != nil {
+				return err
+			}
+			if err := b.Put(encodedID, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,111 @@
+package kv_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestHashToken(t *testing.T) {
+	h1, err := kv.HashToken("rand1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h2, err := kv.HashToken("rand1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h1 == h2 {
+		t.Error("expected hashes of the same token to be salted differently")
+	}
+	if !kv.CompareTokenHash(h1, "rand1") || !kv.CompareTokenHash(h2, "rand1") {
+		t.Error("expected token to match its hashes")
+	}
+	if kv.CompareTokenHash(h1, "rand2") {
+		t.Error("expected other token not to match hash")
+	}
+	if kv.CompareTokenHash("rand1", "rand1") {
+		t.Error("expected plaintext token not to match as a hash")
+	}
+}
+
+func TestService_HashAuthorizationTokensMigration(t *testing.T) {
+	ctx := context.Background()
+	store := inmem.NewKVStore()
+
+	// store an authorization the way it was stored before tokens were hashed.
+	legacy := []byte(`{"id":"020f755c3c082000","token":"legacy-token","status":"active","orgID":"020f755c3c082001","userID":"020f755c3c082002","permissions":[]}`)
+	id, _ := influxdb.IDFromString("020f755c3c082000")
+	encodedID, _ := id.Encode()
+	if err := store.Update(ctx, func(tx kv.Tx) error {
+		b, err := tx.Bucket([]byte("authorizationsv1"))
+		if err != nil {
+			return err
+		}
+		if err := b.Put(encodedID, legacy); err != nil {
+			return err
+		}
+		idx, err := tx.Bucket([]byte("authorizationindexv1"))
+		if err != nil {
+			return err
+		}
+		return idx.Put([]byte("legacy-token"), encodedID)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := svc.FindAuthorizationByToken(ctx, "legacy-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.ID != *id {
+		t.Errorf("expected authorization %s, got %s", id, a.ID)
+	}
+
+	if err := store.View(ctx, func(tx kv.Tx) error {
+		for _, bucket := range []string{"authorizationsv1", "authorizationindexv1"} {
+			b, err := tx.Bucket([]byte(bucket))
+			if err != nil {
+				return err
+			}
+			cur, err := b.Cursor()
+			if err != nil {
+				return err
+			}
+			for k, v := cur.First(); k != nil; k, v = cur.Next() {
+				if bytes.Contains(k, []byte("legacy-token")) || bytes.Contains(v, []byte("legacy-token")) {
+					t.Errorf("expected token not to be stored in %s", bucket)
+				}
+			}
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPutAuthorizationRecord_invalidID(t *testing.T) {
+	ctx := context.Background()
+	store := inmem.NewKVStore()
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	err := store.Update(ctx, func(tx kv.Tx) error {
+		return kv.PutAuthorizationRecord(ctx, tx, &influxdb.Authorization{Token: "rand1"})
+	})
+	if code := influxdb.ErrorCode(err); code != influxdb.EInvalid {
+		t.Errorf("expected an invalid ID to be invalid, got %v", err)
+	}
+}
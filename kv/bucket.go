@@ -687,6 +687,10 @@ func (s *Service) updateBucket(ctx context.Context, tx Tx, id influxdb.ID, upd i
 		b.RetentionPeriod = *upd.RetentionPeriod
 	}
 
+	if upd.Durability != nil {
+		b.Durability = *upd.Durability
+	}
+
 	if upd.Description != nil {
 		b.Description = *upd.Description
 	}
@@ -0,0 +1,132 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var loginAttemptsBucket = []byte("userloginattemptsv1")
+
+var _ influxdb.LockoutService = (*Service)(nil)
+
+func (s *Service) initializeLoginAttempts(ctx context.Context, store Store) error {
+	return store.Update(ctx, func(tx Tx) error {
+		_, err := tx.Bucket(loginAttemptsBucket)
+		return err
+	})
+}
+
+// FindLoginAttempts returns the failed sign-ins of the user.
+func (s *Service) FindLoginAttempts(ctx context.Context, userID influxdb.ID) (*influxdb.LoginAttempts, error) {
+	var a *influxdb.LoginAttempts
+	err := s.kv.View(ctx, func(tx Tx) error {
+		var err error
+		a, err = s.findLoginAttempts(ctx, tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindLoginAttempts,
+			Err: err,
+		}
+	}
+	return a, nil
+}
+
+// RecordLoginFailure records a failed sign-in of the user, and locks it out
+// according to the lockout policy of the service.
+func (s *Service) RecordLoginFailure(ctx context.Context, userID influxdb.ID) (*influxdb.LoginAttempts, error) {
+	var a *influxdb.LoginAttempts
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		var err error
+		a, err = s.findLoginAttempts(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+
+		now := s.clock.Now().UTC()
+		a.Failures++
+		a.LastFailure = now
+		if d := s.Config.LockoutPolicy.LockoutDuration(a.Failures); d > 0 {
+			a.LockedUntil = now.Add(d)
+		}
+		return s.putLoginAttempts(ctx, tx, a)
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpRecordLoginFailure,
+			Err: err,
+		}
+	}
+	return a, nil
+}
+
+// ResetLoginAttempts clears the failed sign-ins of the user.
+func (s *Service) ResetLoginAttempts(ctx context.Context, userID influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		encodedID, err := userID.Encode()
+		if err != nil {
+			return err
+		}
+		b, err := tx.Bucket(loginAttemptsBucket)
+		if err != nil {
+			return err
+		}
+		return b.Delete(encodedID)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpResetLoginAttempts,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) findLoginAttempts(ctx context.Context, tx Tx, userID influxdb.ID) (*influxdb.LoginAttempts, error) {
+	encodedID, err := userID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(loginAttemptsBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encodedID)
+	if IsNotFound(err) {
+		// The user never failed to sign in.
+		return &influxdb.LoginAttempts{UserID: userID}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	a := &influxdb.LoginAttempts{}
+	if err := json.Unmarshal(v, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (s *Service) putLoginAttempts(ctx context.Context, tx Tx, a *influxdb.LoginAttempts) error {
+	encodedID, err := a.UserID.Encode()
+	if err != nil {
+		return err
+	}
+	v, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	b, err := tx.Bucket(loginAttemptsBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(encodedID, v)
+}
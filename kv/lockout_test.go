@@ -0,0 +1,110 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestBoltLockoutService(t *testing.T) {
+	s, closeBolt, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeBolt()
+
+	ctx := context.Background()
+	clk := clock.NewMock()
+	svc := kv.NewService(zaptest.NewLogger(t), s, kv.ServiceConfig{
+		Clock: clk,
+		LockoutPolicy: influxdb.LockoutPolicy{
+			MaxAttempts: 2,
+			Duration:    time.Minute,
+			MaxDuration: 3 * time.Minute,
+		},
+	})
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing lockout service: %v", err)
+	}
+
+	userID := influxdb.ID(1)
+	a, err := svc.FindLoginAttempts(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Failures != 0 || a.Locked(clk.Now()) {
+		t.Fatalf("expected no failed sign-ins, got %+v", a)
+	}
+
+	for i, want := range []time.Duration{0, time.Minute, 2 * time.Minute, 3 * time.Minute, 3 * time.Minute} {
+		a, err := svc.RecordLoginFailure(ctx, userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.Failures != i+1 {
+			t.Fatalf("expected %d failed sign-ins, got %d", i+1, a.Failures)
+		}
+		var got time.Duration
+		if a.Locked(clk.Now()) {
+			got = a.LockedUntil.Sub(clk.Now())
+		}
+		if got != want {
+			t.Errorf("failure %d: expected to be locked out for %s, got %s", i+1, want, got)
+		}
+	}
+
+	clk.Add(3 * time.Minute)
+	a, err = svc.FindLoginAttempts(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Locked(clk.Now()) || a.Failures != 5 {
+		t.Fatalf("expected the lockout to expire and the failures to be kept, got %+v", a)
+	}
+
+	if err := svc.ResetLoginAttempts(ctx, userID); err != nil {
+		t.Fatal(err)
+	}
+	a, err = svc.FindLoginAttempts(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Failures != 0 {
+		t.Fatalf("expected the failed sign-ins to be reset, got %+v", a)
+	}
+}
+
+func TestBoltPasswordPolicy(t *testing.T) {
+	s, closeBolt, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeBolt()
+
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), s, kv.ServiceConfig{
+		PasswordPolicy: influxdb.PasswordPolicy{MinLength: 10, RequireDigit: true},
+	})
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing password service: %v", err)
+	}
+	user := &influxdb.User{Name: "user"}
+	if err := svc.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, password := range []string{"short1", "longenoughbutnodigit"} {
+		err := svc.SetPassword(ctx, user.ID, password)
+		if code := influxdb.ErrorCode(err); code != influxdb.EInvalid {
+			t.Errorf("expected password %q to be invalid, got %v", password, err)
+		}
+	}
+	if err := svc.SetPassword(ctx, user.ID, "longenough1"); err != nil {
+		t.Fatal(err)
+	}
+}
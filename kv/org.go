@@ -554,6 +554,12 @@ func (s *Service) DeleteOrganization(ctx context.Context, id influxdb.ID) error
 		if pe := s.deleteOrganization(ctx, tx, id); pe != nil {
 			return pe
 		}
+		if err := s.deleteOrgLimits(ctx, tx, id); err != nil {
+			return err
+		}
+		if err := s.deleteOrgRoles(ctx, tx, id); err != nil {
+			return err
+		}
 
 		uid, _ := icontext.GetUserID(ctx)
 		return s.audit.Log(resource.Change{
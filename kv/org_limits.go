@@ -0,0 +1,148 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	orgLimitsBucket = []byte("orglimitsv1")
+)
+
+var _ influxdb.OrgLimitsService = (*Service)(nil)
+
+func (s *Service) initializeOrgLimits(ctx context.Context, store Store) error {
+	return store.Update(ctx, func(tx Tx) error {
+		_, err := tx.Bucket(orgLimitsBucket)
+		return err
+	})
+}
+
+// FindOrgLimits retrieves the limits of the organization orgID.
+func (s *Service) FindOrgLimits(ctx context.Context, orgID influxdb.ID) (*influxdb.OrgLimits, error) {
+	var l *influxdb.OrgLimits
+	err := s.kv.View(ctx, func(tx Tx) error {
+		lim, err := s.findOrgLimits(ctx, tx, orgID)
+		if err != nil {
+			return err
+		}
+		l = lim
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindOrgLimits,
+			Err: err,
+		}
+	}
+	return l, nil
+}
+
+func (s *Service) findOrgLimits(ctx context.Context, tx Tx, orgID influxdb.ID) (*influxdb.OrgLimits, error) {
+	key, err := orgID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(orgLimitsBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(key)
+	if IsNotFound(err) {
+		return &influxdb.OrgLimits{OrgID: orgID}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	l := &influxdb.OrgLimits{}
+	if err := json.Unmarshal(v, l); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return l, nil
+}
+
+// UpdateOrgLimits updates the limits of the organization orgID.
+func (s *Service) UpdateOrgLimits(ctx context.Context, orgID influxdb.ID, upd influxdb.OrgLimitsUpdate) (*influxdb.OrgLimits, error) {
+	var l *influxdb.OrgLimits
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findOrganizationByID(ctx, tx, orgID); err != nil {
+			return err
+		}
+
+		lim, err := s.findOrgLimits(ctx, tx, orgID)
+		if err != nil {
+			return err
+		}
+		upd.Apply(lim)
+		if err := lim.Valid(); err != nil {
+			return err
+		}
+
+		if err := s.putOrgLimits(ctx, tx, lim); err != nil {
+			return err
+		}
+		l = lim
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpUpdateOrgLimits,
+			Err: err,
+		}
+	}
+	return l, nil
+}
+
+func (s *Service) putOrgLimits(ctx context.Context, tx Tx, l *influxdb.OrgLimits) error {
+	key, err := l.OrgID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	v, err := json.Marshal(l)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(orgLimitsBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(key, v)
+}
+
+func (s *Service) deleteOrgLimits(ctx context.Context, tx Tx, orgID influxdb.ID) error {
+	key, err := orgID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(orgLimitsBucket)
+	if err != nil {
+		return err
+	}
+	if err := b.Delete(key); err != nil && !IsNotFound(err) {
+		return err
+	}
+	return nil
+}
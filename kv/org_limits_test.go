@@ -0,0 +1,92 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestBoltOrgLimitsService(t *testing.T) {
+	s, closeBolt, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeBolt()
+
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing org limits service: %v", err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := svc.FindOrgLimits(ctx, org.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (&influxdb.OrgLimits{OrgID: org.ID}); !cmp.Equal(want, l) {
+		t.Fatalf("unexpected default limits -want/+got:\n%s", cmp.Diff(want, l))
+	}
+
+	concurrency, memory, points, monthly := 2, int64(1024), int64(500), int64(1<<30)
+	if _, err := svc.UpdateOrgLimits(ctx, org.ID, influxdb.OrgLimitsUpdate{ConcurrencyQuota: &concurrency}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.UpdateOrgLimits(ctx, org.ID, influxdb.OrgLimitsUpdate{MemoryBytesQuota: &memory, WritePointsPerSecond: &points, MonthlyWriteBytesQuota: &monthly}); err != nil {
+		t.Fatal(err)
+	}
+	l, err = svc.FindOrgLimits(ctx, org.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &influxdb.OrgLimits{
+		OrgID:            org.ID,
+		ConcurrencyQuota: 2,
+		MemoryBytesQuota: 1024,
+		RateLimits:       influxdb.RateLimits{WritePointsPerSecond: 500},
+
+		MonthlyWriteBytesQuota: 1 << 30,
+	}
+	if !cmp.Equal(want, l) {
+		t.Fatalf("unexpected limits -want/+got:\n%s", cmp.Diff(want, l))
+	}
+
+	negative, negativeRate := -1, int64(-1)
+	_, err = svc.UpdateOrgLimits(ctx, org.ID, influxdb.OrgLimitsUpdate{QueueSize: &negative})
+	if code := influxdb.ErrorCode(err); code != influxdb.EInvalid {
+		t.Fatalf("expected invalid negative limit, got %v", err)
+	}
+	_, err = svc.UpdateOrgLimits(ctx, org.ID, influxdb.OrgLimitsUpdate{QueriesPerSecond: &negativeRate})
+	if code := influxdb.ErrorCode(err); code != influxdb.EInvalid {
+		t.Fatalf("expected invalid negative rate limit, got %v", err)
+	}
+	_, err = svc.UpdateOrgLimits(ctx, org.ID, influxdb.OrgLimitsUpdate{StorageBytesQuota: &negativeRate})
+	if code := influxdb.ErrorCode(err); code != influxdb.EInvalid {
+		t.Fatalf("expected invalid negative quota, got %v", err)
+	}
+
+	_, err = svc.UpdateOrgLimits(ctx, influxdb.ID(1), influxdb.OrgLimitsUpdate{ConcurrencyQuota: &concurrency})
+	if code := influxdb.ErrorCode(err); code != influxdb.ENotFound {
+		t.Fatalf("expected org not found, got %v", err)
+	}
+
+	// The limits are deleted along with the organization.
+	if err := svc.DeleteOrganization(ctx, org.ID); err != nil {
+		t.Fatal(err)
+	}
+	l, err = svc.FindOrgLimits(ctx, org.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (&influxdb.OrgLimits{OrgID: org.ID}); !cmp.Equal(want, l) {
+		t.Fatalf("limits were not deleted -want/+got:\n%s", cmp.Diff(want, l))
+	}
+}
@@ -104,8 +104,8 @@ func (s *Service) ComparePassword(ctx context.Context, userID influxdb.ID, passw
 }
 
 func (s *Service) setPassword(ctx context.Context, tx Tx, userID influxdb.ID, password string) error {
-	if len(password) < MinPasswordLength {
-		return EShortPassword
+	if err := s.passwordPolicy.Validate(password); err != nil {
+		return err
 	}
 
 	encodedID, err := userID.Encode()
@@ -0,0 +1,544 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	roleBucket      = []byte("rolesv1")
+	userGroupBucket = []byte("usergroupsv1")
+)
+
+var (
+	_ influxdb.RoleService      = (*Service)(nil)
+	_ influxdb.UserGroupService = (*Service)(nil)
+)
+
+func (s *Service) initializeRoles(ctx context.Context, store Store) error {
+	return store.Update(ctx, func(tx Tx) error {
+		if _, err := tx.Bucket(roleBucket); err != nil {
+			return err
+		}
+		_, err := tx.Bucket(userGroupBucket)
+		return err
+	})
+}
+
+// FindRoleByID retrieves a role by id.
+func (s *Service) FindRoleByID(ctx context.Context, id influxdb.ID) (*influxdb.Role, error) {
+	var r *influxdb.Role
+	err := s.kv.View(ctx, func(tx Tx) error {
+		role, err := s.findRoleByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		r = role
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindRoleByID,
+			Err: err,
+		}
+	}
+	return r, nil
+}
+
+func (s *Service) findRoleByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.Role, error) {
+	r := &influxdb.Role{}
+	if err := getByID(tx, roleBucket, id, r); err != nil {
+		if IsNotFound(err) {
+			return nil, &influxdb.Error{
+				Code: influxdb.ENotFound,
+				Msg:  influxdb.ErrRoleNotFound,
+			}
+		}
+		return nil, err
+	}
+	return r, nil
+}
+
+// FindRoles retrieves all roles that match the filter.
+func (s *Service) FindRoles(ctx context.Context, filter influxdb.RoleFilter, opt ...influxdb.FindOptions) ([]*influxdb.Role, int, error) {
+	if filter.ID != nil {
+		r, err := s.FindRoleByID(ctx, *filter.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+		return []*influxdb.Role{r}, 1, nil
+	}
+
+	rs := []*influxdb.Role{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		roles, err := s.findRoles(ctx, tx, filter)
+		if err != nil {
+			return err
+		}
+		rs = roles
+		return nil
+	})
+	if err != nil {
+		return nil, 0, &influxdb.Error{
+			Op:  influxdb.OpFindRoles,
+			Err: err,
+		}
+	}
+	return rs, len(rs), nil
+}
+
+func (s *Service) findRoles(ctx context.Context, tx Tx, filter influxdb.RoleFilter) ([]*influxdb.Role, error) {
+	rs := []*influxdb.Role{}
+	err := forEach(tx, roleBucket, func(v []byte) error {
+		r := &influxdb.Role{}
+		if err := json.Unmarshal(v, r); err != nil {
+			return err
+		}
+		if filter.OrgID != nil && r.OrgID != *filter.OrgID {
+			return nil
+		}
+		if filter.Name != nil && r.Name != *filter.Name {
+			return nil
+		}
+		if filter.UserID != nil && !r.HasUser(*filter.UserID) {
+			return nil
+		}
+		rs = append(rs, r)
+		return nil
+	})
+	return rs, err
+}
+
+// CreateRole creates a role and sets r.ID.
+func (s *Service) CreateRole(ctx context.Context, r *influxdb.Role) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if err := s.validRole(ctx, tx, r); err != nil {
+			return err
+		}
+
+		r.ID = s.IDGenerator.ID()
+		now := s.TimeGenerator.Now()
+		r.SetCreatedAt(now)
+		r.SetUpdatedAt(now)
+		return putByID(tx, roleBucket, r.ID, r)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpCreateRole,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// UpdateRole updates a role with the changeset.
+func (s *Service) UpdateRole(ctx context.Context, id influxdb.ID, upd influxdb.RoleUpdate) (*influxdb.Role, error) {
+	var r *influxdb.Role
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		role, err := s.findRoleByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		upd.Apply(role)
+		if err := s.validRole(ctx, tx, role); err != nil {
+			return err
+		}
+
+		role.SetUpdatedAt(s.TimeGenerator.Now())
+		if err := putByID(tx, roleBucket, role.ID, role); err != nil {
+			return err
+		}
+		r = role
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpUpdateRole,
+			Err: err,
+		}
+	}
+	return r, nil
+}
+
+// validRole returns an error if the role is invalid, its name is taken
+// within its organization, or any of its users does not exist.
+func (s *Service) validRole(ctx context.Context, tx Tx, r *influxdb.Role) error {
+	if err := r.Valid(); err != nil {
+		return err
+	}
+	if _, err := s.findOrganizationByID(ctx, tx, r.OrgID); err != nil {
+		return err
+	}
+
+	rs, err := s.findRoles(ctx, tx, influxdb.RoleFilter{OrgID: &r.OrgID, Name: &r.Name})
+	if err != nil {
+		return err
+	}
+	for _, other := range rs {
+		if other.ID != r.ID {
+			return &influxdb.Error{
+				Code: influxdb.EConflict,
+				Msg:  fmt.Sprintf("role with name %s already exists", r.Name),
+			}
+		}
+	}
+
+	for _, userID := range r.Users {
+		if _, err := s.findUserByID(ctx, tx, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteRole deletes a role and removes it from the user groups it is
+// assigned to.
+func (s *Service) DeleteRole(ctx context.Context, id influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		return s.deleteRole(ctx, tx, id)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpDeleteRole,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) deleteRole(ctx context.Context, tx Tx, id influxdb.ID) error {
+	r, err := s.findRoleByID(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	gs, err := s.findUserGroups(ctx, tx, influxdb.UserGroupFilter{OrgID: &r.OrgID})
+	if err != nil {
+		return err
+	}
+	for _, g := range gs {
+		roles := make([]influxdb.ID, 0, len(g.Roles))
+		for _, roleID := range g.Roles {
+			if roleID != id {
+				roles = append(roles, roleID)
+			}
+		}
+		if len(roles) == len(g.Roles) {
+			continue
+		}
+		g.Roles = roles
+		if err := putByID(tx, userGroupBucket, g.ID, g); err != nil {
+			return err
+		}
+	}
+
+	return deleteByID(tx, roleBucket, id)
+}
+
+// FindUserGroupByID retrieves a user group by id.
+func (s *Service) FindUserGroupByID(ctx context.Context, id influxdb.ID) (*influxdb.UserGroup, error) {
+	var g *influxdb.UserGroup
+	err := s.kv.View(ctx, func(tx Tx) error {
+		group, err := s.findUserGroupByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		g = group
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindUserGroupByID,
+			Err: err,
+		}
+	}
+	return g, nil
+}
+
+func (s *Service) findUserGroupByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.UserGroup, error) {
+	g := &influxdb.UserGroup{}
+	if err := getByID(tx, userGroupBucket, id, g); err != nil {
+		if IsNotFound(err) {
+			return nil, &influxdb.Error{
+				Code: influxdb.ENotFound,
+				Msg:  influxdb.ErrUserGroupNotFound,
+			}
+		}
+		return nil, err
+	}
+	return g, nil
+}
+
+// FindUserGroups retrieves all user groups that match the filter.
+func (s *Service) FindUserGroups(ctx context.Context, filter influxdb.UserGroupFilter, opt ...influxdb.FindOptions) ([]*influxdb.UserGroup, int, error) {
+	if filter.ID != nil {
+		g, err := s.FindUserGroupByID(ctx, *filter.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+		return []*influxdb.UserGroup{g}, 1, nil
+	}
+
+	gs := []*influxdb.UserGroup{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		groups, err := s.findUserGroups(ctx, tx, filter)
+		if err != nil {
+			return err
+		}
+		gs = groups
+		return nil
+	})
+	if err != nil {
+		return nil, 0, &influxdb.Error{
+			Op:  influxdb.OpFindUserGroups,
+			Err: err,
+		}
+	}
+	return gs, len(gs), nil
+}
+
+func (s *Service) findUserGroups(ctx context.Context, tx Tx, filter influxdb.UserGroupFilter) ([]*influxdb.UserGroup, error) {
+	gs := []*influxdb.UserGroup{}
+	err := forEach(tx, userGroupBucket, func(v []byte) error {
+		g := &influxdb.UserGroup{}
+		if err := json.Unmarshal(v, g); err != nil {
+			return err
+		}
+		if filter.OrgID != nil && g.OrgID != *filter.OrgID {
+			return nil
+		}
+		if filter.Name != nil && g.Name != *filter.Name {
+			return nil
+		}
+		if filter.UserID != nil && !g.HasMember(*filter.UserID) {
+			return nil
+		}
+		gs = append(gs, g)
+		return nil
+	})
+	return gs, err
+}
+
+// CreateUserGroup creates a user group and sets g.ID.
+func (s *Service) CreateUserGroup(ctx context.Context, g *influxdb.UserGroup) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if err := s.validUserGroup(ctx, tx, g); err != nil {
+			return err
+		}
+
+		g.ID = s.IDGenerator.ID()
+		now := s.TimeGenerator.Now()
+		g.SetCreatedAt(now)
+		g.SetUpdatedAt(now)
+		return putByID(tx, userGroupBucket, g.ID, g)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpCreateUserGroup,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// UpdateUserGroup updates a user group with the changeset.
+func (s *Service) UpdateUserGroup(ctx context.Context, id influxdb.ID, upd influxdb.UserGroupUpdate) (*influxdb.UserGroup, error) {
+	var g *influxdb.UserGroup
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		group, err := s.findUserGroupByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		upd.Apply(group)
+		if err := s.validUserGroup(ctx, tx, group); err != nil {
+			return err
+		}
+
+		group.SetUpdatedAt(s.TimeGenerator.Now())
+		if err := putByID(tx, userGroupBucket, group.ID, group); err != nil {
+			return err
+		}
+		g = group
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpUpdateUserGroup,
+			Err: err,
+		}
+	}
+	return g, nil
+}
+
+// validUserGroup returns an error if the group is invalid, its name is
+// taken within its organization, any of its members does not exist, or any
+// of its roles is not a role of its organization.
+func (s *Service) validUserGroup(ctx context.Context, tx Tx, g *influxdb.UserGroup) error {
+	if err := g.Valid(); err != nil {
+		return err
+	}
+	if _, err := s.findOrganizationByID(ctx, tx, g.OrgID); err != nil {
+		return err
+	}
+
+	gs, err := s.findUserGroups(ctx, tx, influxdb.UserGroupFilter{OrgID: &g.OrgID, Name: &g.Name})
+	if err != nil {
+		return err
+	}
+	for _, other := range gs {
+		if other.ID != g.ID {
+			return &influxdb.Error{
+				Code: influxdb.EConflict,
+				Msg:  fmt.Sprintf("user group with name %s already exists", g.Name),
+			}
+		}
+	}
+
+	for _, userID := range g.Members {
+		if _, err := s.findUserByID(ctx, tx, userID); err != nil {
+			return err
+		}
+	}
+	for _, roleID := range g.Roles {
+		r, err := s.findRoleByID(ctx, tx, roleID)
+		if err != nil {
+			return err
+		}
+		if r.OrgID != g.OrgID {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("role %s is not a role of the organization of the user group", roleID),
+			}
+		}
+	}
+	return nil
+}
+
+// DeleteUserGroup deletes a user group.
+func (s *Service) DeleteUserGroup(ctx context.Context, id influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findUserGroupByID(ctx, tx, id); err != nil {
+			return err
+		}
+		return deleteByID(tx, userGroupBucket, id)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpDeleteUserGroup,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// deleteOrgRoles deletes the roles and user groups of the organization.
+func (s *Service) deleteOrgRoles(ctx context.Context, tx Tx, orgID influxdb.ID) error {
+	gs, err := s.findUserGroups(ctx, tx, influxdb.UserGroupFilter{OrgID: &orgID})
+	if err != nil {
+		return err
+	}
+	for _, g := range gs {
+		if err := deleteByID(tx, userGroupBucket, g.ID); err != nil {
+			return err
+		}
+	}
+
+	rs, err := s.findRoles(ctx, tx, influxdb.RoleFilter{OrgID: &orgID})
+	if err != nil {
+		return err
+	}
+	for _, r := range rs {
+		if err := deleteByID(tx, roleBucket, r.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getByID(tx Tx, bucket []byte, id influxdb.ID, v interface{}) error {
+	key, err := id.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(bucket)
+	if err != nil {
+		return err
+	}
+
+	data, err := b.Get(key)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return nil
+}
+
+func putByID(tx Tx, bucket []byte, id influxdb.ID, v interface{}) error {
+	key, err := id.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(bucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(key, data)
+}
+
+func deleteByID(tx Tx, bucket []byte, id influxdb.ID) error {
+	key, err := id.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(bucket)
+	if err != nil {
+		return err
+	}
+	return b.Delete(key)
+}
+
+func forEach(tx Tx, bucket []byte, fn func(v []byte) error) error {
+	b, err := tx.Bucket(bucket)
+	if err != nil {
+		return err
+	}
+
+	cur, err := b.Cursor()
+	if err != nil {
+		return err
+	}
+	for k, v := cur.First(); k != nil; k, v = cur.Next() {
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
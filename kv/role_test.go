@@ -0,0 +1,127 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestBoltRoleService(t *testing.T) {
+	s, closeBolt, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeBolt()
+
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing role service: %v", err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	other := &influxdb.Organization{Name: "other"}
+	if err := svc.CreateOrganization(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+	user := &influxdb.User{Name: "sre"}
+	if err := svc.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+
+	perms := []influxdb.Permission{
+		{Action: influxdb.WriteAction, Resource: influxdb.Resource{Type: influxdb.TasksResourceType, OrgID: &org.ID}},
+		{Action: influxdb.ReadAction, Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: &org.ID}},
+	}
+	role := &influxdb.Role{OrgID: org.ID, Name: "sre", Permissions: perms, Users: []influxdb.ID{user.ID}}
+	if err := svc.CreateRole(ctx, role); err != nil {
+		t.Fatal(err)
+	}
+
+	err = svc.CreateRole(ctx, &influxdb.Role{OrgID: org.ID, Name: "sre"})
+	if code := influxdb.ErrorCode(err); code != influxdb.EConflict {
+		t.Fatalf("expected conflict on duplicate name, got %v", err)
+	}
+	err = svc.CreateRole(ctx, &influxdb.Role{OrgID: other.ID, Name: "escalate", Permissions: perms})
+	if code := influxdb.ErrorCode(err); code != influxdb.EInvalid {
+		t.Fatalf("expected invalid permission outside of the org, got %v", err)
+	}
+	err = svc.CreateRole(ctx, &influxdb.Role{OrgID: org.ID, Name: "ghost", Users: []influxdb.ID{influxdb.ID(1)}})
+	if code := influxdb.ErrorCode(err); code != influxdb.ENotFound {
+		t.Fatalf("expected user not found, got %v", err)
+	}
+
+	rs, _, err := svc.FindRoles(ctx, influxdb.RoleFilter{UserID: &user.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs) != 1 || rs[0].ID != role.ID {
+		t.Fatalf("expected the role of the user, got %v", rs)
+	}
+
+	desc := "site reliability"
+	updated, err := svc.UpdateRole(ctx, role.ID, influxdb.RoleUpdate{Description: &desc})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Description != desc || len(updated.Permissions) != 2 {
+		t.Fatalf("unexpected updated role %v", updated)
+	}
+
+	group := &influxdb.UserGroup{OrgID: org.ID, Name: "oncall", Members: []influxdb.ID{user.ID}, Roles: []influxdb.ID{role.ID}}
+	if err := svc.CreateUserGroup(ctx, group); err != nil {
+		t.Fatal(err)
+	}
+	err = svc.CreateUserGroup(ctx, &influxdb.UserGroup{OrgID: other.ID, Name: "oncall", Roles: []influxdb.ID{role.ID}})
+	if code := influxdb.ErrorCode(err); code != influxdb.EInvalid {
+		t.Fatalf("expected invalid role of another org, got %v", err)
+	}
+
+	gs, _, err := svc.FindUserGroups(ctx, influxdb.UserGroupFilter{UserID: &user.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gs) != 1 || gs[0].ID != group.ID {
+		t.Fatalf("expected the group of the user, got %v", gs)
+	}
+
+	// Deleting a role removes it from the groups it is assigned to.
+	if err := svc.DeleteRole(ctx, role.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindRoleByID(ctx, role.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected role not found, got %v", err)
+	}
+	g, err := svc.FindUserGroupByID(ctx, group.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Roles) != 0 {
+		t.Fatalf("expected deleted role to be removed from group, got %v", g.Roles)
+	}
+
+	// The roles and groups are deleted along with the organization.
+	if err := svc.CreateRole(ctx, &influxdb.Role{OrgID: org.ID, Name: "readers"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.DeleteOrganization(ctx, org.ID); err != nil {
+		t.Fatal(err)
+	}
+	rs, _, err = svc.FindRoles(ctx, influxdb.RoleFilter{OrgID: &org.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs, _, err = svc.FindUserGroups(ctx, influxdb.UserGroupFilter{OrgID: &org.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs) != 0 || len(gs) != 0 {
+		t.Fatalf("roles and groups were not deleted with the org: %v %v", rs, gs)
+	}
+}
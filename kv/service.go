@@ -30,6 +30,10 @@ type Service struct {
 	audit       resource.Logger
 	IDGenerator influxdb.IDGenerator
 
+	// passwordPolicy is Config.PasswordPolicy, or the default policy
+	// when none is configured.
+	passwordPolicy influxdb.PasswordPolicy
+
 	// special ID generator that never returns bytes with backslash,
 	// comma, or space. Used to support very specific encoding of org &
 	// bucket into the old measurement in storage.
@@ -99,6 +103,60 @@ func NewService(log *zap.Logger, kv Store, configs ...ServiceConfig) *Service {
 		),
 		// add index user resource mappings by user id
 		s.urmByUserIndex.Migration(),
+		// add bucket for the query limits of organizations
+		NewAnonymousMigration(
+			"create org limits bucket",
+			s.initializeOrgLimits,
+			// down is a noop
+			func(context.Context, Store) error {
+				return nil
+			},
+		),
+		// replace the tokens of authorizations with their hashes
+		NewAnonymousMigration(
+			"hash authorization tokens",
+			s.hashAuthorizationTokens,
+			// down is a noop, as the tokens cannot be recovered
+			func(context.Context, Store) error {
+				return nil
+			},
+		),
+		// add buckets for roles and user groups
+		NewAnonymousMigration(
+			"create roles and user groups buckets",
+			s.initializeRoles,
+			// down is a noop
+			func(context.Context, Store) error {
+				return nil
+			},
+		),
+		// add bucket for the audit log
+		NewAnonymousMigration(
+			"create audit log bucket",
+			s.initializeAuditLog,
+			// down is a noop
+			func(context.Context, Store) error {
+				return nil
+			},
+		),
+		// add bucket for the failed sign-ins of users
+		NewAnonymousMigration(
+			"create login attempts bucket",
+			s.initializeLoginAttempts,
+			// down is a noop
+			func(context.Context, Store) error {
+				return nil
+			},
+		),
+		// add the indexes of the audit log by ID and by org
+		NewAnonymousMigration(
+			"index audit log by id and org",
+			s.indexAuditLog,
+			// down is a noop
+			func(context.Context, Store) error {
+				return nil
+			},
+		),
 		// and new migrations below here (and move this comment down):
 	)
 
@@ -110,6 +168,11 @@ func NewService(log *zap.Logger, kv Store, configs ...ServiceConfig) *Service {
 		s.Config.SessionLength = influxdb.DefaultSessionLength
 	}
 
+	s.passwordPolicy = s.Config.PasswordPolicy
+	if s.passwordPolicy == (influxdb.PasswordPolicy{}) {
+		s.passwordPolicy = influxdb.DefaultPasswordPolicy
+	}
+
 	s.clock = s.Config.Clock
 	if s.clock == nil {
 		s.clock = clock.New()
@@ -122,6 +185,11 @@ func NewService(log *zap.Logger, kv Store, configs ...ServiceConfig) *Service {
 type ServiceConfig struct {
 	SessionLength time.Duration
 	Clock         clock.Clock
+
+	// PasswordPolicy is the policy of the passwords of users, and
+	// LockoutPolicy the one that locks users out after failed sign-ins.
+	PasswordPolicy influxdb.PasswordPolicy
+	LockoutPolicy  influxdb.LockoutPolicy
 }
 
 // AutoMigrationStore is a Store which also describes whether or not
@@ -208,6 +208,49 @@ func (s *Service) ExpireSession(ctx context.Context, key string) error {
 	})
 }
 
+// ExpireUserSessions expires the sessions of the user that have not expired
+// yet.
+func (s *Service) ExpireUserSessions(ctx context.Context, userID influxdb.ID) error {
+	return s.kv.Update(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(sessionBucket)
+		if err != nil {
+			return err
+		}
+		cur, err := b.ForwardCursor(nil)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		var sns []*influxdb.Session
+		for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+			sn := &influxdb.Session{}
+			if err := json.Unmarshal(v, sn); err != nil {
+				cur.Close()
+				return &influxdb.Error{
+					Err: err,
+				}
+			}
+			if sn.UserID == userID && sn.ExpiresAt.After(now) {
+				sns = append(sns, sn)
+			}
+		}
+		if err := cur.Err(); err != nil {
+			cur.Close()
+			return err
+		}
+		cur.Close()
+
+		for _, sn := range sns {
+			sn.ExpiresAt = now
+			if err := s.putSession(ctx, tx, sn); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 // CreateSession creates a session for a user with the users maximal privileges.
 func (s *Service) CreateSession(ctx context.Context, user string) (*influxdb.Session, error) {
 	var sess *influxdb.Session
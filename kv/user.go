@@ -463,7 +463,7 @@ func (s *Service) deleteUsersAuthorizations(ctx context.Context, tx Tx, id influ
 		return err
 	}
 	for _, a := range as {
-		if err := s.deleteAuthorization(ctx, tx, a.ID); err != nil {
+		if err := DeleteAuthorizationRecord(ctx, tx, a.ID); err != nil {
 			return err
 		}
 	}
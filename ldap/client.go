@@ -0,0 +1,281 @@
+package ldap
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	goldap "github.com/go-ldap/ldap/v3"
+)
+
+// Defaults of the Config.
+const (
+	DefaultUserFilter           = "(uid=%s)"
+	DefaultUserNameAttribute    = "uid"
+	DefaultGroupFilter          = "(&(objectClass=groupOfNames)(cn=%s))"
+	DefaultGroupMemberAttribute = "member"
+	DefaultTimeout              = 10 * time.Second
+)
+
+// Config configures the connection to the directory and the searches of
+// its users and groups.
+type Config struct {
+	// URL is the ldap:// or ldaps:// URL of the server.
+	URL string
+	// StartTLS upgrades ldap:// connections to TLS.
+	StartTLS bool
+	// InsecureSkipVerify skips the verification of the certificate of the server.
+	InsecureSkipVerify bool
+	// CAFile is the PEM file of the certificate authorities that verify the
+	// certificate of the server, rather than those of the system.
+	CAFile string
+
+	// BindDN and BindPassword are the credentials the directory is searched
+	// with. The searches are anonymous if BindDN is empty.
+	BindDN       string
+	BindPassword string
+
+	// UserBaseDN is the DN the users are searched under.
+	UserBaseDN string
+	// UserFilter finds a user by name, with %s replaced by the name.
+	UserFilter string
+	// UserNameAttribute is the attribute of the names of the users.
+	UserNameAttribute string
+
+	// GroupBaseDN is the DN the groups are searched under.
+	GroupBaseDN string
+	// GroupFilter finds a group by name, with %s replaced by the name.
+	GroupFilter string
+	// GroupMemberAttribute is the attribute of the members of the groups,
+	// either the DNs or the names of the users.
+	GroupMemberAttribute string
+
+	// Timeout bounds connecting to and each request to the server.
+	Timeout time.Duration
+}
+
+// Client is the Directory of an LDAP server.
+type Client struct {
+	config    Config
+	tlsConfig *tls.Config
+}
+
+var _ Directory = (*Client)(nil)
+
+// NewClient returns a Client of the server of the config.
+func NewClient(config Config) (*Client, error) {
+	if config.URL == "" {
+		return nil, errors.New("ldap url is required")
+	}
+	if config.UserFilter == "" {
+		config.UserFilter = DefaultUserFilter
+	}
+	if config.UserNameAttribute == "" {
+		config.UserNameAttribute = DefaultUserNameAttribute
+	}
+	if config.GroupFilter == "" {
+		config.GroupFilter = DefaultGroupFilter
+	}
+	if config.GroupMemberAttribute == "" {
+		config.GroupMemberAttribute = DefaultGroupMemberAttribute
+	}
+	if config.Timeout == 0 {
+		config.Timeout = DefaultTimeout
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: config.InsecureSkipVerify}
+	if config.CAFile != "" {
+		pem, err := ioutil.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read ldap ca file: %v", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in ldap ca file %s", config.CAFile)
+		}
+	}
+
+	u, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ldap url: %v", err)
+	}
+	// The certificate of the server is verified against its host, which
+	// StartTLS does not infer from the connection.
+	tlsConfig.ServerName = u.Hostname()
+
+	return &Client{config: config, tlsConfig: tlsConfig}, nil
+}
+
+// dial connects to the server and binds with the credentials of the config.
+// The connection is closed when ctx is done, which aborts the request in
+// progress, and once the returned function is called.
+func (c *Client) dial(ctx context.Context) (*goldap.Conn, func(), error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	timeout := c.config.Timeout
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < timeout {
+		timeout = time.Until(deadline)
+	}
+
+	conn, err := goldap.DialURL(c.config.URL,
+		goldap.DialWithDialer(&net.Dialer{Timeout: timeout}),
+		goldap.DialWithTLSConfig(c.tlsConfig),
+	)
+	if err != nil {
+		return nil, nil, ctxErr(ctx, err)
+	}
+	conn.SetTimeout(timeout)
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+	closeConn := func() {
+		close(done)
+		conn.Close()
+	}
+
+	if c.config.StartTLS {
+		if err := conn.StartTLS(c.tlsConfig); err != nil {
+			closeConn()
+			return nil, nil, ctxErr(ctx, err)
+		}
+	}
+	if c.config.BindDN != "" {
+		if err := conn.Bind(c.config.BindDN, c.config.BindPassword); err != nil {
+			closeConn()
+			return nil, nil, ctxErr(ctx, err)
+		}
+	}
+	return conn, closeConn, nil
+}
+
+// ctxErr returns the error of ctx if it is done, since the error of a
+// request aborted by closing its connection does not tell why.
+func ctxErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// FindUser returns the DN of the user with the name.
+func (c *Client) FindUser(ctx context.Context, name string) (string, error) {
+	conn, closeConn, err := c.dial(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer closeConn()
+
+	res, err := conn.Search(goldap.NewSearchRequest(
+		c.config.UserBaseDN, goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, 2, 0, false,
+		fmt.Sprintf(c.config.UserFilter, goldap.EscapeFilter(name)),
+		[]string{"1.1"}, nil,
+	))
+	if err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultNoSuchObject) {
+			return "", ErrUserNotFound
+		}
+		return "", ctxErr(ctx, err)
+	}
+	switch len(res.Entries) {
+	case 0:
+		return "", ErrUserNotFound
+	case 1:
+		return res.Entries[0].DN, nil
+	default:
+		return "", fmt.Errorf("found %d ldap users named %s", len(res.Entries), name)
+	}
+}
+
+// Authenticate binds as the user with the password.
+func (c *Client) Authenticate(ctx context.Context, dn, password string) error {
+	// Binds without a password are unauthenticated binds, which servers
+	// may accept.
+	if password == "" {
+		return EIncorrectPassword
+	}
+
+	conn, closeConn, err := c.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+
+	if err := conn.Bind(dn, password); err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultInvalidCredentials) {
+			return EIncorrectPassword
+		}
+		return ctxErr(ctx, err)
+	}
+	return nil
+}
+
+// GroupMembers returns the names of the users that are members of the group.
+// Members identified by their DN are looked up for their names.
+func (c *Client) GroupMembers(ctx context.Context, group string) ([]string, error) {
+	conn, closeConn, err := c.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer closeConn()
+
+	res, err := conn.Search(goldap.NewSearchRequest(
+		c.config.GroupBaseDN, goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, 0, 0, false,
+		fmt.Sprintf(c.config.GroupFilter, goldap.EscapeFilter(group)),
+		[]string{c.config.GroupMemberAttribute}, nil,
+	))
+	if err != nil {
+		return nil, ctxErr(ctx, err)
+	}
+
+	var names []string
+	for _, e := range res.Entries {
+		for _, member := range e.GetAttributeValues(c.config.GroupMemberAttribute) {
+			if !strings.Contains(member, "=") {
+				names = append(names, member)
+				continue
+			}
+
+			name, err := c.userName(conn, member)
+			if err != nil {
+				return nil, ctxErr(ctx, err)
+			}
+			if name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names, nil
+}
+
+// userName returns the name of the user with the DN, or an empty name if
+// the entry does not exist or has no name.
+func (c *Client) userName(conn *goldap.Conn, dn string) (string, error) {
+	res, err := conn.Search(goldap.NewSearchRequest(
+		dn, goldap.ScopeBaseObject, goldap.NeverDerefAliases, 1, 0, false,
+		"(objectClass=*)", []string{c.config.UserNameAttribute}, nil,
+	))
+	if err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultNoSuchObject) {
+			return "", nil
+		}
+		return "", err
+	}
+	if len(res.Entries) == 0 {
+		return "", nil
+	}
+	return res.Entries[0].GetAttributeValue(c.config.UserNameAttribute), nil
+}
@@ -0,0 +1,49 @@
+package ldap_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/ldap"
+)
+
+func TestClient_Context(t *testing.T) {
+	// The server accepts connections but never answers.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	c, err := ldap.NewClient(ldap.Config{URL: "ldap://" + ln.Addr().String(), BindDN: "cn=admin", BindPassword: "password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := c.FindUser(ctx, "marty"); err != context.DeadlineExceeded {
+		t.Fatalf("expected the deadline of the context to be exceeded, got %v", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Fatalf("expected the request to be aborted with the context, took %s", d)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.GroupMembers(ctx, "engineering"); err != context.Canceled {
+		t.Fatalf("expected the context to be canceled, got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+// Package ldap authenticates users against an LDAP directory and keeps the
+// org memberships of the users in sync with the groups of the directory.
+package ldap
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// OAuthIDPrefix prefixes the OAuthID of the users created from the
+// directory. The org memberships of these users are managed by the Syncer.
+const OAuthIDPrefix = "ldap:"
+
+var (
+	// ErrUserNotFound is returned when the user is not in the directory.
+	ErrUserNotFound = &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  "user not found in directory",
+	}
+
+	// EIncorrectPassword is returned when the directory rejects the password
+	// of the user.
+	EIncorrectPassword = &influxdb.Error{
+		Code: influxdb.EForbidden,
+		Msg:  "your username or password is incorrect",
+	}
+
+	// EPasswordManaged is returned when setting the password of a user whose
+	// password is managed by the directory.
+	EPasswordManaged = &influxdb.Error{
+		Code: influxdb.EMethodNotAllowed,
+		Msg:  "password is managed by the LDAP directory",
+	}
+)
+
+// Directory is the LDAP directory of the users and groups.
+type Directory interface {
+	// FindUser returns the DN of the user with the name, or ErrUserNotFound.
+	FindUser(ctx context.Context, name string) (string, error)
+	// Authenticate checks the password of the user with the DN.
+	Authenticate(ctx context.Context, dn, password string) error
+	// GroupMembers returns the names of the users that are members of the group.
+	GroupMembers(ctx context.Context, group string) ([]string, error)
+}
+
+// GroupMapping gives the members of an LDAP group a role in an org.
+type GroupMapping struct {
+	Group string
+	Org   string
+	Role  influxdb.UserType
+}
+
+// ParseGroupMappings parses group=org[:role] mappings, where the role is
+// owner or member and defaults to member.
+func ParseGroupMappings(groupOrgs map[string]string) ([]GroupMapping, error) {
+	mappings := make([]GroupMapping, 0, len(groupOrgs))
+	for group, v := range groupOrgs {
+		m := GroupMapping{Group: group, Org: v, Role: influxdb.Member}
+		if i := strings.LastIndex(v, ":"); i >= 0 {
+			m.Org, m.Role = v[:i], influxdb.UserType(v[i+1:])
+		}
+		if m.Group == "" || m.Org == "" {
+			return nil, fmt.Errorf("invalid ldap group mapping %s=%s", group, v)
+		}
+		if m.Role != influxdb.Owner && m.Role != influxdb.Member {
+			return nil, fmt.Errorf("invalid role %q of ldap group %s, must be %s or %s", m.Role, group, influxdb.Owner, influxdb.Member)
+		}
+		mappings = append(mappings, m)
+	}
+	return mappings, nil
+}
+
+// IsManaged returns whether the user was created from the directory.
+func IsManaged(u *influxdb.User) bool {
+	return strings.HasPrefix(u.OAuthID, OAuthIDPrefix)
+}
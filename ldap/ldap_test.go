@@ -0,0 +1,83 @@
+package ldap_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/ldap"
+	"github.com/influxdata/influxdb/v2/tenant"
+)
+
+// mockDirectory is a directory of users by name, with the passwords of
+// the users and the members of the groups.
+type mockDirectory struct {
+	passwords map[string]string
+	groups    map[string][]string
+	err       error
+}
+
+func (d *mockDirectory) FindUser(ctx context.Context, name string) (string, error) {
+	if d.err != nil {
+		return "", d.err
+	}
+	if _, ok := d.passwords[name]; !ok {
+		return "", ldap.ErrUserNotFound
+	}
+	return "uid=" + name + ",ou=users,dc=example,dc=com", nil
+}
+
+func (d *mockDirectory) Authenticate(ctx context.Context, dn, password string) error {
+	for name, p := range d.passwords {
+		if dn == "uid="+name+",ou=users,dc=example,dc=com" && p == password {
+			return nil
+		}
+	}
+	return ldap.EIncorrectPassword
+}
+
+func (d *mockDirectory) GroupMembers(ctx context.Context, group string) ([]string, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	return d.groups[group], nil
+}
+
+func newTenantService(t *testing.T) influxdb.TenantService {
+	t.Helper()
+	store, err := tenant.NewStore(inmem.NewKVStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tenant.NewService(store)
+}
+
+func TestParseGroupMappings(t *testing.T) {
+	mappings, err := ldap.ParseGroupMappings(map[string]string{
+		"engineering": "acme",
+		"admins":      "acme:owner",
+		"support":     "help:desk:member",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Slice(mappings, func(i, j int) bool { return mappings[i].Group < mappings[j].Group })
+
+	want := []ldap.GroupMapping{
+		{Group: "admins", Org: "acme", Role: influxdb.Owner},
+		{Group: "engineering", Org: "acme", Role: influxdb.Member},
+		{Group: "support", Org: "help:desk", Role: influxdb.Member},
+	}
+	if diff := cmp.Diff(want, mappings); diff != "" {
+		t.Errorf("unexpected mappings -want/+got:\n%s", diff)
+	}
+
+	for _, v := range []string{"", ":owner", "acme:admin"} {
+		if _, err := ldap.ParseGroupMappings(map[string]string{"engineering": v}); err == nil {
+			t.Errorf("expected an error for mapping to %q", v)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package ldap
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"go.uber.org/zap"
+)
+
+var _ influxdb.PasswordsService = (*PasswordsService)(nil)
+
+// PasswordsService checks the passwords of the users created from the
+// directory by binding as them. The passwords of all other users are left to
+// the next PasswordsService, even if the directory has a user of the same
+// name, so that local users such as the one of the setup keep signing in.
+type PasswordsService struct {
+	log   *zap.Logger
+	dir   Directory
+	users influxdb.UserService
+	next  influxdb.PasswordsService
+}
+
+// NewPasswordsService returns a PasswordsService of the directory.
+func NewPasswordsService(log *zap.Logger, dir Directory, users influxdb.UserService, next influxdb.PasswordsService) *PasswordsService {
+	return &PasswordsService{
+		log:   log,
+		dir:   dir,
+		users: users,
+		next:  next,
+	}
+}
+
+// SetPassword sets the password of users that were not created from the
+// directory.
+func (s *PasswordsService) SetPassword(ctx context.Context, userID influxdb.ID, password string) error {
+	if _, ok, err := s.findUser(ctx, userID); err != nil {
+		return err
+	} else if ok {
+		return EPasswordManaged
+	}
+	return s.next.SetPassword(ctx, userID, password)
+}
+
+// ComparePassword binds as the user if it was created from the directory,
+// and compares the local password otherwise. The users created from the
+// directory that were removed from it cannot sign in.
+func (s *PasswordsService) ComparePassword(ctx context.Context, userID influxdb.ID, password string) error {
+	dn, ok, err := s.findUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return s.next.ComparePassword(ctx, userID, password)
+	}
+	return s.dir.Authenticate(ctx, dn, password)
+}
+
+// CompareAndSetPassword sets the password of users that were not created
+// from the directory.
+func (s *PasswordsService) CompareAndSetPassword(ctx context.Context, userID influxdb.ID, old, new string) error {
+	if _, ok, err := s.findUser(ctx, userID); err != nil {
+		return err
+	} else if ok {
+		return EPasswordManaged
+	}
+	return s.next.CompareAndSetPassword(ctx, userID, old, new)
+}
+
+// findUser returns the DN of the user and whether its password is managed
+// by the directory, which is only the case for the users created from it.
+func (s *PasswordsService) findUser(ctx context.Context, userID influxdb.ID) (string, bool, error) {
+	u, err := s.users.FindUserByID(ctx, userID)
+	if err != nil {
+		return "", false, err
+	}
+	if !IsManaged(u) {
+		return "", false, nil
+	}
+
+	// Users created from the directory only sign in with it, whether they
+	// were removed from it or it is unavailable.
+	dn, err := s.dir.FindUser(ctx, u.Name)
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		s.log.Info("Ldap user not found in directory", zap.String("user", u.Name))
+		return "", false, EIncorrectPassword
+	} else if err != nil {
+		return "", false, err
+	}
+	return dn, true, nil
+}
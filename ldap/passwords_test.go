@@ -0,0 +1,69 @@
+package ldap_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/ldap"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestPasswordsService(t *testing.T) {
+	ctx := context.Background()
+	ts := newTenantService(t)
+	dir := &mockDirectory{passwords: map[string]string{"marty": "flux-capacitor", "admin": "tannen"}}
+	svc := ldap.NewPasswordsService(zaptest.NewLogger(t), dir, ts, ts)
+
+	marty := &influxdb.User{Name: "marty", OAuthID: ldap.OAuthIDPrefix + "marty", Status: influxdb.Active}
+	admin := &influxdb.User{Name: "admin", Status: influxdb.Active}
+	biff := &influxdb.User{Name: "biff", OAuthID: ldap.OAuthIDPrefix + "biff", Status: influxdb.Active}
+	for _, u := range []*influxdb.User{marty, admin, biff} {
+		if err := ts.CreateUser(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Directory users sign in with the passwords of the directory.
+	if err := svc.ComparePassword(ctx, marty.ID, "flux-capacitor"); err != nil {
+		t.Errorf("unexpected error comparing directory password: %v", err)
+	}
+	if err := svc.ComparePassword(ctx, marty.ID, "delorean"); influxdb.ErrorCode(err) != influxdb.EForbidden {
+		t.Errorf("expected forbidden comparing wrong directory password, got %v", err)
+	}
+	if err := svc.SetPassword(ctx, marty.ID, "delorean1"); err != ldap.EPasswordManaged {
+		t.Errorf("expected managed password error, got %v", err)
+	}
+	if err := svc.CompareAndSetPassword(ctx, marty.ID, "flux-capacitor", "delorean1"); err != ldap.EPasswordManaged {
+		t.Errorf("expected managed password error, got %v", err)
+	}
+
+	// Local users keep their local passwords, even if the directory has a
+	// user of the same name.
+	if err := svc.SetPassword(ctx, admin.ID, "password1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.ComparePassword(ctx, admin.ID, "password1"); err != nil {
+		t.Errorf("unexpected error comparing local password: %v", err)
+	}
+	if err := svc.ComparePassword(ctx, admin.ID, "tannen"); influxdb.ErrorCode(err) != influxdb.EForbidden {
+		t.Errorf("expected forbidden comparing the directory password of a local user, got %v", err)
+	}
+
+	// Users created from the directory that were removed from it cannot
+	// sign in.
+	if err := svc.ComparePassword(ctx, biff.ID, ""); influxdb.ErrorCode(err) != influxdb.EForbidden {
+		t.Errorf("expected forbidden for user removed from directory, got %v", err)
+	}
+
+	// Local users sign in while the directory is unavailable, but not the
+	// users of the directory.
+	dir.err = errors.New("connection refused")
+	if err := svc.ComparePassword(ctx, admin.ID, "password1"); err != nil {
+		t.Errorf("unexpected error comparing local password with directory down: %v", err)
+	}
+	if err := svc.ComparePassword(ctx, marty.ID, "flux-capacitor"); err == nil {
+		t.Error("expected an error for directory user with directory down")
+	}
+}
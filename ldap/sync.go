@@ -0,0 +1,259 @@
+package ldap
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"go.uber.org/zap"
+)
+
+// UserSessionExpirer expires the sessions of users.
+type UserSessionExpirer interface {
+	ExpireUserSessions(ctx context.Context, userID influxdb.ID) error
+}
+
+// Syncer makes the members of the groups of the directory members or owners
+// of the orgs of their groups. Users are created when their groups are first
+// synced, and the users created from the directory lose the roles of the
+// groups they were removed from, along with their tokens in the orgs of those
+// groups. Users removed from all of the groups are set inactive and their
+// sessions are expired, until they are added to a group again. Local users of
+// the same names as members of the groups are left alone.
+type Syncer struct {
+	log      *zap.Logger
+	dir      Directory
+	mappings []GroupMapping
+
+	UserService                influxdb.UserService
+	OrganizationService        influxdb.OrganizationService
+	UserResourceMappingService influxdb.UserResourceMappingService
+	AuthorizationService       influxdb.AuthorizationService
+	SessionExpirer             UserSessionExpirer
+}
+
+// NewSyncer returns a Syncer of the group mappings.
+func NewSyncer(log *zap.Logger, dir Directory, mappings []GroupMapping, users influxdb.UserService, orgs influxdb.OrganizationService, urms influxdb.UserResourceMappingService, auths influxdb.AuthorizationService, sessions UserSessionExpirer) *Syncer {
+	return &Syncer{
+		log:      log,
+		dir:      dir,
+		mappings: mappings,
+
+		UserService:                users,
+		OrganizationService:        orgs,
+		UserResourceMappingService: urms,
+		AuthorizationService:       auths,
+		SessionExpirer:             sessions,
+	}
+}
+
+// Run syncs the groups now and then every interval until ctx is done.
+func (s *Syncer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Sync(ctx); err != nil && ctx.Err() == nil {
+			s.log.Error("Failed to sync ldap groups", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync gives the members of the groups their roles in the orgs. Nothing is
+// removed if any group cannot be read from the directory.
+func (s *Syncer) Sync(ctx context.Context) error {
+	// roles are the roles of the users by name in each org.
+	roles := make(map[influxdb.ID]map[string]influxdb.UserType)
+	for _, m := range s.mappings {
+		org, err := s.OrganizationService.FindOrganization(ctx, influxdb.OrganizationFilter{Name: &m.Org})
+		if err != nil {
+			if influxdb.ErrorCode(err) == influxdb.ENotFound {
+				s.log.Warn("Organization of ldap group not found", zap.String("group", m.Group), zap.String("org", m.Org))
+				continue
+			}
+			return err
+		}
+
+		members, err := s.dir.GroupMembers(ctx, m.Group)
+		if err != nil {
+			return err
+		}
+
+		if roles[org.ID] == nil {
+			roles[org.ID] = make(map[string]influxdb.UserType)
+		}
+		for _, name := range members {
+			if roles[org.ID][name] != influxdb.Owner {
+				roles[org.ID][name] = m.Role
+			}
+		}
+	}
+
+	// removed are the users removed from the groups of an org, and synced
+	// those that are members of a group of any org.
+	removed := make(map[influxdb.ID]*influxdb.User)
+	synced := make(map[influxdb.ID]bool)
+	for orgID, users := range roles {
+		if err := s.syncOrg(ctx, orgID, users, synced, removed); err != nil {
+			return err
+		}
+	}
+
+	for userID, u := range removed {
+		if synced[userID] {
+			continue
+		}
+		if err := s.deactivateUser(ctx, u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncOrg gives the users their roles in the org, and removes the roles and
+// tokens in the org of the other users created from the directory. The
+// users are added to synced and the other users to removed.
+func (s *Syncer) syncOrg(ctx context.Context, orgID influxdb.ID, roles map[string]influxdb.UserType, synced map[influxdb.ID]bool, removed map[influxdb.ID]*influxdb.User) error {
+	urms, _, err := s.UserResourceMappingService.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+		ResourceID:   orgID,
+		ResourceType: influxdb.OrgsResourceType,
+	})
+	if err != nil {
+		return err
+	}
+	current := make(map[influxdb.ID]*influxdb.UserResourceMapping, len(urms))
+	for _, urm := range urms {
+		current[urm.UserID] = urm
+	}
+
+	inOrg := make(map[influxdb.ID]bool, len(roles))
+	for name, role := range roles {
+		u, err := s.findOrCreateUser(ctx, name)
+		if err != nil {
+			return err
+		}
+		if !IsManaged(u) {
+			s.log.Warn("Skipped ldap group member of the name of a local user", zap.String("user", name), zap.Stringer("orgID", orgID))
+			continue
+		}
+		inOrg[u.ID] = true
+		synced[u.ID] = true
+
+		if urm, ok := current[u.ID]; ok {
+			if urm.UserType == role {
+				continue
+			}
+			if err := s.UserResourceMappingService.DeleteUserResourceMapping(ctx, orgID, u.ID); err != nil {
+				return err
+			}
+		}
+		if err := s.UserResourceMappingService.CreateUserResourceMapping(ctx, &influxdb.UserResourceMapping{
+			ResourceID:   orgID,
+			ResourceType: influxdb.OrgsResourceType,
+			UserID:       u.ID,
+			UserType:     role,
+		}); err != nil {
+			return err
+		}
+		s.log.Info("Synced ldap user role", zap.String("user", name), zap.Stringer("orgID", orgID), zap.String("role", string(role)))
+	}
+
+	for userID := range current {
+		if inOrg[userID] {
+			continue
+		}
+		u, err := s.UserService.FindUserByID(ctx, userID)
+		if err != nil {
+			if influxdb.ErrorCode(err) == influxdb.ENotFound {
+				continue
+			}
+			return err
+		}
+		if !IsManaged(u) {
+			continue
+		}
+		if err := s.UserResourceMappingService.DeleteUserResourceMapping(ctx, orgID, userID); err != nil {
+			return err
+		}
+		if err := s.deactivateAuthorizations(ctx, u.ID, orgID); err != nil {
+			return err
+		}
+		removed[userID] = u
+		s.log.Info("Removed ldap user role", zap.String("user", u.Name), zap.Stringer("orgID", orgID))
+	}
+	return nil
+}
+
+// deactivateAuthorizations sets the tokens of the user in the org inactive.
+func (s *Syncer) deactivateAuthorizations(ctx context.Context, userID, orgID influxdb.ID) error {
+	as, _, err := s.AuthorizationService.FindAuthorizations(ctx, influxdb.AuthorizationFilter{
+		UserID: &userID,
+		OrgID:  &orgID,
+	})
+	if err != nil {
+		return err
+	}
+	inactive := influxdb.Inactive
+	for _, a := range as {
+		if a.Status == influxdb.Inactive {
+			continue
+		}
+		if _, err := s.AuthorizationService.UpdateAuthorization(ctx, a.ID, &influxdb.AuthorizationUpdate{Status: &inactive}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deactivateUser sets the user inactive and expires its sessions.
+func (s *Syncer) deactivateUser(ctx context.Context, u *influxdb.User) error {
+	if u.Status != influxdb.Inactive {
+		inactive := influxdb.Inactive
+		if _, err := s.UserService.UpdateUser(ctx, u.ID, influxdb.UserUpdate{Status: &inactive}); err != nil {
+			return err
+		}
+	}
+	if err := s.SessionExpirer.ExpireUserSessions(ctx, u.ID); err != nil {
+		return err
+	}
+	s.log.Info("Deactivated ldap user", zap.String("user", u.Name))
+	return nil
+}
+
+func (s *Syncer) findOrCreateUser(ctx context.Context, name string) (*influxdb.User, error) {
+	u, err := s.UserService.FindUser(ctx, influxdb.UserFilter{Name: &name})
+	if err == nil {
+		return s.activateUser(ctx, u)
+	}
+	if influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return nil, err
+	}
+
+	u = &influxdb.User{Name: name, OAuthID: OAuthIDPrefix + name, Status: influxdb.Active}
+	if err := s.UserService.CreateUser(ctx, u); err != nil {
+		return nil, err
+	}
+	s.log.Info("Created ldap user", zap.String("user", name), zap.Stringer("userID", u.ID))
+	return u, nil
+}
+
+// activateUser sets a user created from the directory active again, once
+// it is added back to a group.
+func (s *Syncer) activateUser(ctx context.Context, u *influxdb.User) (*influxdb.User, error) {
+	if !IsManaged(u) || u.Status != influxdb.Inactive {
+		return u, nil
+	}
+	active := influxdb.Active
+	u, err := s.UserService.UpdateUser(ctx, u.ID, influxdb.UserUpdate{Status: &active})
+	if err != nil {
+		return nil, err
+	}
+	s.log.Info("Activated ldap user", zap.String("user", u.Name))
+	return u, nil
+}
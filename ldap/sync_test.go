@@ -0,0 +1,225 @@
+package ldap_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/ldap"
+	"go.uber.org/zap/zaptest"
+)
+
+func newKVService(t *testing.T) *kv.Service {
+	t.Helper()
+	svc := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	if err := svc.Initialize(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return svc
+}
+
+func TestSyncer_Sync(t *testing.T) {
+	ctx := context.Background()
+	ts := newKVService(t)
+
+	org := &influxdb.Organization{Name: "acme"}
+	if err := ts.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	admin := &influxdb.User{Name: "admin", Status: influxdb.Active}
+	if err := ts.CreateUser(ctx, admin); err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.CreateUserResourceMapping(ctx, &influxdb.UserResourceMapping{
+		ResourceID:   org.ID,
+		ResourceType: influxdb.OrgsResourceType,
+		UserID:       admin.ID,
+		UserType:     influxdb.Owner,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// biff is a local user, whose roles are not managed by the directory.
+	biff := &influxdb.User{Name: "biff", Status: influxdb.Active}
+	if err := ts.CreateUser(ctx, biff); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := &mockDirectory{groups: map[string][]string{
+		"engineering": {"marty", "doc"},
+		"admins":      {"doc", "biff"},
+	}}
+	syncer := ldap.NewSyncer(zaptest.NewLogger(t), dir, []ldap.GroupMapping{
+		{Group: "engineering", Org: "acme", Role: influxdb.Member},
+		{Group: "admins", Org: "acme", Role: influxdb.Owner},
+		{Group: "engineering", Org: "missing", Role: influxdb.Member},
+	}, ts, ts, ts, ts, ts)
+
+	// roles returns the roles of the users in the org by name.
+	roles := func() map[string]influxdb.UserType {
+		t.Helper()
+		urms, _, err := ts.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+			ResourceID:   org.ID,
+			ResourceType: influxdb.OrgsResourceType,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		roles := make(map[string]influxdb.UserType, len(urms))
+		for _, urm := range urms {
+			u, err := ts.FindUserByID(ctx, urm.UserID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			roles[u.Name] = urm.UserType
+		}
+		return roles
+	}
+
+	if err := syncer.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]influxdb.UserType{"admin": influxdb.Owner, "marty": influxdb.Member, "doc": influxdb.Owner}
+	if diff := cmp.Diff(want, roles()); diff != "" {
+		t.Errorf("unexpected roles after first sync -want/+got:\n%s", diff)
+	}
+
+	name := "marty"
+	marty, err := ts.FindUser(ctx, influxdb.UserFilter{Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ldap.IsManaged(marty) {
+		t.Errorf("expected user created by sync to be managed, got oauthID %q", marty.OAuthID)
+	}
+
+	// Nothing is removed while the directory is unavailable.
+	dir.err = errors.New("connection refused")
+	if err := syncer.Sync(ctx); err == nil {
+		t.Error("expected an error syncing with directory down")
+	}
+	if diff := cmp.Diff(want, roles()); diff != "" {
+		t.Errorf("unexpected roles after failed sync -want/+got:\n%s", diff)
+	}
+	dir.err = nil
+
+	// Users removed from groups lose their roles, but not the local users.
+	dir.groups = map[string][]string{
+		"engineering": {"doc"},
+	}
+	if err := syncer.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	want = map[string]influxdb.UserType{"admin": influxdb.Owner, "doc": influxdb.Member}
+	if diff := cmp.Diff(want, roles()); diff != "" {
+		t.Errorf("unexpected roles after second sync -want/+got:\n%s", diff)
+	}
+}
+
+func TestSyncer_Sync_RemovedUser(t *testing.T) {
+	ctx := context.Background()
+	svc := newKVService(t)
+
+	acme := &influxdb.Organization{Name: "acme"}
+	if err := svc.CreateOrganization(ctx, acme); err != nil {
+		t.Fatal(err)
+	}
+	initech := &influxdb.Organization{Name: "initech"}
+	if err := svc.CreateOrganization(ctx, initech); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := &mockDirectory{groups: map[string][]string{
+		"engineering": {"marty"},
+		"support":     {"marty"},
+	}}
+	syncer := ldap.NewSyncer(zaptest.NewLogger(t), dir, []ldap.GroupMapping{
+		{Group: "engineering", Org: "acme", Role: influxdb.Member},
+		{Group: "support", Org: "initech", Role: influxdb.Member},
+	}, svc, svc, svc, svc, svc)
+	if err := syncer.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	name := "marty"
+	marty, err := svc.FindUser(ctx, influxdb.UserFilter{Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens := make(map[influxdb.ID]string)
+	for _, org := range []*influxdb.Organization{acme, initech} {
+		a := &influxdb.Authorization{
+			OrgID:       org.ID,
+			UserID:      marty.ID,
+			Permissions: influxdb.OperPermissions(),
+		}
+		if err := svc.CreateAuthorization(ctx, a); err != nil {
+			t.Fatal(err)
+		}
+		tokens[org.ID] = a.Token
+	}
+	sess, err := svc.CreateSession(ctx, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// allowed reports whether the token of marty in the org is allowed to
+	// read the org.
+	allowed := func(orgID influxdb.ID) bool {
+		t.Helper()
+		a, err := svc.FindAuthorizationByToken(ctx, tokens[orgID])
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := influxdb.NewPermissionAtID(orgID, influxdb.ReadAction, influxdb.OrgsResourceType, orgID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return a.Allowed(*p)
+	}
+
+	// A user removed from the group of one org loses its token in the org,
+	// but remains active.
+	dir.groups = map[string][]string{"support": {"marty"}}
+	if err := syncer.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if allowed(acme.ID) {
+		t.Error("expected the token in the org of the removed group to be rejected")
+	}
+	if !allowed(initech.ID) {
+		t.Error("expected the token in the org of the remaining group to be allowed")
+	}
+	if u, err := svc.FindUserByID(ctx, marty.ID); err != nil || u.Status != influxdb.Active {
+		t.Errorf("expected the user to remain active, got %+v (%v)", u, err)
+	}
+
+	// A user removed from all of the groups is set inactive, and its
+	// tokens and sessions are rejected.
+	dir.groups = map[string][]string{}
+	if err := syncer.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if allowed(initech.ID) {
+		t.Error("expected the token of the removed user to be rejected")
+	}
+	if u, err := svc.FindUserByID(ctx, marty.ID); err != nil || u.Status != influxdb.Inactive {
+		t.Errorf("expected the user to be inactive, got %+v (%v)", u, err)
+	}
+	if _, err := svc.FindSession(ctx, sess.Key); err == nil {
+		t.Error("expected the session of the removed user to be expired")
+	}
+
+	// The user is active again once added back to a group.
+	dir.groups = map[string][]string{"engineering": {"marty"}}
+	if err := syncer.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if u, err := svc.FindUserByID(ctx, marty.ID); err != nil || u.Status != influxdb.Active {
+		t.Errorf("expected the user to be active again, got %+v (%v)", u, err)
+	}
+}
@@ -0,0 +1,184 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ops for login errors.
+const (
+	OpFindLoginAttempts  = "FindLoginAttempts"
+	OpRecordLoginFailure = "RecordLoginFailure"
+	OpResetLoginAttempts = "ResetLoginAttempts"
+	OpEnrollTOTP         = "EnrollTOTP"
+	OpConfirmTOTP        = "ConfirmTOTP"
+	OpVerifyTOTP         = "VerifyTOTP"
+	OpResetTOTP          = "ResetTOTP"
+)
+
+// PasswordPolicy is the set of rules the passwords of users must follow.
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+// DefaultPasswordPolicy only requires passwords to be 8 characters long.
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength: 8,
+}
+
+// Validate returns an error if the password does not follow the policy.
+func (p PasswordPolicy) Validate(password string) error {
+	if len(password) < p.MinLength {
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("passwords must be at least %d characters long", p.MinLength),
+		}
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+
+	var missing []string
+	if p.RequireUpper && !upper {
+		missing = append(missing, "an uppercase letter")
+	}
+	if p.RequireLower && !lower {
+		missing = append(missing, "a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		missing = append(missing, "a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		missing = append(missing, "a symbol")
+	}
+	if len(missing) > 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "passwords must contain " + strings.Join(missing, ", "),
+		}
+	}
+	return nil
+}
+
+// LockoutPolicy is the policy that locks users out after failed sign-ins.
+type LockoutPolicy struct {
+	// MaxAttempts is the number of failed sign-ins after which a user is
+	// locked out. Users are never locked out if it is zero.
+	MaxAttempts int
+	// Duration is how long a user is locked out after MaxAttempts failed
+	// sign-ins. It doubles with each further failure, up to MaxDuration,
+	// if MaxDuration is longer.
+	Duration    time.Duration
+	MaxDuration time.Duration
+}
+
+// DefaultLockoutPolicy locks users out for a minute after 5 failed sign-ins,
+// and for up to an hour after further failures.
+var DefaultLockoutPolicy = LockoutPolicy{
+	MaxAttempts: 5,
+	Duration:    time.Minute,
+	MaxDuration: time.Hour,
+}
+
+// LockoutDuration returns how long a user is locked out after failures
+// failed sign-ins in a row.
+func (p LockoutPolicy) LockoutDuration(failures int) time.Duration {
+	if p.MaxAttempts <= 0 || failures < p.MaxAttempts {
+		return 0
+	}
+
+	d := p.Duration
+	for i := p.MaxAttempts; i < failures && d < p.MaxDuration; i++ {
+		d *= 2
+	}
+	if d > p.MaxDuration && p.MaxDuration > p.Duration {
+		d = p.MaxDuration
+	}
+	return d
+}
+
+// LoginAttempts are the failed sign-ins of a user since its last successful
+// sign-in.
+type LoginAttempts struct {
+	UserID      ID        `json:"userID"`
+	Failures    int       `json:"failures"`
+	LastFailure time.Time `json:"lastFailure,omitempty"`
+	LockedUntil time.Time `json:"lockedUntil,omitempty"`
+}
+
+// Locked returns true if the user is locked out at now.
+func (a *LoginAttempts) Locked(now time.Time) bool {
+	return now.Before(a.LockedUntil)
+}
+
+// ErrLockedOut is returned when a locked out user signs in.
+var ErrLockedOut = &Error{
+	Code: ETooManyRequests,
+	Msg:  "too many failed sign-ins, try again later",
+}
+
+// LockoutService tracks the failed sign-ins of users to lock them out.
+type LockoutService interface {
+	// FindLoginAttempts returns the failed sign-ins of the user.
+	FindLoginAttempts(ctx context.Context, userID ID) (*LoginAttempts, error)
+
+	// RecordLoginFailure records a failed sign-in of the user, and locks it
+	// out according to the lockout policy.
+	RecordLoginFailure(ctx context.Context, userID ID) (*LoginAttempts, error)
+
+	// ResetLoginAttempts clears the failed sign-ins of the user, which
+	// unlocks it.
+	ResetLoginAttempts(ctx context.Context, userID ID) error
+}
+
+// TOTPEnrollment is the secret of a time-based one-time password (TOTP)
+// enrollment, and the otpauth URL that authenticator apps read it from.
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URL    string `json:"url"`
+}
+
+// ErrTOTPRequired is returned when a user with TOTP enabled signs in
+// without a valid code.
+var ErrTOTPRequired = &Error{
+	Code: EUnauthorized,
+	Msg:  "a valid TOTP code is required",
+}
+
+// TOTPService manages the time-based one-time password (TOTP) second factor
+// of the sign-in of users.
+type TOTPService interface {
+	// EnrollTOTP creates a new TOTP secret for the user. It replaces the
+	// enabled secret of the user once confirmed with ConfirmTOTP.
+	EnrollTOTP(ctx context.Context, userID ID) (*TOTPEnrollment, error)
+
+	// ConfirmTOTP enables the enrolled secret of the user if code is valid.
+	ConfirmTOTP(ctx context.Context, userID ID, code string) error
+
+	// TOTPEnabled returns true if the user must sign in with a TOTP code.
+	TOTPEnabled(ctx context.Context, userID ID) (bool, error)
+
+	// VerifyTOTP returns an error if code is not valid for the user.
+	VerifyTOTP(ctx context.Context, userID ID, code string) error
+
+	// ResetTOTP disables TOTP for the user, such as when it lost its device.
+	ResetTOTP(ctx context.Context, userID ID) error
+}
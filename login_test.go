@@ -0,0 +1,111 @@
+package influxdb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	influxTesting "github.com/influxdata/influxdb/v2/testing"
+)
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	strict := influxdb.PasswordPolicy{
+		MinLength:     8,
+		RequireUpper:  true,
+		RequireLower:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+	}
+	cases := []struct {
+		name     string
+		policy   influxdb.PasswordPolicy
+		password string
+		err      error
+	}{
+		{
+			name:     "default",
+			policy:   influxdb.DefaultPasswordPolicy,
+			password: "password",
+		},
+		{
+			name:     "too short",
+			policy:   influxdb.DefaultPasswordPolicy,
+			password: "secret",
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "passwords must be at least 8 characters long",
+			},
+		},
+		{
+			name:     "complex",
+			policy:   strict,
+			password: "Pa55word!",
+		},
+		{
+			name:     "missing classes",
+			policy:   strict,
+			password: "password",
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "passwords must contain an uppercase letter, a digit, a symbol",
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.policy.Validate(c.password)
+			influxTesting.ErrorsEqual(t, err, c.err)
+		})
+	}
+}
+
+func TestLockoutPolicyLockoutDuration(t *testing.T) {
+	cases := []struct {
+		name     string
+		policy   influxdb.LockoutPolicy
+		failures int
+		want     time.Duration
+	}{
+		{
+			name:     "disabled",
+			policy:   influxdb.LockoutPolicy{Duration: time.Minute},
+			failures: 100,
+		},
+		{
+			name:     "below max attempts",
+			policy:   influxdb.DefaultLockoutPolicy,
+			failures: 4,
+		},
+		{
+			name:     "max attempts",
+			policy:   influxdb.DefaultLockoutPolicy,
+			failures: 5,
+			want:     time.Minute,
+		},
+		{
+			name:     "backoff",
+			policy:   influxdb.DefaultLockoutPolicy,
+			failures: 7,
+			want:     4 * time.Minute,
+		},
+		{
+			name:     "max duration",
+			policy:   influxdb.DefaultLockoutPolicy,
+			failures: 1000,
+			want:     time.Hour,
+		},
+		{
+			name:     "no backoff without max duration",
+			policy:   influxdb.LockoutPolicy{MaxAttempts: 1, Duration: time.Minute},
+			failures: 1000,
+			want:     time.Minute,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.policy.LockoutDuration(c.failures); got != c.want {
+				t.Errorf("got %s want %s", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.AuditService = (*AuditService)(nil)
+
+// AuditService is a mock implementation of influxdb.AuditService.
+type AuditService struct {
+	RecordAuditEventFn func(context.Context, *influxdb.AuditEvent) error
+	FindAuditEventsFn  func(context.Context, influxdb.AuditFilter, ...influxdb.FindOptions) ([]*influxdb.AuditEvent, int, error)
+}
+
+// NewAuditService returns a mock AuditService where its methods will return
+// zero values.
+func NewAuditService() *AuditService {
+	return &AuditService{
+		RecordAuditEventFn: func(context.Context, *influxdb.AuditEvent) error { return nil },
+		FindAuditEventsFn: func(context.Context, influxdb.AuditFilter, ...influxdb.FindOptions) ([]*influxdb.AuditEvent, int, error) {
+			return nil, 0, nil
+		},
+	}
+}
+
+// RecordAuditEvent records an event in the audit log.
+func (s *AuditService) RecordAuditEvent(ctx context.Context, e *influxdb.AuditEvent) error {
+	return s.RecordAuditEventFn(ctx, e)
+}
+
+// FindAuditEvents returns the events of the audit log that match the filter.
+func (s *AuditService) FindAuditEvents(ctx context.Context, filter influxdb.AuditFilter, opt ...influxdb.FindOptions) ([]*influxdb.AuditEvent, int, error) {
+	return s.FindAuditEventsFn(ctx, filter, opt...)
+}
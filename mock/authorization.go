@@ -29,6 +29,10 @@ func (a *Authorizer) Allowed(p influxdb.Permission) bool {
 	return influxdb.PermissionAllowed(p, a.Permissions)
 }
 
+func (a *Authorizer) PermissionSet() []influxdb.Permission {
+	return a.Permissions
+}
+
 func (a *Authorizer) Identifier() influxdb.ID {
 	return 1
 }
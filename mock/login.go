@@ -0,0 +1,94 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.LockoutService = (*LockoutService)(nil)
+
+// LockoutService is a mock implementation of influxdb.LockoutService.
+type LockoutService struct {
+	FindLoginAttemptsFn  func(context.Context, influxdb.ID) (*influxdb.LoginAttempts, error)
+	RecordLoginFailureFn func(context.Context, influxdb.ID) (*influxdb.LoginAttempts, error)
+	ResetLoginAttemptsFn func(context.Context, influxdb.ID) error
+}
+
+// NewLockoutService returns a mock LockoutService where no user ever failed
+// to sign in.
+func NewLockoutService() *LockoutService {
+	return &LockoutService{
+		FindLoginAttemptsFn: func(_ context.Context, id influxdb.ID) (*influxdb.LoginAttempts, error) {
+			return &influxdb.LoginAttempts{UserID: id}, nil
+		},
+		RecordLoginFailureFn: func(_ context.Context, id influxdb.ID) (*influxdb.LoginAttempts, error) {
+			return &influxdb.LoginAttempts{UserID: id, Failures: 1}, nil
+		},
+		ResetLoginAttemptsFn: func(context.Context, influxdb.ID) error { return nil },
+	}
+}
+
+// FindLoginAttempts returns the failed sign-ins of the user.
+func (s *LockoutService) FindLoginAttempts(ctx context.Context, userID influxdb.ID) (*influxdb.LoginAttempts, error) {
+	return s.FindLoginAttemptsFn(ctx, userID)
+}
+
+// RecordLoginFailure records a failed sign-in of the user.
+func (s *LockoutService) RecordLoginFailure(ctx context.Context, userID influxdb.ID) (*influxdb.LoginAttempts, error) {
+	return s.RecordLoginFailureFn(ctx, userID)
+}
+
+// ResetLoginAttempts clears the failed sign-ins of the user.
+func (s *LockoutService) ResetLoginAttempts(ctx context.Context, userID influxdb.ID) error {
+	return s.ResetLoginAttemptsFn(ctx, userID)
+}
+
+var _ influxdb.TOTPService = (*TOTPService)(nil)
+
+// TOTPService is a mock implementation of influxdb.TOTPService.
+type TOTPService struct {
+	EnrollTOTPFn  func(context.Context, influxdb.ID) (*influxdb.TOTPEnrollment, error)
+	ConfirmTOTPFn func(context.Context, influxdb.ID, string) error
+	TOTPEnabledFn func(context.Context, influxdb.ID) (bool, error)
+	VerifyTOTPFn  func(context.Context, influxdb.ID, string) error
+	ResetTOTPFn   func(context.Context, influxdb.ID) error
+}
+
+// NewTOTPService returns a mock TOTPService where no user has TOTP enabled.
+func NewTOTPService() *TOTPService {
+	return &TOTPService{
+		EnrollTOTPFn: func(context.Context, influxdb.ID) (*influxdb.TOTPEnrollment, error) {
+			return &influxdb.TOTPEnrollment{}, nil
+		},
+		ConfirmTOTPFn: func(context.Context, influxdb.ID, string) error { return nil },
+		TOTPEnabledFn: func(context.Context, influxdb.ID) (bool, error) { return false, nil },
+		VerifyTOTPFn:  func(context.Context, influxdb.ID, string) error { return influxdb.ErrTOTPRequired },
+		ResetTOTPFn:   func(context.Context, influxdb.ID) error { return nil },
+	}
+}
+
+// EnrollTOTP creates a new TOTP secret for the user.
+func (s *TOTPService) EnrollTOTP(ctx context.Context, userID influxdb.ID) (*influxdb.TOTPEnrollment, error) {
+	return s.EnrollTOTPFn(ctx, userID)
+}
+
+// ConfirmTOTP enables the enrolled secret of the user.
+func (s *TOTPService) ConfirmTOTP(ctx context.Context, userID influxdb.ID, code string) error {
+	return s.ConfirmTOTPFn(ctx, userID, code)
+}
+
+// TOTPEnabled returns true if the user signs in with TOTP.
+func (s *TOTPService) TOTPEnabled(ctx context.Context, userID influxdb.ID) (bool, error) {
+	return s.TOTPEnabledFn(ctx, userID)
+}
+
+// VerifyTOTP returns an error if code is not valid for the user.
+func (s *TOTPService) VerifyTOTP(ctx context.Context, userID influxdb.ID, code string) error {
+	return s.VerifyTOTPFn(ctx, userID, code)
+}
+
+// ResetTOTP disables TOTP for the user.
+func (s *TOTPService) ResetTOTP(ctx context.Context, userID influxdb.ID) error {
+	return s.ResetTOTPFn(ctx, userID)
+}
@@ -0,0 +1,40 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb/v2"
+)
+
+var _ platform.OrgLimitsService = (*OrgLimitsService)(nil)
+
+// OrgLimitsService is a mock implementation of platform.OrgLimitsService.
+type OrgLimitsService struct {
+	FindOrgLimitsFn   func(ctx context.Context, orgID platform.ID) (*platform.OrgLimits, error)
+	UpdateOrgLimitsFn func(ctx context.Context, orgID platform.ID, upd platform.OrgLimitsUpdate) (*platform.OrgLimits, error)
+}
+
+// NewOrgLimitsService returns a mock OrgLimitsService where every
+// organization has zero limits.
+func NewOrgLimitsService() *OrgLimitsService {
+	return &OrgLimitsService{
+		FindOrgLimitsFn: func(ctx context.Context, orgID platform.ID) (*platform.OrgLimits, error) {
+			return &platform.OrgLimits{OrgID: orgID}, nil
+		},
+		UpdateOrgLimitsFn: func(ctx context.Context, orgID platform.ID, upd platform.OrgLimitsUpdate) (*platform.OrgLimits, error) {
+			l := &platform.OrgLimits{OrgID: orgID}
+			upd.Apply(l)
+			return l, nil
+		},
+	}
+}
+
+// FindOrgLimits returns the limits of an organization.
+func (s *OrgLimitsService) FindOrgLimits(ctx context.Context, orgID platform.ID) (*platform.OrgLimits, error) {
+	return s.FindOrgLimitsFn(ctx, orgID)
+}
+
+// UpdateOrgLimits updates the limits of an organization.
+func (s *OrgLimitsService) UpdateOrgLimits(ctx context.Context, orgID platform.ID, upd platform.OrgLimitsUpdate) (*platform.OrgLimits, error) {
+	return s.UpdateOrgLimitsFn(ctx, orgID, upd)
+}
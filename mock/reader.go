@@ -49,7 +49,7 @@ type WindowAggregateStoreReader struct {
 func (s *WindowAggregateStoreReader) HasWindowAggregateCapability(ctx context.Context, capability ...*influxdb.WindowAggregateCapability) bool {
 	// Use the function if it exists.
 	if s.HasWindowAggregateCapabilityFn != nil {
-		return s.HasWindowAggregateCapabilityFn(ctx)
+		return s.HasWindowAggregateCapabilityFn(ctx, capability...)
 	}
 
 	// Provide a default implementation if one wasn't set.
@@ -0,0 +1,115 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb/v2"
+)
+
+var (
+	_ platform.RoleService      = (*RoleService)(nil)
+	_ platform.UserGroupService = (*UserGroupService)(nil)
+)
+
+// RoleService is a mock implementation of platform.RoleService.
+type RoleService struct {
+	FindRoleByIDFn func(ctx context.Context, id platform.ID) (*platform.Role, error)
+	FindRolesFn    func(ctx context.Context, filter platform.RoleFilter, opt ...platform.FindOptions) ([]*platform.Role, int, error)
+	CreateRoleFn   func(ctx context.Context, r *platform.Role) error
+	UpdateRoleFn   func(ctx context.Context, id platform.ID, upd platform.RoleUpdate) (*platform.Role, error)
+	DeleteRoleFn   func(ctx context.Context, id platform.ID) error
+}
+
+// NewRoleService returns a mock RoleService where there are no roles.
+func NewRoleService() *RoleService {
+	return &RoleService{
+		FindRoleByIDFn: func(ctx context.Context, id platform.ID) (*platform.Role, error) {
+			return nil, &platform.Error{Code: platform.ENotFound, Msg: platform.ErrRoleNotFound}
+		},
+		FindRolesFn: func(ctx context.Context, filter platform.RoleFilter, opt ...platform.FindOptions) ([]*platform.Role, int, error) {
+			return nil, 0, nil
+		},
+		CreateRoleFn: func(ctx context.Context, r *platform.Role) error { return nil },
+		UpdateRoleFn: func(ctx context.Context, id platform.ID, upd platform.RoleUpdate) (*platform.Role, error) {
+			return nil, &platform.Error{Code: platform.ENotFound, Msg: platform.ErrRoleNotFound}
+		},
+		DeleteRoleFn: func(ctx context.Context, id platform.ID) error { return nil },
+	}
+}
+
+// FindRoleByID returns a single role by ID.
+func (s *RoleService) FindRoleByID(ctx context.Context, id platform.ID) (*platform.Role, error) {
+	return s.FindRoleByIDFn(ctx, id)
+}
+
+// FindRoles returns a list of roles that match filter.
+func (s *RoleService) FindRoles(ctx context.Context, filter platform.RoleFilter, opt ...platform.FindOptions) ([]*platform.Role, int, error) {
+	return s.FindRolesFn(ctx, filter, opt...)
+}
+
+// CreateRole creates a new role.
+func (s *RoleService) CreateRole(ctx context.Context, r *platform.Role) error {
+	return s.CreateRoleFn(ctx, r)
+}
+
+// UpdateRole updates a single role with changeset.
+func (s *RoleService) UpdateRole(ctx context.Context, id platform.ID, upd platform.RoleUpdate) (*platform.Role, error) {
+	return s.UpdateRoleFn(ctx, id, upd)
+}
+
+// DeleteRole removes a role by ID.
+func (s *RoleService) DeleteRole(ctx context.Context, id platform.ID) error {
+	return s.DeleteRoleFn(ctx, id)
+}
+
+// UserGroupService is a mock implementation of platform.UserGroupService.
+type UserGroupService struct {
+	FindUserGroupByIDFn func(ctx context.Context, id platform.ID) (*platform.UserGroup, error)
+	FindUserGroupsFn    func(ctx context.Context, filter platform.UserGroupFilter, opt ...platform.FindOptions) ([]*platform.UserGroup, int, error)
+	CreateUserGroupFn   func(ctx context.Context, g *platform.UserGroup) error
+	UpdateUserGroupFn   func(ctx context.Context, id platform.ID, upd platform.UserGroupUpdate) (*platform.UserGroup, error)
+	DeleteUserGroupFn   func(ctx context.Context, id platform.ID) error
+}
+
+// NewUserGroupService returns a mock UserGroupService where there are no
+// user groups.
+func NewUserGroupService() *UserGroupService {
+	return &UserGroupService{
+		FindUserGroupByIDFn: func(ctx context.Context, id platform.ID) (*platform.UserGroup, error) {
+			return nil, &platform.Error{Code: platform.ENotFound, Msg: platform.ErrUserGroupNotFound}
+		},
+		FindUserGroupsFn: func(ctx context.Context, filter platform.UserGroupFilter, opt ...platform.FindOptions) ([]*platform.UserGroup, int, error) {
+			return nil, 0, nil
+		},
+		CreateUserGroupFn: func(ctx context.Context, g *platform.UserGroup) error { return nil },
+		UpdateUserGroupFn: func(ctx context.Context, id platform.ID, upd platform.UserGroupUpdate) (*platform.UserGroup, error) {
+			return nil, &platform.Error{Code: platform.ENotFound, Msg: platform.ErrUserGroupNotFound}
+		},
+		DeleteUserGroupFn: func(ctx context.Context, id platform.ID) error { return nil },
+	}
+}
+
+// FindUserGroupByID returns a single user group by ID.
+func (s *UserGroupService) FindUserGroupByID(ctx context.Context, id platform.ID) (*platform.UserGroup, error) {
+	return s.FindUserGroupByIDFn(ctx, id)
+}
+
+// FindUserGroups returns a list of user groups that match filter.
+func (s *UserGroupService) FindUserGroups(ctx context.Context, filter platform.UserGroupFilter, opt ...platform.FindOptions) ([]*platform.UserGroup, int, error) {
+	return s.FindUserGroupsFn(ctx, filter, opt...)
+}
+
+// CreateUserGroup creates a new user group.
+func (s *UserGroupService) CreateUserGroup(ctx context.Context, g *platform.UserGroup) error {
+	return s.CreateUserGroupFn(ctx, g)
+}
+
+// UpdateUserGroup updates a single user group with changeset.
+func (s *UserGroupService) UpdateUserGroup(ctx context.Context, id platform.ID, upd platform.UserGroupUpdate) (*platform.UserGroup, error) {
+	return s.UpdateUserGroupFn(ctx, id, upd)
+}
+
+// DeleteUserGroup removes a user group by ID.
+func (s *UserGroupService) DeleteUserGroup(ctx context.Context, id platform.ID) error {
+	return s.DeleteUserGroupFn(ctx, id)
+}
@@ -0,0 +1,125 @@
+package influxdb
+
+import (
+	"context"
+)
+
+// OrgLimits are the query resource, rate and write quota limits of an
+// organization. A zero limit means that the organization is only bound by
+// the limits of the server.
+type OrgLimits struct {
+	OrgID ID `json:"orgID"`
+
+	// ConcurrencyQuota is the number of queries of the organization
+	// that may execute at the same time.
+	ConcurrencyQuota int `json:"concurrencyQuota"`
+
+	// TokenConcurrencyQuota is the number of queries made with the same
+	// token that may execute at the same time.
+	TokenConcurrencyQuota int `json:"tokenConcurrencyQuota"`
+
+	// QueueSize is the number of queries of the organization that may
+	// wait for execution before new queries are rejected.
+	QueueSize int `json:"queueSize"`
+
+	// MemoryBytesQuota is the number of bytes that all the executing
+	// queries of the organization may allocate together.
+	MemoryBytesQuota int64 `json:"memoryBytesQuota"`
+
+	// Weight is the share of the query executors the organization is
+	// given when queries of several organizations are waiting. It
+	// defaults to 1.
+	Weight int `json:"weight"`
+
+	// RateLimits are the rates at which all the tokens of the
+	// organization may write and query together.
+	RateLimits
+
+	// MonthlyWriteBytesQuota is the number of line protocol bytes the
+	// organization may write every calendar month (UTC). Writes are
+	// rejected once it is exceeded.
+	MonthlyWriteBytesQuota int64 `json:"monthlyWriteBytesQuota,omitempty"`
+
+	// StorageBytesQuota is the size that the data of the organization may
+	// take up in storage. Writes are rejected once it is exceeded.
+	StorageBytesQuota int64 `json:"storageBytesQuota,omitempty"`
+}
+
+// ops for org limits error.
+const (
+	OpFindOrgLimits   = "FindOrgLimits"
+	OpUpdateOrgLimits = "UpdateOrgLimits"
+)
+
+// Valid returns an error if any of the limits is negative.
+func (l OrgLimits) Valid() error {
+	if l.ConcurrencyQuota < 0 || l.TokenConcurrencyQuota < 0 || l.QueueSize < 0 || l.MemoryBytesQuota < 0 || l.Weight < 0 ||
+		l.MonthlyWriteBytesQuota < 0 || l.StorageBytesQuota < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "org limits must not be negative",
+		}
+	}
+	return l.RateLimits.Valid()
+}
+
+// OrgLimitsService represents a service for managing the limits of
+// organizations.
+type OrgLimitsService interface {
+	// FindOrgLimits returns the limits of an organization. Organizations
+	// without limits report zero limits.
+	FindOrgLimits(ctx context.Context, orgID ID) (*OrgLimits, error)
+
+	// UpdateOrgLimits updates the limits of an organization with
+	// changeset and returns the new limits.
+	UpdateOrgLimits(ctx context.Context, orgID ID, upd OrgLimitsUpdate) (*OrgLimits, error)
+}
+
+// OrgLimitsUpdate represents updates to the limits of an organization.
+// Only fields which are set are updated.
+type OrgLimitsUpdate struct {
+	ConcurrencyQuota       *int   `json:"concurrencyQuota,omitempty"`
+	TokenConcurrencyQuota  *int   `json:"tokenConcurrencyQuota,omitempty"`
+	QueueSize              *int   `json:"queueSize,omitempty"`
+	MemoryBytesQuota       *int64 `json:"memoryBytesQuota,omitempty"`
+	Weight                 *int   `json:"weight,omitempty"`
+	WriteBytesPerSecond    *int64 `json:"writeBytesPerSecond,omitempty"`
+	WritePointsPerSecond   *int64 `json:"writePointsPerSecond,omitempty"`
+	QueriesPerSecond       *int64 `json:"queriesPerSecond,omitempty"`
+	MonthlyWriteBytesQuota *int64 `json:"monthlyWriteBytesQuota,omitempty"`
+	StorageBytesQuota      *int64 `json:"storageBytesQuota,omitempty"`
+}
+
+// Apply applies the update to the limits l.
+func (u OrgLimitsUpdate) Apply(l *OrgLimits) {
+	if u.ConcurrencyQuota != nil {
+		l.ConcurrencyQuota = *u.ConcurrencyQuota
+	}
+	if u.TokenConcurrencyQuota != nil {
+		l.TokenConcurrencyQuota = *u.TokenConcurrencyQuota
+	}
+	if u.QueueSize != nil {
+		l.QueueSize = *u.QueueSize
+	}
+	if u.MemoryBytesQuota != nil {
+		l.MemoryBytesQuota = *u.MemoryBytesQuota
+	}
+	if u.Weight != nil {
+		l.Weight = *u.Weight
+	}
+	if u.WriteBytesPerSecond != nil {
+		l.WriteBytesPerSecond = *u.WriteBytesPerSecond
+	}
+	if u.WritePointsPerSecond != nil {
+		l.WritePointsPerSecond = *u.WritePointsPerSecond
+	}
+	if u.QueriesPerSecond != nil {
+		l.QueriesPerSecond = *u.QueriesPerSecond
+	}
+	if u.MonthlyWriteBytesQuota != nil {
+		l.MonthlyWriteBytesQuota = *u.MonthlyWriteBytesQuota
+	}
+	if u.StorageBytesQuota != nil {
+		l.StorageBytesQuota = *u.StorageBytesQuota
+	}
+}
@@ -57,6 +57,7 @@ var kindPriorities = map[Kind]int{
 	KindVariable:                      12,
 	KindDashboard:                     13,
 	KindTelegraf:                      14,
+	KindRole:                          15,
 }
 
 type exportKey struct {
@@ -83,6 +84,7 @@ type resourceExporter struct {
 	dashSVC     influxdb.DashboardService
 	labelSVC    influxdb.LabelService
 	endpointSVC influxdb.NotificationEndpointService
+	roleSVC     influxdb.RoleService
 	ruleSVC     influxdb.NotificationRuleStore
 	taskSVC     influxdb.TaskService
 	teleSVC     influxdb.TelegrafConfigStore
@@ -100,6 +102,7 @@ func newResourceExporter(svc *Service) *resourceExporter {
 		dashSVC:     svc.dashSVC,
 		labelSVC:    svc.labelSVC,
 		endpointSVC: svc.endpointSVC,
+		roleSVC:     svc.roleSVC,
 		ruleSVC:     svc.ruleSVC,
 		taskSVC:     svc.taskSVC,
 		teleSVC:     svc.teleSVC,
@@ -248,6 +251,12 @@ func (ex *resourceExporter) resourceCloneToKind(ctx context.Context, r ResourceT
 		endpointObjectName := object.Name()
 
 		mapResource(rule.GetOrgID(), rule.GetID(), KindNotificationRule, NotificationRuleToObject(r.Name, endpointObjectName, rule))
+	case r.Kind.is(KindRole):
+		role, err := ex.roleSVC.FindRoleByID(ctx, r.ID)
+		if err != nil {
+			return err
+		}
+		mapResource(role.OrgID, uniqByNameResID, KindRole, RoleToObject(r.Name, *role))
 	case r.Kind.is(KindTask):
 		t, err := ex.taskSVC.FindTaskByID(ctx, r.ID)
 		if err != nil {
@@ -293,6 +302,10 @@ func (ex *resourceExporter) resourceCloneAssociationsGen(ctx context.Context, la
 			shouldSkip := len(mLabelIDs) > 0 && !mLabelIDs[r.ID]
 			return nil, shouldSkip, nil
 		}
+		if r.Kind.is(KindRole) {
+			// roles cannot have labels, so are skipped when exporting by label names
+			return nil, len(mLabelNames) > 0, nil
+		}
 
 		labels, err := ex.labelSVC.FindResourceLabels(ctx, influxdb.LabelMappingFilter{
 			ResourceID:   r.ID,
@@ -946,6 +959,35 @@ func TelegrafToObject(name string, t influxdb.TelegrafConfig) Object {
 	return o
 }
 
+// RoleToObject converts an influxdb.Role to a pkger.Object. The organization
+// and users of the role are not exported, as the permissions of the role are
+// scoped to the organization the pkg is applied to.
+func RoleToObject(name string, r influxdb.Role) Object {
+	if name == "" {
+		name = r.Name
+	}
+
+	o := newObject(KindRole, name)
+	assignNonZeroStrings(o.Spec, map[string]string{fieldDescription: r.Description})
+
+	perms := make([]Resource, 0, len(r.Permissions))
+	for _, p := range r.Permissions {
+		res := Resource{fieldType: string(p.Resource.Type)}
+		if p.Resource.ID != nil && p.Resource.Type != influxdb.OrgsResourceType {
+			res[fieldRolePermissionResourceID] = p.Resource.ID.String()
+		}
+		perm := Resource{
+			fieldRolePermissionAction:   string(p.Action),
+			fieldRolePermissionResource: res,
+		}
+		assignNonZeroStrings(perm, map[string]string{fieldRolePermissionPredicate: p.Predicate})
+		perms = append(perms, perm)
+	}
+	o.Spec[fieldRolePermissions] = perms
+
+	return o
+}
+
 // VariableToObject converts an influxdb.Variable to a pkger.Object.
 func VariableToObject(name string, v influxdb.Variable) Object {
 	if name == "" {
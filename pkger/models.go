@@ -26,6 +26,7 @@ const (
 	KindNotificationEndpointSlack     Kind = "NotificationEndpointSlack"
 	KindNotificationRule              Kind = "NotificationRule"
 	KindPackage                       Kind = "Package"
+	KindRole                          Kind = "Role"
 	KindTask                          Kind = "Task"
 	KindTelegraf                      Kind = "Telegraf"
 	KindVariable                      Kind = "Variable"
@@ -43,6 +44,7 @@ var kinds = map[Kind]bool{
 	KindNotificationEndpointPagerDuty: true,
 	KindNotificationEndpointSlack:     true,
 	KindNotificationRule:              true,
+	KindRole:                          true,
 	KindTask:                          true,
 	KindTelegraf:                      true,
 	KindVariable:                      true,
@@ -151,6 +153,7 @@ type Diff struct {
 	LabelMappings         []DiffLabelMapping         `json:"labelMappings"`
 	NotificationEndpoints []DiffNotificationEndpoint `json:"notificationEndpoints"`
 	NotificationRules     []DiffNotificationRule     `json:"notificationRules"`
+	Roles                 []DiffRole                 `json:"roles"`
 	Tasks                 []DiffTask                 `json:"tasks"`
 	Telegrafs             []DiffTelegraf             `json:"telegrafConfigs"`
 	Variables             []DiffVariable             `json:"variables"`
@@ -171,6 +174,12 @@ func (d Diff) HasConflicts() bool {
 		}
 	}
 
+	for _, r := range d.Roles {
+		if r.hasConflict() {
+			return true
+		}
+	}
+
 	for _, v := range d.Variables {
 		if v.hasConflict() {
 			return true
@@ -359,6 +368,27 @@ type (
 	}
 )
 
+type (
+	// DiffRole is a diff of an individual role.
+	DiffRole struct {
+		DiffIdentifier
+
+		New DiffRoleValues  `json:"new"`
+		Old *DiffRoleValues `json:"old"`
+	}
+
+	// DiffRoleValues are the varying values for a role.
+	DiffRoleValues struct {
+		Name        string                `json:"name"`
+		Description string                `json:"description"`
+		Permissions []influxdb.Permission `json:"permissions"`
+	}
+)
+
+func (d DiffRole) hasConflict() bool {
+	return !d.IsNew() && d.Old != nil && !reflect.DeepEqual(*d.Old, d.New)
+}
+
 type (
 	// DiffTask is a diff of an individual task.
 	DiffTask struct {
@@ -421,6 +451,7 @@ type Summary struct {
 	LabelMappings         []SummaryLabelMapping         `json:"labelMappings"`
 	MissingEnvs           []string                      `json:"missingEnvRefs"`
 	MissingSecrets        []string                      `json:"missingSecrets"`
+	Roles                 []SummaryRole                 `json:"roles"`
 	Tasks                 []SummaryTask                 `json:"summaryTask"`
 	TelegrafConfigs       []SummaryTelegraf             `json:"telegrafConfigs"`
 	Variables             []SummaryVariable             `json:"variables"`
@@ -611,6 +642,16 @@ type SummaryLabelMapping struct {
 	LabelID         SafeID                `json:"labelID"`
 }
 
+// SummaryRole provides a summary of a pkg role.
+type SummaryRole struct {
+	ID          SafeID                `json:"id,omitempty"`
+	OrgID       SafeID                `json:"orgID,omitempty"`
+	PkgName     string                `json:"pkgName"`
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	Permissions []influxdb.Permission `json:"permissions"`
+}
+
 // SummaryTask provides a summary of a task.
 type SummaryTask struct {
 	ID          SafeID          `json:"id"`
@@ -281,6 +281,7 @@ type Pkg struct {
 	mDashboards            map[string]*dashboard
 	mNotificationEndpoints map[string]*notificationEndpoint
 	mNotificationRules     map[string]*notificationRule
+	mRoles                 map[string]*role
 	mTasks                 map[string]*task
 	mTelegrafs             map[string]*telegraf
 	mVariables             map[string]*variable
@@ -338,6 +339,7 @@ func (p *Pkg) Summary() Summary {
 		Labels:                []SummaryLabel{},
 		MissingEnvs:           p.missingEnvRefs(),
 		MissingSecrets:        p.missingSecrets(),
+		Roles:                 []SummaryRole{},
 		Tasks:                 []SummaryTask{},
 		TelegrafConfigs:       []SummaryTelegraf{},
 		Variables:             []SummaryVariable{},
@@ -378,6 +380,10 @@ func (p *Pkg) Summary() Summary {
 		sum.NotificationRules = append(sum.NotificationRules, r.summarize())
 	}
 
+	for _, r := range p.roles() {
+		sum.Roles = append(sum.Roles, r.summarize())
+	}
+
 	for _, t := range p.tasks() {
 		sum.Tasks = append(sum.Tasks, t.summarize())
 	}
@@ -440,6 +446,9 @@ func (p *Pkg) Contains(k Kind, pkgName string) bool {
 	case KindNotificationRule:
 		_, ok := p.mNotificationRules[pkgName]
 		return ok
+	case KindRole:
+		_, ok := p.mRoles[pkgName]
+		return ok
 	case KindTask:
 		_, ok := p.mTasks[pkgName]
 		return ok
@@ -612,6 +621,17 @@ func (p *Pkg) missingSecrets() []string {
 	return secrets
 }
 
+func (p *Pkg) roles() []*role {
+	roles := make([]*role, 0, len(p.mRoles))
+	for _, r := range p.mRoles {
+		roles = append(roles, r)
+	}
+
+	sort.Slice(roles, func(i, j int) bool { return roles[i].PkgName() < roles[j].PkgName() })
+
+	return roles
+}
+
 func (p *Pkg) tasks() []*task {
 	tasks := make([]*task, 0, len(p.mTasks))
 	for _, t := range p.mTasks {
@@ -708,6 +728,7 @@ func (p *Pkg) graphResources() error {
 		p.graphDashboards,
 		p.graphNotificationEndpoints,
 		p.graphNotificationRules,
+		p.graphRoles,
 		p.graphTasks,
 		p.graphTelegrafs,
 	}
@@ -1025,6 +1046,36 @@ func (p *Pkg) graphNotificationRules() *parseErr {
 	})
 }
 
+func (p *Pkg) graphRoles() *parseErr {
+	p.mRoles = make(map[string]*role)
+	tracker := p.trackNames(true)
+	return p.eachResource(KindRole, func(o Object) []validationErr {
+		ident, errs := tracker(o)
+		if len(errs) > 0 {
+			return errs
+		}
+
+		r := &role{
+			identity:    ident,
+			Description: o.Spec.stringShort(fieldDescription),
+		}
+		for _, pr := range o.Spec.slcResource(fieldRolePermissions) {
+			res, _ := ifaceToResource(pr[fieldRolePermissionResource])
+			r.permissions = append(r.permissions, rolePermission{
+				Action:       influxdb.Action(normStr(pr.stringShort(fieldRolePermissionAction))),
+				ResourceType: influxdb.ResourceType(strings.TrimSpace(res.stringShort(fieldType))),
+				ResourceID:   strings.TrimSpace(res.stringShort(fieldRolePermissionResourceID)),
+				Predicate:    strings.TrimSpace(pr.stringShort(fieldRolePermissionPredicate)),
+			})
+		}
+
+		p.mRoles[r.PkgName()] = r
+		p.setRefs(r.name, r.displayName)
+
+		return r.valid()
+	})
+}
+
 func (p *Pkg) graphTasks() *parseErr {
 	p.mTasks = make(map[string]*task)
 	tracker := p.trackNames(false)
@@ -1661,6 +1661,126 @@ func toSummaryTagRules(tagRules []struct{ k, v, op string }) []SummaryTagRule {
 	return out
 }
 
+const (
+	fieldRolePermissions          = "permissions"
+	fieldRolePermissionAction     = "action"
+	fieldRolePermissionPredicate  = "predicate"
+	fieldRolePermissionResource   = "resource"
+	fieldRolePermissionResourceID = "id"
+)
+
+// rolePermission is a permission of a role. It is scoped to the
+// organization the pkg is applied to, so it has no organization of its own.
+type rolePermission struct {
+	Action       influxdb.Action
+	ResourceType influxdb.ResourceType
+	ResourceID   string
+	Predicate    string
+}
+
+type role struct {
+	identity
+
+	Description string
+	permissions []rolePermission
+}
+
+// influxPermissions returns the permissions of the role within the
+// organization. A permission to the organizations resource type is always
+// a permission to the organization itself.
+func (r *role) influxPermissions(orgID influxdb.ID) []influxdb.Permission {
+	perms := make([]influxdb.Permission, 0, len(r.permissions))
+	for _, rp := range r.permissions {
+		p := influxdb.Permission{
+			Action:    rp.Action,
+			Resource:  influxdb.Resource{Type: rp.ResourceType},
+			Predicate: rp.Predicate,
+		}
+		if rp.ResourceType == influxdb.OrgsResourceType {
+			if orgID.Valid() {
+				id := orgID
+				p.Resource.ID = &id
+			}
+			perms = append(perms, p)
+			continue
+		}
+
+		if orgID.Valid() {
+			id := orgID
+			p.Resource.OrgID = &id
+		}
+		if rp.ResourceID != "" {
+			id, err := influxdb.IDFromString(rp.ResourceID)
+			if err == nil {
+				p.Resource.ID = id
+			}
+		}
+		perms = append(perms, p)
+	}
+	return perms
+}
+
+func (r *role) summarize() SummaryRole {
+	return SummaryRole{
+		PkgName:     r.PkgName(),
+		Name:        r.Name(),
+		Description: r.Description,
+		Permissions: r.influxPermissions(0),
+	}
+}
+
+func (r *role) valid() []validationErr {
+	var vErrs []validationErr
+	if err, ok := isValidName(r.Name(), 1); !ok {
+		vErrs = append(vErrs, err)
+	}
+
+	for i, rp := range r.permissions {
+		var pErrs []validationErr
+		if err := rp.Action.Valid(); err != nil {
+			pErrs = append(pErrs, validationErr{
+				Field: fieldRolePermissionAction,
+				Msg:   "must be 1 of [read, write]",
+			})
+		}
+		if err := rp.ResourceType.Valid(); err != nil {
+			pErrs = append(pErrs, validationErr{
+				Field: fieldRolePermissionResource,
+				Msg:   fmt.Sprintf("invalid resource type %q", rp.ResourceType),
+			})
+		}
+		if rp.ResourceID != "" {
+			if _, err := influxdb.IDFromString(rp.ResourceID); err != nil {
+				pErrs = append(pErrs, validationErr{
+					Field: fieldRolePermissionResource,
+					Msg:   fmt.Sprintf("invalid resource id %q", rp.ResourceID),
+				})
+			}
+		}
+		if rp.Predicate != "" && rp.ResourceType != influxdb.BucketsResourceType {
+			pErrs = append(pErrs, validationErr{
+				Field: fieldRolePermissionPredicate,
+				Msg:   "only permissions for buckets may have a predicate",
+			})
+		}
+		if len(pErrs) > 0 {
+			vErrs = append(vErrs, validationErr{
+				Field:  fieldRolePermissions,
+				Index:  intPtr(i),
+				Nested: pErrs,
+			})
+		}
+	}
+
+	if len(vErrs) > 0 {
+		return []validationErr{
+			objectValidationErr(fieldSpec, vErrs...),
+		}
+	}
+
+	return nil
+}
+
 const (
 	fieldTaskCron = "cron"
 )
@@ -3652,6 +3652,78 @@ spec:
 		})
 	})
 
+	t.Run("pkg with roles", func(t *testing.T) {
+		t.Run("with valid fields should produce summary", func(t *testing.T) {
+			testfileRunner(t, "testdata/roles", func(t *testing.T, pkg *Pkg) {
+				sum := pkg.Summary()
+
+				require.Len(t, sum.Roles, 2)
+
+				r := sum.Roles[0]
+				assert.Equal(t, "role-1", r.PkgName)
+				assert.Equal(t, "readers", r.Name)
+				assert.Equal(t, "readers desc", r.Description)
+				require.Len(t, r.Permissions, 2)
+				assert.Equal(t, influxdb.ReadAction, r.Permissions[0].Action)
+				assert.Equal(t, influxdb.BucketsResourceType, r.Permissions[0].Resource.Type)
+				assert.Nil(t, r.Permissions[0].Resource.ID)
+				assert.Equal(t, `_measurement="cpu"`, r.Permissions[0].Predicate)
+				assert.Equal(t, influxdb.DashboardsResourceType, r.Permissions[1].Resource.Type)
+				require.NotNil(t, r.Permissions[1].Resource.ID)
+				assert.Equal(t, "020f755c3c082000", r.Permissions[1].Resource.ID.String())
+
+				r = sum.Roles[1]
+				assert.Equal(t, "role-2", r.PkgName)
+				assert.Equal(t, "role-2", r.Name)
+				require.Len(t, r.Permissions, 1)
+				assert.Equal(t, influxdb.WriteAction, r.Permissions[0].Action)
+				assert.Equal(t, influxdb.TasksResourceType, r.Permissions[0].Resource.Type)
+			})
+		})
+
+		t.Run("handles bad config", func(t *testing.T) {
+			tests := []testPkgResourceError{
+				{
+					name:           "invalid action",
+					validationErrs: 1,
+					valFields:      []string{fieldSpec, "permissions[0].action"},
+					pkgStr: `apiVersion: influxdata.com/v2alpha1
+kind: Role
+metadata:
+  name: role-1
+spec:
+  permissions:
+    - action: delete
+      resource:
+        type: buckets
+`,
+				},
+				{
+					name:           "invalid resource type",
+					validationErrs: 1,
+					valFields:      []string{fieldSpec, "permissions[1].resource"},
+					pkgStr: `apiVersion: influxdata.com/v2alpha1
+kind: Role
+metadata:
+  name: role-1
+spec:
+  permissions:
+    - action: read
+      resource:
+        type: buckets
+    - action: read
+      resource:
+        type: rockets
+`,
+				},
+			}
+
+			for _, tt := range tests {
+				testPkgErrors(t, KindRole, tt)
+			}
+		})
+	})
+
 	t.Run("pkg with a variable", func(t *testing.T) {
 		t.Run("with valid fields should produce summary", func(t *testing.T) {
 			testfileRunner(t, "testdata/variables", func(t *testing.T, pkg *Pkg) {
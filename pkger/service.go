@@ -85,6 +85,7 @@ type serviceOpt struct {
 	labelSVC    influxdb.LabelService
 	endpointSVC influxdb.NotificationEndpointService
 	orgSVC      influxdb.OrganizationService
+	roleSVC     influxdb.RoleService
 	ruleSVC     influxdb.NotificationRuleStore
 	secretSVC   influxdb.SecretService
 	taskSVC     influxdb.TaskService
@@ -165,6 +166,13 @@ func WithOrganizationService(orgSVC influxdb.OrganizationService) ServiceSetterF
 	}
 }
 
+// WithRoleSVC sets the role service.
+func WithRoleSVC(roleSVC influxdb.RoleService) ServiceSetterFn {
+	return func(opt *serviceOpt) {
+		opt.roleSVC = roleSVC
+	}
+}
+
 // WithLabelSVC sets the label service.
 func WithLabelSVC(labelSVC influxdb.LabelService) ServiceSetterFn {
 	return func(opt *serviceOpt) {
@@ -227,6 +235,7 @@ type Service struct {
 	labelSVC    influxdb.LabelService
 	endpointSVC influxdb.NotificationEndpointService
 	orgSVC      influxdb.OrganizationService
+	roleSVC     influxdb.RoleService
 	ruleSVC     influxdb.NotificationRuleStore
 	secretSVC   influxdb.SecretService
 	taskSVC     influxdb.TaskService
@@ -262,6 +271,7 @@ func NewService(opts ...ServiceSetterFn) *Service {
 		dashSVC:     opt.dashSVC,
 		endpointSVC: opt.endpointSVC,
 		orgSVC:      opt.orgSVC,
+		roleSVC:     opt.roleSVC,
 		ruleSVC:     opt.ruleSVC,
 		secretSVC:   opt.secretSVC,
 		taskSVC:     opt.taskSVC,
@@ -600,6 +610,27 @@ func (s *Service) cloneOrgTasks(ctx context.Context, orgID influxdb.ID) ([]Resou
 	return resources, nil
 }
 
+func (s *Service) cloneOrgRoles(ctx context.Context, orgID influxdb.ID) ([]ResourceToClone, error) {
+	// roles are optional, a service without a role service has no roles to export
+	if s.roleSVC == nil {
+		return nil, nil
+	}
+
+	roles, _, err := s.roleSVC.FindRoles(ctx, influxdb.RoleFilter{OrgID: &orgID})
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]ResourceToClone, 0, len(roles))
+	for _, r := range roles {
+		resources = append(resources, ResourceToClone{
+			Kind: KindRole,
+			ID:   r.ID,
+		})
+	}
+	return resources, nil
+}
+
 func (s *Service) cloneOrgTelegrafs(ctx context.Context, orgID influxdb.ID) ([]ResourceToClone, error) {
 	teles, _, err := s.teleSVC.FindTelegrafConfigs(ctx, influxdb.TelegrafConfigFilter{OrgID: &orgID})
 	if err != nil {
@@ -648,6 +679,7 @@ func (s *Service) filterOrgResourceKinds(resourceKindFilters []Kind) []struct {
 		KindLabel:                s.cloneOrgLabels,
 		KindNotificationEndpoint: s.cloneOrgNotificationEndpoints,
 		KindNotificationRule:     s.cloneOrgNotificationRules,
+		KindRole:                 s.cloneOrgRoles,
 		KindTask:                 s.cloneOrgTasks,
 		KindTelegraf:             s.cloneOrgTelegrafs,
 		KindVariable:             s.cloneOrgVariables,
@@ -750,6 +782,7 @@ func (s *Service) dryRun(ctx context.Context, orgID influxdb.ID, pkg *Pkg, opt A
 	s.dryRunChecks(ctx, orgID, state.mChecks)
 	s.dryRunDashboards(ctx, orgID, state.mDashboards)
 	s.dryRunLabels(ctx, orgID, state.mLabels)
+	s.dryRunRoles(ctx, orgID, state.mRoles)
 	s.dryRunTasks(ctx, orgID, state.mTasks)
 	s.dryRunTelegrafConfigs(ctx, orgID, state.mTelegrafs)
 	s.dryRunVariables(ctx, orgID, state.mVariables)
@@ -947,6 +980,34 @@ func (s *Service) dryRunTelegrafConfigs(ctx context.Context, orgID influxdb.ID,
 	}
 }
 
+func (s *Service) dryRunRoles(ctx context.Context, orgID influxdb.ID, roles map[string]*stateRole) {
+	if len(roles) == 0 {
+		return
+	}
+
+	existingRoles, _, _ := s.roleSVC.FindRoles(ctx, influxdb.RoleFilter{OrgID: &orgID})
+
+	mIDs := make(map[influxdb.ID]*influxdb.Role)
+	mNames := make(map[string]*influxdb.Role)
+	for _, r := range existingRoles {
+		mIDs[r.ID] = r
+		mNames[r.Name] = r
+	}
+
+	for _, r := range roles {
+		r.orgID = orgID
+
+		existing := mNames[r.parserRole.Name()]
+		if r.ID() != 0 {
+			existing = mIDs[r.ID()]
+		}
+		if IsNew(r.stateStatus) && existing != nil {
+			r.stateStatus = StateStatusExists
+		}
+		r.existing = existing
+	}
+}
+
 func (s *Service) dryRunVariables(ctx context.Context, orgID influxdb.ID, vars map[string]*stateVariable) {
 	existingVars, _ := s.getAllPlatformVariables(ctx, orgID)
 
@@ -1266,6 +1327,7 @@ func (s *Service) applyState(ctx context.Context, coordinator *rollbackCoordinat
 			endpointApp,
 			s.applyTasks(ctx, state.tasks()),
 			s.applyTelegrafs(ctx, userID, state.telegrafConfigs()),
+			s.applyRoles(ctx, state.roles()),
 		},
 	}
 
@@ -2399,6 +2461,126 @@ func (s *Service) rollbackTelegrafConfigs(ctx context.Context, userID influxdb.I
 	return nil
 }
 
+func (s *Service) applyRoles(ctx context.Context, roles []*stateRole) applier {
+	const resource = "role"
+
+	mutex := new(doMutex)
+	rollBackRoles := make([]*stateRole, 0, len(roles))
+
+	createFn := func(ctx context.Context, i int, orgID, userID influxdb.ID) *applyErrBody {
+		var r *stateRole
+		mutex.Do(func() {
+			roles[i].orgID = orgID
+			r = roles[i]
+		})
+		if !r.shouldApply() {
+			return nil
+		}
+		influxRole, err := s.applyRole(ctx, r)
+		if err != nil {
+			return &applyErrBody{
+				name: r.parserRole.PkgName(),
+				msg:  err.Error(),
+			}
+		}
+
+		mutex.Do(func() {
+			roles[i].id = influxRole.ID
+			rollBackRoles = append(rollBackRoles, roles[i])
+		})
+		return nil
+	}
+
+	return applier{
+		creater: creater{
+			entries: len(roles),
+			fn:      createFn,
+		},
+		rollbacker: rollbacker{
+			resource: resource,
+			fn:       func(_ influxdb.ID) error { return s.rollbackRoles(ctx, rollBackRoles) },
+		},
+	}
+}
+
+func (s *Service) rollbackRoles(ctx context.Context, roles []*stateRole) error {
+	rollbackFn := func(r *stateRole) error {
+		var err error
+		switch {
+		case IsRemoval(r.stateStatus):
+			if r.existing == nil {
+				return nil
+			}
+			err = ierrors.Wrap(s.roleSVC.CreateRole(ctx, r.existing), "rolling back removed role")
+		case IsExisting(r.stateStatus):
+			if r.existing == nil {
+				return nil
+			}
+			_, err = s.roleSVC.UpdateRole(ctx, r.ID(), influxdb.RoleUpdate{
+				Name:        &r.existing.Name,
+				Description: &r.existing.Description,
+				Permissions: &r.existing.Permissions,
+			})
+			err = ierrors.Wrap(err, "rolling back updated role")
+		default:
+			err = ierrors.Wrap(s.roleSVC.DeleteRole(ctx, r.ID()), "rolling back created role")
+		}
+		return err
+	}
+
+	var errs []string
+	for _, r := range roles {
+		if err := rollbackFn(r); err != nil {
+			errs = append(errs, fmt.Sprintf("error for role[%q]: %s", r.ID(), err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+func (s *Service) applyRole(ctx context.Context, r *stateRole) (influxdb.Role, error) {
+	switch {
+	case IsRemoval(r.stateStatus):
+		if err := s.roleSVC.DeleteRole(ctx, r.id); err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+			return influxdb.Role{}, ierrors.Wrap(err, "removing existing role")
+		}
+		if r.existing == nil {
+			return influxdb.Role{}, nil
+		}
+		return *r.existing, nil
+	case IsExisting(r.stateStatus) && r.existing != nil:
+		name, desc := r.parserRole.Name(), r.parserRole.Description
+		perms := r.parserRole.influxPermissions(r.orgID)
+		updatedRole, err := s.roleSVC.UpdateRole(ctx, r.ID(), influxdb.RoleUpdate{
+			Name:        &name,
+			Description: &desc,
+			Permissions: &perms,
+		})
+		if err != nil {
+			return influxdb.Role{}, ierrors.Wrap(err, "updating existing role")
+		}
+		return *updatedRole, nil
+	default:
+		// when an existing role (referenced in stack) has been deleted by a user
+		// then the resource is created anew to get it back to the expected state.
+		influxRole := influxdb.Role{
+			OrgID:       r.orgID,
+			Name:        r.parserRole.Name(),
+			Description: r.parserRole.Description,
+			Permissions: r.parserRole.influxPermissions(r.orgID),
+		}
+		err := s.roleSVC.CreateRole(ctx, &influxRole)
+		if err != nil {
+			return influxdb.Role{}, ierrors.Wrap(err, "creating new role")
+		}
+		return influxRole, nil
+	}
+}
+
 func (s *Service) applyVariables(ctx context.Context, vars []*stateVariable) applier {
 	const resource = "variable"
 
@@ -2785,6 +2967,17 @@ func (s *Service) updateStackAfterSuccess(ctx context.Context, stackID influxdb.
 			),
 		})
 	}
+	for _, r := range state.mRoles {
+		if IsRemoval(r.stateStatus) {
+			continue
+		}
+		stackResources = append(stackResources, StackResource{
+			APIVersion: APIVersion,
+			ID:         r.ID(),
+			Kind:       KindRole,
+			PkgName:    r.parserRole.PkgName(),
+		})
+	}
 	for _, t := range state.mTasks {
 		if IsRemoval(t.stateStatus) {
 			continue
@@ -2917,6 +3110,13 @@ func (s *Service) updateStackAfterRollback(ctx context.Context, stackID influxdb
 				res.Associations = newAss
 			}
 		}
+		for _, r := range state.mRoles {
+			res, ok := existingResources[newKey(KindRole, r.parserRole.PkgName())]
+			if ok && res.ID != r.ID() {
+				hasChanges = true
+				res.ID = r.existing.ID
+			}
+		}
 		for _, t := range state.mTasks {
 			res, ok := existingResources[newKey(KindTask, t.parserTask.PkgName())]
 			if ok && res.ID != t.ID() {
@@ -14,6 +14,7 @@ type stateCoordinator struct {
 	mDashboards map[string]*stateDashboard
 	mEndpoints  map[string]*stateEndpoint
 	mLabels     map[string]*stateLabel
+	mRoles      map[string]*stateRole
 	mRules      map[string]*stateRule
 	mTasks      map[string]*stateTask
 	mTelegrafs  map[string]*stateTelegraf
@@ -30,6 +31,7 @@ func newStateCoordinator(pkg *Pkg) *stateCoordinator {
 		mDashboards: make(map[string]*stateDashboard),
 		mEndpoints:  make(map[string]*stateEndpoint),
 		mLabels:     make(map[string]*stateLabel),
+		mRoles:      make(map[string]*stateRole),
 		mRules:      make(map[string]*stateRule),
 		mTasks:      make(map[string]*stateTask),
 		mTelegrafs:  make(map[string]*stateTelegraf),
@@ -66,6 +68,12 @@ func newStateCoordinator(pkg *Pkg) *stateCoordinator {
 			stateStatus: StateStatusNew,
 		}
 	}
+	for _, pkgRole := range pkg.roles() {
+		state.mRoles[pkgRole.PkgName()] = &stateRole{
+			parserRole:  pkgRole,
+			stateStatus: StateStatusNew,
+		}
+	}
 	for _, pkgRule := range pkg.notificationRules() {
 		state.mRules[pkgRule.PkgName()] = &stateRule{
 			parserRule:  pkgRule,
@@ -134,6 +142,14 @@ func (s *stateCoordinator) labels() []*stateLabel {
 	return out
 }
 
+func (s *stateCoordinator) roles() []*stateRole {
+	out := make([]*stateRole, 0, len(s.mRoles))
+	for _, r := range s.mRoles {
+		out = append(out, r)
+	}
+	return out
+}
+
 func (s *stateCoordinator) rules() []*stateRule {
 	out := make([]*stateRule, 0, len(s.mRules))
 	for _, r := range s.mRules {
@@ -210,6 +226,13 @@ func (s *stateCoordinator) diff() Diff {
 		return diff.NotificationRules[i].PkgName < diff.NotificationRules[j].PkgName
 	})
 
+	for _, r := range s.mRoles {
+		diff.Roles = append(diff.Roles, r.diffRole())
+	}
+	sort.Slice(diff.Roles, func(i, j int) bool {
+		return diff.Roles[i].PkgName < diff.Roles[j].PkgName
+	})
+
 	for _, t := range s.mTasks {
 		diff.Tasks = append(diff.Tasks, t.diffTask())
 	}
@@ -320,6 +343,16 @@ func (s *stateCoordinator) summary() Summary {
 		return sum.NotificationRules[i].PkgName < sum.NotificationRules[j].PkgName
 	})
 
+	for _, r := range s.mRoles {
+		if IsRemoval(r.stateStatus) {
+			continue
+		}
+		sum.Roles = append(sum.Roles, r.summarize())
+	}
+	sort.Slice(sum.Roles, func(i, j int) bool {
+		return sum.Roles[i].PkgName < sum.Roles[j].PkgName
+	})
+
 	for _, t := range s.mTasks {
 		if IsRemoval(t.stateStatus) {
 			continue
@@ -474,6 +507,9 @@ func (s *stateCoordinator) get(k Kind, pkgName string) (interface{}, bool) {
 	case KindNotificationRule:
 		v, ok := s.mRules[pkgName]
 		return v, ok
+	case KindRole:
+		v, ok := s.mRoles[pkgName]
+		return v, ok
 	case KindTask:
 		v, ok := s.mTasks[pkgName]
 		return v, ok
@@ -569,6 +605,12 @@ func (s *stateCoordinator) addObjectForRemoval(k Kind, pkgName string, id influx
 			parserRule:  &notificationRule{identity: newIdentity},
 			stateStatus: StateStatusRemove,
 		}
+	case KindRole:
+		s.mRoles[pkgName] = &stateRole{
+			id:          id,
+			parserRole:  &role{identity: newIdentity},
+			stateStatus: StateStatusRemove,
+		}
 	case KindTask:
 		s.mTasks[pkgName] = &stateTask{
 			id:          id,
@@ -631,6 +673,12 @@ func (s *stateCoordinator) getObjectIDSetter(k Kind, pkgName string) (func(influ
 			r.id = id
 			r.stateStatus = StateStatusExists
 		}, ok
+	case KindRole:
+		r, ok := s.mRoles[pkgName]
+		return func(id influxdb.ID) {
+			r.id = id
+			r.stateStatus = StateStatusExists
+		}, ok
 	case KindTask:
 		r, ok := s.mTasks[pkgName]
 		return func(id influxdb.ID) {
@@ -1391,6 +1439,61 @@ func (t *stateTelegraf) summarize() SummaryTelegraf {
 	return sum
 }
 
+type stateRole struct {
+	id, orgID   influxdb.ID
+	stateStatus StateStatus
+
+	parserRole *role
+	existing   *influxdb.Role
+}
+
+func (r *stateRole) ID() influxdb.ID {
+	if !IsNew(r.stateStatus) && r.existing != nil {
+		return r.existing.ID
+	}
+	return r.id
+}
+
+func (r *stateRole) diffRole() DiffRole {
+	diff := DiffRole{
+		DiffIdentifier: DiffIdentifier{
+			ID:          SafeID(r.ID()),
+			Remove:      IsRemoval(r.stateStatus),
+			StateStatus: r.stateStatus,
+			PkgName:     r.parserRole.PkgName(),
+		},
+		New: DiffRoleValues{
+			Name:        r.parserRole.Name(),
+			Description: r.parserRole.Description,
+			Permissions: r.parserRole.influxPermissions(r.orgID),
+		},
+	}
+	if e := r.existing; e != nil {
+		diff.Old = &DiffRoleValues{
+			Name:        e.Name,
+			Description: e.Description,
+			Permissions: e.Permissions,
+		}
+	}
+	return diff
+}
+
+func (r *stateRole) shouldApply() bool {
+	return IsRemoval(r.stateStatus) ||
+		r.existing == nil ||
+		r.existing.Name != r.parserRole.Name() ||
+		r.existing.Description != r.parserRole.Description ||
+		!reflect.DeepEqual(r.existing.Permissions, r.parserRole.influxPermissions(r.orgID))
+}
+
+func (r *stateRole) summarize() SummaryRole {
+	sum := r.parserRole.summarize()
+	sum.ID = SafeID(r.ID())
+	sum.OrgID = SafeID(r.orgID)
+	sum.Permissions = r.parserRole.influxPermissions(r.orgID)
+	return sum
+}
+
 type stateVariable struct {
 	id, orgID   influxdb.ID
 	stateStatus StateStatus
@@ -7,7 +7,6 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/influxdb/v2"
-	influxtesting "github.com/influxdata/influxdb/v2/testing"
 	"github.com/influxdata/influxql"
 )
 
@@ -81,7 +80,7 @@ func TestParseNode(t *testing.T) {
 	}
 	for _, c := range cases {
 		node, err := Parse(c.str)
-		influxtesting.ErrorsEqual(t, err, c.err)
+		errorsEqual(t, err, c.err)
 		if c.err == nil {
 			if diff := cmp.Diff(node, c.node); diff != "" {
 				t.Errorf("tag rule mismatch:\n  %s", diff)
@@ -158,7 +157,7 @@ func TestParseTagRule(t *testing.T) {
 		p := new(parser)
 		p.sc = influxql.NewScanner(strings.NewReader(c.str))
 		tr, err := p.parseTagRuleNode()
-		influxtesting.ErrorsEqual(t, err, c.err)
+		errorsEqual(t, err, c.err)
 		if c.err == nil {
 			if diff := cmp.Diff(tr, c.node); diff != "" {
 				t.Errorf("tag rule mismatch:\n  %s", diff)
@@ -166,3 +165,16 @@ func TestParseTagRule(t *testing.T) {
 		}
 	}
 }
+
+// errorsEqual checks that the errors have the same code and message. The
+// testing package can not be used as it depends on this package through the
+// authorizer.
+func errorsEqual(t *testing.T, actual, expected error) {
+	t.Helper()
+	if influxdb.ErrorCode(expected) != influxdb.ErrorCode(actual) {
+		t.Errorf("expected error code %q but received %q", influxdb.ErrorCode(expected), influxdb.ErrorCode(actual))
+	}
+	if influxdb.ErrorMessage(expected) != influxdb.ErrorMessage(actual) {
+		t.Errorf("expected error message %q but received %q", influxdb.ErrorMessage(expected), influxdb.ErrorMessage(actual))
+	}
+}
@@ -0,0 +1,6 @@
+// Package prompb contains the protocol buffer messages of the remote
+// read and write protocols of Prometheus. The definitions are copied
+// from github.com/prometheus/prometheus/prompb.
+package prompb
+
+//go:generate protoc -I ../../internal -I . --plugin ../../scripts/protoc-gen-gogofaster --gogofaster_out=. types.proto remote.proto
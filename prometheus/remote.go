@@ -0,0 +1,232 @@
+package prometheus
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/prometheus/prompb"
+	"github.com/influxdata/influxdb/v2/storage/reads"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+)
+
+const (
+	// MetricNameLabel is the label that holds the metric name of a series.
+	MetricNameLabel = "__name__"
+
+	// RemoteValueField is the field of the samples when the metric name
+	// is the measurement.
+	RemoteValueField = "value"
+)
+
+// RemoteSchema maps the samples of the Prometheus remote write and read
+// protocols to points.
+//
+// By default the metric name is the measurement and the samples are in the
+// RemoteValueField field. If Measurement is set, it is the measurement of
+// all samples, which are in the field named by the metric name. The other
+// labels of the series are tags in both cases.
+type RemoteSchema struct {
+	Measurement string
+}
+
+// Points converts the samples of the write request to points. Samples that
+// are not numbers, such as the stale markers of Prometheus, are dropped as
+// they can not be stored.
+func (s RemoteSchema) Points(req *prompb.WriteRequest) (models.Points, error) {
+	pts := make(models.Points, 0, len(req.Timeseries))
+	for _, ts := range req.Timeseries {
+		var name string
+		tags := make(models.Tags, 0, len(ts.Labels))
+		for _, l := range ts.Labels {
+			if l.Name == MetricNameLabel {
+				name = l.Value
+				continue
+			}
+			tags = append(tags, models.NewTag([]byte(l.Name), []byte(l.Value)))
+		}
+		if name == "" {
+			return nil, errors.New("series has no metric name")
+		}
+		sort.Sort(tags)
+
+		measurement, field := name, RemoteValueField
+		if s.Measurement != "" {
+			measurement, field = s.Measurement, name
+		}
+		for _, sample := range ts.Samples {
+			if math.IsNaN(sample.Value) || math.IsInf(sample.Value, 0) {
+				continue
+			}
+			tm := time.Unix(0, sample.Timestamp*nsPerMilliseconds)
+			pt, err := models.NewPoint(measurement, tags, models.Fields{field: sample.Value}, tm)
+			if err != nil {
+				return nil, fmt.Errorf("invalid series %q: %v", name, err)
+			}
+			pts = append(pts, pt)
+		}
+	}
+	return pts, nil
+}
+
+// Predicate converts the label matchers of a read query to the storage
+// predicate that selects the matching series.
+func (s RemoteSchema) Predicate(matchers []*prompb.LabelMatcher) (*datatypes.Predicate, error) {
+	var root *datatypes.Node
+	if s.Measurement != "" {
+		root = comparisonNode(models.MeasurementTagKey, datatypes.ComparisonEqual, stringNode(s.Measurement))
+	} else {
+		root = comparisonNode(models.FieldKeyTagKey, datatypes.ComparisonEqual, stringNode(RemoteValueField))
+	}
+
+	for _, m := range matchers {
+		key := m.Name
+		if key == MetricNameLabel {
+			key = models.MeasurementTagKey
+			if s.Measurement != "" {
+				key = models.FieldKeyTagKey
+			}
+		}
+
+		var n *datatypes.Node
+		switch m.Type {
+		case prompb.LabelMatcher_EQ:
+			n = comparisonNode(key, datatypes.ComparisonEqual, stringNode(m.Value))
+		case prompb.LabelMatcher_NEQ:
+			n = comparisonNode(key, datatypes.ComparisonNotEqual, stringNode(m.Value))
+		case prompb.LabelMatcher_RE:
+			n = comparisonNode(key, datatypes.ComparisonRegex, regexNode(m.Value))
+		case prompb.LabelMatcher_NRE:
+			n = comparisonNode(key, datatypes.ComparisonNotRegex, regexNode(m.Value))
+		default:
+			return nil, fmt.Errorf("unknown label matcher type %v", m.Type)
+		}
+		root = &datatypes.Node{
+			NodeType: datatypes.NodeTypeLogicalExpression,
+			Value:    &datatypes.Node_Logical_{Logical: datatypes.LogicalAnd},
+			Children: []*datatypes.Node{root, n},
+		}
+	}
+	return &datatypes.Predicate{Root: root}, nil
+}
+
+// TimeSeries reads the series of the result set. The series are labeled
+// by their tags, with the metric name mapped back from the measurement or
+// field, and the samples are converted to floats. The series are sorted
+// by their labels.
+func (s RemoteSchema) TimeSeries(rs reads.ResultSet) ([]*prompb.TimeSeries, error) {
+	var series []*prompb.TimeSeries
+	for rs.Next() {
+		ts := &prompb.TimeSeries{}
+		for _, t := range rs.Tags() {
+			switch string(t.Key) {
+			case datatypes.MeasurementKey:
+				if s.Measurement == "" {
+					ts.Labels = append(ts.Labels, &prompb.Label{Name: MetricNameLabel, Value: string(t.Value)})
+				}
+			case datatypes.FieldKey:
+				if s.Measurement != "" {
+					ts.Labels = append(ts.Labels, &prompb.Label{Name: MetricNameLabel, Value: string(t.Value)})
+				}
+			default:
+				ts.Labels = append(ts.Labels, &prompb.Label{Name: string(t.Key), Value: string(t.Value)})
+			}
+		}
+		sort.Slice(ts.Labels, func(i, j int) bool { return ts.Labels[i].Name < ts.Labels[j].Name })
+
+		samples, err := readSamples(rs.Cursor())
+		if err != nil {
+			return nil, err
+		}
+		if len(samples) == 0 {
+			continue
+		}
+		ts.Samples = samples
+		series = append(series, ts)
+	}
+	sort.Slice(series, func(i, j int) bool {
+		return labelsLess(series[i].Labels, series[j].Labels)
+	})
+	return series, rs.Err()
+}
+
+// labelsLess compares sorted labels by name then value.
+func labelsLess(a, b []*prompb.Label) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i].Name != b[i].Name {
+			return a[i].Name < b[i].Name
+		}
+		if a[i].Value != b[i].Value {
+			return a[i].Value < b[i].Value
+		}
+	}
+	return len(a) < len(b)
+}
+
+// readSamples reads the values of a numeric cursor.
+func readSamples(cur cursors.Cursor) ([]prompb.Sample, error) {
+	if cur == nil {
+		return nil, nil
+	}
+	defer cur.Close()
+
+	var samples []prompb.Sample
+	add := func(ts int64, v float64) {
+		samples = append(samples, prompb.Sample{Timestamp: ts / nsPerMilliseconds, Value: v})
+	}
+	switch c := cur.(type) {
+	case cursors.FloatArrayCursor:
+		for a := c.Next(); a.Len() > 0; a = c.Next() {
+			for i, ts := range a.Timestamps {
+				add(ts, a.Values[i])
+			}
+		}
+	case cursors.IntegerArrayCursor:
+		for a := c.Next(); a.Len() > 0; a = c.Next() {
+			for i, ts := range a.Timestamps {
+				add(ts, float64(a.Values[i]))
+			}
+		}
+	case cursors.UnsignedArrayCursor:
+		for a := c.Next(); a.Len() > 0; a = c.Next() {
+			for i, ts := range a.Timestamps {
+				add(ts, float64(a.Values[i]))
+			}
+		}
+	}
+	return samples, cur.Err()
+}
+
+func comparisonNode(key string, op datatypes.Node_Comparison, value *datatypes.Node) *datatypes.Node {
+	return &datatypes.Node{
+		NodeType: datatypes.NodeTypeComparisonExpression,
+		Value:    &datatypes.Node_Comparison_{Comparison: op},
+		Children: []*datatypes.Node{
+			{
+				NodeType: datatypes.NodeTypeTagRef,
+				Value:    &datatypes.Node_TagRefValue{TagRefValue: key},
+			},
+			value,
+		},
+	}
+}
+
+func stringNode(s string) *datatypes.Node {
+	return &datatypes.Node{
+		NodeType: datatypes.NodeTypeLiteral,
+		Value:    &datatypes.Node_StringValue{StringValue: s},
+	}
+}
+
+// regexNode returns the regular expression anchored at both ends, as
+// the regular expressions of Prometheus must match the whole value.
+func regexNode(re string) *datatypes.Node {
+	return &datatypes.Node{
+		NodeType: datatypes.NodeTypeLiteral,
+		Value:    &datatypes.Node_RegexValue{RegexValue: "^(?:" + re + ")$"},
+	}
+}
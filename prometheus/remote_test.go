@@ -0,0 +1,110 @@
+package prometheus_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	pr "github.com/influxdata/influxdb/v2/prometheus"
+	"github.com/influxdata/influxdb/v2/prometheus/prompb"
+	"github.com/influxdata/influxdb/v2/storage/reads"
+)
+
+func TestRemoteSchema_Points(t *testing.T) {
+	req := &prompb.WriteRequest{
+		Timeseries: []*prompb.TimeSeries{
+			{
+				Labels: []*prompb.Label{
+					{Name: "job", Value: "node"},
+					{Name: "__name__", Value: "up"},
+					{Name: "instance", Value: "a"},
+				},
+				Samples: []prompb.Sample{
+					{Timestamp: 1000, Value: 1},
+					{Timestamp: 2000, Value: math.NaN()},
+					{Timestamp: 3000, Value: 0.5},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		schema pr.RemoteSchema
+		want   []string
+	}{
+		{
+			name: "metric name measurement",
+			want: []string{
+				"up,instance=a,job=node value=1 1000000000",
+				"up,instance=a,job=node value=0.5 3000000000",
+			},
+		},
+		{
+			name:   "single measurement",
+			schema: pr.RemoteSchema{Measurement: "prometheus"},
+			want: []string{
+				"prometheus,instance=a,job=node up=1 1000000000",
+				"prometheus,instance=a,job=node up=0.5 3000000000",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pts, err := tt.schema.Points(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]string, len(pts))
+			for i, pt := range pts {
+				got[i] = pt.String()
+			}
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("unexpected points -want/+got:\n%s", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+
+	_, err := pr.RemoteSchema{}.Points(&prompb.WriteRequest{
+		Timeseries: []*prompb.TimeSeries{{Labels: []*prompb.Label{{Name: "job", Value: "node"}}}},
+	})
+	if err == nil {
+		t.Error("expected an error for a series without a metric name")
+	}
+}
+
+func TestRemoteSchema_Predicate(t *testing.T) {
+	matchers := []*prompb.LabelMatcher{
+		{Type: prompb.LabelMatcher_EQ, Name: "__name__", Value: "up"},
+		{Type: prompb.LabelMatcher_NEQ, Name: "job", Value: "node"},
+		{Type: prompb.LabelMatcher_RE, Name: "instance", Value: "a|b"},
+		{Type: prompb.LabelMatcher_NRE, Name: "env", Value: "dev.*"},
+	}
+
+	tests := []struct {
+		name   string
+		schema pr.RemoteSchema
+		want   string
+	}{
+		{
+			name: "metric name measurement",
+			want: "'\xff' = \"value\" AND '\x00' = \"up\" AND 'job' != \"node\" AND 'instance' =~ /^(?:a|b)$/ AND 'env' !~ /^(?:dev.*)$/",
+		},
+		{
+			name:   "single measurement",
+			schema: pr.RemoteSchema{Measurement: "prometheus"},
+			want:   "'\x00' = \"prometheus\" AND '\xff' = \"up\" AND 'job' != \"node\" AND 'instance' =~ /^(?:a|b)$/ AND 'env' !~ /^(?:dev.*)$/",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pred, err := tt.schema.Predicate(matchers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := reads.PredicateToExprString(pred); got != tt.want {
+				t.Errorf("unexpected predicate:\ngot:  %s\nwant: %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// ActiveQuery describes a query that is queued or executing.
+type ActiveQuery struct {
+	// ID is the ephemeral identifier of the query within the process
+	// that executes it.
+	ID uint64 `json:"id"`
+
+	OrganizationID   influxdb.ID `json:"orgID"`
+	UserID           influxdb.ID `json:"userID,omitempty"`
+	TokenDescription string      `json:"tokenDescription,omitempty"`
+
+	Query        string `json:"query"`
+	CompilerType string `json:"compilerType"`
+	State        string `json:"state"`
+
+	StartedAt   time.Time     `json:"startedAt"`
+	Elapsed     time.Duration `json:"elapsed"`
+	MemoryBytes int64         `json:"memoryBytes"`
+}
+
+// ActiveQueryFilter selects the active queries to report.
+type ActiveQueryFilter struct {
+	OrganizationID *influxdb.ID
+}
+
+// ActiveQueryService reports and cancels the queries that are in flight.
+type ActiveQueryService interface {
+	// FindActiveQueries returns the active queries that match the filter.
+	FindActiveQueries(ctx context.Context, filter ActiveQueryFilter) ([]*ActiveQuery, error)
+
+	// FindActiveQueryByID returns the active query with the given ID.
+	FindActiveQueryByID(ctx context.Context, id uint64) (*ActiveQuery, error)
+
+	// CancelActiveQuery cancels the active query with the given ID.
+	CancelActiveQuery(ctx context.Context, id uint64) error
+}
+
+// ErrActiveQueryNotFound is returned when a query is not, or no longer, active.
+var ErrActiveQueryNotFound = &influxdb.Error{
+	Code: influxdb.ENotFound,
+	Msg:  "query not found",
+}
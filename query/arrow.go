@@ -0,0 +1,188 @@
+package query
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/iocounter"
+)
+
+const (
+	ArrowDialectType = "arrow"
+
+	// ArrowContentType is the media type of the Arrow IPC stream format.
+	ArrowContentType = "application/vnd.apache.arrow.stream"
+)
+
+// The keys of the schema metadata of the Arrow streams of the tables.
+const (
+	// ArrowResultKey is the name of the result of the table.
+	ArrowResultKey = "flux.result"
+	// ArrowTableKey is the index of the table within its result.
+	ArrowTableKey = "flux.table"
+	// ArrowGroupKeyKey is a JSON array of the columns of the group key.
+	ArrowGroupKeyKey = "flux.group_key"
+	// ArrowErrorKey is the error of a query that failed after its
+	// results started to be written.
+	ArrowErrorKey = "flux.error"
+)
+
+// ArrowDialect is a dialect that encodes query results in the Arrow IPC
+// stream format.
+type ArrowDialect struct{}
+
+func NewArrowDialect() *ArrowDialect {
+	return &ArrowDialect{}
+}
+
+func (d *ArrowDialect) Encoder() flux.MultiResultEncoder {
+	return NewArrowEncoder(memory.DefaultAllocator)
+}
+
+func (d *ArrowDialect) DialectType() flux.DialectType {
+	return ArrowDialectType
+}
+
+func (d *ArrowDialect) SetHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", ArrowContentType)
+	w.Header().Set("Transfer-Encoding", "chunked")
+}
+
+// ArrowEncoder encodes each table of the results as an Arrow IPC stream,
+// which is written as the record batches of the buffers of the table.
+// The streams are written one after the other, so readers open a stream
+// for each table until the end of the response. The schema metadata of
+// each stream identifies its table.
+//
+// If the query fails after the results started to be written, the error
+// is written as a final stream with no fields, and the ArrowErrorKey
+// metadata set to the error.
+type ArrowEncoder struct {
+	mem memory.Allocator
+}
+
+func NewArrowEncoder(mem memory.Allocator) *ArrowEncoder {
+	return &ArrowEncoder{mem: mem}
+}
+
+func (e *ArrowEncoder) Encode(w io.Writer, results flux.ResultIterator) (int64, error) {
+	defer results.Release()
+
+	cw := &iocounter.Writer{Writer: w}
+	err := func() error {
+		for results.More() {
+			res := results.Next()
+			n := 0
+			if err := res.Tables().Do(func(tbl flux.Table) error {
+				defer func() { n++ }()
+				return e.encodeTable(cw, res.Name(), n, tbl)
+			}); err != nil {
+				return err
+			}
+		}
+		return results.Err()
+	}()
+	if err != nil {
+		md := arrow.NewMetadata([]string{ArrowErrorKey}, []string{err.Error()})
+		_ = ipc.NewWriter(cw, ipc.WithSchema(arrow.NewSchema(nil, &md)), ipc.WithAllocator(e.mem)).Close()
+	}
+	return cw.Count(), err
+}
+
+func (e *ArrowEncoder) encodeTable(w io.Writer, result string, n int, tbl flux.Table) error {
+	schema, err := ArrowSchema(result, n, tbl.Key(), tbl.Cols())
+	if err != nil {
+		return err
+	}
+	iw := ipc.NewWriter(w, ipc.WithSchema(schema), ipc.WithAllocator(e.mem))
+	if err := tbl.Do(func(cr flux.ColReader) error {
+		rec := ArrowRecord(schema, cr)
+		defer rec.Release()
+		return iw.Write(rec)
+	}); err != nil {
+		return err
+	}
+	return iw.Close()
+}
+
+// ArrowSchema returns the schema of the Arrow stream of a table.
+func ArrowSchema(result string, n int, key flux.GroupKey, cols []flux.ColMeta) (*arrow.Schema, error) {
+	fields := make([]arrow.Field, len(cols))
+	for j, c := range cols {
+		fields[j] = arrow.Field{Name: c.Label, Type: arrowType(c.Type), Nullable: true}
+	}
+
+	groupKey := make([]string, len(key.Cols()))
+	for j, c := range key.Cols() {
+		groupKey[j] = c.Label
+	}
+	gk, err := json.Marshal(groupKey)
+	if err != nil {
+		return nil, err
+	}
+	md := arrow.NewMetadata(
+		[]string{ArrowResultKey, ArrowTableKey, ArrowGroupKeyKey},
+		[]string{result, strconv.Itoa(n), string(gk)},
+	)
+	return arrow.NewSchema(fields, &md), nil
+}
+
+// ArrowRecord returns the buffer of a table as a record of the schema.
+// Strings and times, which are stored as binary and integer arrays, are
+// given the string and timestamp types of the schema.
+func ArrowRecord(schema *arrow.Schema, cr flux.ColReader) array.Record {
+	cols := make([]array.Interface, len(cr.Cols()))
+	for j, c := range cr.Cols() {
+		var data *array.Data
+		switch c.Type {
+		case flux.TBool:
+			data = cr.Bools(j).Data()
+		case flux.TInt:
+			data = cr.Ints(j).Data()
+		case flux.TUInt:
+			data = cr.UInts(j).Data()
+		case flux.TFloat:
+			data = cr.Floats(j).Data()
+		case flux.TString:
+			data = cr.Strings(j).Data()
+		case flux.TTime:
+			data = cr.Times(j).Data()
+		default:
+			data = array.NewData(arrow.Null, cr.Len(), []*memory.Buffer{nil}, nil, cr.Len(), 0)
+		}
+		data = array.NewData(schema.Field(j).Type, data.Len(), data.Buffers(), nil, data.NullN(), data.Offset())
+		cols[j] = array.MakeFromData(data)
+		data.Release()
+	}
+	rec := array.NewRecord(schema, cols, int64(cr.Len()))
+	for _, col := range cols {
+		col.Release()
+	}
+	return rec
+}
+
+func arrowType(typ flux.ColType) arrow.DataType {
+	switch typ {
+	case flux.TBool:
+		return arrow.FixedWidthTypes.Boolean
+	case flux.TInt:
+		return arrow.PrimitiveTypes.Int64
+	case flux.TUInt:
+		return arrow.PrimitiveTypes.Uint64
+	case flux.TFloat:
+		return arrow.PrimitiveTypes.Float64
+	case flux.TString:
+		return arrow.BinaryTypes.String
+	case flux.TTime:
+		return &arrow.TimestampType{Unit: arrow.Nanosecond, TimeZone: "UTC"}
+	default:
+		return arrow.Null
+	}
+}
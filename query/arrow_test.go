@@ -0,0 +1,172 @@
+package query_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/influxdb/v2/query"
+)
+
+// arrowStream is a decoded Arrow stream of a table.
+type arrowStream struct {
+	Metadata map[string]string
+	Fields   []string
+	Rows     [][]interface{}
+}
+
+func readArrowStreams(t *testing.T, data []byte) []arrowStream {
+	t.Helper()
+	var streams []arrowStream
+	r := bytes.NewReader(data)
+	for r.Len() > 0 {
+		rdr, err := ipc.NewReader(r, ipc.WithAllocator(memory.DefaultAllocator))
+		if err != nil {
+			t.Fatal(err)
+		}
+		schema := rdr.Schema()
+		s := arrowStream{Metadata: make(map[string]string)}
+		md := schema.Metadata()
+		for i, k := range md.Keys() {
+			s.Metadata[k] = md.Values()[i]
+		}
+		for _, f := range schema.Fields() {
+			s.Fields = append(s.Fields, f.Name+":"+f.Type.Name())
+		}
+		for rdr.Next() {
+			rec := rdr.Record()
+			for i := 0; i < int(rec.NumRows()); i++ {
+				row := make([]interface{}, rec.NumCols())
+				for j, col := range rec.Columns() {
+					if col.IsNull(i) {
+						continue
+					}
+					switch col := col.(type) {
+					case *array.Timestamp:
+						row[j] = int64(col.Value(i))
+					case *array.Float64:
+						row[j] = col.Value(i)
+					case *array.Int64:
+						row[j] = col.Value(i)
+					case *array.String:
+						row[j] = col.Value(i)
+					case *array.Boolean:
+						row[j] = col.Value(i)
+					}
+				}
+				s.Rows = append(s.Rows, row)
+			}
+		}
+		rdr.Release()
+		streams = append(streams, s)
+	}
+	return streams
+}
+
+func TestArrowEncoder(t *testing.T) {
+	result := executetest.NewResult([]*executetest.Table{
+		{
+			KeyCols: []string{"t"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_value", Type: flux.TFloat},
+				{Label: "t", Type: flux.TString},
+				{Label: "ok", Type: flux.TBool},
+			},
+			Data: [][]interface{}{
+				{execute.Time(0), 1.0, "a", true},
+				{execute.Time(10), nil, "a", false},
+			},
+		},
+		{
+			KeyCols: []string{"t"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_value", Type: flux.TInt},
+				{Label: "t", Type: flux.TString},
+			},
+			Data: [][]interface{}{
+				{int64(3), "b"},
+			},
+		},
+	})
+	result.Nm = "_result"
+
+	var buf bytes.Buffer
+	n, err := query.NewArrowEncoder(memory.DefaultAllocator).Encode(&buf, flux.NewSliceResultIterator([]flux.Result{result}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("unexpected number of bytes written: got %d want %d", n, buf.Len())
+	}
+
+	want := []arrowStream{
+		{
+			Metadata: map[string]string{
+				query.ArrowResultKey:   "_result",
+				query.ArrowTableKey:    "0",
+				query.ArrowGroupKeyKey: `["t"]`,
+			},
+			Fields: []string{"_time:timestamp", "_value:float64", "t:utf8", "ok:bool"},
+			Rows: [][]interface{}{
+				{int64(0), 1.0, "a", true},
+				{int64(10), nil, "a", false},
+			},
+		},
+		{
+			Metadata: map[string]string{
+				query.ArrowResultKey:   "_result",
+				query.ArrowTableKey:    "1",
+				query.ArrowGroupKeyKey: `["t"]`,
+			},
+			Fields: []string{"_value:int64", "t:utf8"},
+			Rows: [][]interface{}{
+				{int64(3), "b"},
+			},
+		},
+	}
+	if got := readArrowStreams(t, buf.Bytes()); !cmp.Equal(want, got) {
+		t.Errorf("unexpected streams -want/+got:\n%s", cmp.Diff(want, got))
+	}
+}
+
+func TestArrowEncoder_Error(t *testing.T) {
+	result := executetest.NewResult([]*executetest.Table{{
+		ColMeta: []flux.ColMeta{{Label: "_value", Type: flux.TFloat}},
+		Data:    [][]interface{}{{1.0}},
+	}})
+	result.Nm = "_result"
+	results := flux.NewSliceResultIterator([]flux.Result{result, &errResult{err: errors.New("expected error")}})
+
+	var buf bytes.Buffer
+	if _, err := query.NewArrowEncoder(memory.DefaultAllocator).Encode(&buf, results); err == nil {
+		t.Fatal("expected an error")
+	}
+	streams := readArrowStreams(t, buf.Bytes())
+	if len(streams) != 2 {
+		t.Fatalf("expected the table and the error, got %d streams", len(streams))
+	}
+	if got, want := streams[1].Metadata[query.ArrowErrorKey], "expected error"; got != want {
+		t.Errorf("unexpected error: got %q want %q", got, want)
+	}
+}
+
+// errResult is a result whose tables fail.
+type errResult struct {
+	err error
+}
+
+func (r *errResult) Name() string { return "_error" }
+
+func (r *errResult) Tables() flux.TableIterator { return r }
+
+func (r *errResult) Do(f func(flux.Table) error) error { return r.err }
+
+func (r *errResult) Statistics() flux.Statistics { return flux.Statistics{} }
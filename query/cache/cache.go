@@ -0,0 +1,222 @@
+// Package cache caches the encoded results of queries, such as the
+// queries that dashboards repeat each time they refresh. The results are
+// invalidated when the storage engine writes to or deletes from the time
+// ranges of the buckets that they read.
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	platform "github.com/influxdata/influxdb/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultMaxEntryBytes is the default size of the largest result cached.
+	DefaultMaxEntryBytes = 1 << 20
+
+	// DefaultTTL is the default time that a result is cached for.
+	DefaultTTL = 10 * time.Second
+)
+
+// Config configures a Cache.
+type Config struct {
+	// MaxBytes is the total size of the results that are cached.
+	// The least recently used results are evicted to stay below it.
+	MaxBytes int64
+	// MaxEntryBytes is the size of the largest result that is cached.
+	MaxEntryBytes int64
+	// TTL is the time that a result is cached for. The now time of
+	// the queries is truncated to it, so repeated queries with relative
+	// time ranges resolve to the same bounds until it elapses.
+	TTL time.Duration
+}
+
+// Cache stores the results of queries along with the time ranges of the
+// buckets that they read.
+type Cache struct {
+	config Config
+	log    *zap.Logger
+	now    func() time.Time
+
+	mu       sync.Mutex
+	lru      *list.List
+	entries  map[string]*list.Element
+	byBucket map[platform.ID]map[*entry]struct{}
+	size     int64
+
+	// seq is incremented each time a bucket is modified and modified
+	// holds the last seq of each bucket. Results that read a bucket
+	// that was modified while they were executing are not cached.
+	seq      uint64
+	modified map[platform.ID]uint64
+
+	metrics *metrics
+}
+
+type entry struct {
+	key     string
+	data    []byte
+	stats   flux.Statistics
+	reads   []bucketRead
+	expires time.Time
+}
+
+func (e *entry) size() int64 {
+	return int64(len(e.key) + len(e.data))
+}
+
+type bucketRead struct {
+	orgID    platform.ID
+	bucketID platform.ID
+	bounds   execute.Bounds
+}
+
+// New creates a cache of query results.
+func New(log *zap.Logger, c Config) *Cache {
+	if c.MaxEntryBytes <= 0 {
+		c.MaxEntryBytes = DefaultMaxEntryBytes
+	}
+	if c.MaxEntryBytes > c.MaxBytes {
+		c.MaxEntryBytes = c.MaxBytes
+	}
+	return &Cache{
+		config:   c,
+		log:      log,
+		now:      time.Now,
+		lru:      list.New(),
+		entries:  make(map[string]*list.Element),
+		byBucket: make(map[platform.ID]map[*entry]struct{}),
+		modified: make(map[platform.ID]uint64),
+		metrics:  newMetrics(),
+	}
+}
+
+// PrometheusCollectors returns the metrics of the cache.
+func (c *Cache) PrometheusCollectors() []prometheus.Collector {
+	return c.metrics.PrometheusCollectors()
+}
+
+// BucketModified invalidates the results that read the time range of the
+// bucket that was written to or deleted from.
+func (c *Cache) BucketModified(orgID, bucketID platform.ID, min, max int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.seq++
+	c.modified[bucketID] = c.seq
+	for e := range c.byBucket[bucketID] {
+		for _, r := range e.reads {
+			if r.bucketID == bucketID && min < int64(r.bounds.Stop) && max >= int64(r.bounds.Start) {
+				c.remove(e)
+				c.metrics.evictions.WithLabelValues(evictedWrite).Inc()
+				break
+			}
+		}
+	}
+}
+
+// get returns the result cached for the key if it has not expired.
+func (c *Cache) get(key string) (*entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*entry)
+	if c.config.TTL > 0 && !c.now().Before(e.expires) {
+		c.remove(e)
+		c.metrics.evictions.WithLabelValues(evictedTTL).Inc()
+		return nil, false
+	}
+	c.lru.MoveToFront(el)
+	return e, true
+}
+
+// sequence returns the sequence of the last modification of a bucket.
+func (c *Cache) sequence() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.seq
+}
+
+// put caches a result unless a bucket that it read was modified after
+// the sequence seq.
+func (c *Cache) put(e *entry, seq uint64) {
+	if e.size() > c.config.MaxEntryBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, r := range e.reads {
+		if c.modified[r.bucketID] > seq {
+			return
+		}
+	}
+
+	if el, ok := c.entries[e.key]; ok {
+		c.remove(el.Value.(*entry))
+	}
+	if c.config.TTL > 0 {
+		e.expires = c.now().Add(c.config.TTL)
+	}
+	c.entries[e.key] = c.lru.PushFront(e)
+	for _, r := range e.reads {
+		es, ok := c.byBucket[r.bucketID]
+		if !ok {
+			es = make(map[*entry]struct{})
+			c.byBucket[r.bucketID] = es
+		}
+		es[e] = struct{}{}
+	}
+	c.size += e.size()
+
+	for c.size > c.config.MaxBytes {
+		c.remove(c.lru.Back().Value.(*entry))
+		c.metrics.evictions.WithLabelValues(evictedSize).Inc()
+	}
+	c.metrics.entries.Set(float64(len(c.entries)))
+	c.metrics.size.Set(float64(c.size))
+}
+
+// remove removes an entry from the cache. The lock must be held.
+func (c *Cache) remove(e *entry) {
+	el, ok := c.entries[e.key]
+	if !ok || el.Value.(*entry) != e {
+		return
+	}
+	c.lru.Remove(el)
+	delete(c.entries, e.key)
+	for _, r := range e.reads {
+		if es, ok := c.byBucket[r.bucketID]; ok {
+			delete(es, e)
+			if len(es) == 0 {
+				delete(c.byBucket, r.bucketID)
+			}
+		}
+	}
+	c.size -= e.size()
+	c.metrics.entries.Set(float64(len(c.entries)))
+	c.metrics.size.Set(float64(c.size))
+}
+
+// readRecorder records the buckets that a query reads.
+type readRecorder struct {
+	mu    sync.Mutex
+	reads []bucketRead
+}
+
+func (r *readRecorder) BucketRead(orgID, bucketID platform.ID, bounds execute.Bounds) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reads = append(r.reads, bucketRead{orgID: orgID, bucketID: bucketID, bounds: bounds})
+}
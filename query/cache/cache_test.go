@@ -0,0 +1,275 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/lang"
+	platform "github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/query/mock"
+	"github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb"
+	"go.uber.org/zap/zaptest"
+)
+
+var (
+	orgID    = platform.ID(1)
+	bucketID = platform.ID(2)
+	now      = time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+)
+
+// fakeService counts the queries it performs and reads an hour of the
+// bucket before the now time of each.
+type fakeService struct {
+	mock.ProxyQueryService
+	calls int
+}
+
+func newFakeService() *fakeService {
+	s := &fakeService{}
+	s.QueryF = func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+		s.calls++
+		n := req.Request.Compiler.(lang.FluxCompiler).Now
+		influxdb.ObserveRead(ctx, orgID, bucketID, execute.Bounds{
+			Start: execute.Time(n.Add(-time.Hour).UnixNano()),
+			Stop:  execute.Time(n.UnixNano()),
+		})
+		_, err := fmt.Fprintf(w, "result %d", s.calls)
+		return flux.Statistics{TotalDuration: time.Second}, err
+	}
+	return s
+}
+
+func newTestCache(t *testing.T, c Config) *Cache {
+	cache := New(zaptest.NewLogger(t), c)
+	cache.now = func() time.Time { return now }
+	return cache
+}
+
+func newRequest(q string) *query.ProxyRequest {
+	return &query.ProxyRequest{
+		Request: query.Request{
+			Authorization:  &platform.Authorization{OrgID: orgID},
+			OrganizationID: orgID,
+			Compiler:       lang.FluxCompiler{Now: now, Query: q},
+		},
+		Dialect: &csv.Dialect{},
+	}
+}
+
+const testQuery = `from(bucket: "b") |> range(start: -1h)`
+
+func mustQuery(t *testing.T, s *ProxyQueryService, req *query.ProxyRequest) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if _, err := s.Query(context.Background(), &buf, req); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestProxyQueryService_Hit(t *testing.T) {
+	fake := newFakeService()
+	c := newTestCache(t, Config{MaxBytes: 1024, TTL: time.Minute})
+	c.now = func() time.Time { return now.Add(40 * time.Second) }
+	s := NewProxyQueryService(c, fake)
+
+	if got, want := mustQuery(t, s, newRequest(testQuery)), "result 1"; got != want {
+		t.Fatalf("unexpected result: got %q want %q", got, want)
+	}
+	// The query is normalized and the now time is truncated to the TTL.
+	req := newRequest("from(bucket:\"b\")\n\t|> range(start: -1h)")
+	req.Request.Compiler = lang.FluxCompiler{Now: now.Add(30 * time.Second), Query: req.Request.Compiler.(lang.FluxCompiler).Query}
+	if got, want := mustQuery(t, s, req), "result 1"; got != want {
+		t.Fatalf("unexpected result: got %q want %q", got, want)
+	}
+	if fake.calls != 1 {
+		t.Fatalf("expected one query to be performed, got %d", fake.calls)
+	}
+
+	// Different organizations and permissions do not share results.
+	req = newRequest(testQuery)
+	req.Request.Authorization = &platform.Authorization{OrgID: orgID, Permissions: platform.OperPermissions()}
+	if got, want := mustQuery(t, s, req), "result 2"; got != want {
+		t.Fatalf("unexpected result: got %q want %q", got, want)
+	}
+}
+
+func TestProxyQueryService_Bypass(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(req *query.ProxyRequest)
+	}{
+		{
+			name:   "no cache",
+			modify: func(req *query.ProxyRequest) { req.Request.NoCache = true },
+		},
+		{
+			name:   "profile",
+			modify: func(req *query.ProxyRequest) { req.Request.Profile = true },
+		},
+		{
+			name:   "no authorization",
+			modify: func(req *query.ProxyRequest) { req.Request.Authorization = nil },
+		},
+		{
+			name:   "dialect",
+			modify: func(req *query.ProxyRequest) { req.Dialect = &query.NoContentDialect{} },
+		},
+		{
+			name: "to",
+			modify: func(req *query.ProxyRequest) {
+				req.Request.Compiler = lang.FluxCompiler{Now: now, Query: testQuery + ` |> to(bucket: "c")`}
+			},
+		},
+		{
+			name: "http",
+			modify: func(req *query.ProxyRequest) {
+				req.Request.Compiler = lang.FluxCompiler{Now: now, Query: `import "http"` + "\n" + testQuery}
+			},
+		},
+		{
+			name: "syntax error",
+			modify: func(req *query.ProxyRequest) {
+				req.Request.Compiler = lang.FluxCompiler{Now: now, Query: testQuery + ` |>`}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeService()
+			s := NewProxyQueryService(newTestCache(t, Config{MaxBytes: 1024, TTL: time.Minute}), fake)
+			for i := 0; i < 2; i++ {
+				req := newRequest(testQuery)
+				tt.modify(req)
+				mustQuery(t, s, req)
+			}
+			if fake.calls != 2 {
+				t.Fatalf("expected the queries to bypass the cache, got %d calls", fake.calls)
+			}
+		})
+	}
+}
+
+func TestProxyQueryService_Invalidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		bucketID    platform.ID
+		min, max    time.Time
+		invalidated bool
+	}{
+		{
+			name:        "overlapping",
+			bucketID:    bucketID,
+			min:         now.Add(-2 * time.Hour),
+			max:         now.Add(-30 * time.Minute),
+			invalidated: true,
+		},
+		{
+			name:     "before",
+			bucketID: bucketID,
+			min:      now.Add(-3 * time.Hour),
+			max:      now.Add(-2 * time.Hour),
+		},
+		{
+			name:     "at stop",
+			bucketID: bucketID,
+			min:      now,
+			max:      now.Add(time.Hour),
+		},
+		{
+			name:     "other bucket",
+			bucketID: platform.ID(3),
+			min:      now.Add(-30 * time.Minute),
+			max:      now.Add(-30 * time.Minute),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeService()
+			c := newTestCache(t, Config{MaxBytes: 1024, TTL: time.Minute})
+			s := NewProxyQueryService(c, fake)
+
+			mustQuery(t, s, newRequest(testQuery))
+			c.BucketModified(orgID, tt.bucketID, tt.min.UnixNano(), tt.max.UnixNano())
+			mustQuery(t, s, newRequest(testQuery))
+
+			want := 1
+			if tt.invalidated {
+				want = 2
+			}
+			if fake.calls != want {
+				t.Fatalf("unexpected number of queries performed: got %d want %d", fake.calls, want)
+			}
+		})
+	}
+}
+
+// TestProxyQueryService_ModifiedDuringQuery checks that results are not
+// cached if the buckets they read are modified while they are executing.
+func TestProxyQueryService_ModifiedDuringQuery(t *testing.T) {
+	fake := newFakeService()
+	c := newTestCache(t, Config{MaxBytes: 1024, TTL: time.Minute})
+	s := NewProxyQueryService(c, fake)
+
+	queryF := fake.QueryF
+	fake.QueryF = func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+		c.BucketModified(orgID, bucketID, now.Add(-time.Minute).UnixNano(), now.Add(-time.Minute).UnixNano())
+		return queryF(ctx, w, req)
+	}
+	mustQuery(t, s, newRequest(testQuery))
+	mustQuery(t, s, newRequest(testQuery))
+	if fake.calls != 2 {
+		t.Fatalf("expected the result not to be cached, got %d calls", fake.calls)
+	}
+}
+
+func TestProxyQueryService_TTL(t *testing.T) {
+	fake := newFakeService()
+	c := newTestCache(t, Config{MaxBytes: 1024, TTL: time.Minute})
+	s := NewProxyQueryService(c, fake)
+
+	mustQuery(t, s, newRequest(testQuery))
+	c.now = func() time.Time { return now.Add(time.Minute) }
+	// The result expired.
+	if got, want := mustQuery(t, s, newRequest(testQuery)), "result 2"; got != want {
+		t.Fatalf("unexpected result: got %q want %q", got, want)
+	}
+}
+
+func TestProxyQueryService_Size(t *testing.T) {
+	fake := newFakeService()
+	c := newTestCache(t, Config{MaxBytes: 200, TTL: time.Minute})
+	s := NewProxyQueryService(c, fake)
+
+	queries := []string{testQuery, testQuery + ` |> count()`, testQuery + ` |> sum()`}
+	for _, q := range queries {
+		mustQuery(t, s, newRequest(q))
+	}
+	if got, want := len(c.entries), 2; got != want {
+		t.Fatalf("unexpected number of entries: got %d want %d", got, want)
+	}
+	if c.size > c.config.MaxBytes {
+		t.Fatalf("cache size %d exceeds the maximum %d", c.size, c.config.MaxBytes)
+	}
+	// The least recently used result was evicted.
+	mustQuery(t, s, newRequest(queries[0]))
+	if got, want := fake.calls, 4; got != want {
+		t.Fatalf("unexpected number of queries performed: got %d want %d", got, want)
+	}
+
+	// Results larger than the maximum entry size are not cached.
+	c = newTestCache(t, Config{MaxBytes: 1024, MaxEntryBytes: 4, TTL: time.Minute})
+	s = NewProxyQueryService(c, fake)
+	mustQuery(t, s, newRequest(testQuery))
+	if len(c.entries) != 0 {
+		t.Fatalf("expected no entries, got %d", len(c.entries))
+	}
+}
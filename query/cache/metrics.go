@@ -0,0 +1,67 @@
+package cache
+
+import "github.com/prometheus/client_golang/prometheus"
+
+const (
+	resultHit    = "hit"
+	resultMiss   = "miss"
+	resultBypass = "bypass"
+
+	evictedSize  = "size"
+	evictedTTL   = "ttl"
+	evictedWrite = "write"
+)
+
+type metrics struct {
+	requests  *prometheus.CounterVec
+	evictions *prometheus.CounterVec
+
+	entries prometheus.Gauge
+	size    prometheus.Gauge
+}
+
+func newMetrics() *metrics {
+	const (
+		namespace = "query"
+		subsystem = "cache"
+	)
+
+	return &metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "requests_total",
+			Help:      "Count of the queries looked up in the cache by result (hit, miss or bypass)",
+		}, []string{"result"}),
+
+		evictions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "evictions_total",
+			Help:      "Count of the cached results removed by reason (size, ttl or write)",
+		}, []string{"reason"}),
+
+		entries: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "entries",
+			Help:      "Number of cached results",
+		}),
+
+		size: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "size_bytes",
+			Help:      "Size of the cached results",
+		}),
+	}
+}
+
+func (m *metrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.requests,
+		m.evictions,
+		m.entries,
+		m.size,
+	}
+}
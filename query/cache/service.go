@@ -0,0 +1,223 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb/v2/kit/check"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb"
+)
+
+// sideEffectPackages are the packages whose functions have effects other
+// than returning tables. Queries that import them are never cached, nor
+// are queries that call a function named to.
+var sideEffectPackages = map[string]bool{
+	"experimental/mqtt":           true,
+	"http":                        true,
+	"influxdata/influxdb/monitor": true,
+	"kafka":                       true,
+	"pagerduty":                   true,
+	"pushbullet":                  true,
+	"slack":                       true,
+}
+
+// ProxyQueryService caches the results of the Flux queries of a
+// ProxyQueryService that are encoded as CSV.
+type ProxyQueryService struct {
+	cache *Cache
+	proxy query.ProxyQueryService
+}
+
+// NewProxyQueryService wraps the service with the cache.
+func NewProxyQueryService(c *Cache, s query.ProxyQueryService) *ProxyQueryService {
+	return &ProxyQueryService{cache: c, proxy: s}
+}
+
+// Query writes the cached result of the query if there is one and
+// otherwise performs the query and caches its result.
+func (s *ProxyQueryService) Query(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	preq, key, ok := s.cache.key(req)
+	if !ok {
+		s.cache.metrics.requests.WithLabelValues(resultBypass).Inc()
+		return s.proxy.Query(ctx, w, req)
+	}
+
+	if e, ok := s.cache.get(key); ok {
+		s.cache.metrics.requests.WithLabelValues(resultHit).Inc()
+		span.LogKV("cache", resultHit)
+		if _, err := w.Write(e.data); err != nil {
+			return flux.Statistics{}, err
+		}
+		return e.stats, nil
+	}
+	s.cache.metrics.requests.WithLabelValues(resultMiss).Inc()
+	span.LogKV("cache", resultMiss)
+
+	seq := s.cache.sequence()
+	rec := &readRecorder{}
+	buf := &limitedBuffer{limit: s.cache.config.MaxEntryBytes}
+	stats, err := s.proxy.Query(influxdb.ContextWithReadObserver(ctx, rec), io.MultiWriter(w, buf), preq)
+	if err != nil || buf.overflow {
+		return stats, err
+	}
+
+	// Queries that read nothing from storage, such as those that read
+	// external data, are not invalidated by writes.
+	if len(rec.reads) == 0 {
+		return stats, nil
+	}
+	s.cache.put(&entry{
+		key:   key,
+		data:  buf.Bytes(),
+		stats: stats,
+		reads: rec.reads,
+	}, seq)
+	return stats, nil
+}
+
+func (s *ProxyQueryService) Check(ctx context.Context) check.Response {
+	return s.proxy.Check(ctx)
+}
+
+// key returns the cache key of the request along with the request to
+// perform on a miss, which has its now time truncated to the TTL of the
+// cache. It returns false if the result of the request must not be cached.
+func (c *Cache) key(req *query.ProxyRequest) (*query.ProxyRequest, string, bool) {
+	auth := req.Request.Authorization
+	if req.Request.NoCache || req.Request.Profile || auth == nil {
+		return nil, "", false
+	}
+	dialect, ok := req.Dialect.(*csv.Dialect)
+	if !ok {
+		return nil, "", false
+	}
+
+	var (
+		pkg    *ast.Package
+		extern *ast.File
+		now    time.Time
+	)
+	switch comp := req.Request.Compiler.(type) {
+	case lang.FluxCompiler:
+		pkg, extern, now = parser.ParseSource(comp.Query), comp.Extern, comp.Now
+	case *lang.FluxCompiler:
+		pkg, extern, now = parser.ParseSource(comp.Query), comp.Extern, comp.Now
+	case lang.ASTCompiler:
+		pkg, now = comp.AST, comp.Now
+	case *lang.ASTCompiler:
+		pkg, now = comp.AST, comp.Now
+	default:
+		return nil, "", false
+	}
+	if pkg == nil || ast.Check(pkg) > 0 || hasSideEffects(pkg) {
+		return nil, "", false
+	}
+	if extern != nil && (ast.Check(extern) > 0 || hasSideEffects(extern)) {
+		return nil, "", false
+	}
+
+	// Queries run close to the current time have their now time truncated
+	// to the TTL, so that the relative time ranges of repeated queries
+	// resolve to the same bounds. Queries at other times use it as is.
+	preq := *req
+	if d := c.now().Sub(now); c.config.TTL > 0 && d >= 0 && d < c.config.TTL {
+		now = now.Truncate(c.config.TTL)
+		switch comp := req.Request.Compiler.(type) {
+		case lang.FluxCompiler:
+			comp.Now = now
+			preq.Request.Compiler = comp
+		case *lang.FluxCompiler:
+			preq.Request.Compiler = lang.FluxCompiler{Now: now, Extern: comp.Extern, Query: comp.Query}
+		case lang.ASTCompiler:
+			comp.Now = now
+			preq.Request.Compiler = comp
+		case *lang.ASTCompiler:
+			preq.Request.Compiler = lang.ASTCompiler{AST: comp.AST, Now: now}
+		}
+	}
+
+	perms := make([]string, len(auth.Permissions))
+	for i, p := range auth.Permissions {
+		perms[i] = p.String()
+	}
+	sort.Strings(perms)
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%T\n%d\n", req.Request.OrganizationID, strings.Join(perms, ","), req.Request.Compiler, now.UnixNano())
+	fmt.Fprintf(h, "%q\n%t\n%q\n", dialect.Annotations, dialect.NoHeader, dialect.Delimiter)
+	if extern != nil {
+		fmt.Fprintf(h, "%s\n", ast.Format(extern))
+	}
+	fmt.Fprintf(h, "%s\n", ast.Format(pkg))
+	return &preq, hex.EncodeToString(h.Sum(nil)), true
+}
+
+// hasSideEffects reports whether the query imports a package with side
+// effects or calls a function named to.
+func hasSideEffects(node ast.Node) bool {
+	v := &sideEffectVisitor{}
+	ast.Walk(v, node)
+	return v.found
+}
+
+type sideEffectVisitor struct {
+	found bool
+}
+
+func (v *sideEffectVisitor) Visit(node ast.Node) ast.Visitor {
+	if v.found {
+		return nil
+	}
+	switch n := node.(type) {
+	case *ast.ImportDeclaration:
+		if n.Path != nil && sideEffectPackages[n.Path.Value] {
+			v.found = true
+		}
+	case *ast.CallExpression:
+		switch callee := n.Callee.(type) {
+		case *ast.Identifier:
+			v.found = callee.Name == "to"
+		case *ast.MemberExpression:
+			v.found = ast.Format(callee.Property) == "to"
+		}
+	}
+	return v
+}
+
+func (v *sideEffectVisitor) Done(ast.Node) {}
+
+// limitedBuffer buffers what is written to it until it exceeds its limit.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit    int64
+	overflow bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.overflow {
+		return len(p), nil
+	}
+	if int64(b.Len()+len(p)) > b.limit {
+		b.overflow = true
+		b.Reset()
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
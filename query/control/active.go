@@ -0,0 +1,110 @@
+package control
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/query/influxql"
+)
+
+var _ query.ActiveQueryService = (*Controller)(nil)
+
+// FindActiveQueries reports the queries that are queued or executing,
+// ordered by their ID.
+func (c *Controller) FindActiveQueries(ctx context.Context, filter query.ActiveQueryFilter) ([]*query.ActiveQuery, error) {
+	queries := c.Queries()
+	sort.Slice(queries, func(i, j int) bool {
+		return queries[i].id < queries[j].id
+	})
+
+	now := time.Now()
+	active := make([]*query.ActiveQuery, 0, len(queries))
+	for _, q := range queries {
+		if filter.OrganizationID != nil && *filter.OrganizationID != q.orgID {
+			continue
+		}
+		active = append(active, q.active(now))
+	}
+	return active, nil
+}
+
+// FindActiveQueryByID reports the query with the given ID if it is still active.
+func (c *Controller) FindActiveQueryByID(ctx context.Context, id uint64) (*query.ActiveQuery, error) {
+	q := c.activeQuery(id)
+	if q == nil {
+		return nil, query.ErrActiveQueryNotFound
+	}
+	return q.active(time.Now()), nil
+}
+
+// CancelActiveQuery cancels the query with the given ID. The query is
+// stopped and its results are dropped, but the caller of the query must
+// still call Done.
+func (c *Controller) CancelActiveQuery(ctx context.Context, id uint64) error {
+	q := c.activeQuery(id)
+	if q == nil {
+		return query.ErrActiveQueryNotFound
+	}
+	q.Cancel()
+	return nil
+}
+
+func (c *Controller) activeQuery(id uint64) *Query {
+	c.queriesMu.RLock()
+	defer c.queriesMu.RUnlock()
+	return c.queries[QueryID(id)]
+}
+
+// active describes q as it is at the time now.
+func (q *Query) active(now time.Time) *query.ActiveQuery {
+	var memoryBytes int64
+	q.stateMu.RLock()
+	if q.memoryManager != nil {
+		memoryBytes = q.memoryManager.memoryBytes()
+	}
+	q.stateMu.RUnlock()
+
+	return &query.ActiveQuery{
+		ID:               uint64(q.id),
+		OrganizationID:   q.orgID,
+		UserID:           q.userID,
+		TokenDescription: q.tokenDescription,
+		Query:            q.text,
+		CompilerType:     string(q.compilerType),
+		State:            q.State().String(),
+		StartedAt:        q.createdAt,
+		Elapsed:          now.Sub(q.createdAt),
+		MemoryBytes:      memoryBytes,
+	}
+}
+
+// queryText returns the text of the query that compiler compiles, if
+// the compiler has one.
+func queryText(compiler flux.Compiler) string {
+	switch c := compiler.(type) {
+	case lang.FluxCompiler:
+		return c.Query
+	case *lang.FluxCompiler:
+		return c.Query
+	case lang.ASTCompiler:
+		return formatAST(c.AST)
+	case *lang.ASTCompiler:
+		return formatAST(c.AST)
+	case *influxql.Compiler:
+		return c.Query
+	default:
+		return ""
+	}
+}
+
+func formatAST(pkg *ast.Package) string {
+	if pkg == nil {
+		return ""
+	}
+	return ast.Format(pkg)
+}
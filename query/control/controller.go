@@ -23,6 +23,7 @@ import (
 	"runtime/debug"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/codes"
@@ -34,6 +35,7 @@ import (
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	influxlogger "github.com/influxdata/influxdb/v2/logger"
 	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/query/profiler"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -49,7 +51,7 @@ type Controller struct {
 	lastID     uint64
 	queriesMu  sync.RWMutex
 	queries    map[QueryID]*Query
-	queryQueue chan *Query
+	queryQueue *queryQueue
 	wg         sync.WaitGroup
 	shutdown   bool
 	done       chan struct{}
@@ -98,6 +100,15 @@ type Config struct {
 	MetricLabelKeys []string
 
 	ExecutorDependencies []flux.Dependency
+
+	// OrgLimits looks up the quotas of the organization of each query.
+	// If it is nil, organizations are only bound by the limits above.
+	OrgLimits influxdb.OrgLimitsService
+
+	// SlowQueryThreshold is the total duration above which a finished query
+	// is logged together with its statistics. If this is unset, slow queries
+	// are not logged.
+	SlowQueryThreshold time.Duration
 }
 
 // complete will fill in the defaults, validate the configuration, and
@@ -135,6 +146,9 @@ func (c *Config) validate(isComplete bool) error {
 	if c.QueueSize <= 0 {
 		return errors.New("QueueSize must be positive")
 	}
+	if c.SlowQueryThreshold < 0 {
+		return errors.New("SlowQueryThreshold must not be negative")
+	}
 	return nil
 }
 
@@ -160,7 +174,8 @@ func New(config Config) (*Controller, error) {
 		zap.Int64("initial_memory_bytes_quota_per_query", c.InitialMemoryBytesQuotaPerQuery),
 		zap.Int64("memory_bytes_quota_per_query", c.MemoryBytesQuotaPerQuery),
 		zap.Int64("max_memory_bytes", c.MaxMemoryBytes),
-		zap.Int("queue_size", c.QueueSize))
+		zap.Int("queue_size", c.QueueSize),
+		zap.Duration("slow_query_threshold", c.SlowQueryThreshold))
 
 	mm := &memoryManager{
 		initialBytesQuotaPerQuery: c.InitialMemoryBytesQuotaPerQuery,
@@ -174,7 +189,7 @@ func New(config Config) (*Controller, error) {
 	ctrl := &Controller{
 		config:       c,
 		queries:      make(map[QueryID]*Query),
-		queryQueue:   make(chan *Query, c.QueueSize),
+		queryQueue:   newQueryQueue(c.QueueSize, c.InitialMemoryBytesQuotaPerQuery),
 		done:         make(chan struct{}),
 		abort:        make(chan struct{}),
 		memory:       mm,
@@ -217,7 +232,7 @@ func (c *Controller) Query(ctx context.Context, req *query.Request) (flux.Query,
 // query submits a query for execution returning immediately.
 // Done must be called on any returned Query objects.
 func (c *Controller) query(ctx context.Context, compiler flux.Compiler) (flux.Query, error) {
-	q, err := c.createQuery(ctx, compiler.CompilerType())
+	q, err := c.createQuery(ctx, compiler)
 	if err != nil {
 		return nil, handleFluxError(err)
 	}
@@ -237,7 +252,7 @@ func (c *Controller) query(ctx context.Context, compiler flux.Compiler) (flux.Qu
 	return q, nil
 }
 
-func (c *Controller) createQuery(ctx context.Context, ct flux.CompilerType) (*Query, error) {
+func (c *Controller) createQuery(ctx context.Context, compiler flux.Compiler) (*Query, error) {
 	c.queriesMu.RLock()
 	if c.shutdown {
 		c.queriesMu.RUnlock()
@@ -260,8 +275,26 @@ func (c *Controller) createQuery(ctx context.Context, ct flux.CompilerType) (*Qu
 		labelValues[i] = str
 		compileLabelValues[i] = str
 	}
+	ct := compiler.CompilerType()
 	compileLabelValues[len(compileLabelValues)-1] = string(ct)
 
+	var (
+		orgID, tokenID, userID influxdb.ID
+		tokenDescription       string
+	)
+	if req := query.RequestFromContext(ctx); req != nil {
+		orgID = req.OrganizationID
+		if req.Authorization != nil {
+			tokenID = req.Authorization.ID
+			userID = req.Authorization.UserID
+			tokenDescription = req.Authorization.Description
+		}
+	}
+	limits, err := c.orgLimits(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
 	cctx, cancel := context.WithCancel(ctx)
 	parentSpan, parentCtx := tracing.StartSpanFromContextWithPromMetrics(
 		cctx,
@@ -273,6 +306,14 @@ func (c *Controller) createQuery(ctx context.Context, ct flux.CompilerType) (*Qu
 		id:                 id,
 		labelValues:        labelValues,
 		compileLabelValues: compileLabelValues,
+		orgID:              orgID,
+		tokenID:            tokenID,
+		limits:             limits,
+		userID:             userID,
+		tokenDescription:   tokenDescription,
+		text:               queryText(compiler),
+		compilerType:       ct,
+		createdAt:          time.Now(),
 		state:              Created,
 		c:                  c,
 		results:            make(chan flux.Result),
@@ -300,6 +341,18 @@ func (c *Controller) createQuery(ctx context.Context, ct flux.CompilerType) (*Qu
 	return q, nil
 }
 
+// orgLimits returns the quotas of the organization orgID.
+func (c *Controller) orgLimits(ctx context.Context, orgID influxdb.ID) (influxdb.OrgLimits, error) {
+	if c.config.OrgLimits == nil || !orgID.Valid() {
+		return influxdb.OrgLimits{OrgID: orgID}, nil
+	}
+	l, err := c.config.OrgLimits.FindOrgLimits(ctx, orgID)
+	if err != nil {
+		return influxdb.OrgLimits{}, err
+	}
+	return *l, nil
+}
+
 func (c *Controller) nextID() QueryID {
 	nextID := atomic.AddUint64(&c.lastID, 1)
 	return QueryID(nextID)
@@ -346,6 +399,12 @@ func (c *Controller) compileQuery(q *Query, compiler flux.Compiler) (err error)
 		}
 	}
 
+	if req := query.RequestFromContext(ctx); req != nil && req.Profile {
+		if prog, err = profiler.Instrument(prog, compiler); err != nil {
+			return err
+		}
+	}
+
 	if p, ok := prog.(lang.LoggingProgram); ok {
 		p.SetLogger(log)
 	}
@@ -362,26 +421,21 @@ func (c *Controller) enqueueQuery(q *Query) error {
 		}
 	}
 
-	select {
-	case c.queryQueue <- q:
-	default:
-		return &flux.Error{
-			Code: codes.ResourceExhausted,
-			Msg:  "queue length exceeded",
-		}
+	if quota, err := c.queryQueue.push(q); err != nil {
+		c.metrics.quotaRejections.WithLabelValues(q.orgID.String(), quota).Inc()
+		return err
 	}
-
 	return nil
 }
 
 func (c *Controller) processQueryQueue() {
 	for {
-		select {
-		case <-c.done:
+		q := c.queryQueue.pop()
+		if q == nil {
 			return
-		case q := <-c.queryQueue:
-			c.executeQuery(q)
 		}
+		c.executeQuery(q)
+		c.queryQueue.done(q)
 	}
 }
 
@@ -444,6 +498,7 @@ func (c *Controller) finish(q *Query) {
 	delete(c.queries, q.id)
 	if len(c.queries) == 0 && c.shutdown {
 		close(c.done)
+		c.queryQueue.close()
 	}
 	c.queriesMu.Unlock()
 }
@@ -525,6 +580,21 @@ type Query struct {
 
 	c *Controller
 
+	// orgID and tokenID identify the organization and token of the
+	// request and limits are the quotas of the organization. org is
+	// the queue of the organization once the query is queued.
+	orgID, tokenID influxdb.ID
+	limits         influxdb.OrgLimits
+	org            *orgQueue
+
+	// userID, tokenDescription, text, compilerType and createdAt
+	// describe the request when the active queries are reported.
+	userID           influxdb.ID
+	tokenDescription string
+	text             string
+	compilerType     flux.CompilerType
+	createdAt        time.Time
+
 	// query state. The stateMu protects access for the group below.
 	stateMu     sync.RWMutex
 	state       State
@@ -574,6 +644,22 @@ func (q *Query) Results() <-chan flux.Result {
 func (q *Query) recordUnusedMemory() {
 	unused := q.c.GetUnusedMemoryBytes()
 	q.c.metrics.memoryUnused.WithLabelValues(q.labelValues...).Set(float64(unused))
+	if q.org != nil {
+		q.c.metrics.orgMemoryUsed.WithLabelValues(q.orgID.String()).Set(float64(q.org.getMemoryBytes()))
+	}
+}
+
+// quotaErr names the quota of the organization in err if the query
+// failed because the organization ran out of memory.
+func (q *Query) quotaErr(err error) error {
+	if err == nil || q.memoryManager == nil || !q.memoryManager.orgQuotaExceeded() {
+		return err
+	}
+	return &flux.Error{
+		Code: codes.ResourceExhausted,
+		Msg:  fmt.Sprintf("memory quota exceeded for organization %s: the org memory bytes quota is %d", q.orgID, q.limits.MemoryBytesQuota),
+		Err:  err,
+	}
 }
 
 // Done signals to the Controller that this query is no longer
@@ -609,7 +695,7 @@ func (q *Query) Done() {
 			if q.err == nil {
 				// TODO(jsternberg): The underlying program never returns
 				// this so maybe their interface should change?
-				q.err = q.exec.Err()
+				q.err = q.quotaErr(q.exec.Err())
 			}
 			// Merge the metadata from the program into the controller stats.
 			stats := q.exec.Statistics()
@@ -633,6 +719,10 @@ func (q *Query) Done() {
 			q.recordUnusedMemory()
 		}
 
+		if q.memoryManager != nil && q.memoryManager.orgQuotaExceeded() {
+			q.c.metrics.quotaRejections.WithLabelValues(q.orgID.String(), quotaOrgMemory).Inc()
+		}
+
 		// Count query request.
 		if q.err != nil || len(q.runtimeErrs) > 0 {
 			q.c.countQueryRequest(q, labelRuntimeError)
@@ -640,6 +730,7 @@ func (q *Query) Done() {
 			q.c.countQueryRequest(q, labelSuccess)
 		}
 
+		q.c.logSlowQuery(q)
 	})
 	<-q.doneCh
 }
@@ -907,7 +998,7 @@ type errorCollectingTableIterator struct {
 func (ti *errorCollectingTableIterator) Do(f func(t flux.Table) error) error {
 	err := ti.TableIterator.Do(f)
 	if err != nil {
-		err = handleFluxError(err)
+		err = handleFluxError(ti.q.quotaErr(err))
 		ti.q.addRuntimeError(err)
 	}
 	return err
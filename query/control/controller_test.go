@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/arrow"
 	"github.com/influxdata/flux/codes"
@@ -20,13 +21,18 @@ import (
 	"github.com/influxdata/flux/plan"
 	"github.com/influxdata/flux/plan/plantest"
 	"github.com/influxdata/flux/stdlib/universe"
+	platform "github.com/influxdata/influxdb/v2"
+	influxmock "github.com/influxdata/influxdb/v2/mock"
 	"github.com/influxdata/influxdb/v2/query"
 	_ "github.com/influxdata/influxdb/v2/query/builtin"
 	"github.com/influxdata/influxdb/v2/query/control"
+	"github.com/influxdata/influxdb/v2/query/profiler"
 	"github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
+	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func init() {
@@ -1305,6 +1311,358 @@ func consumeResults(tb testing.TB, q flux.Query) {
 	}
 }
 
+// orgLimits returns an OrgLimitsService with the given limits.
+func orgLimits(limits ...platform.OrgLimits) platform.OrgLimitsService {
+	svc := influxmock.NewOrgLimitsService()
+	svc.FindOrgLimitsFn = func(ctx context.Context, orgID platform.ID) (*platform.OrgLimits, error) {
+		for _, l := range limits {
+			if l.OrgID == orgID {
+				return &l, nil
+			}
+		}
+		return &platform.OrgLimits{OrgID: orgID}, nil
+	}
+	return svc
+}
+
+// blockingCompiler returns a compiler of queries that report the
+// organization of their request on executing and then wait for done.
+func blockingCompiler(executing chan<- platform.ID, done <-chan struct{}) flux.Compiler {
+	return &mock.Compiler{
+		CompileFn: func(ctx context.Context) (flux.Program, error) {
+			return &mock.Program{
+				ExecuteFn: func(ctx context.Context, q *mock.Query, alloc *memory.Allocator) {
+					executing <- query.RequestFromContext(ctx).OrganizationID
+					<-done
+				},
+			}, nil
+		},
+	}
+}
+
+func TestController_OrgConcurrencyQuota(t *testing.T) {
+	config := config
+	config.ConcurrencyQuota = 3
+	config.QueueSize = 10
+	config.OrgLimits = orgLimits(platform.OrgLimits{OrgID: 1, ConcurrencyQuota: 1})
+	ctrl, err := control.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(t, ctrl)
+
+	executing := make(chan platform.ID, 3)
+	done := make(chan struct{})
+	defer close(done)
+	compiler := blockingCompiler(executing, done)
+
+	for _, orgID := range []platform.ID{1, 1, 2} {
+		q, err := ctrl.Query(context.Background(), makeOrgRequest(compiler, orgID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		go discardResults(q)
+	}
+
+	started := map[platform.ID]int{}
+	for i := 0; i < 2; i++ {
+		select {
+		case orgID := <-executing:
+			started[orgID]++
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for queries to start")
+		}
+	}
+	if started[1] != 1 || started[2] != 1 {
+		t.Fatalf("unexpected started queries: %v", started)
+	}
+
+	select {
+	case <-executing:
+		t.Fatal("organization exceeded its concurrency quota")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestController_OrgQueueSize(t *testing.T) {
+	config := config
+	config.ConcurrencyQuota = 1
+	config.QueueSize = 10
+	config.OrgLimits = orgLimits(platform.OrgLimits{OrgID: 1, QueueSize: 1})
+	ctrl, err := control.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(t, ctrl)
+	reg := setupPromRegistry(ctrl)
+
+	executing := make(chan platform.ID, 1)
+	done := make(chan struct{})
+	defer close(done)
+	compiler := blockingCompiler(executing, done)
+
+	// Occupy the only executor and then fill up the queue of org 1.
+	for _, orgID := range []platform.ID{2, 1} {
+		q, err := ctrl.Query(context.Background(), makeOrgRequest(compiler, orgID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		go discardResults(q)
+		if orgID == 2 {
+			<-executing
+		}
+	}
+
+	_, err = ctrl.Query(context.Background(), makeOrgRequest(compiler, 1))
+	if err == nil || !strings.Contains(err.Error(), "org queue size quota is 1") {
+		t.Fatalf("expected an error naming the org queue size quota, got %v", err)
+	}
+
+	// Other organizations may still queue queries.
+	q, err := ctrl.Query(context.Background(), makeOrgRequest(compiler, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	go discardResults(q)
+
+	metrics, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := FindMetric(metrics, "query_control_quota_rejections_total", map[string]string{
+		"org":   platform.ID(1).String(),
+		"quota": "org_queue",
+	})
+	if m == nil || *m.Counter.Value != 1 {
+		t.Fatalf("expected one rejection for the org queue quota, got %v", m)
+	}
+}
+
+func TestController_OrgMemoryQuota(t *testing.T) {
+	config := config
+	config.InitialMemoryBytesQuotaPerQuery = 16
+	config.MemoryBytesQuotaPerQuery = 1024
+	config.OrgLimits = orgLimits(platform.OrgLimits{OrgID: 1, MemoryBytesQuota: 64})
+	ctrl, err := control.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(t, ctrl)
+
+	compiler := &mock.Compiler{
+		CompileFn: func(ctx context.Context) (flux.Program, error) {
+			return &mock.Program{
+				ExecuteFn: func(ctx context.Context, q *mock.Query, alloc *memory.Allocator) {
+					for i := 0; i < 64; i++ {
+						if err := alloc.Account(16); err != nil {
+							q.SetErr(err)
+							return
+						}
+					}
+				},
+			}, nil
+		},
+	}
+
+	q, err := ctrl.Query(context.Background(), makeOrgRequest(compiler, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range q.Results() {
+	}
+	q.Done()
+
+	if err := q.Err(); err == nil || !strings.Contains(err.Error(), "org memory bytes quota is 64") {
+		t.Fatalf("expected an error naming the org memory quota, got %v", err)
+	}
+	if got := ctrl.GetUnusedMemoryBytes(); got != 0 {
+		t.Fatalf("memory was not released: %d", got)
+	}
+}
+
+func TestController_WeightedFairScheduling(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		limits []platform.OrgLimits
+		want   []platform.ID
+	}{
+		{
+			name: "equal weights",
+			want: []platform.ID{1, 2, 1, 2, 1, 2},
+		},
+		{
+			name:   "weighted",
+			limits: []platform.OrgLimits{{OrgID: 1, Weight: 2}},
+			want:   []platform.ID{1, 2, 1, 1, 2, 1},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			config := config
+			config.ConcurrencyQuota = 1
+			config.QueueSize = 20
+			config.OrgLimits = orgLimits(tt.limits...)
+			ctrl, err := control.New(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer shutdown(t, ctrl)
+
+			executing := make(chan platform.ID, 1)
+			done := make(chan struct{})
+			blocking := blockingCompiler(executing, done)
+
+			var (
+				mu    sync.Mutex
+				order []platform.ID
+			)
+			recording := &mock.Compiler{
+				CompileFn: func(ctx context.Context) (flux.Program, error) {
+					return &mock.Program{
+						ExecuteFn: func(ctx context.Context, q *mock.Query, alloc *memory.Allocator) {
+							mu.Lock()
+							order = append(order, query.RequestFromContext(ctx).OrganizationID)
+							mu.Unlock()
+						},
+					}, nil
+				},
+			}
+
+			// Hold the only executor while org 1 queues its queries before org 2.
+			q, err := ctrl.Query(context.Background(), makeOrgRequest(blocking, 3))
+			if err != nil {
+				t.Fatal(err)
+			}
+			go consumeResults(t, q)
+			<-executing
+
+			var wg sync.WaitGroup
+			for _, orgID := range []platform.ID{1, 1, 1, 1, 1, 1, 2, 2, 2, 2, 2, 2} {
+				q, err := ctrl.Query(context.Background(), makeOrgRequest(recording, orgID))
+				if err != nil {
+					t.Fatal(err)
+				}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					consumeResults(t, q)
+				}()
+			}
+			close(done)
+			wg.Wait()
+
+			if got := order[:len(tt.want)]; !cmp.Equal(tt.want, got) {
+				t.Fatalf("unexpected execution order -want/+got:\n%s", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
+func TestController_ActiveQueries(t *testing.T) {
+	config := config
+	config.ConcurrencyQuota = 1
+	config.QueueSize = 10
+	config.InitialMemoryBytesQuotaPerQuery = 512
+	ctrl, err := control.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(t, ctrl)
+
+	executing := make(chan struct{}, 2)
+	compiler := &mock.Compiler{
+		CompileFn: func(ctx context.Context) (flux.Program, error) {
+			return &mock.Program{
+				ExecuteFn: func(ctx context.Context, q *mock.Query, alloc *memory.Allocator) {
+					executing <- struct{}{}
+					<-ctx.Done()
+					q.SetErr(ctx.Err())
+				},
+			}, nil
+		},
+	}
+
+	queries := make([]flux.Query, 0, 2)
+	for _, orgID := range []platform.ID{1, 2} {
+		req := makeOrgRequest(compiler, orgID)
+		req.Authorization = &platform.Authorization{
+			ID:          orgID + 10,
+			UserID:      orgID + 20,
+			Description: fmt.Sprintf("token of org %d", orgID),
+		}
+		q, err := ctrl.Query(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		queries = append(queries, q)
+	}
+	select {
+	case <-executing:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the query to start")
+	}
+
+	active, err := ctrl.FindActiveQueries(context.Background(), query.ActiveQueryFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(active) != 2 {
+		t.Fatalf("expected 2 active queries, got %d", len(active))
+	}
+	for i, want := range []struct {
+		orgID, userID platform.ID
+		description   string
+		state         string
+	}{
+		{orgID: 1, userID: 21, description: "token of org 1", state: "executing"},
+		{orgID: 2, userID: 22, description: "token of org 2", state: "queueing"},
+	} {
+		q := active[i]
+		if q.OrganizationID != want.orgID || q.UserID != want.userID || q.TokenDescription != want.description || q.State != want.state {
+			t.Errorf("unexpected active query %d: %+v", i, q)
+		}
+	}
+	if got, want := active[0].MemoryBytes, config.InitialMemoryBytesQuotaPerQuery; got != want {
+		t.Errorf("unexpected memory of the executing query: got %d, want %d", got, want)
+	}
+	if got := active[1].MemoryBytes; got != 0 {
+		t.Errorf("unexpected memory of the queued query: got %d, want 0", got)
+	}
+
+	orgID := platform.ID(2)
+	active, err = ctrl.FindActiveQueries(context.Background(), query.ActiveQueryFilter{OrganizationID: &orgID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(active) != 1 || active[0].OrganizationID != orgID {
+		t.Fatalf("unexpected active queries of org %s: %+v", orgID, active)
+	}
+
+	// Canceling the executing query lets the queued query start.
+	if err := ctrl.CancelActiveQuery(context.Background(), uint64(queries[0].(*control.Query).ID())); err != nil {
+		t.Fatal(err)
+	}
+	discardResults(queries[0])
+	if queries[0].Err() == nil {
+		t.Fatal("expected the canceled query to fail")
+	}
+	select {
+	case <-executing:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the queued query to start")
+	}
+
+	id := uint64(queries[0].(*control.Query).ID())
+	if _, err := ctrl.FindActiveQueryByID(context.Background(), id); platform.ErrorCode(err) != platform.ENotFound {
+		t.Fatalf("expected finished query not to be found, got %v", err)
+	}
+	if err := ctrl.CancelActiveQuery(context.Background(), id); platform.ErrorCode(err) != platform.ENotFound {
+		t.Fatalf("expected finished query not to be found, got %v", err)
+	}
+
+	queries[1].Cancel()
+	discardResults(queries[1])
+}
+
 func shutdown(t *testing.T, ctrl *control.Controller) {
 	t.Helper()
 
@@ -1320,3 +1678,127 @@ func makeRequest(c flux.Compiler) *query.Request {
 		Compiler: c,
 	}
 }
+
+// discardResults discards the results of q, which may be canceled
+// when the controller shuts down, and marks it done.
+func discardResults(q flux.Query) {
+	for range q.Results() {
+	}
+	q.Done()
+}
+
+func makeOrgRequest(c flux.Compiler, orgID platform.ID) *query.Request {
+	return &query.Request{
+		Compiler:       c,
+		OrganizationID: orgID,
+	}
+}
+
+func TestController_SlowQueryLog(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		threshold time.Duration
+		wantLog   bool
+	}{
+		{name: "disabled"},
+		{name: "below threshold", threshold: time.Hour},
+		{name: "above threshold", threshold: time.Nanosecond, wantLog: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zap.InfoLevel)
+			config := config
+			config.Logger = zap.New(core)
+			config.SlowQueryThreshold = tt.threshold
+			ctrl, err := control.New(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer shutdown(t, ctrl)
+
+			compiler := &mock.Compiler{
+				CompileFn: func(ctx context.Context) (flux.Program, error) {
+					return &mock.Program{
+						ExecuteFn: func(ctx context.Context, q *mock.Query, alloc *memory.Allocator) {
+							q.SetStatistics(flux.Statistics{
+								Metadata: flux.Metadata{
+									"influxdb/scanned-values": []interface{}{10, 5},
+									"influxdb/scanned-bytes":  []interface{}{80, 40},
+								},
+							})
+							q.ResultsCh <- &executetest.Result{}
+						},
+					}, nil
+				},
+			}
+			q, err := ctrl.Query(context.Background(), makeRequest(compiler))
+			if err != nil {
+				t.Fatal(err)
+			}
+			consumeResults(t, q)
+
+			entries := logs.FilterMessage("Slow query").All()
+			if !tt.wantLog {
+				if len(entries) != 0 {
+					t.Fatalf("unexpected slow query log: %v", entries)
+				}
+				return
+			}
+			if len(entries) != 1 {
+				t.Fatalf("expected one slow query log, got %d", len(entries))
+			}
+			fields := entries[0].ContextMap()
+			if got, want := fields["scanned_values"], int64(15); got != want {
+				t.Errorf("unexpected scanned values: got %v want %v", got, want)
+			}
+			if got, want := fields["scanned_bytes"], int64(120); got != want {
+				t.Errorf("unexpected scanned bytes: got %v want %v", got, want)
+			}
+			for _, key := range []string{"total_duration", "compile_duration", "queue_duration", "execute_duration", "max_allocated"} {
+				if _, ok := fields[key]; !ok {
+					t.Errorf("expected %s to be logged", key)
+				}
+			}
+		})
+	}
+}
+
+func TestController_Profile(t *testing.T) {
+	ctrl, err := control.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(t, ctrl)
+
+	req := makeRequest(lang.FluxCompiler{
+		Query: `import "csv"
+csv.from(csv: "#datatype,string,long,long\n#group,false,false,false\n#default,_result,,\n,result,table,_value\n,,0,1\n,,0,2\n")`,
+	})
+	req.Profile = true
+	q, err := ctrl.Query(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for res := range q.Results() {
+		names = append(names, res.Name())
+		if err := res.Tables().Do(func(tbl flux.Table) error {
+			tbl.Done()
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	q.Done()
+	if err := q.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"_result", profiler.ResultName}; !cmp.Equal(want, names) {
+		t.Fatalf("unexpected results -want/+got:\n%s", cmp.Diff(want, names))
+	}
+
+	req = makeRequest(mockCompiler)
+	req.Profile = true
+	if _, err := ctrl.Query(context.Background(), req); err == nil {
+		t.Fatal("expected profiling a program that was not compiled from Flux to fail")
+	}
+}
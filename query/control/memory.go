@@ -43,10 +43,15 @@ func (m *memoryManager) addUnusedMemoryBytes(amount int64) int64 {
 // createAllocator will construct an allocator and memory manager
 // for the given query.
 func (c *Controller) createAllocator(q *Query) {
+	// The memory manager is set with the state lock held because the
+	// memory of the query is reported while it executes.
+	q.stateMu.Lock()
 	q.memoryManager = &queryMemoryManager{
 		m:     c.memory,
+		org:   q.org,
 		limit: c.memory.initialBytesQuotaPerQuery,
 	}
+	q.stateMu.Unlock()
 	q.alloc = &memory.Allocator{
 		// Use an anonymous function to ensure the value is copied.
 		Limit:   func(v int64) *int64 { return &v }(q.memoryManager.limit),
@@ -56,9 +61,19 @@ func (c *Controller) createAllocator(q *Query) {
 
 // queryMemoryManager is a memory manager for a specific query.
 type queryMemoryManager struct {
-	m     *memoryManager
+	m *memoryManager
+
+	// limit is the memory that the query may use. It is only modified
+	// by the query, but it is written atomically so that it can be
+	// reported while the query executes.
 	limit int64
 	given int64
+
+	// org holds the memory quota of the organization of the query
+	// and orgExceeded is set once the query was refused memory
+	// because of it.
+	org         *orgQueue
+	orgExceeded int32
 }
 
 // RequestMemory will determine if the query can be given more memory
@@ -94,18 +109,30 @@ func (q *queryMemoryManager) RequestMemory(want int64) (got int64, err error) {
 		// this method.
 		given := q.giveMemory(want, unused)
 
+		// Reserve the memory from the quota of the organization
+		// which may give us less than we would like.
+		if q.org != nil {
+			if given = q.org.reserveMemoryBytes(want, given); given == 0 {
+				atomic.StoreInt32(&q.orgExceeded, 1)
+				return 0, errors.New("organization quota exceeded")
+			}
+		}
+
 		// Reserve this memory for our own use.
 		if !q.m.unlimited {
 			if !q.m.trySetUnusedMemoryBytes(unused, unused-given) {
 				// The unused value has changed so someone may have taken
 				// the memory that we wanted. Retry.
+				if q.org != nil {
+					q.org.addMemoryBytes(-given)
+				}
 				continue
 			}
 		}
 
 		// Successfully reserved the memory so update our own internal
 		// counter for the limit.
-		q.limit += given
+		atomic.AddInt64(&q.limit, given)
 		q.given += given
 		return given, nil
 	}
@@ -152,6 +179,20 @@ func (q *queryMemoryManager) Release() {
 	if !q.m.unlimited {
 		q.m.addUnusedMemoryBytes(q.given)
 	}
-	q.limit = q.m.initialBytesQuotaPerQuery
+	if q.org != nil {
+		q.org.addMemoryBytes(-q.given)
+	}
+	atomic.StoreInt64(&q.limit, q.m.initialBytesQuotaPerQuery)
 	q.given = 0
 }
+
+// memoryBytes reports the memory that the query may currently use.
+func (q *queryMemoryManager) memoryBytes() int64 {
+	return atomic.LoadInt64(&q.limit)
+}
+
+// orgQuotaExceeded reports whether the query was refused memory because
+// of the memory quota of its organization.
+func (q *queryMemoryManager) orgQuotaExceeded() bool {
+	return atomic.LoadInt32(&q.orgExceeded) == 1
+}
@@ -13,6 +13,9 @@ type controllerMetrics struct {
 	executing    *prometheus.GaugeVec
 	memoryUnused *prometheus.GaugeVec
 
+	quotaRejections *prometheus.CounterVec
+	orgMemoryUsed   *prometheus.GaugeVec
+
 	allDur       *prometheus.HistogramVec
 	compilingDur *prometheus.HistogramVec
 	queueingDur  *prometheus.HistogramVec
@@ -84,6 +87,20 @@ func newControllerMetrics(labels []string) *controllerMetrics {
 			Help:      "The free memory as seen by the internal memory manager",
 		}, labels),
 
+		quotaRejections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "quota_rejections_total",
+			Help:      "Count of the query requests rejected because a quota was exhausted",
+		}, []string{orgLabel, "quota"}),
+
+		orgMemoryUsed: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "org_memory_used_bytes",
+			Help:      "The memory used by the executing queries of an organization",
+		}, []string{orgLabel}),
+
 		allDur: prometheus.NewHistogramVec(prometheus.HistogramOpts{
 			Namespace: namespace,
 			Subsystem: subsystem,
@@ -130,6 +147,9 @@ func (cm *controllerMetrics) PrometheusCollectors() []prometheus.Collector {
 		cm.executing,
 		cm.memoryUnused,
 
+		cm.quotaRejections,
+		cm.orgMemoryUsed,
+
 		cm.allDur,
 		cm.compilingDur,
 		cm.queueingDur,
@@ -0,0 +1,265 @@
+package control
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/influxdb/v2"
+)
+
+// Names of the quotas that may be exhausted by a query.
+const (
+	quotaQueue     = "queue"
+	quotaOrgQueue  = "org_queue"
+	quotaOrgMemory = "org_memory"
+)
+
+// strideScale is the distance an organization of weight one advances
+// each time one of its queries is started.
+const strideScale = 1 << 20
+
+// queryQueue holds the queries that wait for execution.
+//
+// The queries of an organization are started in the order they were
+// queued, as long as the organization has not exhausted its concurrency
+// and memory quotas. Organizations with waiting queries take turns in
+// proportion to their weight using stride scheduling: each organization
+// has a pass that advances by the inverse of its weight every time one of
+// its queries starts, and the organization with the lowest pass goes next.
+type queryQueue struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	closed bool
+
+	// size is the number of queued queries of every organization
+	// and maxSize is the limit above which queries are rejected.
+	size    int
+	maxSize int
+
+	// initialMemoryBytes is the memory that a query is given when it
+	// starts and counts against the memory quota of its organization.
+	initialMemoryBytes int64
+
+	// pass is the pass of the last organization that started a query.
+	// An organization that had no waiting queries resumes from here so
+	// that it cannot save up turns while it is idle.
+	pass uint64
+
+	orgs map[influxdb.ID]*orgQueue
+}
+
+// orgQueue is the state of the queries of one organization.
+type orgQueue struct {
+	id      influxdb.ID
+	limits  influxdb.OrgLimits
+	queries []*Query
+	pass    uint64
+
+	executing int
+	tokens    map[influxdb.ID]int
+
+	// memoryBytes is the memory used by the executing queries of the
+	// organization and memoryBytesQuota is its limit. They are accessed
+	// atomically because queries request memory while they execute.
+	memoryBytes      int64
+	memoryBytesQuota int64
+}
+
+func newQueryQueue(maxSize int, initialMemoryBytes int64) *queryQueue {
+	qq := &queryQueue{
+		maxSize:            maxSize,
+		initialMemoryBytes: initialMemoryBytes,
+		orgs:               make(map[influxdb.ID]*orgQueue),
+	}
+	qq.cond = sync.NewCond(&qq.mu)
+	return qq
+}
+
+// push adds q to the queue of its organization. If a queue is full, it
+// returns an error along with the name of the exhausted quota.
+func (qq *queryQueue) push(q *Query) (string, error) {
+	qq.mu.Lock()
+	defer qq.mu.Unlock()
+
+	if qq.size >= qq.maxSize {
+		return quotaQueue, &flux.Error{
+			Code: codes.ResourceExhausted,
+			Msg:  "queue length exceeded",
+		}
+	}
+
+	o := qq.orgs[q.orgID]
+	if o == nil {
+		o = &orgQueue{
+			id:     q.orgID,
+			pass:   qq.pass,
+			tokens: make(map[influxdb.ID]int),
+		}
+		qq.orgs[q.orgID] = o
+	}
+	// The latest limits of the organization apply to all of its queries.
+	o.limits = q.limits
+	atomic.StoreInt64(&o.memoryBytesQuota, q.limits.MemoryBytesQuota)
+	if o.limits.QueueSize > 0 && len(o.queries) >= o.limits.QueueSize {
+		return quotaOrgQueue, &flux.Error{
+			Code: codes.ResourceExhausted,
+			Msg:  fmt.Sprintf("queue length exceeded for organization %s: the org queue size quota is %d", o.id, o.limits.QueueSize),
+		}
+	}
+
+	if len(o.queries) == 0 && o.pass < qq.pass {
+		o.pass = qq.pass
+	}
+	o.queries = append(o.queries, q)
+	q.org = o
+	qq.size++
+	qq.cond.Signal()
+	return "", nil
+}
+
+// pop waits for a query that may start and removes it from the queue.
+// It returns nil once the queue is closed.
+func (qq *queryQueue) pop() *Query {
+	qq.mu.Lock()
+	defer qq.mu.Unlock()
+
+	for {
+		if qq.closed {
+			return nil
+		}
+		if q := qq.next(); q != nil {
+			return q
+		}
+		qq.cond.Wait()
+	}
+}
+
+// next removes the next query to start from the queue or returns nil if
+// every queued query waits for a quota.
+func (qq *queryQueue) next() *Query {
+	var (
+		next *orgQueue
+		idx  int
+	)
+	for _, o := range qq.orgs {
+		if next != nil && (o.pass > next.pass || (o.pass == next.pass && o.id > next.id)) {
+			continue
+		}
+		if i := o.startable(qq.initialMemoryBytes); i >= 0 {
+			next, idx = o, i
+		}
+	}
+	if next == nil {
+		return nil
+	}
+
+	q := next.queries[idx]
+	next.queries = append(next.queries[:idx], next.queries[idx+1:]...)
+	qq.size--
+
+	qq.pass = next.pass
+	weight := next.limits.Weight
+	if weight <= 0 {
+		weight = 1
+	}
+	next.pass += strideScale / uint64(weight)
+
+	next.executing++
+	next.tokens[q.tokenID]++
+	next.addMemoryBytes(qq.initialMemoryBytes)
+	return q
+}
+
+// done releases the quotas held by q once it has finished executing.
+func (qq *queryQueue) done(q *Query) {
+	qq.mu.Lock()
+	defer qq.mu.Unlock()
+
+	o := q.org
+	o.executing--
+	if o.tokens[q.tokenID]--; o.tokens[q.tokenID] == 0 {
+		delete(o.tokens, q.tokenID)
+	}
+	o.addMemoryBytes(-qq.initialMemoryBytes)
+	if len(o.queries) == 0 && o.executing == 0 {
+		delete(qq.orgs, o.id)
+	}
+	// Releasing quotas may allow queries of any organization to start.
+	qq.cond.Broadcast()
+}
+
+// close wakes up every caller of pop.
+func (qq *queryQueue) close() {
+	qq.mu.Lock()
+	qq.closed = true
+	qq.mu.Unlock()
+	qq.cond.Broadcast()
+}
+
+// startable returns the index of the first query of o that may start
+// without exceeding the quotas of the organization, or -1.
+//
+// An organization may always start a query when none is executing so
+// that a quota smaller than a single query cannot stall it.
+func (o *orgQueue) startable(initialMemoryBytes int64) int {
+	if len(o.queries) == 0 {
+		return -1
+	}
+	if o.executing > 0 && o.exhausted(initialMemoryBytes) {
+		return -1
+	}
+	for i, q := range o.queries {
+		if quota := o.limits.TokenConcurrencyQuota; quota > 0 && q.tokenID.Valid() && o.tokens[q.tokenID] >= quota {
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+// exhausted reports whether the concurrency or memory quota of o
+// prevents it from starting another query.
+func (o *orgQueue) exhausted(initialMemoryBytes int64) bool {
+	if quota := o.limits.ConcurrencyQuota; quota > 0 && o.executing >= quota {
+		return true
+	}
+	if quota := o.limits.MemoryBytesQuota; quota > 0 && o.getMemoryBytes()+initialMemoryBytes > quota {
+		return true
+	}
+	return false
+}
+
+func (o *orgQueue) getMemoryBytes() int64 {
+	return atomic.LoadInt64(&o.memoryBytes)
+}
+
+func (o *orgQueue) addMemoryBytes(n int64) int64 {
+	return atomic.AddInt64(&o.memoryBytes, n)
+}
+
+// reserveMemoryBytes reserves between want and given bytes of the memory
+// quota of o and returns the reserved amount, or zero if not even want
+// bytes are left.
+func (o *orgQueue) reserveMemoryBytes(want, given int64) int64 {
+	quota := atomic.LoadInt64(&o.memoryBytesQuota)
+	if quota <= 0 {
+		o.addMemoryBytes(given)
+		return given
+	}
+	for {
+		used := o.getMemoryBytes()
+		n := given
+		if left := quota - used; left < n {
+			n = left
+		}
+		if n < want {
+			return 0
+		}
+		if atomic.CompareAndSwapInt64(&o.memoryBytes, used, used+n) {
+			return n
+		}
+	}
+}
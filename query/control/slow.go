@@ -0,0 +1,67 @@
+package control
+
+import (
+	"github.com/influxdata/flux"
+	influxlogger "github.com/influxdata/influxdb/v2/logger"
+	"go.uber.org/zap"
+)
+
+// The metadata keys that the storage sources use to report the
+// cursors.CursorStats of the series they read.
+const (
+	scannedValuesKey = "influxdb/scanned-values"
+	scannedBytesKey  = "influxdb/scanned-bytes"
+)
+
+// logSlowQuery logs the query and its statistics if it took longer
+// than the slow query threshold. It must be called once the query
+// statistics have been finalized.
+func (c *Controller) logSlowQuery(q *Query) {
+	if c.config.SlowQueryThreshold <= 0 {
+		return
+	}
+
+	stats := q.Statistics()
+	if stats.TotalDuration < c.config.SlowQueryThreshold {
+		return
+	}
+
+	fields := append(influxlogger.TraceFields(q.parentCtx),
+		zap.Uint64("query_id", uint64(q.id)),
+		zap.String("org_id", q.orgID.String()),
+		zap.String("compiler_type", string(q.compilerType)),
+		zap.String("query", q.text),
+		zap.Duration("total_duration", stats.TotalDuration),
+		zap.Duration("compile_duration", stats.CompileDuration),
+		zap.Duration("queue_duration", stats.QueueDuration),
+		zap.Duration("execute_duration", stats.ExecuteDuration),
+		zap.Int64("max_allocated", stats.MaxAllocated),
+		zap.Int64("scanned_values", sumMetadata(stats.Metadata, scannedValuesKey)),
+		zap.Int64("scanned_bytes", sumMetadata(stats.Metadata, scannedBytesKey)),
+	)
+	if q.userID.Valid() {
+		fields = append(fields, zap.String("user_id", q.userID.String()))
+	}
+	if len(stats.RuntimeErrors) > 0 {
+		fields = append(fields, zap.Strings("runtime_errors", stats.RuntimeErrors))
+	}
+	if q.err != nil {
+		fields = append(fields, zap.Error(q.err))
+	}
+	c.log.Warn("Slow query", fields...)
+}
+
+// sumMetadata adds up the integer values reported under the key
+// by each of the sources of a query.
+func sumMetadata(meta flux.Metadata, key string) int64 {
+	var n int64
+	for _, v := range meta[key] {
+		switch v := v.(type) {
+		case int:
+			n += int64(v)
+		case int64:
+			n += v
+		}
+	}
+	return n
+}
@@ -0,0 +1,78 @@
+package query
+
+import (
+	"context"
+	"time"
+)
+
+// Explanation describes how a query is planned without executing it.
+type Explanation struct {
+	// LogicalPlan is the plan before the physical planner rules are applied.
+	LogicalPlan []*PlanNode `json:"logicalPlan"`
+	// PhysicalPlan is the plan that is executed.
+	PhysicalPlan []*PlanNode `json:"physicalPlan"`
+	// Rules are the planner rules that rewrote the plan, in the order
+	// they were applied.
+	Rules []*PlanRule `json:"rules"`
+	// StorageReads are the requests the physical plan sends to storage.
+	StorageReads []*StorageRead `json:"storageReads"`
+
+	// Text renders the explanation as trees of plan nodes.
+	Text string `json:"text"`
+}
+
+// PlanNode is a node of a query plan. The nodes of a plan are
+// listed so that each node follows its predecessors.
+type PlanNode struct {
+	ID           string     `json:"id"`
+	Kind         string     `json:"kind"`
+	Predecessors []string   `json:"predecessors,omitempty"`
+	Bounds       *TimeRange `json:"bounds,omitempty"`
+}
+
+// TimeRange is the half-open time range that a plan node reads.
+type TimeRange struct {
+	Start time.Time `json:"start"`
+	Stop  time.Time `json:"stop"`
+}
+
+// The phases of the planner that apply rules.
+const (
+	LogicalPlanPhase  = "logical"
+	PhysicalPlanPhase = "physical"
+)
+
+// PlanRule records a planner rule that rewrote a node of the plan.
+type PlanRule struct {
+	Name  string `json:"name"`
+	Phase string `json:"phase"`
+	// Node is the node that the rule matched.
+	Node string `json:"node"`
+}
+
+// StorageRead describes a request that a plan node sends to storage.
+type StorageRead struct {
+	// Node is the physical plan node that reads from storage.
+	Node string `json:"node"`
+	// Request is the kind of storage request, e.g. ReadFilterRequest.
+	Request string `json:"request"`
+
+	Bucket   string     `json:"bucket,omitempty"`
+	BucketID string     `json:"bucketID,omitempty"`
+	Range    *TimeRange `json:"range,omitempty"`
+	// Predicate is the predicate that storage evaluates against
+	// the series keys, rendered as an expression.
+	Predicate string `json:"predicate"`
+
+	Group       string   `json:"group,omitempty"`
+	GroupKeys   []string `json:"groupKeys,omitempty"`
+	Aggregates  []string `json:"aggregates,omitempty"`
+	WindowEvery int64    `json:"windowEvery,omitempty"`
+}
+
+// ExplainService explains how queries are planned.
+type ExplainService interface {
+	// Explain compiles and plans the query of the request
+	// without executing it.
+	Explain(ctx context.Context, req *Request) (*Explanation, error)
+}
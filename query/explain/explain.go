@@ -0,0 +1,400 @@
+// Package explain reports how Flux queries are planned without
+// executing them.
+package explain
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/stdlib/experimental/bigtable"
+	"github.com/influxdata/flux/stdlib/universe"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb"
+	storageflux "github.com/influxdata/influxdb/v2/storage/flux"
+	"github.com/influxdata/influxdb/v2/storage/reads"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+	"go.uber.org/zap"
+)
+
+var _ query.ExplainService = (*Service)(nil)
+
+// Service explains Flux queries by compiling and planning them the
+// same way the query controller does before executing them.
+type Service struct {
+	log          *zap.Logger
+	dependencies []flux.Dependency
+
+	// MemoryBytesQuota limits the memory used while evaluating the
+	// query, for example by tableFind. Zero means no limit.
+	MemoryBytesQuota int64
+}
+
+// NewService returns a Service that injects the dependencies into the
+// context of each request, like the query controller does.
+func NewService(log *zap.Logger, deps ...flux.Dependency) *Service {
+	return &Service{
+		log:          log,
+		dependencies: deps,
+	}
+}
+
+// The planner does not expose the rules that are registered with it,
+// so the rules are listed here in order to record which of them fire.
+// TestService_Explain_MatchesPlanner checks that the lists are complete.
+var (
+	logicalRules = []plan.Rule{
+		universe.MergeGroupRule{},
+	}
+	physicalRules = append([]plan.Rule{
+		universe.RemoveTrivialFilterRule{},
+		universe.WindowTriggerPhysicalRule{},
+		bigtable.BigtableFilterRewriteRule{},
+		bigtable.BigtableLimitRewriteRule{},
+	}, influxdb.PhysicalRules()...)
+)
+
+// Explain compiles and plans the query of the request.
+func (s *Service) Explain(ctx context.Context, req *query.Request) (*query.Explanation, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	ctx = query.ContextWithRequest(ctx, req)
+	for _, dep := range s.dependencies {
+		ctx = dep.Inject(ctx)
+	}
+
+	prog, err := req.Compiler.Compile(ctx)
+	if err != nil {
+		return nil, &flux.Error{
+			Msg: "compilation failed",
+			Err: err,
+		}
+	}
+
+	e := &query.Explanation{
+		LogicalPlan:  []*query.PlanNode{},
+		PhysicalPlan: []*query.PlanNode{},
+		Rules:        []*query.PlanRule{},
+		StorageReads: []*query.StorageRead{},
+	}
+
+	var ps *plan.Spec
+	if pkg, now, ok := query.ProgramAST(prog, req.Compiler); ok {
+		if ps, err = s.plan(ctx, e, pkg, now); err != nil {
+			return nil, err
+		}
+	} else if p, ok := prog.(*lang.Program); ok {
+		// The compiler has already planned the query, so
+		// only the physical plan can be explained.
+		ps = p.PlanSpec
+	} else {
+		return nil, &flux.Error{
+			Code: codes.Invalid,
+			Msg:  fmt.Sprintf("explain is not supported for %s queries", req.Compiler.CompilerType()),
+		}
+	}
+
+	e.PhysicalPlan = planNodes(ps)
+	if e.StorageReads, err = storageReads(ctx, req, ps); err != nil {
+		return nil, err
+	}
+	e.Text = format(e)
+	return e, nil
+}
+
+// plan evaluates the package and plans the query, recording the logical
+// plan and the rules that fire in the explanation.
+func (s *Service) plan(ctx context.Context, e *query.Explanation, pkg *ast.Package, now time.Time) (*plan.Spec, error) {
+	alloc := &memory.Allocator{}
+	if s.MemoryBytesQuota > 0 {
+		limit := s.MemoryBytesQuota
+		alloc.Limit = &limit
+	}
+	// Evaluation may call functions, such as tableFind, that rely on
+	// the execution dependencies.
+	ctx = lang.ExecutionDependencies{
+		Allocator: alloc,
+		Logger:    s.log,
+	}.Inject(ctx)
+
+	spec, err := query.SpecFromAST(ctx, pkg, now)
+	if err != nil {
+		return nil, err
+	}
+
+	lp := plan.NewLogicalPlanner(plan.OnlyLogicalRules(recording(logicalRules, query.LogicalPlanPhase, &e.Rules)...))
+	ps, err := lp.CreateInitialPlan(spec)
+	if err != nil {
+		return nil, err
+	}
+	if ps, err = lp.Plan(ctx, ps); err != nil {
+		return nil, &flux.Error{
+			Code: codes.Inherit,
+			Msg:  "error in building logical plan",
+			Err:  err,
+		}
+	}
+	// The physical planner rewrites the nodes of the logical
+	// plan, so they are recorded first.
+	e.LogicalPlan = planNodes(ps)
+
+	pp := plan.NewPhysicalPlanner(plan.OnlyPhysicalRules(recording(physicalRules, query.PhysicalPlanPhase, &e.Rules)...))
+	if ps, err = pp.Plan(ctx, ps); err != nil {
+		return nil, &flux.Error{
+			Code: codes.Inherit,
+			Msg:  "error in building physical plan",
+			Err:  err,
+		}
+	}
+	return ps, nil
+}
+
+// recordingRule records the nodes that a rule rewrites.
+type recordingRule struct {
+	plan.Rule
+	phase string
+	fired *[]*query.PlanRule
+}
+
+func recording(rules []plan.Rule, phase string, fired *[]*query.PlanRule) []plan.Rule {
+	rs := make([]plan.Rule, len(rules))
+	for i, r := range rules {
+		rs[i] = recordingRule{Rule: r, phase: phase, fired: fired}
+	}
+	return rs
+}
+
+func (r recordingRule) Rewrite(ctx context.Context, node plan.Node) (plan.Node, bool, error) {
+	id := node.ID()
+	n, changed, err := r.Rule.Rewrite(ctx, node)
+	if err == nil && changed {
+		*r.fired = append(*r.fired, &query.PlanRule{
+			Name:  r.Name(),
+			Phase: r.phase,
+			Node:  string(id),
+		})
+	}
+	return n, changed, err
+}
+
+// planNodes lists the nodes of the plan so that each node
+// follows its predecessors.
+func planNodes(ps *plan.Spec) []*query.PlanNode {
+	nodes := []*query.PlanNode{}
+	_ = walk(ps, func(node plan.Node) error {
+		n := &query.PlanNode{
+			ID:     string(node.ID()),
+			Kind:   string(node.Kind()),
+			Bounds: interval(node.Bounds()),
+		}
+		for _, pred := range node.Predecessors() {
+			n.Predecessors = append(n.Predecessors, string(pred.ID()))
+		}
+		nodes = append(nodes, n)
+		return nil
+	})
+	return nodes
+}
+
+// walk visits the nodes of the plan bottom up. Unlike plan.Spec.BottomUpWalk,
+// it visits the roots in the order of their IDs so that the order is stable.
+func walk(ps *plan.Spec, f func(plan.Node) error) error {
+	visited := make(map[plan.Node]bool)
+	var visit func(plan.Node) error
+	visit = func(node plan.Node) error {
+		if visited[node] {
+			return nil
+		}
+		visited[node] = true
+		for _, pred := range node.Predecessors() {
+			if err := visit(pred); err != nil {
+				return err
+			}
+		}
+		return f(node)
+	}
+	for _, root := range roots(ps) {
+		if err := visit(root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func roots(ps *plan.Spec) []plan.Node {
+	nodes := make([]plan.Node, 0, len(ps.Roots))
+	for root := range ps.Roots {
+		nodes = append(nodes, root)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].ID() < nodes[j].ID()
+	})
+	return nodes
+}
+
+func interval(b *plan.Bounds) *query.TimeRange {
+	if b == nil {
+		return nil
+	}
+	return &query.TimeRange{
+		Start: b.Start.Time().UTC(),
+		Stop:  b.Stop.Time().UTC(),
+	}
+}
+
+// storageReads describes the requests that the sources of the physical
+// plan send to storage. The buckets are looked up like they are when the
+// sources are created, so the request must be authorized to read them.
+func storageReads(ctx context.Context, req *query.Request, ps *plan.Spec) ([]*query.StorageRead, error) {
+	srs := []*query.StorageRead{}
+	err := walk(ps, func(node plan.Node) error {
+		var r *query.StorageRead
+		var err error
+		switch spec := node.ProcedureSpec().(type) {
+		case *influxdb.ReadRangePhysSpec:
+			r, err = readFilter(ctx, req, node, spec)
+		case *influxdb.ReadGroupPhysSpec:
+			r, err = readGroup(ctx, req, node, spec)
+		case *influxdb.ReadWindowAggregatePhysSpec:
+			r, err = readWindowAggregate(ctx, req, node, spec)
+		default:
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		srs = append(srs, r)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return srs, nil
+}
+
+func readFilter(ctx context.Context, req *query.Request, node plan.Node, spec *influxdb.ReadRangePhysSpec) (*query.StorageRead, error) {
+	fs, err := readFilterSpec(ctx, req, node, spec)
+	if err != nil {
+		return nil, err
+	}
+	sr, err := storageflux.NewReadFilterRequest(fs)
+	if err != nil {
+		return nil, err
+	}
+	return newStorageRead(node, "ReadFilterRequest", spec, fs, sr.Predicate), nil
+}
+
+func readGroup(ctx context.Context, req *query.Request, node plan.Node, spec *influxdb.ReadGroupPhysSpec) (*query.StorageRead, error) {
+	fs, err := readFilterSpec(ctx, req, node, &spec.ReadRangePhysSpec)
+	if err != nil {
+		return nil, err
+	}
+	sr, err := storageflux.NewReadGroupRequest(influxdb.ReadGroupSpec{
+		ReadFilterSpec:  fs,
+		GroupMode:       influxdb.ToGroupMode(spec.GroupMode),
+		GroupKeys:       spec.GroupKeys,
+		AggregateMethod: spec.AggregateMethod,
+	})
+	if err != nil {
+		return nil, err
+	}
+	r := newStorageRead(node, "ReadGroupRequest", &spec.ReadRangePhysSpec, fs, sr.Predicate)
+	r.Group = sr.Group.String()
+	r.GroupKeys = sr.GroupKeys
+	if sr.Aggregate != nil {
+		r.Aggregates = []string{sr.Aggregate.Type.String()}
+	}
+	return r, nil
+}
+
+func readWindowAggregate(ctx context.Context, req *query.Request, node plan.Node, spec *influxdb.ReadWindowAggregatePhysSpec) (*query.StorageRead, error) {
+	fs, err := readFilterSpec(ctx, req, node, &spec.ReadRangePhysSpec)
+	if err != nil {
+		return nil, err
+	}
+	sr, err := storageflux.NewReadWindowAggregateRequest(influxdb.ReadWindowAggregateSpec{
+		ReadFilterSpec: fs,
+		WindowEvery:    spec.WindowEvery,
+		Aggregates:     spec.Aggregates,
+		CreateEmpty:    spec.CreateEmpty,
+	})
+	if err != nil {
+		return nil, err
+	}
+	r := newStorageRead(node, "ReadWindowAggregateRequest", &spec.ReadRangePhysSpec, fs, sr.Predicate)
+	r.WindowEvery = sr.WindowEvery
+	for _, agg := range sr.Aggregate {
+		if agg != nil {
+			r.Aggregates = append(r.Aggregates, agg.Type.String())
+		}
+	}
+	return r, nil
+}
+
+func readFilterSpec(ctx context.Context, req *query.Request, node plan.Node, spec *influxdb.ReadRangePhysSpec) (influxdb.ReadFilterSpec, error) {
+	deps := influxdb.GetStorageDependencies(ctx).FromDeps
+	bucketID, err := spec.LookupBucketID(ctx, req.OrganizationID, deps.BucketLookup)
+	if err != nil {
+		return influxdb.ReadFilterSpec{}, err
+	}
+
+	fs := influxdb.ReadFilterSpec{
+		OrganizationID: req.OrganizationID,
+		BucketID:       bucketID,
+	}
+	if b := node.Bounds(); b != nil {
+		fs.Bounds.Start = b.Start
+		fs.Bounds.Stop = b.Stop
+	}
+	if spec.FilterSet {
+		fs.Predicate = spec.Filter
+	}
+	return fs, nil
+}
+
+func newStorageRead(node plan.Node, request string, spec *influxdb.ReadRangePhysSpec, fs influxdb.ReadFilterSpec, pred *datatypes.Predicate) *query.StorageRead {
+	return &query.StorageRead{
+		Node:      string(node.ID()),
+		Request:   request,
+		Bucket:    spec.Bucket,
+		BucketID:  fs.BucketID.String(),
+		Range:     interval(node.Bounds()),
+		Predicate: predicateString(pred),
+	}
+}
+
+// predicateString renders the predicate with the names that Flux uses
+// for the measurement and field, rather than the keys storage uses.
+func predicateString(p *datatypes.Predicate) string {
+	if p != nil {
+		renameTagRefs(p.Root)
+	}
+	return reads.PredicateToExprString(p)
+}
+
+func renameTagRefs(n *datatypes.Node) {
+	if n == nil {
+		return
+	}
+	if ref, ok := n.Value.(*datatypes.Node_TagRefValue); ok {
+		switch ref.TagRefValue {
+		case models.MeasurementTagKey:
+			ref.TagRefValue = "_measurement"
+		case models.FieldKeyTagKey:
+			ref.TagRefValue = "_field"
+		}
+	}
+	for _, c := range n.Children {
+		renameTagRefs(c)
+	}
+}
@@ -0,0 +1,175 @@
+package explain_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux/dependencies/dependenciestest"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/flux/plan"
+	platform "github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/query"
+	_ "github.com/influxdata/influxdb/v2/query/builtin"
+	"github.com/influxdata/influxdb/v2/query/explain"
+	"github.com/influxdata/influxdb/v2/query/influxql"
+	"github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb"
+	"go.uber.org/zap/zaptest"
+)
+
+var now = time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+
+func newService(t *testing.T) *explain.Service {
+	return explain.NewService(zaptest.NewLogger(t), influxdb.Dependencies{
+		FluxDeps: dependenciestest.Default(),
+		StorageDeps: influxdb.StorageDependencies{
+			FromDeps: influxdb.FromDependencies{
+				BucketLookup:       mock.BucketLookup{},
+				OrganizationLookup: mock.OrganizationLookup{},
+			},
+		},
+	})
+}
+
+func newRequest(q string) *query.Request {
+	return &query.Request{
+		OrganizationID: platform.ID(1),
+		Compiler: lang.FluxCompiler{
+			Now:   now,
+			Query: q,
+		},
+	}
+}
+
+func TestService_Explain(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		rules []string
+		read  *query.StorageRead
+	}{
+		{
+			name: "filter",
+			query: `from(bucket: "my-bucket")
+	|> range(start: -1h)
+	|> filter(fn: (r) => r._measurement == "cpu" and r.host == "a")`,
+			rules: []string{"PushDownRangeRule", "PushDownFilterRule"},
+			read: &query.StorageRead{
+				Request:   "ReadFilterRequest",
+				Bucket:    "my-bucket",
+				BucketID:  "0000000000000001",
+				Range:     &query.TimeRange{Start: now.Add(-time.Hour), Stop: now},
+				Predicate: `'_measurement' = "cpu" AND 'host' = "a"`,
+			},
+		},
+		{
+			name: "group",
+			query: `from(bucket: "my-bucket")
+	|> range(start: -1h)
+	|> group(columns: ["host"])`,
+			rules: []string{"PushDownRangeRule", "PushDownGroupRule"},
+			read: &query.StorageRead{
+				Request:   "ReadGroupRequest",
+				Bucket:    "my-bucket",
+				BucketID:  "0000000000000001",
+				Range:     &query.TimeRange{Start: now.Add(-time.Hour), Stop: now},
+				Predicate: "[none]",
+				Group:     "GROUP_BY",
+				GroupKeys: []string{"host"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := newService(t).Explain(context.Background(), newRequest(tt.query))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := len(e.LogicalPlan), 4; got != want {
+				t.Errorf("unexpected logical plan nodes: got %d want %d", got, want)
+			}
+			var rules []string
+			for _, r := range e.Rules {
+				if r.Phase != query.PhysicalPlanPhase {
+					t.Errorf("unexpected %s rule %s", r.Phase, r.Name)
+				}
+				rules = append(rules, r.Name)
+			}
+			if !cmp.Equal(tt.rules, rules) {
+				t.Errorf("unexpected rules fired -want/+got:\n%s", cmp.Diff(tt.rules, rules))
+			}
+
+			if len(e.StorageReads) != 1 {
+				t.Fatalf("expected one storage read, got %d", len(e.StorageReads))
+			}
+			read := e.StorageReads[0]
+			tt.read.Node = read.Node
+			if !cmp.Equal(tt.read, read) {
+				t.Errorf("unexpected storage read -want/+got:\n%s", cmp.Diff(tt.read, read))
+			}
+
+			for _, s := range []string{"Logical Plan:", "Physical Plan:", "└── ", read.Node, tt.read.Predicate} {
+				if !strings.Contains(e.Text, s) {
+					t.Errorf("expected text to contain %q:\n%s", s, e.Text)
+				}
+			}
+		})
+	}
+}
+
+// TestService_Explain_MatchesPlanner checks that the service plans
+// queries the same way the planner does with its registered rules.
+func TestService_Explain_MatchesPlanner(t *testing.T) {
+	for _, q := range []string{
+		`from(bucket: "my-bucket") |> range(start: -1h) |> filter(fn: (r) => true)`,
+		`from(bucket: "my-bucket") |> range(start: -1h) |> filter(fn: (r) => r._field == "usage") |> group(columns: ["host"]) |> sum()`,
+		`from(bucket: "my-bucket") |> range(start: -1h) |> group() |> group(columns: ["host"]) |> count()`,
+		`from(bucket: "my-bucket") |> range(start: -1h) |> window(every: 1m) |> mean()`,
+		`import "influxdata/influxdb/v1" v1.tagValues(bucket: "my-bucket", tag: "host")`,
+	} {
+		t.Run(q, func(t *testing.T) {
+			req := newRequest(q)
+			e, err := newService(t).Explain(context.Background(), req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := influxdb.StorageDependencies{}.Inject(context.Background())
+			spec, err := query.SpecFromAST(ctx, parser.ParseSource(q), now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ps, err := plan.PlannerBuilder{}.Build().Plan(ctx, spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want []string
+			_ = ps.BottomUpWalk(func(node plan.Node) error {
+				want = append(want, string(node.Kind()))
+				return nil
+			})
+			var got []string
+			for _, n := range e.PhysicalPlan {
+				got = append(got, n.Kind)
+			}
+			if !cmp.Equal(want, got) {
+				t.Errorf("unexpected physical plan -want/+got:\n%s", cmp.Diff(want, got))
+			}
+		})
+	}
+}
+
+func TestService_Explain_Unsupported(t *testing.T) {
+	req := &query.Request{
+		OrganizationID: platform.ID(1),
+		Compiler:       &influxql.Compiler{},
+	}
+	if _, err := newService(t).Explain(context.Background(), req); err == nil {
+		t.Fatal("expected error explaining an InfluxQL query")
+	}
+}
@@ -0,0 +1,99 @@
+package explain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/query"
+)
+
+// format renders the explanation as text. The plans are drawn as trees
+// that start at their results and descend into the predecessors of each
+// node, so the sources of the query are the leaves.
+func format(e *query.Explanation) string {
+	var b strings.Builder
+	if len(e.LogicalPlan) > 0 {
+		b.WriteString("Logical Plan:\n")
+		formatPlan(&b, e.LogicalPlan)
+		b.WriteString("\n")
+	}
+
+	b.WriteString("Physical Plan:\n")
+	formatPlan(&b, e.PhysicalPlan)
+
+	b.WriteString("\nRules Fired:\n")
+	if len(e.Rules) == 0 {
+		b.WriteString("  none\n")
+	}
+	for _, r := range e.Rules {
+		fmt.Fprintf(&b, "  %s: %s on %s\n", r.Phase, r.Name, r.Node)
+	}
+
+	b.WriteString("\nStorage Reads:\n")
+	if len(e.StorageReads) == 0 {
+		b.WriteString("  none\n")
+	}
+	for _, r := range e.StorageReads {
+		fmt.Fprintf(&b, "  %s: %s\n", r.Node, r.Request)
+		bucket := r.Bucket
+		if bucket == "" {
+			bucket = r.BucketID
+		} else if r.BucketID != "" {
+			bucket = fmt.Sprintf("%s (%s)", r.Bucket, r.BucketID)
+		}
+		fmt.Fprintf(&b, "    bucket:     %s\n", bucket)
+		if r.Range != nil {
+			fmt.Fprintf(&b, "    range:      [%s, %s)\n", r.Range.Start.Format(time.RFC3339Nano), r.Range.Stop.Format(time.RFC3339Nano))
+		}
+		fmt.Fprintf(&b, "    predicate:  %s\n", r.Predicate)
+		if r.Group != "" {
+			fmt.Fprintf(&b, "    group:      %s [%s]\n", r.Group, strings.Join(r.GroupKeys, ", "))
+		}
+		if r.WindowEvery != 0 {
+			fmt.Fprintf(&b, "    every:      %s\n", time.Duration(r.WindowEvery))
+		}
+		if len(r.Aggregates) > 0 {
+			fmt.Fprintf(&b, "    aggregates: %s\n", strings.Join(r.Aggregates, ", "))
+		}
+	}
+	return b.String()
+}
+
+// formatPlan draws a tree for each node that is not the predecessor
+// of another node.
+func formatPlan(b *strings.Builder, nodes []*query.PlanNode) {
+	byID := make(map[string]*query.PlanNode, len(nodes))
+	hasSuccessor := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		byID[n.ID] = n
+		for _, pred := range n.Predecessors {
+			hasSuccessor[pred] = true
+		}
+	}
+
+	var draw func(n *query.PlanNode, prefix, branch, indent string)
+	draw = func(n *query.PlanNode, prefix, branch, indent string) {
+		b.WriteString(prefix + branch + n.ID)
+		if n.Bounds != nil {
+			fmt.Fprintf(b, " [%s, %s)", n.Bounds.Start.Format(time.RFC3339Nano), n.Bounds.Stop.Format(time.RFC3339Nano))
+		}
+		b.WriteString("\n")
+		for i, id := range n.Predecessors {
+			pred, ok := byID[id]
+			if !ok {
+				continue
+			}
+			if i == len(n.Predecessors)-1 {
+				draw(pred, prefix+indent, "└── ", "    ")
+			} else {
+				draw(pred, prefix+indent, "├── ", "│   ")
+			}
+		}
+	}
+	for _, n := range nodes {
+		if !hasSuccessor[n.ID] {
+			draw(n, "  ", "", "")
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package flight
+
+// The messages and service of flight.proto. They are declared by hand, as
+// the version of Arrow in use has no Flight package, and are marshaled by
+// the reflection of their protobuf struct tags.
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+)
+
+type FlightDescriptor_DescriptorType int32
+
+const (
+	FlightDescriptor_UNKNOWN FlightDescriptor_DescriptorType = 0
+	FlightDescriptor_PATH    FlightDescriptor_DescriptorType = 1
+	FlightDescriptor_CMD     FlightDescriptor_DescriptorType = 2
+)
+
+type FlightDescriptor struct {
+	Type FlightDescriptor_DescriptorType `protobuf:"varint,1,opt,name=type,proto3,enum=arrow.flight.protocol.FlightDescriptor_DescriptorType" json:"type,omitempty"`
+	Cmd  []byte                          `protobuf:"bytes,2,opt,name=cmd,proto3" json:"cmd,omitempty"`
+	Path []string                        `protobuf:"bytes,3,rep,name=path,proto3" json:"path,omitempty"`
+}
+
+func (m *FlightDescriptor) Reset()         { *m = FlightDescriptor{} }
+func (m *FlightDescriptor) String() string { return proto.CompactTextString(m) }
+func (*FlightDescriptor) ProtoMessage()    {}
+
+type Ticket struct {
+	Ticket []byte `protobuf:"bytes,1,opt,name=ticket,proto3" json:"ticket,omitempty"`
+}
+
+func (m *Ticket) Reset()         { *m = Ticket{} }
+func (m *Ticket) String() string { return proto.CompactTextString(m) }
+func (*Ticket) ProtoMessage()    {}
+
+type FlightData struct {
+	FlightDescriptor *FlightDescriptor `protobuf:"bytes,1,opt,name=flight_descriptor,json=flightDescriptor,proto3" json:"flight_descriptor,omitempty"`
+	DataHeader       []byte            `protobuf:"bytes,2,opt,name=data_header,json=dataHeader,proto3" json:"data_header,omitempty"`
+	AppMetadata      []byte            `protobuf:"bytes,3,opt,name=app_metadata,json=appMetadata,proto3" json:"app_metadata,omitempty"`
+	DataBody         []byte            `protobuf:"bytes,1000,opt,name=data_body,json=dataBody,proto3" json:"data_body,omitempty"`
+}
+
+func (m *FlightData) Reset()         { *m = FlightData{} }
+func (m *FlightData) String() string { return proto.CompactTextString(m) }
+func (*FlightData) ProtoMessage()    {}
+
+// FlightServiceClient is the client API for FlightService service.
+type FlightServiceClient interface {
+	DoGet(ctx context.Context, in *Ticket, opts ...grpc.CallOption) (FlightService_DoGetClient, error)
+}
+
+type flightServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewFlightServiceClient(cc *grpc.ClientConn) FlightServiceClient {
+	return &flightServiceClient{cc}
+}
+
+func (c *flightServiceClient) DoGet(ctx context.Context, in *Ticket, opts ...grpc.CallOption) (FlightService_DoGetClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_FlightService_serviceDesc.Streams[0], "/arrow.flight.protocol.FlightService/DoGet", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &flightServiceDoGetClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type FlightService_DoGetClient interface {
+	Recv() (*FlightData, error)
+	grpc.ClientStream
+}
+
+type flightServiceDoGetClient struct {
+	grpc.ClientStream
+}
+
+func (x *flightServiceDoGetClient) Recv() (*FlightData, error) {
+	m := new(FlightData)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// FlightServiceServer is the server API for FlightService service.
+type FlightServiceServer interface {
+	DoGet(*Ticket, FlightService_DoGetServer) error
+}
+
+func RegisterFlightServiceServer(s *grpc.Server, srv FlightServiceServer) {
+	s.RegisterService(&_FlightService_serviceDesc, srv)
+}
+
+func _FlightService_DoGet_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Ticket)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FlightServiceServer).DoGet(m, &flightServiceDoGetServer{stream})
+}
+
+type FlightService_DoGetServer interface {
+	Send(*FlightData) error
+	grpc.ServerStream
+}
+
+type flightServiceDoGetServer struct {
+	grpc.ServerStream
+}
+
+func (x *flightServiceDoGetServer) Send(m *FlightData) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _FlightService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "arrow.flight.protocol.FlightService",
+	HandlerType: (*FlightServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "DoGet",
+			Handler:       _FlightService_DoGet_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "flight.proto",
+}
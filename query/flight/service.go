@@ -0,0 +1,314 @@
+// Package flight implements the DoGet method of the Arrow Flight protocol,
+// which streams the results of Flux queries as Arrow record batches over
+// gRPC for bulk reads.
+package flight
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	pcontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/ratelimit"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// TicketRequest is the JSON content of the tickets of DoGet.
+type TicketRequest struct {
+	Org   string      `json:"org,omitempty"`
+	OrgID influxdb.ID `json:"orgID,omitempty"`
+	Query string      `json:"query"`
+}
+
+// Service serves the results of the Flux query of a ticket.
+//
+// The tables of the results are written as in the Arrow responses of the
+// query endpoint: each table starts with a schema message, whose metadata
+// identifies the table, followed by its record batches. The token of the
+// request is read from the authorization metadata of the call, as
+// "Token <token>" or "Bearer <token>".
+//
+// As on the query endpoint, queries are allowed by the rate limiter, which
+// rejects them with ResourceExhausted and a retry-after trailer, and their
+// tickets are recorded as the usage of the organization. Tokens are allowed
+// the permissions of the roles of their user when the RoleService and
+// UserGroupService are set.
+type Service struct {
+	log *zap.Logger
+
+	QueryService         query.QueryService
+	AuthorizationService influxdb.AuthorizationService
+	OrganizationService  influxdb.OrganizationService
+	RoleService          influxdb.RoleService
+	UserGroupService     influxdb.UserGroupService
+	RateLimiter          *ratelimit.Limiter
+	UsageRecorder        influxdb.UsageRecorder
+}
+
+// NewService returns a new instance of Service.
+func NewService(log *zap.Logger, qs query.QueryService, as influxdb.AuthorizationService, os influxdb.OrganizationService) *Service {
+	return &Service{
+		log:                  log,
+		QueryService:         qs,
+		AuthorizationService: as,
+		OrganizationService:  os,
+	}
+}
+
+// NewServer returns a gRPC server of the service.
+func NewServer(s *Service, opts ...grpc.ServerOption) *grpc.Server {
+	srv := grpc.NewServer(opts...)
+	RegisterFlightServiceServer(srv, s)
+	return srv
+}
+
+// DoGet performs the query of the ticket and streams its results.
+func (s *Service) DoGet(ticket *Ticket, stream FlightService_DoGetServer) error {
+	ctx := stream.Context()
+
+	var tr TicketRequest
+	if err := json.Unmarshal(ticket.Ticket, &tr); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid ticket: %v", err)
+	}
+	if tr.Query == "" {
+		return status.Error(codes.InvalidArgument, "ticket has no query")
+	}
+
+	auth, err := s.authorize(ctx)
+	if err != nil {
+		return err
+	}
+	org, err := s.findOrganization(ctx, tr)
+	if err != nil {
+		return err
+	}
+
+	ctx = pcontext.SetAuthorizer(ctx, auth)
+	requestBytes := len(ticket.Ticket)
+	if s.RateLimiter != nil {
+		if err := s.RateLimiter.AllowQuery(ctx, org.ID, remoteIP(ctx), requestBytes); err != nil {
+			return rateLimitedStatus(stream, err)
+		}
+	}
+	s.recordUsage(ctx, org.ID, requestBytes)
+
+	results, err := s.QueryService.Query(ctx, &query.Request{
+		Authorization:  auth,
+		OrganizationID: org.ID,
+		Compiler: lang.FluxCompiler{
+			Now:   time.Now(),
+			Query: tr.Query,
+		},
+	})
+	if err != nil {
+		return toStatus(err)
+	}
+	defer results.Release()
+
+	w := &messageWriter{stream: stream}
+	for results.More() {
+		res := results.Next()
+		n := 0
+		if err := res.Tables().Do(func(tbl flux.Table) error {
+			defer func() { n++ }()
+			return writeTable(w, res.Name(), n, tbl)
+		}); err != nil {
+			return toStatus(err)
+		}
+	}
+	if err := results.Err(); err != nil {
+		return toStatus(err)
+	}
+	return nil
+}
+
+// authorize finds the authorization of the token of the call.
+func (s *Service) authorize(ctx context.Context) (*influxdb.Authorization, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var token string
+	for _, v := range md.Get("authorization") {
+		for _, scheme := range []string{"Token ", "Bearer "} {
+			if strings.HasPrefix(v, scheme) {
+				token = strings.TrimPrefix(v, scheme)
+			}
+		}
+	}
+	if token == "" {
+		return nil, status.Error(codes.Unauthenticated, "token required")
+	}
+
+	auth, err := s.AuthorizationService.FindAuthorizationByToken(ctx, token)
+	if err != nil {
+		s.log.Info("Unauthorized", zap.Error(err))
+		return nil, status.Error(codes.Unauthenticated, "unauthorized access")
+	}
+	if !auth.IsActive() {
+		return nil, status.Error(codes.PermissionDenied, "authorization is inactive")
+	}
+	if s.RoleService == nil || s.UserGroupService == nil {
+		return auth, nil
+	}
+	auth, err = authorizer.AuthorizationWithRoles(ctx, s.RoleService, s.UserGroupService, auth)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return auth, nil
+}
+
+// recordUsage records a query of bytes to the organization, unless the
+// service has no usage recorder.
+func (s *Service) recordUsage(ctx context.Context, orgID influxdb.ID, bytes int) {
+	if s.UsageRecorder == nil {
+		return
+	}
+	for m, v := range map[influxdb.UsageMetric]int{
+		influxdb.UsageQueryRequestCount: 1,
+		influxdb.UsageQueryRequestBytes: bytes,
+	} {
+		s.UsageRecorder.RecordUsage(ctx, influxdb.Usage{OrganizationID: &orgID, Type: m, Value: float64(v)})
+	}
+}
+
+// remoteIP returns the IP address of the client of the call.
+func remoteIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	addr := p.Addr.String()
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+// rateLimitedStatus converts the error of the rate limiter to a status
+// error, with the seconds to wait before retrying in the retry-after trailer
+// when a rate limit is exceeded.
+func rateLimitedStatus(stream grpc.ServerStream, err error) error {
+	e, ok := err.(*ratelimit.Error)
+	if !ok {
+		return toStatus(err)
+	}
+	retry := int(math.Ceil(e.RetryAfter.Seconds()))
+	stream.SetTrailer(metadata.Pairs("retry-after", strconv.Itoa(retry)))
+	return status.Error(codes.ResourceExhausted, e.Error())
+}
+
+func (s *Service) findOrganization(ctx context.Context, tr TicketRequest) (*influxdb.Organization, error) {
+	var filter influxdb.OrganizationFilter
+	switch {
+	case tr.OrgID.Valid():
+		filter.ID = &tr.OrgID
+	case tr.Org != "":
+		filter.Name = &tr.Org
+	default:
+		return nil, status.Error(codes.InvalidArgument, "ticket has no org or orgID")
+	}
+	org, err := s.OrganizationService.FindOrganization(ctx, filter)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return org, nil
+}
+
+// writeTable writes the Arrow stream of a table.
+func writeTable(w *messageWriter, result string, n int, tbl flux.Table) error {
+	schema, err := query.ArrowSchema(result, n, tbl.Key(), tbl.Cols())
+	if err != nil {
+		return err
+	}
+	iw := ipc.NewWriter(w, ipc.WithSchema(schema), ipc.WithAllocator(memory.DefaultAllocator))
+	if err := tbl.Do(func(cr flux.ColReader) error {
+		rec := query.ArrowRecord(schema, cr)
+		defer rec.Release()
+		return iw.Write(rec)
+	}); err != nil {
+		return err
+	}
+	return iw.Close()
+}
+
+// messageWriter splits the Arrow IPC stream written to it into its
+// messages, and sends each of them as the header and body of a FlightData.
+// The end of stream markers are dropped, as the end of the call ends the
+// Flight stream.
+type messageWriter struct {
+	stream FlightService_DoGetServer
+	buf    bytes.Buffer
+}
+
+// The size of the continuation marker and length that prefix a message.
+const messagePrefixLen = 8
+
+func (w *messageWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	for w.buf.Len() >= messagePrefixLen {
+		b := w.buf.Bytes()
+		metaLen := int(binary.LittleEndian.Uint32(b[4:messagePrefixLen]))
+		if metaLen == 0 {
+			w.buf.Next(messagePrefixLen)
+			continue
+		}
+		if len(b) < messagePrefixLen+metaLen {
+			break
+		}
+		meta := b[messagePrefixLen : messagePrefixLen+metaLen]
+		msg := ipc.NewMessage(memory.NewBufferBytes(meta), memory.NewBufferBytes(nil))
+		bodyLen := int(msg.BodyLen())
+		msg.Release()
+		if len(b) < messagePrefixLen+metaLen+bodyLen {
+			break
+		}
+
+		data := &FlightData{
+			DataHeader: append([]byte(nil), meta...),
+			DataBody:   append([]byte(nil), b[messagePrefixLen+metaLen:messagePrefixLen+metaLen+bodyLen]...),
+		}
+		if err := w.stream.Send(data); err != nil {
+			return 0, err
+		}
+		w.buf.Next(messagePrefixLen + metaLen + bodyLen)
+	}
+	return len(p), nil
+}
+
+// toStatus converts an error to a gRPC status error.
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	code := codes.Unknown
+	switch influxdb.ErrorCode(err) {
+	case influxdb.EInvalid, influxdb.EUnprocessableEntity:
+		code = codes.InvalidArgument
+	case influxdb.ENotFound:
+		code = codes.NotFound
+	case influxdb.EUnauthorized:
+		code = codes.Unauthenticated
+	case influxdb.EForbidden:
+		code = codes.PermissionDenied
+	case influxdb.EInternal:
+		code = codes.Internal
+	}
+	return status.Error(code, err.Error())
+}
@@ -0,0 +1,311 @@
+package flight_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/query/flight"
+	qmock "github.com/influxdata/influxdb/v2/query/mock"
+	"github.com/influxdata/influxdb/v2/ratelimit"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testToken = "mytoken"
+	testOrgID = influxdb.ID(1)
+)
+
+func newClient(t *testing.T, opts ...func(*flight.Service)) (flight.FlightServiceClient, func()) {
+	t.Helper()
+
+	as := mock.NewAuthorizationService()
+	as.FindAuthorizationByTokenFn = func(ctx context.Context, token string) (*influxdb.Authorization, error) {
+		if token != testToken {
+			return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "authorization not found"}
+		}
+		return &influxdb.Authorization{ID: 2, OrgID: testOrgID, Status: influxdb.Active}, nil
+	}
+	os := mock.NewOrganizationService()
+	os.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+		return &influxdb.Organization{ID: testOrgID, Name: *filter.Name}, nil
+	}
+	qs := &qmock.QueryService{
+		QueryF: func(ctx context.Context, req *query.Request) (flux.ResultIterator, error) {
+			if req.OrganizationID != testOrgID || req.Authorization == nil || req.Authorization.ID != 2 {
+				t.Errorf("unexpected request: %+v", req)
+			}
+			if q := req.Compiler.(lang.FluxCompiler).Query; q != "from(bucket: \"b\")" {
+				t.Errorf("unexpected query: %q", q)
+			}
+			result := executetest.NewResult([]*executetest.Table{
+				{
+					KeyCols: []string{"t"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TFloat},
+						{Label: "t", Type: flux.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(0), 1.0, "a"},
+						{execute.Time(10), 2.0, "a"},
+					},
+				},
+				{
+					KeyCols: []string{"t"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TFloat},
+						{Label: "t", Type: flux.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(0), 3.0, "b"},
+					},
+				},
+			})
+			result.Nm = "_result"
+			return flux.NewSliceResultIterator([]flux.Result{result}), nil
+		},
+	}
+
+	svc := flight.NewService(zaptest.NewLogger(t), qs, as, os)
+	for _, opt := range opts {
+		opt(svc)
+	}
+	srv := flight.NewServer(svc)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = srv.Serve(ln) }()
+
+	conn, err := grpc.Dial(ln.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		srv.Stop()
+		t.Fatal(err)
+	}
+	return flight.NewFlightServiceClient(conn), func() {
+		_ = conn.Close()
+		srv.Stop()
+	}
+}
+
+// readTables reads the tables of the Flight stream, each of which starts
+// with a schema message.
+func readTables(t *testing.T, stream flight.FlightService_DoGetClient) [][]string {
+	t.Helper()
+
+	var streams []*bytes.Buffer
+	for {
+		data, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		msg := ipc.NewMessage(memory.NewBufferBytes(data.DataHeader), memory.NewBufferBytes(data.DataBody))
+		if msg.Type() == ipc.MessageSchema {
+			streams = append(streams, new(bytes.Buffer))
+		}
+		msg.Release()
+
+		buf := streams[len(streams)-1]
+		prefix := make([]byte, 8)
+		binary.LittleEndian.PutUint32(prefix, 0xFFFFFFFF)
+		binary.LittleEndian.PutUint32(prefix[4:], uint32(len(data.DataHeader)))
+		buf.Write(prefix)
+		buf.Write(data.DataHeader)
+		buf.Write(data.DataBody)
+	}
+
+	var tables [][]string
+	for _, buf := range streams {
+		r, err := ipc.NewReader(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var values []string
+		for r.Next() {
+			rec := r.Record()
+			for i := 0; i < int(rec.NumCols()); i++ {
+				if s, ok := rec.Column(i).(*array.String); ok {
+					for j := 0; j < s.Len(); j++ {
+						values = append(values, s.Value(j))
+					}
+				}
+			}
+		}
+		r.Release()
+		tables = append(tables, values)
+	}
+	return tables
+}
+
+func TestService_DoGet(t *testing.T) {
+	client, closeFn := newClient(t)
+	defer closeFn()
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Token "+testToken)
+	stream, err := client.DoGet(ctx, &flight.Ticket{
+		Ticket: []byte(`{"org":"myorg","query":"from(bucket: \"b\")"}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tables := readTables(t, stream)
+	if len(tables) != 2 {
+		t.Fatalf("unexpected number of tables: got %d want 2", len(tables))
+	}
+	if got, want := tables[0], []string{"a", "a"}; len(got) != len(want) || got[0] != want[0] {
+		t.Errorf("unexpected first table: got %v want %v", got, want)
+	}
+	if got, want := tables[1], []string{"b"}; len(got) != len(want) || got[0] != want[0] {
+		t.Errorf("unexpected second table: got %v want %v", got, want)
+	}
+}
+
+func TestService_DoGet_Errors(t *testing.T) {
+	client, closeFn := newClient(t)
+	defer closeFn()
+
+	for _, tt := range []struct {
+		name   string
+		token  string
+		ticket string
+		code   codes.Code
+	}{
+		{
+			name:   "no token",
+			ticket: `{"org":"myorg","query":"from(bucket: \"b\")"}`,
+			code:   codes.Unauthenticated,
+		},
+		{
+			name:   "invalid token",
+			token:  "Token invalid",
+			ticket: `{"org":"myorg","query":"from(bucket: \"b\")"}`,
+			code:   codes.Unauthenticated,
+		},
+		{
+			name:   "invalid ticket",
+			token:  "Bearer " + testToken,
+			ticket: `from(bucket: "b")`,
+			code:   codes.InvalidArgument,
+		},
+		{
+			name:   "no org",
+			token:  "Bearer " + testToken,
+			ticket: `{"query":"from(bucket: \"b\")"}`,
+			code:   codes.InvalidArgument,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.token != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", tt.token)
+			}
+			stream, err := client.DoGet(ctx, &flight.Ticket{Ticket: []byte(tt.ticket)})
+			if err == nil {
+				_, err = stream.Recv()
+			}
+			if got := status.Code(err); got != tt.code {
+				t.Errorf("unexpected code: got %v want %v (%v)", got, tt.code, err)
+			}
+		})
+	}
+}
+
+type usageRecorder map[influxdb.UsageMetric]float64
+
+func (r usageRecorder) RecordUsage(_ context.Context, u influxdb.Usage) {
+	r[u.Type] += u.Value
+}
+
+func TestService_DoGet_RateLimit(t *testing.T) {
+	limits := mock.NewOrgLimitsService()
+	limits.FindOrgLimitsFn = func(_ context.Context, orgID influxdb.ID) (*influxdb.OrgLimits, error) {
+		return &influxdb.OrgLimits{
+			OrgID:      orgID,
+			RateLimits: influxdb.RateLimits{QueriesPerSecond: 1},
+		}, nil
+	}
+	recorder := usageRecorder{}
+	client, closeFn := newClient(t, func(s *flight.Service) {
+		s.RateLimiter = ratelimit.NewLimiter(limits, influxdb.RateLimits{})
+		s.UsageRecorder = recorder
+	})
+	defer closeFn()
+
+	ticket := []byte(`{"org":"myorg","query":"from(bucket: \"b\")"}`)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Token "+testToken)
+	stream, err := client.DoGet(ctx, &flight.Ticket{Ticket: ticket})
+	if err != nil {
+		t.Fatal(err)
+	}
+	readTables(t, stream)
+
+	stream, err = client.DoGet(ctx, &flight.Ticket{Ticket: ticket})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if got, want := status.Code(err), codes.ResourceExhausted; got != want {
+		t.Fatalf("unexpected code: got %v want %v (%v)", got, want, err)
+	}
+	if got, want := stream.Trailer().Get("retry-after"), []string{"1"}; len(got) != 1 || got[0] != want[0] {
+		t.Errorf("unexpected retry-after: got %v want %v", got, want)
+	}
+
+	// Only the allowed query is recorded.
+	if got := recorder[influxdb.UsageQueryRequestCount]; got != 1 {
+		t.Errorf("unexpected query count: got %v want 1", got)
+	}
+	if got, want := recorder[influxdb.UsageQueryRequestBytes], float64(len(ticket)); got != want {
+		t.Errorf("unexpected query bytes: got %v want %v", got, want)
+	}
+}
+
+func TestService_DoGet_Roles(t *testing.T) {
+	var userIDs []influxdb.ID
+	roles := mock.NewRoleService()
+	roles.FindRolesFn = func(_ context.Context, filter influxdb.RoleFilter, _ ...influxdb.FindOptions) ([]*influxdb.Role, int, error) {
+		userIDs = append(userIDs, *filter.UserID)
+		return nil, 0, &influxdb.Error{Code: influxdb.EInternal, Msg: "roles unavailable"}
+	}
+	client, closeFn := newClient(t, func(s *flight.Service) {
+		s.RoleService = roles
+		s.UserGroupService = mock.NewUserGroupService()
+	})
+	defer closeFn()
+
+	// The roles of the user of the token are evaluated before the query.
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Token "+testToken)
+	stream, err := client.DoGet(ctx, &flight.Ticket{
+		Ticket: []byte(`{"org":"myorg","query":"from(bucket: \"b\")"}`),
+	})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if got, want := status.Code(err), codes.Internal; got != want {
+		t.Fatalf("unexpected code: got %v want %v (%v)", got, want, err)
+	}
+	if len(userIDs) != 1 {
+		t.Fatalf("expected the roles of the user to be found once, got %d", len(userIDs))
+	}
+}
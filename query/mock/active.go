@@ -0,0 +1,31 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2/query"
+)
+
+var _ query.ActiveQueryService = (*ActiveQueryService)(nil)
+
+// ActiveQueryService mocks the query.ActiveQueryService for testing.
+type ActiveQueryService struct {
+	FindActiveQueriesFn   func(ctx context.Context, filter query.ActiveQueryFilter) ([]*query.ActiveQuery, error)
+	FindActiveQueryByIDFn func(ctx context.Context, id uint64) (*query.ActiveQuery, error)
+	CancelActiveQueryFn   func(ctx context.Context, id uint64) error
+}
+
+// FindActiveQueries returns the active queries that match the filter.
+func (s *ActiveQueryService) FindActiveQueries(ctx context.Context, filter query.ActiveQueryFilter) ([]*query.ActiveQuery, error) {
+	return s.FindActiveQueriesFn(ctx, filter)
+}
+
+// FindActiveQueryByID returns the active query with the given ID.
+func (s *ActiveQueryService) FindActiveQueryByID(ctx context.Context, id uint64) (*query.ActiveQuery, error) {
+	return s.FindActiveQueryByIDFn(ctx, id)
+}
+
+// CancelActiveQuery cancels the active query with the given ID.
+func (s *ActiveQueryService) CancelActiveQuery(ctx context.Context, id uint64) error {
+	return s.CancelActiveQueryFn(ctx, id)
+}
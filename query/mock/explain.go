@@ -0,0 +1,19 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2/query"
+)
+
+var _ query.ExplainService = (*ExplainService)(nil)
+
+// ExplainService mocks the query.ExplainService for testing.
+type ExplainService struct {
+	ExplainFn func(ctx context.Context, req *query.Request) (*query.Explanation, error)
+}
+
+// Explain compiles and plans the query of the request.
+func (s *ExplainService) Explain(ctx context.Context, req *query.Request) (*query.Explanation, error) {
+	return s.ExplainFn(ctx, req)
+}
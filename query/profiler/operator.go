@@ -0,0 +1,295 @@
+package profiler
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/plan"
+)
+
+// OperatorProfileKind is the kind of the plan nodes that profile the
+// operator they follow.
+const OperatorProfileKind = "OperatorProfileKind"
+
+func init() {
+	execute.RegisterTransformation(OperatorProfileKind, createOperatorProfileTransformation)
+}
+
+// profile holds the profiles of the operators of one query.
+type profile struct {
+	start     time.Time
+	operators []*operatorProfile
+}
+
+// instrument inserts a node after each operator of the physical plan
+// that records what the operator produces. Yields and operators without
+// successors, such as side effects, are not profiled.
+func instrument(ps *plan.Spec) *profile {
+	var nodes []plan.Node
+	_ = ps.BottomUpWalk(func(node plan.Node) error {
+		if _, ok := node.ProcedureSpec().(plan.YieldProcedureSpec); ok {
+			return nil
+		}
+		if len(node.Successors()) > 0 {
+			nodes = append(nodes, node)
+		}
+		return nil
+	})
+
+	prof := &profile{}
+	profiles := make(map[plan.Node]*operatorProfile, len(nodes))
+	for _, node := range nodes {
+		op := &operatorProfile{
+			operator: string(node.ID()),
+			kind:     string(node.Kind()),
+		}
+		for _, pred := range node.Predecessors() {
+			if p, ok := profiles[pred]; ok {
+				op.inputs = append(op.inputs, p)
+			}
+		}
+		op.finished.Add(1)
+		prof.operators = append(prof.operators, op)
+
+		pn := plan.CreatePhysicalNode(plan.NodeID(fmt.Sprintf("%s_profile", node.ID())), &OperatorProfileProcedureSpec{
+			profile: op,
+		})
+		pn.SetBounds(node.Bounds())
+		for _, succ := range node.Successors() {
+			preds := succ.Predecessors()
+			for i := range preds {
+				if preds[i] == node {
+					preds[i] = pn
+				}
+			}
+			pn.AddSuccessors(succ)
+		}
+		node.ClearSuccessors()
+		node.AddSuccessors(pn)
+		pn.AddPredecessors(node)
+
+		// The successors of the operator now read from the profiling node.
+		profiles[node] = op
+		profiles[pn] = op
+	}
+	return prof
+}
+
+// OperatorProfileProcedureSpec is the procedure spec of a plan node
+// that profiles its predecessor.
+type OperatorProfileProcedureSpec struct {
+	plan.DefaultCost
+
+	profile *operatorProfile
+}
+
+func (s *OperatorProfileProcedureSpec) Kind() plan.ProcedureKind {
+	return OperatorProfileKind
+}
+
+func (s *OperatorProfileProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := *s
+	return &ns
+}
+
+func createOperatorProfileTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*OperatorProfileProcedureSpec)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid spec type %T", spec)
+	}
+	d := execute.NewPassthroughDataset(id)
+	return &operatorProfileTransformation{d: d, profile: s.profile}, d, nil
+}
+
+// operatorProfileTransformation passes the tables of an operator
+// through to its successors while recording them in the profile.
+type operatorProfileTransformation struct {
+	d       *execute.PassthroughDataset
+	profile *operatorProfile
+}
+
+func (t *operatorProfileTransformation) RetractTable(id execute.DatasetID, key flux.GroupKey) error {
+	return t.d.RetractTable(key)
+}
+
+func (t *operatorProfileTransformation) Process(id execute.DatasetID, tbl flux.Table) error {
+	t.profile.addTable()
+	return t.d.Process(&countingTable{Table: tbl, profile: t.profile})
+}
+
+func (t *operatorProfileTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
+	return t.d.UpdateWatermark(mark)
+}
+
+func (t *operatorProfileTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateProcessingTime(pt)
+}
+
+func (t *operatorProfileTransformation) Finish(id execute.DatasetID, err error) {
+	t.profile.finish()
+	t.d.Finish(err)
+}
+
+// countingTable counts the rows of the table as they are read.
+type countingTable struct {
+	flux.Table
+	profile *operatorProfile
+}
+
+func (t *countingTable) Do(f func(flux.ColReader) error) error {
+	return t.Table.Do(func(cr flux.ColReader) error {
+		t.profile.addRows(cr.Len())
+		return f(cr)
+	})
+}
+
+// operatorProfile records the tables and rows that an operator produced
+// and when it produced them.
+type operatorProfile struct {
+	operator string
+	kind     string
+	inputs   []*operatorProfile
+
+	// finished is done once the operator has finished.
+	finished sync.WaitGroup
+
+	mu         sync.Mutex
+	tables     int64
+	rows       int64
+	firstTable time.Time
+	stop       time.Time
+}
+
+func (p *operatorProfile) addTable() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.tables == 0 {
+		p.firstTable = time.Now()
+	}
+	p.tables++
+}
+
+func (p *operatorProfile) addRows(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rows += int64(n)
+}
+
+func (p *operatorProfile) finish() {
+	p.mu.Lock()
+	p.stop = time.Now()
+	p.mu.Unlock()
+	p.finished.Done()
+}
+
+// startedAt reports when the operator received its first input.
+// Sources start with the query.
+func (p *operatorProfile) startedAt(queryStart time.Time) time.Time {
+	if len(p.inputs) == 0 {
+		return queryStart
+	}
+	var start time.Time
+	for _, in := range p.inputs {
+		in.mu.Lock()
+		t := in.firstTable
+		if t.IsZero() {
+			t = in.stop
+		}
+		in.mu.Unlock()
+		if start.IsZero() || t.Before(start) {
+			start = t
+		}
+	}
+	return start
+}
+
+// result is the flux.Result that holds the profile.
+type result struct {
+	profile  *profile
+	canceled <-chan struct{}
+}
+
+func (r *result) Name() string {
+	return ResultName
+}
+
+func (r *result) Tables() flux.TableIterator {
+	return r
+}
+
+// Do waits for every operator to finish and then produces a table
+// with one row per operator.
+func (r *result) Do(f func(flux.Table) error) error {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, op := range r.profile.operators {
+			op.finished.Wait()
+		}
+	}()
+	select {
+	case <-done:
+	case <-r.canceled:
+		return nil
+	}
+
+	tbl, err := r.table()
+	if err != nil {
+		return err
+	}
+	return f(tbl)
+}
+
+func (r *result) table() (flux.Table, error) {
+	// The profile is not charged to the memory quota of the query so
+	// that profiling cannot make the query fail.
+	b := execute.NewColListTableBuilder(execute.NewGroupKey(nil, nil), &memory.Allocator{})
+	cols := []flux.ColMeta{
+		{Label: "operator", Type: flux.TString},
+		{Label: "kind", Type: flux.TString},
+		{Label: "tables", Type: flux.TInt},
+		{Label: "rows", Type: flux.TInt},
+		{Label: "start", Type: flux.TTime},
+		{Label: "stop", Type: flux.TTime},
+		{Label: "duration", Type: flux.TInt},
+	}
+	for _, c := range cols {
+		if _, err := b.AddCol(c); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, op := range r.profile.operators {
+		start := op.startedAt(r.profile.start)
+		op.mu.Lock()
+		tables, rows, stop := op.tables, op.rows, op.stop
+		op.mu.Unlock()
+
+		if err := b.AppendString(0, op.operator); err != nil {
+			return nil, err
+		}
+		if err := b.AppendString(1, op.kind); err != nil {
+			return nil, err
+		}
+		if err := b.AppendInt(2, tables); err != nil {
+			return nil, err
+		}
+		if err := b.AppendInt(3, rows); err != nil {
+			return nil, err
+		}
+		if err := b.AppendTime(4, execute.Time(start.UnixNano())); err != nil {
+			return nil, err
+		}
+		if err := b.AppendTime(5, execute.Time(stop.UnixNano())); err != nil {
+			return nil, err
+		}
+		if err := b.AppendInt(6, int64(stop.Sub(start))); err != nil {
+			return nil, err
+		}
+	}
+	return b.Table()
+}
@@ -0,0 +1,166 @@
+// Package profiler instruments Flux programs so that their results
+// are followed by a table describing how each operator performed.
+package profiler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/influxdb/v2/query"
+	"go.uber.org/zap"
+)
+
+// ResultName is the name of the result that holds the profile of a query.
+const ResultName = "_profiler"
+
+// Instrument returns a program that runs the compiled program and appends
+// the profile of its operators to its results.
+//
+// The compiler must be the one that produced the program. It is used to
+// recover the external Flux file that the compiled program keeps private.
+// Options of the Flux planner package are not honored while profiling.
+func Instrument(prog flux.Program, compiler flux.Compiler) (flux.Program, error) {
+	if pkg, now, ok := query.ProgramAST(prog, compiler); ok {
+		return &program{
+			logger: prog.(*lang.AstProgram).Logger,
+			ast:    pkg,
+			now:    now,
+		}, nil
+	}
+	if p, ok := prog.(*lang.Program); ok {
+		return &program{
+			logger:   p.Logger,
+			planSpec: p.PlanSpec,
+		}, nil
+	}
+	return nil, &flux.Error{
+		Code: codes.Invalid,
+		Msg:  fmt.Sprintf("profiling is not supported for %s queries", compiler.CompilerType()),
+	}
+}
+
+// program is a flux.Program that plans the query itself so that it
+// can instrument the physical plan before it is executed.
+type program struct {
+	logger *zap.Logger
+
+	// Either the AST is evaluated and planned when the program starts
+	// or the plan has already been built by the compiler.
+	ast      *ast.Package
+	now      time.Time
+	planSpec *plan.Spec
+}
+
+// SetLogger sets the logger of the executor.
+func (p *program) SetLogger(logger *zap.Logger) {
+	p.logger = logger
+}
+
+// Start plans and instruments the query, then starts executing it.
+func (p *program) Start(ctx context.Context, alloc *memory.Allocator) (flux.Query, error) {
+	ps := p.planSpec
+	if ps == nil {
+		var err error
+		if ps, err = p.plan(ctx, alloc); err != nil {
+			return nil, err
+		}
+	}
+
+	prof := instrument(ps)
+	prog := &lang.Program{
+		Logger:   p.logger,
+		PlanSpec: ps,
+	}
+	prof.start = time.Now()
+	q, err := prog.Start(ctx, alloc)
+	if err != nil {
+		return nil, err
+	}
+	return newQuery(q, prof), nil
+}
+
+// plan evaluates the AST and builds its physical plan the same way
+// lang.AstProgram does when it is started.
+func (p *program) plan(ctx context.Context, alloc *memory.Allocator) (*plan.Spec, error) {
+	// Evaluation may call functions, such as tableFind, that rely on
+	// the execution dependencies.
+	ctx = lang.ExecutionDependencies{
+		Allocator: alloc,
+		Logger:    p.logger,
+	}.Inject(ctx)
+	spec, err := query.SpecFromAST(ctx, p.ast, p.now)
+	if err != nil {
+		return nil, err
+	}
+	ps, err := plan.PlannerBuilder{}.Build().Plan(ctx, spec)
+	if err != nil {
+		return nil, &flux.Error{
+			Code: codes.Inherit,
+			Msg:  "error in building plan while starting program",
+			Err:  err,
+		}
+	}
+	return ps, nil
+}
+
+// profiledQuery appends the profiler result to the results of the query.
+type profiledQuery struct {
+	flux.Query
+
+	results  chan flux.Result
+	canceled chan struct{}
+	once     sync.Once
+}
+
+func newQuery(q flux.Query, prof *profile) *profiledQuery {
+	wq := &profiledQuery{
+		Query:    q,
+		results:  make(chan flux.Result),
+		canceled: make(chan struct{}),
+	}
+	go wq.forward(&result{
+		profile:  prof,
+		canceled: wq.canceled,
+	})
+	return wq
+}
+
+func (q *profiledQuery) forward(res flux.Result) {
+	defer close(q.results)
+	for r := range q.Query.Results() {
+		select {
+		case q.results <- r:
+		case <-q.canceled:
+			return
+		}
+	}
+	if q.Query.Err() != nil {
+		return
+	}
+	select {
+	case q.results <- res:
+	case <-q.canceled:
+	}
+}
+
+func (q *profiledQuery) Results() <-chan flux.Result {
+	return q.results
+}
+
+func (q *profiledQuery) Cancel() {
+	q.once.Do(func() { close(q.canceled) })
+	q.Query.Cancel()
+}
+
+func (q *profiledQuery) Done() {
+	q.once.Do(func() { close(q.canceled) })
+	q.Query.Done()
+}
@@ -0,0 +1,119 @@
+package profiler_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/dependencies/dependenciestest"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/memory"
+	_ "github.com/influxdata/influxdb/v2/query/builtin"
+	"github.com/influxdata/influxdb/v2/query/influxql"
+	"github.com/influxdata/influxdb/v2/query/profiler"
+)
+
+const script = `
+import "csv"
+
+data = "
+#datatype,string,long,dateTime:RFC3339,double,string,string
+#group,false,false,false,false,true,true
+#default,_result,,,,,
+,result,table,_time,_value,_field,_measurement
+,,0,2020-01-01T00:00:00Z,1.0,usage,cpu
+,,0,2020-01-01T00:00:10Z,2.0,usage,cpu
+,,0,2020-01-01T00:00:20Z,3.0,usage,cpu
+,,1,2020-01-01T00:00:00Z,4.0,free,mem
+"
+
+csv.from(csv: data)
+	|> filter(fn: (r) => r._value > 1.5)
+	|> yield(name: "filtered")
+`
+
+func TestInstrument(t *testing.T) {
+	compiler := lang.FluxCompiler{
+		Now:   time.Date(2020, 1, 1, 0, 1, 0, 0, time.UTC),
+		Query: script,
+	}
+	prog, err := compiler.Compile(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	prog, err = profiler.Instrument(prog, compiler)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := dependenciestest.Default().Inject(context.Background())
+	q, err := prog.Start(ctx, &memory.Allocator{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Done()
+
+	var names []string
+	rows := make(map[string]int)
+	profiles := make(map[string][]int64)
+	for res := range q.Results() {
+		names = append(names, res.Name())
+		if err := res.Tables().Do(func(tbl flux.Table) error {
+			return tbl.Do(func(cr flux.ColReader) error {
+				rows[res.Name()] += cr.Len()
+				if res.Name() != profiler.ResultName {
+					return nil
+				}
+				for i := 0; i < cr.Len(); i++ {
+					kind := cr.Strings(execute.ColIdx("kind", cr.Cols())).ValueString(i)
+					profiles[kind] = []int64{
+						cr.Ints(execute.ColIdx("tables", cr.Cols())).Value(i),
+						cr.Ints(execute.ColIdx("rows", cr.Cols())).Value(i),
+						cr.Ints(execute.ColIdx("duration", cr.Cols())).Value(i),
+					}
+				}
+				return nil
+			})
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := q.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"filtered", profiler.ResultName}; len(names) != 2 || names[0] != want[0] || names[1] != want[1] {
+		t.Fatalf("unexpected results: got %v want %v", names, want)
+	}
+	if got, want := rows["filtered"], 3; got != want {
+		t.Errorf("unexpected rows in the query result: got %d want %d", got, want)
+	}
+	if got, want := rows[profiler.ResultName], 2; got != want {
+		t.Errorf("unexpected operators in the profile: got %d want %d", got, want)
+	}
+	for kind, want := range map[string][]int64{
+		"fromCSV": {2, 4},
+		"filter":  {2, 3},
+	} {
+		got, ok := profiles[kind]
+		if !ok {
+			t.Errorf("expected %s to be profiled", kind)
+			continue
+		}
+		if got[0] != want[0] || got[1] != want[1] {
+			t.Errorf("unexpected %s tables and rows: got %v want %v", kind, got[:2], want)
+		}
+		if got[2] < 0 {
+			t.Errorf("unexpected negative %s duration: %d", kind, got[2])
+		}
+	}
+}
+
+func TestInstrument_Unsupported(t *testing.T) {
+	compiler := &influxql.Compiler{}
+	if _, err := profiler.Instrument(nil, compiler); err == nil {
+		t.Fatal("expected error profiling a program that was not compiled from Flux")
+	}
+}
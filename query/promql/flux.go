@@ -0,0 +1,378 @@
+package promql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+)
+
+// The metrics are stored with the metric name as the measurement, the
+// labels as tags and the samples in the field named ValueField.
+const (
+	// ValueField is the field that holds the samples of the metrics.
+	ValueField = "value"
+	// MetricNameLabel is the label that holds the metric name.
+	MetricNameLabel = "__name__"
+
+	// DefaultLookbackDelta is the longest time before an evaluation
+	// time that a sample is considered the current value of its series.
+	DefaultLookbackDelta = 5 * time.Minute
+)
+
+// Result types of the evaluation of an expression.
+const (
+	ValueTypeVector = "vector"
+	ValueTypeMatrix = "matrix"
+)
+
+// EvalParams are the parameters of the evaluation of an expression.
+type EvalParams struct {
+	// BucketID is the bucket that holds the metrics.
+	BucketID string
+	// Start and End are the first and last evaluation times.
+	// They are equal for instant queries.
+	Start, End time.Time
+	// Step is the time between evaluations of range queries.
+	// It is zero for instant queries.
+	Step time.Duration
+	// LookbackDelta defaults to DefaultLookbackDelta.
+	LookbackDelta time.Duration
+}
+
+// FluxQuery is a Flux query that evaluates a PromQL expression. Each
+// table of its result is a series. The series labels are the string
+// columns of the group key other than those of the Flux data model,
+// with the measurement being the metric name.
+type FluxQuery struct {
+	Query string
+	// ResultType is ValueTypeVector if the result has a single value per
+	// series at the evaluation time, or ValueTypeMatrix if it has a value
+	// per series at each step, or the samples of a range selector.
+	ResultType string
+	// Shift is added to the times of the result to get the evaluation
+	// times of range queries.
+	Shift time.Duration
+}
+
+// Flux builds the Flux query that evaluates the expression.
+//
+// Range queries evaluate the expression at each step from the start time
+// to the end time. A series has a value at a step if it has a sample
+// within the lookback delta before it, which is found with sliding windows.
+func Flux(promql string, p EvalParams) (*FluxQuery, error) {
+	parsed, err := ParsePromQL(promql)
+	if err != nil {
+		return nil, err
+	}
+	if p.LookbackDelta <= 0 {
+		p.LookbackDelta = DefaultLookbackDelta
+	}
+
+	var (
+		sel *Selector
+		agg *AggregateExpr
+	)
+	switch e := parsed.(type) {
+	case *Selector:
+		sel = e
+	case *AggregateExpr:
+		agg, sel = e, e.Selector
+	default:
+		return nil, fmt.Errorf("unsupported expression %q", promql)
+	}
+
+	pred, err := sel.predicate()
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "from(bucketID: %s)\n", fluxString(p.BucketID))
+
+	// Range selectors return the samples of the range before the time of
+	// an instant query.
+	if sel.Range > 0 {
+		if p.Step > 0 || agg != nil {
+			return nil, fmt.Errorf("range vector selector %q must be evaluated by an instant query", promql)
+		}
+		stop := p.End.Add(-sel.Offset)
+		fmt.Fprintf(&b, "\t|> range(start: %s, stop: %s)\n", fluxTime(stop.Add(-sel.Range+1)), fluxTime(stop.Add(1)))
+		fmt.Fprintf(&b, "\t|> filter(fn: %s)\n", pred)
+		return &FluxQuery{Query: b.String(), ResultType: ValueTypeMatrix}, nil
+	}
+
+	q := &FluxQuery{ResultType: ValueTypeVector}
+	start, stop := p.Start.Add(-sel.Offset), p.End.Add(-sel.Offset)
+	fmt.Fprintf(&b, "\t|> range(start: %s, stop: %s)\n", fluxTime(start.Add(-p.LookbackDelta+1)), fluxTime(stop.Add(1)))
+	fmt.Fprintf(&b, "\t|> filter(fn: %s)\n", pred)
+
+	var groupBy []string
+	if p.Step > 0 {
+		// The window of each step is the lookback delta before it, and
+		// includes the step time. The windows that the range clips are
+		// dropped so they are not taken for a step.
+		q.ResultType = ValueTypeMatrix
+		q.Shift = sel.Offset - 1
+		offset := time.Duration(start.Add(1).UnixNano() % int64(p.Step))
+		if offset < 0 {
+			offset += p.Step
+		}
+		fmt.Fprintf(&b, "\t|> window(every: %s, period: %s, offset: %s, createEmpty: false)\n",
+			fluxDuration(p.Step), fluxDuration(p.LookbackDelta), fluxDuration(offset))
+		b.WriteString("\t|> last()\n")
+		fmt.Fprintf(&b, "\t|> filter(fn: (r) => int(v: r._stop) - int(v: r._start) == %d)\n", int64(p.LookbackDelta))
+		b.WriteString("\t|> drop(columns: [\"_time\"])\n")
+		b.WriteString("\t|> duplicate(column: \"_stop\", as: \"_time\")\n")
+		b.WriteString("\t|> window(every: inf)\n")
+		groupBy = []string{"_time"}
+	} else {
+		b.WriteString("\t|> last()\n")
+	}
+
+	if agg != nil {
+		if err := agg.flux(&b, groupBy); err != nil {
+			return nil, err
+		}
+	}
+	q.Query = b.String()
+	return q, nil
+}
+
+// SeriesFlux builds the Flux query that finds the series that match any
+// of the selectors between the start and end times. Each table of its
+// result is a series.
+func SeriesFlux(bucketID string, matches []string, start, end time.Time) (string, error) {
+	pred, err := matchPredicate(matches)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "from(bucketID: %s)\n", fluxString(bucketID))
+	fmt.Fprintf(&b, "\t|> range(start: %s, stop: %s)\n", fluxTime(start), fluxTime(end.Add(1)))
+	fmt.Fprintf(&b, "\t|> filter(fn: %s)\n", pred)
+	b.WriteString("\t|> last()\n")
+	return b.String(), nil
+}
+
+// LabelNamesFlux builds the Flux query that finds the names of the labels
+// of the series that match any of the selectors between the start and end
+// times. Its result is a table with the tag keys in the _value column.
+func LabelNamesFlux(bucketID string, matches []string, start, end time.Time) (string, error) {
+	pred, err := matchPredicate(matches)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "from(bucketID: %s)\n", fluxString(bucketID))
+	fmt.Fprintf(&b, "\t|> range(start: %s, stop: %s)\n", fluxTime(start), fluxTime(end.Add(1)))
+	fmt.Fprintf(&b, "\t|> filter(fn: %s)\n", pred)
+	b.WriteString("\t|> keys()\n")
+	b.WriteString("\t|> keep(columns: [\"_value\"])\n")
+	b.WriteString("\t|> distinct()\n")
+	return b.String(), nil
+}
+
+// LabelValuesFlux builds the Flux query that finds the values of a label
+// of the series that match any of the selectors between the start and end
+// times. Its result is a table with the values in the _value column.
+func LabelValuesFlux(bucketID, name string, matches []string, start, end time.Time) (string, error) {
+	pred, err := matchPredicate(matches)
+	if err != nil {
+		return "", err
+	}
+	tag := fluxString(tagKey(name))
+	var b strings.Builder
+	fmt.Fprintf(&b, "from(bucketID: %s)\n", fluxString(bucketID))
+	fmt.Fprintf(&b, "\t|> range(start: %s, stop: %s)\n", fluxTime(start), fluxTime(end.Add(1)))
+	fmt.Fprintf(&b, "\t|> filter(fn: %s)\n", pred)
+	fmt.Fprintf(&b, "\t|> keep(columns: [%s])\n", tag)
+	b.WriteString("\t|> group()\n")
+	fmt.Fprintf(&b, "\t|> distinct(column: %s)\n", tag)
+	return b.String(), nil
+}
+
+// flux appends the aggregation to the query. The series are grouped by
+// the extra columns as well as the labels of the aggregation.
+func (a *AggregateExpr) flux(b *strings.Builder, extra []string) error {
+	var labels []string
+	if a.Aggregate != nil {
+		for _, l := range a.Aggregate.Labels {
+			labels = append(labels, tagKey(l.Name))
+		}
+	}
+
+	switch {
+	case a.Aggregate != nil && a.Aggregate.Without:
+		// The metric name is dropped along with the labels.
+		except := append(labels, "_measurement", "_field", "_value", "_start", "_stop")
+		if len(extra) == 0 {
+			except = append(except, "_time")
+		}
+		fmt.Fprintf(b, "\t|> group(columns: %s, mode: \"except\")\n", fluxStrings(except))
+	default:
+		fmt.Fprintf(b, "\t|> group(columns: %s)\n", fluxStrings(append(labels, extra...)))
+	}
+
+	switch a.Op.Kind {
+	case SumKind:
+		b.WriteString("\t|> sum()\n")
+	case CountKind:
+		b.WriteString("\t|> count()\n")
+	case AvgKind:
+		b.WriteString("\t|> mean()\n")
+	case MinKind:
+		b.WriteString("\t|> min()\n")
+	case MaxKind:
+		b.WriteString("\t|> max()\n")
+	case StdevKind:
+		b.WriteString("\t|> stddev(mode: \"population\")\n")
+	case StdVarKind:
+		b.WriteString("\t|> stddev(mode: \"population\")\n")
+		b.WriteString("\t|> map(fn: (r) => ({r with _value: r._value * r._value}))\n")
+	case TopKind, BottomKind:
+		n, ok := a.Op.Arg.(*Number)
+		if !ok || n.Val < 1 || n.Val != float64(int64(n.Val)) {
+			return fmt.Errorf("invalid number of series for topk or bottomk")
+		}
+		fn := "top"
+		if a.Op.Kind == BottomKind {
+			fn = "bottom"
+		}
+		fmt.Fprintf(b, "\t|> %s(n: %d)\n", fn, int64(n.Val))
+		// The series keep all of their labels.
+		fmt.Fprintf(b, "\t|> group(columns: %s, mode: \"except\")\n", fluxStrings([]string{"_field", "_value", "_start", "_stop", "_time"}))
+		return nil
+	default:
+		return fmt.Errorf("unsupported aggregation operator %d", a.Op.Kind)
+	}
+
+	if len(extra) > 0 {
+		// Regroup the values of each step into series.
+		if a.Aggregate != nil && a.Aggregate.Without {
+			except := append(labels, "_measurement", "_field", "_value", "_start", "_stop", "_time")
+			fmt.Fprintf(b, "\t|> group(columns: %s, mode: \"except\")\n", fluxStrings(except))
+		} else {
+			fmt.Fprintf(b, "\t|> group(columns: %s)\n", fluxStrings(labels))
+		}
+	}
+	return nil
+}
+
+// predicate returns the Flux function that matches the series of the selector.
+func (s *Selector) predicate() (string, error) {
+	conds := []string{
+		"r._measurement == " + fluxString(s.Name),
+		"r._field == " + fluxString(ValueField),
+	}
+	for _, m := range s.LabelMatchers {
+		cond, err := m.condition()
+		if err != nil {
+			return "", err
+		}
+		conds = append(conds, cond)
+	}
+	return "(r) => " + strings.Join(conds, " and "), nil
+}
+
+// condition returns the Flux expression that evaluates the matcher. Series
+// without the label match if the matcher matches the empty string.
+func (m *LabelMatcher) condition() (string, error) {
+	var value string
+	switch v := m.Value.(type) {
+	case *StringLiteral:
+		value = v.String
+	case *Number:
+		value = strconv.FormatFloat(v.Val, 'f', -1, 64)
+	default:
+		return "", fmt.Errorf("invalid value for label %s", m.Name)
+	}
+
+	ref := fmt.Sprintf("r[%s]", fluxString(tagKey(m.Name)))
+	var cond string
+	var matchesEmpty bool
+	switch m.Kind {
+	case Equal:
+		cond = ref + " == " + fluxString(value)
+		matchesEmpty = value == ""
+	case NotEqual:
+		cond = ref + " != " + fluxString(value)
+		matchesEmpty = value != ""
+	case RegexMatch, RegexNoMatch:
+		// Prometheus regular expressions are fully anchored.
+		re, err := regexp.Compile("^(?:" + value + ")$")
+		if err != nil {
+			return "", err
+		}
+		lit := ast.Format(&ast.RegexpLiteral{Value: re})
+		if m.Kind == RegexMatch {
+			cond = ref + " =~ " + lit
+			matchesEmpty = re.MatchString("")
+		} else {
+			cond = ref + " !~ " + lit
+			matchesEmpty = !re.MatchString("")
+		}
+	default:
+		return "", fmt.Errorf("unknown label match kind %d", m.Kind)
+	}
+	if matchesEmpty && m.Name != MetricNameLabel {
+		cond = fmt.Sprintf("(not exists %s or %s)", ref, cond)
+	}
+	return cond, nil
+}
+
+// matchPredicate returns the Flux function that matches the series of
+// any of the selectors.
+func matchPredicate(matches []string) (string, error) {
+	if len(matches) == 0 {
+		return fmt.Sprintf("(r) => r._field == %s", fluxString(ValueField)), nil
+	}
+	conds := make([]string, 0, len(matches))
+	for _, match := range matches {
+		parsed, err := ParsePromQL(match)
+		if err != nil {
+			return "", err
+		}
+		sel, ok := parsed.(*Selector)
+		if !ok || sel.Range > 0 {
+			return "", fmt.Errorf("invalid series selector %q", match)
+		}
+		pred, err := sel.predicate()
+		if err != nil {
+			return "", err
+		}
+		conds = append(conds, "("+strings.TrimPrefix(pred, "(r) => ")+")")
+	}
+	return "(r) => " + strings.Join(conds, " or "), nil
+}
+
+// tagKey returns the tag key of a label.
+func tagKey(label string) string {
+	if label == MetricNameLabel {
+		return "_measurement"
+	}
+	return label
+}
+
+func fluxString(s string) string {
+	return ast.Format(&ast.StringLiteral{Value: s})
+}
+
+func fluxStrings(ss []string) string {
+	quoted := make([]string, len(ss))
+	for i, s := range ss {
+		quoted[i] = fluxString(s)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+func fluxTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func fluxDuration(d time.Duration) string {
+	return strconv.FormatInt(int64(d), 10) + "ns"
+}
@@ -0,0 +1,169 @@
+package promql
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	fluxparser "github.com/influxdata/flux/parser"
+)
+
+func checkFlux(t *testing.T, q string) {
+	t.Helper()
+	pkg := fluxparser.ParseSource(q)
+	if ast.Check(pkg) > 0 {
+		t.Fatalf("invalid Flux query: %v\n%s", ast.GetError(pkg), q)
+	}
+}
+
+func TestFlux(t *testing.T) {
+	end := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	instant := EvalParams{BucketID: "0000000000000001", Start: end, End: end}
+	ranged := EvalParams{BucketID: "0000000000000001", Start: end.Add(-time.Hour), End: end, Step: time.Minute}
+
+	tests := []struct {
+		name       string
+		promql     string
+		params     EvalParams
+		resultType string
+		contains   []string
+		wantErr    bool
+	}{
+		{
+			name:       "instant selector",
+			promql:     `http_requests_total{method="GET",code=~"2.."}`,
+			params:     instant,
+			resultType: ValueTypeVector,
+			contains: []string{
+				`range(start: 2020-01-01T23:55:00.000000001Z, stop: 2020-01-02T00:00:00.000000001Z)`,
+				`r._measurement == "http_requests_total"`,
+				`r._field == "value"`,
+				`r["method"] == "GET"`,
+				`r["code"] =~ /^(?:2..)$/`,
+				`|> last()`,
+			},
+		},
+		{
+			name:       "empty matcher",
+			promql:     `up{job=""}`,
+			params:     instant,
+			resultType: ValueTypeVector,
+			contains:   []string{`not exists r["job"]`},
+		},
+		{
+			name:       "range selector",
+			promql:     `up[10m] offset 1h`,
+			params:     instant,
+			resultType: ValueTypeMatrix,
+			contains: []string{
+				`range(start: 2020-01-01T22:50:00.000000001Z, stop: 2020-01-01T23:00:00.000000001Z)`,
+			},
+		},
+		{
+			name:       "range query",
+			promql:     `up`,
+			params:     ranged,
+			resultType: ValueTypeMatrix,
+			contains: []string{
+				`window(every: 60000000000ns, period: 300000000000ns, offset: 1ns, createEmpty: false)`,
+				`duplicate(column: "_stop", as: "_time")`,
+			},
+		},
+		{
+			name:       "aggregation",
+			promql:     `sum by (job) (up)`,
+			params:     ranged,
+			resultType: ValueTypeMatrix,
+			contains: []string{
+				`group(columns: ["job", "_time"])`,
+				`sum()`,
+			},
+		},
+		{
+			name:       "aggregation without",
+			promql:     `avg without (instance) (up)`,
+			params:     instant,
+			resultType: ValueTypeVector,
+			contains:   []string{`mean()`},
+		},
+		{
+			name:       "topk",
+			promql:     `topk(3, up)`,
+			params:     instant,
+			resultType: ValueTypeVector,
+			contains:   []string{`top(n: 3)`},
+		},
+		{
+			name:    "range selector in range query",
+			promql:  `up[5m]`,
+			params:  ranged,
+			wantErr: true,
+		},
+		{
+			name:    "unsupported aggregation",
+			promql:  `quantile(0.9, up)`,
+			params:  instant,
+			wantErr: true,
+		},
+		{
+			name:    "syntax error",
+			promql:  `up{`,
+			params:  instant,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Flux(tt.promql, tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Flux() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			checkFlux(t, q.Query)
+			if q.ResultType != tt.resultType {
+				t.Errorf("unexpected result type: got %q want %q", q.ResultType, tt.resultType)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(q.Query, s) {
+					t.Errorf("expected query to contain %q:\n%s", s, q.Query)
+				}
+			}
+		})
+	}
+}
+
+func TestMetadataFlux(t *testing.T) {
+	start, end := time.Unix(0, 0), time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	matches := []string{`up{job="prometheus"}`, `http_requests_total{code=~"5.."}`}
+
+	q, err := SeriesFlux("0000000000000001", matches, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkFlux(t, q)
+	if !strings.Contains(q, ` or `) {
+		t.Errorf("expected the series to match either selector:\n%s", q)
+	}
+
+	q, err = LabelNamesFlux("0000000000000001", nil, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkFlux(t, q)
+
+	q, err = LabelValuesFlux("0000000000000001", "job", matches, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkFlux(t, q)
+	if !strings.Contains(q, `distinct(column: "job")`) {
+		t.Errorf("expected the distinct values of the label:\n%s", q)
+	}
+
+	if _, err := SeriesFlux("0000000000000001", []string{`up{`}, start, end); err == nil {
+		t.Error("expected an error for an invalid selector")
+	}
+}
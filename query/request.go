@@ -33,6 +33,14 @@ type Request struct {
 	// Source represents the ultimate source of the request.
 	Source string `json:"source"`
 
+	// Profile requests that the results are followed by the profile
+	// of the operators of the query.
+	Profile bool `json:"profile,omitempty"`
+
+	// NoCache requests that the query is performed even if its
+	// results are cached.
+	NoCache bool `json:"noCache,omitempty"`
+
 	// compilerMappings maps compiler types to creation methods
 	compilerMappings flux.CompilerMappings
 
@@ -2,10 +2,15 @@ package query
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/interpreter"
 	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/values"
 	platform "github.com/influxdata/influxdb/v2"
 )
 
@@ -47,3 +52,123 @@ func BucketsAccessed(ast *ast.Package, orgID *platform.ID) (readBuckets, writeBu
 
 	return readBuckets, writeBuckets, nil
 }
+
+// ProgramAST returns the Flux package that a program evaluates when it is
+// started and the time it evaluates it at. The compiler must be the one that
+// compiled the program, since the program keeps the external file of a
+// lang.FluxCompiler private. It reports false if the program does not
+// evaluate Flux when it is started.
+func ProgramAST(prog flux.Program, compiler flux.Compiler) (*ast.Package, time.Time, bool) {
+	p, ok := prog.(*lang.AstProgram)
+	if !ok {
+		return nil, time.Time{}, false
+	}
+
+	var extern *ast.File
+	switch c := compiler.(type) {
+	case lang.FluxCompiler:
+		extern = c.Extern
+	case *lang.FluxCompiler:
+		extern = c.Extern
+	}
+
+	pkg := p.Ast
+	if extern != nil {
+		pkg = pkg.Copy().(*ast.Package)
+		pkg.Files = append([]*ast.File{extern}, pkg.Files...)
+	}
+	return pkg, p.Now, true
+}
+
+// SpecFromAST evaluates the Flux package and returns the specification of
+// the query it describes, the same way a lang.AstProgram does when it is
+// started. If now is zero, the current time is used.
+func SpecFromAST(ctx context.Context, pkg *ast.Package, now time.Time) (*flux.Spec, error) {
+	if now.IsZero() {
+		now = time.Now()
+	}
+	sideEffects, scope, err := flux.EvalAST(ctx, pkg, flux.SetNowOption(now))
+	if err != nil {
+		return nil, err
+	}
+
+	nowOpt, ok := scope.Lookup(flux.NowOption)
+	if !ok {
+		return nil, fmt.Errorf("%q option not set", flux.NowOption)
+	}
+	nowTime, err := nowOpt.Function().Call(ctx, nil)
+	if err != nil {
+		return nil, &flux.Error{
+			Code: codes.Inherit,
+			Msg:  "error in evaluating AST while starting program",
+			Err:  err,
+		}
+	}
+
+	spec, err := specFromEvaluation(sideEffects, nowTime.Time().Time())
+	if err != nil {
+		return nil, &flux.Error{
+			Code: codes.Inherit,
+			Msg:  "error in query specification while starting program",
+			Err:  err,
+		}
+	}
+	return spec, nil
+}
+
+// specFromEvaluation builds the query specification from the table
+// objects produced by evaluating a script. Flux keeps its own version
+// of this function internal, so this mirrors it.
+func specFromEvaluation(sideEffects []interpreter.SideEffect, now time.Time) (*flux.Spec, error) {
+	b := &specBuilder{
+		spec:    &flux.Spec{Now: now},
+		ids:     make(map[*flux.TableObject]flux.OperationID),
+		visited: make(map[*flux.TableObject]bool),
+	}
+	for _, se := range sideEffects {
+		if to, ok := se.Value.(*flux.TableObject); ok && !b.visited[to] {
+			b.build(to)
+		}
+	}
+	if len(b.spec.Operations) == 0 {
+		return nil, fmt.Errorf("this Flux script returns no streaming data. " +
+			"Consider adding a \"yield\" or invoking streaming functions directly, without performing an assignment")
+	}
+	return b.spec, nil
+}
+
+type specBuilder struct {
+	spec    *flux.Spec
+	ids     map[*flux.TableObject]flux.OperationID
+	visited map[*flux.TableObject]bool
+}
+
+// ID implements flux.IDer.
+func (b *specBuilder) ID(t *flux.TableObject) flux.OperationID {
+	id, ok := b.ids[t]
+	if !ok {
+		id = flux.OperationID(fmt.Sprintf("%s%d", t.Kind, len(b.ids)))
+		b.ids[t] = id
+	}
+	return id
+}
+
+// build adds the table object to the spec after all of its ancestors.
+func (b *specBuilder) build(t *flux.TableObject) {
+	t.Parents.Range(func(i int, v values.Value) {
+		if p := v.(*flux.TableObject); !b.visited[p] {
+			b.build(p)
+		}
+	})
+
+	id := b.ID(t)
+	t.Parents.Range(func(i int, v values.Value) {
+		b.spec.Edges = append(b.spec.Edges, flux.Edge{
+			Parent: b.ID(v.(*flux.TableObject)),
+			Child:  id,
+		})
+	})
+
+	b.visited[t] = true
+	b.spec.Operations = append(b.spec.Operations, t.Operation(b))
+}
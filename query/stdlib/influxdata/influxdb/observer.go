@@ -0,0 +1,28 @@
+package influxdb
+
+import (
+	"context"
+
+	"github.com/influxdata/flux/execute"
+	platform "github.com/influxdata/influxdb/v2"
+)
+
+// ReadObserver is notified of the buckets that the storage sources
+// of a query read and the time range they read from each.
+type ReadObserver interface {
+	BucketRead(orgID, bucketID platform.ID, bounds execute.Bounds)
+}
+
+// ContextWithReadObserver returns a context that makes the storage
+// sources created with it notify the observer of what they read.
+func ContextWithReadObserver(ctx context.Context, obs ReadObserver) context.Context {
+	return context.WithValue(ctx, readObserverKey, obs)
+}
+
+// ObserveRead notifies the read observer of the context, if any, that
+// a source reads the time range of the bucket.
+func ObserveRead(ctx context.Context, orgID, bucketID platform.ID, bounds execute.Bounds) {
+	if obs, ok := ctx.Value(readObserverKey).(ReadObserver); ok {
+		obs.BucketRead(orgID, bucketID, bounds)
+	}
+}
@@ -2,6 +2,7 @@ package influxdb
 
 import (
 	"context"
+	"math"
 
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/ast"
@@ -9,17 +10,27 @@ import (
 	"github.com/influxdata/flux/plan"
 	"github.com/influxdata/flux/semantic"
 	"github.com/influxdata/flux/stdlib/universe"
+	"github.com/influxdata/influxdb/v2/kit/feature"
 )
 
 func init() {
-	plan.RegisterPhysicalRules(
+	plan.RegisterPhysicalRules(PhysicalRules()...)
+}
+
+// PhysicalRules returns the physical planner rules that push
+// operations down to storage.
+func PhysicalRules() []plan.Rule {
+	return []plan.Rule{
 		PushDownRangeRule{},
 		PushDownFilterRule{},
 		PushDownGroupRule{},
 		PushDownReadTagKeysRule{},
 		PushDownReadTagValuesRule{},
 		SortedPivotRule{},
-	)
+		PushDownWindowAggregateRule{},
+		PushDownBareAggregateRule{},
+		PushDownGroupAggregateRule{},
+	}
 }
 
 // PushDownGroupRule pushes down a group operation to storage
@@ -597,3 +608,201 @@ func (SortedPivotRule) Rewrite(ctx context.Context, pn plan.Node) (plan.Node, bo
 	}
 	return pn, false, nil
 }
+
+// PushDownWindowAggregateRule rewrites 'ReadRange |> window |> agg' into
+// 'ReadWindowAggregate' when the storage layer can compute agg over each
+// window. Since aggregateWindow expands to window and the aggregate, it is
+// pushed down as well.
+type PushDownWindowAggregateRule struct{}
+
+func (PushDownWindowAggregateRule) Name() string {
+	return "PushDownWindowAggregateRule"
+}
+
+var windowPushableAggs = []plan.ProcedureKind{
+	universe.CountKind,
+	universe.SumKind,
+	universe.MinKind,
+	universe.MaxKind,
+	universe.MeanKind,
+	universe.FirstKind,
+	universe.LastKind,
+}
+
+func (rule PushDownWindowAggregateRule) Pattern() plan.Pattern {
+	return plan.OneOf(windowPushableAggs,
+		plan.Pat(universe.WindowKind,
+			plan.Pat(ReadRangePhysKind)))
+}
+
+func (PushDownWindowAggregateRule) Rewrite(ctx context.Context, pn plan.Node) (plan.Node, bool, error) {
+	if !feature.PushDownWindowAggregate().Enabled(ctx) {
+		return pn, false, nil
+	}
+
+	windowNode := pn.Predecessors()[0]
+	windowSpec := windowNode.ProcedureSpec().(*universe.WindowProcedureSpec)
+	fromNode := windowNode.Predecessors()[0]
+	fromSpec := fromNode.ProcedureSpec().(*ReadRangePhysSpec)
+
+	// The window and read may not be consumed by anything else.
+	if len(windowNode.Successors()) != 1 || len(fromNode.Successors()) != 1 {
+		return pn, false, nil
+	}
+
+	if !aggregatesValue(pn) {
+		return pn, false, nil
+	}
+
+	// Storage computes tumbling windows aligned to the epoch, with the
+	// default time columns.
+	window := windowSpec.Window
+	if !window.Every.Equal(window.Period) ||
+		!window.Every.IsPositive() ||
+		window.Every.Months() != 0 ||
+		window.Every.Nanoseconds() == math.MaxInt64 ||
+		!window.Offset.IsZero() {
+		return pn, false, nil
+	}
+	if windowSpec.TimeColumn != execute.DefaultTimeColLabel ||
+		windowSpec.StartColumn != execute.DefaultStartColLabel ||
+		windowSpec.StopColumn != execute.DefaultStopColLabel {
+		return pn, false, nil
+	}
+
+	capability := &WindowAggregateCapability{
+		Aggregates:  []string{string(pn.Kind())},
+		WindowEvery: window.Every.Nanoseconds(),
+	}
+	if !hasWindowAggregateCapability(ctx, capability) {
+		return pn, false, nil
+	}
+
+	return plan.CreatePhysicalNode("ReadWindowAggregate", &ReadWindowAggregatePhysSpec{
+		ReadRangePhysSpec: *fromSpec.Copy().(*ReadRangePhysSpec),
+		WindowEvery:       capability.WindowEvery,
+		Aggregates:        capability.Aggregates,
+		CreateEmpty:       windowSpec.CreateEmpty,
+	}), true, nil
+}
+
+// hasWindowAggregateCapability returns true if the storage reader of the
+// query can compute the windowed aggregates of capability.
+func hasWindowAggregateCapability(ctx context.Context, capability *WindowAggregateCapability) bool {
+	deps, ok := ctx.Value(dependenciesKey).(StorageDependencies)
+	if !ok {
+		return false
+	}
+	reader, ok := deps.FromDeps.Reader.(WindowAggregateReader)
+	return ok && reader.HasWindowAggregateCapability(ctx, capability)
+}
+
+// aggregatesValue returns true if the aggregate or selector of pn reads the
+// _value column.
+func aggregatesValue(pn plan.Node) bool {
+	switch spec := pn.ProcedureSpec().(type) {
+	case *universe.CountProcedureSpec:
+		return isValueColumns(spec.Columns)
+	case *universe.SumProcedureSpec:
+		return isValueColumns(spec.Columns)
+	case *universe.MeanProcedureSpec:
+		return isValueColumns(spec.Columns)
+	case *universe.MinProcedureSpec:
+		return spec.Column == execute.DefaultValueColLabel
+	case *universe.MaxProcedureSpec:
+		return spec.Column == execute.DefaultValueColLabel
+	case *universe.FirstProcedureSpec:
+		return spec.Column == execute.DefaultValueColLabel
+	case *universe.LastProcedureSpec:
+		return spec.Column == execute.DefaultValueColLabel
+	default:
+		return false
+	}
+}
+
+func isValueColumns(columns []string) bool {
+	return len(columns) == 1 && columns[0] == execute.DefaultValueColLabel
+}
+
+// PushDownBareAggregateRule rewrites 'ReadRange |> agg' into a
+// 'ReadWindowAggregate' with a single window spanning the bounds of the read.
+type PushDownBareAggregateRule struct{}
+
+func (PushDownBareAggregateRule) Name() string {
+	return "PushDownBareAggregateRule"
+}
+
+func (PushDownBareAggregateRule) Pattern() plan.Pattern {
+	return plan.OneOf(windowPushableAggs, plan.Pat(ReadRangePhysKind))
+}
+
+func (PushDownBareAggregateRule) Rewrite(ctx context.Context, pn plan.Node) (plan.Node, bool, error) {
+	if !feature.PushDownGroupAggregate().Enabled(ctx) {
+		return pn, false, nil
+	}
+
+	fromNode := pn.Predecessors()[0]
+	fromSpec := fromNode.ProcedureSpec().(*ReadRangePhysSpec)
+	if len(fromNode.Successors()) != 1 {
+		return pn, false, nil
+	}
+
+	capability := &WindowAggregateCapability{
+		Aggregates:  []string{string(pn.Kind())},
+		WindowEvery: math.MaxInt64,
+	}
+	if !aggregatesValue(pn) || !hasWindowAggregateCapability(ctx, capability) {
+		return pn, false, nil
+	}
+
+	return plan.CreatePhysicalNode("ReadWindowAggregate", &ReadWindowAggregatePhysSpec{
+		ReadRangePhysSpec: *fromSpec.Copy().(*ReadRangePhysSpec),
+		WindowEvery:       capability.WindowEvery,
+		Aggregates:        capability.Aggregates,
+	}), true, nil
+}
+
+// PushDownGroupAggregateRule rewrites 'ReadGroup |> agg' into a 'ReadGroup'
+// that computes the aggregate of each group in storage.
+type PushDownGroupAggregateRule struct{}
+
+func (PushDownGroupAggregateRule) Name() string {
+	return "PushDownGroupAggregateRule"
+}
+
+// groupPushableAggs are the aggregates storage can combine across the series of
+// a group. The mean of a group cannot be computed from the mean of its series,
+// and first and last select the first and last rows of a group, which depend
+// on the order its series are read in rather than on time.
+var groupPushableAggs = []plan.ProcedureKind{
+	universe.CountKind,
+	universe.SumKind,
+	universe.MinKind,
+	universe.MaxKind,
+}
+
+func (PushDownGroupAggregateRule) Pattern() plan.Pattern {
+	return plan.OneOf(groupPushableAggs, plan.Pat(ReadGroupPhysKind))
+}
+
+func (PushDownGroupAggregateRule) Rewrite(ctx context.Context, pn plan.Node) (plan.Node, bool, error) {
+	if !feature.PushDownGroupAggregate().Enabled(ctx) {
+		return pn, false, nil
+	}
+
+	groupNode := pn.Predecessors()[0]
+	groupSpec := groupNode.ProcedureSpec().(*ReadGroupPhysSpec)
+	if len(groupNode.Successors()) != 1 ||
+		groupSpec.GroupMode != flux.GroupModeBy ||
+		groupSpec.AggregateMethod != "" {
+		return pn, false, nil
+	}
+
+	if !aggregatesValue(pn) {
+		return pn, false, nil
+	}
+
+	newGroupSpec := groupSpec.Copy().(*ReadGroupPhysSpec)
+	newGroupSpec.AggregateMethod = string(pn.Kind())
+	return plan.CreatePhysicalNode("ReadGroupAggregate", newGroupSpec), true, nil
+}
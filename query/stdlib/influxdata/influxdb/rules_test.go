@@ -1,17 +1,25 @@
 package influxdb_test
 
 import (
+	"context"
+	"fmt"
+	"math"
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/ast"
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/memory"
 	"github.com/influxdata/flux/plan"
 	"github.com/influxdata/flux/plan/plantest"
 	"github.com/influxdata/flux/semantic"
 	"github.com/influxdata/flux/stdlib/universe"
+	"github.com/influxdata/flux/values"
+	"github.com/influxdata/influxdb/v2/kit/feature"
+	"github.com/influxdata/influxdb/v2/kit/feature/override"
 	"github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb"
 )
 
@@ -1284,3 +1292,400 @@ func TestReadTagValuesRule(t *testing.T) {
 		})
 	}
 }
+
+type mockWindowAggregateReader struct {
+	mockReader
+	capable bool
+}
+
+func (r mockWindowAggregateReader) HasWindowAggregateCapability(ctx context.Context, capability ...*influxdb.WindowAggregateCapability) bool {
+	return r.capable
+}
+
+func (mockWindowAggregateReader) ReadWindowAggregate(ctx context.Context, spec influxdb.ReadWindowAggregateSpec, alloc *memory.Allocator) (influxdb.TableIterator, error) {
+	return &mockTableIterator{}, nil
+}
+
+// pushDownContext returns the context of a query with flag set to enabled,
+// reading from storage that has the window aggregate capability if capable.
+func pushDownContext(t *testing.T, flag feature.Flag, enabled, capable bool) context.Context {
+	t.Helper()
+
+	flagger, err := override.Make(map[string]string{
+		flag.Key(): fmt.Sprint(enabled),
+	}, feature.ByKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, err := feature.Annotate(context.Background(), flagger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deps := influxdb.StorageDependencies{
+		FromDeps: influxdb.FromDependencies{
+			Reader: mockWindowAggregateReader{capable: capable},
+		},
+	}
+	return deps.Inject(ctx)
+}
+
+func TestPushDownWindowAggregateRule(t *testing.T) {
+	readRange := influxdb.ReadRangePhysSpec{
+		Bucket: "my-bucket",
+		Bounds: flux.Bounds{
+			Start: fluxTime(5),
+			Stop:  fluxTime(10),
+		},
+	}
+
+	window := func(every, period time.Duration) *universe.WindowProcedureSpec {
+		return &universe.WindowProcedureSpec{
+			Window: plan.WindowSpec{
+				Every:  values.ConvertDuration(every),
+				Period: values.ConvertDuration(period),
+			},
+			TimeColumn:  execute.DefaultTimeColLabel,
+			StartColumn: execute.DefaultStartColLabel,
+			StopColumn:  execute.DefaultStopColLabel,
+		}
+	}
+	windowCreateEmpty := window(time.Minute, time.Minute)
+	windowCreateEmpty.CreateEmpty = true
+
+	readWindowAggregate := func(agg string, createEmpty bool) *influxdb.ReadWindowAggregatePhysSpec {
+		return &influxdb.ReadWindowAggregatePhysSpec{
+			ReadRangePhysSpec: readRange,
+			WindowEvery:       int64(time.Minute),
+			Aggregates:        []string{agg},
+			CreateEmpty:       createEmpty,
+		}
+	}
+
+	simple := func(agg plan.PhysicalProcedureSpec, w *universe.WindowProcedureSpec) *plantest.PlanSpec {
+		return &plantest.PlanSpec{
+			Nodes: []plan.Node{
+				plan.CreatePhysicalNode("ReadRange", &readRange),
+				plan.CreatePhysicalNode("window", w),
+				plan.CreatePhysicalNode("agg", agg),
+			},
+			Edges: [][2]int{{0, 1}, {1, 2}},
+		}
+	}
+	pushed := func(spec *influxdb.ReadWindowAggregatePhysSpec) *plantest.PlanSpec {
+		return &plantest.PlanSpec{
+			Nodes: []plan.Node{
+				plan.CreatePhysicalNode("ReadWindowAggregate", spec),
+			},
+		}
+	}
+
+	valueAgg := execute.AggregateConfig{Columns: []string{execute.DefaultValueColLabel}}
+	valueSel := execute.SelectorConfig{Column: execute.DefaultValueColLabel}
+
+	multipleSuccessors := func() *plantest.PlanSpec {
+		return &plantest.PlanSpec{
+			Nodes: []plan.Node{
+				plan.CreatePhysicalNode("ReadRange", &readRange),
+				plan.CreatePhysicalNode("window", window(time.Minute, time.Minute)),
+				plan.CreatePhysicalNode("count", &universe.CountProcedureSpec{AggregateConfig: valueAgg}),
+				plan.CreatePhysicalNode("min", &universe.MinProcedureSpec{SelectorConfig: valueSel}),
+			},
+			Edges: [][2]int{{0, 1}, {1, 2}, {1, 3}},
+		}
+	}
+
+	tests := []struct {
+		name             string
+		enabled, capable bool
+		tc               plantest.RuleTestCase
+	}{
+		{
+			name:    "count",
+			enabled: true,
+			capable: true,
+			tc: plantest.RuleTestCase{
+				Before: simple(&universe.CountProcedureSpec{AggregateConfig: valueAgg}, window(time.Minute, time.Minute)),
+				After:  pushed(readWindowAggregate("count", false)),
+			},
+		},
+		{
+			name:    "mean create empty",
+			enabled: true,
+			capable: true,
+			tc: plantest.RuleTestCase{
+				Before: simple(&universe.MeanProcedureSpec{AggregateConfig: valueAgg}, windowCreateEmpty),
+				After:  pushed(readWindowAggregate("mean", true)),
+			},
+		},
+		{
+			name:    "last",
+			enabled: true,
+			capable: true,
+			tc: plantest.RuleTestCase{
+				Before: simple(&universe.LastProcedureSpec{SelectorConfig: valueSel}, window(time.Minute, time.Minute)),
+				After:  pushed(readWindowAggregate("last", false)),
+			},
+		},
+		{
+			name:    "flag disabled",
+			capable: true,
+			tc: plantest.RuleTestCase{
+				Before: simple(&universe.CountProcedureSpec{AggregateConfig: valueAgg}, window(time.Minute, time.Minute)),
+				After:  simple(&universe.CountProcedureSpec{AggregateConfig: valueAgg}, window(time.Minute, time.Minute)),
+			},
+		},
+		{
+			name:    "reader not capable",
+			enabled: true,
+			tc: plantest.RuleTestCase{
+				Before: simple(&universe.CountProcedureSpec{AggregateConfig: valueAgg}, window(time.Minute, time.Minute)),
+				After:  simple(&universe.CountProcedureSpec{AggregateConfig: valueAgg}, window(time.Minute, time.Minute)),
+			},
+		},
+		{
+			name:    "overlapping windows",
+			enabled: true,
+			capable: true,
+			tc: plantest.RuleTestCase{
+				Before: simple(&universe.SumProcedureSpec{AggregateConfig: valueAgg}, window(time.Minute, 2*time.Minute)),
+				After:  simple(&universe.SumProcedureSpec{AggregateConfig: valueAgg}, window(time.Minute, 2*time.Minute)),
+			},
+		},
+		{
+			name:    "other column",
+			enabled: true,
+			capable: true,
+			tc: plantest.RuleTestCase{
+				Before: simple(&universe.MaxProcedureSpec{SelectorConfig: execute.SelectorConfig{Column: "other"}}, window(time.Minute, time.Minute)),
+				After:  simple(&universe.MaxProcedureSpec{SelectorConfig: execute.SelectorConfig{Column: "other"}}, window(time.Minute, time.Minute)),
+			},
+		},
+		{
+			name:    "window with multiple successors",
+			enabled: true,
+			capable: true,
+			tc: plantest.RuleTestCase{
+				Before: multipleSuccessors(),
+				After:  multipleSuccessors(),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tt.tc.Rules = []plan.Rule{influxdb.PushDownWindowAggregateRule{}}
+			physicalRuleTestHelper(t, pushDownContext(t, feature.PushDownWindowAggregate(), tt.enabled, tt.capable), &tt.tc)
+		})
+	}
+}
+
+func TestPushDownBareAggregateRule(t *testing.T) {
+	readRange := influxdb.ReadRangePhysSpec{
+		Bucket: "my-bucket",
+		Bounds: flux.Bounds{
+			Start: fluxTime(5),
+			Stop:  fluxTime(10),
+		},
+	}
+	valueAgg := execute.AggregateConfig{Columns: []string{execute.DefaultValueColLabel}}
+
+	simple := func(agg plan.PhysicalProcedureSpec) *plantest.PlanSpec {
+		return &plantest.PlanSpec{
+			Nodes: []plan.Node{
+				plan.CreatePhysicalNode("ReadRange", &readRange),
+				plan.CreatePhysicalNode("agg", agg),
+			},
+			Edges: [][2]int{{0, 1}},
+		}
+	}
+
+	tests := []struct {
+		name             string
+		enabled, capable bool
+		tc               plantest.RuleTestCase
+	}{
+		{
+			name:    "sum",
+			enabled: true,
+			capable: true,
+			tc: plantest.RuleTestCase{
+				Before: simple(&universe.SumProcedureSpec{AggregateConfig: valueAgg}),
+				After: &plantest.PlanSpec{
+					Nodes: []plan.Node{
+						plan.CreatePhysicalNode("ReadWindowAggregate", &influxdb.ReadWindowAggregatePhysSpec{
+							ReadRangePhysSpec: readRange,
+							WindowEvery:       math.MaxInt64,
+							Aggregates:        []string{"sum"},
+						}),
+					},
+				},
+			},
+		},
+		{
+			name:    "flag disabled",
+			capable: true,
+			tc: plantest.RuleTestCase{
+				Before: simple(&universe.SumProcedureSpec{AggregateConfig: valueAgg}),
+				After:  simple(&universe.SumProcedureSpec{AggregateConfig: valueAgg}),
+			},
+		},
+		{
+			name:    "reader not capable",
+			enabled: true,
+			tc: plantest.RuleTestCase{
+				Before: simple(&universe.SumProcedureSpec{AggregateConfig: valueAgg}),
+				After:  simple(&universe.SumProcedureSpec{AggregateConfig: valueAgg}),
+			},
+		},
+		{
+			name:    "other column",
+			enabled: true,
+			capable: true,
+			tc: plantest.RuleTestCase{
+				Before: simple(&universe.FirstProcedureSpec{SelectorConfig: execute.SelectorConfig{Column: "other"}}),
+				After:  simple(&universe.FirstProcedureSpec{SelectorConfig: execute.SelectorConfig{Column: "other"}}),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tt.tc.Rules = []plan.Rule{influxdb.PushDownBareAggregateRule{}}
+			physicalRuleTestHelper(t, pushDownContext(t, feature.PushDownGroupAggregate(), tt.enabled, tt.capable), &tt.tc)
+		})
+	}
+}
+
+func TestPushDownGroupAggregateRule(t *testing.T) {
+	readGroup := func(mode flux.GroupMode, aggregateMethod string) *influxdb.ReadGroupPhysSpec {
+		return &influxdb.ReadGroupPhysSpec{
+			ReadRangePhysSpec: influxdb.ReadRangePhysSpec{
+				Bucket: "my-bucket",
+				Bounds: flux.Bounds{
+					Start: fluxTime(5),
+					Stop:  fluxTime(10),
+				},
+			},
+			GroupMode:       mode,
+			GroupKeys:       []string{"host"},
+			AggregateMethod: aggregateMethod,
+		}
+	}
+	valueSel := execute.SelectorConfig{Column: execute.DefaultValueColLabel}
+
+	simple := func(group *influxdb.ReadGroupPhysSpec, agg plan.PhysicalProcedureSpec) *plantest.PlanSpec {
+		return &plantest.PlanSpec{
+			Nodes: []plan.Node{
+				plan.CreatePhysicalNode("ReadGroup", group),
+				plan.CreatePhysicalNode("agg", agg),
+			},
+			Edges: [][2]int{{0, 1}},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		enabled bool
+		tc      plantest.RuleTestCase
+	}{
+		{
+			name:    "max",
+			enabled: true,
+			tc: plantest.RuleTestCase{
+				Before: simple(readGroup(flux.GroupModeBy, ""), &universe.MaxProcedureSpec{SelectorConfig: valueSel}),
+				After: &plantest.PlanSpec{
+					Nodes: []plan.Node{
+						plan.CreatePhysicalNode("ReadGroupAggregate", readGroup(flux.GroupModeBy, "max")),
+					},
+				},
+			},
+		},
+		{
+			name: "flag disabled",
+			tc: plantest.RuleTestCase{
+				Before: simple(readGroup(flux.GroupModeBy, ""), &universe.MaxProcedureSpec{SelectorConfig: valueSel}),
+				After:  simple(readGroup(flux.GroupModeBy, ""), &universe.MaxProcedureSpec{SelectorConfig: valueSel}),
+			},
+		},
+		{
+			name:    "already aggregated",
+			enabled: true,
+			tc: plantest.RuleTestCase{
+				Before: simple(readGroup(flux.GroupModeBy, "count"), &universe.MaxProcedureSpec{SelectorConfig: valueSel}),
+				After:  simple(readGroup(flux.GroupModeBy, "count"), &universe.MaxProcedureSpec{SelectorConfig: valueSel}),
+			},
+		},
+		{
+			name:    "last",
+			enabled: true,
+			tc: plantest.RuleTestCase{
+				Before: simple(readGroup(flux.GroupModeBy, ""), &universe.LastProcedureSpec{SelectorConfig: valueSel}),
+				After:  simple(readGroup(flux.GroupModeBy, ""), &universe.LastProcedureSpec{SelectorConfig: valueSel}),
+			},
+		},
+		{
+			name:    "mean",
+			enabled: true,
+			tc: plantest.RuleTestCase{
+				Before: simple(readGroup(flux.GroupModeBy, ""), &universe.MeanProcedureSpec{}),
+				After:  simple(readGroup(flux.GroupModeBy, ""), &universe.MeanProcedureSpec{}),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tt.tc.Rules = []plan.Rule{influxdb.PushDownGroupAggregateRule{}}
+			physicalRuleTestHelper(t, pushDownContext(t, feature.PushDownGroupAggregate(), tt.enabled, true), &tt.tc)
+		})
+	}
+}
+
+// physicalRuleTestHelper is plantest.PhysicalRuleTestHelper planning with ctx,
+// for rules that depend on the flags and dependencies of the query. Since
+// copying a window spec drops its columns, cases without a change must build
+// the same plan for After rather than set NoChange.
+func physicalRuleTestHelper(t *testing.T, ctx context.Context, tc *plantest.RuleTestCase) {
+	t.Helper()
+
+	before := plantest.CreatePlanSpec(tc.Before)
+	after := plantest.CreatePlanSpec(tc.After)
+
+	physicalPlanner := plan.NewPhysicalPlanner(
+		plan.OnlyPhysicalRules(tc.Rules...),
+		plan.DisableValidation(),
+	)
+	pp, err := physicalPlanner.Plan(ctx, before)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type testAttrs struct {
+		ID   plan.NodeID
+		Spec plan.PhysicalProcedureSpec
+	}
+	walk := func(spec *plan.Spec) []testAttrs {
+		attrs := make([]testAttrs, 0)
+		_ = spec.BottomUpWalk(func(node plan.Node) error {
+			attrs = append(attrs, testAttrs{
+				ID:   node.ID(),
+				Spec: node.ProcedureSpec().(plan.PhysicalProcedureSpec),
+			})
+			return nil
+		})
+		return attrs
+	}
+
+	want, got := walk(after), walk(pp)
+	if !cmp.Equal(want, got, plantest.CmpOptions...) {
+		t.Errorf("transformed plan not as expected, -want/+got:\n%v",
+			cmp.Diff(want, got, plantest.CmpOptions...))
+	}
+}
@@ -186,6 +186,8 @@ func createReadFilterSource(s plan.ProcedureSpec, id execute.DatasetID, a execut
 	if spec.FilterSet {
 		filter = spec.Filter
 	}
+
+	ObserveRead(a.Context(), orgID, bucketID, *bounds)
 	return ReadFilterSource(
 		id,
 		deps.Reader,
@@ -263,6 +265,8 @@ func createReadGroupSource(s plan.ProcedureSpec, id execute.DatasetID, a execute
 	if spec.FilterSet {
 		filter = spec.Filter
 	}
+
+	ObserveRead(a.Context(), orgID, bucketID, *bounds)
 	return ReadGroupSource(
 		id,
 		deps.Reader,
@@ -352,6 +356,8 @@ func createReadWindowAggregateSource(s plan.ProcedureSpec, id execute.DatasetID,
 	if spec.FilterSet {
 		filter = spec.Filter
 	}
+
+	ObserveRead(a.Context(), orgID, bucketID, *bounds)
 	return ReadWindowAggregateSource(
 		id,
 		reader,
@@ -364,6 +370,7 @@ func createReadWindowAggregateSource(s plan.ProcedureSpec, id execute.DatasetID,
 			},
 			WindowEvery: spec.WindowEvery,
 			Aggregates:  spec.Aggregates,
+			CreateEmpty: spec.CreateEmpty,
 		},
 		a,
 	), nil
@@ -392,6 +399,7 @@ func createReadTagKeysSource(prSpec plan.ProcedureSpec, dsid execute.DatasetID,
 	}
 
 	bounds := a.StreamContext().Bounds()
+	ObserveRead(a.Context(), orgID, bucketID, *bounds)
 	return ReadTagKeysSource(
 		dsid,
 		deps.Reader,
@@ -461,6 +469,7 @@ func createReadTagValuesSource(prSpec plan.ProcedureSpec, dsid execute.DatasetID
 	}
 
 	bounds := a.StreamContext().Bounds()
+	ObserveRead(a.Context(), orgID, bucketID, *bounds)
 	return ReadTagValuesSource(
 		dsid,
 		deps.Reader,
@@ -148,16 +148,22 @@ type ReadWindowAggregateSpec struct {
 	ReadFilterSpec
 	WindowEvery int64
 	Aggregates  []string
+	CreateEmpty bool
 }
 
-// WindowAggregateCapability describes what is supported by WindowAggregateReader.
-type WindowAggregateCapability struct{}
+// WindowAggregateCapability describes a window aggregate read, whose
+// support is checked by WindowAggregateReader. It has the aggregates and
+// window of a ReadWindowAggregateSpec.
+type WindowAggregateCapability struct {
+	Aggregates  []string
+	WindowEvery int64
+}
 
 // WindowAggregateReader implements the WindowAggregate capability.
 type WindowAggregateReader interface {
 	// HasWindowAggregateCapability will test if this Reader source supports the ReadWindowAggregate capability.
-	// If WindowAggregateCapability is passed to the method, then the struct
-	// is filled with a detailed list of what the RPC call supports.
+	// If WindowAggregateCapability is passed to the method, then the Reader
+	// checks that it supports the described read.
 	HasWindowAggregateCapability(ctx context.Context, capability ...*WindowAggregateCapability) bool
 
 	// ReadWindowAggregate will read a table using the WindowAggregate method.
@@ -0,0 +1,33 @@
+package influxdb
+
+// RateLimits are the rates at which a token, an organization or a client
+// may write and query. A zero rate is not limited.
+type RateLimits struct {
+	// WriteBytesPerSecond is the number of line protocol bytes that may
+	// be written every second.
+	WriteBytesPerSecond int64 `json:"writeBytesPerSecond,omitempty"`
+
+	// WritePointsPerSecond is the number of points that may be written
+	// every second.
+	WritePointsPerSecond int64 `json:"writePointsPerSecond,omitempty"`
+
+	// QueriesPerSecond is the number of queries that may be made every
+	// second.
+	QueriesPerSecond int64 `json:"queriesPerSecond,omitempty"`
+}
+
+// Valid returns an error if any of the rates is negative.
+func (l RateLimits) Valid() error {
+	if l.WriteBytesPerSecond < 0 || l.WritePointsPerSecond < 0 || l.QueriesPerSecond < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "rate limits must not be negative",
+		}
+	}
+	return nil
+}
+
+// Unlimited returns true if none of the rates is limited.
+func (l RateLimits) Unlimited() bool {
+	return l == RateLimits{}
+}
@@ -0,0 +1,309 @@
+// Package ratelimit limits the rates at which tokens, organizations and
+// clients write and query.
+//
+// Every rate is a token bucket that holds up to one second of its rate.
+// Requests larger than a bucket are allowed once it is full and leave it in
+// debt, so that the client waits for them to be paid back instead.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Scope is who a rate limit applies to.
+type Scope string
+
+// The scopes of rate limits.
+const (
+	ScopeToken Scope = "token"
+	ScopeOrg   Scope = "org"
+	ScopeIP    Scope = "ip"
+)
+
+// idleTimeout is how long buckets are kept once they are full again.
+const idleTimeout = time.Minute
+
+// rate is one of the limited rates of RateLimits.
+type rate struct {
+	op    string
+	unit  string
+	limit func(influxdb.RateLimits) int64
+}
+
+var (
+	writeBytes = &rate{
+		op:    "write",
+		unit:  "bytes",
+		limit: func(l influxdb.RateLimits) int64 { return l.WriteBytesPerSecond },
+	}
+	writePoints = &rate{
+		op:    "write",
+		unit:  "points",
+		limit: func(l influxdb.RateLimits) int64 { return l.WritePointsPerSecond },
+	}
+	queries = &rate{
+		op:    "query",
+		unit:  "queries",
+		limit: func(l influxdb.RateLimits) int64 { return l.QueriesPerSecond },
+	}
+)
+
+// Error is returned for requests that exceed a rate limit.
+type Error struct {
+	Scope Scope
+	Op    string
+	Unit  string
+	Limit int64
+
+	// RetryAfter is how long the client has to wait before the
+	// request is allowed.
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s %s rate limit of %d %s per second exceeded", e.Scope, e.Op, e.Limit, e.Unit)
+}
+
+type key struct {
+	scope Scope
+	id    string
+	rate  *rate
+}
+
+// bucket is a token bucket of a rate.
+type bucket struct {
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+// refill adds the tokens of the rate since the bucket was last refilled.
+func (b *bucket) refill(now time.Time) {
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.rate {
+		b.tokens = b.rate
+	}
+	b.last = now
+}
+
+// wait returns how long until the bucket has the tokens of a request of n.
+func (b *bucket) wait(n int64) time.Duration {
+	need := math.Min(float64(n), b.rate)
+	if b.tokens >= need {
+		return 0
+	}
+	return time.Duration(math.Ceil((need - b.tokens) / b.rate * float64(time.Second)))
+}
+
+// scoped are the rate limits of a scope.
+type scoped struct {
+	scope  Scope
+	id     string
+	limits influxdb.RateLimits
+}
+
+// draw is an amount a request draws from a rate.
+type draw struct {
+	rate *rate
+	n    int64
+}
+
+// Limiter limits the rates of the tokens, organizations and clients that
+// write and query. The limits of tokens are those of their authorizations,
+// the limits of organizations are found with an OrgLimitsService and the
+// limits of clients are the same for every IP.
+type Limiter struct {
+	orgs influxdb.OrgLimitsService
+	ip   influxdb.RateLimits
+	now  func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[key]*bucket
+	lastSweep time.Time
+
+	usage    *prometheus.CounterVec
+	rejected *prometheus.CounterVec
+}
+
+// NewLimiter returns a Limiter that finds the limits of organizations with
+// orgs and limits every IP to ip.
+func NewLimiter(orgs influxdb.OrgLimitsService, ip influxdb.RateLimits) *Limiter {
+	const (
+		namespace = "http"
+		subsystem = "ratelimit"
+	)
+
+	return &Limiter{
+		orgs:    orgs,
+		ip:      ip,
+		now:     time.Now,
+		buckets: make(map[key]*bucket),
+		usage: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "usage",
+			Help:      "Usage of the requests allowed by the rate limits",
+		}, []string{"org_id", "type"}),
+		rejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "rejected_count",
+			Help:      "Total number of requests rejected by the rate limits",
+		}, []string{"org_id", "scope", "op", "unit"}),
+	}
+}
+
+// PrometheusCollectors exposes the usage and rejected requests of the limiter.
+func (l *Limiter) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		l.usage,
+		l.rejected,
+	}
+}
+
+// AllowWrite returns an *Error if a write of points and bytes to the
+// organization from ip exceeds any of the write limits. Allowed writes are
+// drawn from the limits.
+func (l *Limiter) AllowWrite(ctx context.Context, orgID influxdb.ID, ip string, bytes, points int) error {
+	if err := l.allow(ctx, orgID, ip, draw{writeBytes, int64(bytes)}, draw{writePoints, int64(points)}); err != nil {
+		return err
+	}
+
+	org := orgID.String()
+	l.usage.WithLabelValues(org, string(influxdb.UsageWriteRequestCount)).Inc()
+	l.usage.WithLabelValues(org, string(influxdb.UsageWriteRequestBytes)).Add(float64(bytes))
+	return nil
+}
+
+// AllowQuery returns an *Error if a query of bytes to the organization from
+// ip exceeds any of the query limits. Allowed queries are drawn from the
+// limits.
+func (l *Limiter) AllowQuery(ctx context.Context, orgID influxdb.ID, ip string, bytes int) error {
+	if err := l.allow(ctx, orgID, ip, draw{queries, 1}); err != nil {
+		return err
+	}
+
+	org := orgID.String()
+	l.usage.WithLabelValues(org, string(influxdb.UsageQueryRequestCount)).Inc()
+	l.usage.WithLabelValues(org, string(influxdb.UsageQueryRequestBytes)).Add(float64(bytes))
+	return nil
+}
+
+func (l *Limiter) allow(ctx context.Context, orgID influxdb.ID, ip string, draws ...draw) error {
+	scopes, err := l.scopes(ctx, orgID, ip)
+	if err != nil {
+		return err
+	}
+
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	// A request is only drawn from its buckets once it is allowed by
+	// every one of them.
+	var (
+		exceeded *Error
+		drawn    []*bucket
+		amounts  []int64
+	)
+	for _, s := range scopes {
+		for _, d := range draws {
+			limit := d.rate.limit(s.limits)
+			if limit <= 0 {
+				continue
+			}
+
+			b := l.bucket(key{scope: s.scope, id: s.id, rate: d.rate}, limit, now)
+			if wait := b.wait(d.n); wait > 0 {
+				if exceeded == nil || wait > exceeded.RetryAfter {
+					exceeded = &Error{
+						Scope:      s.scope,
+						Op:         d.rate.op,
+						Unit:       d.rate.unit,
+						Limit:      limit,
+						RetryAfter: wait,
+					}
+				}
+				continue
+			}
+			drawn = append(drawn, b)
+			amounts = append(amounts, d.n)
+		}
+	}
+
+	if exceeded != nil {
+		l.rejected.WithLabelValues(orgID.String(), string(exceeded.Scope), exceeded.Op, exceeded.Unit).Inc()
+		return exceeded
+	}
+
+	for i, b := range drawn {
+		b.tokens -= float64(amounts[i])
+	}
+	return nil
+}
+
+// scopes returns the rate limits of the token on ctx, the organization and
+// ip.
+func (l *Limiter) scopes(ctx context.Context, orgID influxdb.ID, ip string) ([]scoped, error) {
+	var scopes []scoped
+
+	if a, err := icontext.GetAuthorizer(ctx); err == nil {
+		if auth, ok := a.(*influxdb.Authorization); ok && auth.RateLimits != nil {
+			scopes = append(scopes, scoped{scope: ScopeToken, id: auth.ID.String(), limits: *auth.RateLimits})
+		}
+	}
+
+	if l.orgs != nil {
+		lim, err := l.orgs.FindOrgLimits(ctx, orgID)
+		if err != nil {
+			return nil, err
+		}
+		scopes = append(scopes, scoped{scope: ScopeOrg, id: orgID.String(), limits: lim.RateLimits})
+	}
+
+	if ip != "" {
+		scopes = append(scopes, scoped{scope: ScopeIP, id: ip, limits: l.ip})
+	}
+
+	return scopes, nil
+}
+
+// bucket returns the refilled bucket of k. The bucket of a new rate starts
+// full, and the rate of a bucket follows changes of its limit.
+func (l *Limiter) bucket(k key, limit int64, now time.Time) *bucket {
+	b, ok := l.buckets[k]
+	if !ok {
+		b = &bucket{tokens: float64(limit), last: now}
+		l.buckets[k] = b
+	}
+	b.rate = float64(limit)
+	b.refill(now)
+	return b
+}
+
+// sweep drops the buckets that have been full for a while, as they are the
+// same as new ones.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < idleTimeout {
+		return
+	}
+	l.lastSweep = now
+
+	for k, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*b.rate >= b.rate && now.Sub(b.last) > idleTimeout {
+			delete(l.buckets, k)
+		}
+	}
+}
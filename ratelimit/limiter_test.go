@@ -0,0 +1,129 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/mock"
+)
+
+func newTestLimiter(org, ip influxdb.RateLimits) (*Limiter, *time.Time) {
+	orgs := mock.NewOrgLimitsService()
+	orgs.FindOrgLimitsFn = func(_ context.Context, orgID influxdb.ID) (*influxdb.OrgLimits, error) {
+		return &influxdb.OrgLimits{OrgID: orgID, RateLimits: org}, nil
+	}
+
+	now := time.Unix(0, 0)
+	l := NewLimiter(orgs, ip)
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func expectExceeded(t *testing.T, err error, scope Scope, retryAfter time.Duration) {
+	t.Helper()
+	e, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("expected a rate limit error, got %v", err)
+	}
+	if e.Scope != scope {
+		t.Errorf("expected the %s limit to be exceeded, got %s", scope, e.Scope)
+	}
+	if e.RetryAfter < retryAfter || e.RetryAfter > retryAfter+time.Millisecond {
+		t.Errorf("expected to retry after %s, got %s", retryAfter, e.RetryAfter)
+	}
+}
+
+func TestLimiter_AllowWrite(t *testing.T) {
+	ctx := context.Background()
+	orgID := influxdb.ID(1)
+
+	l, now := newTestLimiter(influxdb.RateLimits{WriteBytesPerSecond: 100}, influxdb.RateLimits{})
+
+	// Writes larger than a second of the rate are allowed, and pay for
+	// it before the next one.
+	if err := l.AllowWrite(ctx, orgID, "", 300, 1); err != nil {
+		t.Fatal(err)
+	}
+	err := l.AllowWrite(ctx, orgID, "", 1, 1)
+	expectExceeded(t, err, ScopeOrg, 2010*time.Millisecond)
+	if got, want := err.Error(), "org write rate limit of 100 bytes per second exceeded"; got != want {
+		t.Errorf("got error %q want %q", got, want)
+	}
+
+	*now = now.Add(2 * time.Second)
+	expectExceeded(t, l.AllowWrite(ctx, orgID, "", 1, 1), ScopeOrg, 10*time.Millisecond)
+
+	*now = now.Add(10 * time.Millisecond)
+	if err := l.AllowWrite(ctx, orgID, "", 1, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// Other organizations are limited on their own.
+	if err := l.AllowWrite(ctx, influxdb.ID(2), "", 1, 1); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLimiter_scopes(t *testing.T) {
+	orgID := influxdb.ID(1)
+	auth := &influxdb.Authorization{
+		ID:         influxdb.ID(10),
+		OrgID:      orgID,
+		RateLimits: &influxdb.RateLimits{WritePointsPerSecond: 10},
+	}
+	ctx := icontext.SetAuthorizer(context.Background(), auth)
+
+	l, now := newTestLimiter(influxdb.RateLimits{WritePointsPerSecond: 100}, influxdb.RateLimits{QueriesPerSecond: 1})
+
+	if err := l.AllowWrite(ctx, orgID, "10.0.0.1", 1000, 20); err != nil {
+		t.Fatal(err)
+	}
+	expectExceeded(t, l.AllowWrite(ctx, orgID, "10.0.0.1", 1000, 1), ScopeToken, 1100*time.Millisecond)
+
+	// The rejected write is not drawn from the org, which still allows
+	// the points of other tokens.
+	if err := l.AllowWrite(context.Background(), orgID, "10.0.0.1", 1000, 80); err != nil {
+		t.Fatal(err)
+	}
+	expectExceeded(t, l.AllowWrite(context.Background(), orgID, "10.0.0.1", 1000, 1), ScopeOrg, 10*time.Millisecond)
+
+	if err := l.AllowQuery(ctx, orgID, "10.0.0.1", 100); err != nil {
+		t.Fatal(err)
+	}
+	expectExceeded(t, l.AllowQuery(ctx, orgID, "10.0.0.1", 100), ScopeIP, time.Second)
+	if err := l.AllowQuery(ctx, orgID, "10.0.0.2", 100); err != nil {
+		t.Fatal(err)
+	}
+
+	// Updated limits apply to the existing buckets.
+	auth.RateLimits = &influxdb.RateLimits{}
+	*now = now.Add(2 * time.Second)
+	if err := l.AllowWrite(ctx, orgID, "10.0.0.1", 1000, 100); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLimiter_sweep(t *testing.T) {
+	ctx := context.Background()
+	l, now := newTestLimiter(influxdb.RateLimits{}, influxdb.RateLimits{QueriesPerSecond: 1})
+
+	for _, ip := range []string{"10.0.0.1", "10.0.0.2"} {
+		if err := l.AllowQuery(ctx, influxdb.ID(1), ip, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := len(l.buckets); got != 2 {
+		t.Fatalf("expected 2 buckets, got %d", got)
+	}
+
+	*now = now.Add(2 * idleTimeout)
+	if err := l.AllowQuery(ctx, influxdb.ID(1), "", 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(l.buckets); got != 0 {
+		t.Fatalf("expected the idle buckets to be dropped, got %d buckets", got)
+	}
+}
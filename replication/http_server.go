@@ -0,0 +1,293 @@
+package replication
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/storage/wal"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixReplication = "/api/v2/replication"
+
+	// DefaultPollInterval is how often the streams check for new changes.
+	DefaultPollInterval = 100 * time.Millisecond
+
+	// kvBatchSize is the number of change log entries read at once.
+	kvBatchSize = 1000
+)
+
+// WALSource is the storage engine whose WAL is streamed to replicas.
+type WALSource interface {
+	TailWAL(pos wal.Position, fn func(wal.WALEntry, wal.Position) error) (wal.Position, error)
+}
+
+// WALMessage is a WAL entry sent on the WAL stream.
+type WALMessage struct {
+	// Position follows the entry in the WAL of the primary.
+	Position wal.Position     `json:"position"`
+	Type     wal.WalEntryType `json:"type"`
+	Data     []byte           `json:"data"`
+}
+
+// Status describes the replication role of a server.
+type Status struct {
+	Role       string       `json:"role"`
+	Primary    string       `json:"primary,omitempty"`
+	WAL        wal.Position `json:"wal"`
+	KVSequence uint64       `json:"kvSequence"`
+	LastApply  time.Time    `json:"lastApply,omitempty"`
+	Error      string       `json:"error,omitempty"`
+}
+
+// Handler serves the replication streams of a primary and the status and
+// promotion of a replica.
+type Handler struct {
+	chi.Router
+	api *kithttp.API
+	log *zap.Logger
+
+	walSource WALSource
+	kvStore   kv.Store
+	replica   *Replica
+
+	// PollInterval is how often the streams check for new changes.
+	PollInterval time.Duration
+}
+
+// NewHTTPHandler constructs a new replication http server. The WAL of
+// walSource and the change log of kvStore are streamed to replicas, if not
+// nil. The replica, if not nil, is the replica this server is.
+func NewHTTPHandler(log *zap.Logger, walSource WALSource, kvStore kv.Store, replica *Replica) *Handler {
+	h := &Handler{
+		api:          kithttp.NewAPI(kithttp.WithLog(log)),
+		log:          log,
+		walSource:    walSource,
+		kvStore:      kvStore,
+		replica:      replica,
+		PollInterval: DefaultPollInterval,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+		h.mwOperator,
+	)
+
+	r.Route("/", func(r chi.Router) {
+		r.Get("/status", h.handleGetStatus)
+		r.Post("/promote", h.handlePostPromote)
+		r.Get("/wal", h.handleGetWAL)
+		r.Get("/kv", h.handleGetKV)
+	})
+
+	h.Router = r
+	return h
+}
+
+// Prefix provides the route prefix.
+func (h *Handler) Prefix() string {
+	return prefixReplication
+}
+
+// mwOperator restricts replication to authorizers with operator permissions,
+// as the streams hold the data of every organization.
+func (h *Handler) mwOperator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := authorizer.IsAllowedAll(r.Context(), influxdb.OperPermissions()); err != nil {
+			h.api.Err(w, &influxdb.Error{
+				Code: influxdb.EUnauthorized,
+				Msg:  "replication requires operator permissions",
+				Err:  err,
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleGetStatus is the HTTP handler for the GET /api/v2/replication/status route.
+func (h *Handler) handleGetStatus(w http.ResponseWriter, r *http.Request) {
+	status := Status{Role: "primary"}
+	if h.replica != nil {
+		status = h.replica.Status()
+	}
+	h.api.Respond(w, http.StatusOK, status)
+}
+
+// handlePostPromote is the HTTP handler for the POST /api/v2/replication/promote route.
+func (h *Handler) handlePostPromote(w http.ResponseWriter, r *http.Request) {
+	if h.replica == nil {
+		h.api.Err(w, &influxdb.Error{
+			Code: influxdb.EConflict,
+			Msg:  "server is not a replica",
+		})
+		return
+	}
+
+	if err := h.replica.Promote(); err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	h.api.Respond(w, http.StatusOK, h.replica.Status())
+}
+
+// handleGetWAL is the HTTP handler for the GET /api/v2/replication/wal route.
+// It streams the WAL entries following the segment and offset parameters
+// until the client disconnects.
+func (h *Handler) handleGetWAL(w http.ResponseWriter, r *http.Request) {
+	if h.walSource == nil {
+		h.api.Err(w, errReplicationDisabled)
+		return
+	}
+
+	var pos wal.Position
+	qp := r.URL.Query()
+	if s := qp.Get("segment"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			h.api.Err(w, &influxdb.Error{Code: influxdb.EInvalid, Msg: "invalid segment", Err: err})
+			return
+		}
+		pos.Segment = n
+	}
+	if s := qp.Get("offset"); s != "" {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			h.api.Err(w, &influxdb.Error{Code: influxdb.EInvalid, Msg: "invalid offset", Err: err})
+			return
+		}
+		pos.Offset = n
+	}
+
+	h.stream(w, r, func(enc *json.Encoder) error {
+		var err error
+		pos, err = h.walSource.TailWAL(pos, func(entry wal.WALEntry, next wal.Position) error {
+			data, err := entry.MarshalBinary()
+			if err != nil {
+				return err
+			}
+			return enc.Encode(WALMessage{Position: next, Type: entry.Type(), Data: data})
+		})
+		switch err {
+		case wal.ErrPositionUnavailable:
+			return &influxdb.Error{Code: influxdb.ENotFound, Msg: err.Error()}
+		case wal.ErrWALCorrupt:
+			return &influxdb.Error{
+				Code: influxdb.EInternal,
+				Msg:  fmt.Sprintf("WAL of the primary is corrupt at segment %d offset %d", pos.Segment, pos.Offset),
+				Err:  err,
+			}
+		}
+		return err
+	})
+}
+
+// handleGetKV is the HTTP handler for the GET /api/v2/replication/kv route.
+// It streams the KV change log entries following the after parameter until
+// the client disconnects.
+func (h *Handler) handleGetKV(w http.ResponseWriter, r *http.Request) {
+	if h.kvStore == nil {
+		h.api.Err(w, errReplicationDisabled)
+		return
+	}
+
+	var after uint64
+	if s := r.URL.Query().Get("after"); s != "" {
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			h.api.Err(w, &influxdb.Error{Code: influxdb.EInvalid, Msg: "invalid after", Err: err})
+			return
+		}
+		after = n
+	}
+
+	ctx := r.Context()
+	h.stream(w, r, func(enc *json.Encoder) error {
+		err := ReadKVLog(ctx, h.kvStore, after, kvBatchSize, func(entry KVLogEntry) error {
+			if err := enc.Encode(entry); err != nil {
+				return err
+			}
+			after = entry.Sequence
+			return nil
+		})
+		if err == ErrKVPositionUnavailable {
+			return &influxdb.Error{Code: influxdb.ENotFound, Msg: err.Error()}
+		}
+		return err
+	})
+}
+
+var errReplicationDisabled = &influxdb.Error{
+	Code: influxdb.ENotFound,
+	Msg:  "replication is not enabled",
+}
+
+// stream calls next every poll interval, flushing what it encoded to w, until
+// the client disconnects or next fails. An error before anything was sent is
+// returned to the client.
+func (h *Handler) stream(w http.ResponseWriter, r *http.Request, next func(*json.Encoder) error) {
+	ctx := r.Context()
+	sw := &streamWriter{ResponseWriter: w}
+	enc := json.NewEncoder(sw)
+
+	t := time.NewTicker(h.PollInterval)
+	defer t.Stop()
+
+	for {
+		if err := next(enc); err != nil {
+			if !sw.started {
+				h.api.Err(w, err)
+			} else if ctx.Err() == nil {
+				h.log.Info("Replication stream stopped", zap.Error(err))
+			}
+			return
+		}
+		sw.Flush()
+
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// streamWriter writes the response header of a stream on its first write.
+type streamWriter struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (w *streamWriter) Write(b []byte) (int, error) {
+	w.start()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *streamWriter) start() {
+	if !w.started {
+		w.started = true
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// Flush sends the entries written so far, starting the stream if needed.
+func (w *streamWriter) Flush() {
+	w.start()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,246 @@
+package replication
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+
+	"github.com/influxdata/influxdb/v2/kv"
+)
+
+// DefaultKVLogSize is the number of update transactions kept in the KV change
+// log for replicas to catch up with.
+const DefaultKVLogSize = 100000
+
+var kvLogBucket = []byte("replicationkvlogv1")
+
+// ErrKVPositionUnavailable is returned when reading the KV change log after a
+// sequence whose following changes were already trimmed from the log.
+var ErrKVPositionUnavailable = errors.New("KV change log position is no longer available: the following changes were trimmed; resync the replica from a backup of the primary")
+
+// KVChange is a put or delete of a key made by an update transaction.
+type KVChange struct {
+	Bucket []byte `json:"bucket"`
+	Key    []byte `json:"key"`
+	Value  []byte `json:"value,omitempty"`
+	Delete bool   `json:"delete,omitempty"`
+}
+
+// KVLogEntry holds the changes made by an update transaction, in order.
+type KVLogEntry struct {
+	Sequence uint64     `json:"sequence"`
+	Changes  []KVChange `json:"changes"`
+}
+
+var _ kv.AutoMigrationStore = (*LoggingStore)(nil)
+
+// LoggingStore is a kv.Store that records the changes made by each update
+// transaction in a change log stored alongside the data, in the same
+// transaction. Replicas follow the log to apply the same changes.
+type LoggingStore struct {
+	kv.Store
+
+	// Size is the number of entries kept in the log.
+	Size uint64
+}
+
+// NewLoggingStore returns a store recording the changes made to s.
+func NewLoggingStore(s kv.Store) *LoggingStore {
+	return &LoggingStore{Store: s, Size: DefaultKVLogSize}
+}
+
+// Initialize creates the bucket of the change log.
+func (s *LoggingStore) Initialize(ctx context.Context) error {
+	return s.Store.Update(ctx, func(tx kv.Tx) error {
+		_, err := tx.Bucket(kvLogBucket)
+		return err
+	})
+}
+
+// AutoMigrate returns the store the migrations of the wrapped store are
+// applied to automatically, if any. Those changes are not logged.
+func (s *LoggingStore) AutoMigrate() kv.Store {
+	if store, ok := s.Store.(kv.AutoMigrationStore); ok {
+		return store.AutoMigrate()
+	}
+	return nil
+}
+
+// Update runs fn in an update transaction and appends the changes it made to
+// the change log.
+func (s *LoggingStore) Update(ctx context.Context, fn func(kv.Tx) error) error {
+	return s.Store.Update(ctx, func(tx kv.Tx) error {
+		ltx := &loggingTx{Tx: tx}
+		if err := fn(ltx); err != nil {
+			return err
+		}
+		if len(ltx.changes) == 0 {
+			return nil
+		}
+		return s.append(tx, ltx.changes)
+	})
+}
+
+func (s *LoggingStore) append(tx kv.Tx, changes []KVChange) error {
+	b, err := tx.Bucket(kvLogBucket)
+	if err != nil {
+		return err
+	}
+
+	seq, err := lastSequence(b)
+	if err != nil {
+		return err
+	}
+	seq++
+
+	v, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	if err := b.Put(encodeSequence(seq), v); err != nil {
+		return err
+	}
+
+	if s.Size > 0 && seq > s.Size {
+		return b.Delete(encodeSequence(seq - s.Size))
+	}
+	return nil
+}
+
+// ReadKVLog calls fn with each entry of the change log of store following the
+// entry with sequence after, up to limit entries. ErrKVPositionUnavailable is
+// returned if entries following after were trimmed from the log, including
+// when reading from the start of a log whose first entries were trimmed.
+func ReadKVLog(ctx context.Context, store kv.Store, after uint64, limit int, fn func(KVLogEntry) error) error {
+	return store.View(ctx, func(tx kv.Tx) error {
+		b, err := tx.Bucket(kvLogBucket)
+		if err != nil {
+			return err
+		}
+
+		cur, err := b.ForwardCursor(encodeSequence(after))
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		n := 0
+		for k, v := cur.Next(); k != nil && n < limit; k, v = cur.Next() {
+			seq := binary.BigEndian.Uint64(k)
+			if seq <= after {
+				continue
+			}
+			if n == 0 && seq != after+1 {
+				return ErrKVPositionUnavailable
+			}
+
+			entry := KVLogEntry{Sequence: seq}
+			if err := json.Unmarshal(v, &entry.Changes); err != nil {
+				return err
+			}
+			if err := fn(entry); err != nil {
+				return err
+			}
+			n++
+		}
+		return cur.Err()
+	})
+}
+
+// ApplyKVLogEntry applies the changes of entry to store in a single update
+// transaction.
+func ApplyKVLogEntry(ctx context.Context, store kv.Store, entry KVLogEntry) error {
+	return store.Update(ctx, func(tx kv.Tx) error {
+		return applyKVChanges(tx, entry.Changes)
+	})
+}
+
+func applyKVChanges(tx kv.Tx, changes []KVChange) error {
+	for _, c := range changes {
+		b, err := tx.Bucket(c.Bucket)
+		if err != nil {
+			return err
+		}
+
+		if c.Delete {
+			err = b.Delete(c.Key)
+		} else {
+			err = b.Put(c.Key, c.Value)
+		}
+		if err != nil && !kv.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func lastSequence(b kv.Bucket) (uint64, error) {
+	cur, err := b.Cursor()
+	if err != nil {
+		return 0, err
+	}
+
+	k, _ := cur.Last()
+	if k == nil {
+		return 0, nil
+	}
+	return binary.BigEndian.Uint64(k), nil
+}
+
+func encodeSequence(seq uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, seq)
+	return b
+}
+
+// loggingTx records the changes made through the buckets it returns.
+type loggingTx struct {
+	kv.Tx
+	changes []KVChange
+}
+
+func (tx *loggingTx) Bucket(b []byte) (kv.Bucket, error) {
+	bucket, err := tx.Tx.Bucket(b)
+	if err != nil {
+		return nil, err
+	}
+	return &loggingBucket{Bucket: bucket, name: b, tx: tx}, nil
+}
+
+type loggingBucket struct {
+	kv.Bucket
+	name []byte
+	tx   *loggingTx
+}
+
+func (b *loggingBucket) Put(key, value []byte) error {
+	if err := b.Bucket.Put(key, value); err != nil {
+		return err
+	}
+	b.tx.changes = append(b.tx.changes, KVChange{
+		Bucket: copyBytes(b.name),
+		Key:    copyBytes(key),
+		Value:  copyBytes(value),
+	})
+	return nil
+}
+
+func (b *loggingBucket) Delete(key []byte) error {
+	if err := b.Bucket.Delete(key); err != nil {
+		return err
+	}
+	b.tx.changes = append(b.tx.changes, KVChange{
+		Bucket: copyBytes(b.name),
+		Key:    copyBytes(key),
+		Delete: true,
+	})
+	return nil
+}
+
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
@@ -0,0 +1,96 @@
+package replication_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/replication"
+)
+
+func TestLoggingStore(t *testing.T) {
+	ctx := context.Background()
+	store := replication.NewLoggingStore(inmem.NewKVStore())
+	store.Size = 2
+	if err := store.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	put := func(key, value string) {
+		t.Helper()
+		if err := store.Update(ctx, func(tx kv.Tx) error {
+			b, err := tx.Bucket([]byte("b"))
+			if err != nil {
+				return err
+			}
+			return b.Put([]byte(key), []byte(value))
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	read := func(after uint64) ([]replication.KVLogEntry, error) {
+		t.Helper()
+		var got []replication.KVLogEntry
+		err := replication.ReadKVLog(ctx, store, after, 10, func(entry replication.KVLogEntry) error {
+			got = append(got, entry)
+			return nil
+		})
+		return got, err
+	}
+
+	put("k1", "v1")
+	put("k2", "v2")
+
+	got, err := read(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []replication.KVLogEntry{
+		{Sequence: 1, Changes: []replication.KVChange{{Bucket: []byte("b"), Key: []byte("k1"), Value: []byte("v1")}}},
+		{Sequence: 2, Changes: []replication.KVChange{{Bucket: []byte("b"), Key: []byte("k2"), Value: []byte("v2")}}},
+	}
+	if diff := cmp.Diff(exp, got); diff != "" {
+		t.Fatalf("unexpected entries: -exp/+got\n%s", diff)
+	}
+
+	// Applying the entries to another store reproduces the changes.
+	replica := inmem.NewKVStore()
+	for _, entry := range got {
+		if err := replication.ApplyKVLogEntry(ctx, replica, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := replica.View(ctx, func(tx kv.Tx) error {
+		b, err := tx.Bucket([]byte("b"))
+		if err != nil {
+			return err
+		}
+		v, err := b.Get([]byte("k2"))
+		if err != nil {
+			return err
+		}
+		if string(v) != "v2" {
+			t.Errorf("unexpected value: got %q", v)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first entry is trimmed once the log is full, after which the log
+	// cannot be read from its start.
+	put("k3", "v3")
+	if _, err := read(0); err != replication.ErrKVPositionUnavailable {
+		t.Fatalf("unexpected error: got %v, exp %v", err, replication.ErrKVPositionUnavailable)
+	}
+	if _, err := read(1); err != nil {
+		t.Fatalf("unexpected error reading after a kept entry: %v", err)
+	}
+	put("k4", "v4")
+	if _, err := read(1); err != replication.ErrKVPositionUnavailable {
+		t.Fatalf("unexpected error: got %v, exp %v", err, replication.ErrKVPositionUnavailable)
+	}
+}
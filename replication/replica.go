@@ -0,0 +1,486 @@
+package replication
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"github.com/influxdata/influxdb/v2/storage/wal"
+	"github.com/influxdata/influxdb/v2/tsdb/value"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultRetryInterval is how long a replica waits before reconnecting to
+	// its primary.
+	DefaultRetryInterval = 5 * time.Second
+
+	// DefaultSaveInterval is how often a replica persists its WAL position.
+	DefaultSaveInterval = time.Second
+)
+
+var (
+	positionsBucket = []byte("replicationpositionsv1")
+	walPositionKey  = []byte("wal")
+	kvSequenceKey   = []byte("kv")
+)
+
+// WALApplier applies WAL entries streamed from a primary.
+type WALApplier interface {
+	ApplyWALEntry(ctx context.Context, entry wal.WALEntry) error
+}
+
+// Replica follows the WAL and KV change log of a primary and applies them to
+// its own engine and store, until it is promoted. The positions reached in
+// both streams are persisted in the store so that following resumes after a
+// restart.
+//
+// A replica must be seeded with a restored backup of its primary, or start
+// before the primary removes any WAL segment or trims its change log.
+type Replica struct {
+	client *httpc.Client
+	engine WALApplier
+	store  kv.Store
+	log    *zap.Logger
+	api    *kithttp.API
+
+	// RetryInterval is how long to wait before reconnecting to the primary.
+	RetryInterval time.Duration
+
+	// SaveInterval is how often the WAL position is persisted. Positions
+	// following deletes are always persisted immediately, since replaying a
+	// delete could remove later writes.
+	SaveInterval time.Duration
+
+	mu        sync.Mutex
+	status    Status
+	promoted  bool
+	onPromote []func()
+	lastSave  time.Time
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// NewReplica returns a replica of the primary client connects to.
+func NewReplica(log *zap.Logger, client *httpc.Client, engine WALApplier, store kv.Store) *Replica {
+	return &Replica{
+		client:        client,
+		engine:        engine,
+		store:         store,
+		log:           log,
+		api:           kithttp.NewAPI(kithttp.WithLog(log)),
+		RetryInterval: DefaultRetryInterval,
+		SaveInterval:  DefaultSaveInterval,
+		status:        Status{Role: "replica"},
+	}
+}
+
+// WithPrimary sets the address of the primary reported in the status.
+func (r *Replica) WithPrimary(addr string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status.Primary = addr
+}
+
+// Open loads the persisted positions and starts following the primary.
+func (r *Replica) Open(ctx context.Context) error {
+	if err := r.loadPositions(ctx); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.wg.Add(2)
+	go r.follow(ctx, "wal", r.followWAL)
+	go r.follow(ctx, "kv", r.followKV)
+	return nil
+}
+
+// Close stops following the primary and persists the positions reached.
+func (r *Replica) Close() error {
+	r.stop()
+	return r.saveWALPosition(context.Background())
+}
+
+// OnPromote calls fn once the replica is promoted, to start the services
+// of the server that only run on a primary.
+func (r *Replica) OnPromote(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onPromote = append(r.onPromote, fn)
+}
+
+// Promote stops following the primary and starts the services registered
+// with OnPromote. The server may then accept writes.
+func (r *Replica) Promote() error {
+	r.mu.Lock()
+	if r.promoted {
+		r.mu.Unlock()
+		return nil
+	}
+	r.promoted = true
+	r.mu.Unlock()
+
+	r.stop()
+	if err := r.saveWALPosition(context.Background()); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.status.Role = "primary"
+	r.status.Error = ""
+	onPromote := r.onPromote
+	r.mu.Unlock()
+
+	for _, fn := range onPromote {
+		fn()
+	}
+
+	r.log.Info("Promoted replica to primary")
+	return nil
+}
+
+// Promoted returns true once the replica was promoted.
+func (r *Replica) Promoted() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.promoted
+}
+
+// Status returns the positions the replica reached.
+func (r *Replica) Status() Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.status
+}
+
+func (r *Replica) stop() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+}
+
+// follow calls fn until ctx is canceled, waiting RetryInterval after each
+// failure.
+func (r *Replica) follow(ctx context.Context, stream string, fn func(context.Context) error) {
+	defer r.wg.Done()
+
+	log := r.log.With(zap.String("stream", stream))
+	for {
+		err := fn(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Error("Failed following primary", zap.Error(err))
+			r.mu.Lock()
+			r.status.Error = err.Error()
+			r.mu.Unlock()
+		}
+
+		select {
+		case <-time.After(r.RetryInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// followWAL applies the WAL stream of the primary until it ends.
+func (r *Replica) followWAL(ctx context.Context) error {
+	pos := r.Status().WAL
+
+	var applyErr error
+	err := r.client.
+		Get(prefixReplication, "wal").
+		QueryParams(
+			[2]string{"segment", strconv.Itoa(pos.Segment)},
+			[2]string{"offset", strconv.FormatInt(pos.Offset, 10)},
+		).
+		Accept("application/x-ndjson").
+		Decode(func(resp *http.Response) error {
+			dec := json.NewDecoder(resp.Body)
+			for {
+				var msg WALMessage
+				if err := dec.Decode(&msg); err == io.EOF {
+					return nil
+				} else if err != nil {
+					return err
+				}
+
+				entry, err := decodeWALEntry(msg.Type, msg.Data)
+				if err != nil {
+					applyErr = err
+					return err
+				}
+				if err := r.engine.ApplyWALEntry(ctx, entry); err != nil {
+					applyErr = err
+					return err
+				}
+
+				_, isDelete := entry.(*wal.DeleteBucketRangeWALEntry)
+				if err := r.setWALPosition(ctx, msg.Position, isDelete); err != nil {
+					applyErr = err
+					return err
+				}
+			}
+		}).
+		Do(ctx)
+	if applyErr != nil {
+		return applyErr
+	}
+	return err
+}
+
+// followKV applies the KV change log stream of the primary until it ends.
+func (r *Replica) followKV(ctx context.Context) error {
+	after := r.Status().KVSequence
+
+	var applyErr error
+	err := r.client.
+		Get(prefixReplication, "kv").
+		QueryParams([2]string{"after", strconv.FormatUint(after, 10)}).
+		Accept("application/x-ndjson").
+		Decode(func(resp *http.Response) error {
+			dec := json.NewDecoder(resp.Body)
+			for {
+				var entry KVLogEntry
+				if err := dec.Decode(&entry); err == io.EOF {
+					return nil
+				} else if err != nil {
+					return err
+				}
+
+				// Apply the changes and record the sequence reached atomically.
+				if err := r.store.Update(ctx, func(tx kv.Tx) error {
+					if err := applyKVChanges(tx, entry.Changes); err != nil {
+						return err
+					}
+					b, err := tx.Bucket(positionsBucket)
+					if err != nil {
+						return err
+					}
+					return b.Put(kvSequenceKey, encodeSequence(entry.Sequence))
+				}); err != nil {
+					applyErr = err
+					return err
+				}
+
+				r.mu.Lock()
+				r.status.KVSequence = entry.Sequence
+				r.status.LastApply = time.Now().UTC()
+				r.mu.Unlock()
+			}
+		}).
+		Do(ctx)
+	if applyErr != nil {
+		return applyErr
+	}
+	return err
+}
+
+func (r *Replica) setWALPosition(ctx context.Context, pos wal.Position, save bool) error {
+	r.mu.Lock()
+	r.status.WAL = pos
+	r.status.LastApply = time.Now().UTC()
+	save = save || time.Since(r.lastSave) >= r.SaveInterval
+	r.mu.Unlock()
+
+	if !save {
+		return nil
+	}
+	return r.saveWALPosition(ctx)
+}
+
+func (r *Replica) saveWALPosition(ctx context.Context) error {
+	r.mu.Lock()
+	pos := r.status.WAL
+	r.lastSave = time.Now()
+	r.mu.Unlock()
+
+	v, err := json.Marshal(pos)
+	if err != nil {
+		return err
+	}
+	return r.store.Update(ctx, func(tx kv.Tx) error {
+		b, err := tx.Bucket(positionsBucket)
+		if err != nil {
+			return err
+		}
+		return b.Put(walPositionKey, v)
+	})
+}
+
+func (r *Replica) loadPositions(ctx context.Context) error {
+	var status Status
+	err := r.store.Update(ctx, func(tx kv.Tx) error {
+		b, err := tx.Bucket(positionsBucket)
+		if err != nil {
+			return err
+		}
+
+		if v, err := b.Get(walPositionKey); err == nil {
+			if err := json.Unmarshal(v, &status.WAL); err != nil {
+				return err
+			}
+		} else if !kv.IsNotFound(err) {
+			return err
+		}
+
+		if v, err := b.Get(kvSequenceKey); err == nil {
+			status.KVSequence = binary.BigEndian.Uint64(v)
+			return nil
+		} else if !kv.IsNotFound(err) {
+			return err
+		}
+
+		// A replica restored from a backup of its primary continues after the
+		// last change the backup holds.
+		logb, err := tx.Bucket(kvLogBucket)
+		if err != nil {
+			return err
+		}
+		status.KVSequence, err = lastSequence(logb)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.status.WAL = status.WAL
+	r.status.KVSequence = status.KVSequence
+	r.mu.Unlock()
+	return nil
+}
+
+func decodeWALEntry(typ wal.WalEntryType, data []byte) (wal.WALEntry, error) {
+	var entry wal.WALEntry
+	switch typ {
+	case wal.WriteWALEntryType:
+		entry = &wal.WriteWALEntry{Values: make(map[string][]value.Value)}
+	case wal.DeleteBucketRangeWALEntryType:
+		entry = &wal.DeleteBucketRangeWALEntry{}
+	default:
+		return nil, fmt.Errorf("unknown wal entry type: %v", typ)
+	}
+	if err := entry.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// readOnlyRoutes may be requested with any method while the replica follows
+// its primary; they do not write data replicated from the primary.
+var readOnlyRoutes = map[string]bool{
+	"/api/v2/query":                true,
+	"/api/v2/query/ast":            true,
+	"/api/v2/query/analyze":        true,
+	"/api/v2/query/explain":        true,
+	"/api/v2/query/suggestions":    true,
+	"/api/v2/prom/read":            true,
+	prefixReplication + "/promote": true,
+}
+
+var errReadOnly = &influxdb.Error{
+	Code: influxdb.EMethodNotAllowed,
+	Msg:  "server is a read-only replica; promote it to accept writes",
+}
+
+// ReadOnly rejects requests that could write data until the replica is
+// promoted.
+func (r *Replica) ReadOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			if !readOnlyRoutes[req.URL.Path] && !r.Promoted() {
+				r.api.Err(w, errReadOnly)
+				return
+			}
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// SessionService returns a session service that does not renew the sessions
+// of s until the replica is promoted, since renewing a session updates the
+// store.
+func (r *Replica) SessionService(s influxdb.SessionService) influxdb.SessionService {
+	return &sessionService{SessionService: s, replica: r}
+}
+
+type sessionService struct {
+	influxdb.SessionService
+	replica *Replica
+}
+
+func (s *sessionService) RenewSession(ctx context.Context, session *influxdb.Session, newExpiration time.Time) error {
+	if !s.replica.Promoted() {
+		return nil
+	}
+	return s.SessionService.RenewSession(ctx, session, newExpiration)
+}
+
+var _ kv.AutoMigrationStore = (*ReadOnlyStore)(nil)
+
+// ReadOnlyStore is a kv.Store that rejects update transactions while the
+// replica it is attached to follows its primary, so that the services of a
+// replica cannot change the data replicated from the primary. The replica
+// applies the changes of its primary to the wrapped store directly.
+//
+// Updates are allowed until a replica is attached, so that the services can
+// initialize the store when the server starts.
+type ReadOnlyStore struct {
+	kv.Store
+
+	mu      sync.Mutex
+	replica *Replica
+}
+
+// NewReadOnlyStore returns a store rejecting updates of s once a replica that
+// was not promoted is attached.
+func NewReadOnlyStore(s kv.Store) *ReadOnlyStore {
+	return &ReadOnlyStore{Store: s}
+}
+
+// Attach rejects the updates of the store until r is promoted.
+func (s *ReadOnlyStore) Attach(r *Replica) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replica = r
+}
+
+// AutoMigrate returns the store the migrations of the wrapped store are
+// applied to automatically, if any.
+func (s *ReadOnlyStore) AutoMigrate() kv.Store {
+	if store, ok := s.Store.(kv.AutoMigrationStore); ok {
+		return store.AutoMigrate()
+	}
+	return nil
+}
+
+// Update runs fn in an update transaction of the wrapped store, unless the
+// attached replica was not promoted.
+func (s *ReadOnlyStore) Update(ctx context.Context, fn func(kv.Tx) error) error {
+	s.mu.Lock()
+	r := s.replica
+	s.mu.Unlock()
+
+	if r != nil && !r.Promoted() {
+		return errReadOnly
+	}
+	return s.Store.Update(ctx, fn)
+}
@@ -0,0 +1,278 @@
+package replication_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	ihttp "github.com/influxdata/influxdb/v2/http"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/replication"
+	"github.com/influxdata/influxdb/v2/storage/wal"
+	"github.com/influxdata/influxdb/v2/tsdb/value"
+	"go.uber.org/zap/zaptest"
+)
+
+type walApplier struct {
+	mu      sync.Mutex
+	entries []wal.WALEntry
+}
+
+func (a *walApplier) ApplyWALEntry(_ context.Context, entry wal.WALEntry) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.entries = append(a.entries, entry)
+	return nil
+}
+
+func (a *walApplier) len() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.entries)
+}
+
+// walSource streams the entries of a WAL.
+type walSource struct {
+	*wal.WAL
+}
+
+func (s walSource) TailWAL(pos wal.Position, fn func(wal.WALEntry, wal.Position) error) (wal.Position, error) {
+	return s.Tail(pos, fn)
+}
+
+// operator authenticates every request with operator permissions.
+func operator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := &influxdb.Authorization{Status: influxdb.Active, Permissions: influxdb.OperPermissions()}
+		next.ServeHTTP(w, r.WithContext(icontext.SetAuthorizer(r.Context(), auth)))
+	})
+}
+
+func waitFor(t *testing.T, fn func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !fn() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for replica")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReplica(t *testing.T) {
+	ctx := context.Background()
+	log := zaptest.NewLogger(t)
+
+	dir, err := ioutil.TempDir("", "replication")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	w := wal.NewWAL(dir)
+	if err := w.Open(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	primaryStore := replication.NewLoggingStore(inmem.NewKVStore())
+	if err := primaryStore.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	h := replication.NewHTTPHandler(log, walSource{w}, primaryStore, nil)
+	h.PollInterval = 10 * time.Millisecond
+	mux := http.NewServeMux()
+	mux.Handle(h.Prefix()+"/", http.StripPrefix(h.Prefix(), h))
+	srv := httptest.NewServer(operator(mux))
+	defer srv.Close()
+
+	client, err := ihttp.NewHTTPClient(srv.URL, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	applier := &walApplier{}
+	replicaStore := inmem.NewKVStore()
+	replica := replication.NewReplica(log, client, applier, replicaStore)
+	replica.RetryInterval = 10 * time.Millisecond
+	if err := replica.Open(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer replica.Close()
+
+	if _, err := w.WriteMulti(ctx, map[string][]value.Value{
+		"cpu,host=A#!~#value": {value.NewValue(1, 1.0)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := primaryStore.Update(ctx, func(tx kv.Tx) error {
+		b, err := tx.Bucket([]byte("b"))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte("k"), []byte("v"))
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, func() bool {
+		return applier.len() == 1 && replica.Status().KVSequence == 1
+	})
+
+	if err := replicaStore.View(ctx, func(tx kv.Tx) error {
+		b, err := tx.Bucket([]byte("b"))
+		if err != nil {
+			return err
+		}
+		v, err := b.Get([]byte("k"))
+		if err != nil {
+			return err
+		}
+		if string(v) != "v" {
+			t.Errorf("unexpected value: got %q", v)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Writes are rejected until the replica is promoted.
+	readOnly := replica.ReadOnly(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	post := func(path string) int {
+		rec := httptest.NewRecorder()
+		readOnly.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, nil))
+		return rec.Code
+	}
+	write := func() int { return post("/api/v2/write") }
+	if code := write(); code != http.StatusMethodNotAllowed {
+		t.Fatalf("unexpected status before promotion: got %d", code)
+	}
+
+	// Queries are served before the promotion, whatever their method.
+	for _, path := range []string{"/api/v2/query", "/api/v2/query/explain", "/api/v2/prom/read"} {
+		if code := post(path); code != http.StatusNoContent {
+			t.Fatalf("unexpected status of %s before promotion: got %d", path, code)
+		}
+	}
+
+	var started int
+	replica.OnPromote(func() { started++ })
+
+	if err := replica.Promote(); err != nil {
+		t.Fatal(err)
+	}
+	if code := write(); code != http.StatusNoContent {
+		t.Fatalf("unexpected status after promotion: got %d", code)
+	}
+
+	// The services of a primary are started once, however often the
+	// replica is promoted.
+	if err := replica.Promote(); err != nil {
+		t.Fatal(err)
+	}
+	if started != 1 {
+		t.Fatalf("unexpected starts of the services of a primary: got %d", started)
+	}
+
+	// Entries written after the promotion are not applied.
+	if _, err := w.WriteMulti(ctx, map[string][]value.Value{
+		"cpu,host=A#!~#value": {value.NewValue(2, 2.0)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if n := applier.len(); n != 1 {
+		t.Fatalf("unexpected entries applied after promotion: got %d", n)
+	}
+	if status := replica.Status(); status.Role != "primary" {
+		t.Fatalf("unexpected role: got %q", status.Role)
+	}
+}
+
+func TestReplica_restoredFromBackup(t *testing.T) {
+	ctx := context.Background()
+
+	// The store of the replica was restored from a backup of its primary,
+	// holding the change log of the primary.
+	backup := inmem.NewKVStore()
+	primaryStore := replication.NewLoggingStore(backup)
+	if err := primaryStore.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	update := func(store kv.Store) error {
+		return store.Update(ctx, func(tx kv.Tx) error {
+			b, err := tx.Bucket([]byte("b"))
+			if err != nil {
+				return err
+			}
+			return b.Put([]byte("k"), []byte("v"))
+		})
+	}
+	if err := update(primaryStore); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	client, err := ihttp.NewHTTPClient(srv.URL, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replica := replication.NewReplica(zaptest.NewLogger(t), client, &walApplier{}, backup)
+	replica.RetryInterval = time.Hour
+	store := replication.NewReadOnlyStore(backup)
+	store.Attach(replica)
+	if err := replica.Open(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer replica.Close()
+
+	// Following continues after the last change of the backup.
+	if seq := replica.Status().KVSequence; seq != 1 {
+		t.Fatalf("unexpected KV sequence: got %d, exp 1", seq)
+	}
+
+	// The services of the replica cannot update the store until it is
+	// promoted, and sessions are not renewed.
+	var renewed int
+	sessions := mock.NewSessionService()
+	sessions.RenewSessionFn = func(context.Context, *influxdb.Session, time.Time) error {
+		renewed++
+		return nil
+	}
+	sessionSvc := replica.SessionService(sessions)
+	renew := func() {
+		if err := sessionSvc.RenewSession(ctx, &influxdb.Session{}, time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := update(store); influxdb.ErrorCode(err) != influxdb.EMethodNotAllowed {
+		t.Fatalf("unexpected error updating the store of a replica: %v", err)
+	}
+	if renew(); renewed != 0 {
+		t.Fatalf("unexpected renewals of sessions on a replica: got %d", renewed)
+	}
+	if err := replica.Promote(); err != nil {
+		t.Fatal(err)
+	}
+	if err := update(store); err != nil {
+		t.Fatalf("unexpected error updating the store after promotion: %v", err)
+	}
+	if renew(); renewed != 1 {
+		t.Fatalf("unexpected renewals of sessions after promotion: got %d", renewed)
+	}
+}
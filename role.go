@@ -0,0 +1,235 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+)
+
+// errors on roles and user groups.
+const (
+	ErrRoleNotFound      = "role not found"
+	ErrUserGroupNotFound = "user group not found"
+)
+
+// ops for role and user group errors.
+const (
+	OpFindRoleByID      = "FindRoleByID"
+	OpFindRoles         = "FindRoles"
+	OpCreateRole        = "CreateRole"
+	OpUpdateRole        = "UpdateRole"
+	OpDeleteRole        = "DeleteRole"
+	OpFindUserGroupByID = "FindUserGroupByID"
+	OpFindUserGroups    = "FindUserGroups"
+	OpCreateUserGroup   = "CreateUserGroup"
+	OpUpdateUserGroup   = "UpdateUserGroup"
+	OpDeleteUserGroup   = "DeleteUserGroup"
+)
+
+// Role is a named set of permissions within an organization. Users are
+// given the permissions of a role when it is assigned to them directly,
+// or to a user group they are a member of.
+type Role struct {
+	ID          ID           `json:"id,omitempty"`
+	OrgID       ID           `json:"orgID"`
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	Permissions []Permission `json:"permissions"`
+	// Users are the users the role is assigned to directly.
+	Users []ID `json:"users,omitempty"`
+	CRUDLog
+}
+
+// Valid returns an error if the role has no name, or if it has permissions
+// outside of its organization.
+func (r *Role) Valid() error {
+	if r.Name == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "role name is empty",
+		}
+	}
+	if !r.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "role must belong to an organization",
+		}
+	}
+	for _, p := range r.Permissions {
+		if err := p.Valid(); err != nil {
+			return &Error{
+				Code: EInvalid,
+				Err:  err,
+			}
+		}
+		if !permissionInOrg(p, r.OrgID) {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("permission %s is not within the organization of the role", p),
+			}
+		}
+	}
+	return nil
+}
+
+// permissionInOrg reports whether the permission is to resources of the
+// organization, or to the organization itself.
+func permissionInOrg(p Permission, orgID ID) bool {
+	if p.Resource.OrgID != nil {
+		return *p.Resource.OrgID == orgID
+	}
+	return p.Resource.Type == OrgsResourceType && p.Resource.ID != nil && *p.Resource.ID == orgID
+}
+
+// HasUser reports whether the role is assigned to the user directly.
+func (r *Role) HasUser(userID ID) bool {
+	return containsID(r.Users, userID)
+}
+
+// RoleFilter represents a set of filters that restrict the returned roles.
+type RoleFilter struct {
+	ID    *ID
+	OrgID *ID
+	Name  *string
+	// UserID restricts the roles to those assigned to the user directly.
+	UserID *ID
+}
+
+// RoleUpdate represents updates to a role. Only fields which are set are
+// updated.
+type RoleUpdate struct {
+	Name        *string       `json:"name,omitempty"`
+	Description *string       `json:"description,omitempty"`
+	Permissions *[]Permission `json:"permissions,omitempty"`
+	Users       *[]ID         `json:"users,omitempty"`
+}
+
+// Apply applies the update to the role r.
+func (u RoleUpdate) Apply(r *Role) {
+	if u.Name != nil {
+		r.Name = *u.Name
+	}
+	if u.Description != nil {
+		r.Description = *u.Description
+	}
+	if u.Permissions != nil {
+		r.Permissions = *u.Permissions
+	}
+	if u.Users != nil {
+		r.Users = *u.Users
+	}
+}
+
+// RoleService represents a service for managing roles.
+type RoleService interface {
+	// FindRoleByID returns a single role by ID.
+	FindRoleByID(ctx context.Context, id ID) (*Role, error)
+
+	// FindRoles returns a list of roles that match filter and the total count of matching roles.
+	FindRoles(ctx context.Context, filter RoleFilter, opt ...FindOptions) ([]*Role, int, error)
+
+	// CreateRole creates a new role and sets r.ID with the new identifier.
+	CreateRole(ctx context.Context, r *Role) error
+
+	// UpdateRole updates a single role with changeset and returns the new role.
+	UpdateRole(ctx context.Context, id ID, upd RoleUpdate) (*Role, error)
+
+	// DeleteRole removes a role by ID, and removes it from the user groups
+	// it is assigned to.
+	DeleteRole(ctx context.Context, id ID) error
+}
+
+// UserGroup is a group of users within an organization. The members of the
+// group are given the permissions of the roles assigned to the group.
+type UserGroup struct {
+	ID          ID     `json:"id,omitempty"`
+	OrgID       ID     `json:"orgID"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Members     []ID   `json:"members,omitempty"`
+	Roles       []ID   `json:"roles,omitempty"`
+	CRUDLog
+}
+
+// Valid returns an error if the user group has no name or organization.
+func (g *UserGroup) Valid() error {
+	if g.Name == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "user group name is empty",
+		}
+	}
+	if !g.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "user group must belong to an organization",
+		}
+	}
+	return nil
+}
+
+// HasMember reports whether the user is a member of the group.
+func (g *UserGroup) HasMember(userID ID) bool {
+	return containsID(g.Members, userID)
+}
+
+// UserGroupFilter represents a set of filters that restrict the returned
+// user groups.
+type UserGroupFilter struct {
+	ID    *ID
+	OrgID *ID
+	Name  *string
+	// UserID restricts the groups to those the user is a member of.
+	UserID *ID
+}
+
+// UserGroupUpdate represents updates to a user group. Only fields which are
+// set are updated.
+type UserGroupUpdate struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Members     *[]ID   `json:"members,omitempty"`
+	Roles       *[]ID   `json:"roles,omitempty"`
+}
+
+// Apply applies the update to the user group g.
+func (u UserGroupUpdate) Apply(g *UserGroup) {
+	if u.Name != nil {
+		g.Name = *u.Name
+	}
+	if u.Description != nil {
+		g.Description = *u.Description
+	}
+	if u.Members != nil {
+		g.Members = *u.Members
+	}
+	if u.Roles != nil {
+		g.Roles = *u.Roles
+	}
+}
+
+// UserGroupService represents a service for managing user groups.
+type UserGroupService interface {
+	// FindUserGroupByID returns a single user group by ID.
+	FindUserGroupByID(ctx context.Context, id ID) (*UserGroup, error)
+
+	// FindUserGroups returns a list of user groups that match filter and the total count of matching groups.
+	FindUserGroups(ctx context.Context, filter UserGroupFilter, opt ...FindOptions) ([]*UserGroup, int, error)
+
+	// CreateUserGroup creates a new user group and sets g.ID with the new identifier.
+	CreateUserGroup(ctx context.Context, g *UserGroup) error
+
+	// UpdateUserGroup updates a single user group with changeset and returns the new group.
+	UpdateUserGroup(ctx context.Context, id ID, upd UserGroupUpdate) (*UserGroup, error)
+
+	// DeleteUserGroup removes a user group by ID.
+	DeleteUserGroup(ctx context.Context, id ID) error
+}
+
+func containsID(ids []ID, id ID) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
@@ -64,6 +64,14 @@ func (s *Session) Allowed(p Permission) bool {
 	return PermissionAllowed(p, s.Permissions)
 }
 
+// PermissionSet returns the permissions of the session if it is unexpired.
+func (s *Session) PermissionSet() []Permission {
+	if err := s.Expired(); err != nil {
+		return nil
+	}
+	return s.Permissions
+}
+
 // Kind returns session and is used for auditing.
 func (s *Session) Kind() string { return SessionAuthorizionKind }
 
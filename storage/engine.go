@@ -59,6 +59,13 @@ type Engine struct {
 	engine  *tsm1.Engine
 	wal     *wal.WAL
 
+	// replicated is set when replicas follow the WAL, so that no write
+	// skips it.
+	replicated bool
+
+	// writeObserver, if set, is notified of the changes to the buckets.
+	writeObserver WriteObserver
+
 	retentionEnforcer        runner
 	retentionEnforcerLimiter runnable
 
@@ -106,6 +113,15 @@ func WithNodeID(id int) Option {
 	}
 }
 
+// WithReplication makes every write go through the WAL, which replicas
+// follow: the durability none is ignored, and the writes requesting it are
+// acknowledged asynchronously instead.
+func WithReplication() Option {
+	return func(e *Engine) {
+		e.replicated = true
+	}
+}
+
 // WithRetentionEnforcer initialises a retention enforcer on the engine.
 // WithRetentionEnforcer must be called after other options to ensure that all
 // metrics are labelled correctly.
@@ -132,6 +148,14 @@ func WithFileStoreObserver(obs tsm1.FileStoreObserver) Option {
 	}
 }
 
+// WithWriteObserver makes the engine notify the observer of the time
+// range of each bucket that points are written to or deleted from.
+func WithWriteObserver(obs WriteObserver) Option {
+	return func(e *Engine) {
+		e.writeObserver = obs
+	}
+}
+
 // WithCompactionPlanner makes the engine have the provided compaction planner.
 func WithCompactionPlanner(planner tsm1.CompactionPlanner) Option {
 	return func(e *Engine) {
@@ -177,6 +201,8 @@ func NewEngine(path string, c Config, options ...Option) *Engine {
 	// Initialize WAL
 	e.wal = wal.NewWAL(c.GetWALPath(path))
 	e.wal.WithFsyncDelay(time.Duration(c.WAL.FsyncDelay))
+	e.wal.WithGroupCommitMaxBatch(c.WAL.GroupCommitMaxBatch)
+	e.wal.WithFlushInterval(time.Duration(c.WAL.FlushInterval))
 	e.wal.SetEnabled(c.WAL.Enabled)
 
 	// Initialise Engine
@@ -246,6 +272,10 @@ func (e *Engine) Open(ctx context.Context) (err error) {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
+	if err := e.configureWAL(); err != nil {
+		return err
+	}
+
 	// Open the services in order and clean up if any fail.
 	var oh openHelper
 	oh.Open(ctx, e.sfile)
@@ -272,6 +302,23 @@ func (e *Engine) Open(ctx context.Context) (err error) {
 	return nil
 }
 
+// configureWAL applies the WAL durability settings of the config that must be
+// validated.
+func (e *Engine) configureWAL() error {
+	durability, err := wal.ParseDurability(e.config.WAL.Durability)
+	if err != nil {
+		return err
+	}
+	e.wal.WithDurability(durability)
+	if e.replicated {
+		if durability == wal.DurabilityNone {
+			e.logger.Warn("Ignoring the WAL durability none, since replicas follow the WAL")
+		}
+		e.wal.WithReplication()
+	}
+	return nil
+}
+
 // replayWAL reads the WAL segment files and replays them.
 func (e *Engine) replayWAL() error {
 	if !e.config.WAL.Enabled {
@@ -296,28 +343,7 @@ func (e *Engine) replayWAL() error {
 	reader := wal.NewWALReader(walPaths)
 	reader.WithLogger(e.logger)
 	err = reader.Read(func(entry wal.WALEntry) error {
-		switch en := entry.(type) {
-		case *wal.WriteWALEntry:
-			points := tsm1.ValuesToPoints(en.Values)
-			err := e.writePointsLocked(context.Background(), tsdb.NewSeriesCollection(points), en.Values)
-			if _, ok := err.(tsdb.PartialWriteError); ok {
-				err = nil
-			}
-			return err
-
-		case *wal.DeleteBucketRangeWALEntry:
-			var pred tsm1.Predicate
-			if len(en.Predicate) > 0 {
-				pred, err = tsm1.UnmarshalPredicate(en.Predicate)
-				if err != nil {
-					return err
-				}
-			}
-
-			return e.deleteBucketRangeLocked(context.Background(), en.OrgID, en.BucketID, en.Min, en.Max, pred)
-		}
-
-		return nil
+		return e.applyWALEntryLocked(context.Background(), entry)
 	})
 
 	e.logger.Info("Reloaded WAL",
@@ -328,6 +354,82 @@ func (e *Engine) replayWAL() error {
 	return err
 }
 
+// applyWALEntryLocked applies a WAL entry to the index and engine and must be
+// called under some sort of lock.
+func (e *Engine) applyWALEntryLocked(ctx context.Context, entry wal.WALEntry) error {
+	switch en := entry.(type) {
+	case *wal.WriteWALEntry:
+		points := tsm1.ValuesToPoints(en.Values)
+		err := e.writePointsLocked(ctx, tsdb.NewSeriesCollection(points), en.Values)
+		if _, ok := err.(tsdb.PartialWriteError); ok {
+			err = nil
+		}
+		return err
+
+	case *wal.DeleteBucketRangeWALEntry:
+		var pred tsm1.Predicate
+		if len(en.Predicate) > 0 {
+			var err error
+			pred, err = tsm1.UnmarshalPredicate(en.Predicate)
+			if err != nil {
+				return err
+			}
+		}
+
+		return e.deleteBucketRangeLocked(ctx, en.OrgID, en.BucketID, en.Min, en.Max, pred)
+	}
+
+	return nil
+}
+
+// TailWAL calls fn with each entry written to the WAL of the engine after pos
+// and returns the position following the last entry. See wal.WAL.Tail.
+//
+// Only the list of segments to read is taken under the engine lock. The
+// entries are read without it, so that a slow reader, such as a replica, does
+// not hold up writes, compactions or Close.
+func (e *Engine) TailWAL(pos wal.Position, fn func(wal.WALEntry, wal.Position) error) (wal.Position, error) {
+	e.mu.RLock()
+	if e.closing == nil {
+		e.mu.RUnlock()
+		return pos, ErrEngineClosed
+	}
+	snap, err := e.wal.TailSnapshot(pos)
+	e.mu.RUnlock()
+	if err != nil {
+		return pos, err
+	}
+
+	return snap.Tail(fn)
+}
+
+// ApplyWALEntry applies an entry read from the WAL of another engine, such as
+// the primary followed by a replica. The entry is added to the WAL of this
+// engine before it is applied.
+func (e *Engine) ApplyWALEntry(ctx context.Context, entry wal.WALEntry) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return ErrEngineClosed
+	}
+
+	switch en := entry.(type) {
+	case *wal.WriteWALEntry:
+		if _, err := e.wal.WriteMulti(ctx, en.Values); err != nil {
+			return err
+		}
+	case *wal.DeleteBucketRangeWALEntry:
+		if _, err := e.wal.DeleteBucketRange(en.OrgID, en.BucketID, en.Min, en.Max, en.Predicate); err != nil {
+			return err
+		}
+	}
+
+	return e.applyWALEntryLocked(ctx, entry)
+}
+
 // EnableCompactions allows the series file, index, & underlying engine to compact.
 func (e *Engine) EnableCompactions() {
 	e.sfile.EnableCompactions()
@@ -554,6 +656,9 @@ func (e *Engine) writePointsLocked(ctx context.Context, collection *tsdb.SeriesC
 	span, _ := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
+	// Some of the values may have been written even if the write fails.
+	defer func() { e.observeWrite(values) }()
+
 	// TODO(jeff): keep track of the values in the collection so that partial write
 	// errors get tracked all the way. Right now, the engine doesn't drop any values
 	// but if it ever did, the errors could end up missing some data.
@@ -679,6 +784,11 @@ func (e *Engine) deleteBucketRangeLocked(ctx context.Context, orgID, bucketID in
 	encoded := tsdb.EncodeName(orgID, bucketID)
 	name := models.EscapeMeasurement(encoded[:])
 
+	defer func() {
+		if e.writeObserver != nil {
+			e.writeObserver.BucketModified(orgID, bucketID, min, max)
+		}
+	}()
 	return e.engine.DeletePrefixRange(ctx, name, min, max, pred)
 }
 
@@ -7,6 +7,8 @@ import (
 	"math"
 	"math/rand"
 	"os"
+	"reflect"
+	"sync"
 	"testing"
 	"time"
 
@@ -15,6 +17,7 @@ import (
 	"github.com/influxdata/influxdb/v2/models"
 	"github.com/influxdata/influxdb/v2/storage"
 	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/v2/storage/wal"
 	"github.com/influxdata/influxdb/v2/tsdb"
 	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
 	"github.com/prometheus/client_golang/prometheus"
@@ -464,6 +467,208 @@ func TestEngine_WALDisabled(t *testing.T) {
 	}
 }
 
+func TestEngine_TailWALUnlocked(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	pt := models.MustNewPoint(
+		"cpu",
+		models.Tags{
+			{Key: models.MeasurementTagKeyBytes, Value: []byte("cpu")},
+			{Key: []byte("host"), Value: []byte("server")},
+			{Key: models.FieldKeyTagKeyBytes, Value: []byte("value")},
+		},
+		map[string]interface{}{"value": 1.0},
+		time.Unix(1, 2),
+	)
+	if err := engine.Engine.WritePoints(context.TODO(), []models.Point{pt}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A reader blocked on an entry does not keep writes or Close waiting.
+	reading, release := make(chan struct{}), make(chan struct{})
+	go func() {
+		var once sync.Once
+		engine.Engine.TailWAL(wal.Position{}, func(wal.WALEntry, wal.Position) error {
+			once.Do(func() { close(reading) })
+			<-release
+			return nil
+		})
+	}()
+	defer close(release)
+	<-reading
+
+	closed := make(chan error, 1)
+	go func() {
+		if err := engine.Engine.WritePoints(context.TODO(), []models.Point{pt}); err != nil {
+			closed <- err
+			return
+		}
+		closed <- engine.Engine.Close()
+	}()
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("writing and closing the engine waited for the WAL reader")
+	}
+}
+
+func TestEngine_WALDurabilityNone(t *testing.T) {
+	config := storage.NewConfig()
+
+	engine := NewEngine(config, rand.Int(), rand.Int())
+	defer engine.Close()
+	engine.MustOpen()
+
+	// The writes of the second bucket skip the WAL.
+	noneID, _ := influxdb.IDFromString("8888888888888888")
+	for _, bucketID := range []influxdb.ID{engine.bucket, *noneID} {
+		ctx := context.Background()
+		if bucketID == *noneID {
+			ctx = wal.NewContextWithDurability(ctx, wal.DurabilityNone)
+		}
+		err := engine.Engine.WritePoints(ctx, []models.Point{models.MustNewPoint(
+			tsdb.EncodeNameString(engine.org, bucketID),
+			models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": "server"}),
+			map[string]interface{}{"value": 1.0},
+			time.Unix(1, 2),
+		)})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Both buckets are written to the cache.
+	if got, exp := engine.SeriesCardinality(), int64(2); got != exp {
+		t.Fatalf("got %d series, exp %d series in index", got, exp)
+	}
+
+	// Don't remove the data.
+	if err := engine.Engine.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := wal.SegmentFileNames(config.GetWALPath(engine.path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buckets []influxdb.ID
+	if err := wal.NewWALReader(files).Read(func(entry wal.WALEntry) error {
+		for k := range entry.(*wal.WriteWALEntry).Values {
+			_, bucketID := tsdb.DecodeNameSlice(models.ParseName([]byte(k)))
+			buckets = append(buckets, bucketID)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(buckets) != 1 || buckets[0] != engine.bucket {
+		t.Fatalf("unexpected buckets written to WAL: got %v, exp [%v]", buckets, engine.bucket)
+	}
+}
+
+func TestEngine_WALDurabilityNone_replicated(t *testing.T) {
+	config := storage.NewConfig()
+
+	engine := NewEngine(config, rand.Int(), rand.Int(), storage.WithReplication())
+	defer engine.Close()
+	engine.MustOpen()
+
+	// The durability none doesn't skip the WAL that replicas follow.
+	ctx := wal.NewContextWithDurability(context.Background(), wal.DurabilityNone)
+	noneID, _ := influxdb.IDFromString("8888888888888888")
+	for _, bucketID := range []influxdb.ID{engine.bucket, *noneID} {
+		err := engine.Engine.WritePoints(ctx, []models.Point{models.MustNewPoint(
+			tsdb.EncodeNameString(engine.org, bucketID),
+			models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": "server"}),
+			map[string]interface{}{"value": 1.0},
+			time.Unix(1, 2),
+		)})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Don't remove the data.
+	if err := engine.Engine.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := wal.SegmentFileNames(config.GetWALPath(engine.path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	if err := wal.NewWALReader(files).Read(func(entry wal.WALEntry) error {
+		n += len(entry.(*wal.WriteWALEntry).Values)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatalf("unexpected series written to WAL: got %d, exp 2", n)
+	}
+}
+
+type bucketModification struct {
+	orgID, bucketID influxdb.ID
+	min, max        int64
+}
+
+type writeObserver []bucketModification
+
+func (o *writeObserver) BucketModified(orgID, bucketID influxdb.ID, min, max int64) {
+	*o = append(*o, bucketModification{orgID: orgID, bucketID: bucketID, min: min, max: max})
+}
+
+func TestEngine_WriteObserver(t *testing.T) {
+	var observer writeObserver
+	engine := NewEngine(storage.NewConfig(), rand.Int(), rand.Int(), storage.WithWriteObserver(&observer))
+	defer engine.Close()
+	engine.MustOpen()
+
+	var points []models.Point
+	for _, ts := range []time.Time{time.Unix(5, 0), time.Unix(1, 2), time.Unix(3, 0)} {
+		points = append(points, models.MustNewPoint(
+			tsdb.EncodeNameString(engine.org, engine.bucket),
+			models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": "server"}),
+			map[string]interface{}{"value": 1.0},
+			ts,
+		))
+	}
+	if err := engine.Engine.WritePoints(context.TODO(), points); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.DeleteBucketRange(context.Background(), engine.org, engine.bucket, 2e9, 4e9); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := writeObserver{
+		{orgID: engine.org, bucketID: engine.bucket, min: time.Unix(1, 2).UnixNano(), max: time.Unix(5, 0).UnixNano()},
+		{orgID: engine.org, bucketID: engine.bucket, min: 2e9, max: 4e9},
+	}
+	if !reflect.DeepEqual(observer, exp) {
+		t.Fatalf("unexpected modifications: got %v, exp %v", observer, exp)
+	}
+}
+
+func TestEngine_InvalidWALDurability(t *testing.T) {
+	config := storage.NewConfig()
+	config.WAL.Durability = "fast"
+
+	engine := NewEngine(config, rand.Int(), rand.Int())
+	defer engine.Close()
+
+	if err := engine.Engine.Open(context.Background()); err == nil {
+		t.Fatal("expected error opening engine with invalid WAL durability")
+	}
+}
+
 func TestEngine_WriteConflictingBatch(t *testing.T) {
 	engine := NewDefaultEngine()
 	defer engine.Close()
@@ -639,10 +844,11 @@ type Engine struct {
 }
 
 // NewEngine create a new wrapper around a storage engine.
-func NewEngine(c storage.Config, engineID, nodeID int) *Engine {
+func NewEngine(c storage.Config, engineID, nodeID int, options ...storage.Option) *Engine {
 	path, _ := ioutil.TempDir("", "storage_engine_test")
 
-	engine := storage.NewEngine(path, c, storage.WithEngineID(engineID), storage.WithNodeID(nodeID))
+	options = append([]storage.Option{storage.WithEngineID(engineID), storage.WithNodeID(nodeID)}, options...)
+	engine := storage.NewEngine(path, c, options...)
 
 	org, err := influxdb.IDFromString("3131313131313131")
 	if err != nil {
@@ -3,13 +3,17 @@ package storageflux
 import (
 	"context"
 	"fmt"
+	"math"
 	"strings"
+	"time"
 
 	"github.com/gogo/protobuf/types"
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/semantic"
 	"github.com/influxdata/flux/values"
+	platform "github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/kit/errors"
 	"github.com/influxdata/influxdb/v2/models"
 	"github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb"
@@ -81,10 +85,24 @@ func (r *storeReader) ReadGroup(ctx context.Context, spec influxdb.ReadGroupSpec
 }
 
 func (r *storeReader) HasWindowAggregateCapability(ctx context.Context, capability ...*influxdb.WindowAggregateCapability) bool {
-	if aggStore, ok := r.s.(storage.WindowAggregateStore); ok {
-		return aggStore.HasWindowAggregateCapability(ctx)
+	aggStore, ok := r.s.(storage.WindowAggregateStore)
+	if !ok {
+		return false
 	}
-	return false
+
+	caps := make([]*storage.WindowAggregateCapability, 0, len(capability))
+	for _, c := range capability {
+		sc := &storage.WindowAggregateCapability{WindowEvery: c.WindowEvery}
+		for _, agg := range c.Aggregates {
+			t, err := determineAggregateMethod(agg)
+			if err != nil {
+				return false
+			}
+			sc.Aggregates = append(sc.Aggregates, t)
+		}
+		caps = append(caps, sc)
+	}
+	return aggStore.HasWindowAggregateCapability(ctx, caps...)
 }
 
 func (r *storeReader) ReadWindowAggregate(ctx context.Context, spec influxdb.ReadWindowAggregateSpec, alloc *memory.Allocator) (influxdb.TableIterator, error) {
@@ -162,22 +180,13 @@ func (fi *filterIterator) Do(f func(flux.Table) error) error {
 		return err
 	}
 
-	var predicate *datatypes.Predicate
-	if fi.spec.Predicate != nil {
-		p, err := toStoragePredicate(fi.spec.Predicate)
-		if err != nil {
-			return err
-		}
-		predicate = p
+	req, err := NewReadFilterRequest(fi.spec)
+	if err != nil {
+		return err
 	}
-
-	var req datatypes.ReadFilterRequest
 	req.ReadSource = any
-	req.Predicate = predicate
-	req.Range.Start = int64(fi.spec.Bounds.Start)
-	req.Range.End = int64(fi.spec.Bounds.Stop)
 
-	rs, err := fi.s.ReadFilter(fi.ctx, &req)
+	rs, err := fi.s.ReadFilter(fi.ctx, req)
 	if err != nil {
 		return err
 	}
@@ -286,39 +295,265 @@ func (gi *groupIterator) Do(f func(flux.Table) error) error {
 		return err
 	}
 
-	var predicate *datatypes.Predicate
-	if gi.spec.Predicate != nil {
-		p, err := toStoragePredicate(gi.spec.Predicate)
-		if err != nil {
+	req, err := NewReadGroupRequest(gi.spec)
+	if err != nil {
+		return err
+	}
+	req.ReadSource = any
+
+	rs, err := gi.s.ReadGroup(gi.ctx, req)
+	if err != nil {
+		return err
+	}
+
+	if rs == nil {
+		return nil
+	}
+	if req.Aggregate != nil {
+		return gi.handleAggregateRead(f, rs, req.Aggregate.Type)
+	}
+	return gi.handleRead(f, rs)
+}
+
+// handleAggregateRead combines the aggregates storage computed for each series
+// of a group into a single row for the group.
+func (gi *groupIterator) handleAggregateRead(f func(flux.Table) error, rs storage.GroupResultSet, agg datatypes.Aggregate_AggregateType) error {
+	defer func() {
+		rs.Close()
+		gi.cache.Release()
+	}()
+
+	for gc := rs.Next(); gc != nil; gc = rs.Next() {
+		if err := gi.aggregateGroup(f, gc, agg); err != nil {
+			gc.Close()
 			return err
 		}
-		predicate = p
+		gc.Close()
 	}
+	return rs.Err()
+}
 
-	var req datatypes.ReadGroupRequest
-	req.ReadSource = any
-	req.Predicate = predicate
-	req.Range.Start = int64(gi.spec.Bounds.Start)
-	req.Range.End = int64(gi.spec.Bounds.Stop)
+func (gi *groupIterator) aggregateGroup(f func(flux.Table) error, gc storage.GroupCursor, agg datatypes.Aggregate_AggregateType) error {
+	var (
+		first    cursors.Cursor
+		selected struct {
+			ts   int64
+			v    values.Value
+			tags models.Tags
+			ok   bool
+		}
+		typ flux.ColType
+	)
+	for gc.Next() {
+		cur := gc.Cursor()
+		if cur == nil {
+			if err := gc.Err(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if first == nil {
+			first = cur
+		} else if err := checkCursorType(first, cur); err != nil {
+			cur.Close()
+			return err
+		}
 
-	req.Group = convertGroupMode(gi.spec.GroupMode)
-	req.GroupKeys = gi.spec.GroupKeys
+		ts, vs, t, err := readWindowAggregateCursor(cur)
+		stats := cur.Stats()
+		gi.stats.ScannedValues += stats.ScannedValues
+		gi.stats.ScannedBytes += stats.ScannedBytes
+		cur.Close()
+		if err != nil {
+			return err
+		}
+		typ = t
+
+		for i := range ts {
+			if selected.ok && !selectsValue(agg, ts[i], vs[i], selected.ts, selected.v) {
+				if agg == datatypes.AggregateTypeCount || agg == datatypes.AggregateTypeSum {
+					selected.v = addValues(selected.v, vs[i])
+				}
+				continue
+			}
+			selected.ts, selected.v, selected.ok = ts[i], vs[i], true
+			selected.tags = gc.Tags()
+		}
+	}
+	if first == nil {
+		// no series in the group had data
+		return nil
+	}
 
-	if agg, err := determineAggregateMethod(gi.spec.AggregateMethod); err != nil {
-		return err
-	} else if agg != datatypes.AggregateTypeNone {
-		req.Aggregate = &datatypes.Aggregate{Type: agg}
+	bnds := gi.spec.Bounds
+	key := groupKeyForGroup(gc.PartitionKeyVals(), &gi.spec, bnds)
+	builder := execute.NewColListTableBuilder(key, gi.alloc)
+
+	switch agg {
+	case datatypes.AggregateTypeCount, datatypes.AggregateTypeSum:
+		if err := execute.AddTableKeyCols(key, builder); err != nil {
+			return err
+		}
+		valueIdx, err := builder.AddCol(flux.ColMeta{
+			Label: execute.DefaultValueColLabel,
+			Type:  typ,
+		})
+		if err != nil {
+			return err
+		}
+		if err := execute.AppendKeyValues(key, builder); err != nil {
+			return err
+		}
+		switch {
+		case selected.ok:
+			err = builder.AppendValue(valueIdx, selected.v)
+		case agg == datatypes.AggregateTypeCount:
+			err = builder.AppendInt(valueIdx, 0)
+		default:
+			err = builder.AppendNil(valueIdx)
+		}
+		if err != nil {
+			return err
+		}
+	default:
+		cols, defs := determineTableColsForGroup(gc.Keys(), typ)
+		for _, col := range cols {
+			if _, err := builder.AddCol(col); err != nil {
+				return err
+			}
+		}
+		if selected.ok {
+			for j, col := range cols {
+				var v values.Value
+				switch j {
+				case startColIdx:
+					v = values.NewTime(bnds.Start)
+				case stopColIdx:
+					v = values.NewTime(bnds.Stop)
+				case timeColIdx:
+					v = values.NewTime(values.Time(selected.ts))
+				case valueColIdx:
+					v = selected.v
+				default:
+					tag := selected.tags.Get([]byte(col.Label))
+					if tag == nil {
+						tag = defs[j]
+					}
+					v = values.NewString(string(tag))
+				}
+				if err := builder.AppendValue(j, v); err != nil {
+					return err
+				}
+			}
+		}
 	}
 
-	rs, err := gi.s.ReadGroup(gi.ctx, &req)
+	table, err := builder.Table()
 	if err != nil {
 		return err
 	}
+	return f(table)
+}
 
-	if rs == nil {
+// selectsValue returns true if the point at ts with value v replaces the
+// point selected so far by agg. Aggregates that combine every value never
+// select a point.
+func selectsValue(agg datatypes.Aggregate_AggregateType, ts int64, v values.Value, selTs int64, sel values.Value) bool {
+	switch agg {
+	case datatypes.AggregateTypeFirst:
+		return ts < selTs
+	case datatypes.AggregateTypeLast:
+		return ts > selTs
+	case datatypes.AggregateTypeMin:
+		return compareValues(v, sel) < 0
+	case datatypes.AggregateTypeMax:
+		return compareValues(v, sel) > 0
+	default:
+		return false
+	}
+}
+
+func compareValues(a, b values.Value) int {
+	switch a.Type().Nature() {
+	case semantic.Float:
+		return compareFloats(a.Float(), b.Float())
+	case semantic.Int:
+		return compareInts(a.Int(), b.Int())
+	case semantic.UInt:
+		switch x, y := a.UInt(), b.UInt(); {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+func compareFloats(x, y float64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+func compareInts(x, y int64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+func addValues(a, b values.Value) values.Value {
+	switch a.Type().Nature() {
+	case semantic.Float:
+		return values.NewFloat(a.Float() + b.Float())
+	case semantic.Int:
+		return values.NewInt(a.Int() + b.Int())
+	case semantic.UInt:
+		return values.NewUInt(a.UInt() + b.UInt())
+	}
+	panic(fmt.Sprintf("cannot add %v values", a.Type()))
+}
+
+// checkCursorType returns an error if cur reads a different type than first.
+func checkCursorType(first, cur cursors.Cursor) error {
+	var ok bool
+	var typ string
+	switch first.(type) {
+	case cursors.FloatArrayCursor:
+		_, ok = cur.(cursors.FloatArrayCursor)
+		typ = "float"
+	case cursors.IntegerArrayCursor:
+		_, ok = cur.(cursors.IntegerArrayCursor)
+		typ = "integer"
+	case cursors.UnsignedArrayCursor:
+		_, ok = cur.(cursors.UnsignedArrayCursor)
+		typ = "unsigned"
+	case cursors.StringArrayCursor:
+		_, ok = cur.(cursors.StringArrayCursor)
+		typ = "string"
+	case cursors.BooleanArrayCursor:
+		_, ok = cur.(cursors.BooleanArrayCursor)
+		typ = "boolean"
+	}
+	if ok {
 		return nil
 	}
-	return gi.handleRead(f, rs)
+	return &platform.Error{
+		Code: platform.EInvalid,
+		Err: &GroupCursorError{
+			typ:    typ,
+			cursor: cur,
+		},
+	}
 }
 
 func (gi *groupIterator) handleRead(f func(flux.Table) error, rs storage.GroupResultSet) error {
@@ -568,35 +803,16 @@ func (wai *windowAggregateIterator) Do(f func(flux.Table) error) error {
 		return err
 	}
 
-	var predicate *datatypes.Predicate
-	if wai.spec.Predicate != nil {
-		p, err := toStoragePredicate(wai.spec.Predicate)
-		if err != nil {
-			return err
-		}
-		predicate = p
+	req, err := NewReadWindowAggregateRequest(wai.spec)
+	if err != nil {
+		return err
 	}
-
-	var req datatypes.ReadWindowAggregateRequest
 	req.ReadSource = any
-	req.Predicate = predicate
-	req.Range.Start = int64(wai.spec.Bounds.Start)
-	req.Range.End = int64(wai.spec.Bounds.Stop)
-
-	req.WindowEvery = wai.spec.WindowEvery
-	req.Aggregate = make([]*datatypes.Aggregate, len(wai.spec.Aggregates))
-	for i, aggKind := range wai.spec.Aggregates {
-		if agg, err := determineAggregateMethod(aggKind); err != nil {
-			return err
-		} else if agg != datatypes.AggregateTypeNone {
-			req.Aggregate[i] = &datatypes.Aggregate{Type: agg}
-		}
-	}
 
 	if aggStore, ok := wai.s.(storage.WindowAggregateStore); !ok {
 		return errors.New("storage does not support window aggregate.")
 	} else {
-		rs, err := aggStore.WindowAggregate(wai.ctx, &req)
+		rs, err := aggStore.WindowAggregate(wai.ctx, req)
 		if err != nil {
 			return err
 		}
@@ -609,7 +825,203 @@ func (wai *windowAggregateIterator) Do(f func(flux.Table) error) error {
 }
 
 func (wai *windowAggregateIterator) handleRead(f func(flux.Table) error, rs storage.ResultSet) error {
-	return nil
+	defer func() {
+		rs.Close()
+		wai.cache.Release()
+	}()
+
+	every := values.ConvertDuration(time.Duration(wai.spec.WindowEvery))
+	window, err := execute.NewWindow(every, every, values.ConvertDuration(0))
+	if err != nil {
+		return err
+	}
+
+	agg, err := determineAggregateMethod(wai.spec.Aggregates[0])
+	if err != nil {
+		return err
+	}
+
+	for rs.Next() {
+		cur := rs.Cursor()
+		if cur == nil {
+			// no data for series key + field combination
+			continue
+		}
+
+		ts, vs, typ, err := readWindowAggregateCursor(cur)
+		stats := cur.Stats()
+		wai.stats.ScannedValues += stats.ScannedValues
+		wai.stats.ScannedBytes += stats.ScannedBytes
+		cur.Close()
+		if err != nil {
+			return err
+		}
+
+		tags := rs.Tags()
+		if wai.spec.WindowEvery == math.MaxInt64 {
+			// A bare aggregate has a single window spanning the bounds,
+			// for the series with points in the bounds.
+			if len(ts) > 0 {
+				if err := wai.emitWindow(f, agg, wai.spec.Bounds, tags, typ, ts, vs); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if wai.spec.CreateEmpty {
+			i := 0
+			for _, bnds := range window.GetOverlappingBounds(wai.spec.Bounds) {
+				j := i
+				for j < len(ts) && execute.Time(ts[j]) < bnds.Stop {
+					j++
+				}
+				if err := wai.emitWindow(f, agg, bnds, tags, typ, ts[i:j], vs[i:j]); err != nil {
+					return err
+				}
+				i = j
+			}
+			continue
+		}
+
+		for i := 0; i < len(ts); {
+			bnds := window.GetEarliestBounds(execute.Time(ts[i]))
+			j := i + 1
+			for j < len(ts) && execute.Time(ts[j]) < bnds.Stop {
+				j++
+			}
+			if err := wai.emitWindow(f, agg, bnds, tags, typ, ts[i:j], vs[i:j]); err != nil {
+				return err
+			}
+			i = j
+		}
+	}
+	return rs.Err()
+}
+
+// emitWindow passes the table for a single window of a series to f. Tables of
+// aggregates have a single row with the group key and _value columns; tables
+// of selectors have the columns of the series.
+func (wai *windowAggregateIterator) emitWindow(f func(flux.Table) error, agg datatypes.Aggregate_AggregateType, bnds execute.Bounds, tags models.Tags, typ flux.ColType, ts []int64, vs []values.Value) error {
+	bnds = bnds.Intersect(wai.spec.Bounds)
+	key := defaultGroupKeyForSeries(tags, bnds)
+	builder := execute.NewColListTableBuilder(key, wai.alloc)
+
+	switch agg {
+	case datatypes.AggregateTypeCount, datatypes.AggregateTypeSum, datatypes.AggregateTypeMean:
+		if err := execute.AddTableKeyCols(key, builder); err != nil {
+			return err
+		}
+		valueIdx, err := builder.AddCol(flux.ColMeta{
+			Label: execute.DefaultValueColLabel,
+			Type:  typ,
+		})
+		if err != nil {
+			return err
+		}
+		if err := execute.AppendKeyValues(key, builder); err != nil {
+			return err
+		}
+		switch {
+		case len(vs) > 0:
+			err = builder.AppendValue(valueIdx, vs[0])
+		case agg == datatypes.AggregateTypeCount:
+			err = builder.AppendInt(valueIdx, 0)
+		default:
+			err = builder.AppendNil(valueIdx)
+		}
+		if err != nil {
+			return err
+		}
+	default:
+		cols, _ := determineTableColsForSeries(tags, typ)
+		for _, col := range cols {
+			if _, err := builder.AddCol(col); err != nil {
+				return err
+			}
+		}
+		for i := range ts {
+			for j, col := range cols {
+				var v values.Value
+				switch j {
+				case startColIdx:
+					v = values.NewTime(bnds.Start)
+				case stopColIdx:
+					v = values.NewTime(bnds.Stop)
+				case timeColIdx:
+					v = values.NewTime(values.Time(ts[i]))
+				case valueColIdx:
+					v = vs[i]
+				default:
+					v = key.LabelValue(col.Label)
+				}
+				if err := builder.AppendValue(j, v); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	table, err := builder.Table()
+	if err != nil {
+		return err
+	}
+	return f(table)
+}
+
+// readWindowAggregateCursor reads the windows computed by cur. It returns the
+// type of the values the aggregate produced.
+func readWindowAggregateCursor(cur cursors.Cursor) ([]int64, []values.Value, flux.ColType, error) {
+	var (
+		ts  []int64
+		vs  []values.Value
+		typ flux.ColType
+	)
+	switch cur := cur.(type) {
+	case cursors.FloatArrayCursor:
+		typ = flux.TFloat
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			ts = append(ts, a.Timestamps...)
+			for _, v := range a.Values {
+				vs = append(vs, values.NewFloat(v))
+			}
+		}
+	case cursors.IntegerArrayCursor:
+		typ = flux.TInt
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			ts = append(ts, a.Timestamps...)
+			for _, v := range a.Values {
+				vs = append(vs, values.NewInt(v))
+			}
+		}
+	case cursors.UnsignedArrayCursor:
+		typ = flux.TUInt
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			ts = append(ts, a.Timestamps...)
+			for _, v := range a.Values {
+				vs = append(vs, values.NewUInt(v))
+			}
+		}
+	case cursors.StringArrayCursor:
+		typ = flux.TString
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			ts = append(ts, a.Timestamps...)
+			for _, v := range a.Values {
+				vs = append(vs, values.NewString(v))
+			}
+		}
+	case cursors.BooleanArrayCursor:
+		typ = flux.TBool
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			ts = append(ts, a.Timestamps...)
+			for _, v := range a.Values {
+				vs = append(vs, values.NewBool(v))
+			}
+		}
+	default:
+		panic(fmt.Sprintf("unreachable: %T", cur))
+	}
+	return ts, vs, typ, cur.Err()
 }
 
 type tagKeysIterator struct {
@@ -0,0 +1,84 @@
+package storageflux
+
+import (
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/influxdb/v2/kit/errors"
+	"github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+)
+
+// NewReadFilterRequest returns the request that is sent to storage to
+// read the series described by spec. The read source is left unset.
+func NewReadFilterRequest(spec influxdb.ReadFilterSpec) (*datatypes.ReadFilterRequest, error) {
+	predicate, err := newStoragePredicate(spec.Predicate)
+	if err != nil {
+		return nil, err
+	}
+
+	var req datatypes.ReadFilterRequest
+	req.Predicate = predicate
+	req.Range.Start = int64(spec.Bounds.Start)
+	req.Range.End = int64(spec.Bounds.Stop)
+	return &req, nil
+}
+
+// NewReadGroupRequest returns the request that is sent to storage to
+// read the groups described by spec. The read source is left unset.
+func NewReadGroupRequest(spec influxdb.ReadGroupSpec) (*datatypes.ReadGroupRequest, error) {
+	predicate, err := newStoragePredicate(spec.Predicate)
+	if err != nil {
+		return nil, err
+	}
+
+	var req datatypes.ReadGroupRequest
+	req.Predicate = predicate
+	req.Range.Start = int64(spec.Bounds.Start)
+	req.Range.End = int64(spec.Bounds.Stop)
+
+	req.Group = convertGroupMode(spec.GroupMode)
+	req.GroupKeys = spec.GroupKeys
+
+	if agg, err := determineAggregateMethod(spec.AggregateMethod); err != nil {
+		return nil, err
+	} else if agg != datatypes.AggregateTypeNone {
+		req.Aggregate = &datatypes.Aggregate{Type: agg}
+	}
+	return &req, nil
+}
+
+// NewReadWindowAggregateRequest returns the request that is sent to
+// storage to compute the windowed aggregate described by spec. The read
+// source is left unset.
+func NewReadWindowAggregateRequest(spec influxdb.ReadWindowAggregateSpec) (*datatypes.ReadWindowAggregateRequest, error) {
+	predicate, err := newStoragePredicate(spec.Predicate)
+	if err != nil {
+		return nil, err
+	}
+
+	var req datatypes.ReadWindowAggregateRequest
+	req.Predicate = predicate
+	req.Range.Start = int64(spec.Bounds.Start)
+	req.Range.End = int64(spec.Bounds.Stop)
+
+	req.WindowEvery = spec.WindowEvery
+	if len(spec.Aggregates) != 1 {
+		return nil, errors.New("window aggregate requires exactly one aggregate")
+	}
+	req.Aggregate = make([]*datatypes.Aggregate, len(spec.Aggregates))
+	for i, aggKind := range spec.Aggregates {
+		if agg, err := determineAggregateMethod(aggKind); err != nil {
+			return nil, err
+		} else if agg != datatypes.AggregateTypeNone {
+			req.Aggregate[i] = &datatypes.Aggregate{Type: agg}
+		}
+	}
+	return &req, nil
+}
+
+// newStoragePredicate converts the predicate of a read spec, if any.
+func newStoragePredicate(fn *semantic.FunctionExpression) (*datatypes.Predicate, error) {
+	if fn == nil {
+		return nil, nil
+	}
+	return toStoragePredicate(fn)
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/value"
+)
+
+// A WriteObserver is notified when the data of a bucket changes,
+// for example to invalidate the results of queries that read it.
+type WriteObserver interface {
+	// BucketModified is called once points with timestamps in
+	// [min, max] have been written to or deleted from the bucket.
+	BucketModified(orgID, bucketID influxdb.ID, min, max int64)
+}
+
+// observeWrite notifies the write observer, if any, of the time range
+// of the values written to each bucket.
+func (e *Engine) observeWrite(values map[string][]value.Value) {
+	if e.writeObserver == nil {
+		return
+	}
+
+	type bucketKey struct{ orgID, bucketID influxdb.ID }
+	ranges := make(map[bucketKey][2]int64)
+	for k, vs := range values {
+		if len(vs) == 0 {
+			continue
+		}
+		name := models.ParseName([]byte(k))
+		if len(name) < 16 {
+			continue
+		}
+		orgID, bucketID := tsdb.DecodeNameSlice(name)
+		key := bucketKey{orgID: orgID, bucketID: bucketID}
+
+		r, ok := ranges[key]
+		for _, v := range vs {
+			ts := v.UnixNano()
+			if !ok || ts < r[0] {
+				r[0] = ts
+			}
+			if !ok || ts > r[1] {
+				r[1] = ts
+			}
+			ok = true
+		}
+		ranges[key] = r
+	}
+
+	for key, r := range ranges {
+		e.writeObserver.BucketModified(key.orgID, key.bucketID, r[0], r[1])
+	}
+}
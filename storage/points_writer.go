@@ -3,7 +3,10 @@ package storage
 import (
 	"context"
 
+	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage/wal"
+	"github.com/influxdata/influxdb/v2/tsdb"
 )
 
 // PointsWriter describes the ability to write points into a storage engine.
@@ -68,3 +71,65 @@ func (b *BufferedPointsWriter) Flush(ctx context.Context) error {
 	b.n = 0
 	return nil
 }
+
+// DurabilityPointsWriter writes the points of each bucket to the underlying
+// PointsWriter with the WAL durability set on the bucket.
+type DurabilityPointsWriter struct {
+	buckets influxdb.BucketService
+	wr      PointsWriter
+}
+
+// NewDurabilityPointsWriter returns a DurabilityPointsWriter that finds the
+// buckets written to with buckets and writes the points to pointswriter.
+func NewDurabilityPointsWriter(buckets influxdb.BucketService, pointswriter PointsWriter) *DurabilityPointsWriter {
+	return &DurabilityPointsWriter{
+		buckets: buckets,
+		wr:      pointswriter,
+	}
+}
+
+// WritePoints writes the points to the underlying PointsWriter, in a write
+// per durability of the buckets they are written to. Points that are not
+// written to the bucket of an organization use the default durability.
+func (w *DurabilityPointsWriter) WritePoints(ctx context.Context, p []models.Point) error {
+	durabilities := make(map[influxdb.ID]wal.Durability)
+	groups := make(map[wal.Durability][]models.Point)
+	var order []wal.Durability
+	for _, pt := range p {
+		d := wal.DurabilityDefault
+		if name := pt.Name(); len(name) == len(tsdb.EncodeName(0, 0)) {
+			_, bucketID := tsdb.DecodeNameSlice(name)
+			var ok bool
+			if d, ok = durabilities[bucketID]; !ok {
+				b, err := w.buckets.FindBucketByID(ctx, bucketID)
+				if err != nil {
+					return err
+				}
+				if d, err = wal.ParseDurability(string(b.Durability)); err != nil {
+					return err
+				}
+				durabilities[bucketID] = d
+			}
+		}
+		if _, ok := groups[d]; !ok {
+			order = append(order, d)
+		}
+		groups[d] = append(groups[d], pt)
+	}
+
+	// Avoid copying the points when they are all written with the same
+	// durability.
+	if len(order) == 1 {
+		groups[order[0]] = p
+	}
+	for _, d := range order {
+		wctx := ctx
+		if d != wal.DurabilityDefault {
+			wctx = wal.NewContextWithDurability(ctx, d)
+		}
+		if err := w.wr.WritePoints(wctx, groups[d]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/influxdata/influxdb/v2/mock"
 	"github.com/influxdata/influxdb/v2/models"
 	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/storage/wal"
 	"github.com/influxdata/influxdb/v2/tsdb"
 )
 
@@ -109,6 +110,59 @@ e day="Saturday",humidity=6,ratio=0.1,temperature=99 51
 	})
 }
 
+type durabilityWrite struct {
+	durability wal.Durability
+	points     []models.Point
+}
+
+type durabilityPointsWriter []durabilityWrite
+
+func (w *durabilityPointsWriter) WritePoints(ctx context.Context, points []models.Point) error {
+	*w = append(*w, durabilityWrite{durability: wal.DurabilityFromContext(ctx), points: points})
+	return nil
+}
+
+func TestDurabilityPointsWriter(t *testing.T) {
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+		switch id {
+		case 2:
+			return &influxdb.Bucket{ID: id}, nil
+		case 3:
+			return &influxdb.Bucket{ID: id, Durability: influxdb.BucketDurabilityNone}, nil
+		}
+		return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "bucket not found"}
+	}
+
+	t.Run("writes per durability", func(t *testing.T) {
+		var pw durabilityPointsWriter
+		w := storage.NewDurabilityPointsWriter(buckets, &pw)
+		points := append(mockPoints(1, 2, "a v=1 1\nb v=2 2"), mockPoints(1, 3, "a v=3 3")...)
+		if err := w.WritePoints(context.Background(), points); err != nil {
+			t.Fatal(err)
+		}
+		if len(pw) != 2 {
+			t.Fatalf("expected 2 writes, got %d", len(pw))
+		}
+		if got := pw[0]; got.durability != wal.DurabilityDefault || len(got.points) != 2 {
+			t.Errorf("unexpected first write: durability %q with %d points", got.durability, len(got.points))
+		}
+		if got := pw[1]; got.durability != wal.DurabilityNone || len(got.points) != 1 {
+			t.Errorf("unexpected second write: durability %q with %d points", got.durability, len(got.points))
+		}
+	})
+	t.Run("unknown bucket", func(t *testing.T) {
+		var pw durabilityPointsWriter
+		w := storage.NewDurabilityPointsWriter(buckets, &pw)
+		if err := w.WritePoints(context.Background(), mockPoints(1, 4, "a v=1 1")); influxdb.ErrorCode(err) != influxdb.ENotFound {
+			t.Errorf("expected not found error, got %v", err)
+		}
+		if len(pw) != 0 {
+			t.Errorf("expected no writes, got %d", len(pw))
+		}
+	})
+}
+
 func mockPoints(org, bucket influxdb.ID, pointdata string) []models.Point {
 	name := tsdb.EncodeName(org, bucket)
 	points, err := models.ParsePoints([]byte(pointdata), name[:])
@@ -9,6 +9,7 @@ package reads
 import (
 	"errors"
 
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
 	"github.com/influxdata/influxdb/v2/tsdb/cursors"
 )
 
@@ -228,6 +229,358 @@ func (c *integerFloatCountArrayCursor) Next() *cursors.IntegerArray {
 	}
 }
 
+type integerFloatWindowCountArrayCursor struct {
+	cursors.FloatArrayCursor
+	every int64
+	res   *cursors.IntegerArray
+	tmp   *cursors.FloatArray
+}
+
+func newIntegerFloatWindowCountArrayCursor(cur cursors.FloatArrayCursor, every int64) *integerFloatWindowCountArrayCursor {
+	return &integerFloatWindowCountArrayCursor{
+		FloatArrayCursor: cur,
+		every:            every,
+		res:              cursors.NewIntegerArrayLen(MaxPointsPerBlock),
+		tmp:              &cursors.FloatArray{},
+	}
+}
+
+func (c *integerFloatWindowCountArrayCursor) Stats() cursors.CursorStats {
+	return c.FloatArrayCursor.Stats()
+}
+
+func (c *integerFloatWindowCountArrayCursor) Next() *cursors.IntegerArray {
+	pos := 0
+	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
+	c.res.Values = c.res.Values[:cap(c.res.Values)]
+
+	var a *cursors.FloatArray
+	if c.tmp.Len() > 0 {
+		a = c.tmp
+	} else {
+		a = c.FloatArrayCursor.Next()
+	}
+
+	var (
+		start int64
+		acc   int64
+		ok    bool
+	)
+
+LOOP:
+	for len(a.Timestamps) > 0 {
+		for i, t := range a.Timestamps {
+			if ws := windowStart(t, c.every); ok && ws != start {
+				c.res.Timestamps[pos] = start
+				c.res.Values[pos] = acc
+				pos++
+				ok = false
+				if pos >= MaxPointsPerBlock {
+					c.tmp.Timestamps = a.Timestamps[i:]
+					c.tmp.Values = a.Values[i:]
+					break LOOP
+				}
+			}
+			if !ok {
+				start, acc, ok = windowStart(t, c.every), 0, true
+			}
+			acc++
+		}
+
+		c.tmp.Timestamps = nil
+		c.tmp.Values = nil
+
+		a = c.FloatArrayCursor.Next()
+	}
+
+	if ok {
+		c.res.Timestamps[pos] = start
+		c.res.Values[pos] = acc
+		pos++
+	}
+
+	c.res.Timestamps = c.res.Timestamps[:pos]
+	c.res.Values = c.res.Values[:pos]
+	return c.res
+}
+
+type floatWindowSumArrayCursor struct {
+	cursors.FloatArrayCursor
+	every int64
+	res   *cursors.FloatArray
+	tmp   *cursors.FloatArray
+}
+
+func newFloatWindowSumArrayCursor(cur cursors.FloatArrayCursor, every int64) *floatWindowSumArrayCursor {
+	return &floatWindowSumArrayCursor{
+		FloatArrayCursor: cur,
+		every:            every,
+		res:              cursors.NewFloatArrayLen(MaxPointsPerBlock),
+		tmp:              &cursors.FloatArray{},
+	}
+}
+
+func (c *floatWindowSumArrayCursor) Stats() cursors.CursorStats {
+	return c.FloatArrayCursor.Stats()
+}
+
+func (c *floatWindowSumArrayCursor) Next() *cursors.FloatArray {
+	pos := 0
+	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
+	c.res.Values = c.res.Values[:cap(c.res.Values)]
+
+	var a *cursors.FloatArray
+	if c.tmp.Len() > 0 {
+		a = c.tmp
+	} else {
+		a = c.FloatArrayCursor.Next()
+	}
+
+	var (
+		start int64
+		acc   float64
+		ok    bool
+	)
+
+LOOP:
+	for len(a.Timestamps) > 0 {
+		for i, t := range a.Timestamps {
+			if ws := windowStart(t, c.every); ok && ws != start {
+				c.res.Timestamps[pos] = start
+				c.res.Values[pos] = acc
+				pos++
+				ok = false
+				if pos >= MaxPointsPerBlock {
+					c.tmp.Timestamps = a.Timestamps[i:]
+					c.tmp.Values = a.Values[i:]
+					break LOOP
+				}
+			}
+			if !ok {
+				start, acc, ok = windowStart(t, c.every), 0, true
+			}
+			acc += a.Values[i]
+		}
+
+		c.tmp.Timestamps = nil
+		c.tmp.Values = nil
+
+		a = c.FloatArrayCursor.Next()
+	}
+
+	if ok {
+		c.res.Timestamps[pos] = start
+		c.res.Values[pos] = acc
+		pos++
+	}
+
+	c.res.Timestamps = c.res.Timestamps[:pos]
+	c.res.Values = c.res.Values[:pos]
+	return c.res
+}
+
+type floatFloatWindowMeanArrayCursor struct {
+	cursors.FloatArrayCursor
+	every int64
+	res   *cursors.FloatArray
+	tmp   *cursors.FloatArray
+}
+
+func newFloatFloatWindowMeanArrayCursor(cur cursors.FloatArrayCursor, every int64) *floatFloatWindowMeanArrayCursor {
+	return &floatFloatWindowMeanArrayCursor{
+		FloatArrayCursor: cur,
+		every:            every,
+		res:              cursors.NewFloatArrayLen(MaxPointsPerBlock),
+		tmp:              &cursors.FloatArray{},
+	}
+}
+
+func (c *floatFloatWindowMeanArrayCursor) Stats() cursors.CursorStats {
+	return c.FloatArrayCursor.Stats()
+}
+
+func (c *floatFloatWindowMeanArrayCursor) Next() *cursors.FloatArray {
+	pos := 0
+	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
+	c.res.Values = c.res.Values[:cap(c.res.Values)]
+
+	var a *cursors.FloatArray
+	if c.tmp.Len() > 0 {
+		a = c.tmp
+	} else {
+		a = c.FloatArrayCursor.Next()
+	}
+
+	var (
+		start int64
+		sum   float64
+		n     int64
+		ok    bool
+	)
+
+LOOP:
+	for len(a.Timestamps) > 0 {
+		for i, t := range a.Timestamps {
+			if ws := windowStart(t, c.every); ok && ws != start {
+				c.res.Timestamps[pos] = start
+				c.res.Values[pos] = sum / float64(n)
+				pos++
+				ok = false
+				if pos >= MaxPointsPerBlock {
+					c.tmp.Timestamps = a.Timestamps[i:]
+					c.tmp.Values = a.Values[i:]
+					break LOOP
+				}
+			}
+			if !ok {
+				start, sum, n, ok = windowStart(t, c.every), 0, 0, true
+			}
+			sum += float64(a.Values[i])
+			n++
+		}
+
+		c.tmp.Timestamps = nil
+		c.tmp.Values = nil
+
+		a = c.FloatArrayCursor.Next()
+	}
+
+	if ok {
+		c.res.Timestamps[pos] = start
+		c.res.Values[pos] = sum / float64(n)
+		pos++
+	}
+
+	c.res.Timestamps = c.res.Timestamps[:pos]
+	c.res.Values = c.res.Values[:pos]
+	return c.res
+}
+
+// floatWindowSelectorArrayCursor selects a single point of each window.
+// Selected points keep their own timestamp.
+type floatWindowSelectorArrayCursor struct {
+	cursors.FloatArrayCursor
+	agg   datatypes.Aggregate_AggregateType
+	every int64
+	res   *cursors.FloatArray
+	tmp   *cursors.FloatArray
+}
+
+func newFloatWindowSelectorArrayCursor(cur cursors.FloatArrayCursor, agg datatypes.Aggregate_AggregateType, every int64) *floatWindowSelectorArrayCursor {
+	return &floatWindowSelectorArrayCursor{
+		FloatArrayCursor: cur,
+		agg:              agg,
+		every:            every,
+		res:              cursors.NewFloatArrayLen(MaxPointsPerBlock),
+		tmp:              &cursors.FloatArray{},
+	}
+}
+
+func (c *floatWindowSelectorArrayCursor) Stats() cursors.CursorStats {
+	return c.FloatArrayCursor.Stats()
+}
+
+func (c *floatWindowSelectorArrayCursor) Next() *cursors.FloatArray {
+	pos := 0
+	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
+	c.res.Values = c.res.Values[:cap(c.res.Values)]
+
+	var a *cursors.FloatArray
+	if c.tmp.Len() > 0 {
+		a = c.tmp
+	} else {
+		a = c.FloatArrayCursor.Next()
+	}
+
+	var (
+		start int64
+		ts    int64
+		v     float64
+		ok    bool
+	)
+
+LOOP:
+	for len(a.Timestamps) > 0 {
+		for i, t := range a.Timestamps {
+			if ws := windowStart(t, c.every); ok && ws != start {
+				c.res.Timestamps[pos] = ts
+				c.res.Values[pos] = v
+				pos++
+				ok = false
+				if pos >= MaxPointsPerBlock {
+					c.tmp.Timestamps = a.Timestamps[i:]
+					c.tmp.Values = a.Values[i:]
+					break LOOP
+				}
+			}
+			if !ok {
+				start, ts, v, ok = windowStart(t, c.every), t, a.Values[i], true
+				continue
+			}
+			switch c.agg {
+			case datatypes.AggregateTypeLast:
+				ts, v = t, a.Values[i]
+			case datatypes.AggregateTypeMin:
+				if a.Values[i] < v {
+					ts, v = t, a.Values[i]
+				}
+			case datatypes.AggregateTypeMax:
+				if a.Values[i] > v {
+					ts, v = t, a.Values[i]
+				}
+			}
+		}
+
+		c.tmp.Timestamps = nil
+		c.tmp.Values = nil
+
+		a = c.FloatArrayCursor.Next()
+	}
+
+	if ok {
+		c.res.Timestamps[pos] = ts
+		c.res.Values[pos] = v
+		pos++
+	}
+
+	c.res.Timestamps = c.res.Timestamps[:pos]
+	c.res.Values = c.res.Values[:pos]
+	return c.res
+}
+
+// floatLimitArrayCursor reads only the first point of a cursor, so
+// that no further blocks are decoded.
+type floatLimitArrayCursor struct {
+	cursors.FloatArrayCursor
+	res  *cursors.FloatArray
+	done bool
+}
+
+func newFloatLimitArrayCursor(cur cursors.FloatArrayCursor) *floatLimitArrayCursor {
+	return &floatLimitArrayCursor{
+		FloatArrayCursor: cur,
+		res:              cursors.NewFloatArrayLen(1),
+	}
+}
+
+func (c *floatLimitArrayCursor) Stats() cursors.CursorStats {
+	return c.FloatArrayCursor.Stats()
+}
+
+func (c *floatLimitArrayCursor) Next() *cursors.FloatArray {
+	if c.done {
+		return &cursors.FloatArray{}
+	}
+	a := c.FloatArrayCursor.Next()
+	if a.Len() == 0 {
+		return a
+	}
+	c.done = true
+	c.res.Timestamps[0] = a.Timestamps[0]
+	c.res.Values[0] = a.Values[0]
+	return c.res
+}
+
 type floatEmptyArrayCursor struct {
 	res cursors.FloatArray
 }
@@ -448,79 +801,431 @@ func (c *integerIntegerCountArrayCursor) Next() *cursors.IntegerArray {
 	}
 }
 
-type integerEmptyArrayCursor struct {
-	res cursors.IntegerArray
-}
-
-var IntegerEmptyArrayCursor cursors.IntegerArrayCursor = &integerEmptyArrayCursor{}
-
-func (c *integerEmptyArrayCursor) Err() error                  { return nil }
-func (c *integerEmptyArrayCursor) Close()                      {}
-func (c *integerEmptyArrayCursor) Stats() cursors.CursorStats  { return cursors.CursorStats{} }
-func (c *integerEmptyArrayCursor) Next() *cursors.IntegerArray { return &c.res }
-
-// ********************
-// Unsigned Array Cursor
-
-type unsignedArrayFilterCursor struct {
-	cursors.UnsignedArrayCursor
-	cond expression
-	m    *singleValue
-	res  *cursors.UnsignedArray
-	tmp  *cursors.UnsignedArray
+type integerIntegerWindowCountArrayCursor struct {
+	cursors.IntegerArrayCursor
+	every int64
+	res   *cursors.IntegerArray
+	tmp   *cursors.IntegerArray
 }
 
-func newUnsignedFilterArrayCursor(cond expression) *unsignedArrayFilterCursor {
-	return &unsignedArrayFilterCursor{
-		cond: cond,
-		m:    &singleValue{},
-		res:  cursors.NewUnsignedArrayLen(MaxPointsPerBlock),
-		tmp:  &cursors.UnsignedArray{},
+func newIntegerIntegerWindowCountArrayCursor(cur cursors.IntegerArrayCursor, every int64) *integerIntegerWindowCountArrayCursor {
+	return &integerIntegerWindowCountArrayCursor{
+		IntegerArrayCursor: cur,
+		every:              every,
+		res:                cursors.NewIntegerArrayLen(MaxPointsPerBlock),
+		tmp:                &cursors.IntegerArray{},
 	}
 }
 
-func (c *unsignedArrayFilterCursor) reset(cur cursors.UnsignedArrayCursor) {
-	c.UnsignedArrayCursor = cur
-	c.tmp.Timestamps, c.tmp.Values = nil, nil
+func (c *integerIntegerWindowCountArrayCursor) Stats() cursors.CursorStats {
+	return c.IntegerArrayCursor.Stats()
 }
 
-func (c *unsignedArrayFilterCursor) Stats() cursors.CursorStats { return c.UnsignedArrayCursor.Stats() }
-
-func (c *unsignedArrayFilterCursor) Next() *cursors.UnsignedArray {
+func (c *integerIntegerWindowCountArrayCursor) Next() *cursors.IntegerArray {
 	pos := 0
 	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
 	c.res.Values = c.res.Values[:cap(c.res.Values)]
 
-	var a *cursors.UnsignedArray
-
+	var a *cursors.IntegerArray
 	if c.tmp.Len() > 0 {
 		a = c.tmp
 	} else {
-		a = c.UnsignedArrayCursor.Next()
+		a = c.IntegerArrayCursor.Next()
 	}
 
+	var (
+		start int64
+		acc   int64
+		ok    bool
+	)
+
 LOOP:
 	for len(a.Timestamps) > 0 {
-		for i, v := range a.Values {
-			c.m.v = v
-			if c.cond.EvalBool(c.m) {
-				c.res.Timestamps[pos] = a.Timestamps[i]
-				c.res.Values[pos] = v
+		for i, t := range a.Timestamps {
+			if ws := windowStart(t, c.every); ok && ws != start {
+				c.res.Timestamps[pos] = start
+				c.res.Values[pos] = acc
 				pos++
+				ok = false
 				if pos >= MaxPointsPerBlock {
-					c.tmp.Timestamps = a.Timestamps[i+1:]
-					c.tmp.Values = a.Values[i+1:]
+					c.tmp.Timestamps = a.Timestamps[i:]
+					c.tmp.Values = a.Values[i:]
 					break LOOP
 				}
 			}
+			if !ok {
+				start, acc, ok = windowStart(t, c.every), 0, true
+			}
+			acc++
 		}
 
-		// Clear bufferred timestamps & values if we make it through a cursor.
-		// The break above will skip this if a cursor is partially read.
 		c.tmp.Timestamps = nil
 		c.tmp.Values = nil
 
-		a = c.UnsignedArrayCursor.Next()
+		a = c.IntegerArrayCursor.Next()
+	}
+
+	if ok {
+		c.res.Timestamps[pos] = start
+		c.res.Values[pos] = acc
+		pos++
+	}
+
+	c.res.Timestamps = c.res.Timestamps[:pos]
+	c.res.Values = c.res.Values[:pos]
+	return c.res
+}
+
+type integerWindowSumArrayCursor struct {
+	cursors.IntegerArrayCursor
+	every int64
+	res   *cursors.IntegerArray
+	tmp   *cursors.IntegerArray
+}
+
+func newIntegerWindowSumArrayCursor(cur cursors.IntegerArrayCursor, every int64) *integerWindowSumArrayCursor {
+	return &integerWindowSumArrayCursor{
+		IntegerArrayCursor: cur,
+		every:              every,
+		res:                cursors.NewIntegerArrayLen(MaxPointsPerBlock),
+		tmp:                &cursors.IntegerArray{},
+	}
+}
+
+func (c *integerWindowSumArrayCursor) Stats() cursors.CursorStats {
+	return c.IntegerArrayCursor.Stats()
+}
+
+func (c *integerWindowSumArrayCursor) Next() *cursors.IntegerArray {
+	pos := 0
+	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
+	c.res.Values = c.res.Values[:cap(c.res.Values)]
+
+	var a *cursors.IntegerArray
+	if c.tmp.Len() > 0 {
+		a = c.tmp
+	} else {
+		a = c.IntegerArrayCursor.Next()
+	}
+
+	var (
+		start int64
+		acc   int64
+		ok    bool
+	)
+
+LOOP:
+	for len(a.Timestamps) > 0 {
+		for i, t := range a.Timestamps {
+			if ws := windowStart(t, c.every); ok && ws != start {
+				c.res.Timestamps[pos] = start
+				c.res.Values[pos] = acc
+				pos++
+				ok = false
+				if pos >= MaxPointsPerBlock {
+					c.tmp.Timestamps = a.Timestamps[i:]
+					c.tmp.Values = a.Values[i:]
+					break LOOP
+				}
+			}
+			if !ok {
+				start, acc, ok = windowStart(t, c.every), 0, true
+			}
+			acc += a.Values[i]
+		}
+
+		c.tmp.Timestamps = nil
+		c.tmp.Values = nil
+
+		a = c.IntegerArrayCursor.Next()
+	}
+
+	if ok {
+		c.res.Timestamps[pos] = start
+		c.res.Values[pos] = acc
+		pos++
+	}
+
+	c.res.Timestamps = c.res.Timestamps[:pos]
+	c.res.Values = c.res.Values[:pos]
+	return c.res
+}
+
+type floatIntegerWindowMeanArrayCursor struct {
+	cursors.IntegerArrayCursor
+	every int64
+	res   *cursors.FloatArray
+	tmp   *cursors.IntegerArray
+}
+
+func newFloatIntegerWindowMeanArrayCursor(cur cursors.IntegerArrayCursor, every int64) *floatIntegerWindowMeanArrayCursor {
+	return &floatIntegerWindowMeanArrayCursor{
+		IntegerArrayCursor: cur,
+		every:              every,
+		res:                cursors.NewFloatArrayLen(MaxPointsPerBlock),
+		tmp:                &cursors.IntegerArray{},
+	}
+}
+
+func (c *floatIntegerWindowMeanArrayCursor) Stats() cursors.CursorStats {
+	return c.IntegerArrayCursor.Stats()
+}
+
+func (c *floatIntegerWindowMeanArrayCursor) Next() *cursors.FloatArray {
+	pos := 0
+	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
+	c.res.Values = c.res.Values[:cap(c.res.Values)]
+
+	var a *cursors.IntegerArray
+	if c.tmp.Len() > 0 {
+		a = c.tmp
+	} else {
+		a = c.IntegerArrayCursor.Next()
+	}
+
+	var (
+		start int64
+		sum   float64
+		n     int64
+		ok    bool
+	)
+
+LOOP:
+	for len(a.Timestamps) > 0 {
+		for i, t := range a.Timestamps {
+			if ws := windowStart(t, c.every); ok && ws != start {
+				c.res.Timestamps[pos] = start
+				c.res.Values[pos] = sum / float64(n)
+				pos++
+				ok = false
+				if pos >= MaxPointsPerBlock {
+					c.tmp.Timestamps = a.Timestamps[i:]
+					c.tmp.Values = a.Values[i:]
+					break LOOP
+				}
+			}
+			if !ok {
+				start, sum, n, ok = windowStart(t, c.every), 0, 0, true
+			}
+			sum += float64(a.Values[i])
+			n++
+		}
+
+		c.tmp.Timestamps = nil
+		c.tmp.Values = nil
+
+		a = c.IntegerArrayCursor.Next()
+	}
+
+	if ok {
+		c.res.Timestamps[pos] = start
+		c.res.Values[pos] = sum / float64(n)
+		pos++
+	}
+
+	c.res.Timestamps = c.res.Timestamps[:pos]
+	c.res.Values = c.res.Values[:pos]
+	return c.res
+}
+
+// integerWindowSelectorArrayCursor selects a single point of each window.
+// Selected points keep their own timestamp.
+type integerWindowSelectorArrayCursor struct {
+	cursors.IntegerArrayCursor
+	agg   datatypes.Aggregate_AggregateType
+	every int64
+	res   *cursors.IntegerArray
+	tmp   *cursors.IntegerArray
+}
+
+func newIntegerWindowSelectorArrayCursor(cur cursors.IntegerArrayCursor, agg datatypes.Aggregate_AggregateType, every int64) *integerWindowSelectorArrayCursor {
+	return &integerWindowSelectorArrayCursor{
+		IntegerArrayCursor: cur,
+		agg:                agg,
+		every:              every,
+		res:                cursors.NewIntegerArrayLen(MaxPointsPerBlock),
+		tmp:                &cursors.IntegerArray{},
+	}
+}
+
+func (c *integerWindowSelectorArrayCursor) Stats() cursors.CursorStats {
+	return c.IntegerArrayCursor.Stats()
+}
+
+func (c *integerWindowSelectorArrayCursor) Next() *cursors.IntegerArray {
+	pos := 0
+	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
+	c.res.Values = c.res.Values[:cap(c.res.Values)]
+
+	var a *cursors.IntegerArray
+	if c.tmp.Len() > 0 {
+		a = c.tmp
+	} else {
+		a = c.IntegerArrayCursor.Next()
+	}
+
+	var (
+		start int64
+		ts    int64
+		v     int64
+		ok    bool
+	)
+
+LOOP:
+	for len(a.Timestamps) > 0 {
+		for i, t := range a.Timestamps {
+			if ws := windowStart(t, c.every); ok && ws != start {
+				c.res.Timestamps[pos] = ts
+				c.res.Values[pos] = v
+				pos++
+				ok = false
+				if pos >= MaxPointsPerBlock {
+					c.tmp.Timestamps = a.Timestamps[i:]
+					c.tmp.Values = a.Values[i:]
+					break LOOP
+				}
+			}
+			if !ok {
+				start, ts, v, ok = windowStart(t, c.every), t, a.Values[i], true
+				continue
+			}
+			switch c.agg {
+			case datatypes.AggregateTypeLast:
+				ts, v = t, a.Values[i]
+			case datatypes.AggregateTypeMin:
+				if a.Values[i] < v {
+					ts, v = t, a.Values[i]
+				}
+			case datatypes.AggregateTypeMax:
+				if a.Values[i] > v {
+					ts, v = t, a.Values[i]
+				}
+			}
+		}
+
+		c.tmp.Timestamps = nil
+		c.tmp.Values = nil
+
+		a = c.IntegerArrayCursor.Next()
+	}
+
+	if ok {
+		c.res.Timestamps[pos] = ts
+		c.res.Values[pos] = v
+		pos++
+	}
+
+	c.res.Timestamps = c.res.Timestamps[:pos]
+	c.res.Values = c.res.Values[:pos]
+	return c.res
+}
+
+// integerLimitArrayCursor reads only the first point of a cursor, so
+// that no further blocks are decoded.
+type integerLimitArrayCursor struct {
+	cursors.IntegerArrayCursor
+	res  *cursors.IntegerArray
+	done bool
+}
+
+func newIntegerLimitArrayCursor(cur cursors.IntegerArrayCursor) *integerLimitArrayCursor {
+	return &integerLimitArrayCursor{
+		IntegerArrayCursor: cur,
+		res:                cursors.NewIntegerArrayLen(1),
+	}
+}
+
+func (c *integerLimitArrayCursor) Stats() cursors.CursorStats {
+	return c.IntegerArrayCursor.Stats()
+}
+
+func (c *integerLimitArrayCursor) Next() *cursors.IntegerArray {
+	if c.done {
+		return &cursors.IntegerArray{}
+	}
+	a := c.IntegerArrayCursor.Next()
+	if a.Len() == 0 {
+		return a
+	}
+	c.done = true
+	c.res.Timestamps[0] = a.Timestamps[0]
+	c.res.Values[0] = a.Values[0]
+	return c.res
+}
+
+type integerEmptyArrayCursor struct {
+	res cursors.IntegerArray
+}
+
+var IntegerEmptyArrayCursor cursors.IntegerArrayCursor = &integerEmptyArrayCursor{}
+
+func (c *integerEmptyArrayCursor) Err() error                  { return nil }
+func (c *integerEmptyArrayCursor) Close()                      {}
+func (c *integerEmptyArrayCursor) Stats() cursors.CursorStats  { return cursors.CursorStats{} }
+func (c *integerEmptyArrayCursor) Next() *cursors.IntegerArray { return &c.res }
+
+// ********************
+// Unsigned Array Cursor
+
+type unsignedArrayFilterCursor struct {
+	cursors.UnsignedArrayCursor
+	cond expression
+	m    *singleValue
+	res  *cursors.UnsignedArray
+	tmp  *cursors.UnsignedArray
+}
+
+func newUnsignedFilterArrayCursor(cond expression) *unsignedArrayFilterCursor {
+	return &unsignedArrayFilterCursor{
+		cond: cond,
+		m:    &singleValue{},
+		res:  cursors.NewUnsignedArrayLen(MaxPointsPerBlock),
+		tmp:  &cursors.UnsignedArray{},
+	}
+}
+
+func (c *unsignedArrayFilterCursor) reset(cur cursors.UnsignedArrayCursor) {
+	c.UnsignedArrayCursor = cur
+	c.tmp.Timestamps, c.tmp.Values = nil, nil
+}
+
+func (c *unsignedArrayFilterCursor) Stats() cursors.CursorStats { return c.UnsignedArrayCursor.Stats() }
+
+func (c *unsignedArrayFilterCursor) Next() *cursors.UnsignedArray {
+	pos := 0
+	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
+	c.res.Values = c.res.Values[:cap(c.res.Values)]
+
+	var a *cursors.UnsignedArray
+
+	if c.tmp.Len() > 0 {
+		a = c.tmp
+	} else {
+		a = c.UnsignedArrayCursor.Next()
+	}
+
+LOOP:
+	for len(a.Timestamps) > 0 {
+		for i, v := range a.Values {
+			c.m.v = v
+			if c.cond.EvalBool(c.m) {
+				c.res.Timestamps[pos] = a.Timestamps[i]
+				c.res.Values[pos] = v
+				pos++
+				if pos >= MaxPointsPerBlock {
+					c.tmp.Timestamps = a.Timestamps[i+1:]
+					c.tmp.Values = a.Values[i+1:]
+					break LOOP
+				}
+			}
+		}
+
+		// Clear bufferred timestamps & values if we make it through a cursor.
+		// The break above will skip this if a cursor is partially read.
+		c.tmp.Timestamps = nil
+		c.tmp.Values = nil
+
+		a = c.UnsignedArrayCursor.Next()
 	}
 
 	c.res.Timestamps = c.res.Timestamps[:pos]
@@ -630,42 +1335,394 @@ func (c unsignedArraySumCursor) Next() *cursors.UnsignedArray {
 			acc += v
 		}
 		a = c.UnsignedArrayCursor.Next()
-		if len(a.Timestamps) == 0 {
-			c.ts[0] = ts
-			c.vs[0] = acc
-			c.res.Timestamps = c.ts[:]
-			c.res.Values = c.vs[:]
-			return c.res
-		}
+		if len(a.Timestamps) == 0 {
+			c.ts[0] = ts
+			c.vs[0] = acc
+			c.res.Timestamps = c.ts[:]
+			c.res.Values = c.vs[:]
+			return c.res
+		}
+	}
+}
+
+type integerUnsignedCountArrayCursor struct {
+	cursors.UnsignedArrayCursor
+}
+
+func (c *integerUnsignedCountArrayCursor) Stats() cursors.CursorStats {
+	return c.UnsignedArrayCursor.Stats()
+}
+
+func (c *integerUnsignedCountArrayCursor) Next() *cursors.IntegerArray {
+	a := c.UnsignedArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return &cursors.IntegerArray{}
+	}
+
+	ts := a.Timestamps[0]
+	var acc int64
+	for {
+		acc += int64(len(a.Timestamps))
+		a = c.UnsignedArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			res := cursors.NewIntegerArrayLen(1)
+			res.Timestamps[0] = ts
+			res.Values[0] = acc
+			return res
+		}
+	}
+}
+
+type integerUnsignedWindowCountArrayCursor struct {
+	cursors.UnsignedArrayCursor
+	every int64
+	res   *cursors.IntegerArray
+	tmp   *cursors.UnsignedArray
+}
+
+func newIntegerUnsignedWindowCountArrayCursor(cur cursors.UnsignedArrayCursor, every int64) *integerUnsignedWindowCountArrayCursor {
+	return &integerUnsignedWindowCountArrayCursor{
+		UnsignedArrayCursor: cur,
+		every:               every,
+		res:                 cursors.NewIntegerArrayLen(MaxPointsPerBlock),
+		tmp:                 &cursors.UnsignedArray{},
+	}
+}
+
+func (c *integerUnsignedWindowCountArrayCursor) Stats() cursors.CursorStats {
+	return c.UnsignedArrayCursor.Stats()
+}
+
+func (c *integerUnsignedWindowCountArrayCursor) Next() *cursors.IntegerArray {
+	pos := 0
+	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
+	c.res.Values = c.res.Values[:cap(c.res.Values)]
+
+	var a *cursors.UnsignedArray
+	if c.tmp.Len() > 0 {
+		a = c.tmp
+	} else {
+		a = c.UnsignedArrayCursor.Next()
+	}
+
+	var (
+		start int64
+		acc   int64
+		ok    bool
+	)
+
+LOOP:
+	for len(a.Timestamps) > 0 {
+		for i, t := range a.Timestamps {
+			if ws := windowStart(t, c.every); ok && ws != start {
+				c.res.Timestamps[pos] = start
+				c.res.Values[pos] = acc
+				pos++
+				ok = false
+				if pos >= MaxPointsPerBlock {
+					c.tmp.Timestamps = a.Timestamps[i:]
+					c.tmp.Values = a.Values[i:]
+					break LOOP
+				}
+			}
+			if !ok {
+				start, acc, ok = windowStart(t, c.every), 0, true
+			}
+			acc++
+		}
+
+		c.tmp.Timestamps = nil
+		c.tmp.Values = nil
+
+		a = c.UnsignedArrayCursor.Next()
+	}
+
+	if ok {
+		c.res.Timestamps[pos] = start
+		c.res.Values[pos] = acc
+		pos++
+	}
+
+	c.res.Timestamps = c.res.Timestamps[:pos]
+	c.res.Values = c.res.Values[:pos]
+	return c.res
+}
+
+type unsignedWindowSumArrayCursor struct {
+	cursors.UnsignedArrayCursor
+	every int64
+	res   *cursors.UnsignedArray
+	tmp   *cursors.UnsignedArray
+}
+
+func newUnsignedWindowSumArrayCursor(cur cursors.UnsignedArrayCursor, every int64) *unsignedWindowSumArrayCursor {
+	return &unsignedWindowSumArrayCursor{
+		UnsignedArrayCursor: cur,
+		every:               every,
+		res:                 cursors.NewUnsignedArrayLen(MaxPointsPerBlock),
+		tmp:                 &cursors.UnsignedArray{},
+	}
+}
+
+func (c *unsignedWindowSumArrayCursor) Stats() cursors.CursorStats {
+	return c.UnsignedArrayCursor.Stats()
+}
+
+func (c *unsignedWindowSumArrayCursor) Next() *cursors.UnsignedArray {
+	pos := 0
+	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
+	c.res.Values = c.res.Values[:cap(c.res.Values)]
+
+	var a *cursors.UnsignedArray
+	if c.tmp.Len() > 0 {
+		a = c.tmp
+	} else {
+		a = c.UnsignedArrayCursor.Next()
+	}
+
+	var (
+		start int64
+		acc   uint64
+		ok    bool
+	)
+
+LOOP:
+	for len(a.Timestamps) > 0 {
+		for i, t := range a.Timestamps {
+			if ws := windowStart(t, c.every); ok && ws != start {
+				c.res.Timestamps[pos] = start
+				c.res.Values[pos] = acc
+				pos++
+				ok = false
+				if pos >= MaxPointsPerBlock {
+					c.tmp.Timestamps = a.Timestamps[i:]
+					c.tmp.Values = a.Values[i:]
+					break LOOP
+				}
+			}
+			if !ok {
+				start, acc, ok = windowStart(t, c.every), 0, true
+			}
+			acc += a.Values[i]
+		}
+
+		c.tmp.Timestamps = nil
+		c.tmp.Values = nil
+
+		a = c.UnsignedArrayCursor.Next()
+	}
+
+	if ok {
+		c.res.Timestamps[pos] = start
+		c.res.Values[pos] = acc
+		pos++
+	}
+
+	c.res.Timestamps = c.res.Timestamps[:pos]
+	c.res.Values = c.res.Values[:pos]
+	return c.res
+}
+
+type floatUnsignedWindowMeanArrayCursor struct {
+	cursors.UnsignedArrayCursor
+	every int64
+	res   *cursors.FloatArray
+	tmp   *cursors.UnsignedArray
+}
+
+func newFloatUnsignedWindowMeanArrayCursor(cur cursors.UnsignedArrayCursor, every int64) *floatUnsignedWindowMeanArrayCursor {
+	return &floatUnsignedWindowMeanArrayCursor{
+		UnsignedArrayCursor: cur,
+		every:               every,
+		res:                 cursors.NewFloatArrayLen(MaxPointsPerBlock),
+		tmp:                 &cursors.UnsignedArray{},
+	}
+}
+
+func (c *floatUnsignedWindowMeanArrayCursor) Stats() cursors.CursorStats {
+	return c.UnsignedArrayCursor.Stats()
+}
+
+func (c *floatUnsignedWindowMeanArrayCursor) Next() *cursors.FloatArray {
+	pos := 0
+	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
+	c.res.Values = c.res.Values[:cap(c.res.Values)]
+
+	var a *cursors.UnsignedArray
+	if c.tmp.Len() > 0 {
+		a = c.tmp
+	} else {
+		a = c.UnsignedArrayCursor.Next()
+	}
+
+	var (
+		start int64
+		sum   float64
+		n     int64
+		ok    bool
+	)
+
+LOOP:
+	for len(a.Timestamps) > 0 {
+		for i, t := range a.Timestamps {
+			if ws := windowStart(t, c.every); ok && ws != start {
+				c.res.Timestamps[pos] = start
+				c.res.Values[pos] = sum / float64(n)
+				pos++
+				ok = false
+				if pos >= MaxPointsPerBlock {
+					c.tmp.Timestamps = a.Timestamps[i:]
+					c.tmp.Values = a.Values[i:]
+					break LOOP
+				}
+			}
+			if !ok {
+				start, sum, n, ok = windowStart(t, c.every), 0, 0, true
+			}
+			sum += float64(a.Values[i])
+			n++
+		}
+
+		c.tmp.Timestamps = nil
+		c.tmp.Values = nil
+
+		a = c.UnsignedArrayCursor.Next()
+	}
+
+	if ok {
+		c.res.Timestamps[pos] = start
+		c.res.Values[pos] = sum / float64(n)
+		pos++
+	}
+
+	c.res.Timestamps = c.res.Timestamps[:pos]
+	c.res.Values = c.res.Values[:pos]
+	return c.res
+}
+
+// unsignedWindowSelectorArrayCursor selects a single point of each window.
+// Selected points keep their own timestamp.
+type unsignedWindowSelectorArrayCursor struct {
+	cursors.UnsignedArrayCursor
+	agg   datatypes.Aggregate_AggregateType
+	every int64
+	res   *cursors.UnsignedArray
+	tmp   *cursors.UnsignedArray
+}
+
+func newUnsignedWindowSelectorArrayCursor(cur cursors.UnsignedArrayCursor, agg datatypes.Aggregate_AggregateType, every int64) *unsignedWindowSelectorArrayCursor {
+	return &unsignedWindowSelectorArrayCursor{
+		UnsignedArrayCursor: cur,
+		agg:                 agg,
+		every:               every,
+		res:                 cursors.NewUnsignedArrayLen(MaxPointsPerBlock),
+		tmp:                 &cursors.UnsignedArray{},
+	}
+}
+
+func (c *unsignedWindowSelectorArrayCursor) Stats() cursors.CursorStats {
+	return c.UnsignedArrayCursor.Stats()
+}
+
+func (c *unsignedWindowSelectorArrayCursor) Next() *cursors.UnsignedArray {
+	pos := 0
+	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
+	c.res.Values = c.res.Values[:cap(c.res.Values)]
+
+	var a *cursors.UnsignedArray
+	if c.tmp.Len() > 0 {
+		a = c.tmp
+	} else {
+		a = c.UnsignedArrayCursor.Next()
+	}
+
+	var (
+		start int64
+		ts    int64
+		v     uint64
+		ok    bool
+	)
+
+LOOP:
+	for len(a.Timestamps) > 0 {
+		for i, t := range a.Timestamps {
+			if ws := windowStart(t, c.every); ok && ws != start {
+				c.res.Timestamps[pos] = ts
+				c.res.Values[pos] = v
+				pos++
+				ok = false
+				if pos >= MaxPointsPerBlock {
+					c.tmp.Timestamps = a.Timestamps[i:]
+					c.tmp.Values = a.Values[i:]
+					break LOOP
+				}
+			}
+			if !ok {
+				start, ts, v, ok = windowStart(t, c.every), t, a.Values[i], true
+				continue
+			}
+			switch c.agg {
+			case datatypes.AggregateTypeLast:
+				ts, v = t, a.Values[i]
+			case datatypes.AggregateTypeMin:
+				if a.Values[i] < v {
+					ts, v = t, a.Values[i]
+				}
+			case datatypes.AggregateTypeMax:
+				if a.Values[i] > v {
+					ts, v = t, a.Values[i]
+				}
+			}
+		}
+
+		c.tmp.Timestamps = nil
+		c.tmp.Values = nil
+
+		a = c.UnsignedArrayCursor.Next()
 	}
+
+	if ok {
+		c.res.Timestamps[pos] = ts
+		c.res.Values[pos] = v
+		pos++
+	}
+
+	c.res.Timestamps = c.res.Timestamps[:pos]
+	c.res.Values = c.res.Values[:pos]
+	return c.res
 }
 
-type integerUnsignedCountArrayCursor struct {
+// unsignedLimitArrayCursor reads only the first point of a cursor, so
+// that no further blocks are decoded.
+type unsignedLimitArrayCursor struct {
 	cursors.UnsignedArrayCursor
+	res  *cursors.UnsignedArray
+	done bool
 }
 
-func (c *integerUnsignedCountArrayCursor) Stats() cursors.CursorStats {
+func newUnsignedLimitArrayCursor(cur cursors.UnsignedArrayCursor) *unsignedLimitArrayCursor {
+	return &unsignedLimitArrayCursor{
+		UnsignedArrayCursor: cur,
+		res:                 cursors.NewUnsignedArrayLen(1),
+	}
+}
+
+func (c *unsignedLimitArrayCursor) Stats() cursors.CursorStats {
 	return c.UnsignedArrayCursor.Stats()
 }
 
-func (c *integerUnsignedCountArrayCursor) Next() *cursors.IntegerArray {
-	a := c.UnsignedArrayCursor.Next()
-	if len(a.Timestamps) == 0 {
-		return &cursors.IntegerArray{}
+func (c *unsignedLimitArrayCursor) Next() *cursors.UnsignedArray {
+	if c.done {
+		return &cursors.UnsignedArray{}
 	}
-
-	ts := a.Timestamps[0]
-	var acc int64
-	for {
-		acc += int64(len(a.Timestamps))
-		a = c.UnsignedArrayCursor.Next()
-		if len(a.Timestamps) == 0 {
-			res := cursors.NewIntegerArrayLen(1)
-			res.Timestamps[0] = ts
-			res.Values[0] = acc
-			return res
-		}
+	a := c.UnsignedArrayCursor.Next()
+	if a.Len() == 0 {
+		return a
 	}
+	c.done = true
+	c.res.Timestamps[0] = a.Timestamps[0]
+	c.res.Values[0] = a.Values[0]
+	return c.res
 }
 
 type unsignedEmptyArrayCursor struct {
@@ -848,6 +1905,198 @@ func (c *integerStringCountArrayCursor) Next() *cursors.IntegerArray {
 	}
 }
 
+type integerStringWindowCountArrayCursor struct {
+	cursors.StringArrayCursor
+	every int64
+	res   *cursors.IntegerArray
+	tmp   *cursors.StringArray
+}
+
+func newIntegerStringWindowCountArrayCursor(cur cursors.StringArrayCursor, every int64) *integerStringWindowCountArrayCursor {
+	return &integerStringWindowCountArrayCursor{
+		StringArrayCursor: cur,
+		every:             every,
+		res:               cursors.NewIntegerArrayLen(MaxPointsPerBlock),
+		tmp:               &cursors.StringArray{},
+	}
+}
+
+func (c *integerStringWindowCountArrayCursor) Stats() cursors.CursorStats {
+	return c.StringArrayCursor.Stats()
+}
+
+func (c *integerStringWindowCountArrayCursor) Next() *cursors.IntegerArray {
+	pos := 0
+	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
+	c.res.Values = c.res.Values[:cap(c.res.Values)]
+
+	var a *cursors.StringArray
+	if c.tmp.Len() > 0 {
+		a = c.tmp
+	} else {
+		a = c.StringArrayCursor.Next()
+	}
+
+	var (
+		start int64
+		acc   int64
+		ok    bool
+	)
+
+LOOP:
+	for len(a.Timestamps) > 0 {
+		for i, t := range a.Timestamps {
+			if ws := windowStart(t, c.every); ok && ws != start {
+				c.res.Timestamps[pos] = start
+				c.res.Values[pos] = acc
+				pos++
+				ok = false
+				if pos >= MaxPointsPerBlock {
+					c.tmp.Timestamps = a.Timestamps[i:]
+					c.tmp.Values = a.Values[i:]
+					break LOOP
+				}
+			}
+			if !ok {
+				start, acc, ok = windowStart(t, c.every), 0, true
+			}
+			acc++
+		}
+
+		c.tmp.Timestamps = nil
+		c.tmp.Values = nil
+
+		a = c.StringArrayCursor.Next()
+	}
+
+	if ok {
+		c.res.Timestamps[pos] = start
+		c.res.Values[pos] = acc
+		pos++
+	}
+
+	c.res.Timestamps = c.res.Timestamps[:pos]
+	c.res.Values = c.res.Values[:pos]
+	return c.res
+}
+
+// stringWindowSelectorArrayCursor selects a single point of each window.
+// Selected points keep their own timestamp.
+type stringWindowSelectorArrayCursor struct {
+	cursors.StringArrayCursor
+	agg   datatypes.Aggregate_AggregateType
+	every int64
+	res   *cursors.StringArray
+	tmp   *cursors.StringArray
+}
+
+func newStringWindowSelectorArrayCursor(cur cursors.StringArrayCursor, agg datatypes.Aggregate_AggregateType, every int64) *stringWindowSelectorArrayCursor {
+	return &stringWindowSelectorArrayCursor{
+		StringArrayCursor: cur,
+		agg:               agg,
+		every:             every,
+		res:               cursors.NewStringArrayLen(MaxPointsPerBlock),
+		tmp:               &cursors.StringArray{},
+	}
+}
+
+func (c *stringWindowSelectorArrayCursor) Stats() cursors.CursorStats {
+	return c.StringArrayCursor.Stats()
+}
+
+func (c *stringWindowSelectorArrayCursor) Next() *cursors.StringArray {
+	pos := 0
+	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
+	c.res.Values = c.res.Values[:cap(c.res.Values)]
+
+	var a *cursors.StringArray
+	if c.tmp.Len() > 0 {
+		a = c.tmp
+	} else {
+		a = c.StringArrayCursor.Next()
+	}
+
+	var (
+		start int64
+		ts    int64
+		v     string
+		ok    bool
+	)
+
+LOOP:
+	for len(a.Timestamps) > 0 {
+		for i, t := range a.Timestamps {
+			if ws := windowStart(t, c.every); ok && ws != start {
+				c.res.Timestamps[pos] = ts
+				c.res.Values[pos] = v
+				pos++
+				ok = false
+				if pos >= MaxPointsPerBlock {
+					c.tmp.Timestamps = a.Timestamps[i:]
+					c.tmp.Values = a.Values[i:]
+					break LOOP
+				}
+			}
+			if !ok {
+				start, ts, v, ok = windowStart(t, c.every), t, a.Values[i], true
+				continue
+			}
+			switch c.agg {
+			case datatypes.AggregateTypeLast:
+				ts, v = t, a.Values[i]
+			}
+		}
+
+		c.tmp.Timestamps = nil
+		c.tmp.Values = nil
+
+		a = c.StringArrayCursor.Next()
+	}
+
+	if ok {
+		c.res.Timestamps[pos] = ts
+		c.res.Values[pos] = v
+		pos++
+	}
+
+	c.res.Timestamps = c.res.Timestamps[:pos]
+	c.res.Values = c.res.Values[:pos]
+	return c.res
+}
+
+// stringLimitArrayCursor reads only the first point of a cursor, so
+// that no further blocks are decoded.
+type stringLimitArrayCursor struct {
+	cursors.StringArrayCursor
+	res  *cursors.StringArray
+	done bool
+}
+
+func newStringLimitArrayCursor(cur cursors.StringArrayCursor) *stringLimitArrayCursor {
+	return &stringLimitArrayCursor{
+		StringArrayCursor: cur,
+		res:               cursors.NewStringArrayLen(1),
+	}
+}
+
+func (c *stringLimitArrayCursor) Stats() cursors.CursorStats {
+	return c.StringArrayCursor.Stats()
+}
+
+func (c *stringLimitArrayCursor) Next() *cursors.StringArray {
+	if c.done {
+		return &cursors.StringArray{}
+	}
+	a := c.StringArrayCursor.Next()
+	if a.Len() == 0 {
+		return a
+	}
+	c.done = true
+	c.res.Timestamps[0] = a.Timestamps[0]
+	c.res.Values[0] = a.Values[0]
+	return c.res
+}
+
 type stringEmptyArrayCursor struct {
 	res cursors.StringArray
 }
@@ -1028,6 +2277,198 @@ func (c *integerBooleanCountArrayCursor) Next() *cursors.IntegerArray {
 	}
 }
 
+type integerBooleanWindowCountArrayCursor struct {
+	cursors.BooleanArrayCursor
+	every int64
+	res   *cursors.IntegerArray
+	tmp   *cursors.BooleanArray
+}
+
+func newIntegerBooleanWindowCountArrayCursor(cur cursors.BooleanArrayCursor, every int64) *integerBooleanWindowCountArrayCursor {
+	return &integerBooleanWindowCountArrayCursor{
+		BooleanArrayCursor: cur,
+		every:              every,
+		res:                cursors.NewIntegerArrayLen(MaxPointsPerBlock),
+		tmp:                &cursors.BooleanArray{},
+	}
+}
+
+func (c *integerBooleanWindowCountArrayCursor) Stats() cursors.CursorStats {
+	return c.BooleanArrayCursor.Stats()
+}
+
+func (c *integerBooleanWindowCountArrayCursor) Next() *cursors.IntegerArray {
+	pos := 0
+	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
+	c.res.Values = c.res.Values[:cap(c.res.Values)]
+
+	var a *cursors.BooleanArray
+	if c.tmp.Len() > 0 {
+		a = c.tmp
+	} else {
+		a = c.BooleanArrayCursor.Next()
+	}
+
+	var (
+		start int64
+		acc   int64
+		ok    bool
+	)
+
+LOOP:
+	for len(a.Timestamps) > 0 {
+		for i, t := range a.Timestamps {
+			if ws := windowStart(t, c.every); ok && ws != start {
+				c.res.Timestamps[pos] = start
+				c.res.Values[pos] = acc
+				pos++
+				ok = false
+				if pos >= MaxPointsPerBlock {
+					c.tmp.Timestamps = a.Timestamps[i:]
+					c.tmp.Values = a.Values[i:]
+					break LOOP
+				}
+			}
+			if !ok {
+				start, acc, ok = windowStart(t, c.every), 0, true
+			}
+			acc++
+		}
+
+		c.tmp.Timestamps = nil
+		c.tmp.Values = nil
+
+		a = c.BooleanArrayCursor.Next()
+	}
+
+	if ok {
+		c.res.Timestamps[pos] = start
+		c.res.Values[pos] = acc
+		pos++
+	}
+
+	c.res.Timestamps = c.res.Timestamps[:pos]
+	c.res.Values = c.res.Values[:pos]
+	return c.res
+}
+
+// booleanWindowSelectorArrayCursor selects a single point of each window.
+// Selected points keep their own timestamp.
+type booleanWindowSelectorArrayCursor struct {
+	cursors.BooleanArrayCursor
+	agg   datatypes.Aggregate_AggregateType
+	every int64
+	res   *cursors.BooleanArray
+	tmp   *cursors.BooleanArray
+}
+
+func newBooleanWindowSelectorArrayCursor(cur cursors.BooleanArrayCursor, agg datatypes.Aggregate_AggregateType, every int64) *booleanWindowSelectorArrayCursor {
+	return &booleanWindowSelectorArrayCursor{
+		BooleanArrayCursor: cur,
+		agg:                agg,
+		every:              every,
+		res:                cursors.NewBooleanArrayLen(MaxPointsPerBlock),
+		tmp:                &cursors.BooleanArray{},
+	}
+}
+
+func (c *booleanWindowSelectorArrayCursor) Stats() cursors.CursorStats {
+	return c.BooleanArrayCursor.Stats()
+}
+
+func (c *booleanWindowSelectorArrayCursor) Next() *cursors.BooleanArray {
+	pos := 0
+	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
+	c.res.Values = c.res.Values[:cap(c.res.Values)]
+
+	var a *cursors.BooleanArray
+	if c.tmp.Len() > 0 {
+		a = c.tmp
+	} else {
+		a = c.BooleanArrayCursor.Next()
+	}
+
+	var (
+		start int64
+		ts    int64
+		v     bool
+		ok    bool
+	)
+
+LOOP:
+	for len(a.Timestamps) > 0 {
+		for i, t := range a.Timestamps {
+			if ws := windowStart(t, c.every); ok && ws != start {
+				c.res.Timestamps[pos] = ts
+				c.res.Values[pos] = v
+				pos++
+				ok = false
+				if pos >= MaxPointsPerBlock {
+					c.tmp.Timestamps = a.Timestamps[i:]
+					c.tmp.Values = a.Values[i:]
+					break LOOP
+				}
+			}
+			if !ok {
+				start, ts, v, ok = windowStart(t, c.every), t, a.Values[i], true
+				continue
+			}
+			switch c.agg {
+			case datatypes.AggregateTypeLast:
+				ts, v = t, a.Values[i]
+			}
+		}
+
+		c.tmp.Timestamps = nil
+		c.tmp.Values = nil
+
+		a = c.BooleanArrayCursor.Next()
+	}
+
+	if ok {
+		c.res.Timestamps[pos] = ts
+		c.res.Values[pos] = v
+		pos++
+	}
+
+	c.res.Timestamps = c.res.Timestamps[:pos]
+	c.res.Values = c.res.Values[:pos]
+	return c.res
+}
+
+// booleanLimitArrayCursor reads only the first point of a cursor, so
+// that no further blocks are decoded.
+type booleanLimitArrayCursor struct {
+	cursors.BooleanArrayCursor
+	res  *cursors.BooleanArray
+	done bool
+}
+
+func newBooleanLimitArrayCursor(cur cursors.BooleanArrayCursor) *booleanLimitArrayCursor {
+	return &booleanLimitArrayCursor{
+		BooleanArrayCursor: cur,
+		res:                cursors.NewBooleanArrayLen(1),
+	}
+}
+
+func (c *booleanLimitArrayCursor) Stats() cursors.CursorStats {
+	return c.BooleanArrayCursor.Stats()
+}
+
+func (c *booleanLimitArrayCursor) Next() *cursors.BooleanArray {
+	if c.done {
+		return &cursors.BooleanArray{}
+	}
+	a := c.BooleanArrayCursor.Next()
+	if a.Len() == 0 {
+		return a
+	}
+	c.done = true
+	c.res.Timestamps[0] = a.Timestamps[0]
+	c.res.Values[0] = a.Values[0]
+	return c.res
+}
+
 type booleanEmptyArrayCursor struct {
 	res cursors.BooleanArray
 }
@@ -3,6 +3,7 @@ package reads
 import (
 	"context"
 	"fmt"
+	"math"
 
 	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
 	"github.com/influxdata/influxdb/v2/tsdb/cursors"
@@ -16,6 +17,9 @@ func (v *singleValue) Value(key string) (interface{}, bool) {
 	return v.v, true
 }
 
+// newAggregateArrayCursor returns a cursor that computes agg over all the
+// points of cursor. It returns nil if agg is not supported for the type of
+// cursor. The cursor of a last aggregate must read points in descending order.
 func newAggregateArrayCursor(ctx context.Context, agg *datatypes.Aggregate, cursor cursors.Cursor) cursors.Cursor {
 	if cursor == nil {
 		return nil
@@ -26,9 +30,12 @@ func newAggregateArrayCursor(ctx context.Context, agg *datatypes.Aggregate, curs
 		return newSumArrayCursor(cursor)
 	case datatypes.AggregateTypeCount:
 		return newCountArrayCursor(cursor)
+	case datatypes.AggregateTypeFirst, datatypes.AggregateTypeLast:
+		return newLimitArrayCursor(cursor)
+	case datatypes.AggregateTypeMin, datatypes.AggregateTypeMax:
+		return newWindowAggregateArrayCursor(agg.Type, infiniteWindow, cursor)
 	default:
-		// TODO(sgc): should be validated higher up
-		panic("invalid aggregate")
+		return nil
 	}
 }
 
@@ -63,6 +70,165 @@ func newCountArrayCursor(cur cursors.Cursor) cursors.Cursor {
 	}
 }
 
+// IsWindowAggregateSupported returns true if agg is computed over windows by
+// NewWindowAggregateResultSet, for the types of fields it applies to.
+func IsWindowAggregateSupported(agg datatypes.Aggregate_AggregateType) bool {
+	switch agg {
+	case datatypes.AggregateTypeCount,
+		datatypes.AggregateTypeSum,
+		datatypes.AggregateTypeMean,
+		datatypes.AggregateTypeMin,
+		datatypes.AggregateTypeMax,
+		datatypes.AggregateTypeFirst,
+		datatypes.AggregateTypeLast:
+		return true
+	}
+	return false
+}
+
+// newWindowAggregateArrayCursor returns a cursor that computes agg over
+// consecutive windows of width every. It returns nil if agg is not supported
+// for the type of cursor.
+func newWindowAggregateArrayCursor(agg datatypes.Aggregate_AggregateType, every int64, cursor cursors.Cursor) cursors.Cursor {
+	if cursor == nil {
+		return nil
+	}
+
+	switch agg {
+	case datatypes.AggregateTypeCount:
+		return newWindowCountArrayCursor(cursor, every)
+	case datatypes.AggregateTypeFirst, datatypes.AggregateTypeLast:
+		if every == infiniteWindow {
+			// The cursor of a last aggregate reads points in descending order.
+			return newLimitArrayCursor(cursor)
+		}
+		return newWindowSelectorArrayCursor(cursor, agg, every)
+	case datatypes.AggregateTypeSum:
+		return newWindowSumArrayCursor(cursor, every)
+	case datatypes.AggregateTypeMean:
+		return newWindowMeanArrayCursor(cursor, every)
+	case datatypes.AggregateTypeMin, datatypes.AggregateTypeMax:
+		switch cursor.(type) {
+		case cursors.StringArrayCursor, cursors.BooleanArrayCursor:
+			return nil
+		}
+		return newWindowSelectorArrayCursor(cursor, agg, every)
+	default:
+		return nil
+	}
+}
+
+func newWindowCountArrayCursor(cur cursors.Cursor, every int64) cursors.Cursor {
+	switch cur := cur.(type) {
+	case cursors.FloatArrayCursor:
+		return newIntegerFloatWindowCountArrayCursor(cur, every)
+	case cursors.IntegerArrayCursor:
+		return newIntegerIntegerWindowCountArrayCursor(cur, every)
+	case cursors.UnsignedArrayCursor:
+		return newIntegerUnsignedWindowCountArrayCursor(cur, every)
+	case cursors.StringArrayCursor:
+		return newIntegerStringWindowCountArrayCursor(cur, every)
+	case cursors.BooleanArrayCursor:
+		return newIntegerBooleanWindowCountArrayCursor(cur, every)
+	default:
+		panic(fmt.Sprintf("unreachable: %T", cur))
+	}
+}
+
+func newWindowSumArrayCursor(cur cursors.Cursor, every int64) cursors.Cursor {
+	switch cur := cur.(type) {
+	case cursors.FloatArrayCursor:
+		return newFloatWindowSumArrayCursor(cur, every)
+	case cursors.IntegerArrayCursor:
+		return newIntegerWindowSumArrayCursor(cur, every)
+	case cursors.UnsignedArrayCursor:
+		return newUnsignedWindowSumArrayCursor(cur, every)
+	default:
+		return nil
+	}
+}
+
+func newWindowMeanArrayCursor(cur cursors.Cursor, every int64) cursors.Cursor {
+	switch cur := cur.(type) {
+	case cursors.FloatArrayCursor:
+		return newFloatFloatWindowMeanArrayCursor(cur, every)
+	case cursors.IntegerArrayCursor:
+		return newFloatIntegerWindowMeanArrayCursor(cur, every)
+	case cursors.UnsignedArrayCursor:
+		return newFloatUnsignedWindowMeanArrayCursor(cur, every)
+	default:
+		return nil
+	}
+}
+
+func newWindowSelectorArrayCursor(cur cursors.Cursor, agg datatypes.Aggregate_AggregateType, every int64) cursors.Cursor {
+	switch cur := cur.(type) {
+	case cursors.FloatArrayCursor:
+		return newFloatWindowSelectorArrayCursor(cur, agg, every)
+	case cursors.IntegerArrayCursor:
+		return newIntegerWindowSelectorArrayCursor(cur, agg, every)
+	case cursors.UnsignedArrayCursor:
+		return newUnsignedWindowSelectorArrayCursor(cur, agg, every)
+	case cursors.StringArrayCursor:
+		return newStringWindowSelectorArrayCursor(cur, agg, every)
+	case cursors.BooleanArrayCursor:
+		return newBooleanWindowSelectorArrayCursor(cur, agg, every)
+	default:
+		panic(fmt.Sprintf("unreachable: %T", cur))
+	}
+}
+
+func newLimitArrayCursor(cur cursors.Cursor) cursors.Cursor {
+	switch cur := cur.(type) {
+	case cursors.FloatArrayCursor:
+		return newFloatLimitArrayCursor(cur)
+	case cursors.IntegerArrayCursor:
+		return newIntegerLimitArrayCursor(cur)
+	case cursors.UnsignedArrayCursor:
+		return newUnsignedLimitArrayCursor(cur)
+	case cursors.StringArrayCursor:
+		return newStringLimitArrayCursor(cur)
+	case cursors.BooleanArrayCursor:
+		return newBooleanLimitArrayCursor(cur)
+	default:
+		panic(fmt.Sprintf("unreachable: %T", cur))
+	}
+}
+
+// cursorTypeName returns the name of the type of values read by cur.
+func cursorTypeName(cur cursors.Cursor) string {
+	switch cur.(type) {
+	case cursors.FloatArrayCursor:
+		return "float"
+	case cursors.IntegerArrayCursor:
+		return "integer"
+	case cursors.UnsignedArrayCursor:
+		return "unsigned"
+	case cursors.StringArrayCursor:
+		return "string"
+	case cursors.BooleanArrayCursor:
+		return "boolean"
+	default:
+		return fmt.Sprintf("%T", cur)
+	}
+}
+
+// infiniteWindow is the width of the single window that contains every point.
+const infiniteWindow = math.MaxInt64
+
+// windowStart returns the start of the window of width every that contains t.
+// Windows are aligned to the Unix epoch.
+func windowStart(t, every int64) int64 {
+	if every == infiniteWindow {
+		return math.MinInt64
+	}
+	mod := t % every
+	if mod < 0 {
+		mod += every
+	}
+	return t - mod
+}
+
 type cursorContext struct {
 	ctx            context.Context
 	req            *cursors.CursorRequest
@@ -84,6 +250,11 @@ type arrayCursors struct {
 }
 
 func newArrayCursors(ctx context.Context, start, end int64, asc bool) *arrayCursors {
+	if !asc {
+		// Descending cursors read the points in (StartTime, EndTime].
+		start, end = start-1, end-1
+	}
+
 	m := &arrayCursors{
 		ctx: ctx,
 		req: cursors.CursorRequest{
@@ -1,8 +1,11 @@
 package reads
 
 import (
+	"context"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
 	"github.com/influxdata/influxdb/v2/tsdb/cursors"
 )
 
@@ -39,6 +42,153 @@ func TestIntegerFilterArrayCursor(t *testing.T) {
 	}
 }
 
+// newMockIntegerArrayCursor returns a cursor that reads each of arrays in turn.
+func newMockIntegerArrayCursor(arrays ...*cursors.IntegerArray) *MockIntegerArrayCursor {
+	return &MockIntegerArrayCursor{
+		CloseFunc: func() {},
+		ErrFunc:   func() error { return nil },
+		StatsFunc: func() cursors.CursorStats { return cursors.CursorStats{} },
+		NextFunc: func() *cursors.IntegerArray {
+			if len(arrays) == 0 {
+				return &cursors.IntegerArray{}
+			}
+			a := arrays[0]
+			arrays = arrays[1:]
+			return a
+		},
+	}
+}
+
+func TestWindowAggregateArrayCursor(t *testing.T) {
+	newInput := func() cursors.Cursor {
+		return newMockIntegerArrayCursor(
+			&cursors.IntegerArray{
+				Timestamps: []int64{1, 2, 11},
+				Values:     []int64{4, 3, 1},
+			},
+			&cursors.IntegerArray{
+				Timestamps: []int64{15, 32},
+				Values:     []int64{5, 2},
+			},
+		)
+	}
+
+	type result struct {
+		Timestamps []int64
+		Values     interface{}
+	}
+
+	tests := []struct {
+		agg  datatypes.Aggregate_AggregateType
+		want result
+	}{
+		{
+			agg:  datatypes.AggregateTypeCount,
+			want: result{Timestamps: []int64{0, 10, 30}, Values: []int64{2, 2, 1}},
+		},
+		{
+			agg:  datatypes.AggregateTypeSum,
+			want: result{Timestamps: []int64{0, 10, 30}, Values: []int64{7, 6, 2}},
+		},
+		{
+			agg:  datatypes.AggregateTypeMean,
+			want: result{Timestamps: []int64{0, 10, 30}, Values: []float64{3.5, 3, 2}},
+		},
+		{
+			agg:  datatypes.AggregateTypeMin,
+			want: result{Timestamps: []int64{2, 11, 32}, Values: []int64{3, 1, 2}},
+		},
+		{
+			agg:  datatypes.AggregateTypeMax,
+			want: result{Timestamps: []int64{1, 15, 32}, Values: []int64{4, 5, 2}},
+		},
+		{
+			agg:  datatypes.AggregateTypeFirst,
+			want: result{Timestamps: []int64{1, 11, 32}, Values: []int64{4, 1, 2}},
+		},
+		{
+			agg:  datatypes.AggregateTypeLast,
+			want: result{Timestamps: []int64{2, 15, 32}, Values: []int64{3, 5, 2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.agg.String(), func(t *testing.T) {
+			var got result
+			switch cur := newWindowAggregateArrayCursor(tt.agg, 10, newInput()).(type) {
+			case cursors.IntegerArrayCursor:
+				a := cur.Next()
+				got = result{Timestamps: a.Timestamps, Values: a.Values}
+			case cursors.FloatArrayCursor:
+				a := cur.Next()
+				got = result{Timestamps: a.Timestamps, Values: a.Values}
+			default:
+				t.Fatalf("unexpected cursor type %T", cur)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("unexpected windows -want/+got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWindowAggregateArrayCursor_MaxPointsPerBlock(t *testing.T) {
+	// Two points in each of 1500 windows.
+	a := cursors.NewIntegerArrayLen(3000)
+	for i := range a.Timestamps {
+		a.Timestamps[i] = int64(i) * 5
+		a.Values[i] = 1
+	}
+
+	cur := newWindowAggregateArrayCursor(datatypes.AggregateTypeCount, 10, newMockIntegerArrayCursor(a)).(cursors.IntegerArrayCursor)
+	if got, want := cur.Next().Len(), MaxPointsPerBlock; got != want {
+		t.Fatalf("len(Next())=%d, want %d", got, want)
+	}
+	next := cur.Next()
+	if got, want := next.Len(), 500; got != want {
+		t.Fatalf("len(Next())=%d, want %d", got, want)
+	}
+	if got, want := next.Timestamps[0], int64(10000); got != want {
+		t.Fatalf("first window=%d, want %d", got, want)
+	}
+	if got, want := next.Values[0], int64(2); got != want {
+		t.Fatalf("first count=%d, want %d", got, want)
+	}
+	if got := cur.Next().Len(); got != 0 {
+		t.Fatalf("len(Next())=%d, want 0", got)
+	}
+}
+
+func TestWindowAggregateArrayCursor_Unsupported(t *testing.T) {
+	cur := newWindowAggregateArrayCursor(datatypes.AggregateTypeSum, 10, &stringEmptyArrayCursor{})
+	if cur != nil {
+		t.Fatalf("expected no cursor for sum of strings, got %T", cur)
+	}
+}
+
+func TestAggregateArrayCursor_Limit(t *testing.T) {
+	input := newMockIntegerArrayCursor(
+		&cursors.IntegerArray{
+			Timestamps: []int64{3, 5},
+			Values:     []int64{7, 9},
+		},
+		&cursors.IntegerArray{
+			Timestamps: []int64{8},
+			Values:     []int64{1},
+		},
+	)
+
+	agg := &datatypes.Aggregate{Type: datatypes.AggregateTypeFirst}
+	cur := newAggregateArrayCursor(context.Background(), agg, input).(cursors.IntegerArrayCursor)
+	got := cur.Next()
+	if !cmp.Equal(got.Timestamps, []int64{3}) || !cmp.Equal(got.Values, []int64{7}) {
+		t.Fatalf("unexpected first point: %v %v", got.Timestamps, got.Values)
+	}
+	if got := cur.Next().Len(); got != 0 {
+		t.Fatalf("len(Next())=%d, want 0", got)
+	}
+}
+
 type MockIntegerArrayCursor struct {
 	CloseFunc func()
 	ErrFunc   func() error
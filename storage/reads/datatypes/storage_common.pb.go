@@ -97,6 +97,9 @@ const (
 	AggregateTypeCount Aggregate_AggregateType = 2
 	AggregateTypeMin   Aggregate_AggregateType = 3
 	AggregateTypeMax   Aggregate_AggregateType = 4
+	AggregateTypeFirst Aggregate_AggregateType = 5
+	AggregateTypeLast  Aggregate_AggregateType = 6
+	AggregateTypeMean  Aggregate_AggregateType = 7
 )
 
 var Aggregate_AggregateType_name = map[int32]string{
@@ -105,6 +108,9 @@ var Aggregate_AggregateType_name = map[int32]string{
 	2: "COUNT",
 	3: "MIN",
 	4: "MAX",
+	5: "FIRST",
+	6: "LAST",
+	7: "MEAN",
 }
 
 var Aggregate_AggregateType_value = map[string]int32{
@@ -113,6 +119,9 @@ var Aggregate_AggregateType_value = map[string]int32{
 	"COUNT": 2,
 	"MIN":   3,
 	"MAX":   4,
+	"FIRST": 5,
+	"LAST":  6,
+	"MEAN":  7,
 }
 
 func (x Aggregate_AggregateType) String() string {
@@ -1396,117 +1405,119 @@ func init() {
 func init() { proto.RegisterFile("storage_common.proto", fileDescriptor_715e4bf4cdf1f73d) }
 
 var fileDescriptor_715e4bf4cdf1f73d = []byte{
-	// 1750 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe4, 0x58, 0xcd, 0x8f, 0x23, 0x47,
-	0x15, 0x77, 0xfb, 0x6b, 0xa6, 0x9f, 0x3d, 0xde, 0xde, 0x8a, 0xd9, 0xcc, 0xf6, 0x66, 0xed, 0x8e,
-	0x81, 0x64, 0x24, 0x16, 0x8f, 0x34, 0x09, 0x52, 0xb4, 0x10, 0x89, 0xf1, 0xac, 0x67, 0x6c, 0x76,
-	0x6c, 0x8f, 0xca, 0x9e, 0xf0, 0x71, 0x31, 0x35, 0xe3, 0x72, 0xa7, 0x15, 0xbb, 0xdb, 0x74, 0xb7,
-	0x97, 0xb5, 0xc4, 0x85, 0x5b, 0xe4, 0x03, 0x0a, 0x12, 0x5c, 0x90, 0x7c, 0xe2, 0xc8, 0x3d, 0x7f,
-	0xc3, 0x22, 0x71, 0xc8, 0x11, 0x71, 0xb0, 0xc0, 0x2b, 0x21, 0xf1, 0x27, 0x10, 0x2e, 0xa8, 0xaa,
-	0xba, 0xda, 0xed, 0xd9, 0x61, 0x62, 0xaf, 0xf6, 0x80, 0x36, 0xb7, 0xaa, 0xf7, 0x5e, 0xfd, 0xde,
-	0x47, 0xbf, 0x57, 0xef, 0x55, 0x43, 0xde, 0xf3, 0x1d, 0x97, 0x98, 0xb4, 0x7b, 0xe9, 0x0c, 0x87,
-	0x8e, 0x5d, 0x1e, 0xb9, 0x8e, 0xef, 0xa0, 0x7b, 0x96, 0xdd, 0x1f, 0x8c, 0x9f, 0xf6, 0x88, 0x4f,
-	0xca, 0xa3, 0x01, 0xf1, 0xfb, 0x8e, 0x3b, 0x2c, 0x07, 0x92, 0x7a, 0xde, 0x74, 0x4c, 0x87, 0xcb,
-	0xed, 0xb3, 0x95, 0x38, 0xa2, 0xdf, 0x35, 0x1d, 0xc7, 0x1c, 0xd0, 0x7d, 0xbe, 0xbb, 0x18, 0xf7,
-	0xf7, 0x89, 0x3d, 0x09, 0x58, 0xb7, 0x46, 0x2e, 0xed, 0x59, 0x97, 0xc4, 0xa7, 0x82, 0x50, 0xfa,
-	0x97, 0x02, 0xb7, 0x31, 0x25, 0xbd, 0x63, 0x6b, 0xe0, 0x53, 0x17, 0xd3, 0x5f, 0x8c, 0xa9, 0xe7,
-	0xa3, 0x2a, 0x64, 0x5c, 0x4a, 0x7a, 0x5d, 0xcf, 0x19, 0xbb, 0x97, 0x74, 0x57, 0x31, 0x94, 0xbd,
-	0xcc, 0x41, 0xbe, 0x2c, 0x70, 0xcb, 0x12, 0xb7, 0x7c, 0x68, 0x4f, 0x2a, 0xb9, 0xc5, 0xbc, 0x08,
-	0x0c, 0xa1, 0xcd, 0x65, 0x31, 0xb8, 0xe1, 0x1a, 0x9d, 0x40, 0xca, 0x25, 0xb6, 0x49, 0x77, 0xe3,
-	0x1c, 0xe0, 0x3b, 0xe5, 0x1b, 0x7c, 0x29, 0x77, 0xac, 0x21, 0xf5, 0x7c, 0x32, 0x1c, 0x61, 0x76,
-	0xa4, 0x92, 0x7c, 0x36, 0x2f, 0xc6, 0xb0, 0x38, 0x8f, 0x1e, 0x81, 0x1a, 0x1a, 0xbe, 0x9b, 0xe0,
-	0x60, 0xef, 0xdc, 0x08, 0x76, 0x26, 0xa5, 0xf1, 0xf2, 0x60, 0xe9, 0x2f, 0x29, 0xd0, 0x98, 0xa5,
-	0x27, 0xae, 0x33, 0x1e, 0xbd, 0xd6, 0xae, 0xa2, 0x07, 0x00, 0x26, 0xf3, 0xb2, 0xfb, 0x09, 0x9d,
-	0x78, 0xbb, 0x49, 0x23, 0xb1, 0xa7, 0x56, 0x76, 0x16, 0xf3, 0xa2, 0xca, 0x7d, 0x7f, 0x4c, 0x27,
-	0x1e, 0x56, 0x4d, 0xb9, 0x44, 0x75, 0x48, 0xf1, 0xcd, 0x6e, 0xca, 0x50, 0xf6, 0x72, 0x07, 0xef,
-	0xdd, 0xa8, 0xef, 0x6a, 0x04, 0xcb, 0x62, 0x23, 0x10, 0x98, 0xf9, 0xc4, 0x34, 0x5d, 0x6a, 0x32,
-	0xf3, 0xd3, 0x6b, 0x98, 0x7f, 0x28, 0xa5, 0xf1, 0xf2, 0x20, 0x7a, 0x00, 0xa9, 0x8f, 0x2d, 0xdb,
-	0xf7, 0x76, 0xb7, 0x0c, 0x65, 0x6f, 0xab, 0x72, 0x67, 0x31, 0x2f, 0xa6, 0x6a, 0x8c, 0xf0, 0xe5,
-	0xbc, 0xa8, 0xb2, 0xc5, 0xf1, 0x80, 0x98, 0x1e, 0x16, 0x42, 0xa5, 0x13, 0x48, 0x71, 0x1b, 0xd0,
-	0x7d, 0x80, 0x13, 0xdc, 0x3a, 0x3f, 0xeb, 0x36, 0x5b, 0xcd, 0xaa, 0x16, 0xd3, 0x77, 0xa6, 0x33,
-	0x43, 0x78, 0xdc, 0x74, 0x6c, 0x8a, 0xee, 0xc2, 0xb6, 0x60, 0x57, 0x7e, 0xaa, 0xc5, 0xf5, 0xcc,
-	0x74, 0x66, 0x6c, 0x71, 0x66, 0x65, 0xa2, 0x27, 0x3f, 0xfd, 0x63, 0x21, 0x56, 0xfa, 0x93, 0x02,
-	0x4b, 0x74, 0x74, 0x0f, 0xd4, 0x5a, 0xbd, 0xd9, 0x91, 0x60, 0xd9, 0xe9, 0xcc, 0xd8, 0x66, 0x5c,
-	0x8e, 0xf5, 0x2d, 0xc8, 0x05, 0xcc, 0xee, 0x59, 0xab, 0xde, 0xec, 0xb4, 0x35, 0x45, 0xd7, 0xa6,
-	0x33, 0x23, 0x2b, 0x24, 0xce, 0x1c, 0x66, 0x59, 0x54, 0xaa, 0x5d, 0xc5, 0xf5, 0x6a, 0x5b, 0x8b,
-	0x47, 0xa5, 0xda, 0xd4, 0xb5, 0xa8, 0x87, 0xf6, 0x21, 0xcf, 0xa5, 0xda, 0x47, 0xb5, 0x6a, 0xe3,
-	0xb0, 0x7b, 0x78, 0x7a, 0xda, 0xed, 0xd4, 0x1b, 0x55, 0x2d, 0xa9, 0x7f, 0x63, 0x3a, 0x33, 0x6e,
-	0x33, 0xd9, 0xf6, 0xe5, 0xc7, 0x74, 0x48, 0x0e, 0x07, 0x03, 0x96, 0x3a, 0x81, 0xb5, 0xbf, 0x89,
-	0x83, 0x1a, 0x46, 0x0f, 0xd5, 0x20, 0xe9, 0x4f, 0x46, 0x22, 0x81, 0x73, 0x07, 0xef, 0xaf, 0x17,
-	0xf3, 0xe5, 0xaa, 0x33, 0x19, 0x51, 0xcc, 0x11, 0x4a, 0x9f, 0x2b, 0xb0, 0xb3, 0x42, 0x47, 0x45,
-	0x48, 0x06, 0x41, 0xe0, 0x06, 0xad, 0x30, 0x79, 0x34, 0xee, 0x43, 0xa2, 0x7d, 0xde, 0xd0, 0x14,
-	0x3d, 0x3f, 0x9d, 0x19, 0xda, 0x0a, 0xbf, 0x3d, 0x1e, 0xa2, 0xb7, 0x21, 0x75, 0xd4, 0x3a, 0x6f,
-	0x76, 0xb4, 0xb8, 0x7e, 0x67, 0x3a, 0x33, 0xd0, 0x8a, 0xc0, 0x91, 0x33, 0xb6, 0x7d, 0x86, 0xd0,
-	0xa8, 0x37, 0xb5, 0xc4, 0x35, 0x08, 0x0d, 0xcb, 0xe6, 0xec, 0xc3, 0x9f, 0x68, 0xc9, 0xeb, 0xd8,
-	0xe4, 0x69, 0x10, 0x90, 0xef, 0x42, 0xa2, 0x43, 0x4c, 0xa4, 0x41, 0xe2, 0x13, 0x3a, 0xe1, 0x81,
-	0xc8, 0x62, 0xb6, 0x44, 0x79, 0x48, 0x3d, 0x21, 0x83, 0xb1, 0x28, 0xce, 0x2c, 0x16, 0x9b, 0xd2,
-	0x6f, 0x73, 0x90, 0x65, 0xc9, 0x8c, 0xa9, 0x37, 0x72, 0x6c, 0x8f, 0xa2, 0x06, 0xa4, 0xfb, 0x2e,
-	0x19, 0x52, 0x6f, 0x57, 0x31, 0x12, 0x7b, 0x99, 0x83, 0xfd, 0xaf, 0xac, 0x03, 0x79, 0xb4, 0x7c,
-	0xcc, 0xce, 0x05, 0x85, 0x1c, 0x80, 0xe8, 0x9f, 0xa6, 0x21, 0xc5, 0xe9, 0xe8, 0x54, 0xd6, 0xd7,
-	0x16, 0x2f, 0x88, 0xf7, 0xd7, 0xc7, 0xe5, 0xf9, 0xc9, 0x41, 0x6a, 0x31, 0x59, 0x62, 0x2d, 0x48,
-	0x7b, 0x3c, 0x71, 0x82, 0xcb, 0xea, 0x7b, 0xeb, 0xc3, 0x89, 0x84, 0x93, 0x78, 0x01, 0x0c, 0x1a,
-	0x41, 0xb6, 0x3f, 0x70, 0x88, 0xdf, 0x1d, 0xf1, 0xac, 0x0d, 0xae, 0xb0, 0x87, 0x1b, 0x78, 0xcf,
-	0x4e, 0x8b, 0x94, 0x17, 0x81, 0xb8, 0xb5, 0x98, 0x17, 0x33, 0x11, 0x6a, 0x2d, 0x86, 0x33, 0xfd,
-	0xe5, 0x16, 0x3d, 0x85, 0x9c, 0x65, 0xfb, 0xd4, 0xa4, 0xae, 0xd4, 0x29, 0x6e, 0xba, 0x1f, 0xac,
-	0xaf, 0xb3, 0x2e, 0xce, 0x47, 0xb5, 0xde, 0x5e, 0xcc, 0x8b, 0x3b, 0x2b, 0xf4, 0x5a, 0x0c, 0xef,
-	0x58, 0x51, 0x02, 0xfa, 0x15, 0xdc, 0x1a, 0xdb, 0x9e, 0x65, 0xda, 0xb4, 0x27, 0x55, 0x27, 0xb9,
-	0xea, 0x0f, 0xd7, 0x57, 0x7d, 0x1e, 0x00, 0x44, 0x75, 0xa3, 0xc5, 0xbc, 0x98, 0x5b, 0x65, 0xd4,
-	0x62, 0x38, 0x37, 0x5e, 0xa1, 0x30, 0xbf, 0x2f, 0x1c, 0x67, 0x40, 0x89, 0x2d, 0x95, 0xa7, 0x36,
-	0xf5, 0xbb, 0x22, 0xce, 0xbf, 0xe0, 0xf7, 0x0a, 0x9d, 0xf9, 0x7d, 0x11, 0x25, 0x20, 0x1f, 0x76,
-	0x3c, 0xdf, 0xb5, 0x6c, 0x53, 0x2a, 0x16, 0x77, 0xf3, 0xf7, 0x37, 0xc8, 0x1d, 0x7e, 0x3c, 0xaa,
-	0x57, 0x5b, 0xcc, 0x8b, 0xd9, 0x28, 0xb9, 0x16, 0xc3, 0x59, 0x2f, 0xb2, 0xaf, 0xa4, 0x21, 0xc9,
-	0x90, 0xf5, 0xa7, 0x00, 0xcb, 0x4c, 0x46, 0xef, 0xc0, 0xb6, 0x4f, 0x4c, 0xd1, 0x9a, 0x58, 0xa5,
-	0x65, 0x2b, 0x99, 0xc5, 0xbc, 0xb8, 0xd5, 0x21, 0x26, 0x6f, 0x4c, 0x5b, 0xbe, 0x58, 0xa0, 0x0a,
-	0xa0, 0x11, 0x71, 0x7d, 0xcb, 0xb7, 0x1c, 0x9b, 0x49, 0x77, 0x9f, 0x90, 0x01, 0xcb, 0x4e, 0x76,
-	0x22, 0xbf, 0x98, 0x17, 0xb5, 0x33, 0xc9, 0x7d, 0x4c, 0x27, 0x1f, 0x91, 0x81, 0x87, 0xb5, 0xd1,
-	0x15, 0x8a, 0xfe, 0x07, 0x05, 0x32, 0x91, 0xac, 0x47, 0x0f, 0x21, 0xe9, 0x13, 0x53, 0x56, 0xb8,
-	0x71, 0x73, 0x9b, 0x26, 0x66, 0x50, 0xd2, 0xfc, 0x0c, 0x6a, 0x81, 0xca, 0x04, 0xbb, 0xfc, 0x9e,
-	0x8d, 0xf3, 0x7b, 0xf6, 0x60, 0xfd, 0xf8, 0x3d, 0x22, 0x3e, 0xe1, 0xb7, 0xec, 0x76, 0x2f, 0x58,
-	0xe9, 0x3f, 0x02, 0xed, 0x6a, 0xe9, 0xa0, 0x02, 0x80, 0x2f, 0xc7, 0x03, 0x61, 0xa6, 0x86, 0x23,
-	0x14, 0x74, 0x07, 0xd2, 0xfc, 0xfa, 0x12, 0x81, 0x50, 0x70, 0xb0, 0xd3, 0x4f, 0x01, 0xbd, 0x58,
-	0x12, 0x1b, 0xa2, 0x25, 0x42, 0xb4, 0x06, 0xbc, 0x71, 0x4d, 0x96, 0x6f, 0x08, 0x97, 0x8c, 0x1a,
-	0xf7, 0x62, 0xde, 0x6e, 0x88, 0xb6, 0x1d, 0xa2, 0x3d, 0x86, 0xdb, 0x2f, 0x24, 0xe3, 0x86, 0x60,
-	0xaa, 0x04, 0x2b, 0xb5, 0x41, 0xe5, 0x00, 0x41, 0xa3, 0x4b, 0x07, 0x7d, 0x3a, 0xa6, 0xbf, 0x31,
-	0x9d, 0x19, 0xb7, 0x42, 0x56, 0xd0, 0xaa, 0x8b, 0x90, 0x0e, 0xdb, 0xfd, 0xaa, 0x80, 0xb0, 0x25,
-	0xe8, 0x44, 0x9f, 0x2b, 0xb0, 0x2d, 0xbf, 0x37, 0x7a, 0x0b, 0x52, 0xc7, 0xa7, 0xad, 0xc3, 0x8e,
-	0x16, 0xd3, 0x6f, 0x4f, 0x67, 0xc6, 0x8e, 0x64, 0xf0, 0x4f, 0x8f, 0x0c, 0xd8, 0xaa, 0x37, 0x3b,
-	0xd5, 0x93, 0x2a, 0x96, 0x90, 0x92, 0x1f, 0x7c, 0x4e, 0x54, 0x82, 0xed, 0xf3, 0x66, 0xbb, 0x7e,
-	0xd2, 0xac, 0x3e, 0xd2, 0xe2, 0xa2, 0x01, 0x4a, 0x11, 0xf9, 0x8d, 0x18, 0x4a, 0xa5, 0xd5, 0x3a,
-	0xad, 0x1e, 0xb2, 0x16, 0xba, 0x82, 0x12, 0xc4, 0x1d, 0x15, 0x20, 0xdd, 0xee, 0xe0, 0x7a, 0xf3,
-	0x44, 0x4b, 0xea, 0x68, 0x3a, 0x33, 0x72, 0x52, 0x40, 0x84, 0x32, 0x30, 0x7c, 0x0f, 0xe0, 0x88,
-	0x8c, 0xc8, 0x85, 0x35, 0xb0, 0xfc, 0x09, 0xd2, 0x61, 0xbb, 0x4f, 0x89, 0x3f, 0x76, 0x83, 0x96,
-	0xa8, 0xe2, 0x70, 0x5f, 0xfa, 0xb3, 0x02, 0xf9, 0x50, 0xd4, 0xa2, 0x5e, 0xd8, 0x45, 0x5b, 0x90,
-	0xbc, 0x24, 0x23, 0x59, 0x61, 0x37, 0x5f, 0x30, 0xd7, 0x01, 0x30, 0xa2, 0x57, 0xb5, 0x7d, 0x77,
-	0x82, 0x39, 0x90, 0xfe, 0x73, 0x50, 0x43, 0x52, 0xb4, 0xb9, 0xab, 0xa2, 0xb9, 0x7f, 0x18, 0x6d,
-	0xee, 0x99, 0x83, 0x77, 0xd7, 0x53, 0x38, 0x09, 0xa6, 0x80, 0x87, 0xf1, 0x0f, 0x94, 0xd2, 0x07,
-	0x90, 0x5b, 0x1d, 0xc9, 0xd9, 0xc4, 0xe0, 0xf9, 0xc4, 0xf5, 0xb9, 0xa2, 0x04, 0x16, 0x1b, 0xa6,
-	0x9c, 0xda, 0x3d, 0xae, 0x28, 0x81, 0xd9, 0xb2, 0xf4, 0x4f, 0x05, 0x72, 0xf2, 0xde, 0x5a, 0x3e,
-	0x28, 0xd8, 0x6d, 0xb1, 0xf6, 0x83, 0xa2, 0x43, 0x4c, 0x4f, 0x3e, 0x28, 0xfc, 0x70, 0xfd, 0xff,
-	0xf6, 0x76, 0xfa, 0x75, 0x1c, 0xb4, 0x0e, 0x31, 0x3f, 0xe2, 0x45, 0xf3, 0x5a, 0xbb, 0x8a, 0xde,
-	0x84, 0xad, 0xa0, 0x3d, 0xf1, 0xd1, 0x40, 0xc5, 0x69, 0xd1, 0x90, 0x4a, 0x65, 0xc8, 0x8b, 0x62,
-	0x91, 0x51, 0x08, 0x32, 0x7e, 0x79, 0xb5, 0xf0, 0x6e, 0x16, 0x5e, 0x2d, 0x9f, 0x29, 0xf0, 0x66,
-	0x83, 0x12, 0x6f, 0xec, 0xd2, 0x21, 0xb5, 0xfd, 0x26, 0x9b, 0x0a, 0x65, 0xe8, 0x1e, 0x40, 0xfa,
-	0xab, 0xa3, 0x86, 0x03, 0x99, 0x57, 0x16, 0xa1, 0xd2, 0x97, 0x0a, 0xdc, 0x8d, 0x98, 0x74, 0x25,
-	0x75, 0x37, 0x33, 0xca, 0x80, 0xcc, 0x70, 0x09, 0xc5, 0x4d, 0x53, 0x71, 0x94, 0xb4, 0x34, 0x3b,
-	0xf1, 0x2a, 0x3f, 0x6c, 0xf2, 0x65, 0x73, 0xf8, 0xf7, 0x71, 0xb8, 0xb7, 0xea, 0xfc, 0x6a, 0x3a,
-	0xbf, 0x6a, 0xf7, 0x23, 0x89, 0x94, 0x88, 0x26, 0xd2, 0x32, 0x2e, 0xc9, 0x57, 0x19, 0x97, 0xd4,
-	0xcb, 0xc6, 0xe5, 0xdf, 0x0a, 0xec, 0x46, 0xe2, 0x72, 0x6c, 0xd1, 0x41, 0xef, 0xeb, 0x92, 0x13,
-	0xff, 0x49, 0xac, 0x14, 0x84, 0xf4, 0x3d, 0xa8, 0x6c, 0x02, 0xe9, 0x3e, 0xa7, 0x04, 0xdd, 0xec,
-	0xe8, 0x46, 0x05, 0xff, 0x13, 0xa7, 0xdc, 0xa0, 0x9e, 0x47, 0x4c, 0xca, 0xa9, 0xe1, 0x2b, 0x91,
-	0x8b, 0xe8, 0xbf, 0x53, 0x20, 0x1b, 0x65, 0x5f, 0xd3, 0xe1, 0x3a, 0xc1, 0xd3, 0x5e, 0x8c, 0x9c,
-	0x3f, 0x7c, 0x49, 0x1b, 0xf8, 0x76, 0xf9, 0xcc, 0x47, 0x6f, 0x81, 0x1a, 0x8e, 0x47, 0xfc, 0x63,
-	0x68, 0x78, 0x49, 0x28, 0x3d, 0x57, 0x40, 0x0d, 0x4f, 0xa0, 0xfb, 0xcb, 0x11, 0x86, 0xcf, 0x0e,
-	0x21, 0x47, 0xcc, 0x30, 0x6f, 0x47, 0x67, 0x18, 0x3e, 0xa0, 0x84, 0x02, 0x72, 0x88, 0xf9, 0xe6,
-	0xca, 0x10, 0xc3, 0x7f, 0x23, 0x84, 0x32, 0xe1, 0x14, 0x53, 0x0c, 0x67, 0x94, 0x60, 0x88, 0x09,
-	0x45, 0xc4, 0xbd, 0xcb, 0x14, 0xc9, 0x31, 0x27, 0x79, 0x45, 0x91, 0x9c, 0x73, 0xbe, 0x0d, 0xea,
-	0x79, 0xf3, 0x51, 0xf5, 0xb8, 0xce, 0x34, 0xa5, 0xc4, 0xff, 0x86, 0x88, 0xa6, 0x1e, 0xed, 0x5b,
-	0x36, 0xed, 0x05, 0xe3, 0xce, 0xdf, 0xe2, 0xa0, 0xb3, 0x21, 0xfd, 0xc7, 0x96, 0xdd, 0x73, 0x7e,
-	0xb9, 0xfc, 0x15, 0xf5, 0x5a, 0xff, 0x1b, 0x34, 0x20, 0x23, 0xfc, 0xad, 0x3e, 0xa1, 0xae, 0xe8,
-	0x71, 0x09, 0x1c, 0x25, 0xad, 0xfe, 0xc4, 0x4b, 0xf1, 0xcc, 0xdf, 0xfc, 0x27, 0x5e, 0xe5, 0xdd,
-	0x67, 0xff, 0x28, 0xc4, 0x9e, 0x2d, 0x0a, 0xca, 0x17, 0x8b, 0x82, 0xf2, 0xf7, 0x45, 0x41, 0xf9,
-	0xec, 0x79, 0x21, 0xf6, 0xc5, 0xf3, 0x42, 0xec, 0xaf, 0xcf, 0x0b, 0xb1, 0x9f, 0xf1, 0xa7, 0x14,
-	0x4b, 0x44, 0xef, 0x22, 0xcd, 0x23, 0xf9, 0xde, 0x7f, 0x03, 0x00, 0x00, 0xff, 0xff, 0x38, 0x44,
-	0x05, 0x81, 0x01, 0x17, 0x00, 0x00,
+	// 1783 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe4, 0x58, 0xcd, 0x8f, 0x1b, 0x49,
+	0x15, 0x77, 0xfb, 0x6b, 0xa6, 0x9f, 0x3d, 0x4e, 0x4f, 0xad, 0xc9, 0x4e, 0x3a, 0x1b, 0xbb, 0x63,
+	0x60, 0x77, 0x24, 0x82, 0x47, 0x9a, 0x5d, 0xa4, 0x55, 0x60, 0x25, 0xec, 0x89, 0x67, 0x6c, 0x32,
+	0xb6, 0x47, 0x65, 0xcf, 0xf2, 0x71, 0x31, 0x35, 0xe3, 0x72, 0x6f, 0x6b, 0xed, 0x6e, 0xd3, 0xdd,
+	0x0e, 0xb1, 0xc4, 0x85, 0xdb, 0xca, 0xa7, 0x45, 0x82, 0x0b, 0xc8, 0x27, 0x8e, 0xdc, 0xf9, 0x1b,
+	0x82, 0xc4, 0x61, 0x8f, 0x88, 0x83, 0x05, 0x8e, 0x84, 0xc4, 0x99, 0x13, 0xcb, 0x05, 0x55, 0x55,
+	0x7f, 0x79, 0x62, 0x26, 0x9e, 0x28, 0x07, 0x94, 0xbd, 0x55, 0xbd, 0xf7, 0xea, 0xf7, 0x3e, 0xfa,
+	0xbd, 0x7a, 0xaf, 0x0b, 0xf2, 0x8e, 0x6b, 0xd9, 0x44, 0xa7, 0xbd, 0x4b, 0x6b, 0x34, 0xb2, 0xcc,
+	0xf2, 0xd8, 0xb6, 0x5c, 0x0b, 0xdd, 0x35, 0xcc, 0xc1, 0x70, 0xf2, 0xb4, 0x4f, 0x5c, 0x52, 0x1e,
+	0x0f, 0x89, 0x3b, 0xb0, 0xec, 0x51, 0xd9, 0x93, 0x54, 0xf3, 0xba, 0xa5, 0x5b, 0x5c, 0xee, 0x80,
+	0xad, 0xc4, 0x11, 0xf5, 0x8e, 0x6e, 0x59, 0xfa, 0x90, 0x1e, 0xf0, 0xdd, 0xc5, 0x64, 0x70, 0x40,
+	0xcc, 0xa9, 0xc7, 0xba, 0x35, 0xb6, 0x69, 0xdf, 0xb8, 0x24, 0x2e, 0x15, 0x84, 0xd2, 0x3f, 0x25,
+	0xd8, 0xc5, 0x94, 0xf4, 0x8f, 0x8d, 0xa1, 0x4b, 0x6d, 0x4c, 0x7f, 0x36, 0xa1, 0x8e, 0x8b, 0x6a,
+	0x90, 0xb1, 0x29, 0xe9, 0xf7, 0x1c, 0x6b, 0x62, 0x5f, 0xd2, 0x3d, 0x49, 0x93, 0xf6, 0x33, 0x87,
+	0xf9, 0xb2, 0xc0, 0x2d, 0xfb, 0xb8, 0xe5, 0x8a, 0x39, 0xad, 0xe6, 0x96, 0x8b, 0x22, 0x30, 0x84,
+	0x0e, 0x97, 0xc5, 0x60, 0x07, 0x6b, 0x74, 0x02, 0x29, 0x9b, 0x98, 0x3a, 0xdd, 0x8b, 0x73, 0x80,
+	0x6f, 0x95, 0xaf, 0xf1, 0xa5, 0xdc, 0x35, 0x46, 0xd4, 0x71, 0xc9, 0x68, 0x8c, 0xd9, 0x91, 0x6a,
+	0xf2, 0xd9, 0xa2, 0x18, 0xc3, 0xe2, 0x3c, 0x7a, 0x04, 0x72, 0x60, 0xf8, 0x5e, 0x82, 0x83, 0xbd,
+	0x7b, 0x2d, 0xd8, 0x99, 0x2f, 0x8d, 0xc3, 0x83, 0xa5, 0x3f, 0xa7, 0x40, 0x61, 0x96, 0x9e, 0xd8,
+	0xd6, 0x64, 0xfc, 0x46, 0xbb, 0x8a, 0x1e, 0x00, 0xe8, 0xcc, 0xcb, 0xde, 0xa7, 0x74, 0xea, 0xec,
+	0x25, 0xb5, 0xc4, 0xbe, 0x5c, 0xdd, 0x59, 0x2e, 0x8a, 0x32, 0xf7, 0xfd, 0x31, 0x9d, 0x3a, 0x58,
+	0xd6, 0xfd, 0x25, 0x6a, 0x40, 0x8a, 0x6f, 0xf6, 0x52, 0x9a, 0xb4, 0x9f, 0x3b, 0x7c, 0xff, 0x5a,
+	0x7d, 0x57, 0x23, 0x58, 0x16, 0x1b, 0x81, 0xc0, 0xcc, 0x27, 0xba, 0x6e, 0x53, 0x9d, 0x99, 0x9f,
+	0xde, 0xc0, 0xfc, 0x8a, 0x2f, 0x8d, 0xc3, 0x83, 0xe8, 0x01, 0xa4, 0x3e, 0x31, 0x4c, 0xd7, 0xd9,
+	0xdb, 0xd2, 0xa4, 0xfd, 0xad, 0xea, 0xed, 0xe5, 0xa2, 0x98, 0xaa, 0x33, 0xc2, 0x97, 0x8b, 0xa2,
+	0xcc, 0x16, 0xc7, 0x43, 0xa2, 0x3b, 0x58, 0x08, 0x95, 0x4e, 0x20, 0xc5, 0x6d, 0x40, 0xf7, 0x00,
+	0x4e, 0x70, 0xfb, 0xfc, 0xac, 0xd7, 0x6a, 0xb7, 0x6a, 0x4a, 0x4c, 0xdd, 0x99, 0xcd, 0x35, 0xe1,
+	0x71, 0xcb, 0x32, 0x29, 0xba, 0x03, 0xdb, 0x82, 0x5d, 0xfd, 0xb1, 0x12, 0x57, 0x33, 0xb3, 0xb9,
+	0xb6, 0xc5, 0x99, 0xd5, 0xa9, 0x9a, 0xfc, 0xec, 0xf7, 0x85, 0x58, 0xe9, 0x0f, 0x12, 0x84, 0xe8,
+	0xe8, 0x2e, 0xc8, 0xf5, 0x46, 0xab, 0xeb, 0x83, 0x65, 0x67, 0x73, 0x6d, 0x9b, 0x71, 0x39, 0xd6,
+	0x37, 0x20, 0xe7, 0x31, 0x7b, 0x67, 0xed, 0x46, 0xab, 0xdb, 0x51, 0x24, 0x55, 0x99, 0xcd, 0xb5,
+	0xac, 0x90, 0x38, 0xb3, 0x98, 0x65, 0x51, 0xa9, 0x4e, 0x0d, 0x37, 0x6a, 0x1d, 0x25, 0x1e, 0x95,
+	0xea, 0x50, 0xdb, 0xa0, 0x0e, 0x3a, 0x80, 0x3c, 0x97, 0xea, 0x1c, 0xd5, 0x6b, 0xcd, 0x4a, 0xaf,
+	0x72, 0x7a, 0xda, 0xeb, 0x36, 0x9a, 0x35, 0x25, 0xa9, 0x7e, 0x6d, 0x36, 0xd7, 0x76, 0x99, 0x6c,
+	0xe7, 0xf2, 0x13, 0x3a, 0x22, 0x95, 0xe1, 0x90, 0xa5, 0x8e, 0x67, 0xed, 0xbf, 0xe2, 0x20, 0x07,
+	0xd1, 0x43, 0x75, 0x48, 0xba, 0xd3, 0xb1, 0x48, 0xe0, 0xdc, 0xe1, 0x07, 0x9b, 0xc5, 0x3c, 0x5c,
+	0x75, 0xa7, 0x63, 0x8a, 0x39, 0x42, 0xe9, 0x77, 0x71, 0xd8, 0x59, 0xa1, 0xa3, 0x22, 0x24, 0xbd,
+	0x20, 0x70, 0x83, 0x56, 0x98, 0x3c, 0x1a, 0xf7, 0x20, 0xd1, 0x39, 0x6f, 0x2a, 0x92, 0x9a, 0x9f,
+	0xcd, 0x35, 0x65, 0x85, 0xdf, 0x99, 0x8c, 0xd0, 0x7d, 0x48, 0x1d, 0xb5, 0xcf, 0x5b, 0x5d, 0x25,
+	0xae, 0xde, 0x9e, 0xcd, 0x35, 0xb4, 0x22, 0x70, 0x64, 0x4d, 0x4c, 0x97, 0x21, 0x34, 0x1b, 0x2d,
+	0x25, 0xb1, 0x06, 0xa1, 0x69, 0x98, 0x9c, 0x5d, 0xf9, 0x91, 0x92, 0x5c, 0xc7, 0x26, 0x4f, 0x99,
+	0x82, 0xe3, 0x06, 0xee, 0x74, 0x95, 0xd4, 0x1a, 0x05, 0xc7, 0x86, 0xed, 0xb8, 0xcc, 0x87, 0xd3,
+	0x4a, 0xa7, 0xab, 0xa4, 0xd7, 0xf8, 0x70, 0x4a, 0x84, 0x40, 0xb3, 0x56, 0x69, 0x29, 0x5b, 0x6b,
+	0x04, 0x9a, 0x94, 0x98, 0x5e, 0xd4, 0xbf, 0x0d, 0x89, 0x2e, 0xd1, 0x91, 0x02, 0x89, 0x4f, 0xe9,
+	0x94, 0x47, 0x3b, 0x8b, 0xd9, 0x12, 0xe5, 0x21, 0xf5, 0x84, 0x0c, 0x27, 0xe2, 0x06, 0xc8, 0x62,
+	0xb1, 0x29, 0xfd, 0x2a, 0x07, 0x59, 0x56, 0x31, 0x98, 0x3a, 0x63, 0xcb, 0x74, 0x28, 0x6a, 0x42,
+	0x7a, 0x60, 0x93, 0x11, 0x75, 0xf6, 0x24, 0x2d, 0xb1, 0x9f, 0x39, 0x3c, 0x78, 0x69, 0xb1, 0xf9,
+	0x47, 0xcb, 0xc7, 0xec, 0x9c, 0x77, 0x5b, 0x78, 0x20, 0xea, 0x67, 0x69, 0x48, 0x71, 0x3a, 0x3a,
+	0xf5, 0x8b, 0x78, 0x8b, 0x57, 0xdd, 0x07, 0x9b, 0xe3, 0xf2, 0x22, 0xe0, 0x20, 0xf5, 0x98, 0x5f,
+	0xc7, 0x6d, 0x48, 0x3b, 0x3c, 0x3b, 0xbd, 0x1b, 0xf1, 0x3b, 0x9b, 0xc3, 0x89, 0xac, 0xf6, 0xf1,
+	0x3c, 0x18, 0x34, 0x86, 0xec, 0x60, 0x68, 0x11, 0xb7, 0x37, 0xe6, 0xa5, 0xe1, 0xdd, 0x93, 0x0f,
+	0x6f, 0xe0, 0x3d, 0x3b, 0x2d, 0xea, 0x4a, 0x04, 0xe2, 0xd6, 0x72, 0x51, 0xcc, 0x44, 0xa8, 0xf5,
+	0x18, 0xce, 0x0c, 0xc2, 0x2d, 0x7a, 0x0a, 0x39, 0xc3, 0x74, 0xa9, 0x4e, 0x6d, 0x5f, 0xa7, 0xb8,
+	0x4e, 0xbf, 0xb7, 0xb9, 0xce, 0x86, 0x38, 0x1f, 0xd5, 0xba, 0xbb, 0x5c, 0x14, 0x77, 0x56, 0xe8,
+	0xf5, 0x18, 0xde, 0x31, 0xa2, 0x04, 0xf4, 0x0b, 0xb8, 0x35, 0x31, 0x1d, 0x43, 0x37, 0x69, 0xdf,
+	0x57, 0x9d, 0xe4, 0xaa, 0x3f, 0xda, 0x5c, 0xf5, 0xb9, 0x07, 0x10, 0xd5, 0x8d, 0x96, 0x8b, 0x62,
+	0x6e, 0x95, 0x51, 0x8f, 0xe1, 0xdc, 0x64, 0x85, 0xc2, 0xfc, 0xbe, 0xb0, 0xac, 0x21, 0x25, 0xa6,
+	0xaf, 0x3c, 0x75, 0x53, 0xbf, 0xab, 0xe2, 0xfc, 0x0b, 0x7e, 0xaf, 0xd0, 0x99, 0xdf, 0x17, 0x51,
+	0x02, 0x72, 0x61, 0xc7, 0x71, 0x6d, 0xc3, 0xd4, 0x7d, 0xc5, 0xa2, 0x01, 0x7c, 0xf7, 0x06, 0xb9,
+	0xc3, 0x8f, 0x47, 0xf5, 0x2a, 0xcb, 0x45, 0x31, 0x1b, 0x25, 0xd7, 0x63, 0x38, 0xeb, 0x44, 0xf6,
+	0xd5, 0x34, 0x24, 0x19, 0xb2, 0xfa, 0x14, 0x20, 0xcc, 0x64, 0xf4, 0x2e, 0x6c, 0xbb, 0x44, 0x17,
+	0xfd, 0x8f, 0x55, 0x5a, 0xb6, 0x9a, 0x59, 0x2e, 0x8a, 0x5b, 0x5d, 0xa2, 0xf3, 0xee, 0xb7, 0xe5,
+	0x8a, 0x05, 0xaa, 0x02, 0x1a, 0x13, 0xdb, 0x35, 0x5c, 0xc3, 0x32, 0x99, 0x74, 0xef, 0x09, 0x19,
+	0xb2, 0xec, 0x64, 0x27, 0xf2, 0xcb, 0x45, 0x51, 0x39, 0xf3, 0xb9, 0x8f, 0xe9, 0xf4, 0x63, 0x32,
+	0x74, 0xb0, 0x32, 0xbe, 0x42, 0x51, 0x7f, 0x2b, 0x41, 0x26, 0x92, 0xf5, 0xe8, 0x21, 0x24, 0x5d,
+	0xa2, 0xfb, 0x15, 0xae, 0x5d, 0x3f, 0x0b, 0x10, 0xdd, 0x2b, 0x69, 0x7e, 0x06, 0xb5, 0x41, 0x66,
+	0x82, 0x3d, 0x7e, 0x99, 0xc7, 0xf9, 0x65, 0x7e, 0xb8, 0x79, 0xfc, 0x1e, 0x11, 0x97, 0xf0, 0xab,
+	0x7c, 0xbb, 0xef, 0xad, 0xd4, 0x1f, 0x80, 0x72, 0xb5, 0x74, 0x50, 0x01, 0xc0, 0xf5, 0x67, 0x10,
+	0x61, 0xa6, 0x82, 0x23, 0x14, 0x74, 0x1b, 0xd2, 0xfc, 0xfa, 0x12, 0x81, 0x90, 0xb0, 0xb7, 0x53,
+	0x4f, 0x01, 0xbd, 0x58, 0x12, 0x37, 0x44, 0x4b, 0x04, 0x68, 0x4d, 0x78, 0x6b, 0x4d, 0x96, 0xdf,
+	0x10, 0x2e, 0x19, 0x35, 0xee, 0xc5, 0xbc, 0xbd, 0x21, 0xda, 0x76, 0x80, 0xf6, 0x18, 0x76, 0x5f,
+	0x48, 0xc6, 0x1b, 0x82, 0xc9, 0x3e, 0x58, 0xa9, 0x03, 0x32, 0x07, 0xf0, 0xba, 0x69, 0xda, 0x1b,
+	0x06, 0x62, 0xea, 0x5b, 0xb3, 0xb9, 0x76, 0x2b, 0x60, 0x79, 0xf3, 0x40, 0x11, 0xd2, 0xc1, 0x4c,
+	0xb1, 0x2a, 0x20, 0x6c, 0xf1, 0x3a, 0xd1, 0x1f, 0x25, 0xd8, 0xf6, 0xbf, 0x37, 0x7a, 0x07, 0x52,
+	0xc7, 0xa7, 0xed, 0x4a, 0x57, 0x89, 0xa9, 0xbb, 0xb3, 0xb9, 0xb6, 0xe3, 0x33, 0xf8, 0xa7, 0x47,
+	0x1a, 0x6c, 0x35, 0x5a, 0xdd, 0xda, 0x49, 0x0d, 0xfb, 0x90, 0x3e, 0xdf, 0xfb, 0x9c, 0xa8, 0x04,
+	0xdb, 0xe7, 0xad, 0x4e, 0xe3, 0xa4, 0x55, 0x7b, 0xa4, 0xc4, 0x45, 0x97, 0xf5, 0x45, 0xfc, 0x6f,
+	0xc4, 0x50, 0xaa, 0xed, 0xf6, 0x29, 0x6b, 0x92, 0x89, 0x55, 0x14, 0x2f, 0xee, 0xa8, 0x00, 0xe9,
+	0x4e, 0x17, 0x37, 0x5a, 0x27, 0x4a, 0x52, 0x45, 0xb3, 0xb9, 0x96, 0xf3, 0x05, 0x44, 0x28, 0x3d,
+	0xc3, 0xf7, 0x01, 0x8e, 0xc8, 0x98, 0x5c, 0x18, 0x43, 0xc3, 0x9d, 0x22, 0x15, 0xb6, 0x07, 0x94,
+	0xb8, 0x13, 0xdb, 0x6b, 0x89, 0x32, 0x0e, 0xf6, 0xa5, 0x3f, 0x49, 0x90, 0x0f, 0x44, 0x0d, 0xea,
+	0x04, 0x5d, 0xb4, 0x0d, 0xc9, 0x4b, 0x32, 0xf6, 0x2b, 0xec, 0xfa, 0x0b, 0x66, 0x1d, 0x00, 0x23,
+	0x3a, 0x35, 0xd3, 0xb5, 0xa7, 0x98, 0x03, 0xa9, 0x3f, 0x05, 0x39, 0x20, 0x45, 0x9b, 0xbb, 0x2c,
+	0x9a, 0xfb, 0x47, 0xd1, 0xe6, 0x9e, 0x39, 0x7c, 0x6f, 0x33, 0x85, 0x53, 0x6f, 0x0a, 0x78, 0x18,
+	0xff, 0x50, 0x2a, 0x7d, 0x08, 0xb9, 0xd5, 0xb9, 0x9f, 0x4d, 0x0c, 0x8e, 0x4b, 0x6c, 0x97, 0x2b,
+	0x4a, 0x60, 0xb1, 0x61, 0xca, 0xa9, 0xd9, 0xe7, 0x8a, 0x12, 0x98, 0x2d, 0x4b, 0xff, 0x90, 0x20,
+	0xe7, 0xdf, 0x5b, 0xe1, 0x5f, 0x0b, 0xbb, 0x2d, 0x36, 0xfe, 0x6b, 0xe9, 0x12, 0xdd, 0xf1, 0xff,
+	0x5a, 0xdc, 0x60, 0xfd, 0xff, 0xf6, 0x83, 0xf6, 0xcb, 0x38, 0x28, 0x5d, 0xa2, 0x7f, 0xcc, 0x8b,
+	0xe6, 0x8d, 0x76, 0x15, 0xbd, 0x0d, 0x5b, 0x5e, 0x7b, 0xe2, 0xa3, 0x81, 0x8c, 0xd3, 0xa2, 0x21,
+	0x95, 0xca, 0x90, 0x17, 0xc5, 0xe2, 0x47, 0xc1, 0xcb, 0xf8, 0xf0, 0x6a, 0xe1, 0xdd, 0x2c, 0xb8,
+	0x5a, 0x3e, 0x97, 0xe0, 0xed, 0x26, 0x25, 0xce, 0xc4, 0xa6, 0x23, 0x6a, 0xba, 0x2d, 0x32, 0x0a,
+	0x43, 0xf7, 0x00, 0xd2, 0x2f, 0x8f, 0x1a, 0x4e, 0x3b, 0xaf, 0x37, 0x42, 0xa5, 0x2f, 0x25, 0xb8,
+	0x13, 0x31, 0xe9, 0x4a, 0xea, 0xde, 0xcc, 0x28, 0x0d, 0x32, 0xa3, 0x10, 0x8a, 0x9b, 0x26, 0xe3,
+	0x28, 0x29, 0x34, 0x3b, 0xf1, 0x3a, 0x3f, 0x6c, 0xf2, 0x55, 0x73, 0xf8, 0x37, 0x71, 0xb8, 0xbb,
+	0xea, 0xfc, 0x6a, 0x3a, 0xbf, 0x6e, 0xf7, 0x23, 0x89, 0x94, 0x88, 0x26, 0x52, 0x18, 0x97, 0xe4,
+	0xeb, 0x8c, 0x4b, 0xea, 0x55, 0xe3, 0xf2, 0x6f, 0x09, 0xf6, 0x22, 0x71, 0x39, 0x36, 0xe8, 0xb0,
+	0xff, 0x55, 0xc9, 0x89, 0xff, 0x24, 0xe0, 0xce, 0x1a, 0xdf, 0xbd, 0xca, 0x26, 0x90, 0x1e, 0x70,
+	0x8a, 0xd7, 0xcd, 0x8e, 0xae, 0x55, 0xf0, 0x3f, 0x71, 0xca, 0x4d, 0xea, 0x38, 0x44, 0xa7, 0x9c,
+	0x1a, 0xfc, 0x25, 0x72, 0x11, 0xf5, 0xd7, 0x12, 0x64, 0xa3, 0xec, 0x35, 0x1d, 0xae, 0xeb, 0xbd,
+	0x1f, 0x88, 0x91, 0xf3, 0xfb, 0xaf, 0x68, 0x03, 0xdf, 0x86, 0x6f, 0x09, 0xe8, 0x1d, 0x90, 0x83,
+	0xf1, 0x88, 0x7f, 0x0c, 0x05, 0x87, 0x84, 0xd2, 0x73, 0x09, 0xe4, 0xe0, 0x04, 0xba, 0x17, 0x8e,
+	0x30, 0x7c, 0x76, 0x08, 0x38, 0x62, 0x86, 0xb9, 0x1f, 0x9d, 0x61, 0xf8, 0x80, 0x12, 0x08, 0xf8,
+	0x43, 0xcc, 0xd7, 0x57, 0x86, 0x18, 0xfe, 0x1b, 0x1f, 0xc8, 0x04, 0x53, 0x4c, 0x31, 0x98, 0x51,
+	0xbc, 0x21, 0x26, 0x10, 0x11, 0xf7, 0x2e, 0xba, 0x1f, 0x8e, 0x39, 0xc9, 0x2b, 0x8a, 0xfc, 0x39,
+	0xe7, 0x9b, 0x20, 0x9f, 0xb7, 0x1e, 0xd5, 0x8e, 0x1b, 0x4c, 0x93, 0xf7, 0xe6, 0x10, 0xd1, 0xd4,
+	0xa7, 0x03, 0xc3, 0xa4, 0x7d, 0x6f, 0xdc, 0xf9, 0x6b, 0x1c, 0x54, 0x36, 0xa4, 0xff, 0xd0, 0x30,
+	0xfb, 0xd6, 0xcf, 0xc3, 0xf7, 0xae, 0x37, 0xfa, 0x01, 0x52, 0x83, 0x8c, 0xf0, 0xb7, 0xf6, 0x84,
+	0xda, 0xa2, 0xc7, 0x25, 0x70, 0x94, 0xb4, 0xfa, 0x52, 0x98, 0xd2, 0x12, 0x2f, 0xd5, 0xb3, 0xee,
+	0xa5, 0xb0, 0xfa, 0xde, 0xb3, 0xbf, 0x17, 0x62, 0xcf, 0x96, 0x05, 0xe9, 0x8b, 0x65, 0x41, 0xfa,
+	0xdb, 0xb2, 0x20, 0x7d, 0xfe, 0xbc, 0x10, 0xfb, 0xe2, 0x79, 0x21, 0xf6, 0x97, 0xe7, 0x85, 0xd8,
+	0x4f, 0xf8, 0xaf, 0x14, 0x4b, 0x44, 0xe7, 0x22, 0xcd, 0x23, 0xf9, 0xfe, 0x7f, 0x07, 0x00, 0xe2,
+	0x9a, 0x8d, 0x6a, 0x66, 0x17, 0x00, 0x00,
 }
 
 func (m *ReadFilterRequest) Marshal() (dAtA []byte, err error) {
@@ -57,7 +57,9 @@ func NewGroupResultSet(ctx context.Context, req *datatypes.ReadGroupRequest, new
 		o(g)
 	}
 
-	g.arrayCursors = newArrayCursors(ctx, req.Range.Start, req.Range.End, true)
+	// The last point of each series is the first read in descending order.
+	asc := req.Aggregate == nil || req.Aggregate.Type != datatypes.AggregateTypeLast
+	g.arrayCursors = newArrayCursors(ctx, req.Range.Start, req.Range.End, asc)
 
 	for i, k := range req.GroupKeys {
 		g.keys[i] = []byte(k)
@@ -273,9 +275,10 @@ type groupNoneCursor struct {
 	cur          SeriesCursor
 	row          SeriesRow
 	keys         [][]byte
+	err          error
 }
 
-func (c *groupNoneCursor) Err() error                 { return nil }
+func (c *groupNoneCursor) Err() error                 { return c.err }
 func (c *groupNoneCursor) Tags() models.Tags          { return c.row.Tags }
 func (c *groupNoneCursor) Keys() [][]byte             { return c.keys }
 func (c *groupNoneCursor) PartitionKeyVals() [][]byte { return nil }
@@ -296,7 +299,7 @@ func (c *groupNoneCursor) Next() bool {
 func (c *groupNoneCursor) Cursor() cursors.Cursor {
 	cur := c.arrayCursors.createCursor(c.row)
 	if c.agg != nil {
-		cur = newAggregateArrayCursor(c.ctx, c.agg, cur)
+		cur, c.err = aggregateCursor(c.ctx, c.agg, cur)
 	}
 	return cur
 }
@@ -309,6 +312,7 @@ type groupByCursor struct {
 	seriesRows   []*SeriesRow
 	keys         [][]byte
 	vals         [][]byte
+	err          error
 }
 
 func (c *groupByCursor) reset(seriesRows []*SeriesRow) {
@@ -316,7 +320,7 @@ func (c *groupByCursor) reset(seriesRows []*SeriesRow) {
 	c.seriesRows = seriesRows
 }
 
-func (c *groupByCursor) Err() error                 { return nil }
+func (c *groupByCursor) Err() error                 { return c.err }
 func (c *groupByCursor) Keys() [][]byte             { return c.keys }
 func (c *groupByCursor) PartitionKeyVals() [][]byte { return c.vals }
 func (c *groupByCursor) Tags() models.Tags          { return c.seriesRows[c.i-1].Tags }
@@ -333,11 +337,24 @@ func (c *groupByCursor) Next() bool {
 func (c *groupByCursor) Cursor() cursors.Cursor {
 	cur := c.arrayCursors.createCursor(*c.seriesRows[c.i-1])
 	if c.agg != nil {
-		cur = newAggregateArrayCursor(c.ctx, c.agg, cur)
+		cur, c.err = aggregateCursor(c.ctx, c.agg, cur)
 	}
 	return cur
 }
 
+// aggregateCursor wraps cur with a cursor computing agg. It returns an error
+// if agg is not supported for the type of cur.
+func aggregateCursor(ctx context.Context, agg *datatypes.Aggregate, cur cursors.Cursor) (cursors.Cursor, error) {
+	if cur == nil {
+		return nil, nil
+	}
+	if aggCur := newAggregateArrayCursor(ctx, agg, cur); aggCur != nil {
+		return aggCur, nil
+	}
+	cur.Close()
+	return nil, fmt.Errorf("unsupported aggregate %s for %s", agg.Type, cursorTypeName(cur))
+}
+
 func (c *groupByCursor) Stats() cursors.CursorStats {
 	var stats cursors.CursorStats
 	for _, seriesRow := range c.seriesRows {
@@ -2,6 +2,8 @@ package reads
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/influxdata/influxdb/v2/models"
 	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
@@ -14,6 +16,8 @@ type resultSet struct {
 	seriesCursor SeriesCursor
 	seriesRow    SeriesRow
 	arrayCursors *arrayCursors
+	every        int64
+	err          error
 }
 
 func NewFilteredResultSet(ctx context.Context, req *datatypes.ReadFilterRequest, seriesCursor SeriesCursor) ResultSet {
@@ -24,7 +28,27 @@ func NewFilteredResultSet(ctx context.Context, req *datatypes.ReadFilterRequest,
 	}
 }
 
-func (r *resultSet) Err() error { return nil }
+// NewWindowAggregateResultSet returns a result set that computes the
+// aggregate of req over consecutive windows of each series.
+func NewWindowAggregateResultSet(ctx context.Context, req *datatypes.ReadWindowAggregateRequest, seriesCursor SeriesCursor) (ResultSet, error) {
+	if len(req.Aggregate) != 1 {
+		return nil, errors.New("exactly one aggregate is required")
+	}
+	if req.WindowEvery <= 0 {
+		return nil, errors.New("window every must be positive")
+	}
+	agg := req.Aggregate[0]
+	asc := req.WindowEvery != infiniteWindow || agg.Type != datatypes.AggregateTypeLast
+	return &resultSet{
+		ctx:          ctx,
+		agg:          agg,
+		every:        req.WindowEvery,
+		seriesCursor: seriesCursor,
+		arrayCursors: newArrayCursors(ctx, req.Range.Start, req.Range.End, asc),
+	}, nil
+}
+
+func (r *resultSet) Err() error { return r.err }
 
 // Close closes the result set. Close is idempotent.
 func (r *resultSet) Close() {
@@ -37,7 +61,7 @@ func (r *resultSet) Close() {
 
 // Next returns true if there are more results available.
 func (r *resultSet) Next() bool {
-	if r == nil {
+	if r == nil || r.err != nil {
 		return false
 	}
 
@@ -53,6 +77,17 @@ func (r *resultSet) Next() bool {
 
 func (r *resultSet) Cursor() cursors.Cursor {
 	cur := r.arrayCursors.createCursor(r.seriesRow)
+	if r.every > 0 {
+		if cur == nil {
+			return nil
+		}
+		agg := newWindowAggregateArrayCursor(r.agg.Type, r.every, cur)
+		if agg == nil {
+			r.err = fmt.Errorf("unsupported aggregate %s for %s", r.agg.Type, cursorTypeName(cur))
+			cur.Close()
+		}
+		return agg
+	}
 	if r.agg != nil {
 		cur = newAggregateArrayCursor(r.ctx, r.agg, cur)
 	}
@@ -83,14 +83,19 @@ type Store interface {
 	GetSource(orgID, bucketID uint64) proto.Message
 }
 
-// WindowAggregateCapability describes what is supported by WindowAggregateStore.
-type WindowAggregateCapability struct{}
+// WindowAggregateCapability describes a window aggregate read, whose
+// support is checked by WindowAggregateStore. A WindowEvery of math.MaxInt64
+// is a single window spanning the range of the read.
+type WindowAggregateCapability struct {
+	Aggregates  []datatypes.Aggregate_AggregateType
+	WindowEvery int64
+}
 
 // WindowAggregateStore implements the WindowAggregate capability.
 type WindowAggregateStore interface {
-	// HasWindowAggregateCapability checks if this Store supports the capability.
-	// If WindowAggregateCapability is passed to the method, then the struct
-	// is filled with a detailed list of what the RPC call supports.
+	// HasWindowAggregateCapability checks if this Store supports the
+	// capability. If a WindowAggregateCapability is passed to the method,
+	// the Store checks that it supports the described read.
 	HasWindowAggregateCapability(ctx context.Context, capability ...*WindowAggregateCapability) bool
 
 	// WindowAggregate will invoke a ReadWindowAggregateRequest against the Store.
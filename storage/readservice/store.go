@@ -3,8 +3,10 @@ package readservice
 import (
 	"context"
 	"errors"
+	"math"
 
 	"github.com/gogo/protobuf/proto"
+	"github.com/influxdata/influxdb/v2/kit/feature"
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	"github.com/influxdata/influxdb/v2/models"
 	"github.com/influxdata/influxdb/v2/storage/reads"
@@ -154,12 +156,54 @@ func (s *store) GetSource(orgID, bucketID uint64) proto.Message {
 	}
 }
 
+// HasWindowAggregateCapability reports whether the store computes the
+// aggregates of each capability over windows of its width. Aggregates over
+// windows are enabled by the pushDownWindowAggregate feature flag, and over
+// the whole range of a read by pushDownGroupAggregate. Without a capability,
+// it reports whether either is enabled.
 func (s *store) HasWindowAggregateCapability(ctx context.Context, capability ...*reads.WindowAggregateCapability) bool {
-	return false
+	windowed := feature.PushDownWindowAggregate().Enabled(ctx)
+	bare := feature.PushDownGroupAggregate().Enabled(ctx)
+	if len(capability) == 0 {
+		return windowed || bare
+	}
+
+	for _, c := range capability {
+		if c.WindowEvery == math.MaxInt64 && !bare || c.WindowEvery != math.MaxInt64 && !windowed {
+			return false
+		}
+		if c.WindowEvery <= 0 || len(c.Aggregates) != 1 || !reads.IsWindowAggregateSupported(c.Aggregates[0]) {
+			return false
+		}
+	}
+	return true
 }
 
 // WindowAggregate will invoke a ReadWindowAggregateRequest against the Store.
 func (s *store) WindowAggregate(ctx context.Context, req *datatypes.ReadWindowAggregateRequest) (reads.ResultSet, error) {
-	// TODO: https://github.com/influxdata/idpe/issues/6805
-	return nil, errors.New("WindowAggregate is not implemented")
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if req.ReadSource == nil {
+		return nil, tracing.LogError(span, errors.New("missing read source"))
+	}
+
+	source, err := getReadSource(*req.ReadSource)
+	if err != nil {
+		return nil, tracing.LogError(span, err)
+	}
+
+	var cur reads.SeriesCursor
+	if cur, err = reads.NewIndexSeriesCursor(ctx, source.GetOrgID(), source.GetBucketID(), req.Predicate, s.viewer); err != nil {
+		return nil, tracing.LogError(span, err)
+	} else if cur == nil {
+		return nil, nil
+	}
+
+	rs, err := reads.NewWindowAggregateResultSet(ctx, req, cur)
+	if err != nil {
+		cur.Close()
+		return nil, tracing.LogError(span, err)
+	}
+	return rs, nil
 }
@@ -0,0 +1,64 @@
+package readservice
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2/kit/feature"
+	"github.com/influxdata/influxdb/v2/kit/feature/override"
+	"github.com/influxdata/influxdb/v2/storage/reads"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+)
+
+func TestStore_HasWindowAggregateCapability(t *testing.T) {
+	flagContext := func(windowed, bare string) context.Context {
+		flagger, err := override.Make(map[string]string{
+			feature.PushDownWindowAggregate().Key(): windowed,
+			feature.PushDownGroupAggregate().Key():  bare,
+		}, feature.ByKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, err := feature.Annotate(context.Background(), flagger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ctx
+	}
+	windowed := &reads.WindowAggregateCapability{
+		Aggregates:  []datatypes.Aggregate_AggregateType{datatypes.AggregateTypeMean},
+		WindowEvery: 10,
+	}
+	bare := &reads.WindowAggregateCapability{
+		Aggregates:  []datatypes.Aggregate_AggregateType{datatypes.AggregateTypeSum},
+		WindowEvery: math.MaxInt64,
+	}
+	unsupported := &reads.WindowAggregateCapability{
+		Aggregates:  []datatypes.Aggregate_AggregateType{datatypes.AggregateTypeNone},
+		WindowEvery: 10,
+	}
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		capability []*reads.WindowAggregateCapability
+		want       bool
+	}{
+		{name: "flags disabled", ctx: flagContext("false", "false"), want: false},
+		{name: "any", ctx: flagContext("true", "false"), want: true},
+		{name: "windowed", ctx: flagContext("true", "false"), capability: []*reads.WindowAggregateCapability{windowed}, want: true},
+		{name: "windowed disabled", ctx: flagContext("false", "true"), capability: []*reads.WindowAggregateCapability{windowed}, want: false},
+		{name: "bare", ctx: flagContext("false", "true"), capability: []*reads.WindowAggregateCapability{bare}, want: true},
+		{name: "bare disabled", ctx: flagContext("true", "false"), capability: []*reads.WindowAggregateCapability{bare}, want: false},
+		{name: "unsupported aggregate", ctx: flagContext("true", "true"), capability: []*reads.WindowAggregateCapability{unsupported}, want: false},
+	}
+	s := &store{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.HasWindowAggregateCapability(tt.ctx, tt.capability...); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package wal
+
+import (
+	"context"
+	"fmt"
+)
+
+// Durability controls when a write to the WAL is acknowledged.
+type Durability int
+
+const (
+	// DurabilityDefault uses the durability the WAL was configured with.
+	DurabilityDefault Durability = iota
+
+	// DurabilitySync fsyncs the WAL before each write is acknowledged.
+	DurabilitySync
+
+	// DurabilityGroup batches concurrent writes into a single fsync. A write is
+	// acknowledged once the fsync of its batch completes, which happens at most
+	// the fsync delay after the batch started, or as soon as the batch holds
+	// the maximum number of writes.
+	DurabilityGroup
+
+	// DurabilityAsync acknowledges writes once they are buffered for the
+	// current segment. Segments are flushed and fsynced periodically in the
+	// background, so writes acknowledged since the last flush may be lost if
+	// the process crashes.
+	DurabilityAsync
+
+	// DurabilityNone skips the WAL. Writes only become durable once the cache
+	// is snapshotted to TSM files.
+	DurabilityNone
+)
+
+// String returns the name of d, as accepted by ParseDurability.
+func (d Durability) String() string {
+	switch d {
+	case DurabilityDefault:
+		return ""
+	case DurabilitySync:
+		return "sync"
+	case DurabilityGroup:
+		return "group"
+	case DurabilityAsync:
+		return "async"
+	case DurabilityNone:
+		return "none"
+	default:
+		return fmt.Sprintf("Durability(%d)", int(d))
+	}
+}
+
+// ParseDurability parses the name of a durability mode. An empty string
+// returns DurabilityDefault.
+func ParseDurability(s string) (Durability, error) {
+	switch s {
+	case "":
+		return DurabilityDefault, nil
+	case "sync":
+		return DurabilitySync, nil
+	case "group":
+		return DurabilityGroup, nil
+	case "async":
+		return DurabilityAsync, nil
+	case "none":
+		return DurabilityNone, nil
+	default:
+		return DurabilityDefault, fmt.Errorf("invalid WAL durability %q; valid values are sync, group, async and none", s)
+	}
+}
+
+type durabilityKey struct{}
+
+// NewContextWithDurability returns a new context that requests d for any WAL
+// writes made with it, overriding the durability the WAL was configured with.
+func NewContextWithDurability(ctx context.Context, d Durability) context.Context {
+	return context.WithValue(ctx, durabilityKey{}, d)
+}
+
+// DurabilityFromContext returns the durability requested by ctx, or
+// DurabilityDefault if none was requested.
+func DurabilityFromContext(ctx context.Context) Durability {
+	d, _ := ctx.Value(durabilityKey{}).(Durability)
+	return d
+}
@@ -39,6 +39,8 @@ type walMetrics struct {
 	CurrentSegmentBytes *prometheus.GaugeVec
 	Segments            *prometheus.GaugeVec
 	Writes              *prometheus.CounterVec
+	SyncDuration        *prometheus.HistogramVec
+	SyncBatchSize       *prometheus.HistogramVec
 }
 
 // newWALMetrics initialises the prometheus metrics for tracking the WAL.
@@ -77,6 +79,20 @@ func newWALMetrics(labels prometheus.Labels) *walMetrics {
 			Name:      "writes_total",
 			Help:      "Number of writes to the WAL.",
 		}, writeNames),
+		SyncDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: walSubsystem,
+			Name:      "fsync_duration_seconds",
+			Help:      "Time taken to fsync the WAL.",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 10),
+		}, names),
+		SyncBatchSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: walSubsystem,
+			Name:      "fsync_batch_writes",
+			Help:      "Number of writes made durable by each fsync of the WAL.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 11),
+		}, names),
 	}
 }
 
@@ -87,5 +103,7 @@ func (m *walMetrics) PrometheusCollectors() []prometheus.Collector {
 		m.CurrentSegmentBytes,
 		m.Segments,
 		m.Writes,
+		m.SyncDuration,
+		m.SyncBatchSize,
 	}
 }
@@ -2,6 +2,7 @@ package wal
 
 import (
 	"testing"
+	"time"
 
 	"github.com/influxdata/influxdb/v2/kit/prom/promtest"
 	"github.com/prometheus/client_golang/prometheus"
@@ -30,6 +31,11 @@ func TestMetrics_WAL(t *testing.T) {
 		base + "writes_total",
 	}
 
+	histograms := []string{
+		base + "fsync_duration_seconds",
+		base + "fsync_batch_writes",
+	}
+
 	// Generate some measurements.
 	for i, tracker := range []*walTracker{t1, t2} {
 		tracker.SetOldSegmentSize(uint64(i + len(gauges[0])))
@@ -39,6 +45,10 @@ func TestMetrics_WAL(t *testing.T) {
 		labels := tracker.Labels()
 		labels["status"] = "ok"
 		tracker.metrics.Writes.With(labels).Add(float64(i + len(counters[0])))
+
+		for j := 0; j <= i; j++ {
+			tracker.ObserveSync(time.Millisecond, 2)
+		}
 	}
 
 	// Test that all the correct metrics are present.
@@ -70,6 +80,15 @@ func TestMetrics_WAL(t *testing.T) {
 			if got := metric.GetCounter().GetValue(); got != exp {
 				t.Errorf("[%s %d] got %v, expected %v", name, i, got, exp)
 			}
+			delete(labels, "status")
+		}
+
+		for _, name := range histograms {
+			exp := uint64(i + 1)
+			metric := promtest.MustFindMetric(t, mfs, name, labels)
+			if got := metric.GetHistogram().GetSampleCount(); got != exp {
+				t.Errorf("[%s %d] got %v, expected %v", name, i, got, exp)
+			}
 		}
 	}
 }
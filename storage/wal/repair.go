@@ -0,0 +1,235 @@
+package wal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// Repairer truncates WAL segments at the last entry that can be fully read
+// and decoded. Any data after the first corrupt entry is discarded.
+type Repairer struct {
+	Stderr io.Writer
+	Stdout io.Writer
+	Dir    string
+
+	// DryRun reports corrupt segments without truncating them.
+	DryRun bool
+}
+
+// RepairedSegment describes a WAL segment that was truncated by a Repairer.
+type RepairedSegment struct {
+	Path string
+
+	// ValidBytes is the size of the segment after it was truncated.
+	ValidBytes int64
+
+	// LostBytes is the number of bytes discarded from the end of the segment.
+	LostBytes int64
+
+	// ValidEntries is the number of entries retained in the segment.
+	ValidEntries int
+
+	// DroppedEntries describes the data of the discarded entries that could
+	// still be decoded.
+	DroppedEntries []DroppedEntry
+
+	// UnreadableEntries is the number of discarded entries that could not be
+	// decoded, including the first corrupt entry.
+	UnreadableEntries int
+
+	Err error
+}
+
+// DroppedEntry describes data discarded from the end of a WAL segment: the
+// values of a series key, or a delete of a bucket, within a time range.
+type DroppedEntry struct {
+	// Key is the series key of the values, and is empty for a delete.
+	Key []byte
+
+	// OrgID and BucketID are the bucket of a delete.
+	OrgID    influxdb.ID
+	BucketID influxdb.ID
+
+	MinTime int64
+	MaxTime int64
+}
+
+// RepairSummary describes the outcome of a Repairer run.
+type RepairSummary struct {
+	FileCount int
+	Segments  []RepairedSegment
+}
+
+// WriteReport writes a tab separated report of every truncated segment to w,
+// followed by the key and time range of every dropped entry.
+func (s *RepairSummary) WriteReport(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 8, 2, 1, ' ', 0)
+	fmt.Fprintln(tw, "file\tvalid_entries\tvalid_bytes\tlost_bytes\tunreadable_entries")
+	for _, seg := range s.Segments {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\n", seg.Path, seg.ValidEntries, seg.ValidBytes, seg.LostBytes, seg.UnreadableEntries)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(tw, "file\tentry\tkey\tmin_time\tmax_time")
+	for _, seg := range s.Segments {
+		for _, e := range seg.DroppedEntries {
+			if e.Key == nil {
+				fmt.Fprintf(tw, "%s\tdelete\t%s/%s\t%d\t%d\n", seg.Path, e.OrgID, e.BucketID, e.MinTime, e.MaxTime)
+			} else {
+				fmt.Fprintf(tw, "%s\twrite\t%q\t%d\t%d\n", seg.Path, e.Key, e.MinTime, e.MaxTime)
+			}
+		}
+	}
+	return tw.Flush()
+}
+
+// Run scans every segment in Dir and truncates any corrupt segment at the end
+// of its last valid entry. An error is returned along with the summary if any
+// segment could not be repaired.
+func (r *Repairer) Run() (*RepairSummary, error) {
+	if r.Stderr == nil {
+		r.Stderr = ioutil.Discard
+	}
+	if r.Stdout == nil {
+		r.Stdout = ioutil.Discard
+	}
+
+	dir, err := os.Stat(r.Dir)
+	if err != nil {
+		return nil, err
+	} else if !dir.IsDir() {
+		return nil, errors.New("invalid data directory")
+	}
+
+	files, err := filepath.Glob(path.Join(r.Dir, "*."+WALFileExtension))
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &RepairSummary{FileCount: len(files)}
+	var failed int
+	for _, fpath := range files {
+		seg, corrupt := r.repairFile(fpath)
+		switch {
+		case seg.Err != nil:
+			failed++
+			fmt.Fprintf(r.Stderr, "error repairing file %s: %v\n", fpath, seg.Err)
+		case !corrupt:
+			fmt.Fprintf(r.Stdout, "%s: clean\n", fpath)
+		default:
+			fmt.Fprintf(r.Stdout, "%s: truncated to %d bytes after %d entries, %d bytes lost\n",
+				fpath, seg.ValidBytes, seg.ValidEntries, seg.LostBytes)
+			summary.Segments = append(summary.Segments, seg)
+		}
+	}
+
+	if failed > 0 {
+		return summary, fmt.Errorf("failed to repair %d of %d WAL files", failed, len(files))
+	}
+	return summary, nil
+}
+
+func (r *Repairer) repairFile(fpath string) (RepairedSegment, bool) {
+	seg := RepairedSegment{Path: fpath}
+
+	f, err := os.OpenFile(fpath, os.O_RDWR, 0600)
+	if err != nil {
+		seg.Err = err
+		return seg, false
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		seg.Err = err
+		return seg, false
+	}
+
+	reader := NewWALSegmentReader(ioutil.NopCloser(f))
+	for reader.Next() {
+		if _, err := reader.Read(); err != nil {
+			break
+		}
+		seg.ValidEntries++
+	}
+
+	seg.ValidBytes = reader.Count()
+	seg.LostBytes = stat.Size() - seg.ValidBytes
+	if seg.LostBytes == 0 {
+		return seg, false
+	}
+	seg.DroppedEntries, seg.UnreadableEntries = droppedEntries(io.NewSectionReader(f, seg.ValidBytes, seg.LostBytes))
+
+	if r.DryRun {
+		return seg, true
+	}
+
+	if err := f.Truncate(seg.ValidBytes); err != nil {
+		seg.Err = err
+		return seg, true
+	}
+	seg.Err = f.Sync()
+	return seg, true
+}
+
+// droppedEntries decodes the entries of r, which holds the bytes discarded
+// from the end of a segment, skipping those that cannot be decoded. The values
+// of each series key are merged into a single time range.
+func droppedEntries(r io.Reader) ([]DroppedEntry, int) {
+	var (
+		writes     = make(map[string]*DroppedEntry)
+		deletes    []DroppedEntry
+		unreadable int
+	)
+	reader := NewWALSegmentReader(ioutil.NopCloser(r))
+	for reader.Next() {
+		entry, err := reader.Read()
+		if err != nil {
+			unreadable++
+			continue
+		}
+
+		switch e := entry.(type) {
+		case *WriteWALEntry:
+			for k, values := range e.Values {
+				if len(values) == 0 {
+					continue
+				}
+				d, ok := writes[k]
+				if !ok {
+					d = &DroppedEntry{Key: []byte(k), MinTime: values[0].UnixNano(), MaxTime: values[0].UnixNano()}
+					writes[k] = d
+				}
+				for _, v := range values {
+					if t := v.UnixNano(); t < d.MinTime {
+						d.MinTime = t
+					} else if t > d.MaxTime {
+						d.MaxTime = t
+					}
+				}
+			}
+		case *DeleteBucketRangeWALEntry:
+			deletes = append(deletes, DroppedEntry{OrgID: e.OrgID, BucketID: e.BucketID, MinTime: e.Min, MaxTime: e.Max})
+		}
+	}
+
+	dropped := make([]DroppedEntry, 0, len(writes)+len(deletes))
+	for _, d := range writes {
+		dropped = append(dropped, *d)
+	}
+	sort.Slice(dropped, func(i, j int) bool { return bytes.Compare(dropped[i].Key, dropped[j].Key) < 0 })
+	return append(dropped, deletes...), unreadable
+}
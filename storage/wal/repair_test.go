@@ -0,0 +1,218 @@
+package wal
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2/tsdb/value"
+)
+
+func TestRepairer_CorruptFile(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	f := mustTempWalFile(t, dir)
+	writeCorruptEntries(f, t, 3)
+
+	repairer := &Repairer{Dir: dir}
+	summary, err := repairer.Run()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got, exp := len(summary.Segments), 1; got != exp {
+		t.Fatalf("unexpected number of repaired segments: got %d, exp %d", got, exp)
+	}
+	seg := summary.Segments[0]
+	if seg.Err != nil {
+		t.Fatalf("unexpected repair error: %v", seg.Err)
+	}
+	if got, exp := seg.ValidEntries, 3; got != exp {
+		t.Fatalf("unexpected valid entries: got %d, exp %d", got, exp)
+	}
+	if got, exp := seg.LostBytes, int64(5); got != exp {
+		t.Fatalf("unexpected lost bytes: got %d, exp %d", got, exp)
+	}
+
+	fi, err := os.Stat(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := fi.Size(), seg.ValidBytes; got != exp {
+		t.Fatalf("unexpected file size after repair: got %d, exp %d", got, exp)
+	}
+
+	verifier := &Verifier{Dir: dir}
+	vs, err := verifier.Run(false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(vs.CorruptFiles) != 0 {
+		t.Fatalf("expected no corrupt files after repair, got %v", vs.CorruptFiles)
+	}
+	if got, exp := vs.EntryCount, 3; got != exp {
+		t.Fatalf("unexpected entries after repair: got %d, exp %d", got, exp)
+	}
+}
+
+func TestRepairer_CleanFile(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	w := NewWAL(dir)
+	if err := w.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		writeRandomEntry(w, t)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	repairer := &Repairer{Dir: dir}
+	summary, err := repairer.Run()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(summary.Segments) != 0 {
+		t.Fatalf("expected no repaired segments, got %v", summary.Segments)
+	}
+}
+
+func TestRepairer_DryRun(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	f := mustTempWalFile(t, dir)
+	writeCorruptEntries(f, t, 1)
+
+	before, err := os.Stat(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repairer := &Repairer{Dir: dir, DryRun: true}
+	summary, err := repairer.Run()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, exp := len(summary.Segments), 1; got != exp {
+		t.Fatalf("unexpected number of corrupt segments: got %d, exp %d", got, exp)
+	}
+
+	after, err := os.Stat(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before.Size() != after.Size() {
+		t.Fatalf("dry run modified file: size %d -> %d", before.Size(), after.Size())
+	}
+}
+
+func TestRepairer_DroppedEntries(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	f := mustTempWalFile(t, dir)
+	w := NewWALSegmentWriter(f)
+	for _, entry := range []WALEntry{
+		&WriteWALEntry{Values: map[string][]value.Value{"cpu,host=A#!~#value": {value.NewValue(1, 1.1)}}},
+		&WriteWALEntry{Values: map[string][]value.Value{"cpu,host=A#!~#value": {value.NewValue(2, 1.2)}}},
+	} {
+		if err := w.Write(mustMarshalEntry(entry)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// An entry that can be framed but not decoded is followed by entries that
+	// are dropped with it.
+	if err := w.Write(WriteWALEntryType, []byte{0xff, 0xff, 0xff}); err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range []WALEntry{
+		&WriteWALEntry{Values: map[string][]value.Value{
+			"mem,host=B#!~#used": {value.NewValue(5, 1.0), value.NewValue(3, 2.0)},
+		}},
+		&WriteWALEntry{Values: map[string][]value.Value{
+			"mem,host=B#!~#used":  {value.NewValue(7, 3.0)},
+			"cpu,host=B#!~#value": {value.NewValue(4, 4.0)},
+		}},
+		&DeleteBucketRangeWALEntry{OrgID: 1, BucketID: 2, Min: 10, Max: 20},
+	} {
+		if err := w.Write(mustMarshalEntry(entry)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	repairer := &Repairer{Dir: dir}
+	summary, err := repairer.Run()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, exp := len(summary.Segments), 1; got != exp {
+		t.Fatalf("unexpected number of repaired segments: got %d, exp %d", got, exp)
+	}
+
+	seg := summary.Segments[0]
+	if got, exp := seg.ValidEntries, 2; got != exp {
+		t.Fatalf("unexpected valid entries: got %d, exp %d", got, exp)
+	}
+	if got, exp := seg.UnreadableEntries, 1; got != exp {
+		t.Fatalf("unexpected unreadable entries: got %d, exp %d", got, exp)
+	}
+	exp := []DroppedEntry{
+		{Key: []byte("cpu,host=B#!~#value"), MinTime: 4, MaxTime: 4},
+		{Key: []byte("mem,host=B#!~#used"), MinTime: 3, MaxTime: 7},
+		{OrgID: 1, BucketID: 2, MinTime: 10, MaxTime: 20},
+	}
+	if !reflect.DeepEqual(seg.DroppedEntries, exp) {
+		t.Fatalf("unexpected dropped entries:\ngot %+v\nexp %+v", seg.DroppedEntries, exp)
+	}
+
+	var report bytes.Buffer
+	if err := summary.WriteReport(&report); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{`"mem,host=B#!~#used"`, "0000000000000001/0000000000000002"} {
+		if !strings.Contains(report.String(), s) {
+			t.Fatalf("report does not contain %s:\n%s", s, report.String())
+		}
+	}
+}
+
+func TestRepairer_FileError(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	// A segment that cannot be opened for writing.
+	if err := os.Mkdir(filepath.Join(dir, "_00001."+WALFileExtension), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	repairer := &Repairer{Dir: dir, Stdout: &stdout, Stderr: &stderr}
+	summary, err := repairer.Run()
+	if err == nil {
+		t.Fatal("expected an error for a segment that could not be repaired")
+	}
+	if len(summary.Segments) != 0 {
+		t.Fatalf("expected no repaired segments, got %v", summary.Segments)
+	}
+	if strings.Contains(stdout.String(), "clean") {
+		t.Fatalf("segment that could not be repaired reported as clean: %s", stdout.String())
+	}
+	if stderr.Len() == 0 {
+		t.Fatal("expected the error to be written to stderr")
+	}
+}
@@ -0,0 +1,156 @@
+package wal
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+// maxRemovedSegments is the number of removed segments whose final size is
+// remembered to resume tailing positions at their end.
+const maxRemovedSegments = 1024
+
+// ErrPositionUnavailable is returned when tailing the WAL from a position
+// whose segment was removed before all of its entries were read.
+var ErrPositionUnavailable = errors.New("WAL position is no longer available")
+
+// Position is a location in the WAL: a byte offset within a segment. The zero
+// Position is the start of the oldest segment.
+type Position struct {
+	Segment int   `json:"segment"`
+	Offset  int64 `json:"offset"`
+}
+
+// Tail calls fn with each entry written to the WAL after pos, along with the
+// position following the entry, and returns the position following the last
+// entry read. Only entries flushed to the segment files are read; calling Tail
+// again with the returned position continues with later writes.
+//
+// ErrPositionUnavailable is returned if the segment of pos was removed before
+// it was read entirely, and ErrWALCorrupt along with the position of the
+// corrupt entry if an entry cannot be read.
+func (l *WAL) Tail(pos Position, fn func(WALEntry, Position) error) (Position, error) {
+	snap, err := l.TailSnapshot(pos)
+	if err != nil {
+		return pos, err
+	}
+	return snap.Tail(fn)
+}
+
+// TailSnapshot is the list of segments to read to tail the WAL from a
+// position, as it was when the snapshot was taken.
+type TailSnapshot struct {
+	pos      Position
+	segments []string
+	ids      []int
+}
+
+// TailSnapshot returns the segments to read to tail the WAL from pos. Reading
+// them with the Tail method of the snapshot does not lock the WAL, so that a
+// slow reader does not hold up writes.
+//
+// ErrPositionUnavailable is returned if the segment of pos was removed before
+// it was read entirely.
+func (l *WAL) TailSnapshot(pos Position) (*TailSnapshot, error) {
+	snap := &TailSnapshot{pos: pos}
+	if !l.enabled {
+		return snap, nil
+	}
+
+	l.mu.RLock()
+	segments, err := SegmentFileNames(l.path)
+	removedSize, removed := l.removedSegments[pos.Segment]
+	l.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, len(segments))
+	for i, seg := range segments {
+		if ids[i], err = idFromFileName(seg); err != nil {
+			return nil, err
+		}
+	}
+
+	// Skip the segments that were already read.
+	i := 0
+	for i < len(ids) && ids[i] < pos.Segment {
+		i++
+	}
+	if pos.Segment > 0 && (i == len(ids) || ids[i] != pos.Segment) {
+		// The segment was removed. Continue with the next one only if it was read
+		// entirely and no other segments were removed since.
+		if !removed || removedSize != pos.Offset || (i < len(ids) && ids[i] != pos.Segment+1) {
+			return nil, ErrPositionUnavailable
+		}
+		if i == len(ids) {
+			return snap, nil
+		}
+		snap.pos = Position{Segment: ids[i]}
+	}
+
+	snap.segments, snap.ids = segments[i:], ids[i:]
+	return snap, nil
+}
+
+// Tail calls fn with each entry of the segments of the snapshot following its
+// position, as WAL.Tail does.
+func (s *TailSnapshot) Tail(fn func(WALEntry, Position) error) (Position, error) {
+	pos := s.pos
+	for i, id := range s.ids {
+		if id != pos.Segment {
+			pos = Position{Segment: id}
+		}
+
+		var err error
+		last := i == len(s.ids)-1
+		if pos, err = tailSegment(s.segments[i], pos, last, fn); err == errSegmentRemoved {
+			// The segment was removed since the snapshot was taken. Tailing
+			// again from pos finds out whether it was read entirely.
+			return pos, nil
+		} else if err != nil {
+			// A corrupt entry is not skipped, even in a closed segment, since
+			// a reader resuming after it would silently miss its writes.
+			return pos, err
+		}
+	}
+	return pos, nil
+}
+
+// errSegmentRemoved is returned when tailing a segment that was removed since
+// the tail snapshot was taken.
+var errSegmentRemoved = errors.New("WAL segment was removed")
+
+// tailSegment calls fn with each entry of the segment file after pos. A
+// truncated entry ends the last segment, since it may still be being written;
+// in any other segment it is corrupt.
+func tailSegment(path string, pos Position, last bool, fn func(WALEntry, Position) error) (Position, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return pos, errSegmentRemoved
+	} else if err != nil {
+		return pos, err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(pos.Offset, io.SeekStart); err != nil {
+		return pos, err
+	}
+
+	start := pos.Offset
+	r := NewWALSegmentReader(f)
+	for r.Next() {
+		entry, err := r.Read()
+		if err == io.ErrUnexpectedEOF && last {
+			break
+		} else if err != nil {
+			return pos, ErrWALCorrupt
+		}
+
+		pos.Offset = start + r.Count()
+		if err := fn(entry, pos); err != nil {
+			return pos, err
+		}
+	}
+	return pos, nil
+}
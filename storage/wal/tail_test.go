@@ -0,0 +1,143 @@
+package wal
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2/tsdb/value"
+)
+
+func TestWAL_Tail(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	w := NewWAL(dir)
+	if err := w.Open(context.Background()); err != nil {
+		t.Fatalf("error opening WAL: %v", err)
+	}
+	defer w.Close()
+
+	write := func(ts int64) {
+		t.Helper()
+		if _, err := w.WriteMulti(context.Background(), map[string][]value.Value{
+			"cpu,host=A#!~#value": {value.NewValue(ts, float64(ts))},
+		}); err != nil {
+			t.Fatalf("error writing points: %v", err)
+		}
+	}
+
+	tail := func(pos Position) ([]int64, Position, error) {
+		t.Helper()
+		var got []int64
+		pos, err := w.Tail(pos, func(entry WALEntry, _ Position) error {
+			for _, v := range entry.(*WriteWALEntry).Values["cpu,host=A#!~#value"] {
+				got = append(got, v.UnixNano())
+			}
+			return nil
+		})
+		return got, pos, err
+	}
+
+	write(1)
+	write(2)
+
+	got, pos, err := tail(Position{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Fatalf("unexpected entries: got %v", got)
+	}
+
+	// Nothing new was written.
+	if got, _, err := tail(pos); err != nil || len(got) != 0 {
+		t.Fatalf("unexpected entries: got %v, err %v", got, err)
+	}
+
+	// Roll and remove the segment that was read entirely.
+	if err := w.CloseSegment(); err != nil {
+		t.Fatal(err)
+	}
+	closed, err := w.ClosedSegments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Remove(context.Background(), closed); err != nil {
+		t.Fatal(err)
+	}
+	write(3)
+
+	got, next, err := tail(pos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != 3 {
+		t.Fatalf("unexpected entries: got %v", got)
+	}
+	if next.Segment != pos.Segment+1 {
+		t.Fatalf("unexpected position: got %v", next)
+	}
+
+	// The removed segment was not read entirely from its start.
+	if _, _, err := tail(Position{Segment: pos.Segment}); err != ErrPositionUnavailable {
+		t.Fatalf("unexpected error: got %v, exp %v", err, ErrPositionUnavailable)
+	}
+}
+
+func TestWAL_TailCorrupt(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	w := NewWAL(dir)
+	if err := w.Open(context.Background()); err != nil {
+		t.Fatalf("error opening WAL: %v", err)
+	}
+	defer w.Close()
+
+	write := func(ts int64) {
+		t.Helper()
+		if _, err := w.WriteMulti(context.Background(), map[string][]value.Value{
+			"cpu,host=A#!~#value": {value.NewValue(ts, float64(ts))},
+		}); err != nil {
+			t.Fatalf("error writing points: %v", err)
+		}
+	}
+
+	write(1)
+	pos, err := w.Tail(Position{}, func(WALEntry, Position) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	write(2)
+	if err := w.CloseSegment(); err != nil {
+		t.Fatal(err)
+	}
+	write(3)
+
+	// Corrupt the second entry of the closed segment.
+	closed, err := w.ClosedSegments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(closed[0], os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteAt([]byte{0xff, 0xff, 0xff, 0xff}, pos.Offset+1); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	// The corrupt entry is reported rather than skipped for the next segment.
+	got, err := w.Tail(pos, func(WALEntry, Position) error {
+		t.Fatal("unexpected entry after the corrupt one")
+		return nil
+	})
+	if err != ErrWALCorrupt {
+		t.Fatalf("unexpected error: got %v, exp %v", err, ErrWALCorrupt)
+	}
+	if got != pos {
+		t.Fatalf("unexpected position: got %v, exp %v", got, pos)
+	}
+}
@@ -38,9 +38,16 @@ const (
 	// WALFilePrefix is the prefix on all wal segment files.
 	WALFilePrefix = "_"
 
+	// DefaultFlushInterval is how often writes acknowledged with DurabilityAsync
+	// are flushed and fsynced.
+	DefaultFlushInterval = time.Second
+
 	// walEncodeBufSize is the size of the wal entry encoding buffer
 	walEncodeBufSize = 4 * 1024 * 1024
 
+	// maxSyncWaiters is the maximum number of writes waiting for an fsync.
+	maxSyncWaiters = 1024
+
 	float64EntryType  = 1
 	integerEntryType  = 2
 	booleanEntryType  = 3
@@ -103,6 +110,26 @@ type WAL struct {
 	// is opened if a non-default value is required.
 	syncDelay time.Duration
 
+	// durability is used for writes that do not request a durability.
+	durability Durability
+
+	// replicated is set when replicas follow the WAL, so that no write
+	// skips it.
+	replicated bool
+
+	// maxBatch is the number of writes waiting for an fsync that cause the
+	// fsync to happen without waiting for syncDelay.
+	maxBatch int
+
+	// flushInterval is how often writes made with DurabilityAsync are fsync'd.
+	flushInterval time.Duration
+
+	// unsynced is the number of writes since the last fsync.
+	unsynced int
+
+	// removedSegments holds the final size of recently removed segments, by ID.
+	removedSegments map[int]int64
+
 	// WALOutput is the writer used by the logger.
 	logger *zap.Logger // Logger to be used for important messages
 
@@ -123,19 +150,56 @@ func NewWAL(path string) *WAL {
 		enabled: true,
 
 		// these options should be overridden by any options in the config
-		SegmentSize: DefaultSegmentSize,
-		closing:     make(chan struct{}),
-		syncWaiters: make(chan chan error, 1024),
-		limiter:     limiter.NewFixed(defaultWaitingWALWrites),
-		logger:      logger,
+		SegmentSize:   DefaultSegmentSize,
+		closing:       make(chan struct{}),
+		syncWaiters:   make(chan chan error, maxSyncWaiters),
+		maxBatch:      maxSyncWaiters,
+		flushInterval: DefaultFlushInterval,
+		limiter:       limiter.NewFixed(defaultWaitingWALWrites),
+		logger:        logger,
 	}
 }
 
 // WithFsyncDelay sets the fsync delay and should be called before the WAL is opened.
+// It is the longest a write made with DurabilityGroup waits for its fsync.
 func (l *WAL) WithFsyncDelay(delay time.Duration) {
 	l.syncDelay = delay
 }
 
+// WithDurability sets the durability used for writes that do not request one
+// and should be called before the WAL is opened. DurabilityDefault, the
+// default, behaves as DurabilityGroup.
+func (l *WAL) WithDurability(d Durability) {
+	l.durability = d
+}
+
+// WithReplication writes the writes that request DurabilityNone with
+// DurabilityAsync instead, since replicas only receive the writes in the
+// WAL. It should be called before the WAL is opened.
+func (l *WAL) WithReplication() {
+	l.replicated = true
+}
+
+// WithGroupCommitMaxBatch sets the number of writes made with DurabilityGroup
+// that are fsync'd without waiting for the fsync delay. A value of 0 or more
+// than the maximum number of waiting writes uses that maximum. It should be
+// called before the WAL is opened.
+func (l *WAL) WithGroupCommitMaxBatch(n int) {
+	if n <= 0 || n > maxSyncWaiters {
+		n = maxSyncWaiters
+	}
+	l.maxBatch = n
+}
+
+// WithFlushInterval sets how often writes made with DurabilityAsync are flushed
+// and fsync'd. It should be called before the WAL is opened.
+func (l *WAL) WithFlushInterval(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultFlushInterval
+	}
+	l.flushInterval = interval
+}
+
 // SetEnabled sets if the WAL is enabled and should be called before the WAL is opened.
 func (l *WAL) SetEnabled(enabled bool) {
 	l.enabled = enabled
@@ -246,10 +310,47 @@ func (l *WAL) Open(ctx context.Context) error {
 	l.tracker.SetOldSegmentSize(uint64(totalOldDiskSize))
 
 	l.closing = make(chan struct{})
+	go l.flushLoop(l.closing)
 
 	return nil
 }
 
+// flushLoop periodically fsyncs writes made with DurabilityAsync until closing
+// is closed.
+func (l *WAL) flushLoop(closing <-chan struct{}) {
+	t := time.NewTicker(l.flushInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			l.mu.Lock()
+			if l.currentSegmentWriter != nil && l.unsynced > 0 {
+				if err := l.sync(); err != nil {
+					l.logger.Error("Failed to fsync WAL segment", zap.Error(err))
+				}
+			}
+			l.mu.Unlock()
+		case <-closing:
+			return
+		}
+	}
+}
+
+// resolveDurability returns the durability used for a write requesting d.
+func (l *WAL) resolveDurability(d Durability) Durability {
+	if d == DurabilityDefault {
+		d = l.durability
+	}
+	if d == DurabilityDefault {
+		d = DurabilityGroup
+	}
+	if d == DurabilityNone && l.replicated {
+		d = DurabilityAsync
+	}
+	return d
+}
+
 // scheduleSync will schedule an fsync to the current wal segment and notify any
 // waiting gorutines.  If an fsync is already scheduled, subsequent calls will
 // not schedule a new fsync and will be handle by the existing scheduled fsync.
@@ -298,17 +399,29 @@ func (l *WAL) scheduleSync() {
 
 // sync fsyncs the current wal segments and notifies any waiters.  Callers must ensure
 // a write lock on the WAL is obtained before calling sync.
-func (l *WAL) sync() {
+func (l *WAL) sync() error {
+	start := time.Now()
 	err := l.currentSegmentWriter.sync()
+	if l.unsynced > 0 {
+		l.tracker.ObserveSync(time.Since(start), l.unsynced)
+		l.unsynced = 0
+	}
+
 	for len(l.syncWaiters) > 0 {
 		errC := <-l.syncWaiters
 		errC <- err
 	}
+	return err
 }
 
 // WriteMulti writes the given values to the WAL. It returns the WAL segment ID to
 // which the points were written. If an error is returned the segment ID should
 // be ignored. If the WAL is disabled, -1 and nil is returned.
+//
+// WriteMulti returns once the write satisfies the durability requested by ctx,
+// or the durability of the WAL if ctx does not request one. With
+// DurabilityNone the values are not written and -1 and nil is returned,
+// unless the WAL is replicated.
 func (l *WAL) WriteMulti(ctx context.Context, values map[string][]value.Value) (int, error) {
 	span, _ := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
@@ -317,11 +430,17 @@ func (l *WAL) WriteMulti(ctx context.Context, values map[string][]value.Value) (
 		return -1, nil
 	}
 
+	durability := l.resolveDurability(DurabilityFromContext(ctx))
+	span.LogKV("durability", durability.String())
+	if durability == DurabilityNone {
+		return -1, nil
+	}
+
 	entry := &WriteWALEntry{
 		Values: values,
 	}
 
-	id, err := l.writeToLog(entry)
+	id, err := l.writeToLog(entry, durability)
 	if err != nil {
 		l.tracker.IncWritesErr()
 		return -1, err
@@ -382,6 +501,7 @@ func (l *WAL) Remove(ctx context.Context, files []string) error {
 
 	for i, fn := range files {
 		span.LogKV(fmt.Sprintf("path-%d", i), fn)
+		l.recordRemovedSegment(fn)
 		os.RemoveAll(fn)
 	}
 
@@ -405,6 +525,25 @@ func (l *WAL) Remove(ctx context.Context, files []string) error {
 	return nil
 }
 
+// recordRemovedSegment remembers the size of the segment file being removed,
+// so that tailing can continue past it.
+func (l *WAL) recordRemovedSegment(fn string) {
+	id, err := idFromFileName(fn)
+	if err != nil {
+		return
+	}
+	stat, err := os.Stat(fn)
+	if err != nil {
+		return
+	}
+
+	if l.removedSegments == nil {
+		l.removedSegments = make(map[int]int64)
+	}
+	l.removedSegments[id] = stat.Size()
+	delete(l.removedSegments, id-maxRemovedSegments)
+}
+
 // LastWriteTime is the last time anything was written to the WAL.
 func (l *WAL) LastWriteTime() time.Time {
 	l.mu.RLock()
@@ -417,7 +556,7 @@ func (l *WAL) DiskSizeBytes() int64 {
 	return int64(l.tracker.OldSegmentSize() + l.tracker.CurrentSegmentSize())
 }
 
-func (l *WAL) writeToLog(entry WALEntry) (int, error) {
+func (l *WAL) writeToLog(entry WALEntry, durability Durability) (int, error) {
 	// limit how many concurrent encodings can be in flight.  Since we can only
 	// write one at a time to disk, a slow disk can cause the allocations below
 	// to increase quickly.  If we're backed up, wait until others have completed.
@@ -434,7 +573,9 @@ func (l *WAL) writeToLog(entry WALEntry) (int, error) {
 	compressed := snappy.Encode(encBuf, b)
 	bytesPool.Put(bytes)
 
-	syncErr := make(chan error)
+	// The channel is buffered so that a write can be notified of an fsync it
+	// performs itself while holding the lock.
+	syncErr := make(chan error, 1)
 
 	segID, err := func() (int, error) {
 		l.mu.Lock()
@@ -456,13 +597,30 @@ func (l *WAL) writeToLog(entry WALEntry) (int, error) {
 		if err := l.currentSegmentWriter.Write(entry.Type(), compressed); err != nil {
 			return -1, fmt.Errorf("error writing WAL entry: %v", err)
 		}
+		l.unsynced++
 
-		select {
-		case l.syncWaiters <- syncErr:
+		switch durability {
+		case DurabilitySync:
+			if err := l.sync(); err != nil {
+				return -1, fmt.Errorf("error syncing wal: %v", err)
+			}
+			syncErr <- nil
+		case DurabilityAsync:
+			// Flushed by flushLoop.
+			syncErr <- nil
 		default:
-			return -1, fmt.Errorf("error syncing wal")
+			select {
+			case l.syncWaiters <- syncErr:
+			default:
+				return -1, fmt.Errorf("error syncing wal")
+			}
+
+			if len(l.syncWaiters) >= l.maxBatch {
+				l.sync()
+			} else {
+				l.scheduleSync()
+			}
 		}
-		l.scheduleSync()
 
 		// Update stats for current segment size
 		l.tracker.SetCurrentSegmentSize(uint64(l.currentSegmentWriter.size))
@@ -532,7 +690,7 @@ func (l *WAL) DeleteBucketRange(orgID, bucketID influxdb.ID, min, max int64, pre
 		Predicate: pred,
 	}
 
-	id, err := l.writeToLog(entry)
+	id, err := l.writeToLog(entry, l.resolveDurability(DurabilityDefault))
 	if err != nil {
 		return -1, err
 	}
@@ -665,6 +823,14 @@ func (t *walTracker) SetCurrentSegmentSize(sz uint64) {
 // CurrentSegmentSize returns the on-disk size of all old segments.
 func (t *walTracker) CurrentSegmentSize() uint64 { return atomic.LoadUint64(&t.oldSegmentBytes) }
 
+// ObserveSync records the duration of an fsync and the number of writes it
+// made durable.
+func (t *walTracker) ObserveSync(d time.Duration, writes int) {
+	labels := t.labels
+	t.metrics.SyncDuration.With(labels).Observe(d.Seconds())
+	t.metrics.SyncBatchSize.With(labels).Observe(float64(writes))
+}
+
 // SetSegments sets the number of segments files on disk.
 func (t *walTracker) SetSegments(sz uint64) {
 	labels := t.labels
@@ -7,7 +7,9 @@ import (
 	"math/rand"
 	"os"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/golang/snappy"
 
@@ -308,6 +310,151 @@ func TestWAL_ClosedSegments(t *testing.T) {
 	}
 }
 
+func TestWAL_WriteMulti_Durability(t *testing.T) {
+	for _, durability := range []Durability{DurabilitySync, DurabilityGroup, DurabilityAsync, DurabilityNone} {
+		t.Run(durability.String(), func(t *testing.T) {
+			dir := MustTempDir()
+			defer os.RemoveAll(dir)
+
+			w := NewWAL(dir)
+			w.WithFsyncDelay(10 * time.Millisecond)
+			w.WithGroupCommitMaxBatch(4)
+			if err := w.Open(context.Background()); err != nil {
+				t.Fatalf("error opening WAL: %v", err)
+			}
+
+			ctx := NewContextWithDurability(context.Background(), durability)
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					if _, err := w.WriteMulti(ctx, map[string][]value.Value{
+						"cpu,host=A#!~#value": {value.NewValue(int64(i), float64(i))},
+					}); err != nil {
+						t.Errorf("error writing points: %v", err)
+					}
+				}(i)
+			}
+			wg.Wait()
+
+			if err := w.Close(); err != nil {
+				t.Fatalf("error closing wal: %v", err)
+			}
+
+			files, err := SegmentFileNames(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var n int
+			if err := NewWALReader(files).Read(func(WALEntry) error {
+				n++
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			exp := 10
+			if durability == DurabilityNone {
+				exp = 0
+			}
+			if n != exp {
+				t.Fatalf("unexpected number of entries: got %d, exp %d", n, exp)
+			}
+		})
+	}
+}
+
+func TestWAL_WriteMulti_DurabilityNoneReplicated(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	w := NewWAL(dir)
+	w.WithDurability(DurabilityNone)
+	w.WithReplication()
+	if err := w.Open(context.Background()); err != nil {
+		t.Fatalf("error opening WAL: %v", err)
+	}
+
+	// Replicas only receive the writes in the WAL, so none are skipped.
+	if _, err := w.WriteMulti(context.Background(), map[string][]value.Value{
+		"cpu,host=A#!~#value": {value.NewValue(1, 1.1)},
+	}); err != nil {
+		t.Fatalf("error writing points: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("error closing wal: %v", err)
+	}
+
+	files, err := SegmentFileNames(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	if err := NewWALReader(files).Read(func(WALEntry) error {
+		n++
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("unexpected number of entries: got %d, exp 1", n)
+	}
+}
+
+func TestWAL_WriteMulti_AsyncFlush(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	w := NewWAL(dir)
+	w.WithDurability(DurabilityAsync)
+	w.WithFlushInterval(10 * time.Millisecond)
+	if err := w.Open(context.Background()); err != nil {
+		t.Fatalf("error opening WAL: %v", err)
+	}
+	defer w.Close()
+
+	if _, err := w.WriteMulti(context.Background(), map[string][]value.Value{
+		"cpu,host=A#!~#value": {value.NewValue(1, 1.1)},
+	}); err != nil {
+		t.Fatalf("error writing points: %v", err)
+	}
+
+	// The write is only buffered until the next flush.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		files, err := SegmentFileNames(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) == 1 {
+			if fi, err := os.Stat(files[0]); err == nil && fi.Size() > 0 {
+				return
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for async write to be flushed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestParseDurability(t *testing.T) {
+	for _, d := range []Durability{DurabilityDefault, DurabilitySync, DurabilityGroup, DurabilityAsync, DurabilityNone} {
+		got, err := ParseDurability(d.String())
+		if err != nil {
+			t.Fatalf("unexpected error parsing %q: %v", d, err)
+		}
+		if got != d {
+			t.Fatalf("unexpected durability: got %v, exp %v", got, d)
+		}
+	}
+
+	if _, err := ParseDurability("fast"); err == nil {
+		t.Fatal("expected error parsing invalid durability")
+	}
+}
+
 func TestWALWriter_Corrupt(t *testing.T) {
 	dir := MustTempDir()
 	defer os.RemoveAll(dir)
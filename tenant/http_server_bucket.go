@@ -69,13 +69,14 @@ func (h *BucketHandler) Prefix() string {
 
 // bucket is used for serialization/deserialization with duration string syntax.
 type bucket struct {
-	ID                  influxdb.ID     `json:"id,omitempty"`
-	OrgID               influxdb.ID     `json:"orgID,omitempty"`
-	Type                string          `json:"type"`
-	Description         string          `json:"description,omitempty"`
-	Name                string          `json:"name"`
-	RetentionPolicyName string          `json:"rp,omitempty"` // This to support v1 sources
-	RetentionRules      []retentionRule `json:"retentionRules"`
+	ID                  influxdb.ID               `json:"id,omitempty"`
+	OrgID               influxdb.ID               `json:"orgID,omitempty"`
+	Type                string                    `json:"type"`
+	Description         string                    `json:"description,omitempty"`
+	Name                string                    `json:"name"`
+	RetentionPolicyName string                    `json:"rp,omitempty"` // This to support v1 sources
+	RetentionRules      []retentionRule           `json:"retentionRules"`
+	Durability          influxdb.BucketDurability `json:"durability,omitempty"`
 	influxdb.CRUDLog
 }
 
@@ -123,6 +124,7 @@ func (b *bucket) toInfluxDB() (*influxdb.Bucket, error) {
 		Name:                b.Name,
 		RetentionPolicyName: b.RetentionPolicyName,
 		RetentionPeriod:     d,
+		Durability:          b.Durability,
 		CRUDLog:             b.CRUDLog,
 	}, nil
 }
@@ -149,15 +151,17 @@ func newBucket(pb *influxdb.Bucket) *bucket {
 		Description:         pb.Description,
 		RetentionPolicyName: pb.RetentionPolicyName,
 		RetentionRules:      rules,
+		Durability:          pb.Durability,
 		CRUDLog:             pb.CRUDLog,
 	}
 }
 
 // bucketUpdate is used for serialization/deserialization with retention rules.
 type bucketUpdate struct {
-	Name           *string         `json:"name,omitempty"`
-	Description    *string         `json:"description,omitempty"`
-	RetentionRules []retentionRule `json:"retentionRules,omitempty"`
+	Name           *string                    `json:"name,omitempty"`
+	Description    *string                    `json:"description,omitempty"`
+	RetentionRules []retentionRule            `json:"retentionRules,omitempty"`
+	Durability     *influxdb.BucketDurability `json:"durability,omitempty"`
 }
 
 func (b *bucketUpdate) OK() error {
@@ -167,6 +171,11 @@ func (b *bucketUpdate) OK() error {
 			return err
 		}
 	}
+	if b.Durability != nil {
+		if err := b.Durability.Valid(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -185,6 +194,7 @@ func (b *bucketUpdate) toInfluxDB() *influxdb.BucketUpdate {
 		Name:            b.Name,
 		Description:     b.Description,
 		RetentionPeriod: &d,
+		Durability:      b.Durability,
 	}
 }
 
@@ -197,6 +207,7 @@ func newBucketUpdate(pb *influxdb.BucketUpdate) *bucketUpdate {
 		Name:           pb.Name,
 		Description:    pb.Description,
 		RetentionRules: []retentionRule{},
+		Durability:     pb.Durability,
 	}
 
 	if pb.RetentionPeriod != nil {
@@ -275,11 +286,12 @@ func (h *BucketHandler) handlePostBucket(w http.ResponseWriter, r *http.Request)
 }
 
 type postBucketRequest struct {
-	OrgID               influxdb.ID     `json:"orgID,omitempty"`
-	Name                string          `json:"name"`
-	Description         string          `json:"description"`
-	RetentionPolicyName string          `json:"rp,omitempty"` // This to support v1 sources
-	RetentionRules      []retentionRule `json:"retentionRules"`
+	OrgID               influxdb.ID               `json:"orgID,omitempty"`
+	Name                string                    `json:"name"`
+	Description         string                    `json:"description"`
+	RetentionPolicyName string                    `json:"rp,omitempty"` // This to support v1 sources
+	RetentionRules      []retentionRule           `json:"retentionRules"`
+	Durability          influxdb.BucketDurability `json:"durability,omitempty"`
 }
 
 func (b *postBucketRequest) OK() error {
@@ -300,6 +312,10 @@ func (b *postBucketRequest) OK() error {
 		}
 	}
 
+	if err := b.Durability.Valid(); err != nil {
+		return err
+	}
+
 	// names starting with an underscore are reserved for system buckets
 	if err := validBucketName(b.toInfluxDB()); err != nil {
 		return &influxdb.Error{
@@ -325,6 +341,7 @@ func (b postBucketRequest) toInfluxDB() *influxdb.Bucket {
 		Type:                influxdb.BucketTypeUser,
 		RetentionPolicyName: b.RetentionPolicyName,
 		RetentionPeriod:     dur,
+		Durability:          b.Durability,
 	}
 }
 
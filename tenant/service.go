@@ -3,11 +3,27 @@ package tenant
 import "github.com/influxdata/influxdb/v2"
 
 type Service struct {
-	store *Store
+	store          *Store
+	passwordPolicy influxdb.PasswordPolicy
 }
 
-func NewService(st *Store) influxdb.TenantService {
-	return &Service{
-		store: st,
+// ServiceOption configures the Service.
+type ServiceOption func(*Service)
+
+// WithPasswordPolicy sets the policy the passwords of users must follow.
+func WithPasswordPolicy(p influxdb.PasswordPolicy) ServiceOption {
+	return func(s *Service) {
+		s.passwordPolicy = p
+	}
+}
+
+func NewService(st *Store, opts ...ServiceOption) influxdb.TenantService {
+	s := &Service{
+		store:          st,
+		passwordPolicy: influxdb.DefaultPasswordPolicy,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
@@ -0,0 +1,240 @@
+package totp
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+)
+
+// DefaultIssuer is the issuer the authenticator apps show the codes under.
+const DefaultIssuer = "InfluxDB"
+
+var totpBucket = []byte("totpv1")
+
+var _ influxdb.TOTPService = (*Service)(nil)
+
+// record is what is stored for a user, under its ID.
+type record struct {
+	// Secret is the enabled secret of the user, and Pending the secret it
+	// enrolled but has yet to confirm.
+	Secret  string `json:"secret,omitempty"`
+	Pending string `json:"pending,omitempty"`
+	// LastStep is the time step of the last code that was accepted, so that
+	// a code can only be used once.
+	LastStep uint64 `json:"lastStep,omitempty"`
+}
+
+// Service manages the TOTP secrets of users in a bucket of a kv.Store.
+type Service struct {
+	kvStore kv.Store
+	users   influxdb.UserService
+	issuer  string
+
+	// now is the clock the codes are generated from.
+	now func() time.Time
+}
+
+// NewService returns a Service that stores the secrets of users in store.
+func NewService(store kv.Store, users influxdb.UserService, issuer string) (*Service, error) {
+	if issuer == "" {
+		issuer = DefaultIssuer
+	}
+	s := &Service{
+		kvStore: store,
+		users:   users,
+		issuer:  issuer,
+		now:     time.Now,
+	}
+	return s, s.setup()
+}
+
+func (s *Service) setup() error {
+	return s.kvStore.Update(context.Background(), func(tx kv.Tx) error {
+		_, err := tx.Bucket(totpBucket)
+		return err
+	})
+}
+
+// EnrollTOTP creates a new secret for the user, which replaces its enabled
+// secret once confirmed.
+func (s *Service) EnrollTOTP(ctx context.Context, userID influxdb.ID) (*influxdb.TOTPEnrollment, error) {
+	u, err := s.users.FindUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := NewSecret()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Op:   influxdb.OpEnrollTOTP,
+			Err:  err,
+		}
+	}
+	err = s.kvStore.Update(ctx, func(tx kv.Tx) error {
+		r, err := s.get(tx, userID)
+		if err != nil {
+			return err
+		}
+		r.Pending = secret
+		return s.put(tx, userID, r)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &influxdb.TOTPEnrollment{
+		Secret: secret,
+		URL:    URL(s.issuer, u.Name, secret),
+	}, nil
+}
+
+// ConfirmTOTP enables the enrolled secret of the user if code is one of its
+// codes.
+func (s *Service) ConfirmTOTP(ctx context.Context, userID influxdb.ID, code string) error {
+	return s.kvStore.Update(ctx, func(tx kv.Tx) error {
+		r, err := s.get(tx, userID)
+		if err != nil {
+			return err
+		}
+		if r.Pending == "" {
+			return &influxdb.Error{
+				Code: influxdb.ENotFound,
+				Op:   influxdb.OpConfirmTOTP,
+				Msg:  "TOTP enrollment not found",
+			}
+		}
+		step, ok := validate(r.Pending, code, s.now())
+		if !ok {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Op:   influxdb.OpConfirmTOTP,
+				Msg:  "invalid TOTP code",
+			}
+		}
+
+		r.Secret, r.Pending, r.LastStep = r.Pending, "", step
+		return s.put(tx, userID, r)
+	})
+}
+
+// TOTPEnabled returns true if the user confirmed a secret.
+func (s *Service) TOTPEnabled(ctx context.Context, userID influxdb.ID) (bool, error) {
+	var enabled bool
+	err := s.kvStore.View(ctx, func(tx kv.Tx) error {
+		r, err := s.get(tx, userID)
+		if err != nil {
+			return err
+		}
+		enabled = r.Secret != ""
+		return nil
+	})
+	return enabled, err
+}
+
+// VerifyTOTP returns an error if code is not one of the codes of the enabled
+// secret of the user, or if the code, or a later one, was already used.
+func (s *Service) VerifyTOTP(ctx context.Context, userID influxdb.ID, code string) error {
+	return s.kvStore.Update(ctx, func(tx kv.Tx) error {
+		r, err := s.get(tx, userID)
+		if err != nil {
+			return err
+		}
+		if r.Secret == "" {
+			return errTOTPRequired
+		}
+		step, ok := validate(r.Secret, code, s.now())
+		if !ok || step <= r.LastStep {
+			return errTOTPRequired
+		}
+
+		r.LastStep = step
+		return s.put(tx, userID, r)
+	})
+}
+
+// ResetTOTP deletes the secrets of the user.
+func (s *Service) ResetTOTP(ctx context.Context, userID influxdb.ID) error {
+	if _, err := s.users.FindUserByID(ctx, userID); err != nil {
+		return err
+	}
+	return s.kvStore.Update(ctx, func(tx kv.Tx) error {
+		key, err := encodeUserID(userID)
+		if err != nil {
+			return err
+		}
+		b, err := tx.Bucket(totpBucket)
+		if err != nil {
+			return err
+		}
+		return b.Delete(key)
+	})
+}
+
+var errTOTPRequired = &influxdb.Error{
+	Code: influxdb.EUnauthorized,
+	Op:   influxdb.OpVerifyTOTP,
+	Msg:  influxdb.ErrTOTPRequired.Msg,
+}
+
+// get returns the record of the user, or an empty record if it has none.
+func (s *Service) get(tx kv.Tx, userID influxdb.ID) (*record, error) {
+	key, err := encodeUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	b, err := tx.Bucket(totpBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(key)
+	if kv.IsNotFound(err) {
+		return &record{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r := &record{}
+	if err := json.Unmarshal(v, r); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return r, nil
+}
+
+func (s *Service) put(tx kv.Tx, userID influxdb.ID, r *record) error {
+	key, err := encodeUserID(userID)
+	if err != nil {
+		return err
+	}
+	v, err := json.Marshal(r)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	b, err := tx.Bucket(totpBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(key, v)
+}
+
+func encodeUserID(userID influxdb.ID) ([]byte, error) {
+	key, err := userID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	return key, nil
+}
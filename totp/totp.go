@@ -0,0 +1,117 @@
+// Package totp implements the time-based one-time passwords (TOTP) of
+// RFC 6238 that users sign in with as a second factor.
+//
+// The codes are the 6 digits of HMAC-SHA1 over 30 second steps, which is
+// what authenticator apps generate by default.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// Step is the time step of the codes.
+	Step = 30 * time.Second
+	// Digits is the number of digits of the codes.
+	Digits = 6
+	// Skew is the number of steps before and after the current one whose
+	// codes are also valid, for the clocks of devices that drift.
+	Skew = 1
+
+	secretSize = 20
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewSecret returns a new random secret, encoded in base32.
+func NewSecret() (string, error) {
+	b := make([]byte, secretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(b), nil
+}
+
+// Code returns the code of the base32 secret at t.
+func Code(secret string, t time.Time) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+	return hotp(key, counter(t)), nil
+}
+
+// Validate returns true if code is the code of the base32 secret at t, or
+// at the steps within Skew of t.
+func Validate(secret, code string, t time.Time) bool {
+	_, ok := validate(secret, code, t)
+	return ok
+}
+
+// validate returns the step at which code is the code of the base32 secret,
+// within Skew of t, and whether there is one.
+func validate(secret, code string, t time.Time) (uint64, bool) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != Digits {
+		return 0, false
+	}
+
+	c := counter(t)
+	var step uint64
+	valid := false
+	for i := c - Skew; i <= c+Skew; i++ {
+		// Compare all the steps so that the time it takes does not depend on
+		// which one matches.
+		if subtle.ConstantTimeCompare([]byte(hotp(key, i)), []byte(code)) == 1 {
+			step, valid = i, true
+		}
+	}
+	return step, valid
+}
+
+// URL returns the otpauth URL of the secret that authenticator apps read,
+// usually from a QR code.
+func URL(issuer, account, secret string) string {
+	v := url.Values{}
+	v.Set("secret", secret)
+	v.Set("issuer", issuer)
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + account,
+		RawQuery: v.Encode(),
+	}
+	return u.String()
+}
+
+func counter(t time.Time) uint64 {
+	return uint64(t.Unix() / int64(Step/time.Second))
+}
+
+// hotp is the HOTP value of RFC 4226 of the counter.
+func hotp(key []byte, c uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], c)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	v := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < Digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", Digits, v%mod)
+}
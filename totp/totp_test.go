@@ -0,0 +1,150 @@
+package totp_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/totp"
+	"go.uber.org/zap/zaptest"
+)
+
+// rfcSecret is the base32 encoding of the secret of the test vectors of
+// RFC 6238, "12345678901234567890".
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	// The last 6 digits of the SHA1 test vectors of RFC 6238.
+	for unix, want := range map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	} {
+		got, err := totp.Code(rfcSecret, time.Unix(unix, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("code at %d: got %s want %s", unix, got, want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	code, err := totp.Code(rfcSecret, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name  string
+		code  string
+		at    time.Time
+		valid bool
+	}{
+		{name: "current step", code: code, at: now, valid: true},
+		{name: "previous step", code: code, at: now.Add(totp.Step), valid: true},
+		{name: "next step", code: code, at: now.Add(-totp.Step), valid: true},
+		{name: "expired", code: code, at: now.Add(3 * totp.Step)},
+		{name: "wrong code", code: "000000", at: now},
+		{name: "empty code", code: "", at: now},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := totp.Validate(rfcSecret, tt.code, tt.at); got != tt.valid {
+				t.Errorf("got %v want %v", got, tt.valid)
+			}
+		})
+	}
+}
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+	store := inmem.NewKVStore()
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing kv service: %v", err)
+	}
+	user := &influxdb.User{Name: "user"}
+	if err := svc.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := totp.NewService(store, svc, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	enabled := func() bool {
+		t.Helper()
+		ok, err := s.TOTPEnabled(ctx, user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ok
+	}
+
+	e, err := s.EnrollTOTP(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(e.URL, "otpauth://totp/InfluxDB:user?") {
+		t.Errorf("unexpected url %s", e.URL)
+	}
+	if enabled() {
+		t.Fatal("expected TOTP not to be enabled before the enrollment is confirmed")
+	}
+
+	err = s.ConfirmTOTP(ctx, user.ID, "000000")
+	if code := influxdb.ErrorCode(err); code != influxdb.EInvalid {
+		t.Fatalf("expected a wrong code to be invalid, got %v", err)
+	}
+	now := time.Now()
+	code, err := totp.Code(e.Secret, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ConfirmTOTP(ctx, user.ID, code); err != nil {
+		t.Fatal(err)
+	}
+	if !enabled() {
+		t.Fatal("expected TOTP to be enabled")
+	}
+
+	// The code that confirmed the enrollment may not be used again, but the
+	// code of the next step may, once.
+	err = s.VerifyTOTP(ctx, user.ID, code)
+	if code := influxdb.ErrorCode(err); code != influxdb.EUnauthorized {
+		t.Fatalf("expected a used code to be unauthorized, got %v", err)
+	}
+	next, err := totp.Code(e.Secret, now.Add(totp.Step))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.VerifyTOTP(ctx, user.ID, next); err != nil {
+		t.Fatal(err)
+	}
+	err = s.VerifyTOTP(ctx, user.ID, next)
+	if code := influxdb.ErrorCode(err); code != influxdb.EUnauthorized {
+		t.Fatalf("expected a replayed code to be unauthorized, got %v", err)
+	}
+	err = s.VerifyTOTP(ctx, user.ID, code)
+	if code := influxdb.ErrorCode(err); code != influxdb.EUnauthorized {
+		t.Fatalf("expected an earlier code to be unauthorized, got %v", err)
+	}
+	err = s.VerifyTOTP(ctx, user.ID, "")
+	if code := influxdb.ErrorCode(err); code != influxdb.EUnauthorized {
+		t.Fatalf("expected a missing code to be unauthorized, got %v", err)
+	}
+
+	if err := s.ResetTOTP(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if enabled() {
+		t.Fatal("expected TOTP to be disabled")
+	}
+}
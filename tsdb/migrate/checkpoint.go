@@ -0,0 +1,109 @@
+package migrate
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/influxdata/influxdb/v2/pkg/fs"
+)
+
+// checkpoint records the progress of a remote migration so that an
+// interrupted migration can be resumed without copying everything again.
+//
+// Progress is tracked per measurement as the end of the last time window
+// that was completely written to 2.x, and per user once its authorization
+// has been created. An empty path disables persistence.
+type checkpoint struct {
+	mu   sync.Mutex
+	path string
+
+	Measurements map[string]int64 `json:"measurements"`
+	Users        map[string]bool  `json:"users"`
+}
+
+// loadCheckpoint reads the checkpoint at path. A missing file results in an
+// empty checkpoint.
+func loadCheckpoint(path string) (*checkpoint, error) {
+	c := &checkpoint{
+		path:         path,
+		Measurements: make(map[string]int64),
+		Users:        make(map[string]bool),
+	}
+	if path == "" {
+		return c, nil
+	}
+
+	buf, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(buf, c); err != nil {
+		return nil, err
+	}
+	if c.Measurements == nil {
+		c.Measurements = make(map[string]int64)
+	}
+	if c.Users == nil {
+		c.Users = make(map[string]bool)
+	}
+	return c, nil
+}
+
+func measurementCheckpointKey(db, rp, m string) string {
+	return db + "/" + rp + "/" + m
+}
+
+// measurement returns the time up to which the measurement was migrated and
+// whether any progress was recorded.
+func (c *checkpoint) measurement(db, rp, m string) (int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t, ok := c.Measurements[measurementCheckpointKey(db, rp, m)]
+	return t, ok
+}
+
+// setMeasurement records that the measurement was migrated up to t and
+// persists the checkpoint.
+func (c *checkpoint) setMeasurement(db, rp, m string, t int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Measurements[measurementCheckpointKey(db, rp, m)] = t
+	return c.save()
+}
+
+func (c *checkpoint) user(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Users[name]
+}
+
+// setUser records that the user was migrated and persists the checkpoint.
+func (c *checkpoint) setUser(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Users[name] = true
+	return c.save()
+}
+
+// save atomically replaces the checkpoint file.
+func (c *checkpoint) save() error {
+	if c.path == "" {
+		return nil
+	}
+
+	buf, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	tmp := c.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf, 0600); err != nil {
+		return err
+	}
+	return fs.RenameFileWithReplacement(tmp, c.path)
+}
@@ -0,0 +1,198 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb/v2"
+	iql "github.com/influxdata/influxdb/v2/query/influxql"
+	"github.com/influxdata/influxql"
+)
+
+// fluxIdentifier matches column names that can be used as Flux identifiers.
+var fluxIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// fluxKeywords cannot be used as Flux identifiers.
+var fluxKeywords = map[string]bool{
+	"and": true, "builtin": true, "else": true, "empty": true, "exists": true,
+	"if": true, "import": true, "in": true, "not": true, "option": true,
+	"or": true, "package": true, "return": true, "test": true, "then": true,
+}
+
+// transpileContinuousQuery converts a 1.x continuous query on db into the
+// Flux script of an equivalent 2.x task.
+//
+// The SELECT statement of the continuous query is transpiled with the
+// InfluxQL transpiler. The task runs every GROUP BY interval, or the
+// RESAMPLE EVERY interval if set, and each run reads the GROUP BY interval,
+// or the RESAMPLE FOR duration if set, before the scheduled time. A GROUP BY
+// time offset becomes the task offset. The results are written to the bucket
+// and measurement of the INTO clause.
+func transpileContinuousQuery(db, defaultRP string, orgID influxdb.ID, cq ContinuousQueryInfo) (string, error) {
+	stmt, err := influxql.ParseStatement(cq.Query)
+	if err != nil {
+		return "", err
+	}
+	cqStmt, ok := stmt.(*influxql.CreateContinuousQueryStatement)
+	if !ok {
+		return "", fmt.Errorf("not a continuous query: %s", cq.Query)
+	}
+
+	sel := cqStmt.Source.Clone()
+	if sel.Target == nil || sel.Target.Measurement == nil {
+		return "", errors.New("continuous query has no INTO clause")
+	}
+	if len(sel.Sources) != 1 {
+		return "", errors.New("unsupported: continuous query must select from a single measurement")
+	}
+	source, ok := sel.Sources[0].(*influxql.Measurement)
+	if !ok || source.Regex != nil {
+		return "", errors.New("unsupported: continuous query must select from a named measurement")
+	}
+
+	interval, err := sel.GroupByInterval()
+	if err != nil {
+		return "", err
+	} else if interval <= 0 {
+		return "", errors.New("continuous query has no GROUP BY time interval")
+	}
+	offset, err := sel.GroupByOffset()
+	if err != nil {
+		return "", err
+	}
+
+	every, window := interval, interval
+	if cqStmt.ResampleEvery > 0 {
+		every = cqStmt.ResampleEvery
+	}
+	if cqStmt.ResampleFor > 0 {
+		window = cqStmt.ResampleFor
+	}
+
+	// Resolve the buckets read from and written to.
+	srcRP := source.RetentionPolicy
+	if srcRP == "" {
+		srcRP = defaultRP
+	}
+	target := sel.Target.Measurement
+	dstDB, dstRP, dstName := target.Database, target.RetentionPolicy, target.Name
+	if dstDB == "" {
+		dstDB = db
+	}
+	if dstRP == "" {
+		dstRP = defaultRP
+	}
+	if dstName == "" || strings.Contains(dstName, ":MEASUREMENT") {
+		dstName = source.Name
+	}
+
+	var fields []string
+	for _, name := range sel.ColumnNames()[1:] {
+		if !fluxIdentifier.MatchString(name) || fluxKeywords[name] {
+			return "", fmt.Errorf("unsupported: column name %q is not a valid Flux identifier", name)
+		}
+		fields = append(fields, fmt.Sprintf("%s: r.%s", name, name))
+	}
+
+	tags := []string{}
+	for _, d := range sel.Dimensions {
+		switch expr := d.Expr.(type) {
+		case *influxql.VarRef:
+			tags = append(tags, fmt.Sprintf("%q", expr.Val))
+		case *influxql.Wildcard, *influxql.RegexLiteral:
+			return "", errors.New("unsupported: continuous query must GROUP BY named tags")
+		}
+	}
+
+	sel.Target = nil
+	pkg, err := iql.NewTranspilerWithConfig(nil, iql.Config{
+		Bucket:          path.Join(db, srcRP),
+		DefaultDatabase: db,
+	}).Transpile(context.Background(), sel.String())
+	if err != nil {
+		return "", err
+	}
+
+	file := pkg.Files[0]
+	if len(file.Body) != 1 {
+		return "", errors.New("unexpected transpiled continuous query")
+	}
+	es, ok := file.Body[0].(*ast.ExpressionStatement)
+	if !ok {
+		return "", errors.New("unexpected transpiled continuous query")
+	}
+	expr := es.Expression
+	if pe, ok := expr.(*ast.PipeExpression); ok && isCall(pe.Call, "yield") {
+		expr = pe.Argument
+	}
+
+	// Replace the absolute time range computed at transpile time with one
+	// relative to the scheduled time of each task run.
+	rangeArgs := []ast.Expression{&ast.ObjectExpression{
+		Properties: []*ast.Property{
+			{Key: &ast.Identifier{Name: "start"}, Value: durationLiteral(offset - window)},
+			{Key: &ast.Identifier{Name: "stop"}, Value: durationLiteral(offset)},
+		},
+	}}
+	ast.Visit(expr, func(n ast.Node) {
+		if call, ok := n.(*ast.CallExpression); ok && isCall(call, "range") {
+			call.Arguments = rangeArgs
+		}
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "option task = {name: %q, every: %s, offset: %s}\n\n",
+		cq.Name, ast.Format(durationLiteral(every)), ast.Format(durationLiteral(offset)))
+	b.WriteString(ast.Format(expr))
+	fmt.Fprintf(&b, "\n\t|> set(key: \"_measurement\", value: %q)", dstName)
+	fmt.Fprintf(&b, "\n\t|> to(bucket: %q, orgID: %q, tagColumns: [%s], fieldFn: (r) => ({%s}))\n",
+		path.Join(dstDB, dstRP), orgID.String(), strings.Join(tags, ", "), strings.Join(fields, ", "))
+
+	script := b.String()
+	if p := parser.ParseSource(script); ast.Check(p) > 0 {
+		return "", fmt.Errorf("invalid transpiled continuous query: %v", ast.GetError(p))
+	}
+	return script, nil
+}
+
+func isCall(call *ast.CallExpression, name string) bool {
+	id, ok := call.Callee.(*ast.Identifier)
+	return ok && id.Name == name
+}
+
+// durationLiteral returns a Flux expression for d.
+func durationLiteral(d time.Duration) ast.Expression {
+	neg := d < 0
+	if neg {
+		d = -d
+	}
+
+	lit := &ast.DurationLiteral{}
+	for _, u := range []struct {
+		unit string
+		d    time.Duration
+	}{
+		{"h", time.Hour}, {"m", time.Minute}, {"s", time.Second},
+		{"ms", time.Millisecond}, {"us", time.Microsecond}, {"ns", time.Nanosecond},
+	} {
+		if n := d / u.d; n > 0 {
+			lit.Values = append(lit.Values, ast.Duration{Magnitude: int64(n), Unit: u.unit})
+			d -= n * u.d
+		}
+	}
+	if len(lit.Values) == 0 {
+		lit.Values = []ast.Duration{{Magnitude: 0, Unit: "s"}}
+	}
+
+	if neg {
+		return &ast.UnaryExpression{Operator: ast.SubtractionOperator, Argument: lit}
+	}
+	return lit
+}
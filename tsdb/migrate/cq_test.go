@@ -0,0 +1,63 @@
+package migrate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+func Test_transpileContinuousQuery(t *testing.T) {
+	cases := []struct {
+		name string
+		cq   string
+		exp  []string
+	}{
+		{
+			name: "basic",
+			cq:   `CREATE CONTINUOUS QUERY "cq_mean" ON "db0" BEGIN SELECT mean("value") INTO "cpu_mean" FROM "cpu" GROUP BY time(1h) END`,
+			exp: []string{
+				`option task = {name: "basic", every: 1h, offset: 0s}`,
+				`from(bucket: "db0/autogen")`,
+				`|> range(start: -1h, stop: 0s)`,
+				`|> set(key: "_measurement", value: "cpu_mean")`,
+				`|> to(bucket: "db0/autogen", orgID: "0000000000000001", tagColumns: [], fieldFn: (r) => ({mean: r.mean}))`,
+			},
+		},
+		{
+			name: "resample and offset",
+			cq:   `CREATE CONTINUOUS QUERY "cq_max" ON "db0" RESAMPLE EVERY 30m FOR 2h BEGIN SELECT max("value") AS peak INTO "db1"."rp1"."cpu_max" FROM "db0"."autogen"."cpu" GROUP BY time(1h, 15m), host END`,
+			exp: []string{
+				`option task = {name: "resample and offset", every: 30m, offset: 15m}`,
+				`|> range(start: -1h45m, stop: 15m)`,
+				`|> set(key: "_measurement", value: "cpu_max")`,
+				`|> to(bucket: "db1/rp1", orgID: "0000000000000001", tagColumns: ["host"], fieldFn: (r) => ({peak: r.peak}))`,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := transpileContinuousQuery("db0", "autogen", influxdb.ID(1), ContinuousQueryInfo{Name: tc.name, Query: tc.cq})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, exp := range tc.exp {
+				if !strings.Contains(got, exp) {
+					t.Errorf("expected script to contain %q, got:\n%s", exp, got)
+				}
+			}
+		})
+	}
+}
+
+func Test_transpileContinuousQuery_Unsupported(t *testing.T) {
+	for _, q := range []string{
+		`CREATE CONTINUOUS QUERY "cq" ON "db0" BEGIN SELECT mean("value") INTO "cpu_mean" FROM "cpu" GROUP BY time(1h), * END`,
+		`CREATE CONTINUOUS QUERY "cq" ON "db0" BEGIN SELECT mean("value") INTO "cpu_mean" FROM /cpu.*/ GROUP BY time(1h) END`,
+	} {
+		if _, err := transpileContinuousQuery("db0", "autogen", influxdb.ID(1), ContinuousQueryInfo{Name: "cq", Query: q}); err == nil {
+			t.Errorf("expected error transpiling %q", q)
+		}
+	}
+}
@@ -15,6 +15,7 @@ type Data struct {
 	Index     uint64 // associated raft index
 	ClusterID uint64
 	Databases []DatabaseInfo
+	Users     []UserInfo
 
 	MaxShardGroupID uint64
 	MaxShardID      uint64
@@ -26,6 +27,11 @@ func (data *Data) unmarshal(pb *internal.Data) {
 	for i, x := range pb.GetDatabases() {
 		data.Databases[i].unmarshal(x)
 	}
+
+	data.Users = make([]UserInfo, len(pb.GetUsers()))
+	for i, x := range pb.GetUsers() {
+		data.Users[i].unmarshal(x)
+	}
 }
 
 // UnmarshalBinary decodes the object from a binary format.
@@ -43,6 +49,7 @@ type DatabaseInfo struct {
 	Name                   string
 	DefaultRetentionPolicy string
 	RetentionPolicies      []RetentionPolicyInfo
+	ContinuousQueries      []ContinuousQueryInfo
 }
 
 // unmarshal deserializes from a protobuf representation.
@@ -56,6 +63,13 @@ func (di *DatabaseInfo) unmarshal(pb *internal.DatabaseInfo) {
 			di.RetentionPolicies[i].unmarshal(x)
 		}
 	}
+
+	if len(pb.GetContinuousQueries()) > 0 {
+		di.ContinuousQueries = make([]ContinuousQueryInfo, len(pb.GetContinuousQueries()))
+		for i, x := range pb.GetContinuousQueries() {
+			di.ContinuousQueries[i].unmarshal(x)
+		}
+	}
 }
 
 // RetentionPolicyInfo represents metadata about a retention policy.
@@ -73,3 +87,35 @@ func (rpi *RetentionPolicyInfo) unmarshal(pb *internal.RetentionPolicyInfo) {
 	rpi.Duration = time.Duration(pb.GetDuration())
 	rpi.ShardGroupDuration = time.Duration(pb.GetShardGroupDuration())
 }
+
+// ContinuousQueryInfo represents metadata about a continuous query.
+type ContinuousQueryInfo struct {
+	Name  string
+	Query string
+}
+
+// unmarshal deserializes from a protobuf representation.
+func (cqi *ContinuousQueryInfo) unmarshal(pb *internal.ContinuousQueryInfo) {
+	cqi.Name = pb.GetName()
+	cqi.Query = pb.GetQuery()
+}
+
+// UserInfo represents metadata about a user in the system.
+type UserInfo struct {
+	Name       string
+	Hash       string
+	Admin      bool
+	Privileges map[string]Privilege1x
+}
+
+// unmarshal deserializes from a protobuf representation.
+func (ui *UserInfo) unmarshal(pb *internal.UserInfo) {
+	ui.Name = pb.GetName()
+	ui.Hash = pb.GetHash()
+	ui.Admin = pb.GetAdmin()
+
+	ui.Privileges = make(map[string]Privilege1x)
+	for _, p := range pb.GetPrivileges() {
+		ui.Privileges[p.GetDatabase()] = Privilege1x(p.GetPrivilege())
+	}
+}
@@ -0,0 +1,159 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// process1xMeta migrates the users and continuous queries stored in the 1.x
+// meta.db, as enabled by the configuration. It must run after the buckets
+// for the migrated databases and retention policies have been created.
+func (m *Migrator) process1xMeta(dbFilter, rpFilter string) error {
+	ctx := context.Background()
+
+	data, err := m.loadMeta()
+	if err != nil {
+		return err
+	}
+
+	var dbs []DatabaseInfo
+	for _, db := range data.Databases {
+		if dbFilter == "" && db.Name == internalDBName1x {
+			continue
+		} else if dbFilter != "" && db.Name != dbFilter {
+			continue
+		}
+		dbs = append(dbs, db)
+	}
+
+	if m.MigrateUsers {
+		buckets, err := m.findBuckets(ctx, dbs, rpFilter)
+		if err != nil {
+			return err
+		}
+		if err := m.migrateUsers(ctx, data.Users, buckets); err != nil {
+			return err
+		}
+	}
+
+	if m.MigrateContinuousQueries {
+		return m.migrateContinuousQueries(ctx, dbs)
+	}
+	return nil
+}
+
+// findBuckets returns the IDs of the buckets each 1.x database was migrated
+// to, keyed by database name.
+func (m *Migrator) findBuckets(ctx context.Context, dbs []DatabaseInfo, rpFilter string) (map[string][]influxdb.ID, error) {
+	buckets := make(map[string][]influxdb.ID)
+	for _, db := range dbs {
+		for _, rp := range db.RetentionPolicies {
+			if rpFilter != "" && rp.Name != rpFilter {
+				continue
+			}
+
+			bucket, err := m.metaSvc.FindBucketByName(ctx, m.DestOrg, filepath.Join(db.Name, rp.Name))
+			if influxdb.ErrorCode(err) == influxdb.ENotFound {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			buckets[db.Name] = append(buckets[db.Name], bucket.ID)
+		}
+	}
+	return buckets, nil
+}
+
+func (m *Migrator) migrateUsers(ctx context.Context, users []UserInfo, buckets map[string][]influxdb.ID) error {
+	svc := UserServices{
+		UserService:                m.metaSvc,
+		UserResourceMappingService: m.metaSvc,
+		AuthorizationService:       m.metaSvc,
+	}
+
+	for _, u := range users {
+		if m.DryRun {
+			fmt.Fprintf(m.Stdout, "Would migrate user %q\n", u.Name)
+			continue
+		}
+
+		auth, err := migrateUser(ctx, svc, m.DestOrg, User1x{Name: u.Name, Admin: u.Admin, Privileges: u.Privileges}, buckets)
+		if err != nil {
+			return fmt.Errorf("error migrating user %q: %v", u.Name, err)
+		}
+		if auth != nil && auth.Token == "" {
+			fmt.Fprintf(m.Stdout, "Migrated user %q, whose token %s was created by an earlier run\n", u.Name, auth.ID)
+		} else if auth != nil {
+			fmt.Fprintf(m.Stdout, "Migrated user %q with token %s\n", u.Name, auth.Token)
+		} else {
+			fmt.Fprintf(m.Stdout, "Migrated user %q without privileges\n", u.Name)
+		}
+	}
+	return nil
+}
+
+// migrateContinuousQueries creates a task for each continuous query of dbs.
+// Continuous queries that cannot be transpiled are reported and skipped.
+func (m *Migrator) migrateContinuousQueries(ctx context.Context, dbs []DatabaseInfo) error {
+	ownerID, err := m.orgOwner(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, db := range dbs {
+		for _, cq := range db.ContinuousQueries {
+			script, err := transpileContinuousQuery(db.Name, db.DefaultRetentionPolicy, m.DestOrg, cq)
+			if err != nil {
+				fmt.Fprintf(m.Stdout, "Skipping continuous query %q on %q: %v\n", cq.Name, db.Name, err)
+				continue
+			}
+
+			name := cq.Name
+			_, n, err := m.metaSvc.FindTasks(ctx, influxdb.TaskFilter{OrganizationID: &m.DestOrg, Name: &name})
+			if err != nil {
+				return err
+			} else if n > 0 {
+				fmt.Fprintf(m.verboseStdout, "Task %q already exists\n", cq.Name)
+				continue
+			}
+
+			if m.DryRun {
+				fmt.Fprintf(m.Stdout, "Would create task %q:\n%s\n", cq.Name, script)
+				continue
+			}
+
+			task, err := m.metaSvc.CreateTask(ctx, influxdb.TaskCreate{
+				Flux:           script,
+				Description:    fmt.Sprintf("Migrated from 1.x continuous query %q on %q", cq.Name, db.Name),
+				OrganizationID: m.DestOrg,
+				OwnerID:        ownerID,
+			})
+			if err != nil {
+				return fmt.Errorf("error creating task for continuous query %q: %v", cq.Name, err)
+			}
+			fmt.Fprintf(m.Stdout, "Migrated continuous query %q to task %s\n", cq.Name, task.ID)
+		}
+	}
+	return nil
+}
+
+// orgOwner returns the ID of an owner of the destination organization. Tasks
+// run with the permissions of their owner.
+func (m *Migrator) orgOwner(ctx context.Context) (influxdb.ID, error) {
+	urms, _, err := m.metaSvc.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+		ResourceID:   m.DestOrg,
+		ResourceType: influxdb.OrgsResourceType,
+		UserType:     influxdb.Owner,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(urms) == 0 {
+		return 0, errors.New("destination organization has no owner to own migrated tasks")
+	}
+	return urms[0].UserID, nil
+}
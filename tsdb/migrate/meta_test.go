@@ -0,0 +1,217 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/tsdb/migrate/internal"
+	"go.uber.org/zap/zaptest"
+)
+
+// writeMeta writes a 1.x meta.db of data to the meta directory of dir.
+func writeMeta(t *testing.T, dir string, data *internal.Data) {
+	t.Helper()
+	buf, err := proto.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "meta"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "meta", metaFile), buf, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// metaFixture is a meta.db of db0, with the autogen and rp1 retention
+// policies, and of users with grants on it.
+func metaFixture() *internal.Data {
+	rp := func(name string) *internal.RetentionPolicyInfo {
+		return &internal.RetentionPolicyInfo{
+			Name:               proto.String(name),
+			Duration:           proto.Int64(0),
+			ShardGroupDuration: proto.Int64(0),
+			ReplicaN:           proto.Uint32(1),
+		}
+	}
+	user := func(name string, admin bool, privileges map[string]Privilege1x) *internal.UserInfo {
+		u := &internal.UserInfo{
+			Name:  proto.String(name),
+			Hash:  proto.String("hash"),
+			Admin: proto.Bool(admin),
+		}
+		for db, p := range privileges {
+			u.Privileges = append(u.Privileges, &internal.UserPrivilege{
+				Database:  proto.String(db),
+				Privilege: proto.Int32(int32(p)),
+			})
+		}
+		return u
+	}
+
+	return &internal.Data{
+		Term:            proto.Uint64(1),
+		Index:           proto.Uint64(1),
+		ClusterID:       proto.Uint64(1),
+		MaxNodeID:       proto.Uint64(0),
+		MaxShardGroupID: proto.Uint64(0),
+		MaxShardID:      proto.Uint64(0),
+		Databases: []*internal.DatabaseInfo{{
+			Name:                   proto.String("db0"),
+			DefaultRetentionPolicy: proto.String("autogen"),
+			RetentionPolicies:      []*internal.RetentionPolicyInfo{rp("autogen"), rp("rp1")},
+		}},
+		Users: []*internal.UserInfo{
+			user("reader", false, map[string]Privilege1x{"db0": ReadPrivilege1x}),
+			user("writer", false, map[string]Privilege1x{"db1": WritePrivilege1x}),
+			user("admin", true, nil),
+		},
+	}
+}
+
+func TestMigrator_process1xMeta_users(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "migrate-meta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeMeta(t, dir, metaFixture())
+
+	svc := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	org := &influxdb.Organization{Name: "o"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	var bucketIDs []influxdb.ID
+	for _, name := range []string{"db0/autogen", "db0/rp1"} {
+		b := &influxdb.Bucket{OrgID: org.ID, Name: name}
+		if err := svc.CreateBucket(ctx, b); err != nil {
+			t.Fatal(err)
+		}
+		bucketIDs = append(bucketIDs, b.ID)
+	}
+
+	var out bytes.Buffer
+	m := &Migrator{
+		Config: Config{
+			SourcePath:   dir,
+			DestOrg:      org.ID,
+			MigrateUsers: true,
+			Stdout:       &out,
+		},
+		metaSvc:       svc,
+		verboseStdout: ioutil.Discard,
+	}
+	if err := m.process1xMeta("", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	findUser := func(name string) *influxdb.User {
+		t.Helper()
+		u, err := svc.FindUser(ctx, influxdb.UserFilter{Name: &name})
+		if err != nil {
+			t.Fatalf("expected user %q to be migrated: %v", name, err)
+		}
+		return u
+	}
+	findAuths := func(u *influxdb.User) []*influxdb.Authorization {
+		t.Helper()
+		as, _, err := svc.FindAuthorizations(ctx, influxdb.AuthorizationFilter{UserID: &u.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return as
+	}
+
+	// The read grant on db0 is a read permission on each of its buckets.
+	reader := findUser("reader")
+	as := findAuths(reader)
+	if len(as) != 1 {
+		t.Fatalf("expected 1 authorization for reader, got %d", len(as))
+	}
+	for _, id := range bucketIDs {
+		p, err := influxdb.NewPermissionAtID(id, influxdb.ReadAction, influxdb.BucketsResourceType, org.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !as[0].Allowed(*p) {
+			t.Errorf("expected reader to be allowed %s", p)
+		}
+	}
+	if len(as[0].Permissions) != len(bucketIDs) {
+		t.Errorf("expected %d permissions for reader, got %v", len(bucketIDs), as[0].Permissions)
+	}
+
+	// db1 was not migrated, so the writer has no privileges left.
+	if as := findAuths(findUser("writer")); len(as) != 0 {
+		t.Errorf("expected no authorization for writer, got %d", len(as))
+	}
+
+	// Admins own the org.
+	admin := findUser("admin")
+	urms, _, err := svc.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+		ResourceID:   org.ID,
+		ResourceType: influxdb.OrgsResourceType,
+		UserID:       admin.ID,
+		UserType:     influxdb.Owner,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(urms) != 1 {
+		t.Errorf("expected admin to own the org, got %v", urms)
+	}
+	if as := findAuths(admin); len(as) != 1 || !as[0].Allowed(influxdb.OwnerPermissions(org.ID)[0]) {
+		t.Errorf("expected an owner authorization for admin, got %v", as)
+	}
+
+	// Migrating again reuses the authorizations.
+	if err := m.process1xMeta("", ""); err != nil {
+		t.Fatal(err)
+	}
+	if as := findAuths(reader); len(as) != 1 {
+		t.Errorf("expected the authorization of reader to be reused, got %d", len(as))
+	}
+}
+
+func TestMigrator_getRetentionPolicy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migrate-meta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeMeta(t, dir, metaFixture())
+
+	m := &Migrator{Config: Config{SourcePath: dir}}
+	rp, err := m.getRetentionPolicy("db0", "rp1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rp.Name != "rp1" || rp.ReplicaN != 1 {
+		t.Errorf("unexpected retention policy %+v", rp)
+	}
+	if _, err := m.getRetentionPolicy("db0", "rp2"); err == nil {
+		t.Error("expected an error for a missing retention policy")
+	}
+
+	// A meta.db that cannot be decoded is an error, not a missing
+	// retention policy.
+	if err := ioutil.WriteFile(filepath.Join(dir, "meta", metaFile), []byte("not a meta.db"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.getRetentionPolicy("db0", "rp1"); err == nil || err.Error() == "unable to find retention policy" {
+		t.Errorf("expected a decoding error, got %v", err)
+	}
+}
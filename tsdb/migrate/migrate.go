@@ -58,6 +58,13 @@ type Config struct {
 	To              int64
 	MigrateHotShard bool
 
+	// MigrateUsers enables the migration of 1.x users and their privileges
+	// to 2.x users and authorizations.
+	MigrateUsers bool
+	// MigrateContinuousQueries enables the migration of 1.x continuous
+	// queries to 2.x tasks.
+	MigrateContinuousQueries bool
+
 	DryRun bool
 
 	// Optional if you want to emit logs
@@ -173,6 +180,12 @@ func (m *Migrator) Process1xShards(dbFilter, rpFilter string) error {
 		fmt.Fprintf(m.Stdout, "Migrated shard %s to bucket %s in %v\n", shard.path, shard.bucketID.String(), time.Since(now))
 	}
 
+	if m.MigrateUsers || m.MigrateContinuousQueries {
+		if err := m.process1xMeta(dbFilter, rpFilter); err != nil {
+			return err
+		}
+	}
+
 	fmt.Fprintln(m.Stdout, "Building TSI index")
 
 	sfilePath := filepath.Join(filepath.Dir(m.DestPath), storage.DefaultSeriesFileDirectoryName)
@@ -299,8 +312,8 @@ func (m *Migrator) createBucket(db, rp string) (influxdb.ID, error) {
 	return bucket.ID, nil
 }
 
-// Load and extract retention policy from meta.db
-func (m *Migrator) getRetentionPolicy(dbFilter, rpFilter string) (*RetentionPolicyInfo, error) {
+// Load the 1.x meta.db
+func (m *Migrator) loadMeta() (*Data, error) {
 	file := filepath.Join(m.SourcePath, "meta/"+metaFile)
 
 	data, err := ioutil.ReadFile(file)
@@ -309,7 +322,19 @@ func (m *Migrator) getRetentionPolicy(dbFilter, rpFilter string) (*RetentionPoli
 	}
 
 	var cacheData = new(Data)
-	cacheData.UnmarshalBinary(data)
+	if err := cacheData.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return cacheData, nil
+}
+
+// Load and extract retention policy from meta.db. A meta.db that cannot be
+// decoded is an error rather than a missing retention policy.
+func (m *Migrator) getRetentionPolicy(dbFilter, rpFilter string) (*RetentionPolicyInfo, error) {
+	cacheData, err := m.loadMeta()
+	if err != nil {
+		return nil, err
+	}
 
 	for _, database := range cacheData.Databases {
 		if database.Name == dbFilter {
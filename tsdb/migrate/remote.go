@@ -0,0 +1,546 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+)
+
+const (
+	// DefaultRemoteWindow is the default time range copied in each pass over
+	// a measurement during a remote migration.
+	DefaultRemoteWindow = 24 * time.Hour
+
+	// DefaultRemoteBatchSize is the default number of points written to 2.x
+	// in a single request during a remote migration.
+	DefaultRemoteBatchSize = 5000
+)
+
+// RemoteConfig configures a RemoteMigrator.
+type RemoteConfig struct {
+	// SourceURL is the address of the 1.x server, e.g. http://influxdb1:8086.
+	SourceURL      string
+	SourceUsername string
+	SourcePassword string
+	ChunkSize      int
+	HTTPClient     *http.Client
+
+	DestOrg       influxdb.ID
+	BucketService influxdb.BucketService
+	WriteService  influxdb.WriteService
+
+	// DBRPMappingService, if set, maps each migrated database and retention
+	// policy to its bucket for Cluster, which defaults to the host of
+	// SourceURL.
+	DBRPMappingService influxdb.DBRPMappingService
+	Cluster            string
+
+	// MigrateUsers enables the migration of 1.x users to 2.x users and
+	// authorizations using UserServices.
+	MigrateUsers bool
+	UserServices UserServices
+
+	// CheckpointPath is the file progress is recorded in. Running a
+	// migration again with the same checkpoint resumes where it stopped.
+	CheckpointPath string
+
+	// Window is the time range of data read from 1.x in a single query.
+	Window time.Duration
+	// BatchSize is the number of points written to 2.x in a single request.
+	BatchSize int
+
+	DryRun bool
+
+	// Optional if you want to emit logs
+	Stdout         io.Writer
+	VerboseLogging bool
+}
+
+// A RemoteMigrator migrates data and users from a running InfluxDB 1.x server
+// to a running InfluxDB 2.x server over their HTTP APIs.
+//
+// Each 1.x database and retention policy is migrated to a bucket named
+// "db/rp", and mapped to it so that InfluxQL queries against that database
+// and retention policy resolve to the bucket.
+type RemoteMigrator struct {
+	RemoteConfig
+	client        *v1Client
+	verboseStdout io.Writer
+}
+
+// NewRemoteMigrator returns a new RemoteMigrator.
+func NewRemoteMigrator(c RemoteConfig) *RemoteMigrator {
+	if c.Stdout == nil {
+		c.Stdout = ioutil.Discard
+	}
+	if c.Window <= 0 {
+		c.Window = DefaultRemoteWindow
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = DefaultRemoteBatchSize
+	}
+	if c.Cluster == "" {
+		if u, err := url.Parse(c.SourceURL); err == nil {
+			c.Cluster = u.Host
+		}
+	}
+
+	verboseStdout := ioutil.Discard
+	if c.VerboseLogging {
+		verboseStdout = c.Stdout
+	}
+
+	return &RemoteMigrator{
+		RemoteConfig:  c,
+		client:        newV1Client(c.SourceURL, c.SourceUsername, c.SourcePassword, c.ChunkSize, c.HTTPClient),
+		verboseStdout: verboseStdout,
+	}
+}
+
+// retentionPolicy1x is a retention policy as returned by SHOW RETENTION POLICIES.
+type retentionPolicy1x struct {
+	name      string
+	duration  time.Duration
+	isDefault bool
+}
+
+// Migrate copies every matching database and retention policy, followed by
+// the 1.x users if enabled.
+//
+// The caller can filter on a database and retention policy. Providing the
+// zero value for the filters will result in all data being migrated, with the
+// exception of the `_internal` database, which is never migrated unless
+// explicitly filtered on.
+func (m *RemoteMigrator) Migrate(ctx context.Context, dbFilter, rpFilter string) error {
+	cp, err := loadCheckpoint(m.CheckpointPath)
+	if err != nil {
+		return fmt.Errorf("unable to load checkpoint: %v", err)
+	}
+
+	dbs, err := m.client.column(ctx, "", "SHOW DATABASES", "name")
+	if err != nil {
+		return err
+	}
+
+	buckets := make(map[string][]influxdb.ID)
+	for _, db := range dbs {
+		if dbFilter == "" && db == internalDBName1x {
+			continue
+		} else if dbFilter != "" && db != dbFilter {
+			continue
+		}
+
+		rps, err := m.retentionPolicies(ctx, db)
+		if err != nil {
+			return err
+		}
+
+		for _, rp := range rps {
+			if rpFilter != "" && rp.name != rpFilter {
+				continue
+			}
+
+			bucketID, err := m.createBucket(ctx, db, rp)
+			if err != nil {
+				return err
+			}
+			buckets[db] = append(buckets[db], bucketID)
+
+			if err := m.createDBRPMapping(ctx, db, rp, bucketID); err != nil {
+				return err
+			}
+
+			now := time.Now()
+			if err := m.migrateRetentionPolicy(ctx, cp, db, rp.name, bucketID); err != nil {
+				return err
+			}
+			fmt.Fprintf(m.Stdout, "Migrated %s/%s to bucket %s in %v\n", db, rp.name, bucketID, time.Since(now))
+		}
+	}
+
+	if !m.MigrateUsers {
+		return nil
+	}
+	return m.migrateUsers(ctx, cp, buckets)
+}
+
+func (m *RemoteMigrator) retentionPolicies(ctx context.Context, db string) ([]retentionPolicy1x, error) {
+	var rps []retentionPolicy1x
+	err := m.client.query(ctx, db, "SHOW RETENTION POLICIES ON "+quoteIdent(db), func(s v1Series) error {
+		nameIdx, durIdx := columnIndex(s.Columns, "name"), columnIndex(s.Columns, "duration")
+		defaultIdx := columnIndex(s.Columns, "default")
+		if nameIdx < 0 || durIdx < 0 {
+			return errors.New("unexpected result for SHOW RETENTION POLICIES")
+		}
+		for _, row := range s.Values {
+			d, err := time.ParseDuration(fmt.Sprint(row[durIdx]))
+			if err != nil {
+				return fmt.Errorf("invalid duration for retention policy %v: %v", row[nameIdx], err)
+			}
+			rp := retentionPolicy1x{name: fmt.Sprint(row[nameIdx]), duration: d}
+			if defaultIdx >= 0 {
+				rp.isDefault, _ = row[defaultIdx].(bool)
+			}
+			rps = append(rps, rp)
+		}
+		return nil
+	})
+	return rps, err
+}
+
+func (m *RemoteMigrator) createBucket(ctx context.Context, db string, rp retentionPolicy1x) (influxdb.ID, error) {
+	name := path.Join(db, rp.name)
+
+	bucket, err := m.BucketService.FindBucketByName(ctx, m.DestOrg, name)
+	if err != nil {
+		if influxdb.ErrorCode(err) != influxdb.ENotFound {
+			return 0, err
+		}
+	} else if bucket != nil {
+		fmt.Fprintf(m.verboseStdout, "Bucket %q already exists with ID %s\n", name, bucket.ID.String())
+		return bucket.ID, nil
+	}
+
+	if m.DryRun {
+		fmt.Fprintf(m.Stdout, "Would create bucket %q\n", name)
+		return 0, nil
+	}
+
+	bucket = &influxdb.Bucket{
+		OrgID:               m.DestOrg,
+		Name:                name,
+		RetentionPolicyName: rp.name,
+		RetentionPeriod:     rp.duration,
+	}
+	if err := m.BucketService.CreateBucket(ctx, bucket); err != nil {
+		return 0, err
+	}
+	fmt.Fprintf(m.verboseStdout, "Created bucket %q with ID %s\n", name, bucket.ID.String())
+	return bucket.ID, nil
+}
+
+// createDBRPMapping maps the database and retention policy to the bucket
+// they are migrated to, unless they are already mapped to it.
+func (m *RemoteMigrator) createDBRPMapping(ctx context.Context, db string, rp retentionPolicy1x, bucketID influxdb.ID) error {
+	if m.DBRPMappingService == nil {
+		return nil
+	}
+
+	mapping, err := m.DBRPMappingService.FindBy(ctx, m.Cluster, db, rp.name)
+	if err != nil {
+		if influxdb.ErrorCode(err) != influxdb.ENotFound {
+			return err
+		}
+	} else if mapping != nil {
+		if !m.DryRun && mapping.BucketID != bucketID {
+			return fmt.Errorf("%s/%s is already mapped to bucket %s", db, rp.name, mapping.BucketID)
+		}
+		fmt.Fprintf(m.verboseStdout, "DBRP mapping of %s/%s already exists\n", db, rp.name)
+		return nil
+	}
+
+	if m.DryRun {
+		fmt.Fprintf(m.Stdout, "Would map %s/%s to its bucket\n", db, rp.name)
+		return nil
+	}
+
+	mapping = &influxdb.DBRPMapping{
+		Cluster:         m.Cluster,
+		Database:        db,
+		RetentionPolicy: rp.name,
+		Default:         rp.isDefault,
+		OrganizationID:  m.DestOrg,
+		BucketID:        bucketID,
+	}
+	if err := m.DBRPMappingService.Create(ctx, mapping); err != nil {
+		return fmt.Errorf("error mapping %s/%s to bucket %s: %v", db, rp.name, bucketID, err)
+	}
+	fmt.Fprintf(m.verboseStdout, "Mapped %s/%s to bucket %s\n", db, rp.name, bucketID)
+	return nil
+}
+
+func (m *RemoteMigrator) migrateRetentionPolicy(ctx context.Context, cp *checkpoint, db, rp string, bucketID influxdb.ID) error {
+	measurements, err := m.client.column(ctx, db, "SHOW MEASUREMENTS", "name")
+	if err != nil {
+		return err
+	}
+
+	for _, name := range measurements {
+		if err := m.migrateMeasurement(ctx, cp, db, rp, name, bucketID); err != nil {
+			return fmt.Errorf("error migrating measurement %q in %s/%s: %v", name, db, rp, err)
+		}
+	}
+	return nil
+}
+
+// migrateMeasurement copies all data of a single measurement one window at a
+// time, recording a checkpoint after each window has been written.
+func (m *RemoteMigrator) migrateMeasurement(ctx context.Context, cp *checkpoint, db, rp, name string, bucketID influxdb.ID) error {
+	source := quoteIdent(rp) + "." + quoteIdent(name)
+
+	first, ok, err := m.boundary(ctx, db, "SELECT * FROM "+source+" LIMIT 1")
+	if err != nil || !ok {
+		return err
+	}
+	last, _, err := m.boundary(ctx, db, "SELECT * FROM "+source+" ORDER BY time DESC LIMIT 1")
+	if err != nil {
+		return err
+	}
+
+	start := first
+	if t, ok := cp.measurement(db, rp, name); ok {
+		if t > last {
+			fmt.Fprintf(m.verboseStdout, "Skipping %s/%s/%s, already migrated\n", db, rp, name)
+			return nil
+		}
+		start = t
+	}
+
+	if m.DryRun {
+		fmt.Fprintf(m.Stdout, "Would migrate %s/%s/%s from %v to %v\n", db, rp, name, time.Unix(0, start).UTC(), time.Unix(0, last).UTC())
+		return nil
+	}
+
+	types, err := m.fieldTypes(ctx, db, rp, name)
+	if err != nil {
+		return err
+	}
+
+	w := &pointBatcher{
+		ctx:       ctx,
+		svc:       m.WriteService,
+		orgID:     m.DestOrg,
+		bucketID:  bucketID,
+		batchSize: m.BatchSize,
+	}
+	for ; start <= last; start += int64(m.Window) {
+		end := start + int64(m.Window)
+		q := fmt.Sprintf("SELECT * FROM %s WHERE time >= %d AND time < %d GROUP BY *", source, start, end)
+		err := m.client.query(ctx, db, q, func(s v1Series) error {
+			return w.writeSeries(name, s, types)
+		})
+		if err != nil {
+			return err
+		}
+		if err := w.flush(); err != nil {
+			return err
+		}
+		if err := cp.setMeasurement(db, rp, name, end); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(m.verboseStdout, "Migrated %d points from %s/%s/%s\n", w.total, db, rp, name)
+	return nil
+}
+
+// boundary returns the time of the single row returned by q.
+func (m *RemoteMigrator) boundary(ctx context.Context, db, q string) (int64, bool, error) {
+	var (
+		t     int64
+		found bool
+	)
+	err := m.client.query(ctx, db, q, func(s v1Series) error {
+		idx := columnIndex(s.Columns, "time")
+		if idx < 0 || len(s.Values) == 0 {
+			return nil
+		}
+		n, ok := s.Values[0][idx].(json.Number)
+		if !ok {
+			return fmt.Errorf("unexpected time value %v", s.Values[0][idx])
+		}
+		v, err := n.Int64()
+		if err != nil {
+			return err
+		}
+		t, found = v, true
+		return nil
+	})
+	return t, found, err
+}
+
+// fieldTypes returns the type of each field of a measurement. The JSON
+// encoding of query results does not distinguish integers from whole floats,
+// so the types must be known to write the points correctly.
+func (m *RemoteMigrator) fieldTypes(ctx context.Context, db, rp, name string) (map[string]string, error) {
+	types := make(map[string]string)
+	err := m.client.query(ctx, db, "SHOW FIELD KEYS FROM "+quoteIdent(rp)+"."+quoteIdent(name), func(s v1Series) error {
+		keyIdx, typeIdx := columnIndex(s.Columns, "fieldKey"), columnIndex(s.Columns, "fieldType")
+		if keyIdx < 0 || typeIdx < 0 {
+			return errors.New("unexpected result for SHOW FIELD KEYS")
+		}
+		for _, row := range s.Values {
+			key := fmt.Sprint(row[keyIdx])
+			if _, ok := types[key]; !ok {
+				types[key] = fmt.Sprint(row[typeIdx])
+			}
+		}
+		return nil
+	})
+	return types, err
+}
+
+func (m *RemoteMigrator) migrateUsers(ctx context.Context, cp *checkpoint, buckets map[string][]influxdb.ID) error {
+	var users []User1x
+	err := m.client.query(ctx, "", "SHOW USERS", func(s v1Series) error {
+		userIdx, adminIdx := columnIndex(s.Columns, "user"), columnIndex(s.Columns, "admin")
+		if userIdx < 0 || adminIdx < 0 {
+			return errors.New("unexpected result for SHOW USERS")
+		}
+		for _, row := range s.Values {
+			admin, _ := row[adminIdx].(bool)
+			users = append(users, User1x{Name: fmt.Sprint(row[userIdx]), Admin: admin})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, u := range users {
+		if cp.user(u.Name) {
+			fmt.Fprintf(m.verboseStdout, "Skipping user %q, already migrated\n", u.Name)
+			continue
+		}
+
+		u.Privileges = make(map[string]Privilege1x)
+		err := m.client.query(ctx, "", "SHOW GRANTS FOR "+quoteIdent(u.Name), func(s v1Series) error {
+			dbIdx, privIdx := columnIndex(s.Columns, "database"), columnIndex(s.Columns, "privilege")
+			if dbIdx < 0 || privIdx < 0 {
+				return errors.New("unexpected result for SHOW GRANTS")
+			}
+			for _, row := range s.Values {
+				u.Privileges[fmt.Sprint(row[dbIdx])] = parsePrivilege1x(fmt.Sprint(row[privIdx]))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if m.DryRun {
+			fmt.Fprintf(m.Stdout, "Would migrate user %q\n", u.Name)
+			continue
+		}
+
+		auth, err := migrateUser(ctx, m.UserServices, m.DestOrg, u, buckets)
+		if err != nil {
+			return fmt.Errorf("error migrating user %q: %v", u.Name, err)
+		}
+		if auth != nil && auth.Token == "" {
+			fmt.Fprintf(m.Stdout, "Migrated user %q, whose token %s was created by an earlier run\n", u.Name, auth.ID)
+		} else if auth != nil {
+			fmt.Fprintf(m.Stdout, "Migrated user %q with token %s\n", u.Name, auth.Token)
+		} else {
+			fmt.Fprintf(m.Stdout, "Migrated user %q without privileges\n", u.Name)
+		}
+		if err := cp.setUser(u.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pointBatcher converts 1.x query results to line protocol and writes them to
+// 2.x in batches.
+type pointBatcher struct {
+	ctx       context.Context
+	svc       influxdb.WriteService
+	orgID     influxdb.ID
+	bucketID  influxdb.ID
+	batchSize int
+
+	buf   bytes.Buffer
+	n     int
+	total int
+}
+
+func (b *pointBatcher) writeSeries(name string, s v1Series, types map[string]string) error {
+	timeIdx := columnIndex(s.Columns, "time")
+	if timeIdx < 0 {
+		return errors.New("time column missing from result")
+	}
+	tags := models.NewTags(s.Tags)
+
+	for _, row := range s.Values {
+		ts, ok := row[timeIdx].(json.Number)
+		if !ok {
+			return fmt.Errorf("unexpected time value %v", row[timeIdx])
+		}
+		t, err := ts.Int64()
+		if err != nil {
+			return err
+		}
+
+		fields := make(models.Fields, len(s.Columns)-1)
+		for i, col := range s.Columns {
+			if i == timeIdx || row[i] == nil {
+				continue
+			}
+			v, err := fieldValue(row[i], types[col])
+			if err != nil {
+				return fmt.Errorf("field %q: %v", col, err)
+			}
+			fields[col] = v
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		pt, err := models.NewPoint(name, tags, fields, time.Unix(0, t))
+		if err != nil {
+			return err
+		}
+		b.buf.WriteString(pt.String())
+		b.buf.WriteByte('\n')
+		if b.n++; b.n >= b.batchSize {
+			if err := b.flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (b *pointBatcher) flush() error {
+	if b.n == 0 {
+		return nil
+	}
+	if err := b.svc.Write(b.ctx, b.orgID, b.bucketID, bytes.NewReader(b.buf.Bytes())); err != nil {
+		return err
+	}
+	b.total += b.n
+	b.n = 0
+	b.buf.Reset()
+	return nil
+}
+
+// fieldValue converts a JSON decoded 1.x field value to the Go type for the
+// 1.x field type.
+func fieldValue(v interface{}, typ string) (interface{}, error) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return v, nil
+	}
+
+	switch typ {
+	case "integer":
+		return n.Int64()
+	case "unsigned":
+		return strconv.ParseUint(n.String(), 10, 64)
+	default:
+		return n.Float64()
+	}
+}
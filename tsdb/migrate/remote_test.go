@@ -0,0 +1,187 @@
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/mock"
+)
+
+// fake1xServer answers 1.x queries with canned series, keyed by query text.
+func fake1xServer(t *testing.T, responses map[string][]v1Series) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		q := r.Form.Get("q")
+		series, ok := responses[q]
+		if !ok && strings.HasPrefix(q, "SELECT * FROM") && strings.Contains(q, "WHERE time") {
+			series = responses["window"]
+			responses["window"] = nil // only return data for the first window
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"results": []v1Result{{Series: series}},
+		})
+	}))
+}
+
+func TestRemoteMigrator_Migrate(t *testing.T) {
+	ts := time.Unix(0, 1000)
+	srv := fake1xServer(t, map[string][]v1Series{
+		"SHOW DATABASES":                       {{Columns: []string{"name"}, Values: [][]interface{}{{"db0"}, {"_internal"}}}},
+		`SHOW RETENTION POLICIES ON "db0"`:     {{Columns: []string{"name", "duration", "default"}, Values: [][]interface{}{{"autogen", "0s", true}}}},
+		"SHOW MEASUREMENTS":                    {{Columns: []string{"name"}, Values: [][]interface{}{{"cpu"}}}},
+		`SHOW FIELD KEYS FROM "autogen"."cpu"`: {{Columns: []string{"fieldKey", "fieldType"}, Values: [][]interface{}{{"n", "integer"}, {"v", "float"}}}},
+		`SELECT * FROM "autogen"."cpu" LIMIT 1`: {
+			{Columns: []string{"time", "n", "v"}, Values: [][]interface{}{{ts.UnixNano(), 1, 1.0}}},
+		},
+		`SELECT * FROM "autogen"."cpu" ORDER BY time DESC LIMIT 1`: {
+			{Columns: []string{"time", "n", "v"}, Values: [][]interface{}{{ts.UnixNano(), 1, 1.0}}},
+		},
+		"window": {
+			{Name: "cpu", Tags: map[string]string{"host": "a"}, Columns: []string{"time", "n", "v"}, Values: [][]interface{}{{ts.UnixNano(), 1, 1.0}}},
+		},
+		"SHOW USERS":              {{Columns: []string{"user", "admin"}, Values: [][]interface{}{{"alice", false}}}},
+		`SHOW GRANTS FOR "alice"`: {{Columns: []string{"database", "privilege"}, Values: [][]interface{}{{"db0", "READ"}}}},
+	})
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "migrate-remote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	orgID, bucketID, userID := influxdb.ID(1), influxdb.ID(2), influxdb.ID(3)
+	var (
+		created []string
+		written []string
+		auth    *influxdb.Authorization
+		auths   []*influxdb.Authorization
+	)
+	dbrps := inmem.NewService()
+
+	m := NewRemoteMigrator(RemoteConfig{
+		SourceURL: srv.URL,
+		DestOrg:   orgID,
+		BucketService: &mock.BucketService{
+			FindBucketByNameFn: func(ctx context.Context, _ influxdb.ID, name string) (*influxdb.Bucket, error) {
+				return nil, &influxdb.Error{Code: influxdb.ENotFound}
+			},
+			CreateBucketFn: func(ctx context.Context, b *influxdb.Bucket) error {
+				created = append(created, b.Name)
+				b.ID = bucketID
+				return nil
+			},
+		},
+		WriteService: &mock.WriteService{
+			WriteF: func(ctx context.Context, _, _ influxdb.ID, r io.Reader) error {
+				buf, err := ioutil.ReadAll(r)
+				written = append(written, string(buf))
+				return err
+			},
+		},
+		DBRPMappingService: dbrps,
+		MigrateUsers:       true,
+		UserServices: UserServices{
+			UserService: &mock.UserService{
+				FindUserFn: func(context.Context, influxdb.UserFilter) (*influxdb.User, error) {
+					return nil, &influxdb.Error{Code: influxdb.ENotFound}
+				},
+				CreateUserFn: func(ctx context.Context, u *influxdb.User) error {
+					u.ID = userID
+					return nil
+				},
+			},
+			UserResourceMappingService: &mock.UserResourceMappingService{
+				FindMappingsFn: func(context.Context, influxdb.UserResourceMappingFilter) ([]*influxdb.UserResourceMapping, int, error) {
+					return nil, 0, nil
+				},
+				CreateMappingFn: func(context.Context, *influxdb.UserResourceMapping) error { return nil },
+			},
+			AuthorizationService: &mock.AuthorizationService{
+				FindAuthorizationsFn: func(context.Context, influxdb.AuthorizationFilter, ...influxdb.FindOptions) ([]*influxdb.Authorization, int, error) {
+					return auths, len(auths), nil
+				},
+				CreateAuthorizationFn: func(ctx context.Context, a *influxdb.Authorization) error {
+					auth = a
+					auths = append(auths, &influxdb.Authorization{ID: 4, Description: a.Description})
+					return nil
+				},
+			},
+		},
+		CheckpointPath: filepath.Join(dir, "checkpoint"),
+	})
+
+	if err := m.Migrate(context.Background(), "", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got, exp := created, []string{"db0/autogen"}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected buckets created: got %v, exp %v", got, exp)
+	}
+	if got, exp := written, []string{"cpu,host=a n=1i,v=1 1000\n"}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected points written: got %q, exp %q", got, exp)
+	}
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	mapping, err := dbrps.FindBy(context.Background(), host, "db0", "autogen")
+	if err != nil {
+		t.Fatalf("expected db0/autogen to be mapped: %v", err)
+	}
+	if !mapping.Default || mapping.OrganizationID != orgID || mapping.BucketID != bucketID {
+		t.Fatalf("unexpected mapping %+v", mapping)
+	}
+
+	if auth == nil {
+		t.Fatal("expected authorization to be created")
+	}
+	readBucket, _ := influxdb.NewPermissionAtID(bucketID, influxdb.ReadAction, influxdb.BucketsResourceType, orgID)
+	if got, exp := auth.Permissions, []influxdb.Permission{*readBucket}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected permissions: got %v, exp %v", got, exp)
+	}
+
+	cp, err := loadCheckpoint(filepath.Join(dir, "checkpoint"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if end, ok := cp.measurement("db0", "autogen", "cpu"); !ok || end <= ts.UnixNano() {
+		t.Fatalf("unexpected checkpoint for cpu: %d, %v", end, ok)
+	}
+	if !cp.user("alice") {
+		t.Fatal("expected user to be checkpointed")
+	}
+
+	// Running again resumes from the checkpoint and copies nothing.
+	written, auth = nil, nil
+	if err := m.Migrate(context.Background(), "", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(written) != 0 || auth != nil {
+		t.Fatalf("expected resumed migration to be a no-op, wrote %q", written)
+	}
+
+	// A migration that stopped after creating the token of a user, but
+	// before recording it, does not create another one.
+	if err := os.Remove(filepath.Join(dir, "checkpoint")); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Migrate(context.Background(), "", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if auth != nil || len(auths) != 1 {
+		t.Fatalf("expected the token of the user not to be created again, got %d", len(auths))
+	}
+}
@@ -0,0 +1,178 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// Privilege1x is a privilege a 1.x user holds on a database. The values match
+// those used by InfluxQL and stored in the 1.x meta store.
+type Privilege1x int
+
+const (
+	// NoPrivileges1x grants no access.
+	NoPrivileges1x Privilege1x = iota
+	// ReadPrivilege1x grants read access.
+	ReadPrivilege1x
+	// WritePrivilege1x grants write access.
+	WritePrivilege1x
+	// AllPrivileges1x grants read and write access.
+	AllPrivileges1x
+)
+
+// parsePrivilege1x parses a privilege as returned by SHOW GRANTS.
+func parsePrivilege1x(s string) Privilege1x {
+	switch s {
+	case "READ":
+		return ReadPrivilege1x
+	case "WRITE":
+		return WritePrivilege1x
+	case "ALL PRIVILEGES":
+		return AllPrivileges1x
+	default:
+		return NoPrivileges1x
+	}
+}
+
+// User1x is a 1.x user and its per-database privileges.
+type User1x struct {
+	Name       string
+	Admin      bool
+	Privileges map[string]Privilege1x
+}
+
+// UserServices are the 2.x services used to migrate 1.x users.
+type UserServices struct {
+	UserService                influxdb.UserService
+	UserResourceMappingService influxdb.UserResourceMappingService
+	AuthorizationService       influxdb.AuthorizationService
+}
+
+// migrateUser creates a 2.x user for u, adds it to orgID and creates an
+// authorization granting the equivalent of the 1.x privileges on the buckets
+// each database was mapped to. 1.x admins become owners of orgID.
+//
+// The created authorization is returned, or nil if u holds no privileges.
+// An authorization created for u by an earlier migration that stopped before
+// recording u as migrated is returned instead of creating another one; its
+// token is not returned. Passwords are never migrated.
+func migrateUser(ctx context.Context, svc UserServices, orgID influxdb.ID, u User1x, buckets map[string][]influxdb.ID) (*influxdb.Authorization, error) {
+	user, err := svc.UserService.FindUser(ctx, influxdb.UserFilter{Name: &u.Name})
+	if err != nil {
+		if influxdb.ErrorCode(err) != influxdb.ENotFound {
+			return nil, err
+		}
+		user = &influxdb.User{Name: u.Name, Status: influxdb.Active}
+		if err := svc.UserService.CreateUser(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	userType := influxdb.Member
+	if u.Admin {
+		userType = influxdb.Owner
+	}
+	if err := addOrgMember(ctx, svc.UserResourceMappingService, orgID, user.ID, userType); err != nil {
+		return nil, err
+	}
+
+	perms, err := permissions1x(orgID, u, buckets)
+	if err != nil {
+		return nil, err
+	}
+	if len(perms) == 0 {
+		return nil, nil
+	}
+
+	description := fmt.Sprintf("%s's token migrated from 1.x", u.Name)
+	existing, _, err := svc.AuthorizationService.FindAuthorizations(ctx, influxdb.AuthorizationFilter{
+		OrgID:  &orgID,
+		UserID: &user.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range existing {
+		if a.Description == description {
+			a.Token = ""
+			return a, nil
+		}
+	}
+
+	auth := &influxdb.Authorization{
+		Description: description,
+		Status:      influxdb.Active,
+		OrgID:       orgID,
+		UserID:      user.ID,
+		Permissions: perms,
+	}
+	if err := svc.AuthorizationService.CreateAuthorization(ctx, auth); err != nil {
+		return nil, err
+	}
+	return auth, nil
+}
+
+// addOrgMember maps userID to orgID with the given user type, unless an
+// equivalent mapping already exists.
+func addOrgMember(ctx context.Context, svc influxdb.UserResourceMappingService, orgID, userID influxdb.ID, userType influxdb.UserType) error {
+	existing, _, err := svc.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+		ResourceID:   orgID,
+		ResourceType: influxdb.OrgsResourceType,
+		UserID:       userID,
+		UserType:     userType,
+	})
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return nil
+	}
+
+	return svc.CreateUserResourceMapping(ctx, &influxdb.UserResourceMapping{
+		UserID:       userID,
+		UserType:     userType,
+		MappingType:  influxdb.UserMappingType,
+		ResourceType: influxdb.OrgsResourceType,
+		ResourceID:   orgID,
+	})
+}
+
+// permissions1x returns the 2.x permissions equivalent to the privileges of u.
+func permissions1x(orgID influxdb.ID, u User1x, buckets map[string][]influxdb.ID) ([]influxdb.Permission, error) {
+	if u.Admin {
+		return influxdb.OwnerPermissions(orgID), nil
+	}
+
+	dbs := make([]string, 0, len(u.Privileges))
+	for db := range u.Privileges {
+		dbs = append(dbs, db)
+	}
+	sort.Strings(dbs)
+
+	var perms []influxdb.Permission
+	for _, db := range dbs {
+		var actions []influxdb.Action
+		switch u.Privileges[db] {
+		case ReadPrivilege1x:
+			actions = []influxdb.Action{influxdb.ReadAction}
+		case WritePrivilege1x:
+			actions = []influxdb.Action{influxdb.WriteAction}
+		case AllPrivileges1x:
+			actions = []influxdb.Action{influxdb.ReadAction, influxdb.WriteAction}
+		}
+
+		for _, bucketID := range buckets[db] {
+			for _, a := range actions {
+				p, err := influxdb.NewPermissionAtID(bucketID, a, influxdb.BucketsResourceType, orgID)
+				if err != nil {
+					return nil, err
+				}
+				perms = append(perms, *p)
+			}
+		}
+	}
+	return perms, nil
+}
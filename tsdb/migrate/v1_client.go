@@ -0,0 +1,151 @@
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// v1Client queries an InfluxDB 1.x server through its /query endpoint.
+type v1Client struct {
+	addr      string
+	username  string
+	password  string
+	chunkSize int
+	client    *http.Client
+}
+
+// v1Result is a single statement result returned by the 1.x /query endpoint.
+type v1Result struct {
+	StatementID int        `json:"statement_id"`
+	Series      []v1Series `json:"series"`
+	Partial     bool       `json:"partial"`
+	Err         string     `json:"error"`
+}
+
+// v1Series is a single series of a 1.x query result.
+type v1Series struct {
+	Name    string            `json:"name"`
+	Tags    map[string]string `json:"tags"`
+	Columns []string          `json:"columns"`
+	Values  [][]interface{}   `json:"values"`
+	Partial bool              `json:"partial"`
+}
+
+func newV1Client(addr, username, password string, chunkSize int, client *http.Client) *v1Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &v1Client{
+		addr:      strings.TrimSuffix(addr, "/"),
+		username:  username,
+		password:  password,
+		chunkSize: chunkSize,
+		client:    client,
+	}
+}
+
+// query executes q against db and calls fn for each series in the response.
+// Results are streamed using the chunked response format, so fn may be
+// called several times for the same series.
+func (c *v1Client) query(ctx context.Context, db, q string, fn func(v1Series) error) error {
+	params := url.Values{}
+	params.Set("q", q)
+	params.Set("epoch", "ns")
+	params.Set("chunked", "true")
+	if c.chunkSize > 0 {
+		params.Set("chunk_size", fmt.Sprint(c.chunkSize))
+	}
+	if db != "" {
+		params.Set("db", db)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.addr+"/query", strings.NewReader(params.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Err string `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err == nil && body.Err != "" {
+			return fmt.Errorf("1.x query failed with status %d: %s", resp.StatusCode, body.Err)
+		}
+		return fmt.Errorf("1.x query failed with status %d", resp.StatusCode)
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	for {
+		var chunk struct {
+			Results []v1Result `json:"results"`
+			Err     string     `json:"error"`
+		}
+		if err := dec.Decode(&chunk); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if chunk.Err != "" {
+			return errors.New(chunk.Err)
+		}
+		for _, res := range chunk.Results {
+			if res.Err != "" {
+				return errors.New(res.Err)
+			}
+			for _, s := range res.Series {
+				if err := fn(s); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// column returns the values of the named column across every series row
+// returned by q.
+func (c *v1Client) column(ctx context.Context, db, q, name string) ([]string, error) {
+	var values []string
+	err := c.query(ctx, db, q, func(s v1Series) error {
+		idx := columnIndex(s.Columns, name)
+		if idx < 0 {
+			return fmt.Errorf("column %q missing from result of %q", name, q)
+		}
+		for _, row := range s.Values {
+			values = append(values, fmt.Sprint(row[idx]))
+		}
+		return nil
+	})
+	return values, err
+}
+
+func columnIndex(columns []string, name string) int {
+	for i, c := range columns {
+		if c == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// quoteIdent quotes an InfluxQL identifier.
+func quoteIdent(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -127,8 +127,9 @@ func NewCacheConfig() CacheConfig {
 
 // Default WAL configuration values.
 const (
-	DefaultWALEnabled    = true
-	DefaultWALFsyncDelay = time.Duration(0)
+	DefaultWALEnabled       = true
+	DefaultWALFsyncDelay    = time.Duration(0)
+	DefaultWALFlushInterval = time.Second
 )
 
 // WALConfig holds all of the configuration about the WAL.
@@ -141,11 +142,27 @@ type WALConfig struct {
 	// useful for slower disks or when WAL write contention is seen.  A value of 0 fsyncs
 	// every write to the WAL.
 	FsyncDelay toml.Duration `toml:"fsync-delay"`
+
+	// Durability is when writes to the WAL are acknowledged: "sync" fsyncs every
+	// write, "group" batches concurrent writes into a single fsync, "async"
+	// fsyncs periodically without waiting and "none" skips the WAL. Buckets may
+	// set a different durability. Empty defaults to "group".
+	Durability string `toml:"durability"`
+
+	// GroupCommitMaxBatch is the number of writes waiting in a group commit
+	// that are fsync'd without waiting for FsyncDelay. A value of 0 uses the
+	// maximum number of waiting writes.
+	GroupCommitMaxBatch int `toml:"group-commit-max-batch"`
+
+	// FlushInterval is how often writes acknowledged asynchronously are
+	// fsync'd.
+	FlushInterval toml.Duration `toml:"flush-interval"`
 }
 
 func NewWALConfig() WALConfig {
 	return WALConfig{
-		Enabled:    DefaultWALEnabled,
-		FsyncDelay: toml.Duration(DefaultWALFsyncDelay),
+		Enabled:       DefaultWALEnabled,
+		FsyncDelay:    toml.Duration(DefaultWALFsyncDelay),
+		FlushInterval: toml.Duration(DefaultWALFlushInterval),
 	}
 }
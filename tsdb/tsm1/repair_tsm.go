@@ -0,0 +1,310 @@
+package tsm1
+
+import (
+	"bytes"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/influxdata/influxdb/v2/pkg/fs"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+)
+
+// RepairTSMBackupExtension is appended to the name of a TSM file that has been
+// replaced by a repaired copy, when backups are enabled.
+const RepairTSMBackupExtension = "bak"
+
+// RepairTSM rewrites TSM files, dropping any block that fails verification.
+//
+// A block is considered corrupt if it cannot be read, its CRC-32 checksum does
+// not match, its timestamps cannot be decoded or the decoded timestamps do not
+// match the min and max times recorded in the index.
+type RepairTSM struct {
+	Stdout io.Writer
+	Paths  []string
+
+	// Backup keeps the original file alongside the repaired one, using the
+	// RepairTSMBackupExtension.
+	Backup bool
+
+	// DryRun reports corrupt blocks without rewriting any files.
+	DryRun bool
+}
+
+// LostBlock describes a block that was dropped from a TSM file during repair.
+type LostBlock struct {
+	Key     []byte
+	MinTime int64
+	MaxTime int64
+	Reason  string
+}
+
+// RepairTSMFile describes the outcome of repairing a single TSM file.
+type RepairTSMFile struct {
+	Path       string
+	BlockCount int
+	LostBlocks []LostBlock
+
+	// LostAllKeys lists the keys for which every block in the file was dropped.
+	LostAllKeys [][]byte
+
+	// Removed is true if every block was dropped and the file was removed.
+	Removed bool
+	Err     error
+}
+
+// RepairTSMSummary describes the outcome of a RepairTSM run.
+type RepairTSMSummary struct {
+	Files []RepairTSMFile
+}
+
+// Repaired returns true if any blocks were dropped from any file.
+func (s *RepairTSMSummary) Repaired() bool {
+	for _, f := range s.Files {
+		if f.Err == nil && len(f.LostBlocks) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteReport writes a tab separated report of every lost block to w. The
+// blocks of files that could not be repaired are not lost.
+func (s *RepairTSMSummary) WriteReport(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 8, 2, 1, ' ', 0)
+	fmt.Fprintln(tw, "file\tkey\tmin_time\tmax_time\treason")
+	for _, f := range s.Files {
+		if f.Err != nil {
+			continue
+		}
+		for _, b := range f.LostBlocks {
+			fmt.Fprintf(tw, "%s\t%q\t%d\t%d\t%s\n", f.Path, b.Key, b.MinTime, b.MaxTime, b.Reason)
+		}
+	}
+	return tw.Flush()
+}
+
+// Run repairs each file in Paths. An error is returned along with the summary
+// if any file could not be repaired.
+func (r *RepairTSM) Run() (*RepairTSMSummary, error) {
+	if r.Stdout == nil {
+		r.Stdout = ioutil.Discard
+	}
+
+	summary := &RepairTSMSummary{}
+	var failed int
+	for _, path := range r.Paths {
+		res := r.processFile(path)
+		if res.Err != nil {
+			failed++
+			fmt.Fprintf(r.Stdout, "Error processing file %q: %v\n", path, res.Err)
+		} else if len(res.LostBlocks) == 0 {
+			fmt.Fprintf(r.Stdout, "%s: clean (%d blocks)\n", path, res.BlockCount)
+		} else {
+			fmt.Fprintf(r.Stdout, "%s: dropped %d of %d blocks\n", path, len(res.LostBlocks), res.BlockCount)
+		}
+		summary.Files = append(summary.Files, res)
+	}
+
+	if failed > 0 {
+		return summary, fmt.Errorf("failed to repair %d of %d TSM files", failed, len(r.Paths))
+	}
+	return summary, nil
+}
+
+func (r *RepairTSM) processFile(path string) RepairTSMFile {
+	res := RepairTSMFile{Path: path}
+
+	file, err := os.OpenFile(path, os.O_RDONLY, 0600)
+	if err != nil {
+		res.Err = fmt.Errorf("OpenFile: %v", err)
+		return res
+	}
+
+	reader, err := NewTSMReader(file)
+	if err != nil {
+		res.Err = fmt.Errorf("failed to create TSM reader for %q: %v", path, err)
+		return res
+	}
+
+	// First pass: identify the corrupt blocks so a clean file is never rewritten.
+	lost := make(map[int]string)
+	blocks, lostBlocks := make(map[string]int), make(map[string]int)
+	iter := reader.BlockIterator()
+	for i := 0; iter.Next(); i++ {
+		res.BlockCount++
+		entry, key := iter.entries[0], iter.iter.Key()
+		blocks[string(key)]++
+
+		_, _, _, _, checksum, buf, err := iter.Read()
+		reason := verifyBlock(entry.MinTime, entry.MaxTime, checksum, buf, err)
+		if reason == "" {
+			continue
+		}
+
+		lost[i] = reason
+		lostBlocks[string(key)]++
+		res.LostBlocks = append(res.LostBlocks, LostBlock{
+			Key:     append([]byte(nil), key...),
+			MinTime: entry.MinTime,
+			MaxTime: entry.MaxTime,
+			Reason:  reason,
+		})
+	}
+	if err := iter.Err(); err != nil {
+		reader.Close()
+		res.Err = fmt.Errorf("failed to iterate TSM index: %v", err)
+		return res
+	}
+
+	for key, n := range lostBlocks {
+		if n == blocks[key] {
+			res.LostAllKeys = append(res.LostAllKeys, []byte(key))
+		}
+	}
+	sort.Slice(res.LostAllKeys, func(i, j int) bool { return bytes.Compare(res.LostAllKeys[i], res.LostAllKeys[j]) < 0 })
+
+	if len(lost) == 0 || r.DryRun {
+		reader.Close()
+		return res
+	}
+
+	res.Removed, res.Err = r.rewrite(path, reader, lost)
+	return res
+}
+
+// rewrite copies all blocks not in lost from reader into a new file which then
+// replaces the file at path. The reader is closed before the file is replaced.
+func (r *RepairTSM) rewrite(path string, reader *TSMReader, lost map[int]string) (removed bool, err error) {
+	tmpPath := path + "." + TmpTSMFileExtension
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_RDWR|os.O_EXCL, 0666)
+	if err != nil {
+		reader.Close()
+		return false, err
+	}
+
+	w, err := NewTSMWriter(f)
+	if err != nil {
+		reader.Close()
+		f.Close()
+		os.Remove(tmpPath)
+		return false, err
+	}
+
+	var written int
+	iter := reader.BlockIterator()
+	for i := 0; iter.Next(); i++ {
+		if _, ok := lost[i]; ok {
+			continue
+		}
+		key, minTime, maxTime, _, _, buf, err := iter.Read()
+		if err != nil {
+			reader.Close()
+			w.Close()
+			os.Remove(tmpPath)
+			return false, err
+		}
+		if err := w.WriteBlock(key, minTime, maxTime, buf); err != nil && err != ErrMaxBlocksExceeded {
+			reader.Close()
+			w.Close()
+			os.Remove(tmpPath)
+			return false, err
+		}
+		written++
+	}
+
+	// The stats file of the repaired file has the same name as the original,
+	// so it must be moved out of the way before the writer is closed.
+	if err := r.replaceStats(path); err != nil {
+		reader.Close()
+		w.Close()
+		os.Remove(tmpPath)
+		return false, err
+	}
+
+	if written == 0 {
+		// Every block was corrupt; there is nothing left to keep.
+		w.Close()
+		os.Remove(tmpPath)
+		os.Remove(StatsFilename(tmpPath))
+		if err := reader.Close(); err != nil {
+			return false, err
+		}
+		return true, r.replace(path, "")
+	}
+
+	if err := w.WriteIndex(); err != nil {
+		reader.Close()
+		w.Close()
+		os.Remove(tmpPath)
+		return false, err
+	}
+	if err := w.Close(); err != nil {
+		reader.Close()
+		os.Remove(tmpPath)
+		return false, err
+	}
+	if err := reader.Close(); err != nil {
+		return false, err
+	}
+	return false, r.replace(path, tmpPath)
+}
+
+// replace moves the original file out of the way, either to a backup or by
+// removing it, and renames tmpPath over it. If tmpPath is empty the original
+// file is only moved or removed.
+func (r *RepairTSM) replace(path, tmpPath string) error {
+	if r.Backup {
+		if err := fs.RenameFile(path, path+"."+RepairTSMBackupExtension); err != nil {
+			return err
+		}
+	} else if err := os.Remove(path); err != nil {
+		return err
+	}
+
+	if tmpPath == "" {
+		return nil
+	}
+	return fs.RenameFile(tmpPath, path)
+}
+
+// replaceStats moves the stats file for the TSM file at path out of the way,
+// either to a backup or by removing it.
+func (r *RepairTSM) replaceStats(path string) error {
+	statsPath := StatsFilename(path)
+	if _, err := os.Stat(statsPath); os.IsNotExist(err) {
+		return nil
+	}
+
+	if r.Backup {
+		return fs.RenameFile(statsPath, statsPath+"."+RepairTSMBackupExtension)
+	}
+	return os.Remove(statsPath)
+}
+
+// verifyBlock returns a description of why a block is corrupt, or an empty
+// string if the block is valid.
+func verifyBlock(minTime, maxTime int64, checksum uint32, buf []byte, err error) string {
+	if err != nil {
+		return fmt.Sprintf("unreadable: %v", err)
+	}
+	if exp := crc32.ChecksumIEEE(buf); checksum != exp {
+		return fmt.Sprintf("checksum mismatch: got %d, expected %d", checksum, exp)
+	}
+
+	var ts cursors.TimestampArray
+	if err := DecodeTimestampArrayBlock(buf, &ts); err != nil {
+		return fmt.Sprintf("undecodable timestamps: %v", err)
+	}
+	if got := ts.MinTime(); got != minTime {
+		return fmt.Sprintf("min time mismatch: index %d, block %d", minTime, got)
+	}
+	if got := ts.MaxTime(); got != maxTime {
+		return fmt.Sprintf("max time mismatch: index %d, block %d", maxTime, got)
+	}
+	return ""
+}
@@ -0,0 +1,162 @@
+package tsm1
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRepairTSM_DropsCorruptBlock(t *testing.T) {
+	dir := mustTempDir()
+	defer os.RemoveAll(dir)
+	f := mustTempFile(dir)
+	path := f.Name()
+
+	w, err := NewTSMWriter(f)
+	if err != nil {
+		t.Fatalf("unexpected error creating writer: %v", err)
+	}
+	for _, key := range []string{"cpu", "disk", "mem"} {
+		if err := w.Write([]byte(key), []Value{NewValue(1, 1.0), NewValue(2, 2.0)}); err != nil {
+			t.Fatalf("unexpected error writing: %v", err)
+		}
+	}
+	if err := w.WriteIndex(); err != nil {
+		t.Fatalf("unexpected error writing index: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error closing: %v", err)
+	}
+
+	// Flip a byte inside the block for "disk" so its checksum no longer matches.
+	entries := mustReadEntries(t, path, "disk")
+	fd, err := os.OpenFile(path, os.O_RDWR, 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fd.WriteAt([]byte{0xff}, entries[0].Offset+int64(entries[0].Size)-1); err != nil {
+		t.Fatal(err)
+	}
+	if err := fd.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	repair := RepairTSM{Paths: []string{path}, Backup: true}
+	summary, err := repair.Run()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	res := summary.Files[0]
+	if res.Err != nil {
+		t.Fatalf("unexpected repair error: %v", res.Err)
+	}
+	if got, exp := len(res.LostBlocks), 1; got != exp {
+		t.Fatalf("unexpected lost blocks: got %d, exp %d", got, exp)
+	}
+	if got, exp := string(res.LostBlocks[0].Key), "disk"; got != exp {
+		t.Fatalf("unexpected lost key: got %q, exp %q", got, exp)
+	}
+	if got, exp := len(res.LostAllKeys), 1; got != exp {
+		t.Fatalf("unexpected lost keys: got %d, exp %d", got, exp)
+	}
+
+	if _, err := os.Stat(path + "." + RepairTSMBackupExtension); err != nil {
+		t.Fatalf("expected backup file: %v", err)
+	}
+
+	fd, err = os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewTSMReader(fd)
+	if err != nil {
+		t.Fatalf("unexpected error opening repaired file: %v", err)
+	}
+	defer r.Close()
+
+	if got, exp := r.KeyCount(), 2; got != exp {
+		t.Fatalf("unexpected key count: got %d, exp %d", got, exp)
+	}
+	if r.Contains([]byte("disk")) {
+		t.Fatalf("expected corrupt key to be dropped")
+	}
+	values, err := r.ReadAll([]byte("mem"))
+	if err != nil {
+		t.Fatalf("unexpected error reading: %v", err)
+	}
+	if got, exp := len(values), 2; got != exp {
+		t.Fatalf("unexpected values: got %d, exp %d", got, exp)
+	}
+}
+
+func TestRepairTSM_DryRun(t *testing.T) {
+	dir := mustTempDir()
+	defer os.RemoveAll(dir)
+	f := mustTempFile(dir)
+	path := f.Name()
+
+	w, err := NewTSMWriter(f)
+	if err != nil {
+		t.Fatalf("unexpected error creating writer: %v", err)
+	}
+	if err := w.Write([]byte("cpu"), []Value{NewValue(1, 1.0)}); err != nil {
+		t.Fatalf("unexpected error writing: %v", err)
+	}
+	if err := w.WriteIndex(); err != nil {
+		t.Fatalf("unexpected error writing index: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error closing: %v", err)
+	}
+
+	repair := RepairTSM{Paths: []string{path}, DryRun: true}
+	summary, err := repair.Run()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Repaired() {
+		t.Fatalf("expected clean file, got %v", summary.Files[0].LostBlocks)
+	}
+}
+
+func TestRepairTSM_FileError(t *testing.T) {
+	dir := mustTempDir()
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "missing."+TSMFileExtension)
+
+	repair := RepairTSM{Paths: []string{path}}
+	summary, err := repair.Run()
+	if err == nil {
+		t.Fatal("expected an error for a file that could not be repaired")
+	}
+	if got, exp := len(summary.Files), 1; got != exp {
+		t.Fatalf("unexpected files: got %d, exp %d", got, exp)
+	}
+	if summary.Files[0].Err == nil {
+		t.Fatal("expected the error of the file")
+	}
+	if summary.Repaired() {
+		t.Fatal("expected no repaired files")
+	}
+}
+
+func mustReadEntries(t *testing.T, path, key string) []IndexEntry {
+	t.Helper()
+
+	fd, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewTSMReader(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	entries, err := r.ReadEntries([]byte(key), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
@@ -1,5 +1,5 @@
 import AJAX from 'src/utils/ajax'
-import {Authorization, Auth0Config} from 'src/types'
+import {Authorization, Auth0Config, OAuthProvider} from 'src/types'
 import {getAPIBasepath} from 'src/utils/basepath'
 
 export const createAuthorization = async (
@@ -31,3 +31,17 @@ export const getAuth0Config = async (): Promise<Auth0Config> => {
     throw error
   }
 }
+
+export const getOAuthProviders = async (): Promise<OAuthProvider[]> => {
+  try {
+    const response = await fetch(`${getAPIBasepath()}/api/v2/oauth`)
+    if (!response.ok) {
+      return []
+    }
+    const {providers} = await response.json()
+    return providers
+  } catch (error) {
+    console.error(error)
+    return []
+  }
+}
@@ -9,6 +9,7 @@ import {Form, Input, Button, Grid} from '@influxdata/clockface'
 
 // APIs
 import {postSignin} from 'src/client'
+import {getOAuthProviders} from 'src/authorizations/apis'
 
 // Actions
 import {notify as notifyAction} from 'src/shared/actions/notifications'
@@ -18,7 +19,7 @@ import * as copy from 'src/shared/copy/notifications'
 
 // Types
 import {Links} from 'src/types/links'
-import {AppState} from 'src/types'
+import {AppState, OAuthProvider} from 'src/types'
 import {
   Columns,
   InputType,
@@ -27,6 +28,9 @@ import {
   ComponentColor,
 } from '@influxdata/clockface'
 
+// Utils
+import {getAPIBasepath} from 'src/utils/basepath'
+
 // Decorators
 import {ErrorHandling} from 'src/shared/decorators/errors'
 
@@ -38,6 +42,7 @@ export interface OwnProps {
 interface State {
   username: string
   password: string
+  providers: OAuthProvider[]
 }
 
 type Props = OwnProps & WithRouterProps
@@ -47,10 +52,16 @@ class SigninForm extends PureComponent<Props, State> {
   public state: State = {
     username: '',
     password: '',
+    providers: [],
+  }
+
+  public async componentDidMount() {
+    const providers = await getOAuthProviders()
+    this.setState({providers})
   }
 
   public render() {
-    const {username, password} = this.state
+    const {username, password, providers} = this.state
     return (
       <Form onSubmit={this.handleSignIn}>
         <Grid>
@@ -89,6 +100,18 @@ class SigninForm extends PureComponent<Props, State> {
                 />
               </Form.Footer>
             </Grid.Column>
+            {providers.map(p => (
+              <Grid.Column widthXS={Columns.Twelve} key={p.name}>
+                <Form.Footer>
+                  <Button
+                    text={`Sign In with ${p.name}`}
+                    size={ComponentSize.Medium}
+                    onClick={() => this.handleOAuthSignIn(p)}
+                    testID={`oauth--${p.name}`}
+                  />
+                </Form.Footer>
+              </Grid.Column>
+            ))}
           </Grid.Row>
         </Grid>
       </Form>
@@ -137,6 +160,10 @@ class SigninForm extends PureComponent<Props, State> {
     }
   }
 
+  private handleOAuthSignIn = (provider: OAuthProvider): void => {
+    window.location.href = `${getAPIBasepath()}${provider.login}`
+  }
+
   private handleRedirect() {
     const {router} = this.props
     const {query} = this.props.location
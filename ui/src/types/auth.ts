@@ -12,3 +12,8 @@ export type Auth0Config = {
   redirectURL: string
   state: string
 }
+
+export type OAuthProvider = {
+  name: string
+  login: string
+}
@@ -38,6 +38,13 @@ type UsageService interface {
 	GetUsage(ctx context.Context, filter UsageFilter) (map[UsageMetric]*Usage, error)
 }
 
+// UsageRecorder records the usage of organizations as it happens.
+type UsageRecorder interface {
+	// RecordUsage adds the value of u to the usage of its organization,
+	// and of its bucket when it is set.
+	RecordUsage(ctx context.Context, u Usage)
+}
+
 // UsageFilter is used to filter usage.
 type UsageFilter struct {
 	OrgID    *ID
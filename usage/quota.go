@@ -0,0 +1,183 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
+)
+
+// storageStatsInterval is how long the sizes of the organizations in storage
+// are used before they are read again.
+const storageStatsInterval = time.Minute
+
+// StorageStats reports the size of the data in storage by measurement, where
+// every measurement is the encoded name of an organization and bucket.
+type StorageStats interface {
+	MeasurementStats() (tsm1.MeasurementStats, error)
+}
+
+// monthlyBytes are the bytes written by an organization in a month.
+type monthlyBytes struct {
+	month time.Time
+	bytes int64
+}
+
+// Quotas enforces the write quotas of organizations: the bytes they may
+// write every calendar month and the size their data may take up in storage.
+//
+// The bytes written in the current month are found with a UsageService the
+// first time they are needed and counted in memory from then on. The sizes in
+// storage are those of the TSM files, which are read at most once a minute.
+type Quotas struct {
+	orgs  influxdb.OrgLimitsService
+	usage influxdb.UsageService
+	stats StorageStats
+	now   func() time.Time
+
+	mu      sync.Mutex
+	monthly map[influxdb.ID]*monthlyBytes
+
+	statsMu   sync.Mutex
+	storage   map[influxdb.ID]int64
+	storageAt time.Time
+}
+
+// NewQuotas returns Quotas that finds the quotas of organizations with orgs,
+// their usage with usage and their sizes in storage with stats.
+func NewQuotas(orgs influxdb.OrgLimitsService, usage influxdb.UsageService, stats StorageStats) *Quotas {
+	return &Quotas{
+		orgs:    orgs,
+		usage:   usage,
+		stats:   stats,
+		now:     time.Now,
+		monthly: make(map[influxdb.ID]*monthlyBytes),
+	}
+}
+
+// AllowWrite returns a forbidden error if a write of bytes to the
+// organization exceeds any of its quotas. Allowed writes count towards the
+// monthly quota, so that concurrent writes cannot exceed it together, and
+// must be refunded with RefundWrite if they fail.
+func (q *Quotas) AllowWrite(ctx context.Context, orgID influxdb.ID, bytes int) error {
+	lim, err := q.orgs.FindOrgLimits(ctx, orgID)
+	if err != nil {
+		return err
+	}
+
+	if lim.StorageBytesQuota > 0 {
+		size, err := q.storageBytes(orgID)
+		if err != nil {
+			return err
+		}
+		if size >= lim.StorageBytesQuota {
+			return &influxdb.Error{
+				Code: influxdb.EForbidden,
+				Msg:  fmt.Sprintf("storage quota of %d bytes of the organization exceeded", lim.StorageBytesQuota),
+			}
+		}
+	}
+
+	if lim.MonthlyWriteBytesQuota > 0 {
+		return q.allowMonthly(ctx, orgID, int64(bytes), lim.MonthlyWriteBytesQuota)
+	}
+	return nil
+}
+
+// RefundWrite no longer counts a failed write of bytes to the organization,
+// which AllowWrite allowed, towards its monthly quota.
+func (q *Quotas) RefundWrite(orgID influxdb.ID, bytes int) {
+	now := q.now().UTC()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if m, ok := q.monthly[orgID]; ok && m.month.Equal(month) {
+		m.bytes -= int64(bytes)
+		if m.bytes < 0 {
+			m.bytes = 0
+		}
+	}
+}
+
+// allowMonthly counts a write of n bytes towards the monthly quota of the
+// organization, unless it exceeds the quota.
+func (q *Quotas) allowMonthly(ctx context.Context, orgID influxdb.ID, n, quota int64) error {
+	now := q.now().UTC()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	q.mu.Lock()
+	m, ok := q.monthly[orgID]
+	q.mu.Unlock()
+
+	if !ok || !m.month.Equal(month) {
+		// The usage is queried without holding the lock, so that writes
+		// of other organizations do not wait for it.
+		written, err := q.writtenBytes(ctx, orgID, month, now)
+		if err != nil {
+			return err
+		}
+
+		q.mu.Lock()
+		if m, ok = q.monthly[orgID]; !ok || !m.month.Equal(month) {
+			m = &monthlyBytes{month: month, bytes: written}
+			q.monthly[orgID] = m
+		}
+		q.mu.Unlock()
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if m.bytes+n > quota {
+		return &influxdb.Error{
+			Code: influxdb.EForbidden,
+			Msg:  fmt.Sprintf("monthly write quota of %d bytes of the organization exceeded", quota),
+		}
+	}
+	m.bytes += n
+	return nil
+}
+
+// writtenBytes returns the bytes written by the organization from start to
+// stop.
+func (q *Quotas) writtenBytes(ctx context.Context, orgID influxdb.ID, start, stop time.Time) (int64, error) {
+	usage, err := q.usage.GetUsage(ctx, influxdb.UsageFilter{
+		OrgID: &orgID,
+		Range: &influxdb.Timespan{Start: start, Stop: stop},
+	})
+	if err != nil {
+		return 0, err
+	}
+	if u, ok := usage[influxdb.UsageWriteRequestBytes]; ok {
+		return int64(u.Value), nil
+	}
+	return 0, nil
+}
+
+// storageBytes returns the size of the data of the organization in storage.
+func (q *Quotas) storageBytes(orgID influxdb.ID) (int64, error) {
+	q.statsMu.Lock()
+	defer q.statsMu.Unlock()
+
+	if now := q.now(); q.storage == nil || now.Sub(q.storageAt) >= storageStatsInterval {
+		stats, err := q.stats.MeasurementStats()
+		if err != nil {
+			return 0, err
+		}
+
+		sizes := make(map[influxdb.ID]int64)
+		for name, size := range stats {
+			if len(name) != len(tsdb.EncodeName(0, 0)) {
+				continue
+			}
+			org, _ := tsdb.DecodeNameSlice([]byte(name))
+			sizes[org] += int64(size)
+		}
+		q.storage, q.storageAt = sizes, now
+	}
+	return q.storage[orgID], nil
+}
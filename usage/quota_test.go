@@ -0,0 +1,105 @@
+package usage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
+)
+
+type storageStats tsm1.MeasurementStats
+
+func (s storageStats) MeasurementStats() (tsm1.MeasurementStats, error) {
+	return tsm1.MeasurementStats(s), nil
+}
+
+func newTestQuotas(limits influxdb.OrgLimits, written float64, stats storageStats) (*Quotas, *time.Time, *int) {
+	orgs := mock.NewOrgLimitsService()
+	orgs.FindOrgLimitsFn = func(_ context.Context, orgID influxdb.ID) (*influxdb.OrgLimits, error) {
+		l := limits
+		l.OrgID = orgID
+		return &l, nil
+	}
+
+	var queried int
+	usage := mock.NewUsageService()
+	usage.GetUsageFn = func(_ context.Context, filter influxdb.UsageFilter) (map[influxdb.UsageMetric]*influxdb.Usage, error) {
+		queried++
+		return map[influxdb.UsageMetric]*influxdb.Usage{
+			influxdb.UsageWriteRequestBytes: {OrganizationID: filter.OrgID, Type: influxdb.UsageWriteRequestBytes, Value: written},
+		}, nil
+	}
+
+	now := time.Date(2020, time.March, 31, 23, 59, 0, 0, time.UTC)
+	q := NewQuotas(orgs, usage, stats)
+	q.now = func() time.Time { return now }
+	return q, &now, &queried
+}
+
+func expectForbidden(t *testing.T, err error, msg string) {
+	t.Helper()
+	if influxdb.ErrorCode(err) != influxdb.EForbidden {
+		t.Fatalf("expected a forbidden error, got %v", err)
+	}
+	if got := influxdb.ErrorMessage(err); got != msg {
+		t.Errorf("got error %q want %q", got, msg)
+	}
+}
+
+func TestQuotas_monthly(t *testing.T) {
+	ctx := context.Background()
+	orgID := influxdb.ID(1)
+	q, now, queried := newTestQuotas(influxdb.OrgLimits{MonthlyWriteBytesQuota: 1000}, 900, nil)
+
+	if err := q.AllowWrite(ctx, orgID, 100); err != nil {
+		t.Fatal(err)
+	}
+	expectForbidden(t, q.AllowWrite(ctx, orgID, 1), "monthly write quota of 1000 bytes of the organization exceeded")
+
+	// A refunded write leaves room for another.
+	q.RefundWrite(orgID, 100)
+	if err := q.AllowWrite(ctx, orgID, 100); err != nil {
+		t.Fatal(err)
+	}
+	expectForbidden(t, q.AllowWrite(ctx, orgID, 1), "monthly write quota of 1000 bytes of the organization exceeded")
+	if *queried != 1 {
+		t.Fatalf("expected the usage of the month to be queried once, got %d", *queried)
+	}
+
+	// The quota starts over every month.
+	*now = now.Add(time.Minute)
+	q.usage.(*mock.UsageService).GetUsageFn = func(_ context.Context, filter influxdb.UsageFilter) (map[influxdb.UsageMetric]*influxdb.Usage, error) {
+		if start := time.Date(2020, time.April, 1, 0, 0, 0, 0, time.UTC); !filter.Range.Start.Equal(start) {
+			t.Errorf("expected the usage since %s, got since %s", start, filter.Range.Start)
+		}
+		return map[influxdb.UsageMetric]*influxdb.Usage{}, nil
+	}
+	if err := q.AllowWrite(ctx, orgID, 1000); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQuotas_storage(t *testing.T) {
+	ctx := context.Background()
+	orgID := influxdb.ID(1)
+	stats := storageStats{
+		string(tsdb.EncodeNameSlice(orgID, influxdb.ID(2))):          600,
+		string(tsdb.EncodeNameSlice(orgID, influxdb.ID(3))):          400,
+		string(tsdb.EncodeNameSlice(influxdb.ID(4), influxdb.ID(5))): 5000,
+	}
+	q, now, _ := newTestQuotas(influxdb.OrgLimits{StorageBytesQuota: 1000}, 0, stats)
+
+	expectForbidden(t, q.AllowWrite(ctx, orgID, 1), "storage quota of 1000 bytes of the organization exceeded")
+
+	// The sizes are read again once they are old.
+	stats[string(tsdb.EncodeNameSlice(orgID, influxdb.ID(3)))] = 100
+	expectForbidden(t, q.AllowWrite(ctx, orgID, 1), "storage quota of 1000 bytes of the organization exceeded")
+	*now = now.Add(storageStatsInterval)
+	if err := q.AllowWrite(ctx, orgID, 1); err != nil {
+		t.Fatal(err)
+	}
+}
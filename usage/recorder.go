@@ -0,0 +1,202 @@
+// Package usage accounts for the usage of organizations and enforces their
+// write quotas.
+//
+// Usage is recorded in memory and written in batches to the usage system
+// bucket of every organization, from which it is queried by time range.
+package usage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"go.uber.org/zap"
+)
+
+// DefaultFlushInterval is the interval at which recorded usage is written
+// to the usage system buckets.
+const DefaultFlushInterval = time.Minute
+
+const (
+	usageMeasurement = "usage"
+	bucketIDTag      = "bucket_id"
+)
+
+// key is the organization, and optionally the bucket, that usage is
+// recorded for.
+type key struct {
+	org    influxdb.ID
+	bucket influxdb.ID
+}
+
+var _ influxdb.UsageRecorder = (*Recorder)(nil)
+
+// Recorder records the usage of organizations. The usage is summed in memory
+// and written to the usage system bucket of every organization on flush, as
+// a point of the "usage" measurement with a field for every metric. Usage of
+// a bucket is tagged with its ID.
+type Recorder struct {
+	log     *zap.Logger
+	buckets influxdb.BucketService
+	writer  storage.PointsWriter
+	now     func() time.Time
+
+	mu      sync.Mutex
+	pending map[key]map[influxdb.UsageMetric]float64
+}
+
+// NewRecorder returns a Recorder that finds and creates the usage system
+// buckets with buckets and writes usage to writer.
+func NewRecorder(log *zap.Logger, buckets influxdb.BucketService, writer storage.PointsWriter) *Recorder {
+	return &Recorder{
+		log:     log,
+		buckets: buckets,
+		writer:  writer,
+		now:     time.Now,
+		pending: make(map[key]map[influxdb.UsageMetric]float64),
+	}
+}
+
+// RecordUsage adds the value of u to the usage recorded since the last flush.
+// Usage without an organization is dropped.
+func (r *Recorder) RecordUsage(ctx context.Context, u influxdb.Usage) {
+	if u.OrganizationID == nil || !u.OrganizationID.Valid() {
+		return
+	}
+
+	k := key{org: *u.OrganizationID}
+	if u.BucketID != nil {
+		k.bucket = *u.BucketID
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.add(k, u.Type, u.Value)
+}
+
+func (r *Recorder) add(k key, m influxdb.UsageMetric, v float64) {
+	metrics, ok := r.pending[k]
+	if !ok {
+		metrics = make(map[influxdb.UsageMetric]float64)
+		r.pending[k] = metrics
+	}
+	metrics[m] += v
+}
+
+// Run flushes the usage every interval until ctx is done.
+func (r *Recorder) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Flush(ctx); err != nil && ctx.Err() == nil {
+				r.log.Error("Failed to flush usage", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Flush writes the usage recorded since the last flush to the usage system
+// buckets, creating the buckets that do not exist yet. Usage of deleted
+// organizations is dropped, and usage that fails to be written is kept for
+// the next flush. The last error is returned.
+func (r *Recorder) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	pending := r.pending
+	r.pending = make(map[key]map[influxdb.UsageMetric]float64)
+	r.mu.Unlock()
+
+	byOrg := make(map[influxdb.ID]map[key]map[influxdb.UsageMetric]float64)
+	for k, metrics := range pending {
+		if byOrg[k.org] == nil {
+			byOrg[k.org] = make(map[key]map[influxdb.UsageMetric]float64)
+		}
+		byOrg[k.org][k] = metrics
+	}
+
+	now := r.now()
+
+	var lastErr error
+	for orgID, usage := range byOrg {
+		err := r.write(ctx, orgID, usage, now)
+		if err == nil {
+			continue
+		}
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			r.log.Debug("Dropping usage of missing organization", zap.Stringer("org_id", orgID), zap.Error(err))
+			continue
+		}
+
+		lastErr = err
+		r.mu.Lock()
+		for k, metrics := range usage {
+			for m, v := range metrics {
+				r.add(k, m, v)
+			}
+		}
+		r.mu.Unlock()
+	}
+	return lastErr
+}
+
+// write writes the usage of an organization to its usage system bucket.
+func (r *Recorder) write(ctx context.Context, orgID influxdb.ID, usage map[key]map[influxdb.UsageMetric]float64, now time.Time) error {
+	b, err := r.systemBucket(ctx, orgID)
+	if err != nil {
+		return err
+	}
+
+	points := make(models.Points, 0, len(usage))
+	for k, metrics := range usage {
+		var tags models.Tags
+		if k.bucket.Valid() {
+			tags = models.NewTags(map[string]string{bucketIDTag: k.bucket.String()})
+		}
+
+		fields := make(map[string]interface{}, len(metrics))
+		for m, v := range metrics {
+			fields[string(m)] = v
+		}
+
+		p, err := models.NewPoint(usageMeasurement, tags, fields, now)
+		if err != nil {
+			return err
+		}
+		points = append(points, p)
+	}
+
+	points, err = tsdb.ExplodePoints(orgID, b.ID, points)
+	if err != nil {
+		return err
+	}
+	return r.writer.WritePoints(ctx, points)
+}
+
+// systemBucket returns the usage system bucket of the organization. The
+// bucket is created on the first flush of usage of the organization.
+func (r *Recorder) systemBucket(ctx context.Context, orgID influxdb.ID) (*influxdb.Bucket, error) {
+	b, err := r.buckets.FindBucketByName(ctx, orgID, influxdb.UsageSystemBucketName)
+	if influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return b, err
+	}
+
+	b = &influxdb.Bucket{
+		OrgID:           orgID,
+		Type:            influxdb.BucketTypeSystem,
+		Name:            influxdb.UsageSystemBucketName,
+		Description:     "System bucket for usage",
+		RetentionPeriod: influxdb.InfiniteRetention,
+	}
+	if err := r.buckets.CreateBucket(ctx, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
@@ -0,0 +1,141 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"go.uber.org/zap/zaptest"
+)
+
+// writtenUsage returns the values of the usage points written to the
+// bucket, by bucket_id tag and field.
+func writtenUsage(t *testing.T, points []models.Point, orgID, bucketID influxdb.ID) map[string]float64 {
+	t.Helper()
+	values := make(map[string]float64)
+	for _, p := range points {
+		org, bucket := tsdb.DecodeNameSlice(p.Name())
+		if org != orgID || bucket != bucketID {
+			t.Fatalf("expected points of the usage bucket %s/%s, got %s/%s", orgID, bucketID, org, bucket)
+		}
+		if m := p.Tags().GetString(models.MeasurementTagKey); m != usageMeasurement {
+			t.Fatalf("expected measurement %q, got %q", usageMeasurement, m)
+		}
+
+		iter := p.FieldIterator()
+		for iter.Next() {
+			v, err := iter.FloatValue()
+			if err != nil {
+				t.Fatal(err)
+			}
+			values[p.Tags().GetString(bucketIDTag)+"/"+string(iter.FieldKey())] += v
+		}
+	}
+	return values
+}
+
+func TestRecorder_Flush(t *testing.T) {
+	ctx := context.Background()
+	orgID, bucketID, usageBucketID := influxdb.ID(1), influxdb.ID(2), influxdb.ID(3)
+
+	var created []*influxdb.Bucket
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByNameFn = func(_ context.Context, org influxdb.ID, name string) (*influxdb.Bucket, error) {
+		for _, b := range created {
+			if b.OrgID == org && b.Name == name {
+				return b, nil
+			}
+		}
+		return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "bucket not found"}
+	}
+	buckets.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+		if b.OrgID != orgID {
+			return &influxdb.Error{Code: influxdb.ENotFound, Msg: "organization not found"}
+		}
+		b.ID = usageBucketID
+		created = append(created, b)
+		return nil
+	}
+	pw := &mock.PointsWriter{}
+
+	r := NewRecorder(zaptest.NewLogger(t), buckets, pw)
+	r.now = func() time.Time { return time.Unix(0, 0) }
+
+	record := func(org, bucket *influxdb.ID, m influxdb.UsageMetric, v float64) {
+		r.RecordUsage(ctx, influxdb.Usage{OrganizationID: org, BucketID: bucket, Type: m, Value: v})
+	}
+	missingOrgID := influxdb.ID(10)
+	record(&orgID, &bucketID, influxdb.UsageWriteRequestBytes, 100)
+	record(&orgID, &bucketID, influxdb.UsageWriteRequestBytes, 50)
+	record(&orgID, &bucketID, influxdb.UsageValues, 3)
+	record(&orgID, nil, influxdb.UsageQueryRequestCount, 1)
+	record(nil, &bucketID, influxdb.UsageQueryRequestCount, 1)
+	record(&missingOrgID, nil, influxdb.UsageQueryRequestCount, 1)
+
+	if err := r.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(created) != 1 || created[0].Type != influxdb.BucketTypeSystem || created[0].Name != influxdb.UsageSystemBucketName {
+		t.Fatalf("expected the usage system bucket to be created, got %+v", created)
+	}
+
+	got := writtenUsage(t, pw.Points, orgID, usageBucketID)
+	want := map[string]float64{
+		bucketID.String() + "/" + string(influxdb.UsageWriteRequestBytes): 150,
+		bucketID.String() + "/" + string(influxdb.UsageValues):            3,
+		"/" + string(influxdb.UsageQueryRequestCount):                     1,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got usage %v want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("got usage %v want %v", got, want)
+			break
+		}
+	}
+
+	// Usage of deleted organizations is dropped, and nothing is left to
+	// flush.
+	if len(r.pending) != 0 {
+		t.Fatalf("expected no pending usage, got %v", r.pending)
+	}
+}
+
+func TestRecorder_FlushError(t *testing.T) {
+	ctx := context.Background()
+	orgID := influxdb.ID(1)
+
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByNameFn = func(_ context.Context, org influxdb.ID, name string) (*influxdb.Bucket, error) {
+		return &influxdb.Bucket{ID: influxdb.ID(3), OrgID: org, Name: name}, nil
+	}
+	pw := &mock.PointsWriter{}
+	pw.ForceError(errors.New("engine closed"))
+
+	r := NewRecorder(zaptest.NewLogger(t), buckets, pw)
+	r.RecordUsage(ctx, influxdb.Usage{OrganizationID: &orgID, Type: influxdb.UsageWriteRequestCount, Value: 1})
+
+	if err := r.Flush(ctx); err == nil {
+		t.Fatal("expected the flush to fail")
+	}
+
+	// The usage is kept and added to for the next flush.
+	r.RecordUsage(ctx, influxdb.Usage{OrganizationID: &orgID, Type: influxdb.UsageWriteRequestCount, Value: 1})
+	pw.ForceError(nil)
+	pw.Points = nil
+	if err := r.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	got := writtenUsage(t, pw.Points, orgID, influxdb.ID(3))
+	if v := got["/"+string(influxdb.UsageWriteRequestCount)]; v != 2 {
+		t.Fatalf("expected a write request count of 2, got %v", got)
+	}
+}
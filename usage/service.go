@@ -0,0 +1,144 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/query"
+)
+
+var _ influxdb.UsageService = (*Service)(nil)
+
+// Service answers the usage of organizations from their usage system
+// buckets.
+type Service struct {
+	buckets influxdb.BucketService
+	queries query.QueryService
+}
+
+// NewService returns a Service that finds the usage system buckets with
+// buckets and queries them with queries.
+func NewService(buckets influxdb.BucketService, queries query.QueryService) *Service {
+	return &Service{
+		buckets: buckets,
+		queries: queries,
+	}
+}
+
+// GetUsage returns the usage of the organization of the filter within its
+// range, by metric. Only the usage of the bucket of the filter is returned
+// when it is set. Metrics without usage are left out.
+func (s *Service) GetUsage(ctx context.Context, filter influxdb.UsageFilter) (map[influxdb.UsageMetric]*influxdb.Usage, error) {
+	if filter.OrgID == nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "usage requires an organization",
+		}
+	}
+	if filter.Range == nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "usage requires a time range",
+		}
+	}
+
+	usage := make(map[influxdb.UsageMetric]*influxdb.Usage)
+
+	orgID := *filter.OrgID
+	sb, err := s.buckets.FindBucketByName(ctx, orgID, influxdb.UsageSystemBucketName)
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		// No usage has been recorded for the organization yet.
+		return usage, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	bucketFilter := ""
+	if filter.BucketID != nil {
+		bucketFilter = fmt.Sprintf(" and r.%s == %q", bucketIDTag, filter.BucketID.String())
+	}
+
+	script := fmt.Sprintf(`from(bucketID: %q)
+	  |> range(start: %s, stop: %s)
+	  |> filter(fn: (r) => r._measurement == %q%s)
+	  |> group(columns: ["_field"])
+	  |> sum()
+	  `, sb.ID.String(), filter.Range.Start.UTC().Format(time.RFC3339Nano), filter.Range.Stop.UTC().Format(time.RFC3339Nano), usageMeasurement, bucketFilter)
+
+	// At this point we are behind authorization
+	// so we are faking a read only permission to the org's system bucket
+	usageSystemBucketID := sb.ID
+	auth := &influxdb.Authorization{
+		ID:     sb.ID,
+		Status: influxdb.Active,
+		OrgID:  orgID,
+		Permissions: []influxdb.Permission{
+			{
+				Action: influxdb.ReadAction,
+				Resource: influxdb.Resource{
+					Type:  influxdb.BucketsResourceType,
+					OrgID: &orgID,
+					ID:    &usageSystemBucketID,
+				},
+			},
+		},
+	}
+	request := &query.Request{Authorization: auth, OrganizationID: orgID, Compiler: lang.FluxCompiler{Query: script}}
+
+	ittr, err := s.queries.Query(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	defer ittr.Release()
+
+	readUsage := func(cr flux.ColReader) error {
+		field, value := -1, -1
+		for j, col := range cr.Cols() {
+			switch col.Label {
+			case "_field":
+				field = j
+			case "_value":
+				if col.Type == flux.TFloat {
+					value = j
+				}
+			}
+		}
+		if field < 0 || value < 0 {
+			return nil
+		}
+
+		for i := 0; i < cr.Len(); i++ {
+			if !cr.Floats(value).IsValid(i) {
+				continue
+			}
+			m := influxdb.UsageMetric(cr.Strings(field).ValueString(i))
+			usage[m] = &influxdb.Usage{
+				OrganizationID: filter.OrgID,
+				BucketID:       filter.BucketID,
+				Type:           m,
+				Value:          cr.Floats(value).Value(i),
+			}
+		}
+		return nil
+	}
+
+	for ittr.More() {
+		err := ittr.Next().Tables().Do(func(tbl flux.Table) error {
+			return tbl.Do(readUsage)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := ittr.Err(); err != nil {
+		return nil, fmt.Errorf("unexpected internal error while decoding usage response: %v", err)
+	}
+
+	return usage, nil
+}
@@ -0,0 +1,113 @@
+package usage
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+// ErrSystemBucketWrite is the message of the error returned for writes and
+// deletes of users in the usage system bucket of an organization.
+const ErrSystemBucketWrite = "the _usage system bucket is only written by the server"
+
+// IsSystemBucket returns true if b is the usage system bucket of its
+// organization. Its data is only written by the server, since the monthly
+// write quota of the organization is checked against it.
+func IsSystemBucket(b *influxdb.Bucket) bool {
+	return b.Type == influxdb.BucketTypeSystem && b.Name == influxdb.UsageSystemBucketName
+}
+
+type writeBytesKey struct{}
+
+// NewContextWithWriteBytes returns a new context that counts n bytes
+// towards the monthly write quota for the points written with it, such as
+// the size of the request they were parsed from.
+func NewContextWithWriteBytes(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, writeBytesKey{}, n)
+}
+
+var _ storage.PointsWriter = (*PointsWriter)(nil)
+
+// PointsWriter enforces the write quotas of organizations on the points
+// written to the underlying writer, and rejects those of usage system
+// buckets. It wraps the writer of the points of users, from the write APIs
+// and the to functions of Flux, while the usage itself is written to the
+// underlying writer.
+type PointsWriter struct {
+	buckets influxdb.BucketService
+	quotas  *Quotas
+	writer  storage.PointsWriter
+}
+
+// NewPointsWriter returns a PointsWriter that finds the buckets written to
+// with buckets, checks the writes against quotas and writes the points to
+// writer.
+func NewPointsWriter(buckets influxdb.BucketService, quotas *Quotas, writer storage.PointsWriter) *PointsWriter {
+	return &PointsWriter{
+		buckets: buckets,
+		quotas:  quotas,
+		writer:  writer,
+	}
+}
+
+// WritePoints writes the points to the underlying writer, unless they are
+// written to a usage system bucket or exceed the write quota of their
+// organization. The bytes counted towards the monthly quota are those of
+// the context, and otherwise the size of the points in line protocol.
+func (w *PointsWriter) WritePoints(ctx context.Context, points []models.Point) error {
+	orgBytes := make(map[influxdb.ID]int)
+	buckets := make(map[influxdb.ID]bool)
+	for _, p := range points {
+		name := p.Name()
+		if len(name) != len(tsdb.EncodeName(0, 0)) {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "points must be written to the bucket of an organization",
+			}
+		}
+		orgID, bucketID := tsdb.DecodeNameSlice(name)
+		orgBytes[orgID] += p.StringSize() + 1
+		buckets[bucketID] = true
+	}
+	if n, ok := ctx.Value(writeBytesKey{}).(int); ok && len(orgBytes) == 1 {
+		for orgID := range orgBytes {
+			orgBytes[orgID] = n
+		}
+	}
+
+	for bucketID := range buckets {
+		b, err := w.buckets.FindBucketByID(ctx, bucketID)
+		if err != nil {
+			return err
+		}
+		if IsSystemBucket(b) {
+			return &influxdb.Error{
+				Code: influxdb.EForbidden,
+				Msg:  ErrSystemBucketWrite,
+			}
+		}
+	}
+
+	allowed := make(map[influxdb.ID]int, len(orgBytes))
+	refund := func() {
+		for orgID, n := range allowed {
+			w.quotas.RefundWrite(orgID, n)
+		}
+	}
+	for orgID, n := range orgBytes {
+		if err := w.quotas.AllowWrite(ctx, orgID, n); err != nil {
+			refund()
+			return err
+		}
+		allowed[orgID] = n
+	}
+
+	if err := w.writer.WritePoints(ctx, points); err != nil {
+		refund()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+func TestPointsWriter(t *testing.T) {
+	ctx := context.Background()
+	orgID, bucketID, usageID := influxdb.ID(1), influxdb.ID(2), influxdb.ID(3)
+
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByIDFn = func(_ context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+		if id == usageID {
+			return &influxdb.Bucket{ID: id, OrgID: orgID, Type: influxdb.BucketTypeSystem, Name: influxdb.UsageSystemBucketName}, nil
+		}
+		return &influxdb.Bucket{ID: id, OrgID: orgID, Name: "b"}, nil
+	}
+	q, _, _ := newTestQuotas(influxdb.OrgLimits{MonthlyWriteBytesQuota: 1000}, 900, nil)
+	pw := &mock.PointsWriter{}
+	w := NewPointsWriter(buckets, q, pw)
+
+	point := func(bucketID influxdb.ID) models.Point {
+		name := tsdb.EncodeName(orgID, bucketID)
+		p, err := models.NewPoint(string(name[:]), models.NewTags(map[string]string{"_m": "m"}), models.Fields{"f": 1.0}, time.Unix(0, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	expectForbidden(t, w.WritePoints(ctx, []models.Point{point(usageID)}), ErrSystemBucketWrite)
+
+	// A failed write does not count towards the quota.
+	pw.ForceError(errors.New("disk full"))
+	if err := w.WritePoints(NewContextWithWriteBytes(ctx, 100), []models.Point{point(bucketID)}); err == nil {
+		t.Fatal("expected the write to fail")
+	}
+	pw.ForceError(nil)
+
+	if err := w.WritePoints(NewContextWithWriteBytes(ctx, 100), []models.Point{point(bucketID)}); err != nil {
+		t.Fatal(err)
+	}
+	// Without the bytes of the request, the size of the points is counted.
+	expectForbidden(t, w.WritePoints(ctx, []models.Point{point(bucketID)}), "monthly write quota of 1000 bytes of the organization exceeded")
+	if n := pw.WritePointsCalled(); n != 2 {
+		t.Fatalf("expected the points to be written twice, got %d", n)
+	}
+}